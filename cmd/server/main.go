@@ -12,7 +12,10 @@ import (
 	"time"
 
 	"clisimplehub/internal/config"
+	"clisimplehub/internal/executor"
+	"clisimplehub/internal/logger"
 	"clisimplehub/internal/proxy"
+	"clisimplehub/internal/runtimeconfig"
 	"clisimplehub/internal/statsdb"
 	"clisimplehub/internal/storage"
 )
@@ -28,8 +31,48 @@ const (
 	ConfigKeyPort     = "port"
 	ConfigKeyAPIKey   = "apiKey"
 	ConfigKeyFallback = "fallback"
+	// Honor the X-CliHub-Endpoint-Id request header (off by default)
+	ConfigKeyAllowEndpointOverride = "allowEndpointOverride"
+	// Gzip non-streaming responses for clients that accept it (off by default)
+	ConfigKeyCompressResponses = "compressResponses"
+	// Keep request/response bodies in request log details (on by default)
+	ConfigKeyCaptureBodies = "captureBodies"
+	// Pass the raw upstream response through when a transformer yields no output for it (on by default)
+	ConfigKeyRawFallbackOnTransformError = "rawFallbackOnTransformError"
+	// Match requested models to endpoint Models by normalized name (dates/-latest stripped) when no mapping matches (off by default)
+	ConfigKeyFuzzyModelMatch = "fuzzyModelMatch"
+	// Open a pooled connection to an endpoint as soon as it becomes active (off by default)
+	ConfigKeyWarmupOnActivate = "warmupOnActivate"
+	// Share one upstream stream among identical concurrent streaming requests (temperature 0 or X-Proxy-Coalesce) (off by default)
+	ConfigKeyStreamCoalescing = "streamCoalescing"
+	// Webhook URL receiving JSON notifications on endpoint fallback, temporary disable and recovery
+	ConfigKeyNotifyWebhookURL = "notifyWebhookURL"
 	// Temporary disable TTL for failed endpoints (minutes)
 	ConfigKeyTempDisableMinutes = "tempDisableMinutes"
+	// Keep failed endpoints disabled (persisted) until re-enabled by the user instead of restoring after the TTL
+	ConfigKeyManualReenable = "manualReenable"
+	// Endpoint selection for new requests: "priority" (default), "least-tokens" or "random"
+	ConfigKeyRoutingMode = "routingMode"
+	// Regex rewrite rules applied to incoming request paths; the first matching rule wins
+	ConfigKeyPathRewrites = "pathRewrites"
+	// Ordered path rules mapping requests to interface types before built-in detection
+	ConfigKeyInterfaceDetectionRules = "interfaceDetectionRules"
+	// Routing of paths no detection rule recognizes: "claude" (default), "reject" (404) or an interface type
+	ConfigKeyUnknownPathBehavior = "unknownPathBehavior"
+	// A/B experiments diverting a share of requests to a fixed endpoint
+	ConfigKeyExperiments = "experiments"
+	// Ordered rules forcing an interface type or endpoint by path, header or body model
+	ConfigKeyRoutingRules = "routingRules"
+	// Labeled proxy keys, each optionally limited to some interface types
+	ConfigKeyAuthKeys = "authKeys"
+	// Extra header names masked in request logs, and whether to drop them instead of masking
+	ConfigKeySensitiveHeaders     = "sensitiveHeaders"
+	ConfigKeyDropSensitiveHeaders = "dropSensitiveHeaders"
+	// Client header forwarding: "denylist" (default) or "allowlist" of forwardHeaders
+	ConfigKeyForwardHeaderMode = "forwardHeaderMode"
+	ConfigKeyForwardHeaders    = "forwardHeaders"
+	// Model used per interface type when neither the client nor the endpoint specifies one
+	ConfigKeyDefaultModels = "defaultModels"
 )
 
 func main() {
@@ -37,6 +80,7 @@ func main() {
 	log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
 
 	log.Println("Starting Cli Simple Hub in headless mode...")
+	applyLogLevelEnv()
 
 	// Get configuration from environment variables or defaults
 	port := getEnvInt("PORT", DefaultPort)
@@ -93,6 +137,27 @@ func main() {
 		}
 	}
 	router.SetTempDisableTTL(time.Duration(tempDisableMinutes) * time.Minute)
+	if v, err := store.GetConfig(ConfigKeyManualReenable); err == nil && v == "true" {
+		router.SetManualReenable(true)
+		log.Println("Manual re-enable of failed endpoints enabled")
+	}
+	if v, err := store.GetConfig(ConfigKeyRoutingMode); err == nil && v != "" {
+		if err := router.SetRoutingMode(v); err != nil {
+			log.Printf("Warning: %v", err)
+		} else {
+			log.Printf("Routing mode: %s", router.RoutingMode())
+		}
+	}
+	if v, err := store.GetConfig(ConfigKeyUnknownPathBehavior); err == nil && v != "" {
+		if err := router.SetUnknownPathBehavior(v); err != nil {
+			log.Printf("Warning: %v", err)
+		} else {
+			log.Printf("Unknown path behavior: %s", v)
+		}
+	}
+	if source, ok := vendorStatsStore.(proxy.TokenUsageSource); ok {
+		router.SetTokenUsageSource(source)
+	}
 	proxyEndpoints := convertEndpoints(endpoints)
 	router.LoadEndpoints(proxyEndpoints)
 
@@ -115,6 +180,90 @@ func main() {
 		proxyServer.SetFallbackEnabled(true)
 		log.Println("Fallback mode enabled")
 	}
+	if v, err := store.GetConfig(ConfigKeyAllowEndpointOverride); err == nil && v == "true" {
+		proxyServer.SetEndpointOverrideEnabled(true)
+		log.Println("Endpoint override header enabled")
+	}
+	if v, err := store.GetConfig(ConfigKeyCompressResponses); err == nil && v == "true" {
+		proxyServer.SetCompressResponses(true)
+		log.Println("Response compression enabled")
+	}
+	if v, err := store.GetConfig(ConfigKeyCaptureBodies); err == nil && v == "false" {
+		proxyServer.SetCaptureBodies(false)
+		log.Println("Request/response body capture disabled")
+	}
+	if v, err := store.GetConfig(ConfigKeyRawFallbackOnTransformError); err == nil && v == "false" {
+		executor.SetRawFallbackOnTransformError(false)
+		log.Println("Raw passthrough on transformer errors disabled")
+	}
+	if v, err := store.GetConfig(ConfigKeyFuzzyModelMatch); err == nil && v == "true" {
+		executor.SetFuzzyModelMatch(true)
+		log.Println("Fuzzy model matching enabled")
+	}
+	if v, err := store.GetConfig(ConfigKeyWarmupOnActivate); err == nil && v == "true" {
+		proxyServer.SetWarmupOnActivate(true)
+		log.Println("Endpoint warmup on activation enabled")
+	}
+	if v, err := store.GetConfig(ConfigKeyStreamCoalescing); err == nil && v == "true" {
+		proxyServer.SetStreamCoalescing(true)
+		log.Println("Streaming request coalescing enabled")
+	}
+	if v, err := store.GetConfig(ConfigKeyNotifyWebhookURL); err == nil && v != "" {
+		proxyServer.SetNotifyWebhookURL(v)
+		log.Println("Endpoint failover notification webhook enabled")
+	}
+	runtimeconfig.Apply(store, proxyServer)
+	log.Printf("Stats timezone: %s", statsdb.StatsLocation())
+	// Load path rewrite, interface detection, header masking and forwarding rules, and default models from config
+	if cfg, err := configLoader.Load(); err == nil && cfg.AppConfigKV != nil {
+		executor.SetSensitiveHeaderPolicy(executor.ParseSensitiveHeaders(cfg.AppConfigKV[ConfigKeySensitiveHeaders]), cfg.AppConfigKV[ConfigKeyDropSensitiveHeaders] == true)
+		forwardMode, _ := cfg.AppConfigKV[ConfigKeyForwardHeaderMode].(string)
+		if err := executor.SetForwardHeaderPolicy(forwardMode, executor.ParseForwardHeaders(cfg.AppConfigKV[ConfigKeyForwardHeaders])); err != nil {
+			log.Printf("Warning: %v", err)
+		} else if executor.ForwardHeaderMode() == executor.ForwardHeaderModeAllowlist {
+			log.Println("Forwarding only allowlisted client headers")
+		}
+		if models, err := executor.ParseDefaultModels(cfg.AppConfigKV[ConfigKeyDefaultModels]); err != nil {
+			log.Printf("Warning: Failed to parse default models: %v", err)
+		} else {
+			executor.SetDefaultModels(models)
+		}
+		if rules, err := proxy.ParsePathRewriteRules(cfg.AppConfigKV[ConfigKeyPathRewrites]); err != nil {
+			log.Printf("Warning: Failed to parse path rewrites: %v", err)
+		} else if err := proxyServer.SetPathRewrites(rules); err != nil {
+			log.Printf("Warning: Failed to load path rewrites: %v", err)
+		} else if len(rules) > 0 {
+			log.Printf("Loaded %d path rewrite rules", len(rules))
+		}
+		if rules, err := proxy.ParseDetectionRules(cfg.AppConfigKV[ConfigKeyInterfaceDetectionRules]); err != nil {
+			log.Printf("Warning: Failed to parse interface detection rules: %v", err)
+		} else if err := router.SetDetectionRules(rules); err != nil {
+			log.Printf("Warning: Failed to load interface detection rules: %v", err)
+		} else if len(rules) > 0 {
+			log.Printf("Loaded %d interface detection rules", len(rules))
+		}
+		if rules, err := proxy.ParseRoutingRules(cfg.AppConfigKV[ConfigKeyRoutingRules]); err != nil {
+			log.Printf("Warning: Failed to parse routing rules: %v", err)
+		} else if err := proxyServer.SetRoutingRules(rules); err != nil {
+			log.Printf("Warning: Failed to load routing rules: %v", err)
+		} else if len(rules) > 0 {
+			log.Printf("Loaded %d routing rules", len(rules))
+		}
+		if keys, err := proxy.ParseScopedAuthKeys(cfg.AppConfigKV[ConfigKeyAuthKeys]); err != nil {
+			log.Printf("Warning: Failed to parse auth keys: %v", err)
+		} else if err := proxyServer.SetScopedAuthKeys(keys); err != nil {
+			log.Printf("Warning: Failed to load auth keys: %v", err)
+		} else if len(keys) > 0 {
+			log.Printf("Loaded %d scoped auth keys", len(keys))
+		}
+		if experiments, err := proxy.ParseExperiments(cfg.AppConfigKV[ConfigKeyExperiments]); err != nil {
+			log.Printf("Warning: Failed to parse experiments: %v", err)
+		} else if err := proxyServer.SetExperiments(experiments); err != nil {
+			log.Printf("Warning: Failed to load experiments: %v", err)
+		} else if len(experiments) > 0 {
+			log.Printf("Loaded %d experiments", len(experiments))
+		}
+	}
 
 	// Set up signal handling for graceful shutdown
 	// Requirements: 5.4
@@ -162,6 +311,34 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// applyLogLevelEnv sets the logger level from the LOG_LEVEL environment variable
+// (debug, info, warn or error); unset keeps the default info level.
+func applyLogLevelEnv() {
+	v := os.Getenv("LOG_LEVEL")
+	if v == "" {
+		return
+	}
+	level, err := logger.ParseLevel(v)
+	if err != nil {
+		log.Printf("Warning: LOG_LEVEL: %v", err)
+		return
+	}
+	logger.SetDefaultLevel(level)
+	log.Printf("Log level: %s", level)
+}
+
+// toProxySchedule converts storage schedule windows to proxy schedule windows
+func toProxySchedule(windows []storage.ScheduleWindow) []proxy.ScheduleWindow {
+	if len(windows) == 0 {
+		return nil
+	}
+	out := make([]proxy.ScheduleWindow, 0, len(windows))
+	for _, w := range windows {
+		out = append(out, proxy.ScheduleWindow{Days: w.Days, StartHHMM: w.StartHHMM, EndHHMM: w.EndHHMM})
+	}
+	return out
+}
+
 // convertEndpoints converts storage.Endpoint to proxy.Endpoint
 func convertEndpoints(endpoints []*storage.Endpoint) []*proxy.Endpoint {
 	result := make([]*proxy.Endpoint, len(endpoints))
@@ -170,25 +347,45 @@ func convertEndpoints(endpoints []*storage.Endpoint) []*proxy.Endpoint {
 		if len(e.Models) > 0 {
 			models = make([]proxy.ModelMapping, 0, len(e.Models))
 			for _, m := range e.Models {
-				models = append(models, proxy.ModelMapping{Name: m.Name, Alias: m.Alias})
+				models = append(models, proxy.ModelMapping{Name: m.Name, Alias: m.Alias, MatchType: m.MatchType})
 			}
 		}
 		result[i] = &proxy.Endpoint{
-			ID:            e.ID,
-			Name:          e.Name,
-			APIURL:        e.APIURL,
-			APIKey:        e.APIKey,
-			Active:        e.Active,
-			Enabled:       e.Enabled,
-			InterfaceType: e.InterfaceType,
-			Transformer:   e.Transformer,
-			VendorID:      e.VendorID,
-			Model:         e.Model,
-			Remark:        e.Remark,
-			Priority:      e.Priority,
-			ProxyURL:      e.ProxyURL,
-			Models:        models,
-			Headers:       e.Headers,
+			ID:                     e.ID,
+			Name:                   e.Name,
+			APIURL:                 e.APIURL,
+			APIKey:                 e.APIKey,
+			Active:                 e.Active,
+			Enabled:                e.Enabled,
+			InterfaceType:          e.InterfaceType,
+			Transformer:            e.Transformer,
+			VendorID:               e.VendorID,
+			Model:                  e.Model,
+			Remark:                 e.Remark,
+			Priority:               e.Priority,
+			ProxyURL:               e.ProxyURL,
+			ProxyUsername:          e.ProxyUsername,
+			ProxyPassword:          e.ProxyPassword,
+			ForceNonStreamUpstream: e.ForceNonStreamUpstream,
+			FallbackToDefaultModel: e.FallbackToDefaultModel,
+			Models:                 models,
+			Headers:                e.Headers,
+			DropParams:             e.DropParams,
+			BodyOverrides:          e.BodyOverrides,
+			BodyForce:              e.BodyForce,
+			IncludeReasoning:       e.IncludeReasoning,
+			ReasoningSummary:       e.ReasoningSummary,
+			InsecureSkipVerify:     e.InsecureSkipVerify,
+			ForwardClientIP:        e.ForwardClientIP,
+			Sandbox:                e.Sandbox,
+			CAFile:                 e.CAFile,
+			AnthropicVersion:       e.AnthropicVersion,
+			AnthropicBeta:          e.AnthropicBeta,
+			DailyTokenBudget:       e.DailyTokenBudget,
+			MaxTokensCap:           e.MaxTokensCap,
+			ResponseValidation:     e.ResponseValidation,
+			Schedule:               toProxySchedule(e.Schedule),
+			VendorHeaders:          e.VendorHeaders,
 		}
 	}
 	return result
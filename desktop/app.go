@@ -1,12 +1,16 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"net"
 	"net/http"
 	"net/url"
@@ -18,7 +22,10 @@ import (
 	"time"
 
 	"clisimplehub/internal/config"
+	"clisimplehub/internal/executor"
+	"clisimplehub/internal/logger"
 	"clisimplehub/internal/proxy"
+	"clisimplehub/internal/runtimeconfig"
 	"clisimplehub/internal/statsdb"
 	"clisimplehub/internal/storage"
 	"clisimplehub/internal/transformer"
@@ -31,31 +38,55 @@ type Settings struct {
 	Port     int    `json:"port"`
 	APIKey   string `json:"apiKey"`
 	Fallback bool   `json:"fallback"`
+	// Defaults for endpoints that omit priority / interface type; nil keeps the stored value
+	DefaultEndpointPriority *int    `json:"defaultEndpointPriority,omitempty"`
+	DefaultInterfaceType    *string `json:"defaultInterfaceType,omitempty"`
 }
 
 // EndpointInfo represents endpoint information for frontend display
 // Requirements: 6.1, 6.2, 6.3, 6.4
 type EndpointInfo struct {
-	ID            int64                  `json:"id"`
-	Name          string                 `json:"name"`
-	APIURL        string                 `json:"apiUrl"`
-	APIKey        string                 `json:"apiKey,omitempty"`
-	Active        bool                   `json:"active"`
-	Enabled       bool                   `json:"enabled"`
-	InterfaceType string                 `json:"interfaceType"`
-	VendorID      int64                  `json:"vendorId"`
-	VendorName    string                 `json:"vendorName,omitempty"`
-	Model         string                 `json:"model,omitempty"`
-	Transformer   string                 `json:"transformer,omitempty"`
-	ProxyURL      string                 `json:"proxyUrl,omitempty"`
-	Models        []storage.ModelMapping `json:"models,omitempty"`
-	Remark        string                 `json:"remark,omitempty"`
-	Priority      int                    `json:"priority"`
+	ID                     int64                    `json:"id"`
+	Name                   string                   `json:"name"`
+	APIURL                 string                   `json:"apiUrl"`
+	APIKey                 string                   `json:"apiKey,omitempty"`
+	Active                 bool                     `json:"active"`
+	Enabled                bool                     `json:"enabled"`
+	InterfaceType          string                   `json:"interfaceType"`
+	VendorID               int64                    `json:"vendorId"`
+	VendorName             string                   `json:"vendorName,omitempty"`
+	Model                  string                   `json:"model,omitempty"`
+	Transformer            string                   `json:"transformer,omitempty"`
+	ProxyURL               string                   `json:"proxyUrl,omitempty"`
+	ProxyUsername          string                   `json:"proxyUsername,omitempty"`
+	ProxyPassword          string                   `json:"proxyPassword,omitempty"`
+	ForceNonStreamUpstream bool                     `json:"forceNonStreamUpstream,omitempty"`
+	FallbackToDefaultModel bool                     `json:"fallbackToDefaultModel,omitempty"`
+	Models                 []storage.ModelMapping   `json:"models,omitempty"`
+	Schedule               []storage.ScheduleWindow `json:"schedule,omitempty"`
+	DropParams             []string                 `json:"dropParams,omitempty"`
+	BodyOverrides          map[string]any           `json:"bodyOverrides,omitempty"`
+	BodyForce              bool                     `json:"bodyForce,omitempty"`
+	IncludeReasoning       bool                     `json:"includeReasoning,omitempty"`
+	ReasoningSummary       string                   `json:"reasoningSummary,omitempty"`
+	InsecureSkipVerify     bool                     `json:"insecureSkipVerify,omitempty"`
+	ForwardClientIP        bool                     `json:"forwardClientIP,omitempty"`
+	Sandbox                bool                     `json:"sandbox,omitempty"`
+	CAFile                 string                   `json:"caFile,omitempty"`
+	AnthropicVersion       string                   `json:"anthropicVersion,omitempty"`
+	AnthropicBeta          string                   `json:"anthropicBeta,omitempty"`
+	DailyTokenBudget       int64                    `json:"dailyTokenBudget,omitempty"`
+	MaxTokensCap           int                      `json:"maxTokensCap,omitempty"`
+	ResponseValidation     []string                 `json:"responseValidation,omitempty"`
+	Remark                 string                   `json:"remark,omitempty"`
+	Priority               int                      `json:"priority"`
 	// Daily stats
 	TodayRequests int64 `json:"todayRequests"`
 	TodayErrors   int64 `json:"todayErrors"`
 	TodayInput    int64 `json:"todayInput"`
 	TodayOutput   int64 `json:"todayOutput"`
+	// LastUsedAt is the unix time in milliseconds of the most recent request, 0 if never used
+	LastUsedAt int64 `json:"lastUsedAt,omitempty"`
 }
 
 // App struct represents the Wails application controller
@@ -68,6 +99,7 @@ type App struct {
 	wsHub        *proxy.WSHub
 	configLoader *config.ConfigLoader
 	vendorStats  *statsdb.SQLiteVendorStatsStore
+	dataDir      string
 }
 
 // NewApp creates a new App application struct
@@ -79,6 +111,7 @@ func NewApp() *App {
 // so we can call the runtime methods
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
+	go a.runConfigSnapshotAutoSync(ctx)
 }
 
 // SetStorage sets the storage instance for the app
@@ -111,6 +144,11 @@ func (a *App) SetConfigLoader(loader *config.ConfigLoader) {
 	a.configLoader = loader
 }
 
+// SetDataDir sets the data directory holding config profiles
+func (a *App) SetDataDir(dir string) {
+	a.dataDir = dir
+}
+
 // =============================================================================
 // Settings Management Methods
 // Requirements: 1.1, 1.2, 1.3, 1.4
@@ -148,6 +186,14 @@ func (a *App) GetSettings() (*Settings, error) {
 		settings.Fallback = true
 	}
 
+	// Get endpoint defaults from storage
+	defaults := config.LoadEndpointDefaults(func(key string) string {
+		v, _ := a.storage.GetConfig(key)
+		return v
+	})
+	settings.DefaultEndpointPriority = &defaults.Priority
+	settings.DefaultInterfaceType = &defaults.InterfaceType
+
 	return settings, nil
 }
 
@@ -162,6 +208,16 @@ func (a *App) SaveSettings(settings *Settings) error {
 		return fmt.Errorf("invalid port: %w", err)
 	}
 
+	// Validate endpoint defaults before anything is written
+	if settings.DefaultEndpointPriority != nil && *settings.DefaultEndpointPriority < 0 {
+		return fmt.Errorf("invalid %s: must not be negative", config.AppConfigKeyDefaultEndpointPriority)
+	}
+	if settings.DefaultInterfaceType != nil {
+		if err := config.ValidateDefaultInterfaceType(*settings.DefaultInterfaceType); err != nil {
+			return err
+		}
+	}
+
 	// Save port to storage
 	if err := a.storage.SetConfig(ConfigKeyPort, strconv.Itoa(settings.Port)); err != nil {
 		return fmt.Errorf("failed to save port: %w", err)
@@ -177,16 +233,217 @@ func (a *App) SaveSettings(settings *Settings) error {
 		return fmt.Errorf("failed to save fallback setting: %w", err)
 	}
 
-	// Update proxy server port if available
+	// Save endpoint defaults (0 / empty => built-in default)
+	if settings.DefaultEndpointPriority != nil {
+		priority := ""
+		if *settings.DefaultEndpointPriority > 0 {
+			priority = strconv.Itoa(*settings.DefaultEndpointPriority)
+		}
+		if err := a.storage.SetConfig(config.AppConfigKeyDefaultEndpointPriority, priority); err != nil {
+			return fmt.Errorf("failed to save default endpoint priority: %w", err)
+		}
+	}
+	if settings.DefaultInterfaceType != nil {
+		interfaceType := strings.ToLower(strings.TrimSpace(*settings.DefaultInterfaceType))
+		if err := a.storage.SetConfig(config.AppConfigKeyDefaultInterfaceType, interfaceType); err != nil {
+			return fmt.Errorf("failed to save default interface type: %w", err)
+		}
+	}
+
+	return a.applyRuntimeConfig()
+}
+
+// applyRuntimeConfig pushes the settings stored in config.json into the running proxy server,
+// router and executor. SaveSettings, ReloadConfig and SwitchProfile all go through it so a
+// setting is never applied by one path but missed by another.
+func (a *App) applyRuntimeConfig() error {
+	settings, err := a.GetSettings()
+	if err != nil {
+		return err
+	}
+
+	// Refresh router temp-disable TTL (default 5 minutes), manual re-enable, routing mode and unknown path behavior from config.json appConfig.
+	if a.router != nil {
+		tempDisableMinutes := 5
+		if v, err := a.storage.GetConfig(ConfigKeyTempDisableMinutes); err == nil && v != "" {
+			if minutes, err := strconv.Atoi(v); err == nil && minutes > 0 {
+				tempDisableMinutes = minutes
+			}
+		}
+		a.router.SetTempDisableTTL(time.Duration(tempDisableMinutes) * time.Minute)
+		manualStr, _ := a.storage.GetConfig(ConfigKeyManualReenable)
+		a.router.SetManualReenable(manualStr == "true")
+		routingMode, _ := a.storage.GetConfig(ConfigKeyRoutingMode)
+		if err := a.router.SetRoutingMode(routingMode); err != nil {
+			logger.Warn("[RuntimeConfig] %v", err)
+		}
+		unknownPath, _ := a.storage.GetConfig(ConfigKeyUnknownPathBehavior)
+		if err := a.router.SetUnknownPathBehavior(unknownPath); err != nil {
+			logger.Warn("[RuntimeConfig] %v", err)
+		}
+	}
+
 	if a.proxyServer != nil {
 		a.proxyServer.SetPort(settings.Port)
 		a.proxyServer.SetAuthKey(settings.APIKey)
 		a.proxyServer.SetFallbackEnabled(settings.Fallback)
+		overrideStr, _ := a.storage.GetConfig(ConfigKeyAllowEndpointOverride)
+		a.proxyServer.SetEndpointOverrideEnabled(overrideStr == "true")
+		compressStr, _ := a.storage.GetConfig(ConfigKeyCompressResponses)
+		a.proxyServer.SetCompressResponses(compressStr == "true")
+		captureStr, _ := a.storage.GetConfig(ConfigKeyCaptureBodies)
+		a.proxyServer.SetCaptureBodies(captureStr != "false")
+		warmupStr, _ := a.storage.GetConfig(ConfigKeyWarmupOnActivate)
+		a.proxyServer.SetWarmupOnActivate(warmupStr == "true")
+		coalesceStr, _ := a.storage.GetConfig(ConfigKeyStreamCoalescing)
+		a.proxyServer.SetStreamCoalescing(coalesceStr == "true")
+		notifyWebhookURL, _ := a.storage.GetConfig(ConfigKeyNotifyWebhookURL)
+		a.proxyServer.SetNotifyWebhookURL(notifyWebhookURL)
+	}
+	rawFallbackStr, _ := a.storage.GetConfig(ConfigKeyRawFallbackOnTransformError)
+	executor.SetRawFallbackOnTransformError(rawFallbackStr != "false")
+	fuzzyStr, _ := a.storage.GetConfig(ConfigKeyFuzzyModelMatch)
+	executor.SetFuzzyModelMatch(fuzzyStr == "true")
+	runtimeconfig.Apply(a.storage, a.proxyServer)
+
+	// Structured settings (arrays/objects) are read from the raw appConfig
+	if a.configLoader == nil {
+		return nil
+	}
+	cfg, err := a.configLoader.Load()
+	if err != nil {
+		logger.Error("[RuntimeConfig] Failed to load config: %v", err)
+		return nil
+	}
+	if a.proxyServer != nil {
+		rules, err := proxy.ParsePathRewriteRules(cfg.AppConfigKV[ConfigKeyPathRewrites])
+		if err != nil {
+			return err
+		}
+		if err := a.proxyServer.SetPathRewrites(rules); err != nil {
+			return err
+		}
+		experiments, err := proxy.ParseExperiments(cfg.AppConfigKV[ConfigKeyExperiments])
+		if err != nil {
+			return err
+		}
+		if err := a.proxyServer.SetExperiments(experiments); err != nil {
+			return err
+		}
+		routingRules, err := proxy.ParseRoutingRules(cfg.AppConfigKV[ConfigKeyRoutingRules])
+		if err != nil {
+			return err
+		}
+		if err := a.proxyServer.SetRoutingRules(routingRules); err != nil {
+			return err
+		}
+		authKeys, err := proxy.ParseScopedAuthKeys(cfg.AppConfigKV[ConfigKeyAuthKeys])
+		if err != nil {
+			return err
+		}
+		if err := a.proxyServer.SetScopedAuthKeys(authKeys); err != nil {
+			return err
+		}
+	}
+	executor.SetSensitiveHeaderPolicy(executor.ParseSensitiveHeaders(cfg.AppConfigKV[ConfigKeySensitiveHeaders]), cfg.AppConfigKV[ConfigKeyDropSensitiveHeaders] == true)
+	forwardMode, _ := cfg.AppConfigKV[ConfigKeyForwardHeaderMode].(string)
+	if err := executor.SetForwardHeaderPolicy(forwardMode, executor.ParseForwardHeaders(cfg.AppConfigKV[ConfigKeyForwardHeaders])); err != nil {
+		return err
+	}
+	defaultModels, err := executor.ParseDefaultModels(cfg.AppConfigKV[ConfigKeyDefaultModels])
+	if err != nil {
+		return err
+	}
+	executor.SetDefaultModels(defaultModels)
+	if a.router != nil {
+		detectionRules, err := proxy.ParseDetectionRules(cfg.AppConfigKV[ConfigKeyInterfaceDetectionRules])
+		if err != nil {
+			return err
+		}
+		if err := a.router.SetDetectionRules(detectionRules); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// =============================================================================
+// Config Profile Methods
+// =============================================================================
+
+// ProfileInfo represents a config profile for frontend display
+type ProfileInfo struct {
+	Name   string `json:"name"`
+	Active bool   `json:"active"`
+}
+
+// ListProfiles returns all config profiles in the data directory
+func (a *App) ListProfiles() ([]ProfileInfo, error) {
+	dataDir := a.getDataDir()
+	names, err := config.ListProfiles(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	active := config.ReadActiveProfile(dataDir)
+	profiles := make([]ProfileInfo, 0, len(names))
+	for _, name := range names {
+		profiles = append(profiles, ProfileInfo{Name: name, Active: name == active})
+	}
+	return profiles, nil
+}
+
+// CreateProfile creates a new empty config profile
+func (a *App) CreateProfile(name string) error {
+	name = strings.TrimSpace(name)
+	if _, err := config.CreateProfile(a.getDataDir(), name); err != nil {
+		return fmt.Errorf("failed to create profile %s: %w", name, err)
+	}
+	return nil
+}
+
+// SwitchProfile makes the named profile active and reloads endpoints and settings from it
+func (a *App) SwitchProfile(name string) error {
+	if a.storage == nil {
+		return fmt.Errorf("storage not initialized")
+	}
+	name = strings.TrimSpace(name)
+	if err := config.ValidateProfileName(name); err != nil {
+		return err
 	}
 
+	dataDir := a.getDataDir()
+	if !config.ProfileExists(dataDir, name) {
+		return fmt.Errorf("%w: %s", config.ErrProfileNotFound, name)
+	}
+
+	switcher, ok := a.storage.(interface{ SetConfigPath(path string) error })
+	if !ok {
+		return fmt.Errorf("storage does not support profiles")
+	}
+	if err := switcher.SetConfigPath(config.ProfileConfigPath(dataDir, name)); err != nil {
+		return fmt.Errorf("failed to switch profile: %w", err)
+	}
+	if err := config.WriteActiveProfile(dataDir, name); err != nil {
+		return err
+	}
+
+	if err := a.ReloadConfig(); err != nil {
+		return fmt.Errorf("failed to reload config: %w", err)
+	}
 	return nil
 }
 
+func (a *App) getDataDir() string {
+	if a.dataDir != "" {
+		return a.dataDir
+	}
+	if a.configLoader != nil {
+		return filepath.Dir(a.configLoader.GetPath())
+	}
+	return config.GetDataDir()
+}
+
 // GetPort returns the current proxy port
 // Requirements: 1.1
 func (a *App) GetPort() (int, error) {
@@ -263,6 +520,7 @@ func (a *App) GetEndpointsByType(interfaceType string) ([]*EndpointInfo, error)
 	if a.vendorStats != nil {
 		todayStats, _ = a.vendorStats.GetTodayStatsByEndpoints(a.ctx)
 	}
+	lastUsed := a.endpointLastUsed()
 
 	// Get active endpoint from router to mark it
 	var activeEndpointID int64
@@ -311,6 +569,7 @@ func (a *App) GetEndpointsByType(interfaceType string) ([]*EndpointInfo, error)
 				info.TodayOutput = stats.OutputTokens
 			}
 		}
+		info.LastUsedAt = lastUsedMillis(lastUsed[ep.ID])
 		result = append(result, info)
 	}
 
@@ -403,6 +662,33 @@ func (a *App) SetActiveEndpoint(interfaceType string, endpointID int64) error {
 	return a.router.SetActiveEndpoint(proxy.InterfaceType(interfaceType), targetEndpoint)
 }
 
+// SetActiveEndpointChecked tests the endpoint's connectivity before making it active.
+// When the test fails the previous active endpoint stays in place.
+// Only claude and codex endpoints can be tested; use SetActiveEndpoint for other types.
+func (a *App) SetActiveEndpointChecked(interfaceType string, endpointID int64) error {
+	if a.storage == nil {
+		return fmt.Errorf("storage not initialized")
+	}
+
+	ep, err := a.storage.GetEndpointByID(endpointID)
+	if err != nil || ep == nil {
+		return fmt.Errorf("endpoint not found: %d", endpointID)
+	}
+	if ep.InterfaceType != interfaceType {
+		return fmt.Errorf("endpoint %d is not a %s endpoint", endpointID, interfaceType)
+	}
+
+	var result TestEndpointResult
+	if err := json.Unmarshal([]byte(a.doTestEndpoint(ep.APIURL, ep.APIKey, ep.InterfaceType, ep.Model, "", false)), &result); err != nil {
+		return fmt.Errorf("connectivity check failed: %w", err)
+	}
+	if !result.Success {
+		return fmt.Errorf("connectivity check failed: %s", result.Message)
+	}
+
+	return a.SetActiveEndpoint(interfaceType, endpointID)
+}
+
 // ToggleEndpointEnabled toggles the enabled status of an endpoint
 // Active endpoints cannot be disabled
 func (a *App) ToggleEndpointEnabled(endpointID int64, enabled bool) error {
@@ -428,10 +714,14 @@ func (a *App) ToggleEndpointEnabled(endpointID int64, enabled bool) error {
 	}
 
 	// Update enabled status
+	reenabled := enabled && !ep.Enabled
 	ep.Enabled = enabled
 	if err := a.storage.UpdateEndpoint(ep); err != nil {
 		return fmt.Errorf("failed to update endpoint: %w", err)
 	}
+	if reenabled && a.proxyServer != nil {
+		a.proxyServer.NotifyEndpointReenabled(proxy.InterfaceType(ep.InterfaceType), convertEndpoints([]*storage.Endpoint{ep})[0])
+	}
 
 	// Reload endpoints into router
 	if a.router != nil {
@@ -456,6 +746,7 @@ func (a *App) GetAllEndpoints() ([]*EndpointInfo, error) {
 		return nil, fmt.Errorf("failed to get endpoints: %w", err)
 	}
 
+	lastUsed := a.endpointLastUsed()
 	result := make([]*EndpointInfo, 0, len(endpoints))
 	for _, ep := range endpoints {
 		info := &EndpointInfo{
@@ -469,6 +760,7 @@ func (a *App) GetAllEndpoints() ([]*EndpointInfo, error) {
 			Model:         ep.Model,
 			Remark:        ep.Remark,
 			Priority:      ep.Priority,
+			LastUsedAt:    lastUsedMillis(lastUsed[ep.ID]),
 		}
 		result = append(result, info)
 	}
@@ -484,6 +776,74 @@ func (a *App) GetAllEndpoints() ([]*EndpointInfo, error) {
 	return result, nil
 }
 
+// GetIdleEndpoints returns endpoints that have not been used within the given number of days,
+// including endpoints that were never used, least recently used first
+func (a *App) GetIdleEndpoints(days int) ([]*EndpointInfo, error) {
+	if days < 0 {
+		return nil, fmt.Errorf("days must not be negative")
+	}
+
+	endpoints, err := a.GetAllEndpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	vendorMap := make(map[int64]string)
+	if vendors, err := a.storage.GetVendors(); err == nil {
+		for _, v := range vendors {
+			vendorMap[v.ID] = v.Name
+		}
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -days).UnixMilli()
+	result := make([]*EndpointInfo, 0)
+	for _, info := range endpoints {
+		if info.LastUsedAt >= cutoff {
+			continue
+		}
+		info.VendorName = vendorMap[info.VendorID]
+		result = append(result, info)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].LastUsedAt < result[j].LastUsedAt
+	})
+
+	return result, nil
+}
+
+// endpointLastUsed merges the last-used times recorded in the stats DB with the
+// in-memory times tracked by the executor, keyed by endpoint ID
+func (a *App) endpointLastUsed() map[int64]time.Time {
+	result := make(map[int64]time.Time)
+	if a.vendorStats != nil {
+		if persisted, err := a.vendorStats.GetLastUsedByEndpoints(a.ctx); err == nil {
+			for idStr, t := range persisted {
+				id, err := strconv.ParseInt(idStr, 10, 64)
+				if err != nil {
+					continue
+				}
+				result[id] = t
+			}
+		}
+	}
+	if a.proxyServer != nil {
+		for id, t := range a.proxyServer.EndpointLastUsed() {
+			if t.After(result[id]) {
+				result[id] = t
+			}
+		}
+	}
+	return result
+}
+
+func lastUsedMillis(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
 // GetInterfaceTypes returns the list of supported interface types
 // Requirements: 6.1
 func (a *App) GetInterfaceTypes() []string {
@@ -500,6 +860,140 @@ func (a *App) GetTransformers() map[string][]string {
 	return transformer.ListAll()
 }
 
+// RouterEndpointStateInfo represents one endpoint in the router's current routing order
+type RouterEndpointStateInfo struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	Enabled   bool   `json:"enabled"`
+	Active    bool   `json:"active"`
+	Preferred bool   `json:"preferred"`
+	// InSchedule is false while the endpoint is outside its schedule windows
+	InSchedule bool `json:"inSchedule"`
+	// TempDisabledUntil is the unix time in milliseconds until which the endpoint is skipped, 0 if not disabled
+	TempDisabledUntil int64 `json:"tempDisabledUntil,omitempty"`
+	// ManualDisabled is true when the endpoint was disabled after failures and waits to be re-enabled
+	ManualDisabled bool `json:"manualDisabled,omitempty"`
+}
+
+// RouterTypeState represents the routing state of one interface type
+type RouterTypeState struct {
+	ActiveID  int64                     `json:"activeId,omitempty"`
+	Endpoints []RouterEndpointStateInfo `json:"endpoints"`
+}
+
+// GetRouterState returns, per interface type, the endpoints in routing order with their
+// enabled/active/temp-disabled flags, for diagnosing failover behavior
+func (a *App) GetRouterState() map[string]RouterTypeState {
+	result := make(map[string]RouterTypeState)
+	for _, it := range a.GetInterfaceTypes() {
+		result[it] = RouterTypeState{Endpoints: []RouterEndpointStateInfo{}}
+	}
+	if a.router == nil {
+		return result
+	}
+
+	for interfaceType, eps := range a.router.State() {
+		state := RouterTypeState{Endpoints: make([]RouterEndpointStateInfo, 0, len(eps))}
+		for _, ep := range eps {
+			if ep.Active {
+				state.ActiveID = ep.ID
+			}
+			state.Endpoints = append(state.Endpoints, RouterEndpointStateInfo{
+				ID:                ep.ID,
+				Name:              ep.Name,
+				Enabled:           ep.Enabled,
+				Active:            ep.Active,
+				Preferred:         ep.Preferred,
+				InSchedule:        ep.InSchedule,
+				TempDisabledUntil: lastUsedMillis(ep.TempDisabledUntil),
+				ManualDisabled:    ep.ManualDisabled,
+			})
+		}
+		result[string(interfaceType)] = state
+	}
+	return result
+}
+
+// Health status levels reported by GetHealthSummary
+const (
+	HealthStatusHealthy      = "healthy"      // endpoints available, no failures
+	HealthStatusDegraded     = "degraded"     // an endpoint is failing or today's error rate is high
+	HealthStatusDown         = "down"         // endpoints configured but none available
+	HealthStatusUnconfigured = "unconfigured" // no endpoints for the interface type
+)
+
+// healthDegradedErrorRate is today's error rate from which an interface type is reported degraded
+const healthDegradedErrorRate = 0.1
+
+// InterfaceHealthInfo is the at-a-glance health of one interface type
+type InterfaceHealthInfo struct {
+	InterfaceType     string  `json:"interfaceType"`
+	ActiveEndpoint    string  `json:"activeEndpoint,omitempty"`
+	EnabledCount      int     `json:"enabledCount"`
+	TempDisabledCount int     `json:"tempDisabledCount"` // disabled after failures, including those waiting for manual re-enable
+	RequestCount      int64   `json:"requestCount"`      // today
+	ErrorCount        int64   `json:"errorCount"`        // today
+	ErrorRate         float64 `json:"errorRate"`         // today, 0..1
+	Failing           bool    `json:"failing"`           // some endpoint is currently disabled after failures
+	Status            string  `json:"status"`
+}
+
+// HealthSummary aggregates router state and today's stats per interface type
+type HealthSummary struct {
+	InterfaceTypes []InterfaceHealthInfo `json:"interfaceTypes"`
+}
+
+// GetHealthSummary returns, per interface type, the active endpoint, endpoint availability
+// and today's error rate in one call, for the dashboard's traffic-light indicator
+func (a *App) GetHealthSummary() HealthSummary {
+	var routerState map[proxy.InterfaceType][]proxy.RouterEndpointState
+	if a.router != nil {
+		routerState = a.router.State()
+	}
+	var todayStats map[string]*statsdb.EndpointDailyStats
+	if a.vendorStats != nil {
+		todayStats, _ = a.vendorStats.GetTodayStatsByEndpoints(a.ctx)
+	}
+
+	summary := HealthSummary{InterfaceTypes: make([]InterfaceHealthInfo, 0, len(a.GetInterfaceTypes()))}
+	for _, it := range a.GetInterfaceTypes() {
+		info := InterfaceHealthInfo{InterfaceType: it}
+		eps := routerState[proxy.InterfaceType(it)]
+		for _, ep := range eps {
+			if ep.Active {
+				info.ActiveEndpoint = ep.Name
+			}
+			switch {
+			case ep.ManualDisabled || !ep.TempDisabledUntil.IsZero():
+				info.TempDisabledCount++
+			case ep.Enabled:
+				info.EnabledCount++
+			}
+			if stats := todayStats[strconv.FormatInt(ep.ID, 10)]; stats != nil {
+				info.RequestCount += stats.RequestCount
+				info.ErrorCount += stats.ErrorCount
+			}
+		}
+		if info.RequestCount > 0 {
+			info.ErrorRate = float64(info.ErrorCount) / float64(info.RequestCount)
+		}
+		info.Failing = info.TempDisabledCount > 0
+
+		switch {
+		case len(eps) == 0:
+			info.Status = HealthStatusUnconfigured
+		case info.EnabledCount == 0:
+			info.Status = HealthStatusDown
+		case info.Failing || info.ErrorRate >= healthDegradedErrorRate:
+			info.Status = HealthStatusDegraded
+		default:
+			info.Status = HealthStatusHealthy
+		}
+		summary.InterfaceTypes = append(summary.InterfaceTypes, info)
+	}
+	return summary
+}
+
 // =============================================================================
 // Stats Retrieval Methods
 // Requirements: 7.2, 8.1, 8.2
@@ -512,6 +1006,7 @@ type RequestLogInfo struct {
 	InterfaceType string `json:"interfaceType"`
 	VendorName    string `json:"vendorName"`
 	EndpointName  string `json:"endpointName"`
+	Remark        string `json:"remark,omitempty"` // endpoint remark
 	Path          string `json:"path"`
 	RunTime       int64  `json:"runTime"` // milliseconds
 	Status        string `json:"status"`
@@ -531,6 +1026,60 @@ type TokenStatsInfo struct {
 	Total        int64  `json:"total"`
 }
 
+// CancelRequest aborts an in-flight request by its log ID, tearing down the upstream
+// connection. The request log entry is updated to "cancelled".
+func (a *App) CancelRequest(id string) error {
+	if a.proxyServer == nil {
+		return fmt.Errorf("proxy server not initialized")
+	}
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return fmt.Errorf("request id is required")
+	}
+	if err := a.proxyServer.CancelRequest(id); err != nil {
+		return fmt.Errorf("cancel request %s: %w", id, err)
+	}
+	return nil
+}
+
+// EnableDebugCapture records detailed executor decisions for the next count requests,
+// retrievable via GetDebugTraces. Capture disables itself afterwards; count <= 0 turns it off.
+func (a *App) EnableDebugCapture(count int) error {
+	if a.proxyServer == nil {
+		return fmt.Errorf("proxy server not initialized")
+	}
+	a.proxyServer.EnableDebugCapture(count)
+	return nil
+}
+
+// GetDebugTraces returns the traces captured since EnableDebugCapture was last called
+func (a *App) GetDebugTraces() ([]*DebugTraceInfo, error) {
+	if a.proxyServer == nil {
+		return []*DebugTraceInfo{}, nil
+	}
+
+	traces := a.proxyServer.GetDebugTraces()
+	result := make([]*DebugTraceInfo, 0, len(traces))
+	for _, t := range traces {
+		steps := make([]DebugTraceStepInfo, 0, len(t.Steps))
+		for _, step := range t.Steps {
+			steps = append(steps, DebugTraceStepInfo{OffsetMs: step.OffsetMs, Level: step.Level, Message: step.Message})
+		}
+		result = append(result, &DebugTraceInfo{
+			RequestID:     t.RequestID,
+			Method:        t.Method,
+			Path:          t.Path,
+			InterfaceType: t.InterfaceType,
+			Endpoint:      t.Endpoint,
+			StartTime:     t.StartTime.UnixMilli(),
+			DurationMs:    t.DurationMs,
+			Status:        t.Status,
+			Steps:         steps,
+		})
+	}
+	return result, nil
+}
+
 // GetRecentLogs returns the most recent request logs
 // Requirements: 7.2
 func (a *App) GetRecentLogs() ([]*RequestLogInfo, error) {
@@ -553,6 +1102,7 @@ func (a *App) GetRecentLogs() ([]*RequestLogInfo, error) {
 			InterfaceType: log.InterfaceType,
 			VendorName:    log.VendorName,
 			EndpointName:  log.EndpointName,
+			Remark:        log.Remark,
 			Path:          log.Path,
 			RunTime:       log.RunTime,
 			Status:        log.Status,
@@ -581,6 +1131,9 @@ type RequestLogDetailInfo struct {
 	RequestHeaders map[string]string `json:"requestHeaders"`
 	RequestStream  string            `json:"requestStream"`
 	ResponseStream string            `json:"responseStream"`
+	ModelFallback  string            `json:"modelFallback,omitempty"`
+	MaxTokensClamp string            `json:"maxTokensClamp,omitempty"`
+	AuthKey        string            `json:"authKey,omitempty"`
 }
 
 // GetLogDetail returns detailed information for a specific request log
@@ -614,6 +1167,9 @@ func (a *App) GetLogDetail(logID string) (*RequestLogDetailInfo, error) {
 				RequestHeaders: log.RequestHeaders,
 				RequestStream:  log.RequestStream,
 				ResponseStream: log.ResponseStream,
+				ModelFallback:  log.ModelFallback,
+				MaxTokensClamp: log.MaxTokensClamp,
+				AuthKey:        log.AuthKey,
 			}, nil
 		}
 	}
@@ -621,6 +1177,34 @@ func (a *App) GetLogDetail(logID string) (*RequestLogDetailInfo, error) {
 	return nil, fmt.Errorf("log not found: %s", logID)
 }
 
+// DeleteLog removes a single request log, including its captured request/response bodies
+func (a *App) DeleteLog(id string) error {
+	if a.proxyServer == nil {
+		return fmt.Errorf("proxy server not initialized")
+	}
+	stats := a.proxyServer.GetStats()
+	if stats == nil {
+		return fmt.Errorf("stats manager not initialized")
+	}
+	if !stats.DeleteLog(id) {
+		return fmt.Errorf("log not found: %s", id)
+	}
+	return nil
+}
+
+// ClearLogs removes all recent request logs and their captured request/response bodies
+func (a *App) ClearLogs() error {
+	if a.proxyServer == nil {
+		return fmt.Errorf("proxy server not initialized")
+	}
+	stats := a.proxyServer.GetStats()
+	if stats == nil {
+		return fmt.Errorf("stats manager not initialized")
+	}
+	stats.ClearLogs()
+	return nil
+}
+
 // GetTokenStats returns token usage statistics
 // Requirements: 8.1, 8.2
 func (a *App) GetTokenStats() ([]*TokenStatsInfo, error) {
@@ -724,6 +1308,18 @@ func (a *App) GetProxyStatus() map[string]interface{} {
 		status["running"] = true
 	}
 
+	// Stats persistence health: writes are disabled temporarily after repeated failures
+	status["statsDegraded"] = false
+	if a.vendorStats != nil {
+		ws := a.vendorStats.WriteStatus()
+		status["statsDegraded"] = ws.Degraded
+		if ws.Degraded {
+			status["statsDegradedSince"] = ws.DegradedSince.UnixMilli()
+			status["statsLastError"] = ws.LastError
+		}
+		status["statsSkipped"] = ws.Skipped
+	}
+
 	return status
 }
 
@@ -733,38 +1329,17 @@ func (a *App) ReloadConfig() error {
 		return fmt.Errorf("storage not initialized")
 	}
 
-	// Refresh router temp-disable TTL from config.json appConfig (default 5 minutes).
 	if a.router != nil {
-		tempDisableMinutes := 5
-		if v, err := a.storage.GetConfig(ConfigKeyTempDisableMinutes); err == nil && v != "" {
-			if minutes, err := strconv.Atoi(v); err == nil && minutes > 0 {
-				tempDisableMinutes = minutes
-			}
-		}
-		a.router.SetTempDisableTTL(time.Duration(tempDisableMinutes) * time.Minute)
-	}
-
-	if a.router != nil {
-		endpoints, err := a.storage.GetEndpoints()
-		if err != nil {
-			return fmt.Errorf("failed to get endpoints: %w", err)
+		endpoints, err := a.storage.GetEndpoints()
+		if err != nil {
+			return fmt.Errorf("failed to get endpoints: %w", err)
 		}
 
 		a.router.LoadEndpoints(convertEndpoints(endpoints))
 	}
 
 	// Also refresh runtime proxy settings from config.json.
-	if a.proxyServer != nil {
-		settings, err := a.GetSettings()
-		if err != nil {
-			return err
-		}
-		a.proxyServer.SetPort(settings.Port)
-		a.proxyServer.SetAuthKey(settings.APIKey)
-		a.proxyServer.SetFallbackEnabled(settings.Fallback)
-	}
-
-	return nil
+	return a.applyRuntimeConfig()
 }
 
 // =============================================================================
@@ -773,11 +1348,12 @@ func (a *App) ReloadConfig() error {
 
 // VendorInfo represents vendor information for frontend display
 type VendorInfo struct {
-	ID      int64  `json:"id"`
-	Name    string `json:"name"`
-	HomeURL string `json:"homeUrl"`
-	APIURL  string `json:"apiUrl"`
-	Remark  string `json:"remark,omitempty"`
+	ID      int64             `json:"id"`
+	Name    string            `json:"name"`
+	HomeURL string            `json:"homeUrl"`
+	APIURL  string            `json:"apiUrl"`
+	Remark  string            `json:"remark,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
 }
 
 // GetVendors returns all vendors
@@ -797,6 +1373,7 @@ func (a *App) GetVendors() ([]*VendorInfo, error) {
 			HomeURL: v.HomeURL,
 			APIURL:  v.APIURL,
 			Remark:  v.Remark,
+			Headers: v.Headers,
 		})
 	}
 	return result, nil
@@ -813,12 +1390,27 @@ func (a *App) SaveVendor(vendor *VendorInfo) (*VendorInfo, error) {
 		HomeURL: vendor.HomeURL,
 		APIURL:  vendor.APIURL,
 		Remark:  vendor.Remark,
+		Headers: vendor.Headers,
+	}
+	// 旧客户端不发送 headers 时保留已有的供应商 headers
+	if v.ID > 0 && v.Headers == nil {
+		if existing, err := a.storage.GetVendorByID(v.ID); err == nil && existing != nil {
+			v.Headers = existing.Headers
+		}
 	}
 	if err := a.storage.SaveVendor(v); err != nil {
 		return nil, err
 	}
 	vendor.ID = v.ID
 
+	// Reload endpoints into router so vendor headers take effect immediately
+	if a.router != nil {
+		endpoints, err := a.storage.GetEndpoints()
+		if err == nil {
+			a.router.LoadEndpoints(convertEndpoints(endpoints))
+		}
+	}
+
 	return vendor, nil
 }
 
@@ -827,7 +1419,18 @@ func (a *App) DeleteVendor(id int64) error {
 	if a.storage == nil {
 		return fmt.Errorf("storage not initialized")
 	}
-	return a.storage.DeleteVendor(id)
+	if err := a.storage.DeleteVendor(id); err != nil {
+		return err
+	}
+
+	// Reload endpoints into router so the vendor's headers are dropped
+	if a.router != nil {
+		endpoints, err := a.storage.GetEndpoints()
+		if err == nil {
+			a.router.LoadEndpoints(convertEndpoints(endpoints))
+		}
+	}
+	return nil
 }
 
 // GetEndpointsByVendorID returns endpoints for a specific vendor
@@ -839,23 +1442,44 @@ func (a *App) GetEndpointsByVendorID(vendorID int64) ([]*EndpointInfo, error) {
 	if err != nil {
 		return nil, err
 	}
+	lastUsed := a.endpointLastUsed()
 	result := make([]*EndpointInfo, 0, len(endpoints))
 	for _, ep := range endpoints {
 		result = append(result, &EndpointInfo{
-			ID:            ep.ID,
-			Name:          ep.Name,
-			APIURL:        ep.APIURL,
-			APIKey:        ep.APIKey,
-			Active:        ep.Active,
-			Enabled:       ep.Enabled,
-			InterfaceType: ep.InterfaceType,
-			VendorID:      ep.VendorID,
-			Model:         ep.Model,
-			Transformer:   ep.Transformer,
-			ProxyURL:      ep.ProxyURL,
-			Models:        ep.Models,
-			Remark:        ep.Remark,
-			Priority:      ep.Priority,
+			ID:                     ep.ID,
+			Name:                   ep.Name,
+			APIURL:                 ep.APIURL,
+			APIKey:                 ep.APIKey,
+			Active:                 ep.Active,
+			Enabled:                ep.Enabled,
+			InterfaceType:          ep.InterfaceType,
+			VendorID:               ep.VendorID,
+			Model:                  ep.Model,
+			Transformer:            ep.Transformer,
+			ProxyURL:               ep.ProxyURL,
+			ProxyUsername:          ep.ProxyUsername,
+			ProxyPassword:          ep.ProxyPassword,
+			ForceNonStreamUpstream: ep.ForceNonStreamUpstream,
+			FallbackToDefaultModel: ep.FallbackToDefaultModel,
+			Models:                 ep.Models,
+			Schedule:               ep.Schedule,
+			DropParams:             ep.DropParams,
+			BodyOverrides:          ep.BodyOverrides,
+			BodyForce:              ep.BodyForce,
+			IncludeReasoning:       ep.IncludeReasoning,
+			ReasoningSummary:       ep.ReasoningSummary,
+			InsecureSkipVerify:     ep.InsecureSkipVerify,
+			ForwardClientIP:        ep.ForwardClientIP,
+			Sandbox:                ep.Sandbox,
+			CAFile:                 ep.CAFile,
+			AnthropicVersion:       ep.AnthropicVersion,
+			AnthropicBeta:          ep.AnthropicBeta,
+			DailyTokenBudget:       ep.DailyTokenBudget,
+			MaxTokensCap:           ep.MaxTokensCap,
+			ResponseValidation:     ep.ResponseValidation,
+			Remark:                 ep.Remark,
+			Priority:               ep.Priority,
+			LastUsedAt:             lastUsedMillis(lastUsed[ep.ID]),
 		})
 	}
 	return result, nil
@@ -863,22 +1487,54 @@ func (a *App) GetEndpointsByVendorID(vendorID int64) ([]*EndpointInfo, error) {
 
 // EndpointInput represents endpoint input from frontend
 type EndpointInput struct {
-	ID             int64                  `json:"id"`
-	Name           string                 `json:"name"`
-	APIURL         string                 `json:"apiUrl"`
-	APIKey         string                 `json:"apiKey"`
-	Active         bool                   `json:"active"`
-	Enabled        bool                   `json:"enabled"`
-	InterfaceType  string                 `json:"interfaceType"`
-	VendorID       int64                  `json:"vendorId"`
-	Model          string                 `json:"model,omitempty"`
-	Transformer    string                 `json:"transformer,omitempty"`
-	TransformerSet bool                   `json:"transformerSet,omitempty"`
-	ProxyURL       string                 `json:"proxyUrl,omitempty"`
-	Models         []storage.ModelMapping `json:"models,omitempty"`
-	ModelsSet      bool                   `json:"modelsSet,omitempty"`
-	Remark         string                 `json:"remark,omitempty"`
-	Priority       int                    `json:"priority"`
+	ID                     int64                    `json:"id"`
+	Name                   string                   `json:"name"`
+	APIURL                 string                   `json:"apiUrl"`
+	APIKey                 string                   `json:"apiKey"`
+	Active                 bool                     `json:"active"`
+	Enabled                bool                     `json:"enabled"`
+	InterfaceType          string                   `json:"interfaceType"`
+	VendorID               int64                    `json:"vendorId"`
+	Model                  string                   `json:"model,omitempty"`
+	Transformer            string                   `json:"transformer,omitempty"`
+	TransformerSet         bool                     `json:"transformerSet,omitempty"`
+	ProxyURL               string                   `json:"proxyUrl,omitempty"`
+	ProxyUsername          string                   `json:"proxyUsername,omitempty"`
+	ProxyPassword          string                   `json:"proxyPassword,omitempty"`
+	ForceNonStreamUpstream bool                     `json:"forceNonStreamUpstream,omitempty"`
+	FallbackToDefaultModel bool                     `json:"fallbackToDefaultModel,omitempty"`
+	Models                 []storage.ModelMapping   `json:"models,omitempty"`
+	ModelsSet              bool                     `json:"modelsSet,omitempty"`
+	Headers                map[string]string        `json:"headers,omitempty"`
+	Schedule               []storage.ScheduleWindow `json:"schedule,omitempty"`
+	ScheduleSet            bool                     `json:"scheduleSet,omitempty"`
+	SandboxSet             bool                     `json:"sandboxSet,omitempty"` // sandbox was sent, allowing it to be cleared
+	TLSSet                 bool                     `json:"tlsSet,omitempty"`
+	DailyTokenBudgetSet    bool                     `json:"dailyTokenBudgetSet,omitempty"`
+	MaxTokensCapSet        bool                     `json:"maxTokensCapSet,omitempty"`
+	ReasoningSet           bool                     `json:"reasoningSet,omitempty"`
+	DropParams             []string                 `json:"dropParams,omitempty"`
+	BodyOverrides          map[string]any           `json:"bodyOverrides,omitempty"`
+	BodyForce              bool                     `json:"bodyForce,omitempty"`
+	IncludeReasoning       bool                     `json:"includeReasoning,omitempty"`
+	ReasoningSummary       string                   `json:"reasoningSummary,omitempty"`
+	InsecureSkipVerify     bool                     `json:"insecureSkipVerify,omitempty"`
+	ForwardClientIP        bool                     `json:"forwardClientIP,omitempty"`
+	Sandbox                bool                     `json:"sandbox,omitempty"`
+	CAFile                 string                   `json:"caFile,omitempty"`
+	AnthropicVersion       string                   `json:"anthropicVersion,omitempty"`
+	AnthropicBeta          string                   `json:"anthropicBeta,omitempty"`
+	DailyTokenBudget       int64                    `json:"dailyTokenBudget,omitempty"`
+	MaxTokensCap           int                      `json:"maxTokensCap,omitempty"`
+	ResponseValidation     []string                 `json:"responseValidation,omitempty"`
+	Remark                 string                   `json:"remark,omitempty"`
+	Priority               int                      `json:"priority"`
+}
+
+// DetectInterfaceTypeFromURL suggests an interface type for an API URL ("" when unknown).
+// The endpoint form uses it to prefill the type, which the user can still change.
+func (a *App) DetectInterfaceTypeFromURL(apiURL string) string {
+	return config.DetectInterfaceTypeFromURL(apiURL)
 }
 
 // SaveEndpointData creates or updates an endpoint
@@ -895,26 +1551,46 @@ func (a *App) SaveEndpointData(endpoint *EndpointInput) (*EndpointInfo, error) {
 		}
 	}
 
-	// Default priority to 5 if not set
-	priority := endpoint.Priority
-	if priority == 0 {
-		priority = 5
-	}
+	// Fill in priority and interface type from the instance-wide defaults when omitted
+	defaults := config.LoadEndpointDefaults(func(key string) string {
+		v, _ := a.storage.GetConfig(key)
+		return v
+	})
 	ep := &storage.Endpoint{
-		ID:            endpoint.ID,
-		Name:          endpoint.Name,
-		APIURL:        endpoint.APIURL,
-		APIKey:        endpoint.APIKey,
-		Active:        endpoint.Active,
-		Enabled:       endpoint.Enabled,
-		InterfaceType: endpoint.InterfaceType,
-		VendorID:      endpoint.VendorID,
-		Model:         endpoint.Model,
-		Transformer:   endpoint.Transformer,
-		ProxyURL:      endpoint.ProxyURL,
-		Models:        endpoint.Models,
-		Remark:        endpoint.Remark,
-		Priority:      priority,
+		ID:                     endpoint.ID,
+		Name:                   endpoint.Name,
+		APIURL:                 endpoint.APIURL,
+		APIKey:                 endpoint.APIKey,
+		Active:                 endpoint.Active,
+		Enabled:                endpoint.Enabled,
+		InterfaceType:          defaults.ResolveInterfaceType(endpoint.InterfaceType),
+		VendorID:               endpoint.VendorID,
+		Model:                  endpoint.Model,
+		Transformer:            endpoint.Transformer,
+		ProxyURL:               endpoint.ProxyURL,
+		ProxyUsername:          endpoint.ProxyUsername,
+		ProxyPassword:          endpoint.ProxyPassword,
+		ForceNonStreamUpstream: endpoint.ForceNonStreamUpstream,
+		FallbackToDefaultModel: endpoint.FallbackToDefaultModel,
+		Models:                 endpoint.Models,
+		Headers:                endpoint.Headers,
+		Schedule:               endpoint.Schedule,
+		DropParams:             endpoint.DropParams,
+		BodyOverrides:          endpoint.BodyOverrides,
+		BodyForce:              endpoint.BodyForce,
+		IncludeReasoning:       endpoint.IncludeReasoning,
+		ReasoningSummary:       strings.TrimSpace(endpoint.ReasoningSummary),
+		InsecureSkipVerify:     endpoint.InsecureSkipVerify,
+		ForwardClientIP:        endpoint.ForwardClientIP,
+		Sandbox:                endpoint.Sandbox,
+		CAFile:                 strings.TrimSpace(endpoint.CAFile),
+		AnthropicVersion:       strings.TrimSpace(endpoint.AnthropicVersion),
+		AnthropicBeta:          strings.TrimSpace(endpoint.AnthropicBeta),
+		DailyTokenBudget:       endpoint.DailyTokenBudget,
+		MaxTokensCap:           endpoint.MaxTokensCap,
+		ResponseValidation:     endpoint.ResponseValidation,
+		Remark:                 endpoint.Remark,
+		Priority:               defaults.ResolvePriority(endpoint.Priority),
 	}
 	if existing != nil {
 		// transformer 支持显式清空：前端会发送 transformerSet=true，
@@ -925,14 +1601,62 @@ func (a *App) SaveEndpointData(endpoint *EndpointInput) (*EndpointInfo, error) {
 		if ep.ProxyURL == "" {
 			ep.ProxyURL = existing.ProxyURL
 		}
+		if ep.ProxyUsername == "" && ep.ProxyPassword == "" {
+			ep.ProxyUsername = existing.ProxyUsername
+			ep.ProxyPassword = existing.ProxyPassword
+		}
 		if ep.Headers == nil {
 			ep.Headers = existing.Headers
 		}
+		if ep.DropParams == nil {
+			ep.DropParams = existing.DropParams
+		}
+		if ep.ResponseValidation == nil {
+			ep.ResponseValidation = existing.ResponseValidation
+		}
+		// maxTokensCapSet=true 时允许改回 0（不限制）
+		if !endpoint.MaxTokensCapSet && ep.MaxTokensCap == 0 {
+			ep.MaxTokensCap = existing.MaxTokensCap
+		}
+		// dailyTokenBudgetSet=true 时允许改回 0（不限额）
+		if !endpoint.DailyTokenBudgetSet && ep.DailyTokenBudget == 0 {
+			ep.DailyTokenBudget = existing.DailyTokenBudget
+		}
+		if ep.BodyOverrides == nil {
+			ep.BodyOverrides = existing.BodyOverrides
+			ep.BodyForce = existing.BodyForce
+		}
+		// 前端表单不含 reasoning 选项：reasoningSet=true 时按提交的值保存，
+		// 否则两者都未设置时保留原值
+		if !endpoint.ReasoningSet && !ep.IncludeReasoning && ep.ReasoningSummary == "" {
+			ep.IncludeReasoning = existing.IncludeReasoning
+			ep.ReasoningSummary = existing.ReasoningSummary
+		}
+		// TLS 选项支持显式关闭：tlsSet=true 时按提交的值保存，
+		// 只有旧客户端未发送该字段且两者都为空时才保留原值
+		if !endpoint.TLSSet && !ep.InsecureSkipVerify && ep.CAFile == "" {
+			ep.InsecureSkipVerify = existing.InsecureSkipVerify
+			ep.CAFile = existing.CAFile
+		}
 		// models 支持显式清空：前端会发送 modelsSet=true，
 		// 只有当旧客户端未发送该字段时才走“空值保留”逻辑，避免误清空。
 		if !endpoint.ModelsSet && ep.Models == nil {
 			ep.Models = existing.Models
 		}
+		// sandbox 同理：sandboxSet=true 时允许显式取消
+		if !endpoint.SandboxSet && !ep.Sandbox {
+			ep.Sandbox = existing.Sandbox
+		}
+		// schedule 同理：scheduleSet=true 时允许显式清空
+		if !endpoint.ScheduleSet && ep.Schedule == nil {
+			ep.Schedule = existing.Schedule
+		}
+	}
+	if err := proxy.ValidateSchedule(toProxySchedule(ep.Schedule)); err != nil {
+		return nil, err
+	}
+	if err := executor.ValidateReasoningSummary(ep.ReasoningSummary); err != nil {
+		return nil, err
 	}
 	if err := a.storage.SaveEndpoint(ep); err != nil {
 		return nil, err
@@ -1001,6 +1725,34 @@ func (a *App) GetLanguage() (string, error) {
 	return lang, nil
 }
 
+// LanguageInfo describes a supported UI language
+type LanguageInfo struct {
+	Code string `json:"code"`
+	Name string `json:"name"` // display name in the language itself
+}
+
+// supportedLanguages lists the UI languages in selector order
+var supportedLanguages = []LanguageInfo{
+	{Code: "en", Name: "English"},
+	{Code: "zh-CN", Name: "简体中文"},
+}
+
+// GetSupportedLanguages returns the languages the UI can be switched to
+func (a *App) GetSupportedLanguages() []LanguageInfo {
+	return append([]LanguageInfo(nil), supportedLanguages...)
+}
+
+// normalizeLanguage returns the supported language code matching lang, case-insensitively
+func normalizeLanguage(lang string) (string, bool) {
+	lang = strings.TrimSpace(lang)
+	for _, l := range supportedLanguages {
+		if strings.EqualFold(l.Code, lang) {
+			return l.Code, true
+		}
+	}
+	return "", false
+}
+
 // detectSystemLanguage detects the system language and returns a supported language code.
 // Supported languages: "en", "zh-CN"
 func detectSystemLanguage() string {
@@ -1023,12 +1775,39 @@ func detectSystemLanguage() string {
 	return "en"
 }
 
-// SetLanguage sets the language setting
+// SetLanguage sets the language setting and notifies connected UIs of the change
 func (a *App) SetLanguage(lang string) error {
 	if a.storage == nil {
 		return fmt.Errorf("storage not initialized")
 	}
-	return a.storage.SetConfig("language", lang)
+	code, ok := normalizeLanguage(lang)
+	if !ok {
+		return fmt.Errorf("unsupported language: %s", lang)
+	}
+	previous, _ := a.storage.GetConfig("language")
+	if err := a.storage.SetConfig("language", code); err != nil {
+		return err
+	}
+	if previous != code && a.wsHub != nil {
+		a.wsHub.BroadcastLanguageChanged(code)
+	}
+	return nil
+}
+
+// GetLogLevel returns the current log level (DEBUG, INFO, WARN or ERROR)
+func (a *App) GetLogLevel() string {
+	return logger.GetDefaultLevel().String()
+}
+
+// SetLogLevel changes the log level at runtime (debug, info, warn or error).
+// It is not persisted: the next start uses LOG_LEVEL or the default info level.
+func (a *App) SetLogLevel(level string) error {
+	parsed, err := logger.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	logger.SetDefaultLevel(parsed)
+	return nil
 }
 
 // GetWebSocketURL returns the WebSocket URL for real-time updates
@@ -1085,6 +1864,84 @@ type InterfaceTypeStatsSummaryInfo struct {
 	Endpoints     []EndpointStatsSummaryInfo `json:"endpoints"`
 }
 
+// UserStatsSummaryInfo represents aggregated stats for one requesting user (frontend)
+type UserStatsSummaryInfo struct {
+	UserID       string `json:"userId"`
+	InputTokens  int64  `json:"inputTokens"`
+	OutputTokens int64  `json:"outputTokens"`
+	CachedCreate int64  `json:"cachedCreate"`
+	CachedRead   int64  `json:"cachedRead"`
+	Reasoning    int64  `json:"reasoning"`
+	Total        int64  `json:"total"`
+	RequestCount int64  `json:"requestCount"`
+	ErrorCount   int64  `json:"errorCount"`
+}
+
+// ExperimentStatsSummaryInfo represents aggregated stats for one experiment group (frontend)
+type ExperimentStatsSummaryInfo struct {
+	Experiment    string  `json:"experiment"`
+	EndpointID    string  `json:"endpointId"`
+	EndpointName  string  `json:"endpointName"`
+	InputTokens   int64   `json:"inputTokens"`
+	OutputTokens  int64   `json:"outputTokens"`
+	CachedCreate  int64   `json:"cachedCreate"`
+	CachedRead    int64   `json:"cachedRead"`
+	Reasoning     int64   `json:"reasoning"`
+	Total         int64   `json:"total"`
+	RequestCount  int64   `json:"requestCount"`
+	ErrorCount    int64   `json:"errorCount"`
+	AvgDurationMs float64 `json:"avgDurationMs"`
+}
+
+// LatencyStatsSummaryInfo represents latency metrics of one endpoint (frontend)
+type LatencyStatsSummaryInfo struct {
+	InterfaceType      string  `json:"interfaceType"`
+	VendorName         string  `json:"vendorName"`
+	EndpointID         string  `json:"endpointId"`
+	EndpointName       string  `json:"endpointName"`
+	RequestCount       int64   `json:"requestCount"`
+	AvgDurationMs      float64 `json:"avgDurationMs"`
+	StreamCount        int64   `json:"streamCount"`
+	AvgTTFTMs          float64 `json:"avgTtftMs"`
+	MaxTTFTMs          int64   `json:"maxTtftMs"`
+	AvgTokensPerSecond float64 `json:"avgTokensPerSecond"`
+}
+
+// DebugTraceStepInfo is one executor decision of a captured request (frontend)
+type DebugTraceStepInfo struct {
+	OffsetMs int64  `json:"offsetMs"`
+	Level    int    `json:"level"`
+	Message  string `json:"message"`
+}
+
+// DebugTraceInfo is the step-by-step trace of a captured request (frontend)
+type DebugTraceInfo struct {
+	RequestID     string `json:"requestId"`
+	Method        string `json:"method"`
+	Path          string `json:"path"`
+	InterfaceType string `json:"interfaceType"`
+	Endpoint      string `json:"endpoint"`
+	// StartTime is the unix time in milliseconds the request started
+	StartTime  int64                `json:"startTime"`
+	DurationMs int64                `json:"durationMs"`
+	Status     string               `json:"status"`
+	Steps      []DebugTraceStepInfo `json:"steps"`
+}
+
+// FallbackEventInfo represents a persisted endpoint fallback switch (frontend)
+type FallbackEventInfo struct {
+	ID           int64  `json:"id"`
+	FromVendor   string `json:"fromVendor"`
+	FromEndpoint string `json:"fromEndpoint"`
+	ToVendor     string `json:"toVendor"`
+	ToEndpoint   string `json:"toEndpoint"`
+	Path         string `json:"path"`
+	StatusCode   int    `json:"statusCode"`
+	ErrorMessage string `json:"errorMessage"`
+	// Time is the unix time in milliseconds of the switch
+	Time int64 `json:"time"`
+}
+
 // GetTokenStatsByTimeRange returns token statistics grouped by vendor for the given time range
 func (a *App) GetTokenStatsByTimeRange(timeRange string) ([]*VendorStatsSummaryInfo, error) {
 	if a.vendorStats == nil {
@@ -1096,7 +1953,24 @@ func (a *App) GetTokenStatsByTimeRange(timeRange string) ([]*VendorStatsSummaryI
 	if err != nil {
 		return nil, fmt.Errorf("failed to get stats: %w", err)
 	}
+	return toVendorStatsSummaryInfos(stats), nil
+}
+
+// GetStatsByCustomRange returns token statistics grouped by vendor for the inclusive
+// date range [startDate, endDate] (YYYY-MM-DD)
+func (a *App) GetStatsByCustomRange(startDate, endDate string) ([]*VendorStatsSummaryInfo, error) {
+	if a.vendorStats == nil {
+		return []*VendorStatsSummaryInfo{}, nil
+	}
+
+	stats, err := a.vendorStats.GetStatsByCustomRange(a.ctx, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats: %w", err)
+	}
+	return toVendorStatsSummaryInfos(stats), nil
+}
 
+func toVendorStatsSummaryInfos(stats []statsdb.VendorStatsSummary) []*VendorStatsSummaryInfo {
 	result := make([]*VendorStatsSummaryInfo, 0, len(stats))
 	for _, s := range stats {
 		endpoints := make([]EndpointStatsSummaryInfo, 0, len(s.Endpoints))
@@ -1124,8 +1998,7 @@ func (a *App) GetTokenStatsByTimeRange(timeRange string) ([]*VendorStatsSummaryI
 			Endpoints:    endpoints,
 		})
 	}
-
-	return result, nil
+	return result
 }
 
 // GetStatsByInterfaceType returns token statistics grouped by interface type for the given time range
@@ -1174,6 +2047,132 @@ func (a *App) GetStatsByInterfaceType(timeRange string) ([]*InterfaceTypeStatsSu
 	return result, nil
 }
 
+// GetStatsByUser returns token statistics grouped by requesting user (Claude
+// metadata.user_id or OpenAI user) for the given time range. Requests without a
+// user id are grouped under an empty userId.
+func (a *App) GetStatsByUser(timeRange string) ([]*UserStatsSummaryInfo, error) {
+	if a.vendorStats == nil {
+		return []*UserStatsSummaryInfo{}, nil
+	}
+
+	tr := statsdb.TimeRange(timeRange)
+	stats, err := a.vendorStats.GetStatsByUser(a.ctx, tr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats by user: %w", err)
+	}
+
+	result := make([]*UserStatsSummaryInfo, 0, len(stats))
+	for _, s := range stats {
+		result = append(result, &UserStatsSummaryInfo{
+			UserID:       s.UserID,
+			InputTokens:  s.InputTokens,
+			OutputTokens: s.OutputTokens,
+			CachedCreate: s.CachedCreate,
+			CachedRead:   s.CachedRead,
+			Reasoning:    s.Reasoning,
+			Total:        s.Total,
+			RequestCount: s.RequestCount,
+			ErrorCount:   s.ErrorCount,
+		})
+	}
+
+	return result, nil
+}
+
+// GetStatsByExperiment returns stats of A/B experiment requests and their control group
+// for the given time range, grouped by experiment and endpoint
+func (a *App) GetStatsByExperiment(timeRange string) ([]*ExperimentStatsSummaryInfo, error) {
+	if a.vendorStats == nil {
+		return []*ExperimentStatsSummaryInfo{}, nil
+	}
+
+	tr := statsdb.TimeRange(timeRange)
+	stats, err := a.vendorStats.GetStatsByExperiment(a.ctx, tr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats by experiment: %w", err)
+	}
+
+	result := make([]*ExperimentStatsSummaryInfo, 0, len(stats))
+	for _, s := range stats {
+		result = append(result, &ExperimentStatsSummaryInfo{
+			Experiment:    s.Experiment,
+			EndpointID:    s.EndpointID,
+			EndpointName:  s.EndpointName,
+			InputTokens:   s.InputTokens,
+			OutputTokens:  s.OutputTokens,
+			CachedCreate:  s.CachedCreate,
+			CachedRead:    s.CachedRead,
+			Reasoning:     s.Reasoning,
+			Total:         s.Total,
+			RequestCount:  s.RequestCount,
+			ErrorCount:    s.ErrorCount,
+			AvgDurationMs: s.AvgDurationMs,
+		})
+	}
+
+	return result, nil
+}
+
+// GetLatencyStats returns per-endpoint latency of successful requests for the given time range,
+// including time to first token and output tokens per second of streamed responses
+func (a *App) GetLatencyStats(timeRange string) ([]*LatencyStatsSummaryInfo, error) {
+	if a.vendorStats == nil {
+		return []*LatencyStatsSummaryInfo{}, nil
+	}
+
+	stats, err := a.vendorStats.GetLatencyStats(a.ctx, statsdb.TimeRange(timeRange))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latency stats: %w", err)
+	}
+
+	result := make([]*LatencyStatsSummaryInfo, 0, len(stats))
+	for _, s := range stats {
+		result = append(result, &LatencyStatsSummaryInfo{
+			InterfaceType:      s.InterfaceType,
+			VendorName:         s.VendorName,
+			EndpointID:         s.EndpointID,
+			EndpointName:       s.EndpointName,
+			RequestCount:       s.RequestCount,
+			AvgDurationMs:      s.AvgDurationMs,
+			StreamCount:        s.StreamCount,
+			AvgTTFTMs:          s.AvgTTFTMs,
+			MaxTTFTMs:          s.MaxTTFTMs,
+			AvgTokensPerSecond: s.AvgTokensPerSecond,
+		})
+	}
+	return result, nil
+}
+
+// GetFallbackEvents returns the endpoint fallback switches recorded in the given time range,
+// newest first, so failures that happened while the UI was closed can be reviewed
+func (a *App) GetFallbackEvents(timeRange string) ([]*FallbackEventInfo, error) {
+	if a.vendorStats == nil {
+		return []*FallbackEventInfo{}, nil
+	}
+
+	events, err := a.vendorStats.GetFallbackEvents(a.ctx, statsdb.TimeRange(timeRange))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fallback events: %w", err)
+	}
+
+	result := make([]*FallbackEventInfo, 0, len(events))
+	for _, e := range events {
+		result = append(result, &FallbackEventInfo{
+			ID:           e.ID,
+			FromVendor:   e.FromVendor,
+			FromEndpoint: e.FromEndpoint,
+			ToVendor:     e.ToVendor,
+			ToEndpoint:   e.ToEndpoint,
+			Path:         e.Path,
+			StatusCode:   e.StatusCode,
+			ErrorMessage: e.ErrorMessage,
+			Time:         lastUsedMillis(e.CreateTime),
+		})
+	}
+
+	return result, nil
+}
+
 // ClearTokenStats clears token statistics for the given time range
 func (a *App) ClearTokenStats(timeRange string) error {
 	fmt.Printf("[ClearTokenStats] Called with timeRange: %s\n", timeRange)
@@ -1191,6 +2190,12 @@ func (a *App) ClearTokenStats(timeRange string) error {
 		return fmt.Errorf("failed to clear stats: %w", err)
 	}
 
+	// In-memory totals accumulate since startup and always include today, so they are
+	// reset by any range that covers today. "yesterday" leaves them untouched.
+	if a.proxyServer != nil && a.proxyServer.GetStats() != nil && tr != statsdb.TimeRangeYesterday {
+		a.proxyServer.GetStats().ResetInMemoryStats()
+	}
+
 	fmt.Println("[ClearTokenStats] Success")
 	return nil
 }
@@ -1208,6 +2213,11 @@ type TestEndpointResult struct {
 	RequestHeaders map[string]string `json:"requestHeaders,omitempty"`
 	ErrorMessage   string            `json:"errorMessage,omitempty"`
 	ResponseText   string            `json:"responseText,omitempty"`
+	// Streaming test details (only set when the test runs in streaming mode)
+	Streaming    bool     `json:"streaming,omitempty"`
+	StreamOK     bool     `json:"streamOk,omitempty"`
+	FirstTokenMs int64    `json:"firstTokenMs,omitempty"`
+	StreamEvents []string `json:"streamEvents,omitempty"`
 }
 
 // TestEndpointParams represents parameters for testing an endpoint
@@ -1217,12 +2227,14 @@ type TestEndpointParams struct {
 	InterfaceType string `json:"interfaceType"`
 	Model         string `json:"model"`
 	Reasoning     string `json:"reasoning,omitempty"`
+	// Stream runs the claude test against the SSE path and validates the event sequence
+	Stream bool `json:"stream,omitempty"`
 }
 
 // TestEndpointWithParams tests an endpoint using provided parameters (from form)
 // This allows testing with current form values before saving
 func (a *App) TestEndpointWithParams(params TestEndpointParams) string {
-	return a.doTestEndpoint(params.APIURL, params.APIKey, params.InterfaceType, params.Model, params.Reasoning)
+	return a.doTestEndpoint(params.APIURL, params.APIKey, params.InterfaceType, params.Model, params.Reasoning, params.Stream)
 }
 
 // TestEndpoint tests an endpoint by ID (uses saved values from database)
@@ -1237,16 +2249,25 @@ func (a *App) TestEndpoint(endpointID int64) string {
 		return toJSON(TestEndpointResult{Success: false, Message: fmt.Sprintf("Endpoint not found: %d", endpointID)})
 	}
 
-	return a.doTestEndpoint(ep.APIURL, ep.APIKey, ep.InterfaceType, ep.Model, "")
+	// Saved endpoints are tested the way clients call them: claude over the SSE path
+	return a.doTestEndpoint(ep.APIURL, ep.APIKey, ep.InterfaceType, ep.Model, "", true)
 }
 
-// doTestEndpoint performs the actual endpoint test
-func (a *App) doTestEndpoint(apiURL, apiKey, interfaceType, model, reasoning string) string {
+// doTestEndpoint performs the actual endpoint test.
+// When stream is true, the claude test parses the SSE response and reports time to first token.
+func (a *App) doTestEndpoint(apiURL, apiKey, interfaceType, model, reasoning string, stream bool) string {
 	// Only support claude and codex types
 	if interfaceType != "claude" && interfaceType != "codex" {
 		return toJSON(TestEndpointResult{Success: false, Message: fmt.Sprintf("Test not supported for interface type: %s", interfaceType)})
 	}
 
+	// Send the key the proxy would send (file: references are read like in the executor)
+	resolvedKey := strings.TrimSpace(executor.ResolveAPIKey(apiKey))
+	if resolvedKey == "" && strings.TrimSpace(apiKey) != "" {
+		return toJSON(TestEndpointResult{Success: false, Message: "API key could not be resolved"})
+	}
+	apiKey = resolvedKey
+
 	// Build test request based on interface type
 	var requestBody []byte
 	var apiPath string
@@ -1270,7 +2291,7 @@ func (a *App) doTestEndpoint(apiURL, apiKey, interfaceType, model, reasoning str
 					},
 				},
 			},
-			"stream": true,
+			"stream": stream,
 		})
 	case "codex":
 		apiPath = "/v1/responses"
@@ -1327,7 +2348,11 @@ func (a *App) doTestEndpoint(apiURL, apiKey, interfaceType, model, reasoning str
 	switch interfaceType {
 	case "claude":
 		req.Host = parsedTargetURL.Host
-		req.Header.Set("accept", "application/json")
+		if stream {
+			req.Header.Set("accept", "text/event-stream")
+		} else {
+			req.Header.Set("accept", "application/json")
+		}
 		req.Header.Set("accept-encoding", "gzip, deflate")
 		req.Header.Set("accept-language", "*")
 		req.Header.Set("anthropic-beta", "claude-code-20250219,interleaved-thinking-2025-05-14,fine-grained-tool-streaming-2025-05-14")
@@ -1339,7 +2364,9 @@ func (a *App) doTestEndpoint(apiURL, apiKey, interfaceType, model, reasoning str
 		req.Header.Set("user-agent", "claude-cli/2.0.0 (external, cli)")
 		req.Header.Set("x-app", "cli")
 		req.Header.Set("x-stainless-arch", "arm64")
-		req.Header.Set("x-stainless-helper-method", "stream")
+		if stream {
+			req.Header.Set("x-stainless-helper-method", "stream")
+		}
 		req.Header.Set("x-stainless-lang", "js")
 		req.Header.Set("x-stainless-os", "MacOS")
 		req.Header.Set("x-stainless-package-version", "0.60.0")
@@ -1366,12 +2393,20 @@ func (a *App) doTestEndpoint(apiURL, apiKey, interfaceType, model, reasoning str
 
 	// Send request with timeout
 	client := &http.Client{Timeout: 30 * time.Second}
+	startTime := time.Now()
 	resp, err := client.Do(req)
 	if err != nil {
 		return toJSON(TestEndpointResult{Success: false, TargetURL: targetURL, RequestHeaders: requestHeaders, Message: fmt.Sprintf("Request failed: %v", err)})
 	}
 	defer resp.Body.Close()
 
+	if interfaceType == "claude" && stream && resp.StatusCode == http.StatusOK {
+		result := checkClaudeTestStream(resp, startTime)
+		result.TargetURL = targetURL
+		result.RequestHeaders = requestHeaders
+		return toJSON(result)
+	}
+
 	// Read response
 	respBody, err := readResponseBodyLimited(resp, 256*1024)
 	if err != nil {
@@ -1430,6 +2465,142 @@ func (a *App) doTestEndpoint(apiURL, apiKey, interfaceType, model, reasoning str
 	return toJSON(TestEndpointResult{Success: true, StatusCode: resp.StatusCode, TargetURL: targetURL, RequestHeaders: requestHeaders, Message: message, ResponseText: respText})
 }
 
+// ReplayRequest re-sends a captured request log to an endpoint (0 = the endpoint that served it)
+// and returns the raw upstream response. The endpoint's current stored key is used, not the
+// masked credentials captured in the log.
+func (a *App) ReplayRequest(logID string, endpointID int64) (*TestEndpointResult, error) {
+	if a.proxyServer == nil {
+		return nil, fmt.Errorf("proxy server not initialized")
+	}
+
+	replay, err := a.proxyServer.ReplayRequest(context.Background(), logID, endpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TestEndpointResult{
+		Success:        replay.Error == nil && replay.StatusCode == http.StatusOK,
+		StatusCode:     replay.StatusCode,
+		TargetURL:      replay.TargetURL,
+		RequestHeaders: replay.RequestHeaders,
+		ResponseText:   replay.ResponseBody,
+		Message:        fmt.Sprintf("HTTP %d", replay.StatusCode),
+	}
+	if replay.Error != nil {
+		result.ErrorMessage = replay.Error.Error()
+		result.Message = fmt.Sprintf("Request failed: %v", replay.Error)
+	}
+	return result, nil
+}
+
+// checkClaudeTestStream reads a claude SSE response and verifies that
+// message_start, content_block_delta and message_stop events all arrive.
+func checkClaudeTestStream(resp *http.Response, startTime time.Time) TestEndpointResult {
+	result := TestEndpointResult{Streaming: true, StatusCode: resp.StatusCode}
+
+	var reader io.Reader = resp.Body
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			result.Message = fmt.Sprintf("Failed to read response: %v", err)
+			return result
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	var (
+		raw        strings.Builder
+		text       strings.Builder
+		gotStart   bool
+		gotDelta   bool
+		gotStop    bool
+		streamErr  string
+		seenEvents = make(map[string]bool)
+	)
+
+	scanner := bufio.NewScanner(io.LimitReader(reader, 256*1024))
+	scanner.Buffer(make([]byte, 0, 64*1024), 256*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		raw.WriteString(line)
+		raw.WriteByte('\n')
+
+		data, ok := strings.CutPrefix(strings.TrimSpace(line), "data:")
+		if !ok {
+			continue
+		}
+		var event map[string]interface{}
+		if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &event); err != nil {
+			continue
+		}
+		eventType, _ := event["type"].(string)
+		if eventType != "" && !seenEvents[eventType] {
+			seenEvents[eventType] = true
+			result.StreamEvents = append(result.StreamEvents, eventType)
+		}
+
+		switch eventType {
+		case "message_start":
+			gotStart = true
+		case "content_block_delta":
+			if !gotDelta {
+				gotDelta = true
+				result.FirstTokenMs = time.Since(startTime).Milliseconds()
+			}
+			if delta, ok := event["delta"].(map[string]interface{}); ok {
+				if t, ok := delta["text"].(string); ok {
+					text.WriteString(t)
+				}
+			}
+		case "message_stop":
+			gotStop = true
+		case "error":
+			if errObj, ok := event["error"].(map[string]interface{}); ok {
+				streamErr, _ = errObj["message"].(string)
+			}
+			if streamErr == "" {
+				streamErr = strings.TrimSpace(data)
+			}
+		}
+	}
+	result.ResponseText = raw.String()
+
+	if err := scanner.Err(); err != nil {
+		result.ErrorMessage = err.Error()
+		result.Message = fmt.Sprintf("Stream read failed: %v", err)
+		return result
+	}
+	if streamErr != "" {
+		result.ErrorMessage = streamErr
+		result.Message = fmt.Sprintf("Stream error: %s", streamErr)
+		return result
+	}
+
+	var missing []string
+	if !gotStart {
+		missing = append(missing, "message_start")
+	}
+	if !gotDelta {
+		missing = append(missing, "content_block_delta")
+	}
+	if !gotStop {
+		missing = append(missing, "message_stop")
+	}
+	if len(missing) > 0 {
+		result.Message = fmt.Sprintf("Streaming incomplete, missing events: %s", strings.Join(missing, ", "))
+		return result
+	}
+
+	result.Success = true
+	result.StreamOK = true
+	result.Message = text.String()
+	if result.Message == "" {
+		result.Message = "Streaming successful"
+	}
+	return result
+}
+
 func toJSON(v interface{}) string {
 	data, _ := json.Marshal(v)
 	return string(data)
@@ -1502,10 +2673,14 @@ func sanitizeRequestHeadersForTestLog(req *http.Request) map[string]string {
 	if host := strings.TrimSpace(req.Host); host != "" {
 		out["host"] = host
 	}
+	drop := executor.DropSensitiveHeaders()
 	for key, values := range req.Header {
 		if len(values) == 0 {
 			continue
 		}
+		if drop && executor.IsSensitiveHeader(key) {
+			continue
+		}
 		out[key] = sanitizeTestHeaderValue(key, values[0])
 	}
 	return out
@@ -1524,6 +2699,9 @@ func sanitizeTestHeaderValue(key string, value string) string {
 	if strings.EqualFold(key, "cookie") {
 		return "[redacted]"
 	}
+	if executor.IsSensitiveHeader(key) {
+		return maskSecret(value)
+	}
 	return value
 }
 
@@ -1905,6 +3083,7 @@ func (a *App) ProcessClaudeConfig(content string) (string, error) {
 	}
 	env["ANTHROPIC_AUTH_TOKEN"] = apiKey
 	env["CLAUDE_CODE_DISABLE_NONESSENTIAL_TRAFFIC"] = "1"
+	a.applyActiveClaudeModel(config, env)
 
 	// Marshal back to JSON with indentation
 	result, err := json.MarshalIndent(config, "", "  ")
@@ -1958,6 +3137,9 @@ func (a *App) ProcessCodexConfig(configToml, authJson string) (*ProcessCodexConf
 		}
 	}
 	newConfigToml := strings.Join(newLines, "\n")
+	if model, _ := a.activeEndpointModels(proxy.InterfaceTypeCodex); model != "" {
+		newConfigToml = setTOMLTopLevelString(newConfigToml, "model", model)
+	}
 
 	// Process auth.json
 	var auth map[string]interface{}
@@ -2008,6 +3190,7 @@ func (a *App) ProcessClaudeConfigWithIP(content string, ip string) (string, erro
 	}
 	env["ANTHROPIC_AUTH_TOKEN"] = apiKey
 	env["CLAUDE_CODE_DISABLE_NONESSENTIAL_TRAFFIC"] = "1"
+	a.applyActiveClaudeModel(config, env)
 
 	// Marshal back to JSON with indentation
 	result, err := json.MarshalIndent(config, "", "  ")
@@ -2054,6 +3237,9 @@ func (a *App) ProcessCodexConfigWithIP(configToml, authJson, ip string) (*Proces
 		}
 	}
 	newConfigToml := strings.Join(newLines, "\n")
+	if model, _ := a.activeEndpointModels(proxy.InterfaceTypeCodex); model != "" {
+		newConfigToml = setTOMLTopLevelString(newConfigToml, "model", model)
+	}
 
 	// Process auth.json
 	var auth map[string]interface{}
@@ -2075,6 +3261,78 @@ func (a *App) ProcessCodexConfigWithIP(configToml, authJson, ip string) (*Proces
 
 // Helper functions
 
+// claudeModelFamilyEnv maps a model family keyword to the Claude Code env var selecting its model
+var claudeModelFamilyEnv = []struct {
+	family string
+	env    string
+}{
+	{family: "opus", env: "ANTHROPIC_DEFAULT_OPUS_MODEL"},
+	{family: "sonnet", env: "ANTHROPIC_DEFAULT_SONNET_MODEL"},
+	{family: "haiku", env: "ANTHROPIC_DEFAULT_HAIKU_MODEL"},
+}
+
+// activeEndpointModels returns the model a CLI should request from the active endpoint of
+// the interface type (its Model, else its first alias) and the exact-match aliases it maps
+func (a *App) activeEndpointModels(interfaceType proxy.InterfaceType) (string, []string) {
+	if a.router == nil {
+		return "", nil
+	}
+	ep := a.router.GetActiveEndpoint(interfaceType)
+	if ep == nil {
+		return "", nil
+	}
+
+	var aliases []string
+	for _, m := range ep.Models {
+		matchType := strings.ToLower(strings.TrimSpace(m.MatchType))
+		if alias := strings.TrimSpace(m.Alias); alias != "" && (matchType == "" || matchType == executor.ModelMatchExact) {
+			aliases = append(aliases, alias)
+		}
+	}
+
+	model := strings.TrimSpace(ep.Model)
+	if model == "" && len(aliases) > 0 {
+		model = aliases[0]
+	}
+	return model, aliases
+}
+
+// applyActiveClaudeModel sets the Claude Code model to the active claude endpoint's model,
+// and points the opus/sonnet/haiku model env vars at the aliases named after each family
+func (a *App) applyActiveClaudeModel(config, env map[string]interface{}) {
+	model, aliases := a.activeEndpointModels(proxy.InterfaceTypeClaude)
+	if model == "" {
+		return
+	}
+	config["model"] = model
+	for _, f := range claudeModelFamilyEnv {
+		for _, alias := range aliases {
+			if strings.Contains(strings.ToLower(alias), f.family) {
+				env[f.env] = alias
+				break
+			}
+		}
+	}
+}
+
+// setTOMLTopLevelString sets a top-level string key of a TOML document, replacing the
+// existing assignment before the first table header or inserting it at the top
+func setTOMLTopLevelString(content, key, value string) string {
+	line := fmt.Sprintf("%s = %q", key, value)
+	lines := strings.Split(content, "\n")
+	for i, l := range lines {
+		trimmed := strings.TrimSpace(l)
+		if strings.HasPrefix(trimmed, "[") {
+			break
+		}
+		if name, _, ok := strings.Cut(trimmed, "="); ok && strings.TrimSpace(name) == key {
+			lines[i] = line
+			return strings.Join(lines, "\n")
+		}
+	}
+	return line + "\n" + content
+}
+
 func readFileContent(path string) (string, bool) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -2140,18 +3398,20 @@ func (a *App) getDefaultClaudeSettings() string {
 	}
 	proxyURL := fmt.Sprintf("http://127.0.0.1:%d", settings.Port)
 
+	env := map[string]interface{}{
+		"ANTHROPIC_AUTH_TOKEN":                     apiKey,
+		"ANTHROPIC_BASE_URL":                       proxyURL,
+		"CLAUDE_CODE_DISABLE_NONESSENTIAL_TRAFFIC": "1",
+	}
 	config := map[string]interface{}{
-		"env": map[string]string{
-			"ANTHROPIC_AUTH_TOKEN":                     apiKey,
-			"ANTHROPIC_BASE_URL":                       proxyURL,
-			"CLAUDE_CODE_DISABLE_NONESSENTIAL_TRAFFIC": "1",
-		},
+		"env": env,
 		"permissions": map[string]interface{}{
 			"allow": []string{"Bash(ls :*)"},
 			"deny":  []string{},
 		},
 		"alwaysThinkingEnabled": true,
 	}
+	a.applyActiveClaudeModel(config, env)
 
 	data, _ := json.MarshalIndent(config, "", "  ")
 	return string(data)
@@ -2160,9 +3420,13 @@ func (a *App) getDefaultClaudeSettings() string {
 func (a *App) getDefaultCodexConfig() string {
 	settings, _ := a.GetSettings()
 	proxyURL := fmt.Sprintf("http://127.0.0.1:%d/v1", settings.Port)
+	model, _ := a.activeEndpointModels(proxy.InterfaceTypeCodex)
+	if model == "" {
+		model = "gpt-5.2"
+	}
 
 	return fmt.Sprintf(`disable_response_storage = true
-model = "gpt-5.2"
+model = %q
 model_provider = 'local'
 model_reasoning_effort = "high"
 
@@ -2170,7 +3434,7 @@ model_reasoning_effort = "high"
 name = 'local'
 base_url = '%s'
 requires_openai_auth = true
-wire_api = 'responses'`, proxyURL)
+wire_api = 'responses'`, model, proxyURL)
 }
 
 func (a *App) getDefaultCodexAuth() string {
@@ -2236,18 +3500,21 @@ func (a *App) GetFullConfig() (*FullConfig, error) {
 		}
 
 		endpoints = append(endpoints, &EndpointInfo{
-			ID:            ep.ID,
-			Name:          ep.Name,
-			APIURL:        ep.APIURL,
-			APIKey:        ep.APIKey,
-			Active:        ep.Active,
-			Enabled:       ep.Enabled,
-			InterfaceType: ep.InterfaceType,
-			VendorID:      ep.VendorID,
-			VendorName:    vendorName,
-			Model:         ep.Model,
-			Remark:        ep.Remark,
-			Priority:      ep.Priority,
+			ID:               ep.ID,
+			Name:             ep.Name,
+			APIURL:           ep.APIURL,
+			APIKey:           ep.APIKey,
+			Active:           ep.Active,
+			Enabled:          ep.Enabled,
+			InterfaceType:    ep.InterfaceType,
+			VendorID:         ep.VendorID,
+			VendorName:       vendorName,
+			Model:            ep.Model,
+			Remark:           ep.Remark,
+			Priority:         ep.Priority,
+			AnthropicVersion: ep.AnthropicVersion,
+			AnthropicBeta:    ep.AnthropicBeta,
+			ForwardClientIP:  ep.ForwardClientIP,
 		})
 	}
 
@@ -2276,27 +3543,7 @@ func (a *App) SaveFullConfig(config *FullConfig) error {
 
 	// Save app settings
 	if config.AppConfig != nil {
-		settings := &Settings{
-			Port:     5600, // Default
-			APIKey:   "",
-			Fallback: false,
-		}
-
-		if port, ok := config.AppConfig["port"].(float64); ok {
-			settings.Port = int(port)
-		} else if port, ok := config.AppConfig["port"].(int); ok {
-			settings.Port = port
-		}
-
-		if apiKey, ok := config.AppConfig["apiKey"].(string); ok {
-			settings.APIKey = apiKey
-		}
-
-		if fallback, ok := config.AppConfig["fallback"].(bool); ok {
-			settings.Fallback = fallback
-		}
-
-		if err := a.SaveSettings(settings); err != nil {
+		if err := a.SaveSettings(settingsFromBackup(config.AppConfig)); err != nil {
 			return fmt.Errorf("failed to save settings: %w", err)
 		}
 	}
@@ -2307,26 +3554,26 @@ func (a *App) SaveFullConfig(config *FullConfig) error {
 
 	// Save vendors
 	if config.Vendors != nil {
-		// First, get existing vendors to avoid duplicates
-		existingVendors, err := a.storage.GetVendors()
+		// First, index existing vendors to avoid duplicates
+		index, err := a.loadRestoreIndex()
 		if err != nil {
-			return fmt.Errorf("failed to get existing vendors: %w", err)
-		}
-
-		// Create a map of existing vendors by name for lookup
-		existingVendorMap := make(map[string]*storage.Vendor)
-		for _, v := range existingVendors {
-			existingVendorMap[v.Name] = v
+			return err
 		}
 
 		for _, v := range config.Vendors {
+			if v == nil {
+				continue
+			}
 			// Check if vendor already exists
-			if existing, exists := existingVendorMap[v.Name]; exists {
+			if existing, exists := index.vendorByName[v.Name]; exists {
 				// Update existing vendor
 				existing.Name = v.Name
 				existing.HomeURL = v.HomeURL
 				existing.APIURL = v.APIURL
 				existing.Remark = v.Remark
+				if v.Headers != nil {
+					existing.Headers = v.Headers
+				}
 				if err := a.storage.SaveVendor(existing); err != nil {
 					return fmt.Errorf("failed to update vendor %s: %w", v.Name, err)
 				}
@@ -2337,6 +3584,7 @@ func (a *App) SaveFullConfig(config *FullConfig) error {
 					HomeURL: v.HomeURL,
 					APIURL:  v.APIURL,
 					Remark:  v.Remark,
+					Headers: v.Headers,
 				}
 				if err := a.storage.SaveVendor(newVendor); err != nil {
 					return fmt.Errorf("failed to save vendor %s: %w", v.Name, err)
@@ -2347,47 +3595,24 @@ func (a *App) SaveFullConfig(config *FullConfig) error {
 
 	// Save endpoints
 	if config.Endpoints != nil {
-		// Get updated vendors to map names to IDs
-		vendors, err := a.storage.GetVendors()
-		if err != nil {
-			return fmt.Errorf("failed to get vendors: %w", err)
-		}
-
-		vendorNameToID := make(map[string]int64)
-		for _, v := range vendors {
-			vendorNameToID[v.Name] = v.ID
-		}
-
-		// Get existing endpoints to avoid duplicates
-		existingEndpoints, err := a.storage.GetEndpoints()
+		// Re-index so vendors created above map names to their new IDs
+		index, err := a.loadRestoreIndex()
 		if err != nil {
-			return fmt.Errorf("failed to get existing endpoints: %w", err)
-		}
-
-		// Create a map for existing endpoints by name+vendor
-		existingEndpointMap := make(map[string]*storage.Endpoint)
-		for _, ep := range existingEndpoints {
-			var vendorName string
-			for _, v := range vendors {
-				if v.ID == ep.VendorID {
-					vendorName = v.Name
-					break
-				}
-			}
-			key := fmt.Sprintf("%s-%s", vendorName, ep.Name)
-			existingEndpointMap[key] = ep
+			return err
 		}
 
 		for _, ep := range config.Endpoints {
-			vendorID, ok := vendorNameToID[ep.VendorName]
+			if ep == nil {
+				continue
+			}
+			vendor, ok := index.vendorByName[ep.VendorName]
 			if !ok {
 				return fmt.Errorf("vendor not found: %s", ep.VendorName)
 			}
-
-			key := fmt.Sprintf("%s-%s", ep.VendorName, ep.Name)
+			vendorID := vendor.ID
 
 			// Check if endpoint already exists
-			if existing, exists := existingEndpointMap[key]; exists {
+			if existing, exists := index.endpointByKey[restoreKey{vendor: ep.VendorName, name: ep.Name}]; exists {
 				// Update existing endpoint
 				existing.Name = ep.Name
 				existing.APIURL = ep.APIURL
@@ -2399,22 +3624,28 @@ func (a *App) SaveFullConfig(config *FullConfig) error {
 				existing.Model = ep.Model
 				existing.Remark = ep.Remark
 				existing.Priority = ep.Priority
+				existing.AnthropicVersion = ep.AnthropicVersion
+				existing.AnthropicBeta = ep.AnthropicBeta
+				existing.ForwardClientIP = ep.ForwardClientIP
 				if err := a.storage.UpdateEndpoint(existing); err != nil {
 					return fmt.Errorf("failed to update endpoint %s: %w", ep.Name, err)
 				}
 			} else {
 				// Create new endpoint
 				newEndpoint := &storage.Endpoint{
-					Name:          ep.Name,
-					APIURL:        ep.APIURL,
-					APIKey:        ep.APIKey,
-					Active:        ep.Active,
-					Enabled:       ep.Enabled,
-					InterfaceType: ep.InterfaceType,
-					VendorID:      vendorID,
-					Model:         ep.Model,
-					Remark:        ep.Remark,
-					Priority:      ep.Priority,
+					Name:             ep.Name,
+					APIURL:           ep.APIURL,
+					APIKey:           ep.APIKey,
+					Active:           ep.Active,
+					Enabled:          ep.Enabled,
+					InterfaceType:    ep.InterfaceType,
+					VendorID:         vendorID,
+					Model:            ep.Model,
+					Remark:           ep.Remark,
+					Priority:         ep.Priority,
+					AnthropicVersion: ep.AnthropicVersion,
+					AnthropicBeta:    ep.AnthropicBeta,
+					ForwardClientIP:  ep.ForwardClientIP,
 				}
 				if err := a.storage.SaveEndpoint(newEndpoint); err != nil {
 					return fmt.Errorf("failed to save endpoint %s: %w", ep.Name, err)
@@ -2431,6 +3662,582 @@ func (a *App) SaveFullConfig(config *FullConfig) error {
 	return nil
 }
 
+// settingsFromBackup reads the settings a backup restores, with defaults for missing keys
+func settingsFromBackup(appConfig map[string]interface{}) *Settings {
+	settings := &Settings{
+		Port:     5600, // Default
+		APIKey:   "",
+		Fallback: false,
+	}
+
+	if port, ok := appConfig["port"].(float64); ok {
+		settings.Port = int(port)
+	} else if port, ok := appConfig["port"].(int); ok {
+		settings.Port = port
+	}
+
+	if apiKey, ok := appConfig["apiKey"].(string); ok {
+		settings.APIKey = apiKey
+	}
+
+	if fallback, ok := appConfig["fallback"].(bool); ok {
+		settings.Fallback = fallback
+	}
+	return settings
+}
+
+// restoreKey identifies an endpoint across backups by vendor name and endpoint name
+type restoreKey struct {
+	vendor string
+	name   string
+}
+
+// restoreIndex matches backup entries to stored vendors (by name) and endpoints
+// (by vendor and endpoint name). SaveFullConfig and PreviewFullConfigRestore both
+// use it so the preview always reflects what a restore does.
+type restoreIndex struct {
+	vendors        []*storage.Vendor
+	vendorByName   map[string]*storage.Vendor
+	vendorNameByID map[int64]string
+	endpoints      []*storage.Endpoint
+	endpointByKey  map[restoreKey]*storage.Endpoint
+}
+
+func (a *App) loadRestoreIndex() (*restoreIndex, error) {
+	vendors, err := a.storage.GetVendors()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get existing vendors: %w", err)
+	}
+	endpoints, err := a.storage.GetEndpoints()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get existing endpoints: %w", err)
+	}
+
+	index := &restoreIndex{
+		vendors:        vendors,
+		vendorByName:   make(map[string]*storage.Vendor, len(vendors)),
+		vendorNameByID: make(map[int64]string, len(vendors)),
+		endpoints:      endpoints,
+		endpointByKey:  make(map[restoreKey]*storage.Endpoint, len(endpoints)),
+	}
+	for _, v := range vendors {
+		index.vendorByName[v.Name] = v
+		index.vendorNameByID[v.ID] = v.Name
+	}
+	for _, ep := range endpoints {
+		index.endpointByKey[index.endpointKey(ep)] = ep
+	}
+	return index, nil
+}
+
+// endpointKey returns the key a backup entry must have to match the stored endpoint
+func (idx *restoreIndex) endpointKey(ep *storage.Endpoint) restoreKey {
+	return restoreKey{vendor: idx.vendorNameByID[ep.VendorID], name: ep.Name}
+}
+
+// RestoreDiffItem describes one vendor or endpoint affected by a configuration restore
+type RestoreDiffItem struct {
+	Kind          string   `json:"kind"` // "vendor" or "endpoint"
+	Name          string   `json:"name"`
+	VendorName    string   `json:"vendorName,omitempty"`
+	InterfaceType string   `json:"interfaceType,omitempty"`
+	Changes       []string `json:"changes,omitempty"` // changed fields, for updates
+	APIKeyChanged bool     `json:"apiKeyChanged,omitempty"`
+}
+
+// RestoreDiff describes what SaveFullConfig would change, without writing anything
+type RestoreDiff struct {
+	SettingsChanges    []string          `json:"settingsChanges,omitempty"`
+	ProxyAPIKeyChanged bool              `json:"proxyApiKeyChanged"`
+	Added              []RestoreDiffItem `json:"added"`
+	Updated            []RestoreDiffItem `json:"updated"`
+	Orphaned           []RestoreDiffItem `json:"orphaned"` // existing entries not in the backup, left untouched
+	Unchanged          int               `json:"unchanged"`
+	APIKeysChanged     int               `json:"apiKeysChanged"` // endpoints whose API key would change
+}
+
+// PreviewFullConfigRestore computes what SaveFullConfig would add, update or leave
+// orphaned for the given backup, using the same name-based matching, so the UI can
+// ask for confirmation before restoring
+func (a *App) PreviewFullConfigRestore(config *FullConfig) (*RestoreDiff, error) {
+	if a.storage == nil {
+		return nil, fmt.Errorf("storage not initialized")
+	}
+	if config == nil {
+		return nil, fmt.Errorf("config is nil")
+	}
+
+	diff := &RestoreDiff{
+		Added:    []RestoreDiffItem{},
+		Updated:  []RestoreDiffItem{},
+		Orphaned: []RestoreDiffItem{},
+	}
+
+	if config.AppConfig != nil {
+		current, err := a.GetSettings()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get settings: %w", err)
+		}
+		restored := settingsFromBackup(config.AppConfig)
+		if restored.Port != current.Port {
+			diff.SettingsChanges = append(diff.SettingsChanges, "port")
+		}
+		if restored.APIKey != current.APIKey {
+			diff.SettingsChanges = append(diff.SettingsChanges, "apiKey")
+			diff.ProxyAPIKeyChanged = true
+		}
+		if restored.Fallback != current.Fallback {
+			diff.SettingsChanges = append(diff.SettingsChanges, "fallback")
+		}
+	}
+
+	index, err := a.loadRestoreIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	// Vendors referenced by the backup (existing or about to be created)
+	knownVendors := make(map[string]bool, len(index.vendors))
+	for name := range index.vendorByName {
+		knownVendors[name] = true
+	}
+
+	if config.Vendors != nil {
+		inBackup := make(map[string]bool, len(config.Vendors))
+		for _, v := range config.Vendors {
+			if v == nil {
+				continue
+			}
+			inBackup[v.Name] = true
+			knownVendors[v.Name] = true
+			item := RestoreDiffItem{Kind: "vendor", Name: v.Name}
+
+			existing, exists := index.vendorByName[v.Name]
+			if !exists {
+				diff.Added = append(diff.Added, item)
+				continue
+			}
+			if existing.HomeURL != v.HomeURL {
+				item.Changes = append(item.Changes, "homeUrl")
+			}
+			if existing.APIURL != v.APIURL {
+				item.Changes = append(item.Changes, "apiUrl")
+			}
+			if existing.Remark != v.Remark {
+				item.Changes = append(item.Changes, "remark")
+			}
+			if v.Headers != nil && !maps.Equal(existing.Headers, v.Headers) {
+				item.Changes = append(item.Changes, "headers")
+			}
+			if len(item.Changes) == 0 {
+				diff.Unchanged++
+				continue
+			}
+			diff.Updated = append(diff.Updated, item)
+		}
+		for _, v := range index.vendors {
+			if !inBackup[v.Name] {
+				diff.Orphaned = append(diff.Orphaned, RestoreDiffItem{Kind: "vendor", Name: v.Name})
+			}
+		}
+	}
+
+	if config.Endpoints != nil {
+		inBackup := make(map[restoreKey]bool, len(config.Endpoints))
+		for _, ep := range config.Endpoints {
+			if ep == nil {
+				continue
+			}
+			if !knownVendors[ep.VendorName] {
+				return nil, fmt.Errorf("vendor not found: %s", ep.VendorName)
+			}
+			key := restoreKey{vendor: ep.VendorName, name: ep.Name}
+			inBackup[key] = true
+			item := RestoreDiffItem{Kind: "endpoint", Name: ep.Name, VendorName: ep.VendorName, InterfaceType: ep.InterfaceType}
+
+			existing, exists := index.endpointByKey[key]
+			if !exists {
+				item.APIKeyChanged = ep.APIKey != ""
+				if item.APIKeyChanged {
+					diff.APIKeysChanged++
+				}
+				diff.Added = append(diff.Added, item)
+				continue
+			}
+			if existing.APIURL != ep.APIURL {
+				item.Changes = append(item.Changes, "apiUrl")
+			}
+			if existing.APIKey != ep.APIKey {
+				item.Changes = append(item.Changes, "apiKey")
+				item.APIKeyChanged = true
+				diff.APIKeysChanged++
+			}
+			if existing.Active != ep.Active {
+				item.Changes = append(item.Changes, "active")
+			}
+			if existing.Enabled != ep.Enabled {
+				item.Changes = append(item.Changes, "enabled")
+			}
+			if existing.InterfaceType != ep.InterfaceType {
+				item.Changes = append(item.Changes, "interfaceType")
+			}
+			if existing.Model != ep.Model {
+				item.Changes = append(item.Changes, "model")
+			}
+			if existing.Remark != ep.Remark {
+				item.Changes = append(item.Changes, "remark")
+			}
+			if existing.Priority != ep.Priority {
+				item.Changes = append(item.Changes, "priority")
+			}
+			if existing.AnthropicVersion != ep.AnthropicVersion {
+				item.Changes = append(item.Changes, "anthropicVersion")
+			}
+			if existing.AnthropicBeta != ep.AnthropicBeta {
+				item.Changes = append(item.Changes, "anthropicBeta")
+			}
+			if existing.ForwardClientIP != ep.ForwardClientIP {
+				item.Changes = append(item.Changes, "forwardClientIP")
+			}
+			if len(item.Changes) == 0 {
+				diff.Unchanged++
+				continue
+			}
+			diff.Updated = append(diff.Updated, item)
+		}
+		for _, ep := range index.endpoints {
+			key := index.endpointKey(ep)
+			if !inBackup[key] {
+				diff.Orphaned = append(diff.Orphaned, RestoreDiffItem{
+					Kind:          "endpoint",
+					Name:          ep.Name,
+					VendorName:    key.vendor,
+					InterfaceType: ep.InterfaceType,
+				})
+			}
+		}
+	}
+
+	return diff, nil
+}
+
+// ExportConfigToFile writes the complete stored configuration (config.json as kept by the
+// store) to path as pretty JSON. When includeAPIKeys is false, every secret-bearing value is
+// replaced with "[redacted]" so the file can be shared safely: endpoint API keys, proxy
+// passwords (including credentials in proxy URLs), sensitive vendor/endpoint headers and
+// the secret appConfig keys.
+func (a *App) ExportConfigToFile(path string, includeAPIKeys bool) error {
+	if a.storage == nil {
+		return fmt.Errorf("storage not initialized")
+	}
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return fmt.Errorf("export path is required")
+	}
+
+	cfg, err := a.storage.GetAppConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if !includeAPIKeys {
+		redactConfigSecrets(cfg)
+	}
+
+	data, err := cfg.ToJSON()
+	if err != nil {
+		return fmt.Errorf("failed to serialize config: %w", err)
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// ImportConfigFromFile reads a configuration exported by ExportConfigToFile, validates it,
+// replaces the stored config with it and reloads the router. Redacted values (sanitized
+// exports) keep the secrets already configured for the same appConfig key, vendor or
+// endpoint (matched by vendor and endpoint name); optional ones without a match are dropped.
+func (a *App) ImportConfigFromFile(path string) error {
+	if a.storage == nil {
+		return fmt.Errorf("storage not initialized")
+	}
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return fmt.Errorf("import path is required")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	cfg, err := config.ParseJSON(data)
+	if err != nil {
+		return fmt.Errorf("invalid config file: %w", err)
+	}
+	if cfg.AppConfigKV == nil && cfg.Vendors == nil {
+		return fmt.Errorf("config file is empty")
+	}
+
+	existing, err := a.storage.GetAppConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := restoreRedactedSecrets(cfg, existing); err != nil {
+		return err
+	}
+	if err := validateStoredConfig(cfg); err != nil {
+		return err
+	}
+	if err := a.storage.ReplaceAppConfig(cfg); err != nil {
+		return fmt.Errorf("failed to import config: %w", err)
+	}
+	return a.ReloadConfig()
+}
+
+// redactedSecret replaces secret values in sanitized config exports
+const redactedSecret = "[redacted]"
+
+// secretAppConfigKeys are the appConfig keys whose whole value is a secret
+var secretAppConfigKeys = []string{ConfigKeyAPIKey, ConfigKeyAuthKeys, ConfigKeyNotifyWebhookURL, ConfigKeyWebDAVPassword}
+
+// redactConfigSecrets replaces every secret-bearing value in cfg with redactedSecret
+func redactConfigSecrets(cfg *config.AppConfig) {
+	for _, key := range secretAppConfigKeys {
+		if v, ok := cfg.AppConfigKV[key]; ok && v != "" && v != nil {
+			cfg.AppConfigKV[key] = redactedSecret
+		}
+	}
+	for vi := range cfg.Vendors {
+		v := &cfg.Vendors[vi]
+		redactSensitiveHeaders(v.Headers)
+		for ei := range v.Endpoints {
+			ep := &v.Endpoints[ei]
+			if ep.APIKey != "" {
+				ep.APIKey = redactedSecret
+			}
+			if ep.ProxyPassword != "" {
+				ep.ProxyPassword = redactedSecret
+			}
+			ep.ProxyURL = redactURLPassword(ep.ProxyURL)
+			redactSensitiveHeaders(ep.Headers)
+		}
+	}
+}
+
+func redactSensitiveHeaders(headers map[string]string) {
+	for name, value := range headers {
+		if value != "" && executor.IsSensitiveHeader(name) {
+			headers[name] = redactedSecret
+		}
+	}
+}
+
+// redactURLPassword replaces the password in a URL's user info, if any
+func redactURLPassword(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.User == nil {
+		return raw
+	}
+	if _, ok := u.User.Password(); !ok {
+		return raw
+	}
+	u.User = url.UserPassword(u.User.Username(), redactedSecret)
+	return u.String()
+}
+
+// restoreRedactedSecrets fills the redacted values of an imported config from existing.
+// Redacted API keys without a matching endpoint are an error, since every endpoint needs one.
+func restoreRedactedSecrets(cfg, existing *config.AppConfig) error {
+	for _, key := range secretAppConfigKeys {
+		if cfg.AppConfigKV[key] != redactedSecret {
+			continue
+		}
+		if v, ok := existing.AppConfigKV[key]; ok {
+			cfg.AppConfigKV[key] = v
+		} else {
+			delete(cfg.AppConfigKV, key)
+		}
+	}
+
+	vendors := make(map[string]*config.VendorConfig, len(existing.Vendors))
+	endpoints := make(map[restoreKey]*config.EndpointConfig)
+	for vi := range existing.Vendors {
+		v := &existing.Vendors[vi]
+		vendors[v.Name] = v
+		for ei := range v.Endpoints {
+			endpoints[restoreKey{vendor: v.Name, name: v.Endpoints[ei].Name}] = &v.Endpoints[ei]
+		}
+	}
+
+	for vi := range cfg.Vendors {
+		v := &cfg.Vendors[vi]
+		var oldVendorHeaders map[string]string
+		if old := vendors[v.Name]; old != nil {
+			oldVendorHeaders = old.Headers
+		}
+		restoreRedactedHeaders(v.Headers, oldVendorHeaders)
+		for ei := range v.Endpoints {
+			ep := &v.Endpoints[ei]
+			old := endpoints[restoreKey{vendor: v.Name, name: ep.Name}]
+			if old == nil {
+				old = &config.EndpointConfig{}
+			}
+			if ep.APIKey == redactedSecret {
+				if old.APIKey == "" {
+					return fmt.Errorf("vendors[%d].endpoints[%d] %s: API key is redacted; fill it in before importing", vi, ei, ep.Name)
+				}
+				ep.APIKey = old.APIKey
+			}
+			if ep.ProxyPassword == redactedSecret {
+				ep.ProxyPassword = old.ProxyPassword
+			}
+			ep.ProxyURL = restoreURLPassword(ep.ProxyURL, old.ProxyURL)
+			restoreRedactedHeaders(ep.Headers, old.Headers)
+		}
+	}
+	return nil
+}
+
+func restoreRedactedHeaders(headers, existing map[string]string) {
+	for name, value := range headers {
+		if value != redactedSecret {
+			continue
+		}
+		if old, ok := existing[name]; ok {
+			headers[name] = old
+		} else {
+			delete(headers, name)
+		}
+	}
+}
+
+// restoreURLPassword puts back the password redactURLPassword removed, taken from existing
+// when it has one, or drops it
+func restoreURLPassword(raw, existing string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.User == nil {
+		return raw
+	}
+	if password, ok := u.User.Password(); !ok || password != redactedSecret {
+		return raw
+	}
+	if old, err := url.Parse(existing); err == nil && old.User != nil {
+		if password, ok := old.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), password)
+			return u.String()
+		}
+	}
+	u.User = url.User(u.User.Username())
+	return u.String()
+}
+
+// maxConfigSnapshotSize bounds the decompressed size of an applied config snapshot
+const maxConfigSnapshotSize = 32 << 20
+
+// GetConfigSnapshot returns the complete stored configuration (config.json as kept by the
+// store: every endpoint field, API keys and all appConfig keys) as gzipped JSON, plus the hex
+// SHA-256 checksum of the compressed bytes. The output is deterministic, so an unchanged
+// config yields the same checksum.
+func (a *App) GetConfigSnapshot() ([]byte, string, error) {
+	if a.storage == nil {
+		return nil, "", fmt.Errorf("storage not initialized")
+	}
+	cfg, err := a.storage.GetAppConfig()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load config: %w", err)
+	}
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to serialize config: %w", err)
+	}
+
+	var buf bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to compress config: %w", err)
+	}
+	if _, err := zw.Write(data); err != nil {
+		return nil, "", fmt.Errorf("failed to compress config: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to compress config: %w", err)
+	}
+
+	snapshot := buf.Bytes()
+	sum := sha256.Sum256(snapshot)
+	return snapshot, hex.EncodeToString(sum[:]), nil
+}
+
+// ApplyConfigSnapshot verifies a snapshot made by GetConfigSnapshot against its checksum,
+// validates it, then replaces the stored config with it and reloads the running proxy
+func (a *App) ApplyConfigSnapshot(data []byte, checksum string) error {
+	if a.storage == nil {
+		return fmt.Errorf("storage not initialized")
+	}
+	if len(data) == 0 {
+		return fmt.Errorf("config snapshot is empty")
+	}
+	sum := sha256.Sum256(data)
+	if !strings.EqualFold(strings.TrimSpace(checksum), hex.EncodeToString(sum[:])) {
+		return fmt.Errorf("config snapshot checksum mismatch")
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("invalid config snapshot: %w", err)
+	}
+	defer zr.Close()
+	raw, err := io.ReadAll(io.LimitReader(zr, maxConfigSnapshotSize+1))
+	if err != nil {
+		return fmt.Errorf("invalid config snapshot: %w", err)
+	}
+	if len(raw) > maxConfigSnapshotSize {
+		return fmt.Errorf("config snapshot exceeds %d bytes", maxConfigSnapshotSize)
+	}
+
+	cfg, err := config.ParseJSON(raw)
+	if err != nil {
+		return fmt.Errorf("invalid config snapshot: %w", err)
+	}
+	if err := validateStoredConfig(cfg); err != nil {
+		return err
+	}
+	if err := a.storage.ReplaceAppConfig(cfg); err != nil {
+		return fmt.Errorf("failed to apply config snapshot: %w", err)
+	}
+	return a.ReloadConfig()
+}
+
+// validateStoredConfig checks a whole config (as in config.json) before it replaces the stored one
+func validateStoredConfig(cfg *config.AppConfig) error {
+	if port, ok := cfg.AppConfigKV[ConfigKeyPort].(float64); ok {
+		if err := config.ValidatePort(int(port)); err != nil {
+			return err
+		}
+	}
+	if t, ok := cfg.AppConfigKV[config.AppConfigKeyDefaultInterfaceType].(string); ok {
+		if err := config.ValidateDefaultInterfaceType(t); err != nil {
+			return err
+		}
+	}
+	for i, v := range cfg.Vendors {
+		if strings.TrimSpace(v.Name) == "" {
+			return fmt.Errorf("vendors[%d]: vendor name is required", i)
+		}
+		for j := range v.Endpoints {
+			if errs := config.ValidateEndpoint(&v.Endpoints[j]); len(errs) > 0 {
+				return fmt.Errorf("vendors[%d].endpoints[%d] %s: %w", i, j, v.Endpoints[j].Name, errs[0])
+			}
+		}
+	}
+	return nil
+}
+
 // GetComputerName returns the computer name for backup identification
 func (a *App) GetComputerName() (string, error) {
 	hostname, err := os.Hostname()
@@ -2449,9 +4256,10 @@ var webdavProxy = proxy.NewWebDAVProxy()
 
 // WebDAVConfigInput represents WebDAV configuration from frontend
 type WebDAVConfigInput struct {
-	ServerURL string `json:"serverUrl"`
-	Username  string `json:"username"`
-	Password  string `json:"password"`
+	ServerURL       string `json:"serverUrl"`
+	Username        string `json:"username"`
+	Password        string `json:"password"`
+	AutoSyncMinutes int    `json:"autoSyncMinutes"` // config snapshot auto-sync interval, 0 = off
 }
 
 // WebDAVRequestInput represents a WebDAV request from frontend
@@ -2465,16 +4273,86 @@ type WebDAVRequestInput struct {
 	Depth    string            `json:"depth,omitempty"`    // For PROPFIND operations
 }
 
+// SaveWebDAVConfig persists the WebDAV credentials so they only need to be entered once.
+// The password is encrypted at rest when CLISHUB_SECRET is set.
+func (a *App) SaveWebDAVConfig(input WebDAVConfigInput) error {
+	if a.storage == nil {
+		return fmt.Errorf("storage not initialized")
+	}
+
+	if input.AutoSyncMinutes < 0 {
+		return fmt.Errorf("invalid auto-sync interval: must not be negative")
+	}
+	password, err := config.EncryptSecret(input.Password)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt WebDAV password: %w", err)
+	}
+	values := []struct{ key, value string }{
+		{ConfigKeyWebDAVServerURL, strings.TrimSpace(input.ServerURL)},
+		{ConfigKeyWebDAVUsername, strings.TrimSpace(input.Username)},
+		{ConfigKeyWebDAVPassword, password},
+		{ConfigKeyWebDAVAutoSyncMinutes, strconv.Itoa(input.AutoSyncMinutes)},
+	}
+	for _, v := range values {
+		if err := a.storage.SetConfig(v.key, v.value); err != nil {
+			return fmt.Errorf("failed to save %s: %w", v.key, err)
+		}
+	}
+	return nil
+}
+
+// GetWebDAVConfig returns the saved WebDAV credentials with the password decrypted
+func (a *App) GetWebDAVConfig() (*WebDAVConfigInput, error) {
+	if a.storage == nil {
+		return nil, fmt.Errorf("storage not initialized")
+	}
+
+	serverURL, err := a.storage.GetConfig(ConfigKeyWebDAVServerURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get WebDAV config: %w", err)
+	}
+	username, _ := a.storage.GetConfig(ConfigKeyWebDAVUsername)
+	stored, _ := a.storage.GetConfig(ConfigKeyWebDAVPassword)
+	password, err := config.DecryptSecret(stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt WebDAV password: %w", err)
+	}
+	autoSyncStr, _ := a.storage.GetConfig(ConfigKeyWebDAVAutoSyncMinutes)
+	autoSyncMinutes, _ := strconv.Atoi(autoSyncStr)
+
+	return &WebDAVConfigInput{
+		ServerURL:       serverURL,
+		Username:        username,
+		Password:        password,
+		AutoSyncMinutes: max(autoSyncMinutes, 0),
+	}, nil
+}
+
+// resolveWebDAVConfig uses the credentials sent by the caller, or the saved ones when no server URL is given
+func (a *App) resolveWebDAVConfig(input WebDAVConfigInput) (*proxy.WebDAVConfig, error) {
+	if strings.TrimSpace(input.ServerURL) == "" && a.storage != nil {
+		saved, err := a.GetWebDAVConfig()
+		if err != nil {
+			return nil, err
+		}
+		input = *saved
+	}
+	return &proxy.WebDAVConfig{
+		ServerURL: input.ServerURL,
+		Username:  input.Username,
+		Password:  input.Password,
+	}, nil
+}
+
 // WebDAVProxyRequest proxies a generic WebDAV request
 func (a *App) WebDAVProxyRequest(input *WebDAVRequestInput) (*proxy.WebDAVResponse, error) {
 	if input == nil {
 		return nil, fmt.Errorf("input is required")
 	}
 
-	config := &proxy.WebDAVConfig{
-		ServerURL: input.Config.ServerURL,
-		Username:  input.Config.Username,
-		Password:  input.Config.Password,
+	config, err := a.resolveWebDAVConfig(input.Config)
+	if err != nil {
+		return nil, err
 	}
 
 	var body io.Reader
@@ -2491,10 +4369,9 @@ func (a *App) WebDAVList(input *WebDAVRequestInput) (*proxy.WebDAVResponse, erro
 		return nil, fmt.Errorf("input is required")
 	}
 
-	config := &proxy.WebDAVConfig{
-		ServerURL: input.Config.ServerURL,
-		Username:  input.Config.Username,
-		Password:  input.Config.Password,
+	config, err := a.resolveWebDAVConfig(input.Config)
+	if err != nil {
+		return nil, err
 	}
 
 	return webdavProxy.List(config, input.Path, input.Depth)
@@ -2506,10 +4383,9 @@ func (a *App) WebDAVGet(input *WebDAVRequestInput) (*proxy.WebDAVResponse, error
 		return nil, fmt.Errorf("input is required")
 	}
 
-	config := &proxy.WebDAVConfig{
-		ServerURL: input.Config.ServerURL,
-		Username:  input.Config.Username,
-		Password:  input.Config.Password,
+	config, err := a.resolveWebDAVConfig(input.Config)
+	if err != nil {
+		return nil, err
 	}
 
 	return webdavProxy.Get(config, input.Path)
@@ -2521,10 +4397,9 @@ func (a *App) WebDAVPut(input *WebDAVRequestInput) (*proxy.WebDAVResponse, error
 		return nil, fmt.Errorf("input is required")
 	}
 
-	config := &proxy.WebDAVConfig{
-		ServerURL: input.Config.ServerURL,
-		Username:  input.Config.Username,
-		Password:  input.Config.Password,
+	config, err := a.resolveWebDAVConfig(input.Config)
+	if err != nil {
+		return nil, err
 	}
 
 	return webdavProxy.Put(config, input.Path, input.Body)
@@ -2536,10 +4411,9 @@ func (a *App) WebDAVDelete(input *WebDAVRequestInput) (*proxy.WebDAVResponse, er
 		return nil, fmt.Errorf("input is required")
 	}
 
-	config := &proxy.WebDAVConfig{
-		ServerURL: input.Config.ServerURL,
-		Username:  input.Config.Username,
-		Password:  input.Config.Password,
+	config, err := a.resolveWebDAVConfig(input.Config)
+	if err != nil {
+		return nil, err
 	}
 
 	return webdavProxy.Delete(config, input.Path)
@@ -2551,10 +4425,9 @@ func (a *App) WebDAVMkcol(input *WebDAVRequestInput) (*proxy.WebDAVResponse, err
 		return nil, fmt.Errorf("input is required")
 	}
 
-	config := &proxy.WebDAVConfig{
-		ServerURL: input.Config.ServerURL,
-		Username:  input.Config.Username,
-		Password:  input.Config.Password,
+	config, err := a.resolveWebDAVConfig(input.Config)
+	if err != nil {
+		return nil, err
 	}
 
 	return webdavProxy.Mkcol(config, input.Path)
@@ -2566,10 +4439,9 @@ func (a *App) WebDAVMove(input *WebDAVRequestInput) (*proxy.WebDAVResponse, erro
 		return nil, fmt.Errorf("input is required")
 	}
 
-	config := &proxy.WebDAVConfig{
-		ServerURL: input.Config.ServerURL,
-		Username:  input.Config.Username,
-		Password:  input.Config.Password,
+	config, err := a.resolveWebDAVConfig(input.Config)
+	if err != nil {
+		return nil, err
 	}
 
 	return webdavProxy.Move(config, input.Path, input.DestPath)
@@ -2581,15 +4453,188 @@ func (a *App) WebDAVCopy(input *WebDAVRequestInput) (*proxy.WebDAVResponse, erro
 		return nil, fmt.Errorf("input is required")
 	}
 
-	config := &proxy.WebDAVConfig{
-		ServerURL: input.Config.ServerURL,
-		Username:  input.Config.Username,
-		Password:  input.Config.Password,
+	config, err := a.resolveWebDAVConfig(input.Config)
+	if err != nil {
+		return nil, err
 	}
 
 	return webdavProxy.Copy(config, input.Path, input.DestPath)
 }
 
+// WebDAVTestResult represents the result of a WebDAV credentials check
+type WebDAVTestResult struct {
+	Success    bool   `json:"success"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	Latency    int64  `json:"latency"` // milliseconds
+	Message    string `json:"message"`
+}
+
+// WebDAVTestConnection checks the server URL and credentials with a Depth 0 PROPFIND on the root,
+// so the settings UI can validate them before saving
+func (a *App) WebDAVTestConnection(config WebDAVConfigInput) (*WebDAVTestResult, error) {
+	if strings.TrimSpace(config.ServerURL) == "" {
+		return &WebDAVTestResult{Message: "Server URL is required"}, nil
+	}
+
+	start := time.Now()
+	resp, err := webdavProxy.List(&proxy.WebDAVConfig{
+		ServerURL: config.ServerURL,
+		Username:  config.Username,
+		Password:  config.Password,
+	}, "", "0")
+	latency := time.Since(start).Milliseconds()
+	if err != nil {
+		return &WebDAVTestResult{Latency: latency, Message: fmt.Sprintf("Invalid server URL: %v", err)}, nil
+	}
+
+	result := &WebDAVTestResult{StatusCode: resp.StatusCode, Latency: latency}
+	switch {
+	case resp.StatusCode == 0:
+		result.Message = fmt.Sprintf("Cannot connect to server, check the URL: %s", resp.Error)
+	case resp.StatusCode == http.StatusMultiStatus || (resp.StatusCode >= 200 && resp.StatusCode < 300):
+		result.Success = true
+		result.Message = "Connection successful"
+	case resp.StatusCode == http.StatusUnauthorized:
+		result.Message = "Authentication failed, check the username and password"
+	case resp.StatusCode == http.StatusForbidden:
+		result.Message = "Access denied, the account has no permission for this path"
+	case resp.StatusCode == http.StatusNotFound:
+		result.Message = "Path not found, check the server URL"
+	case resp.StatusCode == http.StatusMethodNotAllowed:
+		result.Message = "Server does not support WebDAV at this URL"
+	default:
+		result.Message = fmt.Sprintf("Unexpected response: HTTP %d", resp.StatusCode)
+	}
+	return result, nil
+}
+
+// ConfigSnapshotSyncResult reports the outcome of SyncConfigSnapshot
+type ConfigSnapshotSyncResult struct {
+	Checksum string `json:"checksum"`
+	Size     int    `json:"size"`
+	Uploaded bool   `json:"uploaded"` // false when the remote checksum already matched
+}
+
+// configSnapshotChecksumPath returns the path of the checksum file stored next to a snapshot
+func configSnapshotChecksumPath(path string) string {
+	return path + ".sha256"
+}
+
+// SyncConfigSnapshot uploads the config snapshot to path on the WebDAV server, with its
+// checksum stored next to it in path+".sha256". When the remote checksum already matches,
+// the upload is skipped, so automated sync only transfers the snapshot after a change.
+// An empty server URL uses the credentials saved by SaveWebDAVConfig.
+func (a *App) SyncConfigSnapshot(config WebDAVConfigInput, path string) (*ConfigSnapshotSyncResult, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, fmt.Errorf("snapshot path is required")
+	}
+	snapshot, checksum, err := a.GetConfigSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	result := &ConfigSnapshotSyncResult{Checksum: checksum, Size: len(snapshot)}
+
+	cfg, err := a.resolveWebDAVConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	checksumPath := configSnapshotChecksumPath(path)
+	if resp, err := webdavProxy.Get(cfg, checksumPath); err == nil && resp.StatusCode == http.StatusOK && strings.TrimSpace(resp.Body) == checksum {
+		return result, nil
+	}
+
+	// Create the parent collection; servers answer 405 when it already exists
+	if i := strings.LastIndex(path, "/"); i > 0 {
+		_, _ = webdavProxy.Mkcol(cfg, path[:i])
+	}
+
+	// Upload the snapshot first so the checksum never points at a missing or older snapshot
+	for _, upload := range []struct{ path, content string }{
+		{path, string(snapshot)},
+		{checksumPath, checksum},
+	} {
+		resp, err := webdavProxy.Put(cfg, upload.path, upload.content)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			if resp.Error != "" {
+				return nil, fmt.Errorf("failed to upload %s: %s", upload.path, resp.Error)
+			}
+			return nil, fmt.Errorf("failed to upload %s: HTTP %d", upload.path, resp.StatusCode)
+		}
+	}
+	result.Uploaded = true
+	return result, nil
+}
+
+// configSnapshotAutoSyncPath is where the auto-sync loop uploads the config snapshot
+const configSnapshotAutoSyncPath = "/clisimplehub/config.snapshot.gz"
+
+// configSnapshotAutoSyncPoll is how often the auto-sync loop rechecks its interval setting
+const configSnapshotAutoSyncPoll = time.Minute
+
+// runConfigSnapshotAutoSync uploads the config snapshot to the saved WebDAV server every
+// webdavAutoSyncMinutes until ctx is done. SyncConfigSnapshot skips the upload while the
+// remote checksum matches, so an unchanged config costs one small download per interval.
+func (a *App) runConfigSnapshotAutoSync(ctx context.Context) {
+	ticker := time.NewTicker(configSnapshotAutoSyncPoll)
+	defer ticker.Stop()
+
+	var lastSync time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			saved, err := a.GetWebDAVConfig()
+			if err != nil || saved.ServerURL == "" || saved.AutoSyncMinutes <= 0 {
+				continue
+			}
+			if now.Sub(lastSync) < time.Duration(saved.AutoSyncMinutes)*time.Minute {
+				continue
+			}
+			lastSync = now
+			result, err := a.SyncConfigSnapshot(*saved, configSnapshotAutoSyncPath)
+			if err != nil {
+				fmt.Printf("[WebDAV] config snapshot auto-sync failed: %v\n", err)
+			} else if result.Uploaded {
+				fmt.Printf("[WebDAV] config snapshot uploaded (%d bytes, sha256 %s)\n", result.Size, result.Checksum)
+			}
+		}
+	}
+}
+
+// RestoreConfigSnapshot downloads a snapshot uploaded by SyncConfigSnapshot and applies it
+// after verifying it against the stored checksum
+func (a *App) RestoreConfigSnapshot(config WebDAVConfigInput, path string) error {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return fmt.Errorf("snapshot path is required")
+	}
+	cfg, err := a.resolveWebDAVConfig(config)
+	if err != nil {
+		return err
+	}
+
+	var bodies [2]string
+	for i, p := range []string{path, configSnapshotChecksumPath(path)} {
+		resp, err := webdavProxy.Get(cfg, p)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode != http.StatusOK {
+			if resp.Error != "" {
+				return fmt.Errorf("failed to download %s: %s", p, resp.Error)
+			}
+			return fmt.Errorf("failed to download %s: HTTP %d", p, resp.StatusCode)
+		}
+		bodies[i] = resp.Body
+	}
+	return a.ApplyConfigSnapshot([]byte(bodies[0]), bodies[1])
+}
+
 // =============================================================================
 // Endpoint Ping/Speed Test Methods
 // =============================================================================
@@ -2613,12 +4658,12 @@ func (a *App) PingEndpoint(endpointID int64) (*PingResult, error) {
 		return &PingResult{EndpointID: endpointID, Success: false, Error: "endpoint not found"}, nil
 	}
 
-	return a.doPingURL(endpointID, ep.APIURL, ep.ProxyURL), nil
+	return a.doPingURL(endpointID, ep.APIURL, pingProxyConfig(ep)), nil
 }
 
 // PingEndpointByURL tests the HTTP connection speed to a specific URL
 func (a *App) PingEndpointByURL(apiURL string) (*PingResult, error) {
-	return a.doPingURL(0, apiURL, ""), nil
+	return a.doPingURL(0, apiURL, nil), nil
 }
 
 // PingAllEndpoints tests the HTTP connection speed to all endpoints of a given interface type
@@ -2634,15 +4679,26 @@ func (a *App) PingAllEndpoints(interfaceType string) ([]*PingResult, error) {
 
 	results := make([]*PingResult, 0, len(endpoints))
 	for _, ep := range endpoints {
-		result := a.doPingURL(ep.ID, ep.APIURL, ep.ProxyURL)
+		result := a.doPingURL(ep.ID, ep.APIURL, pingProxyConfig(ep))
 		results = append(results, result)
 	}
 
 	return results, nil
 }
 
+// pingProxyConfig extracts the proxy settings of an endpoint for ping requests
+func pingProxyConfig(ep *storage.Endpoint) *executor.EndpointConfig {
+	return &executor.EndpointConfig{
+		ProxyURL:               ep.ProxyURL,
+		ProxyUsername:          ep.ProxyUsername,
+		ProxyPassword:          ep.ProxyPassword,
+		ForceNonStreamUpstream: ep.ForceNonStreamUpstream,
+		FallbackToDefaultModel: ep.FallbackToDefaultModel,
+	}
+}
+
 // doPingURL performs the actual HTTP HEAD/GET request to measure latency
-func (a *App) doPingURL(endpointID int64, apiURL, proxyURL string) *PingResult {
+func (a *App) doPingURL(endpointID int64, apiURL string, proxyCfg *executor.EndpointConfig) *PingResult {
 	result := &PingResult{EndpointID: endpointID, Success: false}
 
 	// Normalize URL
@@ -2665,22 +4721,10 @@ func (a *App) doPingURL(endpointID int64, apiURL, proxyURL string) *PingResult {
 	// Use just the scheme and host for ping
 	pingURL := fmt.Sprintf("%s://%s", parsedURL.Scheme, parsedURL.Host)
 
-	// Create HTTP client with timeout
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			return http.ErrUseLastResponse // Don't follow redirects
-		},
-	}
-
-	// Configure proxy if specified
-	if proxyURL != "" {
-		proxyParsed, err := url.Parse(proxyURL)
-		if err == nil {
-			client.Transport = &http.Transport{
-				Proxy: http.ProxyURL(proxyParsed),
-			}
-		}
+	// Create HTTP client with timeout (uses the endpoint proxy and its credentials if specified)
+	client := executor.NewHTTPClient(proxyCfg, 10*time.Second)
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse // Don't follow redirects
 	}
 
 	// Create HEAD request (lighter than GET)
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"clisimplehub/internal/proxy"
+)
+
+// curlValueFlags are curl options that consume the following argument
+var curlValueFlags = map[string]bool{
+	"-H": true, "--header": true,
+	"-X": true, "--request": true,
+	"-d": true, "--data": true, "--data-raw": true, "--data-binary": true, "--data-ascii": true, "--data-urlencode": true, "--json": true,
+	"-u": true, "--user": true,
+	"-x": true, "--proxy": true, "-U": true, "--proxy-user": true,
+	"-A": true, "--user-agent": true,
+	"-e": true, "--referer": true,
+	"-b": true, "--cookie": true, "-c": true, "--cookie-jar": true,
+	"-o": true, "--output": true, "-w": true, "--write-out": true,
+	"-m": true, "--max-time": true, "--connect-timeout": true,
+	"--url": true, "-F": true, "--form": true, "--resolve": true,
+}
+
+// headers that are set by the proxy itself and should not be pinned on the endpoint
+var curlIgnoredHeaders = map[string]bool{
+	"content-type":   true,
+	"content-length": true,
+	"accept":         true,
+	"host":           true,
+	"user-agent":     true,
+}
+
+// curlRequest is the subset of a curl invocation relevant to endpoint import
+type curlRequest struct {
+	Method    string
+	URL       string
+	Headers   [][2]string
+	Body      string
+	Proxy     string
+	ProxyAuth string
+}
+
+// ParseEndpointFromCurl builds a pre-filled endpoint from a curl command (as copied
+// from provider docs) or from a bare API base URL. The result is not saved.
+func (a *App) ParseEndpointFromCurl(curl string) (*EndpointInput, error) {
+	var router *proxy.DefaultRouter
+	if a != nil {
+		router = a.router
+	}
+	return parseEndpointFromCurl(curl, router)
+}
+
+func parseEndpointFromCurl(input string, router *proxy.DefaultRouter) (*EndpointInput, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil, fmt.Errorf("empty curl command")
+	}
+
+	var req *curlRequest
+	if strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://") {
+		req = &curlRequest{URL: input}
+	} else {
+		args, err := splitCurlArgs(input)
+		if err != nil {
+			return nil, err
+		}
+		if req, err = parseCurlArgs(args); err != nil {
+			return nil, err
+		}
+	}
+
+	u, err := url.Parse(req.URL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid url %q", req.URL)
+	}
+
+	interfaceType := inferCurlInterfaceType(u, router)
+	ep := &EndpointInput{
+		Name:          u.Hostname(),
+		APIURL:        curlBaseURL(u),
+		Enabled:       true,
+		InterfaceType: interfaceType,
+	}
+
+	// gemini passes the key as a query parameter
+	if key := strings.TrimSpace(u.Query().Get("key")); key != "" {
+		ep.APIKey = key
+	}
+	for _, h := range req.Headers {
+		name, value := strings.TrimSpace(h[0]), strings.TrimSpace(h[1])
+		switch lower := strings.ToLower(name); {
+		case lower == "authorization":
+			if token, ok := strings.CutPrefix(value, "Bearer "); ok {
+				ep.APIKey = strings.TrimSpace(token)
+			} else if token, ok := strings.CutPrefix(value, "bearer "); ok {
+				ep.APIKey = strings.TrimSpace(token)
+			} else {
+				ep.APIKey = value
+			}
+		case lower == "x-api-key" || lower == "api-key" || lower == "x-goog-api-key":
+			ep.APIKey = value
+		case curlIgnoredHeaders[lower] || name == "":
+		default:
+			if ep.Headers == nil {
+				ep.Headers = make(map[string]string)
+			}
+			ep.Headers[name] = value
+		}
+	}
+
+	if req.Proxy != "" {
+		ep.ProxyURL = req.Proxy
+		if user, pass, ok := strings.Cut(req.ProxyAuth, ":"); ok {
+			ep.ProxyUsername, ep.ProxyPassword = user, pass
+		}
+	}
+
+	if req.Body != "" {
+		var body struct {
+			Model string `json:"model"`
+		}
+		if json.Unmarshal([]byte(req.Body), &body) == nil {
+			ep.Model = strings.TrimSpace(body.Model)
+		}
+	}
+	return ep, nil
+}
+
+// inferCurlInterfaceType detects the interface type from the request path. A bare base
+// URL (no API resource in the path) is treated as OpenAI-compatible unless it is Gemini.
+func inferCurlInterfaceType(u *url.URL, router *proxy.DefaultRouter) string {
+	path := strings.ToLower(u.Path)
+	host := strings.ToLower(u.Hostname())
+	if strings.Contains(host, "generativelanguage.googleapis.com") || strings.Contains(path, ":generatecontent") || strings.Contains(path, ":streamgeneratecontent") {
+		return string(proxy.InterfaceTypeGemini)
+	}
+	// a bare base URL or the model list carries no hint about the request format
+	if curlResourceIndex(u.Path) < 0 || strings.HasSuffix(strings.TrimRight(path, "/"), "/models") {
+		if strings.Contains(path, "/gemini") {
+			return string(proxy.InterfaceTypeGemini)
+		}
+		if strings.Contains(host, "anthropic") {
+			return string(proxy.InterfaceTypeClaude)
+		}
+		return string(proxy.InterfaceTypeChat)
+	}
+	if router == nil {
+		router = proxy.NewRouter()
+	}
+	return string(router.DetectInterfaceType(u.Path))
+}
+
+// curlResourceSuffixes are API resource paths stripped from the URL to get the endpoint base
+var curlResourceSuffixes = []string{"/chat/completions", "/messages", "/responses", "/completions", "/models"}
+
+// curlResourceIndex returns where the API resource starts in path, or -1 for a base URL
+func curlResourceIndex(path string) int {
+	lower := strings.TrimRight(strings.ToLower(path), "/")
+	// gemini: /v1beta/models/{model}:generateContent
+	if i := strings.Index(lower, "/models/"); i >= 0 && strings.Contains(lower[i:], ":") {
+		return i
+	}
+	for _, suffix := range curlResourceSuffixes {
+		if strings.HasSuffix(lower, suffix) {
+			return len(lower) - len(suffix)
+		}
+	}
+	return -1
+}
+
+// curlBaseURL strips the query and the API resource path, keeping any version prefix
+func curlBaseURL(u *url.URL) string {
+	base := *u
+	base.RawQuery = ""
+	base.Fragment = ""
+	base.User = nil
+	if i := curlResourceIndex(u.Path); i >= 0 {
+		base.Path = u.Path[:i]
+	}
+	base.Path = strings.TrimRight(base.Path, "/")
+	base.RawPath = ""
+	return base.String()
+}
+
+func parseCurlArgs(args []string) (*curlRequest, error) {
+	if len(args) == 0 || !strings.EqualFold(strings.TrimSuffix(args[0], ".exe"), "curl") {
+		return nil, fmt.Errorf("not a curl command")
+	}
+
+	req := &curlRequest{}
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		flag, value, hasValue := arg, "", false
+		switch {
+		case strings.HasPrefix(arg, "--"):
+			if f, v, ok := strings.Cut(arg, "="); ok {
+				flag, value, hasValue = f, v, true
+			}
+		case strings.HasPrefix(arg, "-") && len(arg) > 2 && curlValueFlags[arg[:2]]:
+			// attached short option value, e.g. -XPOST or -H'Key: v'
+			flag, value, hasValue = arg[:2], arg[2:], true
+		}
+
+		if !strings.HasPrefix(flag, "-") {
+			if req.URL == "" {
+				req.URL = arg
+			}
+			continue
+		}
+		if !curlValueFlags[flag] {
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("missing value for %s", flag)
+			}
+			i++
+			value = args[i]
+		}
+
+		switch flag {
+		case "-H", "--header":
+			if name, v, ok := strings.Cut(value, ":"); ok {
+				req.Headers = append(req.Headers, [2]string{name, v})
+			}
+		case "-X", "--request":
+			req.Method = strings.ToUpper(strings.TrimSpace(value))
+		case "-d", "--data", "--data-raw", "--data-binary", "--data-ascii", "--json":
+			req.Body = value
+		case "-x", "--proxy":
+			req.Proxy = value
+		case "-U", "--proxy-user":
+			req.ProxyAuth = value
+		case "--url":
+			req.URL = value
+		}
+	}
+
+	if req.URL == "" {
+		return nil, fmt.Errorf("no url in curl command")
+	}
+	if !strings.Contains(req.URL, "://") {
+		req.URL = "https://" + req.URL
+	}
+	return req, nil
+}
+
+// splitCurlArgs tokenizes a shell command line: single and double quotes, $'...'
+// strings, backslash escapes and line continuations (\ on POSIX shells, ^ and ` on Windows).
+func splitCurlArgs(s string) ([]string, error) {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	var args []string
+	var cur strings.Builder
+	inArg := false
+	flush := func() {
+		if inArg {
+			args = append(args, cur.String())
+			cur.Reset()
+			inArg = false
+		}
+	}
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case (c == '\\' || c == '^' || c == '`') && i+1 < len(runes) && runes[i+1] == '\n':
+			i++ // line continuation
+		case c == ' ' || c == '\t' || c == '\n':
+			flush()
+		case c == '\\' && i+1 < len(runes):
+			i++
+			cur.WriteRune(runes[i])
+			inArg = true
+		case c == '\'':
+			end := indexRune(runes, i+1, '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+			cur.WriteString(string(runes[i+1 : end]))
+			i = end
+			inArg = true
+		case c == '$' && i+1 < len(runes) && runes[i+1] == '\'':
+			i += 2
+			for ; i < len(runes) && runes[i] != '\''; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+					switch runes[i] {
+					case 'n':
+						cur.WriteRune('\n')
+					case 't':
+						cur.WriteRune('\t')
+					default:
+						cur.WriteRune(runes[i])
+					}
+					continue
+				}
+				cur.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated quote")
+			}
+			inArg = true
+		case c == '"':
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`\n", runes[i+1]) {
+					i++
+					if runes[i] == '\n' {
+						continue
+					}
+				}
+				cur.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated double quote")
+			}
+			inArg = true
+		default:
+			cur.WriteRune(c)
+			inArg = true
+		}
+	}
+	flush()
+	return args, nil
+}
+
+func indexRune(runes []rune, from int, r rune) int {
+	for i := from; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitCurlArgs(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{name: "quotes", in: `curl -H 'x-api-key: k' "https://a.example/v1"`, want: []string{"curl", "-H", "x-api-key: k", "https://a.example/v1"}},
+		{name: "posix continuation", in: "curl \\\n  -d '{\"a\":1}' \\\r\n  https://a.example", want: []string{"curl", "-d", `{"a":1}`, "https://a.example"}},
+		{name: "windows continuations", in: "curl ^\n -X POST `\n https://a.example", want: []string{"curl", "-X", "POST", "https://a.example"}},
+		{name: "ansi-c quoting", in: `curl -d $'a\nb\'c'`, want: []string{"curl", "-d", "a\nb'c"}},
+		{name: "escapes in double quotes", in: `curl -d "{\"m\":\"\$x\"}"`, want: []string{"curl", "-d", `{"m":"$x"}`}},
+		{name: "unterminated single quote", in: `curl 'abc`, wantErr: true},
+		{name: "unterminated double quote", in: `curl "abc`, wantErr: true},
+	}
+	for _, tc := range cases {
+		got, err := splitCurlArgs(tc.in)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("%s: err=%v wantErr=%v", tc.name, err, tc.wantErr)
+		}
+		if !tc.wantErr && !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("%s: got %q want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestParseEndpointFromCurl(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		in   string
+		want EndpointInput
+	}{
+		{
+			name: "anthropic messages",
+			in: `curl https://api.anthropic.com/v1/messages \
+  -H "x-api-key: sk-ant" \
+  -H "anthropic-version: 2023-06-01" \
+  -H "content-type: application/json" \
+  -d '{"model":"claude-sonnet-4","max_tokens":16,"messages":[]}'`,
+			want: EndpointInput{Name: "api.anthropic.com", APIURL: "https://api.anthropic.com/v1", APIKey: "sk-ant", Model: "claude-sonnet-4", InterfaceType: "claude", Enabled: true, Headers: map[string]string{"anthropic-version": "2023-06-01"}},
+		},
+		{
+			name: "chat completions with bearer token and proxy",
+			in:   `curl -XPOST --url=https://gw.example/openai/v1/chat/completions -H 'Authorization: Bearer sk-1' -x http://127.0.0.1:8080 -U user:pass --json '{"model":"gpt-x"}'`,
+			want: EndpointInput{Name: "gw.example", APIURL: "https://gw.example/openai/v1", APIKey: "sk-1", Model: "gpt-x", InterfaceType: "chat", Enabled: true, ProxyURL: "http://127.0.0.1:8080", ProxyUsername: "user", ProxyPassword: "pass"},
+		},
+		{
+			name: "gemini key in query",
+			in:   `curl "https://generativelanguage.googleapis.com/v1beta/models/gemini-pro:generateContent?key=g-key"`,
+			want: EndpointInput{Name: "generativelanguage.googleapis.com", APIURL: "https://generativelanguage.googleapis.com/v1beta", APIKey: "g-key", InterfaceType: "gemini", Enabled: true},
+		},
+		{
+			name: "bare base url",
+			in:   "https://api.example.com/v1/",
+			want: EndpointInput{Name: "api.example.com", APIURL: "https://api.example.com/v1", InterfaceType: "chat", Enabled: true},
+		},
+		{
+			name: "model list on an anthropic host",
+			in:   `curl api.anthropic.com/v1/models -H "x-api-key: k"`,
+			want: EndpointInput{Name: "api.anthropic.com", APIURL: "https://api.anthropic.com/v1", APIKey: "k", InterfaceType: "claude", Enabled: true},
+		},
+	}
+	for _, tc := range cases {
+		got, err := parseEndpointFromCurl(tc.in, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if !reflect.DeepEqual(*got, tc.want) {
+			t.Fatalf("%s:\n got %+v\nwant %+v", tc.name, *got, tc.want)
+		}
+	}
+
+	for _, in := range []string{"", "wget https://a.example", "curl -H 'a: b'", "curl -H"} {
+		if _, err := parseEndpointFromCurl(in, nil); err == nil {
+			t.Fatalf("%q: expected error", in)
+		}
+	}
+}
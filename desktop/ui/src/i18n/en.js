@@ -44,6 +44,7 @@ export default {
         startTime: 'Start Time',
         targetUrl: 'Target URL',
         upstreamAuth: 'Upstream Auth (masked)',
+        modelFallback: 'Model Fallback',
         requestStream: 'Request Stream',
         responseStream: 'Response Stream',
         requestHeaders: 'Request Headers',
@@ -170,6 +171,17 @@ export default {
         proxyUrl: 'Proxy URL',
         proxyUrlPlaceholder: 'e.g., socks5://proxy.example.com:1080',
         proxyUrlHelp: 'Optional, use proxy to access upstream API',
+        forceNonStream: 'Force non-streaming upstream',
+        forceNonStreamHelp: 'Call the upstream without streaming and replay the full response as a stream to the client',
+        fallbackToDefaultModel: 'Fall back to default model',
+        fallbackToDefaultModelHelp: 'When the upstream rejects the requested model, retry once with this endpoint\'s default model',
+        forwardClientIP: 'Forward client IP',
+        forwardClientIPHelp: 'Send the client\'s IP upstream in X-Forwarded-For / X-Real-IP. Some providers reject unexpected X-Forwarded-For',
+        anthropicVersion: 'anthropic-version',
+        anthropicVersionPlaceholder: 'e.g., 2023-06-01',
+        anthropicBeta: 'anthropic-beta',
+        anthropicBetaPlaceholder: 'e.g., prompt-caching-2024-07-31',
+        anthropicHeadersHelp: 'Optional, overrides the client\'s headers when forwarding to a Claude upstream',
         transformer: 'Transformer',
         transformerPlaceholder: 'Select transformer',
         transformerHelp: 'Transform requests to another API format',
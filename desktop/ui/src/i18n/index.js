@@ -8,6 +8,12 @@ const translations = {
 
 let currentLanguage = 'en';
 
+// Fallback until the backend list is loaded
+let availableLanguages = [
+    { code: 'en', name: 'English' },
+    { code: 'zh-CN', name: '简体中文' }
+];
+
 export function setLanguage(lang) {
     if (translations[lang]) {
         currentLanguage = lang;
@@ -34,8 +40,14 @@ export function t(key) {
 }
 
 export function getAvailableLanguages() {
-    return [
-        { code: 'en', name: 'English' },
-        { code: 'zh-CN', name: '简体中文' }
-    ];
+    return availableLanguages;
+}
+
+// setAvailableLanguages replaces the selector list with the backend's languages,
+// keeping only those that have translations
+export function setAvailableLanguages(languages) {
+    const list = (languages || []).filter(lang => lang && translations[lang.code]);
+    if (list.length > 0) {
+        availableLanguages = list;
+    }
 }
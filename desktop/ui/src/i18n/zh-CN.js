@@ -44,6 +44,7 @@ export default {
         startTime: '开始时间',
         targetUrl: '目标 URL',
         upstreamAuth: '上游认证 (已脱敏)',
+        modelFallback: '模型回退',
         requestStream: '请求流',
         responseStream: '响应流',
         requestHeaders: '请求头',
@@ -169,6 +170,17 @@ export default {
         proxyUrl: '代理 URL',
         proxyUrlPlaceholder: '例如：socks5://proxy.example.com:1080',
         proxyUrlHelp: '可选，用于通过代理访问上游 API',
+        forceNonStream: '强制上游非流式',
+        forceNonStreamHelp: '以非流式请求上游，再将完整响应以流式方式返回给客户端',
+        fallbackToDefaultModel: '模型不可用时回退默认模型',
+        fallbackToDefaultModelHelp: '上游提示模型不存在时，改用该端点的默认模型重试一次',
+        forwardClientIP: '转发客户端 IP',
+        forwardClientIPHelp: '通过 X-Forwarded-For / X-Real-IP 把客户端 IP 传给上游，部分供应商会拒绝携带 X-Forwarded-For 的请求',
+        anthropicVersion: 'anthropic-version',
+        anthropicVersionPlaceholder: '例如：2023-06-01',
+        anthropicBeta: 'anthropic-beta',
+        anthropicBetaPlaceholder: '例如：prompt-caching-2024-07-31',
+        anthropicHeadersHelp: '可选，转发到 Claude 上游时覆盖客户端的同名请求头',
         transformer: '转换器',
         transformerPlaceholder: '选择转换器',
         transformerHelp: '将请求转换为其他 API 格式',
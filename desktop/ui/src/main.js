@@ -35,6 +35,7 @@ import {
     toggleApiKeyVisibility,
     toggleInterfaceTypeDropdown,
     onEndpointInterfaceTypeChange,
+    onEndpointApiUrlChange,
     updateTestButtonVisibility,
     testEndpoint,
     fetchModels,
@@ -68,7 +69,9 @@ import {
     backupToWebDAV,
     loadBackupsList,
     loadConfigFromWebDAV,
-    deleteBackupFromWebDAV
+    deleteBackupFromWebDAV,
+    saveWebDAVAutoSync,
+    syncConfigSnapshot
 } from './modules/webdav.js';
 
 // Initialize the application
@@ -148,6 +151,7 @@ window.toggleRealtimeConnection = toggleRealtimeConnection;
 window.toggleApiKeyVisibility = toggleApiKeyVisibility;
 window.toggleInterfaceTypeDropdown = toggleInterfaceTypeDropdown;
 window.onEndpointInterfaceTypeChange = onEndpointInterfaceTypeChange;
+window.onEndpointApiUrlChange = onEndpointApiUrlChange;
 window.toggleConsolePanel = toggleConsolePanel;
 window.toggleBottomConsole = toggleBottomConsole;
 window.changeConsoleLogLevel = changeConsoleLogLevel;
@@ -177,3 +181,5 @@ window.backupToWebDAV = backupToWebDAV;
 window.loadBackupsList = loadBackupsList;
 window.loadConfigFromWebDAV = loadConfigFromWebDAV;
 window.deleteBackupFromWebDAV = deleteBackupFromWebDAV;
+window.saveWebDAVAutoSync = saveWebDAVAutoSync;
+window.syncConfigSnapshot = syncConfigSnapshot;
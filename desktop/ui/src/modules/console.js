@@ -182,8 +182,11 @@ function handleRealtimeEvent(event) {
     const transformerLabel = (request.transformer || '').trim()
         ? ` tr=${(request.transformer || '').trim()}`
         : '';
+    const remarkLabel = (request.remark || '').trim()
+        ? ` [${(request.remark || '').trim()}]`
+        : '';
 
-    logInfo(`${method} ${url}${endpointLabel}${transformerLabel}`);
+    logInfo(`${method} ${url}${endpointLabel}${remarkLabel}${transformerLabel}`);
 }
 
 // Toggle bottom console panel visibility (from logs-card button)
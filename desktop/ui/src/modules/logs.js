@@ -109,7 +109,8 @@ function updateRecentLog(request) {
         upstreamAuth: request.upstreamAuth,
         requestHeaders: request.requestHeaders,
         requestStream: request.requestStream,
-        responseStream: request.responseStream
+        responseStream: request.responseStream,
+        modelFallback: request.modelFallback
     };
     
     // Update state
@@ -285,7 +286,8 @@ export async function showLogDetail(logId) {
                         upstreamAuth: detail.upstreamAuth,
                         requestHeaders: detail.requestHeaders,
                         requestStream: detail.requestStream,
-                        responseStream: detail.responseStream
+                        responseStream: detail.responseStream,
+                        modelFallback: detail.modelFallback
                     };
                 }
             }
@@ -311,7 +313,8 @@ export async function showLogDetail(logId) {
                     upstreamAuth: stateLog.upstreamAuth,
                     requestHeaders: stateLog.requestHeaders,
                     requestStream: stateLog.requestStream,
-                    responseStream: stateLog.responseStream
+                    responseStream: stateLog.responseStream,
+                    modelFallback: stateLog.modelFallback
                 };
             }
         }
@@ -381,8 +384,8 @@ function renderLogDetailModal(log) {
                         <tr>
                             <td class="label">${t('logs.upstreamAuth')}</td>
                             <td class="value">${log.upstreamAuth || '-'}</td>
-                            <td class="label"></td>
-                            <td class="value"></td>
+                            <td class="label">${log.modelFallback ? t('logs.modelFallback') : ''}</td>
+                            <td class="value">${log.modelFallback ? escapeHtml(log.modelFallback) : ''}</td>
                         </tr>
                     </table>
                 </div>
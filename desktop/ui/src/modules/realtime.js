@@ -139,6 +139,20 @@ class RealTimeManager {
                     this.processRequestLog(message.payload);
                 }
 
+                // Process initial snapshot sent on (re)connect: replay logs oldest first
+                if (message.type === 'snapshot' && message.payload) {
+                    const logs = message.payload.recentLogs || [];
+                    for (let i = logs.length - 1; i >= 0; i--) {
+                        this.processRequestLog(logs[i]);
+                    }
+                    for (const stats of message.payload.tokenStats || []) {
+                        this.notifyListeners({
+                            type: 'token_stats',
+                            data: stats
+                        });
+                    }
+                }
+
                 // Process token_stats messages
                 if (message.type === 'token_stats' && message.payload) {
                     this.notifyListeners({
@@ -179,6 +193,7 @@ class RealTimeManager {
             vendorName: log.vendorName || '',
             endpointName: log.endpointName || '',
             transformer: log.transformer || '',
+            remark: log.remark || '',
             method: log.method || 'POST',
             path: log.path || '',
             status: status,
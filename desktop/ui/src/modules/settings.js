@@ -2,7 +2,7 @@
  * Settings management module
  */
 import { state } from './state.js';
-import { t, setLanguage } from '../i18n/index.js';
+import { t, setLanguage, setAvailableLanguages } from '../i18n/index.js';
 import { showError, showSuccess } from './utils.js';
 import { initUI } from './ui.js';
 import { loadEndpoints } from './endpoints.js';
@@ -11,6 +11,9 @@ import { loadTokenStats } from './stats.js';
 
 export async function loadLanguage() {
     try {
+        if (window.go?.main?.App?.GetSupportedLanguages) {
+            setAvailableLanguages(await window.go.main.App.GetSupportedLanguages());
+        }
         if (window.go?.main?.App?.GetLanguage) {
             const lang = await window.go.main.App.GetLanguage();
             if (lang) {
@@ -28,17 +31,23 @@ export async function changeLanguage(lang) {
         if (window.go?.main?.App?.SetLanguage) {
             await window.go.main.App.SetLanguage(lang);
         }
-        state.language = lang;
-        setLanguage(lang);
-        initUI();
-        await loadEndpoints(state.currentTab);
-        await loadRecentLogs();
-        await loadTokenStats();
+        await applyLanguage(lang);
     } catch (error) {
         showError('Failed to change language: ' + error.message);
     }
 }
 
+// applyLanguage re-renders the UI in lang; also used when another window changed the language
+export async function applyLanguage(lang) {
+    if (!lang || lang === state.language) return;
+    state.language = lang;
+    setLanguage(lang);
+    initUI();
+    await loadEndpoints(state.currentTab);
+    await loadRecentLogs();
+    await loadTokenStats();
+}
+
 export async function loadSettings() {
     try {
         if (window.go?.main?.App?.GetSettings) {
@@ -226,7 +226,7 @@ export function initUI() {
                     </div>
                     <div class="form-group">
                         <label>${t('manage.apiUrl')} *</label>
-                        <input type="text" id="endpointApiUrl" placeholder="${t('manage.apiUrlPlaceholder')}">
+                        <input type="text" id="endpointApiUrl" placeholder="${t('manage.apiUrlPlaceholder')}" onchange="onEndpointApiUrlChange()">
                     </div>
                     <div class="form-group">
                         <label>${t('manage.apiKey')} *</label>
@@ -305,6 +305,41 @@ export function initUI() {
                         <input type="text" id="endpointProxyUrl" placeholder="${t('manage.proxyUrlPlaceholder')}">
                         <small>${t('manage.proxyUrlHelp')}</small>
                     </div>
+                    <div class="form-group switch-form-group">
+                        <label>${t('manage.forceNonStream')}</label>
+                        <label class="switch">
+                            <input type="checkbox" id="endpointForceNonStream">
+                            <span class="slider"></span>
+                        </label>
+                        <small>${t('manage.forceNonStreamHelp')}</small>
+                    </div>
+                    <div class="form-group switch-form-group">
+                        <label>${t('manage.fallbackToDefaultModel')}</label>
+                        <label class="switch">
+                            <input type="checkbox" id="endpointFallbackToDefaultModel">
+                            <span class="slider"></span>
+                        </label>
+                        <small>${t('manage.fallbackToDefaultModelHelp')}</small>
+                    </div>
+                    <div class="form-group switch-form-group">
+                        <label>${t('manage.forwardClientIP')}</label>
+                        <label class="switch">
+                            <input type="checkbox" id="endpointForwardClientIP">
+                            <span class="slider"></span>
+                        </label>
+                        <small>${t('manage.forwardClientIPHelp')}</small>
+                    </div>
+                    <div class="form-row">
+                        <div class="form-group">
+                            <label>${t('manage.anthropicVersion')}</label>
+                            <input type="text" id="endpointAnthropicVersion" placeholder="${t('manage.anthropicVersionPlaceholder')}">
+                        </div>
+                        <div class="form-group">
+                            <label>${t('manage.anthropicBeta')}</label>
+                            <input type="text" id="endpointAnthropicBeta" placeholder="${t('manage.anthropicBetaPlaceholder')}">
+                        </div>
+                    </div>
+                    <small>${t('manage.anthropicHeadersHelp')}</small>
                     <div class="form-row">
                         <div class="form-group">
                             <label>${t('manage.priority')}</label>
@@ -378,6 +413,24 @@ export function initUI() {
                         </div>
                     </div>
 
+                    <!-- Config Snapshot Sync -->
+                    <div class="card-section">
+                        <h3>配置快照同步</h3>
+                        <div class="form-group">
+                            <label>自动同步间隔（分钟）</label>
+                            <input type="number" id="webdavAutoSyncMinutes" min="0" placeholder="0">
+                            <small>定时上传完整配置快照（gzip + SHA-256 校验），配置未变化时跳过上传；0 表示关闭</small>
+                        </div>
+                        <div class="form-row">
+                            <button class="btn btn-secondary" onclick="saveWebDAVAutoSync()">
+                                💾 保存设置
+                            </button>
+                            <button class="btn btn-primary" onclick="syncConfigSnapshot()" id="webdavSnapshotBtn">
+                                ⬆️ 立即同步快照
+                            </button>
+                        </div>
+                    </div>
+
                     <!-- Backup Records -->
                     <div class="card-section">
                         <h3>备份记录</h3>
@@ -237,6 +237,8 @@ export function showEndpointForm(endpoint = null) {
     document.getElementById('endpointApiUrl').value = endpoint?.apiUrl || state.selectedVendor?.apiUrl || '';
     document.getElementById('endpointApiKey').value = endpoint?.apiKey || '';
     document.getElementById('endpointInterfaceType').value = endpoint?.interfaceType || 'claude';
+    // 编辑已有端点时不再根据 URL 自动推断接口类型
+    interfaceTypePickedByUser = !!endpoint;
     document.getElementById('endpointVendorId').value = endpoint?.vendorId || state.selectedVendor?.id || '';
     document.getElementById('endpointModel').value = endpoint?.model || '';
     document.getElementById('endpointRemark').value = endpoint?.remark || '';
@@ -245,6 +247,11 @@ export function showEndpointForm(endpoint = null) {
 
     // 初始化 proxyUrl
     document.getElementById('endpointProxyUrl').value = endpoint?.proxyUrl || '';
+    document.getElementById('endpointForceNonStream').checked = endpoint?.forceNonStreamUpstream === true;
+    document.getElementById('endpointFallbackToDefaultModel').checked = endpoint?.fallbackToDefaultModel === true;
+    document.getElementById('endpointForwardClientIP').checked = endpoint?.forwardClientIP === true;
+    document.getElementById('endpointAnthropicVersion').value = endpoint?.anthropicVersion || '';
+    document.getElementById('endpointAnthropicBeta').value = endpoint?.anthropicBeta || '';
 
     // 初始化 transformer
     document.getElementById('endpointTransformer').value = endpoint?.transformer || '';
@@ -280,6 +287,26 @@ export function showEndpointForm(endpoint = null) {
     document.getElementById('endpointFormModal').classList.add('active');
 }
 
+// interfaceTypePickedByUser 为 true 时，API URL 变化不再覆盖用户选择的接口类型
+let interfaceTypePickedByUser = false;
+
+// 根据 API URL 推断接口类型并预填，仅作建议，用户手动选择后不再覆盖
+export async function onEndpointApiUrlChange() {
+    if (interfaceTypePickedByUser || !window.go?.main?.App?.DetectInterfaceTypeFromURL) return;
+    const apiUrl = document.getElementById('endpointApiUrl').value.trim();
+    if (!apiUrl) return;
+
+    try {
+        const detected = await window.go.main.App.DetectInterfaceTypeFromURL(apiUrl);
+        const select = document.getElementById('endpointInterfaceType');
+        if (!detected || !select || select.value === detected) return;
+        select.value = detected;
+        onEndpointInterfaceTypeChange();
+    } catch (error) {
+        logError(`[Endpoint] detect interface type failed: ${error?.message || error}`);
+    }
+}
+
 function syncEndpointInterfaceTypeDisplay() {
     const select = document.getElementById('endpointInterfaceType');
     const display = document.getElementById('endpointInterfaceTypeDisplay');
@@ -307,6 +334,7 @@ function renderInterfaceTypeDropdown() {
         item.textContent = option.textContent;
         item.onclick = () => {
             select.value = option.value;
+            interfaceTypePickedByUser = true;
             onEndpointInterfaceTypeChange();
         };
         dropdown.appendChild(item);
@@ -426,12 +454,15 @@ export async function testEndpoint() {
         testBtn.disabled = true;
 
         if (window.go?.main?.App?.TestEndpointWithParams) {
-            const params = { apiUrl, apiKey, interfaceType, model };
+            const params = { apiUrl, apiKey, interfaceType, model, stream: interfaceType === 'claude' };
             const resultStr = await window.go.main.App.TestEndpointWithParams(params);
             const result = JSON.parse(resultStr);
             logEndpointTestResult({ endpointName, interfaceType, model }, result);
             appendEndpointTestToMainLogs({ endpointName, interfaceType, model }, result, Date.now() - startedAt);
 
+            if (result.streaming && result.firstTokenMs) {
+                logInfo(`[Test] Stream events: ${(result.streamEvents || []).join(', ')}, first token in ${result.firstTokenMs}ms`);
+            }
             if (result.success) {
                 logInfo(`[Test] ✅ Success for "${endpointName}": ${result.message}`);
                 showSuccess(t('manage.testSuccess') + ': ' + result.message);
@@ -1099,6 +1130,11 @@ export async function saveEndpoint() {
         transformer: document.getElementById('endpointTransformer').value.trim(),
         transformerSet: true,
         proxyUrl: document.getElementById('endpointProxyUrl').value.trim(),
+        forceNonStreamUpstream: document.getElementById('endpointForceNonStream').checked,
+        fallbackToDefaultModel: document.getElementById('endpointFallbackToDefaultModel').checked,
+        forwardClientIP: document.getElementById('endpointForwardClientIP').checked,
+        anthropicVersion: document.getElementById('endpointAnthropicVersion').value.trim(),
+        anthropicBeta: document.getElementById('endpointAnthropicBeta').value.trim(),
         models: models.length > 0 ? models : null,
         modelsSet: true,
         remark: document.getElementById('endpointRemark').value.trim(),
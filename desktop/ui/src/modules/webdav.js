@@ -13,6 +13,7 @@ export const webdavState = {
     serverUrl: '',
     username: '',
     password: '',
+    autoSyncMinutes: 0,
     backups: [], // List of available backups
     isTesting: false,
     isSyncing: false
@@ -21,6 +22,9 @@ export const webdavState = {
 // Remote backup directory
 const BACKUP_DIR = '/clisimplehub';
 
+// Config snapshot path, shared with the backend auto-sync
+const SNAPSHOT_PATH = `${BACKUP_DIR}/config.snapshot.gz`;
+
 /**
  * Get WebDAV config object for backend calls
  */
@@ -36,18 +40,8 @@ function getWebDAVConfig() {
  * Show WebDAV sync modal
  */
 export async function showWebDAVModal() {
-    // Load existing WebDAV settings from state or localStorage
-    const savedSettings = localStorage.getItem('webdavSettings');
-    if (savedSettings) {
-        try {
-            const settings = JSON.parse(savedSettings);
-            webdavState.serverUrl = settings.serverUrl || '';
-            webdavState.username = settings.username || '';
-            webdavState.password = settings.password || '';
-        } catch (e) {
-            console.error('Failed to parse saved WebDAV settings:', e);
-        }
-    }
+    // Load saved WebDAV settings from the backend config
+    await loadWebDAVSettings();
 
     // Populate form fields
     const serverUrlEl = document.getElementById('webdavServerUrl');
@@ -57,6 +51,8 @@ export async function showWebDAVModal() {
     if (serverUrlEl) serverUrlEl.value = webdavState.serverUrl;
     if (usernameEl) usernameEl.value = webdavState.username;
     if (passwordEl) passwordEl.value = webdavState.password;
+    const autoSyncEl = document.getElementById('webdavAutoSyncMinutes');
+    if (autoSyncEl) autoSyncEl.value = webdavState.autoSyncMinutes;
 
     // Load backups list
     await loadBackupsList();
@@ -101,7 +97,7 @@ export async function testWebDAVConnection() {
         } else if (result.statusCode === 207 || result.statusCode === 200) {
             showSuccess('WebDAV连接成功！');
             // Save settings
-            saveWebDAVSettings(serverUrl, username, password);
+            await saveWebDAVSettings(serverUrl, username, password);
         } else if (result.statusCode === 401) {
             showError('认证失败，请检查用户名和密码');
         } else {
@@ -436,18 +432,99 @@ export async function deleteBackupFromWebDAV(filename) {
 }
 
 /**
- * Save WebDAV settings to localStorage
+ * Load saved WebDAV settings from the backend, migrating any legacy localStorage copy
  */
-function saveWebDAVSettings(serverUrl, username, password) {
+async function loadWebDAVSettings() {
+    const legacy = localStorage.getItem('webdavSettings');
+    if (legacy) {
+        try {
+            const settings = JSON.parse(legacy);
+            await saveWebDAVSettings(settings.serverUrl || '', settings.username || '', settings.password || '');
+            localStorage.removeItem('webdavSettings');
+            return;
+        } catch (e) {
+            console.error('Failed to migrate saved WebDAV settings:', e);
+        }
+    }
+
+    try {
+        const settings = await window.go.main.App.GetWebDAVConfig();
+        webdavState.serverUrl = settings?.serverUrl || '';
+        webdavState.username = settings?.username || '';
+        webdavState.password = settings?.password || '';
+        webdavState.autoSyncMinutes = settings?.autoSyncMinutes || 0;
+    } catch (e) {
+        console.error('Failed to load saved WebDAV settings:', e);
+    }
+}
+
+/**
+ * Save WebDAV settings to the backend config (password encrypted when CLISHUB_SECRET is set)
+ */
+async function saveWebDAVSettings(serverUrl, username, password) {
     webdavState.serverUrl = serverUrl;
     webdavState.username = username;
     webdavState.password = password;
 
-    localStorage.setItem('webdavSettings', JSON.stringify({
+    await window.go.main.App.SaveWebDAVConfig({
         serverUrl,
         username,
-        password
-    }));
+        password,
+        autoSyncMinutes: webdavState.autoSyncMinutes
+    });
+}
+
+/**
+ * Save the config snapshot auto-sync interval along with the current credentials
+ */
+export async function saveWebDAVAutoSync() {
+    const minutes = parseInt(document.getElementById('webdavAutoSyncMinutes')?.value || '0', 10);
+    if (isNaN(minutes) || minutes < 0) {
+        showError('同步间隔必须是非负整数');
+        return;
+    }
+
+    const config = getWebDAVConfig();
+    if (minutes > 0 && !config.serverUrl) {
+        showError('请先配置WebDAV服务器地址');
+        return;
+    }
+
+    try {
+        webdavState.autoSyncMinutes = minutes;
+        await saveWebDAVSettings(config.serverUrl, config.username, config.password);
+        showSuccess(minutes > 0 ? `已开启自动同步：每 ${minutes} 分钟` : '已关闭自动同步');
+    } catch (error) {
+        console.error('Save auto-sync error:', error);
+        showError('保存失败: ' + (error.message || error));
+    }
+}
+
+/**
+ * Upload the config snapshot now (skipped by the backend when the remote checksum matches)
+ */
+export async function syncConfigSnapshot() {
+    const config = getWebDAVConfig();
+    if (!config.serverUrl) {
+        showError('请先配置WebDAV服务器地址');
+        return;
+    }
+
+    const btn = document.getElementById('webdavSnapshotBtn');
+    if (btn) btn.disabled = true;
+    try {
+        const result = await window.go.main.App.SyncConfigSnapshot(config, SNAPSHOT_PATH);
+        if (result?.uploaded) {
+            showSuccess(`快照已上传 (${result.size} 字节)`);
+        } else {
+            showSuccess('配置未变化，已跳过上传');
+        }
+    } catch (error) {
+        console.error('Snapshot sync error:', error);
+        showError('快照同步失败: ' + (error.message || error));
+    } finally {
+        if (btn) btn.disabled = false;
+    }
 }
 
 /**
@@ -559,3 +636,5 @@ window.backupToWebDAV = backupToWebDAV;
 window.loadBackupsList = loadBackupsList;
 window.loadConfigFromWebDAV = loadConfigFromWebDAV;
 window.deleteBackupFromWebDAV = deleteBackupFromWebDAV;
+window.saveWebDAVAutoSync = saveWebDAVAutoSync;
+window.syncConfigSnapshot = syncConfigSnapshot;
@@ -7,6 +7,7 @@ import { state } from './state.js';
 import { loadTokenStats } from './stats.js';
 import { loadEndpoints } from './endpoints.js';
 import { logInfo, logError, logDebug } from './console.js';
+import { applyLanguage } from './settings.js';
 
 let tokenStatsRefreshTimer = null;
 let endpointsRefreshTimer = null;
@@ -62,6 +63,7 @@ function handleWebSocketMessage(message) {
             // This is kept for backward compatibility but realtime.js provides enhanced tracking
             break;
             
+        case 'snapshot':
         case 'token_stats':
             // Token usage is persisted into SQLite; refresh aggregated stats with a small debounce.
             if (tokenStatsRefreshTimer) return;
@@ -83,6 +85,14 @@ function handleWebSocketMessage(message) {
         case 'endpoint_temp_disabled':
             handleEndpointTempDisabled(message.payload);
             break;
+
+        case 'endpoint_reenable_required':
+            handleEndpointReenableRequired(message.payload);
+            break;
+
+        case 'language_changed':
+            handleLanguageChanged(message.payload);
+            break;
     }
 }
 
@@ -141,3 +151,23 @@ function handleEndpointTempDisabled(payload) {
         endpointsRestoreTimer = null;
     }, delayMs);
 }
+
+function handleEndpointReenableRequired(payload) {
+    if (!payload) return;
+
+    const { interfaceType, endpointName, persisted } = payload;
+    if (interfaceType && endpointName) {
+        const note = persisted ? '' : '（保存配置失败，重启后恢复）';
+        logInfo(`端点已禁用，需手动重新启用: ${interfaceType}-${endpointName}${note}`);
+    }
+
+    if (interfaceType && interfaceType !== state.currentTab) return;
+    refreshCurrentTabEndpointsDebounced();
+}
+
+function handleLanguageChanged(payload) {
+    if (!payload?.language) return;
+    applyLanguage(payload.language).catch(error => {
+        logError('Failed to apply language: ' + error.message);
+    });
+}
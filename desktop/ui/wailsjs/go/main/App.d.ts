@@ -6,12 +6,28 @@ import {proxy} from '../models';
 import {storage} from '../models';
 import {statsdb} from '../models';
 
+export function ApplyConfigSnapshot(arg1:Array<number>,arg2:string):Promise<void>;
+
+export function CancelRequest(arg1:string):Promise<void>;
+
+export function ClearLogs():Promise<void>;
+
 export function ClearTokenStats(arg1:string):Promise<void>;
 
+export function CreateProfile(arg1:string):Promise<void>;
+
 export function DeleteEndpoint(arg1:number):Promise<void>;
 
+export function DeleteLog(arg1:string):Promise<void>;
+
 export function DeleteVendor(arg1:number):Promise<void>;
 
+export function DetectInterfaceTypeFromURL(arg1:string):Promise<string>;
+
+export function EnableDebugCapture(arg1:number):Promise<void>;
+
+export function ExportConfigToFile(arg1:string,arg2:boolean):Promise<void>;
+
 export function FetchModels(arg1:string,arg2:string,arg3:string):Promise<string>;
 
 export function GetActiveEndpoint(arg1:string):Promise<main.EndpointInfo>;
@@ -28,30 +44,54 @@ export function GetComputerName():Promise<string>;
 
 export function GetConfigPath():Promise<string>;
 
+export function GetConfigSnapshot():Promise<Array<number>>;
+
+export function GetDebugTraces():Promise<Array<main.DebugTraceInfo>>;
+
 export function GetEndpointsByType(arg1:string):Promise<Array<main.EndpointInfo>>;
 
 export function GetEndpointsByVendorID(arg1:number):Promise<Array<main.EndpointInfo>>;
 
+export function GetFallbackEvents(arg1:string):Promise<Array<main.FallbackEventInfo>>;
+
 export function GetFullConfig():Promise<main.FullConfig>;
 
+export function GetHealthSummary():Promise<main.HealthSummary>;
+
+export function GetIdleEndpoints(arg1:number):Promise<Array<main.EndpointInfo>>;
+
 export function GetInterfaceTypes():Promise<Array<string>>;
 
 export function GetLanguage():Promise<string>;
 
+export function GetLatencyStats(arg1:string):Promise<Array<main.LatencyStatsSummaryInfo>>;
+
 export function GetLocalIPs():Promise<Array<main.LocalIPInfo>>;
 
 export function GetLogDetail(arg1:string):Promise<main.RequestLogDetailInfo>;
 
+export function GetLogLevel():Promise<string>;
+
 export function GetPort():Promise<number>;
 
 export function GetProxyStatus():Promise<Record<string, any>>;
 
 export function GetRecentLogs():Promise<Array<main.RequestLogInfo>>;
 
+export function GetRouterState():Promise<Record<string, main.RouterTypeState>>;
+
 export function GetSettings():Promise<main.Settings>;
 
+export function GetStatsByCustomRange(arg1:string,arg2:string):Promise<Array<main.VendorStatsSummaryInfo>>;
+
+export function GetStatsByExperiment(arg1:string):Promise<Array<main.ExperimentStatsSummaryInfo>>;
+
 export function GetStatsByInterfaceType(arg1:string):Promise<Array<main.InterfaceTypeStatsSummaryInfo>>;
 
+export function GetStatsByUser(arg1:string):Promise<Array<main.UserStatsSummaryInfo>>;
+
+export function GetSupportedLanguages():Promise<Array<main.LanguageInfo>>;
+
 export function GetTokenStats():Promise<Array<main.TokenStatsInfo>>;
 
 export function GetTokenStatsByTimeRange(arg1:string):Promise<Array<main.VendorStatsSummaryInfo>>;
@@ -62,14 +102,24 @@ export function GetTransformers():Promise<Record<string, Array<string>>>;
 
 export function GetVendors():Promise<Array<main.VendorInfo>>;
 
+export function GetWebDAVConfig():Promise<main.WebDAVConfigInput>;
+
 export function GetWebSocketURL():Promise<string>;
 
+export function ImportConfigFromFile(arg1:string):Promise<void>;
+
+export function ListProfiles():Promise<Array<main.ProfileInfo>>;
+
+export function ParseEndpointFromCurl(arg1:string):Promise<main.EndpointInput>;
+
 export function PingAllEndpoints(arg1:string):Promise<Array<main.PingResult>>;
 
 export function PingEndpoint(arg1:number):Promise<main.PingResult>;
 
 export function PingEndpointByURL(arg1:string):Promise<main.PingResult>;
 
+export function PreviewFullConfigRestore(arg1:main.FullConfig):Promise<main.RestoreDiff>;
+
 export function ProcessClaudeConfig(arg1:string):Promise<string>;
 
 export function ProcessClaudeConfigWithIP(arg1:string,arg2:string):Promise<string>;
@@ -80,6 +130,10 @@ export function ProcessCodexConfigWithIP(arg1:string,arg2:string,arg3:string):Pr
 
 export function ReloadConfig():Promise<void>;
 
+export function ReplayRequest(arg1:string,arg2:number):Promise<main.TestEndpointResult>;
+
+export function RestoreConfigSnapshot(arg1:main.WebDAVConfigInput,arg2:string):Promise<void>;
+
 export function SaveCLIConfigDirs(arg1:main.CLIConfigDirs):Promise<void>;
 
 export function SaveClaudeConfig(arg1:string):Promise<void>;
@@ -94,12 +148,18 @@ export function SaveSettings(arg1:main.Settings):Promise<void>;
 
 export function SaveVendor(arg1:main.VendorInfo):Promise<main.VendorInfo>;
 
+export function SaveWebDAVConfig(arg1:main.WebDAVConfigInput):Promise<void>;
+
 export function SetActiveEndpoint(arg1:string,arg2:number):Promise<void>;
 
+export function SetActiveEndpointChecked(arg1:string,arg2:number):Promise<void>;
+
 export function SetConfigLoader(arg1:config.ConfigLoader):Promise<void>;
 
 export function SetLanguage(arg1:string):Promise<void>;
 
+export function SetLogLevel(arg1:string):Promise<void>;
+
 export function SetPort(arg1:number):Promise<void>;
 
 export function SetProxyServer(arg1:proxy.ProxyServer):Promise<void>;
@@ -116,6 +176,10 @@ export function StartProxy():Promise<void>;
 
 export function StopProxy():Promise<void>;
 
+export function SwitchProfile(arg1:string):Promise<void>;
+
+export function SyncConfigSnapshot(arg1:main.WebDAVConfigInput,arg2:string):Promise<main.ConfigSnapshotSyncResult>;
+
 export function TestEndpoint(arg1:number):Promise<string>;
 
 export function TestEndpointWithParams(arg1:main.TestEndpointParams):Promise<string>;
@@ -137,3 +201,5 @@ export function WebDAVMove(arg1:main.WebDAVRequestInput):Promise<proxy.WebDAVRes
 export function WebDAVProxyRequest(arg1:main.WebDAVRequestInput):Promise<proxy.WebDAVResponse>;
 
 export function WebDAVPut(arg1:main.WebDAVRequestInput):Promise<proxy.WebDAVResponse>;
+
+export function WebDAVTestConnection(arg1:main.WebDAVConfigInput):Promise<main.WebDAVTestResult>;
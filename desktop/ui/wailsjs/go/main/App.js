@@ -2,18 +2,50 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function ApplyConfigSnapshot(arg1, arg2) {
+  return window['go']['main']['App']['ApplyConfigSnapshot'](arg1, arg2);
+}
+
+export function CancelRequest(arg1) {
+  return window['go']['main']['App']['CancelRequest'](arg1);
+}
+
+export function ClearLogs() {
+  return window['go']['main']['App']['ClearLogs']();
+}
+
 export function ClearTokenStats(arg1) {
   return window['go']['main']['App']['ClearTokenStats'](arg1);
 }
 
+export function CreateProfile(arg1) {
+  return window['go']['main']['App']['CreateProfile'](arg1);
+}
+
 export function DeleteEndpoint(arg1) {
   return window['go']['main']['App']['DeleteEndpoint'](arg1);
 }
 
+export function DeleteLog(arg1) {
+  return window['go']['main']['App']['DeleteLog'](arg1);
+}
+
 export function DeleteVendor(arg1) {
   return window['go']['main']['App']['DeleteVendor'](arg1);
 }
 
+export function DetectInterfaceTypeFromURL(arg1) {
+  return window['go']['main']['App']['DetectInterfaceTypeFromURL'](arg1);
+}
+
+export function EnableDebugCapture(arg1) {
+  return window['go']['main']['App']['EnableDebugCapture'](arg1);
+}
+
+export function ExportConfigToFile(arg1, arg2) {
+  return window['go']['main']['App']['ExportConfigToFile'](arg1, arg2);
+}
+
 export function FetchModels(arg1, arg2, arg3) {
   return window['go']['main']['App']['FetchModels'](arg1, arg2, arg3);
 }
@@ -46,6 +78,14 @@ export function GetConfigPath() {
   return window['go']['main']['App']['GetConfigPath']();
 }
 
+export function GetConfigSnapshot() {
+  return window['go']['main']['App']['GetConfigSnapshot']();
+}
+
+export function GetDebugTraces() {
+  return window['go']['main']['App']['GetDebugTraces']();
+}
+
 export function GetEndpointsByType(arg1) {
   return window['go']['main']['App']['GetEndpointsByType'](arg1);
 }
@@ -54,10 +94,22 @@ export function GetEndpointsByVendorID(arg1) {
   return window['go']['main']['App']['GetEndpointsByVendorID'](arg1);
 }
 
+export function GetFallbackEvents(arg1) {
+  return window['go']['main']['App']['GetFallbackEvents'](arg1);
+}
+
 export function GetFullConfig() {
   return window['go']['main']['App']['GetFullConfig']();
 }
 
+export function GetHealthSummary() {
+  return window['go']['main']['App']['GetHealthSummary']();
+}
+
+export function GetIdleEndpoints(arg1) {
+  return window['go']['main']['App']['GetIdleEndpoints'](arg1);
+}
+
 export function GetInterfaceTypes() {
   return window['go']['main']['App']['GetInterfaceTypes']();
 }
@@ -66,6 +118,10 @@ export function GetLanguage() {
   return window['go']['main']['App']['GetLanguage']();
 }
 
+export function GetLatencyStats(arg1) {
+  return window['go']['main']['App']['GetLatencyStats'](arg1);
+}
+
 export function GetLocalIPs() {
   return window['go']['main']['App']['GetLocalIPs']();
 }
@@ -74,6 +130,10 @@ export function GetLogDetail(arg1) {
   return window['go']['main']['App']['GetLogDetail'](arg1);
 }
 
+export function GetLogLevel() {
+  return window['go']['main']['App']['GetLogLevel']();
+}
+
 export function GetPort() {
   return window['go']['main']['App']['GetPort']();
 }
@@ -86,14 +146,34 @@ export function GetRecentLogs() {
   return window['go']['main']['App']['GetRecentLogs']();
 }
 
+export function GetRouterState() {
+  return window['go']['main']['App']['GetRouterState']();
+}
+
 export function GetSettings() {
   return window['go']['main']['App']['GetSettings']();
 }
 
+export function GetStatsByCustomRange(arg1, arg2) {
+  return window['go']['main']['App']['GetStatsByCustomRange'](arg1, arg2);
+}
+
+export function GetStatsByExperiment(arg1) {
+  return window['go']['main']['App']['GetStatsByExperiment'](arg1);
+}
+
 export function GetStatsByInterfaceType(arg1) {
   return window['go']['main']['App']['GetStatsByInterfaceType'](arg1);
 }
 
+export function GetStatsByUser(arg1) {
+  return window['go']['main']['App']['GetStatsByUser'](arg1);
+}
+
+export function GetSupportedLanguages() {
+  return window['go']['main']['App']['GetSupportedLanguages']();
+}
+
 export function GetTokenStats() {
   return window['go']['main']['App']['GetTokenStats']();
 }
@@ -114,10 +194,26 @@ export function GetVendors() {
   return window['go']['main']['App']['GetVendors']();
 }
 
+export function GetWebDAVConfig() {
+  return window['go']['main']['App']['GetWebDAVConfig']();
+}
+
 export function GetWebSocketURL() {
   return window['go']['main']['App']['GetWebSocketURL']();
 }
 
+export function ImportConfigFromFile(arg1) {
+  return window['go']['main']['App']['ImportConfigFromFile'](arg1);
+}
+
+export function ListProfiles() {
+  return window['go']['main']['App']['ListProfiles']();
+}
+
+export function ParseEndpointFromCurl(arg1) {
+  return window['go']['main']['App']['ParseEndpointFromCurl'](arg1);
+}
+
 export function PingAllEndpoints(arg1) {
   return window['go']['main']['App']['PingAllEndpoints'](arg1);
 }
@@ -130,6 +226,10 @@ export function PingEndpointByURL(arg1) {
   return window['go']['main']['App']['PingEndpointByURL'](arg1);
 }
 
+export function PreviewFullConfigRestore(arg1) {
+  return window['go']['main']['App']['PreviewFullConfigRestore'](arg1);
+}
+
 export function ProcessClaudeConfig(arg1) {
   return window['go']['main']['App']['ProcessClaudeConfig'](arg1);
 }
@@ -150,6 +250,14 @@ export function ReloadConfig() {
   return window['go']['main']['App']['ReloadConfig']();
 }
 
+export function ReplayRequest(arg1, arg2) {
+  return window['go']['main']['App']['ReplayRequest'](arg1, arg2);
+}
+
+export function RestoreConfigSnapshot(arg1, arg2) {
+  return window['go']['main']['App']['RestoreConfigSnapshot'](arg1, arg2);
+}
+
 export function SaveCLIConfigDirs(arg1) {
   return window['go']['main']['App']['SaveCLIConfigDirs'](arg1);
 }
@@ -178,10 +286,18 @@ export function SaveVendor(arg1) {
   return window['go']['main']['App']['SaveVendor'](arg1);
 }
 
+export function SaveWebDAVConfig(arg1) {
+  return window['go']['main']['App']['SaveWebDAVConfig'](arg1);
+}
+
 export function SetActiveEndpoint(arg1, arg2) {
   return window['go']['main']['App']['SetActiveEndpoint'](arg1, arg2);
 }
 
+export function SetActiveEndpointChecked(arg1, arg2) {
+  return window['go']['main']['App']['SetActiveEndpointChecked'](arg1, arg2);
+}
+
 export function SetConfigLoader(arg1) {
   return window['go']['main']['App']['SetConfigLoader'](arg1);
 }
@@ -190,6 +306,10 @@ export function SetLanguage(arg1) {
   return window['go']['main']['App']['SetLanguage'](arg1);
 }
 
+export function SetLogLevel(arg1) {
+  return window['go']['main']['App']['SetLogLevel'](arg1);
+}
+
 export function SetPort(arg1) {
   return window['go']['main']['App']['SetPort'](arg1);
 }
@@ -222,6 +342,14 @@ export function StopProxy() {
   return window['go']['main']['App']['StopProxy']();
 }
 
+export function SwitchProfile(arg1) {
+  return window['go']['main']['App']['SwitchProfile'](arg1);
+}
+
+export function SyncConfigSnapshot(arg1, arg2) {
+  return window['go']['main']['App']['SyncConfigSnapshot'](arg1, arg2);
+}
+
 export function TestEndpoint(arg1) {
   return window['go']['main']['App']['TestEndpoint'](arg1);
 }
@@ -265,3 +393,7 @@ export function WebDAVProxyRequest(arg1) {
 export function WebDAVPut(arg1) {
   return window['go']['main']['App']['WebDAVPut'](arg1);
 }
+
+export function WebDAVTestConnection(arg1) {
+  return window['go']['main']['App']['WebDAVTestConnection'](arg1);
+}
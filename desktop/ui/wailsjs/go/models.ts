@@ -83,6 +83,84 @@ export namespace main {
 		    return a;
 		}
 	}
+	export class ConfigSnapshotSyncResult {
+	    checksum: string;
+	    size: number;
+	    uploaded: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConfigSnapshotSyncResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.checksum = source["checksum"];
+	        this.size = source["size"];
+	        this.uploaded = source["uploaded"];
+	    }
+	}
+	export class DebugTraceStepInfo {
+	    offsetMs: number;
+	    level: number;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DebugTraceStepInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.offsetMs = source["offsetMs"];
+	        this.level = source["level"];
+	        this.message = source["message"];
+	    }
+	}
+	export class DebugTraceInfo {
+	    requestId: string;
+	    method: string;
+	    path: string;
+	    interfaceType: string;
+	    endpoint: string;
+	    startTime: number;
+	    durationMs: number;
+	    status: string;
+	    steps: DebugTraceStepInfo[];
+	
+	    static createFrom(source: any = {}) {
+	        return new DebugTraceInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.requestId = source["requestId"];
+	        this.method = source["method"];
+	        this.path = source["path"];
+	        this.interfaceType = source["interfaceType"];
+	        this.endpoint = source["endpoint"];
+	        this.startTime = source["startTime"];
+	        this.durationMs = source["durationMs"];
+	        this.status = source["status"];
+	        this.steps = this.convertValues(source["steps"], DebugTraceStepInfo);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class EndpointInfo {
 	    id: number;
 	    name: string;
@@ -96,13 +174,33 @@ export namespace main {
 	    model?: string;
 	    transformer?: string;
 	    proxyUrl?: string;
+	    proxyUsername?: string;
+	    proxyPassword?: string;
+	    forceNonStreamUpstream?: boolean;
+	    fallbackToDefaultModel?: boolean;
 	    models?: storage.ModelMapping[];
+	    schedule?: storage.ScheduleWindow[];
+	    dropParams?: string[];
+	    bodyOverrides?: Record<string, any>;
+	    bodyForce?: boolean;
+	    includeReasoning?: boolean;
+	    reasoningSummary?: string;
+	    insecureSkipVerify?: boolean;
+	    forwardClientIP?: boolean;
+	    sandbox?: boolean;
+	    caFile?: string;
+	    anthropicVersion?: string;
+	    anthropicBeta?: string;
+	    dailyTokenBudget?: number;
+	    maxTokensCap?: number;
+	    responseValidation?: string[];
 	    remark?: string;
 	    priority: number;
 	    todayRequests: number;
 	    todayErrors: number;
 	    todayInput: number;
 	    todayOutput: number;
+	    lastUsedAt?: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new EndpointInfo(source);
@@ -122,13 +220,33 @@ export namespace main {
 	        this.model = source["model"];
 	        this.transformer = source["transformer"];
 	        this.proxyUrl = source["proxyUrl"];
+	        this.proxyUsername = source["proxyUsername"];
+	        this.proxyPassword = source["proxyPassword"];
+	        this.forceNonStreamUpstream = source["forceNonStreamUpstream"];
+	        this.fallbackToDefaultModel = source["fallbackToDefaultModel"];
 	        this.models = this.convertValues(source["models"], storage.ModelMapping);
+	        this.schedule = this.convertValues(source["schedule"], storage.ScheduleWindow);
+	        this.dropParams = source["dropParams"];
+	        this.bodyOverrides = source["bodyOverrides"];
+	        this.bodyForce = source["bodyForce"];
+	        this.includeReasoning = source["includeReasoning"];
+	        this.reasoningSummary = source["reasoningSummary"];
+	        this.insecureSkipVerify = source["insecureSkipVerify"];
+	        this.forwardClientIP = source["forwardClientIP"];
+	        this.sandbox = source["sandbox"];
+	        this.caFile = source["caFile"];
+	        this.anthropicVersion = source["anthropicVersion"];
+	        this.anthropicBeta = source["anthropicBeta"];
+	        this.dailyTokenBudget = source["dailyTokenBudget"];
+	        this.maxTokensCap = source["maxTokensCap"];
+	        this.responseValidation = source["responseValidation"];
 	        this.remark = source["remark"];
 	        this.priority = source["priority"];
 	        this.todayRequests = source["todayRequests"];
 	        this.todayErrors = source["todayErrors"];
 	        this.todayInput = source["todayInput"];
 	        this.todayOutput = source["todayOutput"];
+	        this.lastUsedAt = source["lastUsedAt"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -162,8 +280,34 @@ export namespace main {
 	    transformer?: string;
 	    transformerSet?: boolean;
 	    proxyUrl?: string;
+	    proxyUsername?: string;
+	    proxyPassword?: string;
+	    forceNonStreamUpstream?: boolean;
+	    fallbackToDefaultModel?: boolean;
 	    models?: storage.ModelMapping[];
 	    modelsSet?: boolean;
+	    headers?: Record<string, string>;
+	    schedule?: storage.ScheduleWindow[];
+	    scheduleSet?: boolean;
+	    sandboxSet?: boolean;
+	    tlsSet?: boolean;
+	    dailyTokenBudgetSet?: boolean;
+	    maxTokensCapSet?: boolean;
+	    reasoningSet?: boolean;
+	    dropParams?: string[];
+	    bodyOverrides?: Record<string, any>;
+	    bodyForce?: boolean;
+	    includeReasoning?: boolean;
+	    reasoningSummary?: string;
+	    insecureSkipVerify?: boolean;
+	    forwardClientIP?: boolean;
+	    sandbox?: boolean;
+	    caFile?: string;
+	    anthropicVersion?: string;
+	    anthropicBeta?: string;
+	    dailyTokenBudget?: number;
+	    maxTokensCap?: number;
+	    responseValidation?: string[];
 	    remark?: string;
 	    priority: number;
 	
@@ -185,8 +329,34 @@ export namespace main {
 	        this.transformer = source["transformer"];
 	        this.transformerSet = source["transformerSet"];
 	        this.proxyUrl = source["proxyUrl"];
+	        this.proxyUsername = source["proxyUsername"];
+	        this.proxyPassword = source["proxyPassword"];
+	        this.forceNonStreamUpstream = source["forceNonStreamUpstream"];
+	        this.fallbackToDefaultModel = source["fallbackToDefaultModel"];
 	        this.models = this.convertValues(source["models"], storage.ModelMapping);
 	        this.modelsSet = source["modelsSet"];
+	        this.headers = source["headers"];
+	        this.schedule = this.convertValues(source["schedule"], storage.ScheduleWindow);
+	        this.scheduleSet = source["scheduleSet"];
+	        this.sandboxSet = source["sandboxSet"];
+	        this.tlsSet = source["tlsSet"];
+	        this.dailyTokenBudgetSet = source["dailyTokenBudgetSet"];
+	        this.maxTokensCapSet = source["maxTokensCapSet"];
+	        this.reasoningSet = source["reasoningSet"];
+	        this.dropParams = source["dropParams"];
+	        this.bodyOverrides = source["bodyOverrides"];
+	        this.bodyForce = source["bodyForce"];
+	        this.includeReasoning = source["includeReasoning"];
+	        this.reasoningSummary = source["reasoningSummary"];
+	        this.insecureSkipVerify = source["insecureSkipVerify"];
+	        this.forwardClientIP = source["forwardClientIP"];
+	        this.sandbox = source["sandbox"];
+	        this.caFile = source["caFile"];
+	        this.anthropicVersion = source["anthropicVersion"];
+	        this.anthropicBeta = source["anthropicBeta"];
+	        this.dailyTokenBudget = source["dailyTokenBudget"];
+	        this.maxTokensCap = source["maxTokensCap"];
+	        this.responseValidation = source["responseValidation"];
 	        this.remark = source["remark"];
 	        this.priority = source["priority"];
 	    }
@@ -241,12 +411,75 @@ export namespace main {
 	        this.requestCount = source["requestCount"];
 	    }
 	}
+	export class ExperimentStatsSummaryInfo {
+	    experiment: string;
+	    endpointId: string;
+	    endpointName: string;
+	    inputTokens: number;
+	    outputTokens: number;
+	    cachedCreate: number;
+	    cachedRead: number;
+	    reasoning: number;
+	    total: number;
+	    requestCount: number;
+	    errorCount: number;
+	    avgDurationMs: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ExperimentStatsSummaryInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.experiment = source["experiment"];
+	        this.endpointId = source["endpointId"];
+	        this.endpointName = source["endpointName"];
+	        this.inputTokens = source["inputTokens"];
+	        this.outputTokens = source["outputTokens"];
+	        this.cachedCreate = source["cachedCreate"];
+	        this.cachedRead = source["cachedRead"];
+	        this.reasoning = source["reasoning"];
+	        this.total = source["total"];
+	        this.requestCount = source["requestCount"];
+	        this.errorCount = source["errorCount"];
+	        this.avgDurationMs = source["avgDurationMs"];
+	    }
+	}
+	export class FallbackEventInfo {
+	    id: number;
+	    fromVendor: string;
+	    fromEndpoint: string;
+	    toVendor: string;
+	    toEndpoint: string;
+	    path: string;
+	    statusCode: number;
+	    errorMessage: string;
+	    time: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new FallbackEventInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.fromVendor = source["fromVendor"];
+	        this.fromEndpoint = source["fromEndpoint"];
+	        this.toVendor = source["toVendor"];
+	        this.toEndpoint = source["toEndpoint"];
+	        this.path = source["path"];
+	        this.statusCode = source["statusCode"];
+	        this.errorMessage = source["errorMessage"];
+	        this.time = source["time"];
+	    }
+	}
 	export class VendorInfo {
 	    id: number;
 	    name: string;
 	    homeUrl: string;
 	    apiUrl: string;
 	    remark?: string;
+	    headers?: Record<string, string>;
 	
 	    static createFrom(source: any = {}) {
 	        return new VendorInfo(source);
@@ -259,6 +492,7 @@ export namespace main {
 	        this.homeUrl = source["homeUrl"];
 	        this.apiUrl = source["apiUrl"];
 	        this.remark = source["remark"];
+	        this.headers = source["headers"];
 	    }
 	}
 	export class FullConfig {
@@ -295,6 +529,64 @@ export namespace main {
 		    return a;
 		}
 	}
+	export class InterfaceHealthInfo {
+	    interfaceType: string;
+	    activeEndpoint?: string;
+	    enabledCount: number;
+	    tempDisabledCount: number;
+	    requestCount: number;
+	    errorCount: number;
+	    errorRate: number;
+	    failing: boolean;
+	    status: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new InterfaceHealthInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.interfaceType = source["interfaceType"];
+	        this.activeEndpoint = source["activeEndpoint"];
+	        this.enabledCount = source["enabledCount"];
+	        this.tempDisabledCount = source["tempDisabledCount"];
+	        this.requestCount = source["requestCount"];
+	        this.errorCount = source["errorCount"];
+	        this.errorRate = source["errorRate"];
+	        this.failing = source["failing"];
+	        this.status = source["status"];
+	    }
+	}
+	export class HealthSummary {
+	    interfaceTypes: InterfaceHealthInfo[];
+	
+	    static createFrom(source: any = {}) {
+	        return new HealthSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.interfaceTypes = this.convertValues(source["interfaceTypes"], InterfaceHealthInfo);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class InterfaceTypeStatsSummaryInfo {
 	    interfaceType: string;
 	    inputTokens: number;
@@ -341,6 +633,50 @@ export namespace main {
 		    return a;
 		}
 	}
+	export class LanguageInfo {
+	    code: string;
+	    name: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LanguageInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.code = source["code"];
+	        this.name = source["name"];
+	    }
+	}
+	export class LatencyStatsSummaryInfo {
+	    interfaceType: string;
+	    vendorName: string;
+	    endpointId: string;
+	    endpointName: string;
+	    requestCount: number;
+	    avgDurationMs: number;
+	    streamCount: number;
+	    avgTtftMs: number;
+	    maxTtftMs: number;
+	    avgTokensPerSecond: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new LatencyStatsSummaryInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.interfaceType = source["interfaceType"];
+	        this.vendorName = source["vendorName"];
+	        this.endpointId = source["endpointId"];
+	        this.endpointName = source["endpointName"];
+	        this.requestCount = source["requestCount"];
+	        this.avgDurationMs = source["avgDurationMs"];
+	        this.streamCount = source["streamCount"];
+	        this.avgTtftMs = source["avgTtftMs"];
+	        this.maxTtftMs = source["maxTtftMs"];
+	        this.avgTokensPerSecond = source["avgTokensPerSecond"];
+	    }
+	}
 	export class LocalIPInfo {
 	    ip: string;
 	    interface: string;
@@ -389,6 +725,20 @@ export namespace main {
 	        this.authJson = source["authJson"];
 	    }
 	}
+	export class ProfileInfo {
+	    name: string;
+	    active: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProfileInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.active = source["active"];
+	    }
+	}
 	export class RequestLogDetailInfo {
 	    id: string;
 	    interfaceType: string;
@@ -405,6 +755,9 @@ export namespace main {
 	    requestHeaders: Record<string, string>;
 	    requestStream: string;
 	    responseStream: string;
+	    modelFallback?: string;
+	    maxTokensClamp?: string;
+	    authKey?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new RequestLogDetailInfo(source);
@@ -427,6 +780,9 @@ export namespace main {
 	        this.requestHeaders = source["requestHeaders"];
 	        this.requestStream = source["requestStream"];
 	        this.responseStream = source["responseStream"];
+	        this.modelFallback = source["modelFallback"];
+	        this.maxTokensClamp = source["maxTokensClamp"];
+	        this.authKey = source["authKey"];
 	    }
 	}
 	export class RequestLogInfo {
@@ -434,6 +790,7 @@ export namespace main {
 	    interfaceType: string;
 	    vendorName: string;
 	    endpointName: string;
+	    remark?: string;
 	    path: string;
 	    runTime: number;
 	    status: string;
@@ -449,16 +806,141 @@ export namespace main {
 	        this.interfaceType = source["interfaceType"];
 	        this.vendorName = source["vendorName"];
 	        this.endpointName = source["endpointName"];
+	        this.remark = source["remark"];
 	        this.path = source["path"];
 	        this.runTime = source["runTime"];
 	        this.status = source["status"];
 	        this.timestamp = source["timestamp"];
 	    }
 	}
+	export class RestoreDiffItem {
+	    kind: string;
+	    name: string;
+	    vendorName?: string;
+	    interfaceType?: string;
+	    changes?: string[];
+	    apiKeyChanged?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new RestoreDiffItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kind = source["kind"];
+	        this.name = source["name"];
+	        this.vendorName = source["vendorName"];
+	        this.interfaceType = source["interfaceType"];
+	        this.changes = source["changes"];
+	        this.apiKeyChanged = source["apiKeyChanged"];
+	    }
+	}
+	export class RestoreDiff {
+	    settingsChanges?: string[];
+	    proxyApiKeyChanged: boolean;
+	    added: RestoreDiffItem[];
+	    updated: RestoreDiffItem[];
+	    orphaned: RestoreDiffItem[];
+	    unchanged: number;
+	    apiKeysChanged: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RestoreDiff(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.settingsChanges = source["settingsChanges"];
+	        this.proxyApiKeyChanged = source["proxyApiKeyChanged"];
+	        this.added = this.convertValues(source["added"], RestoreDiffItem);
+	        this.updated = this.convertValues(source["updated"], RestoreDiffItem);
+	        this.orphaned = this.convertValues(source["orphaned"], RestoreDiffItem);
+	        this.unchanged = source["unchanged"];
+	        this.apiKeysChanged = source["apiKeysChanged"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RouterEndpointStateInfo {
+	    id: number;
+	    name: string;
+	    enabled: boolean;
+	    active: boolean;
+	    preferred: boolean;
+	    inSchedule: boolean;
+	    tempDisabledUntil?: number;
+	    manualDisabled?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new RouterEndpointStateInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.enabled = source["enabled"];
+	        this.active = source["active"];
+	        this.preferred = source["preferred"];
+	        this.inSchedule = source["inSchedule"];
+	        this.tempDisabledUntil = source["tempDisabledUntil"];
+	        this.manualDisabled = source["manualDisabled"];
+	    }
+	}
+	export class RouterTypeState {
+	    activeId?: number;
+	    endpoints: RouterEndpointStateInfo[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RouterTypeState(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.activeId = source["activeId"];
+	        this.endpoints = this.convertValues(source["endpoints"], RouterEndpointStateInfo);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class Settings {
 	    port: number;
 	    apiKey: string;
 	    fallback: boolean;
+	    defaultEndpointPriority?: number;
+	    defaultInterfaceType?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new Settings(source);
@@ -469,6 +951,8 @@ export namespace main {
 	        this.port = source["port"];
 	        this.apiKey = source["apiKey"];
 	        this.fallback = source["fallback"];
+	        this.defaultEndpointPriority = source["defaultEndpointPriority"];
+	        this.defaultInterfaceType = source["defaultInterfaceType"];
 	    }
 	}
 	export class TestEndpointParams {
@@ -477,6 +961,7 @@ export namespace main {
 	    interfaceType: string;
 	    model: string;
 	    reasoning?: string;
+	    stream?: boolean;
 	
 	    static createFrom(source: any = {}) {
 	        return new TestEndpointParams(source);
@@ -489,6 +974,39 @@ export namespace main {
 	        this.interfaceType = source["interfaceType"];
 	        this.model = source["model"];
 	        this.reasoning = source["reasoning"];
+	        this.stream = source["stream"];
+	    }
+	}
+	export class TestEndpointResult {
+	    success: boolean;
+	    statusCode?: number;
+	    message: string;
+	    targetUrl?: string;
+	    requestHeaders?: Record<string, string>;
+	    errorMessage?: string;
+	    responseText?: string;
+	    streaming?: boolean;
+	    streamOk?: boolean;
+	    firstTokenMs?: number;
+	    streamEvents?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TestEndpointResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.success = source["success"];
+	        this.statusCode = source["statusCode"];
+	        this.message = source["message"];
+	        this.targetUrl = source["targetUrl"];
+	        this.requestHeaders = source["requestHeaders"];
+	        this.errorMessage = source["errorMessage"];
+	        this.responseText = source["responseText"];
+	        this.streaming = source["streaming"];
+	        this.streamOk = source["streamOk"];
+	        this.firstTokenMs = source["firstTokenMs"];
+	        this.streamEvents = source["streamEvents"];
 	    }
 	}
 	export class TokenStatsInfo {
@@ -518,6 +1036,34 @@ export namespace main {
 	    }
 	}
 	
+	export class UserStatsSummaryInfo {
+	    userId: string;
+	    inputTokens: number;
+	    outputTokens: number;
+	    cachedCreate: number;
+	    cachedRead: number;
+	    reasoning: number;
+	    total: number;
+	    requestCount: number;
+	    errorCount: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new UserStatsSummaryInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.userId = source["userId"];
+	        this.inputTokens = source["inputTokens"];
+	        this.outputTokens = source["outputTokens"];
+	        this.cachedCreate = source["cachedCreate"];
+	        this.cachedRead = source["cachedRead"];
+	        this.reasoning = source["reasoning"];
+	        this.total = source["total"];
+	        this.requestCount = source["requestCount"];
+	        this.errorCount = source["errorCount"];
+	    }
+	}
 	export class VendorStatsSummaryInfo {
 	    vendorId: string;
 	    vendorName: string;
@@ -568,6 +1114,7 @@ export namespace main {
 	    serverUrl: string;
 	    username: string;
 	    password: string;
+	    autoSyncMinutes: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new WebDAVConfigInput(source);
@@ -578,6 +1125,7 @@ export namespace main {
 	        this.serverUrl = source["serverUrl"];
 	        this.username = source["username"];
 	        this.password = source["password"];
+	        this.autoSyncMinutes = source["autoSyncMinutes"];
 	    }
 	}
 	export class WebDAVRequestInput {
@@ -622,6 +1170,24 @@ export namespace main {
 		    return a;
 		}
 	}
+	export class WebDAVTestResult {
+	    success: boolean;
+	    statusCode?: number;
+	    latency: number;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new WebDAVTestResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.success = source["success"];
+	        this.statusCode = source["statusCode"];
+	        this.latency = source["latency"];
+	        this.message = source["message"];
+	    }
+	}
 
 }
 
@@ -706,6 +1272,7 @@ export namespace storage {
 	export class ModelMapping {
 	    name: string;
 	    alias: string;
+	    matchType?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new ModelMapping(source);
@@ -715,6 +1282,23 @@ export namespace storage {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.name = source["name"];
 	        this.alias = source["alias"];
+	        this.matchType = source["matchType"];
+	    }
+	}
+	export class ScheduleWindow {
+	    days?: number[];
+	    startHHMM: string;
+	    endHHMM: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScheduleWindow(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.days = source["days"];
+	        this.startHHMM = source["startHHMM"];
+	        this.endHHMM = source["endHHMM"];
 	    }
 	}
 
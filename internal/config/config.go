@@ -32,36 +32,64 @@ var (
 
 // VendorConfig represents vendor configuration in JSON
 type VendorConfig struct {
-	ID        int64            `json:"id,omitempty"`
-	Name      string           `json:"name"`
-	HomeURL   string           `json:"homeUrl"`
-	APIURL    string           `json:"apiUrl"`
-	Remark    string           `json:"remark,omitempty"`
-	Endpoints []EndpointConfig `json:"endpoints"`
+	ID        int64             `json:"id,omitempty"`
+	Name      string            `json:"name"`
+	HomeURL   string            `json:"homeUrl"`
+	APIURL    string            `json:"apiUrl"`
+	Remark    string            `json:"remark,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"` // default headers inherited by the vendor's endpoints
+	Endpoints []EndpointConfig  `json:"endpoints"`
 }
 
 // EndpointConfig represents endpoint configuration in JSON
 type EndpointConfig struct {
-	ID            int64             `json:"id,omitempty"`
-	Name          string            `json:"name"`
-	APIURL        string            `json:"apiUrl"`
-	APIKey        string            `json:"apiKey"`
-	Active        bool              `json:"active"`
-	Enabled       bool              `json:"enabled"`
-	InterfaceType string            `json:"interfaceType"`
-	Transformer   string            `json:"transformer,omitempty"`
-	Model         string            `json:"model,omitempty"`
-	Remark        string            `json:"remark,omitempty"`
-	Priority      int               `json:"priority,omitempty"`
-	ProxyURL      string            `json:"proxyUrl,omitempty"`
-	Models        []ModelMapping    `json:"models,omitempty"`
-	Headers       map[string]string `json:"headers,omitempty"`
+	ID                     int64             `json:"id,omitempty"`
+	Name                   string            `json:"name"`
+	APIURL                 string            `json:"apiUrl"`
+	APIKey                 string            `json:"apiKey"`
+	Active                 bool              `json:"active"`
+	Enabled                bool              `json:"enabled"`
+	InterfaceType          string            `json:"interfaceType"`
+	Transformer            string            `json:"transformer,omitempty"`
+	Model                  string            `json:"model,omitempty"`
+	Remark                 string            `json:"remark,omitempty"`
+	Priority               int               `json:"priority,omitempty"`
+	ProxyURL               string            `json:"proxyUrl,omitempty"`
+	ProxyUsername          string            `json:"proxyUsername,omitempty"`
+	ProxyPassword          string            `json:"proxyPassword,omitempty"`
+	ForceNonStreamUpstream bool              `json:"forceNonStreamUpstream,omitempty"`
+	FallbackToDefaultModel bool              `json:"fallbackToDefaultModel,omitempty"`
+	Models                 []ModelMapping    `json:"models,omitempty"`
+	Headers                map[string]string `json:"headers,omitempty"`
+	DropParams             []string          `json:"dropParams,omitempty"`
+	BodyOverrides          map[string]any    `json:"bodyOverrides,omitempty"`
+	BodyForce              bool              `json:"bodyForce,omitempty"`
+	IncludeReasoning       bool              `json:"includeReasoning,omitempty"`
+	ReasoningSummary       string            `json:"reasoningSummary,omitempty"`
+	InsecureSkipVerify     bool              `json:"insecureSkipVerify,omitempty"`
+	ForwardClientIP        bool              `json:"forwardClientIP,omitempty"`
+	Sandbox                bool              `json:"sandbox,omitempty"`
+	CAFile                 string            `json:"caFile,omitempty"`
+	AnthropicVersion       string            `json:"anthropicVersion,omitempty"`
+	AnthropicBeta          string            `json:"anthropicBeta,omitempty"`
+	DailyTokenBudget       int64             `json:"dailyTokenBudget,omitempty"`
+	MaxTokensCap           int               `json:"maxTokensCap,omitempty"`
+	ResponseValidation     []string          `json:"responseValidation,omitempty"`
+	Schedule               []ScheduleWindow  `json:"schedule,omitempty"`
+}
+
+// ScheduleWindow represents a weekly time window during which an endpoint is routable
+type ScheduleWindow struct {
+	Days      []int  `json:"days,omitempty"` // 0=周日 ... 6=周六，空表示每天
+	StartHHMM string `json:"startHHMM"`      // 开始时间 "HH:MM"（含）
+	EndHHMM   string `json:"endHHMM"`        // 结束时间 "HH:MM"（不含），早于开始时间表示跨午夜
 }
 
 // ModelMapping represents a model name mapping configuration
 type ModelMapping struct {
-	Name  string `json:"name"`  // 实际模型名（上游模型名）
-	Alias string `json:"alias"` // API 使用的别名（客户端传入的 model）
+	Name      string `json:"name"`                // 实际模型名（上游模型名）
+	Alias     string `json:"alias"`               // API 使用的别名（客户端传入的 model）
+	MatchType string `json:"matchType,omitempty"` // 别名匹配方式: exact(默认) | prefix | regex
 }
 
 // AppConfig represents the complete application configuration
@@ -337,6 +365,8 @@ func (s *ConfigSyncer) SyncToDatabase() (vendorCount, endpointCount int, err err
 		return 0, 0, fmt.Errorf("failed to load config: %w", loadErr)
 	}
 
+	defaults := config.EndpointDefaults()
+
 	// Sync vendors and their endpoints
 	for _, vendorConfig := range config.Vendors {
 		vendor := &Vendor{
@@ -358,23 +388,17 @@ func (s *ConfigSyncer) SyncToDatabase() (vendorCount, endpointCount int, err err
 				continue
 			}
 
-			// Default priority to 5 if not set
-			priority := endpointConfig.Priority
-			if priority == 0 {
-				priority = 5
-			}
-
 			endpoint := &Endpoint{
 				Name:          endpointConfig.Name,
 				APIURL:        endpointConfig.APIURL,
 				APIKey:        endpointConfig.APIKey,
 				Active:        endpointConfig.Active,
 				Enabled:       endpointConfig.Enabled,
-				InterfaceType: endpointConfig.InterfaceType,
+				InterfaceType: defaults.ResolveInterfaceType(endpointConfig.InterfaceType),
 				VendorID:      vendor.ID,
 				Model:         endpointConfig.Model,
 				Remark:        endpointConfig.Remark,
-				Priority:      priority,
+				Priority:      defaults.ResolvePriority(endpointConfig.Priority),
 			}
 
 			if err := s.storage.SaveEndpoint(endpoint); err != nil {
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// AppConfigKeyDefaultEndpointPriority is the appConfig key for the priority of endpoints that omit one
+	AppConfigKeyDefaultEndpointPriority = "defaultEndpointPriority"
+	// AppConfigKeyDefaultInterfaceType is the appConfig key for the interface type of endpoints that omit one
+	AppConfigKeyDefaultInterfaceType = "defaultInterfaceType"
+	// DefaultEndpointPriority is used when neither the endpoint nor appConfig sets a priority
+	DefaultEndpointPriority = 5
+)
+
+// EndpointDefaults holds instance-wide values applied to endpoints that omit them
+type EndpointDefaults struct {
+	Priority      int
+	InterfaceType string // empty when no default is configured
+}
+
+// LoadEndpointDefaults reads the endpoint defaults through get, which returns the
+// string value of an appConfig key ("" when unset). Invalid values are ignored.
+func LoadEndpointDefaults(get func(key string) string) EndpointDefaults {
+	defaults := EndpointDefaults{Priority: DefaultEndpointPriority}
+	if p, err := strconv.Atoi(strings.TrimSpace(get(AppConfigKeyDefaultEndpointPriority))); err == nil && p > 0 {
+		defaults.Priority = p
+	}
+	if t := get(AppConfigKeyDefaultInterfaceType); ValidateDefaultInterfaceType(t) == nil {
+		defaults.InterfaceType = strings.ToLower(strings.TrimSpace(t))
+	}
+	return defaults
+}
+
+// ValidateDefaultInterfaceType checks a defaultInterfaceType value: empty (no default)
+// or one of claude, codex, gemini and chat, case-insensitive
+func ValidateDefaultInterfaceType(value string) error {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "claude", "codex", "gemini", "chat":
+		return nil
+	}
+	return fmt.Errorf("invalid %s %q: must be claude, codex, gemini or chat", AppConfigKeyDefaultInterfaceType, value)
+}
+
+// EndpointDefaults returns the endpoint defaults configured in appConfig
+func (c *AppConfig) EndpointDefaults() EndpointDefaults {
+	return LoadEndpointDefaults(func(key string) string {
+		if c == nil || c.AppConfigKV[key] == nil {
+			return ""
+		}
+		switch v := c.AppConfigKV[key].(type) {
+		case string:
+			return v
+		case float64:
+			return fmt.Sprintf("%.0f", v)
+		default:
+			return fmt.Sprintf("%v", v)
+		}
+	})
+}
+
+// ResolvePriority returns priority, or the default when it is unset (0)
+func (d EndpointDefaults) ResolvePriority(priority int) int {
+	if priority == 0 {
+		return d.Priority
+	}
+	return priority
+}
+
+// ResolveInterfaceType returns interfaceType, or the default when it is empty
+func (d EndpointDefaults) ResolveInterfaceType(interfaceType string) string {
+	if strings.TrimSpace(interfaceType) == "" {
+		return d.InterfaceType
+	}
+	return interfaceType
+}
@@ -0,0 +1,39 @@
+package config
+
+import "testing"
+
+func TestLoadEndpointDefaults(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name          string
+		kv            map[string]string
+		wantPriority  int
+		wantInterface string
+	}{
+		{name: "unset", wantPriority: DefaultEndpointPriority},
+		{name: "configured", kv: map[string]string{AppConfigKeyDefaultEndpointPriority: "2", AppConfigKeyDefaultInterfaceType: " Codex "}, wantPriority: 2, wantInterface: "codex"},
+		{name: "invalid values ignored", kv: map[string]string{AppConfigKeyDefaultEndpointPriority: "-1", AppConfigKeyDefaultInterfaceType: "openai"}, wantPriority: DefaultEndpointPriority},
+	}
+	for _, tc := range cases {
+		got := LoadEndpointDefaults(func(key string) string { return tc.kv[key] })
+		if got.Priority != tc.wantPriority || got.InterfaceType != tc.wantInterface {
+			t.Fatalf("%s: got %+v want priority=%d interface=%q", tc.name, got, tc.wantPriority, tc.wantInterface)
+		}
+	}
+}
+
+func TestValidateDefaultInterfaceType(t *testing.T) {
+	t.Parallel()
+
+	for _, value := range []string{"", "claude", "codex", "gemini", " Chat "} {
+		if err := ValidateDefaultInterfaceType(value); err != nil {
+			t.Fatalf("%q: unexpected error: %v", value, err)
+		}
+	}
+	for _, value := range []string{"openai", "claude,codex"} {
+		if err := ValidateDefaultInterfaceType(value); err == nil {
+			t.Fatalf("%q: expected error", value)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"net/url"
+	"strings"
+)
+
+// DetectInterfaceTypeFromURL guesses the interface type of an endpoint from its API URL
+// using well-known hosts and paths. It returns "" when the URL gives no hint; the result
+// is only a suggestion and callers should let the user override it.
+func DetectInterfaceTypeFromURL(apiURL string) string {
+	raw := strings.TrimSpace(apiURL)
+	if raw == "" {
+		return ""
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	path := strings.ToLower(strings.TrimRight(u.Path, "/"))
+
+	// Explicit API paths win over the host: many gateways serve several formats
+	switch {
+	case strings.Contains(path, "/chat/completions"):
+		return "chat"
+	case strings.HasSuffix(path, "/responses"):
+		return "codex"
+	case strings.HasSuffix(path, "/messages"), strings.HasSuffix(path, "/anthropic"):
+		return "claude"
+	case strings.Contains(path, ":generatecontent"), strings.Contains(path, ":streamgeneratecontent"):
+		return "gemini"
+	}
+
+	switch {
+	case hostMatches(host, "anthropic.com"):
+		return "claude"
+	case hostMatches(host, "generativelanguage.googleapis.com"):
+		return "gemini"
+	case hostMatches(host, "openai.com"), hostMatches(host, "chatgpt.com"):
+		return "codex"
+	}
+	return ""
+}
+
+// hostMatches reports whether host is domain or one of its subdomains
+func hostMatches(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
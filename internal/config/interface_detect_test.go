@@ -0,0 +1,34 @@
+package config
+
+import "testing"
+
+func TestDetectInterfaceTypeFromURL(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{name: "empty", url: "  ", want: ""},
+		{name: "anthropic host", url: "https://api.anthropic.com", want: "claude"},
+		{name: "anthropic host without scheme", url: "api.anthropic.com/", want: "claude"},
+		{name: "gemini host", url: "https://generativelanguage.googleapis.com/v1beta", want: "gemini"},
+		{name: "openai host", url: "https://api.openai.com/v1", want: "codex"},
+		{name: "chatgpt host", url: "https://chatgpt.com/backend-api/codex", want: "codex"},
+		{name: "lookalike host", url: "https://notanthropic.com", want: ""},
+		{name: "unknown host", url: "https://gateway.example.com/v1", want: ""},
+		{name: "chat completions path wins over host", url: "https://api.openai.com/v1/chat/completions", want: "chat"},
+		{name: "responses path", url: "https://gateway.example.com/v1/responses/", want: "codex"},
+		{name: "messages path", url: "https://gateway.example.com/v1/messages", want: "claude"},
+		{name: "anthropic-compatible path", url: "https://open.bigmodel.cn/api/anthropic", want: "claude"},
+		{name: "generateContent path", url: "https://gateway.example.com/v1beta/models/gemini-pro:generateContent", want: "gemini"},
+		{name: "streamGenerateContent path", url: "https://gateway.example.com/v1beta/models/gemini-pro:streamGenerateContent", want: "gemini"},
+		{name: "unparsable", url: "http://[::1", want: ""},
+	}
+	for _, tc := range cases {
+		if got := DetectInterfaceTypeFromURL(tc.url); got != tc.want {
+			t.Fatalf("%s: DetectInterfaceTypeFromURL(%q)=%q want %q", tc.name, tc.url, got, tc.want)
+		}
+	}
+}
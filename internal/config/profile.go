@@ -0,0 +1,127 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// DefaultProfileName is the profile backed by config.json
+	DefaultProfileName = "default"
+	// ActiveProfileFileName stores the name of the active profile in the data dir
+	ActiveProfileFileName = "active_profile"
+)
+
+// Profile errors
+var (
+	ErrInvalidProfileName = errors.New("profile name may only contain letters, digits, '-' and '_'")
+	ErrProfileNotFound    = errors.New("profile not found")
+	ErrProfileExists      = errors.New("profile already exists")
+)
+
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// profileFilePattern matches named profile files: config.<name>.json
+var profileFilePattern = regexp.MustCompile(`^config\.([A-Za-z0-9_-]{1,64})\.json$`)
+
+// ValidateProfileName checks that a profile name is safe to use as part of a file name
+func ValidateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return ErrInvalidProfileName
+	}
+	return nil
+}
+
+// ProfileConfigPath returns the config file path of a profile in dataDir.
+// The default profile uses config.json, others use config.<name>.json.
+func ProfileConfigPath(dataDir, name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" || name == DefaultProfileName {
+		return filepath.Join(dataDir, ConfigFileName)
+	}
+	return filepath.Join(dataDir, "config."+name+".json")
+}
+
+// ListProfiles returns all profile names found in dataDir, default first
+func ListProfiles(dataDir string) ([]string, error) {
+	entries, err := os.ReadDir(dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data dir: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if m := profileFilePattern.FindStringSubmatch(entry.Name()); m != nil && m[1] != DefaultProfileName {
+			names = append(names, m[1])
+		}
+	}
+	sort.Strings(names)
+	return append([]string{DefaultProfileName}, names...), nil
+}
+
+// ReadActiveProfile returns the active profile name stored in dataDir.
+// Falls back to the default profile when the pointer file is missing, invalid
+// or points to a profile whose config file no longer exists.
+func ReadActiveProfile(dataDir string) string {
+	data, err := os.ReadFile(filepath.Join(dataDir, ActiveProfileFileName))
+	if err != nil {
+		return DefaultProfileName
+	}
+	name := strings.TrimSpace(string(data))
+	if ValidateProfileName(name) != nil {
+		return DefaultProfileName
+	}
+	if name != DefaultProfileName && !fileExists(ProfileConfigPath(dataDir, name)) {
+		return DefaultProfileName
+	}
+	return name
+}
+
+// WriteActiveProfile persists the active profile name in dataDir
+func WriteActiveProfile(dataDir, name string) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	path := filepath.Join(dataDir, ActiveProfileFileName)
+	if err := os.WriteFile(path, []byte(name+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write active profile: %w", err)
+	}
+	return nil
+}
+
+// CreateProfile creates an empty config file for a new profile in dataDir
+func CreateProfile(dataDir, name string) (string, error) {
+	if err := ValidateProfileName(name); err != nil {
+		return "", err
+	}
+	path := ProfileConfigPath(dataDir, name)
+	if fileExists(path) {
+		return "", ErrProfileExists
+	}
+
+	emptyConfig := &AppConfig{Vendors: []VendorConfig{}}
+	data, err := emptyConfig.ToJSON()
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize empty config: %w", err)
+	}
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create data dir: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to create profile config: %w", err)
+	}
+	return path, nil
+}
+
+// ProfileExists reports whether the config file for a profile exists in dataDir
+func ProfileExists(dataDir, name string) bool {
+	return fileExists(ProfileConfigPath(dataDir, name))
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateProfileName(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		profile string
+		valid   bool
+	}{
+		{name: "letters digits dash underscore", profile: "Work-2_a", valid: true},
+		{name: "default", profile: DefaultProfileName, valid: true},
+		{name: "max length", profile: strings.Repeat("a", 64), valid: true},
+		{name: "empty", profile: ""},
+		{name: "too long", profile: strings.Repeat("a", 65)},
+		{name: "path traversal", profile: "../config"},
+		{name: "dot", profile: "a.b"},
+		{name: "space", profile: "a b"},
+		{name: "separator", profile: "a/b"},
+	}
+	for _, tc := range cases {
+		err := ValidateProfileName(tc.profile)
+		if tc.valid && err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if !tc.valid && !errors.Is(err, ErrInvalidProfileName) {
+			t.Fatalf("%s: err=%v want ErrInvalidProfileName", tc.name, err)
+		}
+	}
+}
+
+func TestProfileConfigPath(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	cases := []struct {
+		name    string
+		profile string
+		want    string
+	}{
+		{name: "empty is default", profile: "", want: ConfigFileName},
+		{name: "default", profile: DefaultProfileName, want: ConfigFileName},
+		{name: "named", profile: "work", want: "config.work.json"},
+		{name: "trimmed", profile: " work ", want: "config.work.json"},
+	}
+	for _, tc := range cases {
+		if got := ProfileConfigPath(dir, tc.profile); got != filepath.Join(dir, tc.want) {
+			t.Fatalf("%s: got %q want %q", tc.name, got, filepath.Join(dir, tc.want))
+		}
+	}
+}
+
+func TestReadActiveProfile(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		pointer  *string // nil: no active_profile file
+		profiles []string
+		want     string
+	}{
+		{name: "no pointer file", profiles: []string{"work"}, want: DefaultProfileName},
+		{name: "named profile", pointer: strPtr("work\n"), profiles: []string{"work"}, want: "work"},
+		{name: "missing profile file", pointer: strPtr("gone"), profiles: []string{"work"}, want: DefaultProfileName},
+		{name: "invalid name", pointer: strPtr("../work"), profiles: []string{"work"}, want: DefaultProfileName},
+		{name: "default without config.json", pointer: strPtr(DefaultProfileName), want: DefaultProfileName},
+	}
+	for _, tc := range cases {
+		dir := t.TempDir()
+		for _, profile := range tc.profiles {
+			if _, err := CreateProfile(dir, profile); err != nil {
+				t.Fatalf("%s: CreateProfile: %v", tc.name, err)
+			}
+		}
+		if tc.pointer != nil {
+			if err := os.WriteFile(filepath.Join(dir, ActiveProfileFileName), []byte(*tc.pointer), 0644); err != nil {
+				t.Fatalf("%s: write pointer: %v", tc.name, err)
+			}
+		}
+		if got := ReadActiveProfile(dir); got != tc.want {
+			t.Fatalf("%s: got %q want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestWriteActiveProfile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if _, err := CreateProfile(dir, "work"); err != nil {
+		t.Fatalf("CreateProfile: %v", err)
+	}
+
+	// Each step switches the pointer and reads it back; the last one switches back to default
+	steps := []struct {
+		name    string
+		profile string
+		wantErr error
+		want    string
+	}{
+		{name: "switch to named", profile: "work", want: "work"},
+		{name: "invalid name keeps pointer", profile: "a/b", wantErr: ErrInvalidProfileName, want: "work"},
+		{name: "switch back to default", profile: DefaultProfileName, want: DefaultProfileName},
+	}
+	for _, step := range steps {
+		err := WriteActiveProfile(dir, step.profile)
+		if !errors.Is(err, step.wantErr) {
+			t.Fatalf("%s: err=%v want %v", step.name, err, step.wantErr)
+		}
+		if got := ReadActiveProfile(dir); got != step.want {
+			t.Fatalf("%s: active profile %q want %q", step.name, got, step.want)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, ActiveProfileFileName))
+	if err != nil || string(data) != DefaultProfileName+"\n" {
+		t.Fatalf("pointer file=%q err=%v", data, err)
+	}
+}
+
+func TestListProfiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for _, name := range []string{"zeta", "alpha"} {
+		if _, err := CreateProfile(dir, name); err != nil {
+			t.Fatalf("CreateProfile(%s): %v", name, err)
+		}
+	}
+	// Files that are not named profiles are ignored
+	for _, name := range []string{ConfigFileName, "config.default.json", "config.a.b.json", "notes.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	got, err := ListProfiles(dir)
+	if err != nil {
+		t.Fatalf("ListProfiles: %v", err)
+	}
+	if want := []string{DefaultProfileName, "alpha", "zeta"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	if _, err := CreateProfile(dir, "alpha"); !errors.Is(err, ErrProfileExists) {
+		t.Fatalf("duplicate profile: err=%v want ErrProfileExists", err)
+	}
+}
+
+func strPtr(s string) *string { return &s }
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// SecretEnvVar holds the passphrase used to encrypt secrets stored in config.json
+	SecretEnvVar = "CLISHUB_SECRET"
+	// encryptedSecretPrefix marks a value produced by EncryptSecret
+	encryptedSecretPrefix = "enc:v1:"
+	// secretSaltSize is the length of the random per-value salt stored before the nonce
+	secretSaltSize = 16
+)
+
+// scrypt parameters for deriving the AES-256 key from CLISHUB_SECRET (RFC 7914 recommendations
+// for interactive use). Variables so tests can lower the cost.
+var (
+	secretScryptN = 1 << 15
+	secretScryptR = 8
+	secretScryptP = 1
+)
+
+// ErrSecretKeyMissing is returned when an encrypted value is read without CLISHUB_SECRET set
+var ErrSecretKeyMissing = errors.New(SecretEnvVar + " is required to decrypt stored secrets")
+
+// secretCipher derives an AES-256-GCM cipher from CLISHUB_SECRET and salt with scrypt.
+// It returns nil when CLISHUB_SECRET is unset.
+func secretCipher(salt []byte) (cipher.AEAD, error) {
+	passphrase := os.Getenv(SecretEnvVar)
+	if passphrase == "" {
+		return nil, nil
+	}
+	key, err := scrypt.Key([]byte(passphrase), salt, secretScryptN, secretScryptR, secretScryptP, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive secret key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptSecret encrypts a secret for storage when CLISHUB_SECRET is set.
+// Without it the value is returned unchanged (stored as plaintext).
+// The stored form is the prefix followed by base64(salt | nonce | ciphertext).
+func EncryptSecret(plain string) (string, error) {
+	if plain == "" {
+		return "", nil
+	}
+	if os.Getenv(SecretEnvVar) == "" {
+		return plain, nil
+	}
+	salt := make([]byte, secretSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+	aead, err := secretCipher(salt)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := append(salt, nonce...)
+	sealed = aead.Seal(sealed, nonce, []byte(plain), nil)
+	return encryptedSecretPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptSecret reverses EncryptSecret. Plaintext values are returned unchanged.
+func DecryptSecret(stored string) (string, error) {
+	if !strings.HasPrefix(stored, encryptedSecretPrefix) {
+		return stored, nil
+	}
+	if os.Getenv(SecretEnvVar) == "" {
+		return "", ErrSecretKeyMissing
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(stored, encryptedSecretPrefix))
+	if err != nil || len(sealed) < secretSaltSize {
+		return "", fmt.Errorf("invalid encrypted secret")
+	}
+	salt, sealed := sealed[:secretSaltSize], sealed[secretSaltSize:]
+	aead, err := secretCipher(salt)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("invalid encrypted secret")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret (wrong %s?): %w", SecretEnvVar, err)
+	}
+	return string(plain), nil
+}
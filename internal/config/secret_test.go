@@ -0,0 +1,98 @@
+package config
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// lowScryptCost makes key derivation cheap for tests and restores the defaults afterwards.
+// Tests using it, like those calling t.Setenv, must not run in parallel.
+func lowScryptCost(t *testing.T) {
+	n := secretScryptN
+	secretScryptN = 1 << 10
+	t.Cleanup(func() { secretScryptN = n })
+}
+
+func TestSecretRoundTrip(t *testing.T) {
+	lowScryptCost(t)
+	t.Setenv(SecretEnvVar, "passphrase")
+
+	for _, plain := range []string{"p@ssw0rd", "密码", strings.Repeat("x", 4096)} {
+		stored, err := EncryptSecret(plain)
+		if err != nil {
+			t.Fatalf("encrypt: %v", err)
+		}
+		if !strings.HasPrefix(stored, encryptedSecretPrefix) || strings.Contains(stored, plain) {
+			t.Fatalf("stored value not encrypted: %q", stored)
+		}
+		got, err := DecryptSecret(stored)
+		if err != nil || got != plain {
+			t.Fatalf("decrypt=%q err=%v want %q", got, err, plain)
+		}
+	}
+
+	// Every value gets its own salt and nonce
+	a, _ := EncryptSecret("same")
+	b, _ := EncryptSecret("same")
+	rawA, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(a, encryptedSecretPrefix))
+	rawB, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(b, encryptedSecretPrefix))
+	if a == b || string(rawA[:secretSaltSize]) == string(rawB[:secretSaltSize]) {
+		t.Fatalf("salt reused across encryptions")
+	}
+}
+
+func TestDecryptSecretErrors(t *testing.T) {
+	lowScryptCost(t)
+	t.Setenv(SecretEnvVar, "passphrase")
+	stored, err := EncryptSecret("secret")
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+
+	cases := []struct {
+		name       string
+		passphrase string
+		stored     string
+		want       string
+		wantErr    error
+		anyErr     bool
+	}{
+		{name: "wrong key", passphrase: "other", stored: stored, anyErr: true},
+		{name: "missing key", passphrase: "", stored: stored, wantErr: ErrSecretKeyMissing},
+		{name: "truncated", passphrase: "passphrase", stored: encryptedSecretPrefix + base64.StdEncoding.EncodeToString([]byte("short")), anyErr: true},
+		{name: "not base64", passphrase: "passphrase", stored: encryptedSecretPrefix + "!!!", anyErr: true},
+		{name: "unprefixed value passes through", passphrase: "passphrase", stored: "plain-password", want: "plain-password"},
+		{name: "unprefixed value without key", passphrase: "", stored: "plain-password", want: "plain-password"},
+	}
+	for _, tc := range cases {
+		t.Setenv(SecretEnvVar, tc.passphrase)
+		got, err := DecryptSecret(tc.stored)
+		switch {
+		case tc.wantErr != nil:
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("%s: err=%v want %v", tc.name, err, tc.wantErr)
+			}
+		case tc.anyErr:
+			if err == nil {
+				t.Fatalf("%s: decrypted to %q, want error", tc.name, got)
+			}
+		default:
+			if err != nil || got != tc.want {
+				t.Fatalf("%s: got %q err=%v want %q", tc.name, got, err, tc.want)
+			}
+		}
+	}
+}
+
+func TestEncryptSecretWithoutKey(t *testing.T) {
+	t.Setenv(SecretEnvVar, "")
+
+	for _, plain := range []string{"", "plain"} {
+		stored, err := EncryptSecret(plain)
+		if err != nil || stored != plain {
+			t.Fatalf("EncryptSecret(%q)=%q err=%v, want value stored as is", plain, stored, err)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package executor
+
+import (
+	"net/http"
+	"strings"
+)
+
+// applyAnthropicHeaders 目标接口为 claude 时，用端点固定的 anthropic-version / anthropic-beta
+// 覆盖客户端发送的值，方便对接只接受特定版本或 beta 组合的网关
+func applyAnthropicHeaders(req *http.Request, endpoint *EndpointConfig, targetInterfaceType string) {
+	if req == nil || endpoint == nil || !strings.EqualFold(strings.TrimSpace(targetInterfaceType), "claude") {
+		return
+	}
+	if version := strings.TrimSpace(endpoint.AnthropicVersion); version != "" {
+		req.Header.Set("anthropic-version", version)
+	}
+	if beta := strings.TrimSpace(endpoint.AnthropicBeta); beta != "" {
+		req.Header.Set("anthropic-beta", beta)
+	}
+}
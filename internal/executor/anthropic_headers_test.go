@@ -0,0 +1,33 @@
+package executor
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestApplyAnthropicHeaders(t *testing.T) {
+	t.Parallel()
+
+	endpoint := &EndpointConfig{AnthropicVersion: "2023-06-01", AnthropicBeta: "prompt-caching-2024-07-31"}
+	cases := []struct {
+		name        string
+		target      string
+		wantVersion string
+		wantBeta    string
+	}{
+		{name: "claude overrides", target: "claude", wantVersion: "2023-06-01", wantBeta: "prompt-caching-2024-07-31"},
+		{name: "other target untouched", target: "codex", wantVersion: "2024-01-01", wantBeta: "client-beta"},
+	}
+	for _, tc := range cases {
+		req, _ := http.NewRequest(http.MethodPost, "http://example.com/v1/messages", nil)
+		req.Header.Set("anthropic-version", "2024-01-01")
+		req.Header.Set("anthropic-beta", "client-beta")
+		applyAnthropicHeaders(req, endpoint, tc.target)
+		if got := req.Header.Get("anthropic-version"); got != tc.wantVersion {
+			t.Fatalf("%s: anthropic-version=%q want %q", tc.name, got, tc.wantVersion)
+		}
+		if got := req.Header.Get("anthropic-beta"); got != tc.wantBeta {
+			t.Fatalf("%s: anthropic-beta=%q want %q", tc.name, got, tc.wantBeta)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package executor
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"clisimplehub/internal/logger"
+)
+
+// apiKeyFilePrefix 标记从文件读取的 API Key，例如 "file:/run/secrets/openai"（Kubernetes Secret 挂载）
+const apiKeyFilePrefix = "file:"
+
+// apiKeyFileTTL 文件内容的缓存时间，过期后重新读取以感知密钥轮换
+const apiKeyFileTTL = 30 * time.Second
+
+type apiKeyFileEntry struct {
+	key      string
+	loadedAt time.Time
+}
+
+var (
+	apiKeyFileMu    sync.Mutex
+	apiKeyFileCache = map[string]apiKeyFileEntry{}
+)
+
+// ResolveAPIKey 解析端点配置的 API Key：带 file: 前缀时读取文件内容（去除首尾空白），
+// 结果缓存 apiKeyFileTTL；文件读取失败时记录警告并返回空 Key。其他值原样返回。
+func ResolveAPIKey(apiKey string) string {
+	path, ok := strings.CutPrefix(strings.TrimSpace(apiKey), apiKeyFilePrefix)
+	if !ok {
+		return apiKey
+	}
+	path = strings.TrimSpace(path)
+
+	apiKeyFileMu.Lock()
+	defer apiKeyFileMu.Unlock()
+	if entry, ok := apiKeyFileCache[path]; ok && time.Since(entry.loadedAt) < apiKeyFileTTL {
+		return entry.key
+	}
+	data, err := os.ReadFile(path)
+	key := strings.TrimSpace(string(data))
+	if err != nil {
+		logger.Warn("[Executor] read api key file failed (%s): %v", path, err)
+		key = ""
+	}
+	apiKeyFileCache[path] = apiKeyFileEntry{key: key, loadedAt: time.Now()}
+	return key
+}
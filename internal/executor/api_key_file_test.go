@@ -0,0 +1,50 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveAPIKey(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "openai")
+	if err := os.WriteFile(secret, []byte("  sk-from-file\n"), 0o600); err != nil {
+		t.Fatalf("write secret: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		apiKey string
+		want   string
+	}{
+		{name: "plain key", apiKey: "sk-plain", want: "sk-plain"},
+		{name: "file key trimmed", apiKey: "file:" + secret, want: "sk-from-file"},
+		{name: "missing file", apiKey: "file:" + filepath.Join(dir, "missing"), want: ""},
+	}
+	for _, tt := range tests {
+		if got := ResolveAPIKey(tt.apiKey); got != tt.want {
+			t.Fatalf("%s: ResolveAPIKey(%q) = %q, want %q", tt.name, tt.apiKey, got, tt.want)
+		}
+	}
+}
+
+func TestResolveAPIKeyCachesFile(t *testing.T) {
+	t.Parallel()
+
+	secret := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(secret, []byte("first"), 0o600); err != nil {
+		t.Fatalf("write secret: %v", err)
+	}
+	if got := ResolveAPIKey("file:" + secret); got != "first" {
+		t.Fatalf("got %q, want first", got)
+	}
+	if err := os.WriteFile(secret, []byte("second"), 0o600); err != nil {
+		t.Fatalf("rewrite secret: %v", err)
+	}
+	if got := ResolveAPIKey("file:" + secret); got != "first" {
+		t.Fatalf("got %q, want cached first", got)
+	}
+}
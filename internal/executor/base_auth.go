@@ -25,7 +25,7 @@ func ApplyAuthForInterfaceType(req *http.Request, apiKey string, interfaceType s
 		return
 	}
 
-	key := strings.TrimSpace(apiKey)
+	key := strings.TrimSpace(ResolveAPIKey(apiKey))
 	if key == "" {
 		return
 	}
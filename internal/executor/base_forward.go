@@ -1,9 +1,9 @@
 package executor
 
 import (
-	"bufio"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -20,8 +20,16 @@ func (e *BaseExecutor) Forward(ctx context.Context, endpoint *EndpointConfig, re
 		return result
 	}
 	result.TargetURL = targetURL
+	if err := checkProxyLoop(targetURL); err != nil {
+		result.Error = err
+		return result
+	}
+
+	ctx, cancel, watchdog := withFirstByteWatchdog(ctx, req.IsStreaming)
+	defer cancel()
 
-	requestBody := applyModelMapping(req.Body, endpoint)
+	requestBody, maxTokensClamp := prepareUpstreamBody(req.Body, endpoint, req.Path)
+	result.MaxTokensClamp = maxTokensClamp
 	proxyReq, err := http.NewRequestWithContext(ctx, req.Method, targetURL, bytes.NewReader(requestBody))
 	if err != nil {
 		result.Error = fmt.Errorf("failed to create request: %w", err)
@@ -31,12 +39,18 @@ func (e *BaseExecutor) Forward(ctx context.Context, endpoint *EndpointConfig, re
 	copyRequestHeaders(proxyReq, req.Headers)
 	e.getAuthApplier().Apply(proxyReq, endpoint, req.IsStreaming)
 	ApplyEndpointHeaders(proxyReq, endpoint)
+	applyAnthropicHeaders(proxyReq, endpoint, endpoint.InterfaceType)
+	applyClientIPHeaders(proxyReq, endpoint, req)
+	markForwarded(proxyReq)
 
 	result.TargetHeaders = sanitizeHeaders(proxyReq.Header)
 
 	client := NewHTTPClient(endpoint, 0)
 	resp, err := client.Do(proxyReq)
 	if err != nil {
+		if watchdog.timedOut() {
+			return watchdog.fail(result)
+		}
 		result.Error = fmt.Errorf("request failed: %w", err)
 		return result
 	}
@@ -47,22 +61,31 @@ func (e *BaseExecutor) Forward(ctx context.Context, endpoint *EndpointConfig, re
 
 	contentType := resp.Header.Get("Content-Type")
 	if req.IsStreaming && strings.Contains(contentType, "text/event-stream") {
-		return e.handleStreamingResponse(ctx, w, resp, result)
+		return e.handleStreamingResponse(ctx, w, resp, result, endpoint, watchdog)
 	}
 
-	return e.handleNonStreamingResponse(resp, result)
+	watchdog.received()
+	return applyResponseValidation(e.handleNonStreamingResponse(resp, result, req.NoSpool), endpoint)
 }
 
-func (e *BaseExecutor) handleStreamingResponse(ctx context.Context, w http.ResponseWriter, resp *http.Response, result *ForwardResult) *ForwardResult {
-	for key, values := range resp.Header {
-		if key == "Content-Length" || key == "Content-Encoding" {
-			continue
+func (e *BaseExecutor) handleStreamingResponse(ctx context.Context, w http.ResponseWriter, resp *http.Response, result *ForwardResult, endpoint *EndpointConfig, watchdog *firstByteWatchdog) *ForwardResult {
+	// 响应头延迟到收到首行数据后再写出，首字节超时时客户端未收到任何内容，可以故障转移
+	wroteHeader := false
+	writeHeader := func() {
+		if wroteHeader {
+			return
 		}
-		for _, v := range values {
-			w.Header().Add(key, v)
+		wroteHeader = true
+		for key, values := range resp.Header {
+			if key == "Content-Length" || key == "Content-Encoding" {
+				continue
+			}
+			for _, v := range values {
+				w.Header().Add(key, v)
+			}
 		}
+		w.WriteHeader(resp.StatusCode)
 	}
-	w.WriteHeader(resp.StatusCode)
 
 	flusher, ok := w.(http.Flusher)
 	if !ok {
@@ -75,18 +98,22 @@ func (e *BaseExecutor) handleStreamingResponse(ctx context.Context, w http.Respo
 		defer closer.Close()
 	}
 
-	scanner := bufio.NewScanner(reader)
-	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	scanner := newValidatingScanner(newStreamLineReader(reader, MaxStreamLineBytes()), endpoint)
 
 	var capture strings.Builder
 	const maxCaptureSize = 50 * 1024
+	var usageAcc sseUsageAccumulator
 
 	for scanner.Scan() {
+		watchdog.received()
+		writeHeader()
+
 		select {
 		case <-ctx.Done():
 			result.Error = ctx.Err()
 			result.Streamed = true
 			result.ResponseStream = capture.String()
+			result.Tokens = usageAcc.tokens()
 			return result
 		default:
 		}
@@ -97,9 +124,7 @@ func (e *BaseExecutor) handleStreamingResponse(ctx context.Context, w http.Respo
 			capture.WriteByte('\n')
 		}
 
-		if tokens := e.extractStreamTokens(line); tokens != nil {
-			result.Tokens = tokens
-		}
+		usageAcc.feed(line)
 
 		if _, err := w.Write(line); err != nil {
 			result.Error = context.Canceled
@@ -112,16 +137,26 @@ func (e *BaseExecutor) handleStreamingResponse(ctx context.Context, w http.Respo
 		flusher.Flush()
 	}
 
+	if watchdog.timedOut() {
+		return watchdog.fail(result)
+	}
+	if err := scanner.Err(); errors.Is(err, ErrInvalidResponse) {
+		return failInvalidStream(result, err)
+	}
+	writeHeader()
+
 	if err := scanner.Err(); err != nil {
+		writeStreamLineTooLongEvent(w, flusher, endpoint.InterfaceType, err)
 		result.Error = err
 	}
 
 	result.ResponseStream = capture.String()
+	result.Tokens = usageAcc.tokens()
 	result.Streamed = true
 	return result
 }
 
-func (e *BaseExecutor) handleNonStreamingResponse(resp *http.Response, result *ForwardResult) *ForwardResult {
+func (e *BaseExecutor) handleNonStreamingResponse(resp *http.Response, result *ForwardResult, noSpool bool) *ForwardResult {
 	reader := getResponseReader(resp)
 	if closer, ok := reader.(io.Closer); ok && reader != resp.Body {
 		defer closer.Close()
@@ -132,12 +167,33 @@ func (e *BaseExecutor) handleNonStreamingResponse(resp *http.Response, result *F
 		result.Headers.Del("Content-Length")
 	}
 
-	body, err := io.ReadAll(reader)
+	// 只有成功响应可能很大：超过阈值时落盘，错误响应仍完整读入内存供故障转移判断
+	var body []byte
+	var spooled *SpooledBody
+	var err error
+	if resp.StatusCode == http.StatusOK && !noSpool {
+		body, spooled, err = readResponseBodyOrSpool(reader)
+	} else {
+		body, err = io.ReadAll(reader)
+	}
 	if err != nil {
 		result.Error = fmt.Errorf("failed to read response: %w", err)
 		return result
 	}
 
+	if spooled != nil {
+		if isLikelyHTMLResponse(resp.StatusCode, resp.Header.Get("Content-Type"), spooled.Head()) {
+			spooled.Close()
+			result.StatusCode = http.StatusServiceUnavailable
+			result.Error = fmt.Errorf("upstream returned HTML with HTTP 200")
+			result.Body = spooled.Head()
+			return result
+		}
+		result.Spooled = spooled
+		result.Tokens = e.ExtractTokens(spooled.usageSnippet())
+		return result
+	}
+
 	if isLikelyHTMLResponse(resp.StatusCode, resp.Header.Get("Content-Type"), body) {
 		result.StatusCode = http.StatusServiceUnavailable
 		result.Error = fmt.Errorf("upstream returned HTML with HTTP 200")
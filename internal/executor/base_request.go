@@ -1,7 +1,6 @@
 package executor
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -175,49 +174,34 @@ func uniqueStrings(in []string) []string {
 	return out
 }
 
-func applyModelMapping(body []byte, endpoint *EndpointConfig) []byte {
-	if endpoint == nil || (len(endpoint.Models) == 0 && endpoint.Model == "") {
-		return body
-	}
-
-	var req map[string]any
-	if err := json.Unmarshal(body, &req); err != nil {
-		return body
+// mapModelInBody 把请求体中的模型替换为端点映射后的上游模型，返回是否有改动
+func mapModelInBody(req map[string]any, endpoint *EndpointConfig) bool {
+	if len(endpoint.Models) == 0 && endpoint.Model == "" && DefaultModel(endpoint.InterfaceType) == "" {
+		return false
 	}
 
 	requestModel, _ := req["model"].(string)
 	if strings.TrimSpace(requestModel) == "" {
-		if endpoint.Model != "" {
-			req["model"] = endpoint.Model
-			if result, err := json.Marshal(req); err == nil {
-				return result
-			}
+		// 依次使用端点 Model 与接口类型的全局默认模型
+		if model := ResolveUpstreamModel("", endpoint); model != "" {
+			req["model"] = model
+			return true
 		}
-		return body
+		return false
 	}
 
 	upstreamModel := ResolveUpstreamModel(requestModel, endpoint)
 	if upstreamModel == requestModel {
-		return body
+		return false
 	}
 	req["model"] = upstreamModel
-	if result, err := json.Marshal(req); err == nil {
-		return result
-	}
-	return body
+	return true
 }
 
+// copyRequestHeaders 按转发策略把客户端请求头复制到上游请求
 func copyRequestHeaders(dst *http.Request, src http.Header) {
-	skipHeaders := map[string]bool{
-		"host":            true,
-		"accept-encoding": true,
-		"content-length":  true,
-		"authorization":   true,
-		"x-api-key":       true,
-	}
-
 	for key, values := range src {
-		if skipHeaders[strings.ToLower(key)] {
+		if !shouldForwardHeader(key) {
 			continue
 		}
 		for _, v := range values {
@@ -228,14 +212,13 @@ func copyRequestHeaders(dst *http.Request, src http.Header) {
 
 func sanitizeHeaders(h http.Header) map[string]string {
 	result := make(map[string]string)
-	sensitiveKeys := map[string]bool{
-		"authorization": true,
-		"x-api-key":     true,
-		"api-key":       true,
-	}
+	drop := DropSensitiveHeaders()
 
 	for key, values := range h {
-		if sensitiveKeys[strings.ToLower(key)] {
+		if IsSensitiveHeader(key) {
+			if drop {
+				continue
+			}
 			if len(values) > 0 && len(values[0]) > 8 {
 				result[key] = values[0][:4] + "****" + values[0][len(values[0])-4:]
 			} else {
@@ -0,0 +1,57 @@
+package executor
+
+// overrideBodyFields 把端点配置的 BodyOverrides 深度合并进最终发送的请求体（transformer
+// 和模型映射之后），返回是否有改动。两边都是对象时逐层合并；同名的非对象字段默认保留
+// 客户端的值，BodyForce 为 true 时以配置为准。
+func overrideBodyFields(root map[string]any, endpoint *EndpointConfig) bool {
+	if len(endpoint.BodyOverrides) == 0 {
+		return false
+	}
+	return mergeBodyOverrides(root, endpoint.BodyOverrides, endpoint.BodyForce)
+}
+
+// mergeBodyOverrides 把 overrides 合并进 dst，返回 dst 是否被修改
+func mergeBodyOverrides(dst, overrides map[string]any, force bool) bool {
+	changed := false
+	for key, value := range overrides {
+		current, exists := dst[key]
+		if !exists {
+			dst[key] = cloneJSONValue(value)
+			changed = true
+			continue
+		}
+		currentObj, ok1 := current.(map[string]any)
+		valueObj, ok2 := value.(map[string]any)
+		if ok1 && ok2 {
+			if mergeBodyOverrides(currentObj, valueObj, force) {
+				changed = true
+			}
+			continue
+		}
+		if force {
+			dst[key] = cloneJSONValue(value)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// cloneJSONValue 深拷贝 JSON 值，避免请求体与端点配置共享可变的 map/slice
+func cloneJSONValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			out[k] = cloneJSONValue(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = cloneJSONValue(item)
+		}
+		return out
+	default:
+		return val
+	}
+}
@@ -0,0 +1,28 @@
+package executor
+
+import "testing"
+
+func TestApplyBodyOverrides(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name      string
+		overrides map[string]any
+		force     bool
+		body      string
+		want      string
+	}{
+		{name: "adds missing field", overrides: map[string]any{"service_tier": "flex"}, body: `{"model":"m"}`, want: `{"model":"m","service_tier":"flex"}`},
+		{name: "client wins", overrides: map[string]any{"service_tier": "flex"}, body: `{"service_tier":"auto"}`, want: `{"service_tier":"auto"}`},
+		{name: "force wins", overrides: map[string]any{"service_tier": "flex"}, force: true, body: `{"service_tier":"auto"}`, want: `{"service_tier":"flex"}`},
+		{name: "deep merge", overrides: map[string]any{"reasoning": map[string]any{"effort": "low", "summary": "auto"}}, body: `{"reasoning":{"effort":"high"}}`, want: `{"reasoning":{"effort":"high","summary":"auto"}}`},
+		{name: "no change keeps body", overrides: map[string]any{"a": 2}, body: `{"a": 1}`, want: `{"a": 1}`},
+		{name: "invalid json", overrides: map[string]any{"a": 1}, body: `not json`, want: `not json`},
+	}
+	for _, tc := range cases {
+		got, _ := prepareUpstreamBody([]byte(tc.body), &EndpointConfig{BodyOverrides: tc.overrides, BodyForce: tc.force}, "/v1/messages")
+		if string(got) != tc.want {
+			t.Fatalf("%s: got %s want %s", tc.name, got, tc.want)
+		}
+	}
+}
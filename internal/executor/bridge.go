@@ -16,8 +16,25 @@ type EndpointAdapter interface {
 	GetModel() string
 	GetVendorID() int64
 	GetProxyURL() string
+	GetProxyUsername() string
+	GetProxyPassword() string
+	GetForceNonStreamUpstream() bool
+	GetFallbackToDefaultModel() bool
 	GetModels() []ModelMapping
 	GetHeaders() map[string]string
+	GetDropParams() []string
+	GetBodyOverrides() map[string]any
+	GetBodyForce() bool
+	GetIncludeReasoning() bool
+	GetReasoningSummary() string
+	GetInsecureSkipVerify() bool
+	GetForwardClientIP() bool
+	GetCAFile() string
+	GetAnthropicVersion() string
+	GetAnthropicBeta() string
+	GetDailyTokenBudget() int64
+	GetMaxTokensCap() int
+	GetResponseValidation() []string
 }
 
 // EndpointFromAdapter 从适配器创建执行器端点配置
@@ -27,17 +44,34 @@ func EndpointFromAdapter(ep EndpointAdapter) *EndpointConfig {
 	}
 
 	return &EndpointConfig{
-		ID:            ep.GetID(),
-		Name:          ep.GetName(),
-		APIURL:        ep.GetAPIURL(),
-		APIKey:        ep.GetAPIKey(),
-		InterfaceType: ep.GetInterfaceType(),
-		Transformer:   ep.GetTransformer(),
-		VendorID:      ep.GetVendorID(),
-		Model:         ep.GetModel(),
-		ProxyURL:      ep.GetProxyURL(),
-		Models:        ep.GetModels(),
-		Headers:       ep.GetHeaders(),
+		ID:                     ep.GetID(),
+		Name:                   ep.GetName(),
+		APIURL:                 ep.GetAPIURL(),
+		APIKey:                 ep.GetAPIKey(),
+		InterfaceType:          ep.GetInterfaceType(),
+		Transformer:            ep.GetTransformer(),
+		VendorID:               ep.GetVendorID(),
+		Model:                  ep.GetModel(),
+		ProxyURL:               ep.GetProxyURL(),
+		ProxyUsername:          ep.GetProxyUsername(),
+		ProxyPassword:          ep.GetProxyPassword(),
+		ForceNonStreamUpstream: ep.GetForceNonStreamUpstream(),
+		FallbackToDefaultModel: ep.GetFallbackToDefaultModel(),
+		Models:                 ep.GetModels(),
+		Headers:                ep.GetHeaders(),
+		DropParams:             ep.GetDropParams(),
+		BodyOverrides:          ep.GetBodyOverrides(),
+		BodyForce:              ep.GetBodyForce(),
+		IncludeReasoning:       ep.GetIncludeReasoning(),
+		ReasoningSummary:       ep.GetReasoningSummary(),
+		InsecureSkipVerify:     ep.GetInsecureSkipVerify(),
+		ForwardClientIP:        ep.GetForwardClientIP(),
+		CAFile:                 ep.GetCAFile(),
+		AnthropicVersion:       ep.GetAnthropicVersion(),
+		AnthropicBeta:          ep.GetAnthropicBeta(),
+		DailyTokenBudget:       ep.GetDailyTokenBudget(),
+		MaxTokensCap:           ep.GetMaxTokensCap(),
+		ResponseValidation:     ep.GetResponseValidation(),
 	}
 }
 
@@ -50,6 +84,8 @@ func ForwardRequestFromHTTP(r *http.Request, body []byte, isStreaming bool) *For
 		Headers:     r.Header.Clone(),
 		Body:        body,
 		IsStreaming: isStreaming,
+		UserID:      ExtractUserID(body),
+		ClientIP:    clientIPFromRemoteAddr(r.RemoteAddr),
 	}
 }
 
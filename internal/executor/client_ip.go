@@ -0,0 +1,37 @@
+package executor
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// clientIPFromRemoteAddr 从连接的 RemoteAddr（host:port）中取出 IP
+func clientIPFromRemoteAddr(remoteAddr string) string {
+	remoteAddr = strings.TrimSpace(remoteAddr)
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		return host
+	}
+	return remoteAddr
+}
+
+// applyClientIPHeaders 端点开启 ForwardClientIP 时，把客户端 IP 追加到 X-Forwarded-For 链末尾并写入 X-Real-IP。
+// 默认关闭：部分供应商会拒绝带有意外 X-Forwarded-For 的请求
+func applyClientIPHeaders(proxyReq *http.Request, endpoint *EndpointConfig, req *ForwardRequest) {
+	if proxyReq == nil || endpoint == nil || !endpoint.ForwardClientIP || req == nil || req.ClientIP == "" {
+		return
+	}
+
+	// 以客户端原始请求中的链为准，白名单模式下它可能没有被复制到上游请求
+	var chain []string
+	for _, v := range req.Headers.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(v, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				chain = append(chain, hop)
+			}
+		}
+	}
+	chain = append(chain, req.ClientIP)
+	proxyReq.Header.Set("X-Forwarded-For", strings.Join(chain, ", "))
+	proxyReq.Header.Set("X-Real-IP", req.ClientIP)
+}
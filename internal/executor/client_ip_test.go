@@ -0,0 +1,39 @@
+package executor
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestApplyClientIPHeaders(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		enabled  bool
+		incoming string
+		wantXFF  string
+		wantReal string
+	}{
+		{name: "disabled", enabled: false, wantXFF: "", wantReal: ""},
+		{name: "new chain", enabled: true, wantXFF: "203.0.113.7", wantReal: "203.0.113.7"},
+		{name: "append to chain", enabled: true, incoming: "198.51.100.1, 10.0.0.2", wantXFF: "198.51.100.1, 10.0.0.2, 203.0.113.7", wantReal: "203.0.113.7"},
+	}
+	for _, tc := range cases {
+		httpReq, _ := http.NewRequest(http.MethodPost, "http://localhost/v1/messages", nil)
+		httpReq.RemoteAddr = "203.0.113.7:51234"
+		if tc.incoming != "" {
+			httpReq.Header.Set("X-Forwarded-For", tc.incoming)
+		}
+		req := ForwardRequestFromHTTP(httpReq, nil, false)
+
+		proxyReq, _ := http.NewRequest(http.MethodPost, "http://upstream/v1/messages", nil)
+		applyClientIPHeaders(proxyReq, &EndpointConfig{ForwardClientIP: tc.enabled}, req)
+		if got := proxyReq.Header.Get("X-Forwarded-For"); got != tc.wantXFF {
+			t.Fatalf("%s: X-Forwarded-For=%q want %q", tc.name, got, tc.wantXFF)
+		}
+		if got := proxyReq.Header.Get("X-Real-IP"); got != tc.wantReal {
+			t.Fatalf("%s: X-Real-IP=%q want %q", tc.name, got, tc.wantReal)
+		}
+	}
+}
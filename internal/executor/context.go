@@ -3,6 +3,7 @@ package executor
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
@@ -13,6 +14,7 @@ import (
 type ExecutionContext struct {
 	provider EndpointProvider
 	observer ExecutionObserver
+	usage    endpointUsage
 }
 
 // ExecutionObserver 执行观察者接口
@@ -55,12 +57,20 @@ func (c *ExecutionContext) DetectInterfaceType(path string) string {
 	return c.provider.DetectInterfaceType(path)
 }
 
-// ResolveEndpoint 根据路径解析端点
-func (c *ExecutionContext) ResolveEndpoint(path string) (*EndpointConfig, string) {
+// RequestInterfaceType 返回请求的接口类型：路由规则强制的类型优先，否则按路径检测
+func (c *ExecutionContext) RequestInterfaceType(req *ForwardRequest) string {
+	if req.InterfaceType != "" {
+		return req.InterfaceType
+	}
+	return c.DetectInterfaceType(req.Path)
+}
+
+// ResolveEndpoint 根据请求解析端点
+func (c *ExecutionContext) ResolveEndpoint(req *ForwardRequest) (*EndpointConfig, string) {
 	if c.provider == nil {
 		return nil, ""
 	}
-	interfaceType := c.provider.DetectInterfaceType(path)
+	interfaceType := c.RequestInterfaceType(req)
 	endpoint := c.provider.GetActiveEndpoint(interfaceType)
 	return endpoint, interfaceType
 }
@@ -74,7 +84,7 @@ func (c *ExecutionContext) GetExecutor(interfaceType string) Executor {
 // 流程：检测接口类型 → 选择执行器 → 查找端点 → 执行转发
 func (c *ExecutionContext) Execute(ctx context.Context, req *ForwardRequest, w http.ResponseWriter) (*ForwardResult, *EndpointConfig, string) {
 	// 1. 检测接口类型
-	interfaceType := c.DetectInterfaceType(req.Path)
+	interfaceType := c.RequestInterfaceType(req)
 
 	// 2. 查找端点
 	endpoint := c.provider.GetActiveEndpoint(interfaceType)
@@ -99,10 +109,42 @@ func (c *ExecutionContext) Execute(ctx context.Context, req *ForwardRequest, w h
 
 // ExecuteWithEndpoint 使用指定端点执行请求
 func (c *ExecutionContext) ExecuteWithEndpoint(ctx context.Context, endpoint *EndpointConfig, req *ForwardRequest, w http.ResponseWriter) *ForwardResult {
-	interfaceType := c.DetectInterfaceType(req.Path)
+	interfaceType := c.RequestInterfaceType(req)
+	c.usage.touch(endpoint)
+	result := c.execute(ctx, interfaceType, endpoint, req, w)
+
+	// 上游不支持请求的模型时，按端点配置改用默认模型重试一次
+	if retryReq, retryEndpoint, attempted := defaultModelRetry(endpoint, req, result); retryReq != nil {
+		c.DebugLog(ctx, 2, fmt.Sprintf("[ModelFallback] endpoint=%s 上游不支持模型 %q (status=%d)，改用默认模型 %q 重试", endpoint.Name, attempted, result.StatusCode, retryEndpoint.Model))
+		result = c.execute(ctx, interfaceType, retryEndpoint, retryReq, w)
+		if result != nil {
+			result.ModelFallback = attempted + " -> " + retryEndpoint.Model
+		}
+	}
+	return result
+}
+
+func (c *ExecutionContext) execute(ctx context.Context, interfaceType string, endpoint *EndpointConfig, req *ForwardRequest, w http.ResponseWriter) *ForwardResult {
+	if isForcedNonStream(interfaceType, endpoint, req) {
+		return c.executeForcedNonStream(ctx, interfaceType, endpoint, req, w)
+	}
+	return c.forward(ctx, interfaceType, endpoint, req, w)
+}
+
+func (c *ExecutionContext) forward(ctx context.Context, interfaceType string, endpoint *EndpointConfig, req *ForwardRequest, w http.ResponseWriter) *ForwardResult {
 	if endpoint != nil && strings.TrimSpace(endpoint.Transformer) != "" {
+		if isTransformerBypassPath(req.Path) {
+			if result, ok := c.forwardBypassingTransformer(ctx, interfaceType, endpoint, req, w); ok {
+				return result
+			}
+		}
 		return c.executeWithTransformer(ctx, interfaceType, endpoint, req, w)
 	}
+	if TraceEnabled(ctx) {
+		requestModel := extractModelFromBody(req.Body)
+		c.Tracef(ctx, "forward: endpoint=%s interface=%s transformer=none model(client=%q upstream=%q) stream=%v",
+			endpointDisplayName(endpoint), interfaceType, requestModel, ResolveUpstreamModel(requestModel, endpoint), req.IsStreaming)
+	}
 	exec := c.GetExecutor(interfaceType)
 	return exec.Forward(ctx, endpoint, req, w)
 }
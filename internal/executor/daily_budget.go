@@ -0,0 +1,42 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrDailyBudgetExhausted 可用端点均已用尽当天的 token 预算
+var ErrDailyBudgetExhausted = errors.New("daily token budget exhausted")
+
+// TokenUsageProvider 可选接口：EndpointProvider 实现后，配置了 DailyTokenBudget 的端点
+// 在当天用量达到预算时被跳过。实现方应缓存用量，避免每个请求都查询数据库。
+type TokenUsageProvider interface {
+	// TodayTokenUsage 返回端点 ID -> 今天已用 token（输入+输出），统计不可用时返回 nil
+	TodayTokenUsage() map[int64]int64
+}
+
+// overDailyBudget 判断端点今天的用量是否已达到预算。统计不可用时放行，
+// 次日用量清零后自动恢复。
+func (c *ExecutionContext) overDailyBudget(endpoint *EndpointConfig) bool {
+	if endpoint == nil || endpoint.DailyTokenBudget <= 0 || endpoint.ID == 0 {
+		return false
+	}
+	source, ok := c.provider.(TokenUsageProvider)
+	if !ok {
+		return false
+	}
+	usage := source.TodayTokenUsage()
+	if usage == nil {
+		return false
+	}
+	return usage[endpoint.ID] >= endpoint.DailyTokenBudget
+}
+
+// dailyBudgetExhaustedResult 所有候选端点都因预算用尽被跳过时返回 429
+func dailyBudgetExhaustedResult(interfaceType string) *ForwardResult {
+	return &ForwardResult{
+		StatusCode: http.StatusTooManyRequests,
+		Error:      fmt.Errorf("%w for all %s endpoints, resets tomorrow", ErrDailyBudgetExhausted, interfaceType),
+	}
+}
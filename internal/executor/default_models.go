@@ -0,0 +1,53 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// defaultModels 按接口类型（小写）配置的全局默认模型
+var defaultModels atomic.Pointer[map[string]string]
+
+// SetDefaultModels 设置按接口类型的全局默认模型。客户端未指定模型、端点也未配置 Model 时使用，
+// 避免向上游发送空模型。传入 nil 清空配置。
+func SetDefaultModels(models map[string]string) {
+	normalized := make(map[string]string, len(models))
+	for interfaceType, model := range models {
+		interfaceType = strings.ToLower(strings.TrimSpace(interfaceType))
+		model = strings.TrimSpace(model)
+		if interfaceType != "" && model != "" {
+			normalized[interfaceType] = model
+		}
+	}
+	defaultModels.Store(&normalized)
+}
+
+// DefaultModel 返回接口类型的全局默认模型，未配置时返回空串
+func DefaultModel(interfaceType string) string {
+	models := defaultModels.Load()
+	if models == nil {
+		return ""
+	}
+	return (*models)[strings.ToLower(strings.TrimSpace(interfaceType))]
+}
+
+// ParseDefaultModels 解析配置中的 defaultModels，格式为 {"claude": "模型名", "codex": "模型名"}
+func ParseDefaultModels(raw interface{}) (map[string]string, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	obj, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("defaultModels must be an object keyed by interface type")
+	}
+	out := make(map[string]string, len(obj))
+	for interfaceType, v := range obj {
+		model, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("defaultModels.%s must be a string", interfaceType)
+		}
+		out[interfaceType] = model
+	}
+	return out, nil
+}
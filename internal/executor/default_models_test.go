@@ -0,0 +1,43 @@
+package executor
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// 修改全局默认模型，不能与其他测试并行
+func TestDefaultModels(t *testing.T) {
+	models, err := ParseDefaultModels(map[string]interface{}{"Test-Default": " global-model ", "other": ""})
+	if err != nil {
+		t.Fatalf("ParseDefaultModels: %v", err)
+	}
+	SetDefaultModels(models)
+	t.Cleanup(func() { SetDefaultModels(nil) })
+
+	cases := []struct {
+		name     string
+		endpoint *EndpointConfig
+		body     string
+		want     string
+	}{
+		{name: "global default", endpoint: &EndpointConfig{InterfaceType: "test-default"}, body: `{"messages":[]}`, want: "global-model"},
+		{name: "endpoint model wins", endpoint: &EndpointConfig{InterfaceType: "test-default", Model: "ep-model"}, body: `{"messages":[]}`, want: "ep-model"},
+		{name: "client model kept", endpoint: &EndpointConfig{InterfaceType: "test-default"}, body: `{"model":"client"}`, want: "client"},
+		{name: "no default configured", endpoint: &EndpointConfig{InterfaceType: "other"}, body: `{"messages":[]}`, want: ""},
+	}
+	for _, tc := range cases {
+		var out map[string]any
+		body, _ := prepareUpstreamBody([]byte(tc.body), tc.endpoint, "/v1/messages")
+		if err := json.Unmarshal(body, &out); err != nil {
+			t.Fatalf("%s: unmarshal: %v", tc.name, err)
+		}
+		got, _ := out["model"].(string)
+		if got != tc.want {
+			t.Fatalf("%s: model=%q, want %q", tc.name, got, tc.want)
+		}
+	}
+
+	if _, err := ParseDefaultModels([]interface{}{"x"}); err == nil {
+		t.Fatalf("expected error for non-object defaultModels")
+	}
+}
@@ -0,0 +1,69 @@
+package executor
+
+import "strings"
+
+// dropBodyParams 按端点配置的 DropParams 删除请求体中上游不支持的参数（在 transformer
+// 和模型映射之后执行，作用于最终发送的请求体），返回是否有删除。路径以 "." 分隔，
+// "*" 匹配任意键或数组元素，例如 "reasoning"、"stream_options.include_usage"、"tools.*.strict"。
+func dropBodyParams(root map[string]any, endpoint *EndpointConfig) bool {
+	changed := false
+	for _, path := range endpoint.DropParams {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		if dropJSONPath(root, strings.Split(path, ".")) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// dropJSONPath 删除 node 下匹配 segments 的字段，返回是否有删除
+func dropJSONPath(node any, segments []string) bool {
+	if len(segments) == 0 {
+		return false
+	}
+	key, rest := segments[0], segments[1:]
+
+	switch v := node.(type) {
+	case map[string]any:
+		if len(rest) == 0 {
+			if key == "*" {
+				removed := len(v) > 0
+				for k := range v {
+					delete(v, k)
+				}
+				return removed
+			}
+			if _, ok := v[key]; !ok {
+				return false
+			}
+			delete(v, key)
+			return true
+		}
+		if key == "*" {
+			removed := false
+			for _, child := range v {
+				if dropJSONPath(child, rest) {
+					removed = true
+				}
+			}
+			return removed
+		}
+		return dropJSONPath(v[key], rest)
+	case []any:
+		// 数组只支持 "*" 逐个元素下钻，不删除元素本身
+		if key != "*" || len(rest) == 0 {
+			return false
+		}
+		removed := false
+		for _, child := range v {
+			if dropJSONPath(child, rest) {
+				removed = true
+			}
+		}
+		return removed
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package executor
+
+import "testing"
+
+func TestApplyDropParams(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		drop []string
+		body string
+		want string
+	}{
+		{name: "top level", drop: []string{"reasoning", "temperature"}, body: `{"model":"m","reasoning":{"effort":"high"},"temperature":0}`, want: `{"model":"m"}`},
+		{name: "nested", drop: []string{"stream_options.include_usage"}, body: `{"stream_options":{"include_usage":true}}`, want: `{"stream_options":{}}`},
+		{name: "array wildcard", drop: []string{"tools.*.strict"}, body: `{"tools":[{"name":"a","strict":true},{"name":"b"}]}`, want: `{"tools":[{"name":"a"},{"name":"b"}]}`},
+		{name: "missing path keeps body", drop: []string{"missing.field"}, body: `{"a": 1}`, want: `{"a": 1}`},
+		{name: "invalid json", drop: []string{"a"}, body: `not json`, want: `not json`},
+	}
+	for _, tc := range cases {
+		got, _ := prepareUpstreamBody([]byte(tc.body), &EndpointConfig{DropParams: tc.drop}, "/v1/chat/completions")
+		if string(got) != tc.want {
+			t.Fatalf("%s: got %s want %s", tc.name, got, tc.want)
+		}
+	}
+}
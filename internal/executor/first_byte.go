@@ -0,0 +1,82 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// ErrFirstByteTimeout 流式请求在首字节超时时间内未收到上游任何数据
+var ErrFirstByteTimeout = errors.New("upstream sent no data before first-byte timeout")
+
+var streamFirstByteTimeout int64
+
+// SetStreamFirstByteTimeout 设置流式请求的首字节超时时间，d <= 0 时关闭
+func SetStreamFirstByteTimeout(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	atomic.StoreInt64(&streamFirstByteTimeout, int64(d))
+}
+
+// StreamFirstByteTimeout 返回流式请求的首字节超时时间（0 表示关闭）
+func StreamFirstByteTimeout() time.Duration {
+	return time.Duration(atomic.LoadInt64(&streamFirstByteTimeout))
+}
+
+const (
+	firstByteWaiting int32 = iota
+	firstByteReceived
+	firstByteTimedOut
+)
+
+// firstByteWatchdog 在首字节超时后取消上游请求。
+// 收到首字节后即解除，之后不会再触发。nil 表示未启用。
+type firstByteWatchdog struct {
+	state   atomic.Int32
+	timer   *time.Timer
+	timeout time.Duration
+}
+
+// withFirstByteWatchdog 为流式请求派生可取消的上下文并启动首字节计时；
+// 未配置超时时返回原上下文和 nil watchdog
+func withFirstByteWatchdog(ctx context.Context, isStreaming bool) (context.Context, context.CancelFunc, *firstByteWatchdog) {
+	timeout := StreamFirstByteTimeout()
+	if !isStreaming || timeout <= 0 {
+		return ctx, func() {}, nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	w := &firstByteWatchdog{timeout: timeout}
+	w.timer = time.AfterFunc(timeout, func() {
+		if w.state.CompareAndSwap(firstByteWaiting, firstByteTimedOut) {
+			cancel()
+		}
+	})
+	return ctx, cancel, w
+}
+
+// received 标记已收到首字节，解除超时
+func (w *firstByteWatchdog) received() {
+	if w == nil {
+		return
+	}
+	if w.state.CompareAndSwap(firstByteWaiting, firstByteReceived) {
+		w.timer.Stop()
+	}
+}
+
+// timedOut 返回是否因首字节超时取消了上游请求
+func (w *firstByteWatchdog) timedOut() bool {
+	return w != nil && w.state.Load() == firstByteTimedOut
+}
+
+// fail 将结果标记为首字节超时：尚未向客户端写入任何内容，可安全故障转移
+func (w *firstByteWatchdog) fail(result *ForwardResult) *ForwardResult {
+	result.StatusCode = 0
+	result.Headers = nil
+	result.Streamed = false
+	result.Error = fmt.Errorf("%w (%s)", ErrFirstByteTimeout, w.timeout)
+	return result
+}
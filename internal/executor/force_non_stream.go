@@ -0,0 +1,107 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const geminiStreamMethod = ":streamGenerateContent"
+
+// isForcedNonStream 判断是否需要以非流式请求上游、再向客户端合成流式响应
+func isForcedNonStream(interfaceType string, endpoint *EndpointConfig, req *ForwardRequest) bool {
+	if endpoint == nil || !endpoint.ForceNonStreamUpstream || req == nil {
+		return false
+	}
+	if interfaceType == "gemini" {
+		return strings.Contains(req.Path, geminiStreamMethod)
+	}
+	return req.IsStreaming
+}
+
+// executeForcedNonStream 以非流式方式请求上游，再把完整响应按客户端协议重放为 SSE
+func (c *ExecutionContext) executeForcedNonStream(ctx context.Context, interfaceType string, endpoint *EndpointConfig, req *ForwardRequest, w http.ResponseWriter) *ForwardResult {
+	upstreamReq := *req
+	upstreamReq.IsStreaming = false
+	upstreamReq.NoSpool = true
+	upstreamReq.Body = disableStreamInBody(req.Body)
+
+	geminiSSE := false
+	if interfaceType == "gemini" {
+		upstreamReq.Path = strings.Replace(req.Path, geminiStreamMethod, ":generateContent", 1)
+		upstreamReq.RawQuery, geminiSSE = dropAltQuery(req.RawQuery)
+	}
+
+	c.DebugLog(ctx, 1, fmt.Sprintf("[ForceNonStream] endpoint=%s 上游改为非流式请求", endpoint.Name))
+	result := c.forward(ctx, interfaceType, endpoint, &upstreamReq, w)
+	if result == nil || result.Error != nil || result.StatusCode != http.StatusOK || len(result.Body) == 0 {
+		return result
+	}
+
+	stream, contentType, err := synthesizeStream(interfaceType, result.Body, geminiSSE)
+	if err != nil {
+		// 无法解析时按原样返回 JSON，由上层写回客户端
+		c.DebugLog(ctx, 2, fmt.Sprintf("[ForceNonStream] 合成流式响应失败，按非流式返回: endpoint=%s err=%v", endpoint.Name, err))
+		return result
+	}
+
+	for key, values := range result.Headers {
+		switch strings.ToLower(key) {
+		case "content-length", "content-encoding", "content-type":
+			continue
+		}
+		for _, v := range values {
+			w.Header().Add(key, v)
+		}
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(result.StatusCode)
+
+	if _, err := w.Write(stream); err != nil {
+		result.Error = context.Canceled
+	}
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+
+	const maxCaptureSize = 50 * 1024
+	if len(stream) > maxCaptureSize {
+		result.ResponseStream = string(stream[:maxCaptureSize])
+	} else {
+		result.ResponseStream = string(stream)
+	}
+	result.Streamed = true
+	return result
+}
+
+// disableStreamInBody 将请求体中的 stream 置为 false，并移除仅流式可用的 stream_options
+func disableStreamInBody(body []byte) []byte {
+	var req map[string]any
+	if err := json.Unmarshal(body, &req); err != nil {
+		return body
+	}
+	if _, ok := req["stream"]; !ok {
+		return body
+	}
+	req["stream"] = false
+	delete(req, "stream_options")
+	if result, err := json.Marshal(req); err == nil {
+		return result
+	}
+	return body
+}
+
+// dropAltQuery 移除 Gemini 的 alt 参数，返回新的 query 以及原请求是否为 alt=sse
+func dropAltQuery(rawQuery string) (string, bool) {
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return rawQuery, false
+	}
+	sse := strings.EqualFold(values.Get("alt"), "sse")
+	values.Del("alt")
+	return values.Encode(), sse
+}
@@ -0,0 +1,94 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// 转发客户端请求头的模式
+const (
+	// ForwardHeaderModeDenylist 转发除内置跳过列表外的所有请求头（默认）
+	ForwardHeaderModeDenylist = "denylist"
+	// ForwardHeaderModeAllowlist 只转发 forwardHeaders 中列出的请求头
+	ForwardHeaderModeAllowlist = "allowlist"
+)
+
+// skipForwardHeaders 任何模式下都不从客户端复制的请求头（小写），由 http.Client 或鉴权逻辑重新设置
+var skipForwardHeaders = map[string]bool{
+	"host":            true,
+	"accept-encoding": true,
+	"content-length":  true,
+	"authorization":   true,
+	"x-api-key":       true,
+}
+
+// alwaysForwardHeaders 白名单模式下也始终转发的请求头：请求体类型与环路检测标记
+var alwaysForwardHeaders = []string{"content-type", strings.ToLower(ForwardedHeader)}
+
+// defaultForwardHeaders 白名单模式未配置 forwardHeaders 时使用的列表
+var defaultForwardHeaders = []string{"accept", "anthropic-version", "anthropic-beta", "openai-beta"}
+
+// forwardHeaderPolicy 客户端请求头转发策略，allowed 为 nil 表示黑名单模式
+type forwardHeaderPolicy struct {
+	allowed map[string]bool
+}
+
+var forwardPolicy atomic.Pointer[forwardHeaderPolicy]
+
+func init() {
+	forwardPolicy.Store(&forwardHeaderPolicy{})
+}
+
+// SetForwardHeaderPolicy 设置客户端请求头转发策略。mode 为空时使用 denylist；
+// allowlist 模式下只转发 headers 中的请求头（为空时使用默认列表）
+func SetForwardHeaderPolicy(mode string, headers []string) error {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", ForwardHeaderModeDenylist:
+		forwardPolicy.Store(&forwardHeaderPolicy{})
+		return nil
+	case ForwardHeaderModeAllowlist:
+	default:
+		return fmt.Errorf("invalid forwardHeaderMode: %s", mode)
+	}
+
+	if len(headers) == 0 {
+		headers = defaultForwardHeaders
+	}
+	allowed := make(map[string]bool, len(headers)+len(alwaysForwardHeaders))
+	for _, k := range alwaysForwardHeaders {
+		allowed[k] = true
+	}
+	for _, k := range headers {
+		if k = strings.ToLower(strings.TrimSpace(k)); k != "" {
+			allowed[k] = true
+		}
+	}
+	forwardPolicy.Store(&forwardHeaderPolicy{allowed: allowed})
+	return nil
+}
+
+// ForwardHeaderMode 返回当前的请求头转发模式
+func ForwardHeaderMode() string {
+	if forwardPolicy.Load().allowed != nil {
+		return ForwardHeaderModeAllowlist
+	}
+	return ForwardHeaderModeDenylist
+}
+
+// ParseForwardHeaders 解析配置中的 forwardHeaders，格式与 sensitiveHeaders 相同
+func ParseForwardHeaders(raw interface{}) []string {
+	return ParseSensitiveHeaders(raw)
+}
+
+// shouldForwardHeader 判断客户端请求头是否复制到上游请求
+func shouldForwardHeader(key string) bool {
+	key = strings.ToLower(key)
+	if skipForwardHeaders[key] {
+		return false
+	}
+	if allowed := forwardPolicy.Load().allowed; allowed != nil {
+		return allowed[key]
+	}
+	return true
+}
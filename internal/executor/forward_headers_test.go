@@ -0,0 +1,68 @@
+package executor
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestCopyRequestHeaders_ForwardHeaderPolicy(t *testing.T) {
+	t.Cleanup(func() { _ = SetForwardHeaderPolicy("", nil) })
+
+	src := http.Header{}
+	src.Set("Content-Type", "application/json")
+	src.Set("Anthropic-Version", "2023-06-01")
+	src.Set("Cookie", "session=1")
+	src.Set("Traceparent", "00-abc-def-01")
+	src.Set("Authorization", "Bearer client")
+	src.Set(ForwardedHeader, "other-instance")
+
+	tests := []struct {
+		name    string
+		mode    string
+		headers []string
+		want    []string
+		dropped []string
+	}{
+		{
+			name:    "denylist",
+			mode:    "",
+			want:    []string{"Content-Type", "Anthropic-Version", "Cookie", "Traceparent"},
+			dropped: []string{"Authorization"},
+		},
+		{
+			name:    "allowlist defaults",
+			mode:    ForwardHeaderModeAllowlist,
+			want:    []string{"Content-Type", "Anthropic-Version", ForwardedHeader},
+			dropped: []string{"Cookie", "Traceparent", "Authorization"},
+		},
+		{
+			name:    "allowlist configured",
+			mode:    "Allowlist",
+			headers: []string{" traceparent "},
+			want:    []string{"Content-Type", "Traceparent", ForwardedHeader},
+			dropped: []string{"Anthropic-Version", "Cookie", "Authorization"},
+		},
+	}
+
+	for _, tt := range tests {
+		if err := SetForwardHeaderPolicy(tt.mode, tt.headers); err != nil {
+			t.Fatalf("%s: SetForwardHeaderPolicy: %v", tt.name, err)
+		}
+		dst, _ := http.NewRequest(http.MethodPost, "http://example.com", nil)
+		copyRequestHeaders(dst, src)
+		for _, k := range tt.want {
+			if dst.Header.Get(k) == "" {
+				t.Fatalf("%s: %s not forwarded", tt.name, k)
+			}
+		}
+		for _, k := range tt.dropped {
+			if dst.Header.Get(k) != "" {
+				t.Fatalf("%s: %s should not be forwarded", tt.name, k)
+			}
+		}
+	}
+
+	if err := SetForwardHeaderPolicy("passthrough", nil); err == nil {
+		t.Fatalf("expected error for invalid mode")
+	}
+}
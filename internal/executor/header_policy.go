@@ -0,0 +1,75 @@
+package executor
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// defaultSensitiveHeaders 默认需要在日志中脱敏的请求头（小写）
+var defaultSensitiveHeaders = []string{
+	"authorization",
+	"proxy-authorization",
+	"x-api-key",
+	"api-key",
+	"x-goog-api-key",
+	"cookie",
+}
+
+// sensitiveHeaderPolicy 日志请求头脱敏策略
+type sensitiveHeaderPolicy struct {
+	keys map[string]bool
+	drop bool
+}
+
+var headerPolicy atomic.Pointer[sensitiveHeaderPolicy]
+
+func init() {
+	SetSensitiveHeaderPolicy(nil, false)
+}
+
+// SetSensitiveHeaderPolicy 设置日志请求头脱敏策略：extra 与默认列表合并；
+// drop 为 true 时敏感请求头直接从日志中移除，而不是打码
+func SetSensitiveHeaderPolicy(extra []string, drop bool) {
+	keys := make(map[string]bool, len(defaultSensitiveHeaders)+len(extra))
+	for _, k := range defaultSensitiveHeaders {
+		keys[k] = true
+	}
+	for _, k := range extra {
+		if k = strings.ToLower(strings.TrimSpace(k)); k != "" {
+			keys[k] = true
+		}
+	}
+	headerPolicy.Store(&sensitiveHeaderPolicy{keys: keys, drop: drop})
+}
+
+// IsSensitiveHeader 判断请求头是否需要在日志中脱敏
+func IsSensitiveHeader(key string) bool {
+	return headerPolicy.Load().keys[strings.ToLower(strings.TrimSpace(key))]
+}
+
+// DropSensitiveHeaders 返回敏感请求头是否直接从日志中移除
+func DropSensitiveHeaders() bool {
+	return headerPolicy.Load().drop
+}
+
+// ParseSensitiveHeaders 解析配置中的 sensitiveHeaders，支持 JSON 数组或逗号分隔的字符串
+func ParseSensitiveHeaders(raw interface{}) []string {
+	var out []string
+	switch v := raw.(type) {
+	case string:
+		for _, k := range strings.Split(v, ",") {
+			if k = strings.TrimSpace(k); k != "" {
+				out = append(out, k)
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if k, ok := item.(string); ok && strings.TrimSpace(k) != "" {
+				out = append(out, strings.TrimSpace(k))
+			}
+		}
+	case []string:
+		out = append(out, v...)
+	}
+	return out
+}
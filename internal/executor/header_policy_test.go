@@ -0,0 +1,39 @@
+package executor
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSanitizeHeaders_SensitiveHeaderPolicy(t *testing.T) {
+	t.Cleanup(func() { SetSensitiveHeaderPolicy(nil, false) })
+
+	h := http.Header{}
+	h.Set("Authorization", "Bearer sk-1234567890")
+	h.Set("X-Org-Token", "org-secret-value")
+	h.Set("Content-Type", "application/json")
+
+	SetSensitiveHeaderPolicy(ParseSensitiveHeaders([]interface{}{" x-org-token "}), false)
+	got := sanitizeHeaders(h)
+	if got["X-Org-Token"] != "org-****alue" {
+		t.Fatalf("X-Org-Token=%q want masked", got["X-Org-Token"])
+	}
+	if got["Authorization"] == h.Get("Authorization") {
+		t.Fatalf("Authorization not masked")
+	}
+	if got["Content-Type"] != "application/json" {
+		t.Fatalf("Content-Type=%q", got["Content-Type"])
+	}
+
+	SetSensitiveHeaderPolicy(ParseSensitiveHeaders("x-org-token, x-other"), true)
+	got = sanitizeHeaders(h)
+	if _, ok := got["X-Org-Token"]; ok {
+		t.Fatalf("X-Org-Token should be dropped")
+	}
+	if _, ok := got["Authorization"]; ok {
+		t.Fatalf("Authorization should be dropped")
+	}
+	if got["Content-Type"] != "application/json" {
+		t.Fatalf("Content-Type=%q", got["Content-Type"])
+	}
+}
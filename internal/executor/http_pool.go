@@ -0,0 +1,134 @@
+package executor
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxIdleConnsPerHost 默认每个上游主机保留的空闲连接数
+	DefaultMaxIdleConnsPerHost = 16
+	// DefaultIdleConnTimeout 默认空闲连接保留时间
+	DefaultIdleConnTimeout = 90 * time.Second
+	// DefaultConnectTimeout 默认建立上游连接的超时，主机不可达时尽快故障转移
+	DefaultConnectTimeout = 5 * time.Second
+)
+
+// HTTPPoolConfig 上游连接池配置，零值字段使用默认值
+type HTTPPoolConfig struct {
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+	DisableKeepAlives   bool
+	ConnectTimeout      time.Duration // 建立连接（含代理握手）的超时，与请求总超时、流式超时相互独立
+}
+
+var (
+	poolMu     sync.Mutex
+	poolConfig = normalizePoolConfig(HTTPPoolConfig{})
+
+	sharedTransports sync.Map // map[transportKey]*http.Transport
+)
+
+// transportKey 标识可共享同一 Transport 的代理与 TLS 设置。
+// 超时由 http.Client.Timeout 控制，不参与区分。
+type transportKey struct {
+	proxyURL string // 已合并账号密码的代理 URL，直连为空串
+	tls      tlsSettings
+	pool     HTTPPoolConfig
+}
+
+// SetHTTPPoolConfig 设置上游连接池参数。配置变化时丢弃已缓存的 Transport，
+// 新请求使用新配置，进行中的请求不受影响。
+func SetHTTPPoolConfig(cfg HTTPPoolConfig) {
+	cfg = normalizePoolConfig(cfg)
+
+	poolMu.Lock()
+	defer poolMu.Unlock()
+	if cfg == poolConfig {
+		return
+	}
+	poolConfig = cfg
+	sharedTransports.Range(func(key, value any) bool {
+		if key.(transportKey).pool != cfg {
+			value.(*http.Transport).CloseIdleConnections()
+			sharedTransports.Delete(key)
+		}
+		return true
+	})
+}
+
+// CurrentHTTPPoolConfig 返回当前生效的连接池配置
+func CurrentHTTPPoolConfig() HTTPPoolConfig {
+	poolMu.Lock()
+	defer poolMu.Unlock()
+	return poolConfig
+}
+
+func normalizePoolConfig(cfg HTTPPoolConfig) HTTPPoolConfig {
+	if cfg.MaxIdleConnsPerHost <= 0 {
+		cfg.MaxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	}
+	if cfg.IdleConnTimeout <= 0 {
+		cfg.IdleConnTimeout = DefaultIdleConnTimeout
+	}
+	if cfg.ConnectTimeout <= 0 {
+		cfg.ConnectTimeout = DefaultConnectTimeout
+	}
+	return cfg
+}
+
+// sharedTransport 返回 proxyURL 与 TLS 设置对应的共享 Transport，首次使用时用 build 创建并缓存。
+// build 返回 nil 时不缓存，返回 nil。
+func sharedTransport(proxyURL string, tlsCfg tlsSettings, build func() *http.Transport) *http.Transport {
+	key := transportKey{proxyURL: proxyURL, tls: tlsCfg, pool: CurrentHTTPPoolConfig()}
+	if cached, ok := sharedTransports.Load(key); ok {
+		return cached.(*http.Transport)
+	}
+
+	transport := build()
+	if transport == nil {
+		return nil
+	}
+	applyPoolConfig(transport, key.pool)
+	applyTLSSettings(transport, key.tls)
+	if actual, loaded := sharedTransports.LoadOrStore(key, transport); loaded {
+		// 并发创建时只保留先存入的一个
+		return actual.(*http.Transport)
+	}
+	return transport
+}
+
+// newDirectTransport 创建直连 Transport，沿用 http.DefaultTransport 的拨号与 TLS 设置
+func newDirectTransport() *http.Transport {
+	if base, ok := http.DefaultTransport.(*http.Transport); ok {
+		return base.Clone()
+	}
+	return &http.Transport{Proxy: http.ProxyFromEnvironment}
+}
+
+func applyPoolConfig(transport *http.Transport, cfg HTTPPoolConfig) {
+	transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	if transport.MaxIdleConns > 0 && transport.MaxIdleConns < cfg.MaxIdleConnsPerHost {
+		transport.MaxIdleConns = cfg.MaxIdleConnsPerHost
+	}
+	transport.IdleConnTimeout = cfg.IdleConnTimeout
+	transport.DisableKeepAlives = cfg.DisableKeepAlives
+	applyConnectTimeout(transport, cfg.ConnectTimeout)
+}
+
+// applyConnectTimeout 为 Transport 的拨号加上连接超时。
+// SOCKS5 Transport 已有自定义 DialContext，超时同样覆盖与代理的握手。
+func applyConnectTimeout(transport *http.Transport, timeout time.Duration) {
+	dial := transport.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{KeepAlive: 30 * time.Second}).DialContext
+	}
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return dial(ctx, network, addr)
+	}
+}
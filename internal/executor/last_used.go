@@ -0,0 +1,36 @@
+package executor
+
+import (
+	"sync"
+	"time"
+)
+
+// endpointUsage 记录端点最近一次被使用的时间
+type endpointUsage struct {
+	lastUsed sync.Map // int64 -> time.Time
+}
+
+func (u *endpointUsage) touch(endpoint *EndpointConfig) {
+	if endpoint == nil || endpoint.ID == 0 {
+		return
+	}
+	u.lastUsed.Store(endpoint.ID, time.Now())
+}
+
+// LastUsedAt 返回端点最近一次被使用的时间，未使用过返回零值
+func (c *ExecutionContext) LastUsedAt(endpointID int64) time.Time {
+	if v, ok := c.usage.lastUsed.Load(endpointID); ok {
+		return v.(time.Time)
+	}
+	return time.Time{}
+}
+
+// LastUsedSnapshot 返回所有端点最近一次被使用时间的快照
+func (c *ExecutionContext) LastUsedSnapshot() map[int64]time.Time {
+	result := make(map[int64]time.Time)
+	c.usage.lastUsed.Range(func(k, v interface{}) bool {
+		result[k.(int64)] = v.(time.Time)
+		return true
+	})
+	return result
+}
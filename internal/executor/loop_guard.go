@@ -0,0 +1,109 @@
+package executor
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+)
+
+// ForwardedHeader 转发时附带的环路标记，值为经过的代理实例 ID 列表（逗号分隔）。
+// 收到的请求中已包含本实例 ID，说明端点配置形成了环路。
+const ForwardedHeader = "X-CliHub-Forwarded"
+
+// ErrProxyLoop 端点目标指回了本代理
+var ErrProxyLoop = errors.New("proxy loop detected: endpoint points back to this proxy")
+
+// instanceID 本进程的代理实例 ID，用于跨多跳识别环路
+var instanceID = uuid.NewString()
+
+var selfPort int64
+
+var (
+	localAddrsOnce sync.Once
+	localAddrs     map[string]bool
+)
+
+// SetSelfPort 设置本代理的监听端口，用于识别指向自身的端点
+func SetSelfPort(port int) {
+	atomic.StoreInt64(&selfPort, int64(port))
+}
+
+// IsForwardLoop 判断请求是否已经经过本代理（即请求绕了一圈又回来了）
+func IsForwardLoop(h http.Header) bool {
+	for _, value := range h.Values(ForwardedHeader) {
+		for _, id := range strings.Split(value, ",") {
+			if strings.TrimSpace(id) == instanceID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// markForwarded 在转发请求的环路标记中追加本实例 ID（客户端带来的上游实例 ID 已由 copyRequestHeaders 复制）
+func markForwarded(req *http.Request) {
+	ids := append(req.Header.Values(ForwardedHeader), instanceID)
+	req.Header.Set(ForwardedHeader, strings.Join(ids, ", "))
+}
+
+// checkProxyLoop 目标地址为本机且端口与本代理监听端口相同时返回 ErrProxyLoop
+func checkProxyLoop(targetURL string) error {
+	port := int(atomic.LoadInt64(&selfPort))
+	if port <= 0 {
+		return nil
+	}
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return nil
+	}
+
+	targetPort := u.Port()
+	if targetPort == "" {
+		targetPort = "80"
+		if strings.EqualFold(u.Scheme, "https") {
+			targetPort = "443"
+		}
+	}
+	if targetPort != strconv.Itoa(port) {
+		return nil
+	}
+	if isLocalHost(u.Hostname()) {
+		return ErrProxyLoop
+	}
+	return nil
+}
+
+func isLocalHost(host string) bool {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	if ip.IsLoopback() || ip.IsUnspecified() {
+		return true
+	}
+
+	localAddrsOnce.Do(func() {
+		localAddrs = make(map[string]bool)
+		addrs, err := net.InterfaceAddrs()
+		if err != nil {
+			return
+		}
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok {
+				localAddrs[ipNet.IP.String()] = true
+			}
+		}
+	})
+	return localAddrs[ip.String()]
+}
@@ -0,0 +1,50 @@
+package executor
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestCheckProxyLoop(t *testing.T) {
+	// 端口 1 不会被 httptest 分配，避免影响并行测试
+	SetSelfPort(1)
+	defer SetSelfPort(0)
+
+	cases := []struct {
+		target string
+		loop   bool
+	}{
+		{target: "http://127.0.0.1:1/v1/messages", loop: true},
+		{target: "http://localhost:1/v1/messages", loop: true},
+		{target: "http://[::1]:1/v1/messages", loop: true},
+		{target: "http://0.0.0.0:1/v1/messages", loop: true},
+		{target: "http://127.0.0.1:2/v1/messages", loop: false},
+		{target: "https://api.example.com/v1/messages", loop: false},
+		{target: "http://api.example.com:1/v1/messages", loop: false},
+	}
+	for _, tc := range cases {
+		err := checkProxyLoop(tc.target)
+		if got := errors.Is(err, ErrProxyLoop); got != tc.loop {
+			t.Fatalf("%s: loop=%v want %v (err=%v)", tc.target, got, tc.loop, err)
+		}
+	}
+}
+
+func TestForwardedHeader(t *testing.T) {
+	t.Parallel()
+
+	req, _ := http.NewRequest(http.MethodPost, "http://upstream.invalid/v1/messages", nil)
+	req.Header.Set(ForwardedHeader, "other-hub")
+	if IsForwardLoop(req.Header) {
+		t.Fatalf("request from another hub should not be a loop")
+	}
+
+	markForwarded(req)
+	if got, want := req.Header.Get(ForwardedHeader), "other-hub, "+instanceID; got != want {
+		t.Fatalf("%s=%q want %q", ForwardedHeader, got, want)
+	}
+	if !IsForwardLoop(req.Header) {
+		t.Fatalf("request carrying this instance id should be a loop")
+	}
+}
@@ -0,0 +1,70 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxTokensFields 各协议请求体中的最大输出 token 字段：
+// Claude/Chat 的 max_tokens、Chat 的 max_completion_tokens、Responses 的 max_output_tokens
+var maxTokensFields = []string{"max_tokens", "max_completion_tokens", "max_output_tokens"}
+
+// minThinkingBudgetTokens Claude extended thinking 允许的最小 budget_tokens
+const minThinkingBudgetTokens = 1024
+
+// capMaxTokensInBody 按端点 MaxTokensCap 把请求体中超过上限的最大输出 token 数下调到上限
+// （作用于最终发送的请求体），返回下调记录（"原值 -> 上限"，未下调时为空）。
+// 请求未设置该字段时不补充。Claude 要求 thinking.budget_tokens 小于 max_tokens，
+// 因此 max_tokens 被下调后同步下调 budget_tokens，低于最小值时移除 thinking。
+func capMaxTokensInBody(root map[string]any, endpoint *EndpointConfig) string {
+	if endpoint.MaxTokensCap <= 0 {
+		return ""
+	}
+
+	limit := int64(endpoint.MaxTokensCap)
+	var clamped []string
+	for _, field := range maxTokensFields {
+		n, ok := root[field].(json.Number)
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseFloat(n.String(), 64)
+		if err != nil || value <= float64(limit) {
+			continue
+		}
+		root[field] = limit
+		clamped = append(clamped, fmt.Sprintf("%s %s -> %d", field, n.String(), limit))
+		if field == "max_tokens" {
+			if clamp := capThinkingBudget(root, limit); clamp != "" {
+				clamped = append(clamped, clamp)
+			}
+		}
+	}
+	return strings.Join(clamped, ", ")
+}
+
+// capThinkingBudget 把不小于 maxTokens 的 thinking.budget_tokens 下调到 maxTokens-1，
+// 下调后低于最小值时移除 thinking，返回下调记录
+func capThinkingBudget(root map[string]any, maxTokens int64) string {
+	thinking, ok := root["thinking"].(map[string]any)
+	if !ok {
+		return ""
+	}
+	n, ok := thinking["budget_tokens"].(json.Number)
+	if !ok {
+		return ""
+	}
+	value, err := strconv.ParseFloat(n.String(), 64)
+	if err != nil || value < float64(maxTokens) {
+		return ""
+	}
+	budget := maxTokens - 1
+	if budget < minThinkingBudgetTokens {
+		delete(root, "thinking")
+		return fmt.Sprintf("thinking.budget_tokens %s -> removed", n.String())
+	}
+	thinking["budget_tokens"] = budget
+	return fmt.Sprintf("thinking.budget_tokens %s -> %d", n.String(), budget)
+}
@@ -0,0 +1,32 @@
+package executor
+
+import "testing"
+
+func TestApplyMaxTokensCap(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name      string
+		cap       int
+		body      string
+		want      string
+		wantClamp string
+	}{
+		{name: "claude max_tokens clamped", cap: 8192, body: `{"max_tokens":64000,"model":"m"}`, want: `{"max_tokens":8192,"model":"m"}`, wantClamp: "max_tokens 64000 -> 8192"},
+		{name: "chat max_completion_tokens clamped", cap: 100, body: `{"max_completion_tokens":500}`, want: `{"max_completion_tokens":100}`, wantClamp: "max_completion_tokens 500 -> 100"},
+		{name: "responses max_output_tokens clamped", cap: 100, body: `{"max_output_tokens":101}`, want: `{"max_output_tokens":100}`, wantClamp: "max_output_tokens 101 -> 100"},
+		{name: "thinking budget clamped", cap: 8192, body: `{"max_tokens":64000,"thinking":{"type":"enabled","budget_tokens":32000}}`, want: `{"max_tokens":8192,"thinking":{"budget_tokens":8191,"type":"enabled"}}`, wantClamp: "max_tokens 64000 -> 8192, thinking.budget_tokens 32000 -> 8191"},
+		{name: "thinking budget below max kept", cap: 8192, body: `{"max_tokens":64000,"thinking":{"type":"enabled","budget_tokens":4096}}`, want: `{"max_tokens":8192,"thinking":{"budget_tokens":4096,"type":"enabled"}}`, wantClamp: "max_tokens 64000 -> 8192"},
+		{name: "thinking removed when budget too small", cap: 1000, body: `{"max_tokens":64000,"thinking":{"type":"enabled","budget_tokens":2048}}`, want: `{"max_tokens":1000}`, wantClamp: "max_tokens 64000 -> 1000, thinking.budget_tokens 2048 -> removed"},
+		{name: "below cap unchanged", cap: 8192, body: `{"max_tokens": 1024}`, want: `{"max_tokens": 1024}`},
+		{name: "missing field not added", cap: 8192, body: `{"model":"m"}`, want: `{"model":"m"}`},
+		{name: "zero cap disabled", cap: 0, body: `{"max_tokens":64000}`, want: `{"max_tokens":64000}`},
+		{name: "invalid json", cap: 10, body: `not json`, want: `not json`},
+	}
+	for _, tc := range cases {
+		got, clamp := prepareUpstreamBody([]byte(tc.body), &EndpointConfig{MaxTokensCap: tc.cap}, "/v1/messages")
+		if string(got) != tc.want || clamp != tc.wantClamp {
+			t.Fatalf("%s: got %s (%q) want %s (%q)", tc.name, got, clamp, tc.want, tc.wantClamp)
+		}
+	}
+}
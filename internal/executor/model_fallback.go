@@ -0,0 +1,87 @@
+package executor
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// modelErrorPhrases 上游错误信息中明确表示模型不存在/不可用的短语。
+// 只匹配针对模型本身的表述，避免 "invalid max_tokens for model" 这类参数错误触发回退。
+var modelErrorPhrases = []string{
+	"model_not_found",
+	"model not found",
+	"invalid model",
+	"unknown model",
+	"unsupported model",
+	"no such model",
+	"model is not supported",
+	"model is not available",
+	`"model: `, // Anthropic not_found_error: {"message":"model: claude-x"}
+}
+
+// modelErrorPattern 匹配 "The model `gpt-x` does not exist"、"models/gemini-x is not found" 等
+// 在模型名之后说明不存在的表述
+var modelErrorPattern = regexp.MustCompile(`\bmodels?\b[^.,;:]{0,100}?\b(?:does not exist|is not found|not found|is not supported|is not available)`)
+
+// isModelNotFoundError 判断上游响应是否为模型不存在/不可用的错误
+func isModelNotFoundError(result *ForwardResult) bool {
+	if result == nil || result.Streamed || len(result.Body) == 0 {
+		return false
+	}
+	switch result.StatusCode {
+	case http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity:
+	default:
+		return false
+	}
+
+	body := strings.ToLower(string(result.Body))
+	if !strings.Contains(body, "model") {
+		return false
+	}
+	for _, phrase := range modelErrorPhrases {
+		if strings.Contains(body, phrase) {
+			return true
+		}
+	}
+	return modelErrorPattern.MatchString(body)
+}
+
+// defaultModelRetry 在端点开启 fallbackToDefaultModel 且上游报告模型不可用时，
+// 返回改用端点默认模型的请求与端点；不满足条件时返回 nil。
+// 重试端点清空模型映射，确保默认模型原样发送到上游。
+func defaultModelRetry(endpoint *EndpointConfig, req *ForwardRequest, result *ForwardResult) (*ForwardRequest, *EndpointConfig, string) {
+	if endpoint == nil || !endpoint.FallbackToDefaultModel || req == nil {
+		return nil, nil, ""
+	}
+	defaultModel := strings.TrimSpace(endpoint.Model)
+	if defaultModel == "" || !isModelNotFoundError(result) {
+		return nil, nil, ""
+	}
+
+	attempted := ResolveUpstreamModel(extractModelFromBody(req.Body), endpoint)
+	if attempted == defaultModel {
+		return nil, nil, ""
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(req.Body, &body); err != nil {
+		return nil, nil, ""
+	}
+	// 模型不在请求体中（如 Gemini 原生路径）时无法替换
+	if _, ok := body["model"]; !ok {
+		return nil, nil, ""
+	}
+	body["model"] = defaultModel
+	newBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, nil, ""
+	}
+
+	retryReq := *req
+	retryReq.Body = newBody
+	retryEndpoint := *endpoint
+	retryEndpoint.Models = nil
+	return &retryReq, &retryEndpoint, attempted
+}
@@ -0,0 +1,86 @@
+package executor
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestDefaultModelRetry(t *testing.T) {
+	t.Parallel()
+
+	notFound := &ForwardResult{StatusCode: http.StatusNotFound, Body: []byte(`{"error":{"message":"The model 'gpt-x' does not exist"}}`)}
+	endpoint := &EndpointConfig{
+		Name:                   "ep",
+		Model:                  "default-model",
+		FallbackToDefaultModel: true,
+		Models:                 []ModelMapping{{Name: "gpt-x", Alias: "alias"}},
+	}
+
+	cases := []struct {
+		name      string
+		endpoint  *EndpointConfig
+		body      string
+		result    *ForwardResult
+		wantRetry bool
+		wantFrom  string
+	}{
+		{name: "mapped model not found", endpoint: endpoint, body: `{"model":"alias"}`, result: notFound, wantRetry: true, wantFrom: "gpt-x"},
+		{name: "option disabled", endpoint: &EndpointConfig{Model: "default-model"}, body: `{"model":"alias"}`, result: notFound},
+		{name: "unrelated 400", endpoint: endpoint, body: `{"model":"alias"}`, result: &ForwardResult{StatusCode: http.StatusBadRequest, Body: []byte(`{"error":"max_tokens too large"}`)}},
+		{name: "server error", endpoint: endpoint, body: `{"model":"alias"}`, result: &ForwardResult{StatusCode: http.StatusInternalServerError, Body: notFound.Body}},
+		{name: "already default", endpoint: endpoint, body: `{"model":"default-model"}`, result: notFound},
+		{name: "model not in body", endpoint: endpoint, body: `{"contents":[]}`, result: notFound},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			req := &ForwardRequest{Body: []byte(tc.body)}
+			retryReq, retryEndpoint, from := defaultModelRetry(tc.endpoint, req, tc.result)
+			if (retryReq != nil) != tc.wantRetry {
+				t.Fatalf("retry=%v, want %v", retryReq != nil, tc.wantRetry)
+			}
+			if !tc.wantRetry {
+				return
+			}
+			if from != tc.wantFrom {
+				t.Fatalf("from=%q, want %q", from, tc.wantFrom)
+			}
+			if got := extractModelFromBody(retryReq.Body); got != "default-model" {
+				t.Fatalf("retry model=%q", got)
+			}
+			if got := ResolveUpstreamModel(extractModelFromBody(retryReq.Body), retryEndpoint); got != "default-model" {
+				t.Fatalf("resolved upstream model=%q", got)
+			}
+		})
+	}
+}
+
+func TestIsModelNotFoundError(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		status int
+		body   string
+		want   bool
+	}{
+		{name: "openai does not exist", status: http.StatusNotFound, body: `{"error":{"message":"The model ` + "`gpt-x`" + ` does not exist or you do not have access to it.","code":"model_not_found"}}`, want: true},
+		{name: "anthropic not_found_error", status: http.StatusNotFound, body: `{"type":"error","error":{"type":"not_found_error","message":"model: claude-x"}}`, want: true},
+		{name: "gemini not found", status: http.StatusNotFound, body: `{"error":{"code":404,"message":"models/gemini-x is not found for API version v1beta"}}`, want: true},
+		{name: "invalid model", status: http.StatusBadRequest, body: `{"error":"Invalid model: foo"}`, want: true},
+		{name: "unknown model", status: http.StatusUnprocessableEntity, body: `{"detail":"Unknown model 'foo'"}`, want: true},
+		{name: "invalid parameter for model", status: http.StatusBadRequest, body: `{"error":{"message":"Invalid value for max_tokens: 99999 is too large for this model"}}`},
+		{name: "unsupported parameter", status: http.StatusBadRequest, body: `{"error":{"message":"Unsupported parameter: 'temperature' is not supported with this model."}}`},
+		{name: "model overloaded", status: http.StatusBadRequest, body: `{"error":{"message":"The model is currently unavailable, please retry"}}`},
+		{name: "unknown field", status: http.StatusBadRequest, body: `{"error":"unknown field 'foo' in request for model bar"}`},
+		{name: "server error", status: http.StatusInternalServerError, body: `{"error":{"code":"model_not_found"}}`},
+	}
+	for _, tc := range cases {
+		got := isModelNotFoundError(&ForwardResult{StatusCode: tc.status, Body: []byte(tc.body)})
+		if got != tc.want {
+			t.Fatalf("%s: isModelNotFoundError=%v want %v", tc.name, got, tc.want)
+		}
+	}
+}
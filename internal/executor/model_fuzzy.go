@@ -0,0 +1,92 @@
+package executor
+
+import (
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// fuzzyModelMatchEnabled 控制无精确映射时是否按归一化名称模糊匹配端点 Models（默认关闭）
+var fuzzyModelMatchEnabled atomic.Bool
+
+// SetFuzzyModelMatch 设置是否开启模型名模糊匹配，例如 claude-3-5-sonnet → claude-3-5-sonnet-latest
+func SetFuzzyModelMatch(enabled bool) {
+	fuzzyModelMatchEnabled.Store(enabled)
+}
+
+// FuzzyModelMatch 返回是否开启模型名模糊匹配
+func FuzzyModelMatch() bool {
+	return fuzzyModelMatchEnabled.Load()
+}
+
+var (
+	// modelDateSuffix 匹配结尾的日期/版本快照：-20240620、-2024-06-20、@20240620、-0613、-002
+	modelDateSuffix = regexp.MustCompile(`(?:[-@](?:\d{4}-\d{2}-\d{2}|\d{8}|\d{6}|\d{4}|\d{3}))$`)
+	// modelBedrockSuffix 匹配 Bedrock 模型 ID 的版本后缀：-v1:0
+	modelBedrockSuffix = regexp.MustCompile(`-v\d+:\d+$`)
+	// modelBedrockShortSuffix 匹配不带修订号的 Bedrock 版本后缀 -v2，只在带 anthropic. 前缀时去掉，
+	// 避免误删 deepseek-v3 这类属于模型家族名的版本
+	modelBedrockShortSuffix = regexp.MustCompile(`-v\d+$`)
+)
+
+// modelAliasSuffixes 归一化时去掉的别名后缀
+var modelAliasSuffixes = []string{"-latest"}
+
+// normalizeModelName 归一化模型名用于模糊比较：
+// 小写，去掉 provider 前缀（anthropic/、models/、us.anthropic.），把 . _ 空格统一为 -，
+// 再反复去掉结尾的 -latest、日期快照与 Bedrock 版本后缀
+func normalizeModelName(model string) string {
+	s := strings.ToLower(strings.TrimSpace(model))
+	if i := strings.LastIndex(s, "/"); i >= 0 {
+		s = s[i+1:]
+	}
+	bedrock := false
+	if i := strings.LastIndex(s, "anthropic."); i >= 0 {
+		s = s[i+len("anthropic."):]
+		bedrock = true
+	}
+	s = strings.NewReplacer(".", "-", "_", "-", " ", "-").Replace(s)
+
+	for {
+		trimmed := s
+		for _, suffix := range modelAliasSuffixes {
+			trimmed = strings.TrimSuffix(trimmed, suffix)
+		}
+		trimmed = modelBedrockSuffix.ReplaceAllString(trimmed, "")
+		if bedrock {
+			trimmed = modelBedrockShortSuffix.ReplaceAllString(trimmed, "")
+		}
+		trimmed = modelDateSuffix.ReplaceAllString(trimmed, "")
+		if trimmed == s || trimmed == "" {
+			return s
+		}
+		s = trimmed
+	}
+}
+
+// fuzzyMatchModel 在精确映射都未命中时，按归一化名称匹配 exact 类型映射的实际名称或别名，
+// 命中时返回映射的实际模型名（按顺序，首个匹配生效）
+func fuzzyMatchModel(models []ModelMapping, requestModel string) (string, bool) {
+	want := normalizeModelName(requestModel)
+	if want == "" {
+		return "", false
+	}
+	for _, mapping := range models {
+		switch strings.ToLower(strings.TrimSpace(mapping.MatchType)) {
+		case "", ModelMatchExact:
+		default:
+			continue
+		}
+		name := strings.TrimSpace(mapping.Name)
+		if name == "" {
+			continue
+		}
+		if normalizeModelName(name) == want {
+			return name, true
+		}
+		if alias := strings.TrimSpace(mapping.Alias); alias != "" && normalizeModelName(alias) == want {
+			return name, true
+		}
+	}
+	return "", false
+}
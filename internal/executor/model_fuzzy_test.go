@@ -0,0 +1,93 @@
+package executor
+
+import "testing"
+
+func TestNormalizeModelName(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{in: "claude-3-5-sonnet", want: "claude-3-5-sonnet"},
+		{in: "claude-3-5-sonnet-latest", want: "claude-3-5-sonnet"},
+		{in: "claude-3-5-sonnet-20241022", want: "claude-3-5-sonnet"},
+		{in: "Claude-3.5-Sonnet", want: "claude-3-5-sonnet"},
+		{in: "claude-3-5-sonnet@20240620", want: "claude-3-5-sonnet"},
+		{in: "anthropic/claude-3-5-sonnet", want: "claude-3-5-sonnet"},
+		{in: "us.anthropic.claude-3-5-sonnet-20240620-v1:0", want: "claude-3-5-sonnet"},
+		{in: "anthropic.claude-3-5-sonnet-20241022-v2", want: "claude-3-5-sonnet"},
+		{in: "claude-3-5-sonnet-20241022-v2:0", want: "claude-3-5-sonnet"},
+		{in: "deepseek-v3", want: "deepseek-v3"},
+		{in: "deepseek-v2", want: "deepseek-v2"},
+		{in: "deepseek-ai/DeepSeek-V3", want: "deepseek-v3"},
+		{in: "claude-sonnet-4-20250514", want: "claude-sonnet-4"},
+		{in: "gpt-4o-2024-08-06", want: "gpt-4o"},
+		{in: "gpt-4-0613", want: "gpt-4"},
+		{in: "gpt-4.1", want: "gpt-4-1"},
+		{in: "models/gemini-1.5-pro-002", want: "gemini-1-5-pro"},
+		{in: "gemini_2.0_flash", want: "gemini-2-0-flash"},
+		{in: "o1", want: "o1"},
+	}
+	for _, tc := range cases {
+		if got := normalizeModelName(tc.in); got != tc.want {
+			t.Fatalf("normalizeModelName(%q)=%q want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestFuzzyMatchModel(t *testing.T) {
+	t.Parallel()
+
+	models := []ModelMapping{
+		{Name: "claude-3-5-sonnet-latest"},
+		{Name: "gpt-4o-2024-08-06", Alias: "gpt4o"},
+		{Name: "claude-3-opus-20240229", Alias: "claude-3-opus*", MatchType: ModelMatchPrefix},
+		{Name: "gemini-1.5-pro-002"},
+		{Name: "deepseek-v3"},
+	}
+	cases := []struct {
+		request string
+		want    string
+		ok      bool
+	}{
+		{request: "claude-3-5-sonnet", want: "claude-3-5-sonnet-latest", ok: true},
+		{request: "claude-3-5-sonnet-20241022", want: "claude-3-5-sonnet-latest", ok: true},
+		{request: "gpt-4o", want: "gpt-4o-2024-08-06", ok: true},
+		{request: "gemini-1.5-pro", want: "gemini-1.5-pro-002", ok: true},
+		{request: "claude-3-opus", ok: false}, // prefix/regex mappings are not fuzzy matched
+		{request: "claude-3-haiku", ok: false},
+		{request: "gpt-4o-mini", ok: false},
+		{request: "deepseek-v3", want: "deepseek-v3", ok: true},
+		{request: "deepseek-v2", ok: false}, // family versions are not stripped
+		{request: "deepseek", ok: false},
+	}
+	for _, tc := range cases {
+		got, ok := fuzzyMatchModel(models, tc.request)
+		if ok != tc.ok || got != tc.want {
+			t.Fatalf("fuzzyMatchModel(%q)=%q,%v want %q,%v", tc.request, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+// Not parallel: toggles the global fuzzy matching switch
+func TestResolveUpstreamModel_FuzzyMatch(t *testing.T) {
+	endpoint := &EndpointConfig{
+		Model:  "default-model",
+		Models: []ModelMapping{{Name: "claude-3-5-sonnet-latest"}, {Name: "claude-3-5-haiku-latest"}},
+	}
+
+	SetFuzzyModelMatch(false)
+	if got := ResolveUpstreamModel("claude-3-5-sonnet", endpoint); got != "default-model" {
+		t.Fatalf("disabled: model=%q want default-model", got)
+	}
+
+	SetFuzzyModelMatch(true)
+	defer SetFuzzyModelMatch(false)
+	if got := ResolveUpstreamModel("claude-3-5-sonnet-20241022", endpoint); got != "claude-3-5-sonnet-latest" {
+		t.Fatalf("enabled: model=%q want claude-3-5-sonnet-latest", got)
+	}
+	if got := ResolveUpstreamModel("claude-3-opus", endpoint); got != "default-model" {
+		t.Fatalf("enabled, no match: model=%q want default-model", got)
+	}
+}
@@ -0,0 +1,88 @@
+package executor
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+
+	"clisimplehub/internal/logger"
+)
+
+// 模型映射匹配方式
+const (
+	ModelMatchExact  = "exact"
+	ModelMatchPrefix = "prefix"
+	ModelMatchRegex  = "regex"
+)
+
+// modelRegexCache 缓存已编译的别名正则（编译失败记为 nil，只告警一次）
+var modelRegexCache sync.Map // map[string]*regexp.Regexp
+
+// compileModelRegex 编译别名正则并锚定首尾，正则必须匹配完整的模型名
+func compileModelRegex(pattern string) *regexp.Regexp {
+	if cached, ok := modelRegexCache.Load(pattern); ok {
+		re, _ := cached.(*regexp.Regexp)
+		return re
+	}
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		logger.Warn("[Executor] invalid model mapping regex %q ignored: %v", pattern, err)
+		modelRegexCache.Store(pattern, (*regexp.Regexp)(nil))
+		return nil
+	}
+	modelRegexCache.Store(pattern, re)
+	return re
+}
+
+// matchModelMapping 按映射的 matchType 匹配请求模型，匹配成功时返回上游模型名
+//   - exact:  别名或实际名称大小写不敏感相等
+//   - prefix: 请求模型以别名开头（可带结尾 *）；Name 为空时去掉该前缀
+//   - regex:  别名为正则，须匹配完整模型名（自动锚定首尾）；Name 作为替换模板（支持 $1），
+//     例如 my-org/(.*) -> $1；Name 为空时保持请求模型不变
+func matchModelMapping(mapping ModelMapping, requestModel string) (string, bool) {
+	alias := strings.TrimSpace(mapping.Alias)
+	name := strings.TrimSpace(mapping.Name)
+
+	switch strings.ToLower(strings.TrimSpace(mapping.MatchType)) {
+	case ModelMatchPrefix:
+		prefix := strings.TrimSuffix(alias, "*")
+		if prefix == "" || len(requestModel) < len(prefix) || !strings.EqualFold(requestModel[:len(prefix)], prefix) {
+			return "", false
+		}
+		if name != "" {
+			return name, true
+		}
+		if stripped := requestModel[len(prefix):]; stripped != "" {
+			return stripped, true
+		}
+		return requestModel, true
+	case ModelMatchRegex:
+		if alias == "" {
+			return "", false
+		}
+		re := compileModelRegex(alias)
+		if re == nil || !re.MatchString(requestModel) {
+			return "", false
+		}
+		if name == "" {
+			return requestModel, true
+		}
+		if upstream := re.ReplaceAllString(requestModel, name); upstream != "" {
+			return upstream, true
+		}
+		return requestModel, true
+	default:
+		// 如果请求的模型匹配别名，返回实际模型名
+		if alias != "" && strings.EqualFold(alias, requestModel) {
+			if name != "" {
+				return name, true
+			}
+			return requestModel, true
+		}
+		// 如果请求的模型匹配实际名称，直接返回
+		if name != "" && strings.EqualFold(name, requestModel) {
+			return name, true
+		}
+		return "", false
+	}
+}
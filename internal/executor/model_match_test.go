@@ -0,0 +1,76 @@
+package executor
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMatchModelMapping(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		mapping ModelMapping
+		model   string
+		want    string
+		wantOK  bool
+	}{
+		{name: "exact alias", mapping: ModelMapping{Name: "gpt-4o-2024-08-06", Alias: "gpt-4o"}, model: "GPT-4o", want: "gpt-4o-2024-08-06", wantOK: true},
+		{name: "exact name", mapping: ModelMapping{Name: "gpt-4o", Alias: "x"}, model: "gpt-4o", want: "gpt-4o", wantOK: true},
+		{name: "exact miss", mapping: ModelMapping{Name: "gpt-4o", Alias: "x"}, model: "gpt-4o-mini"},
+		{name: "prefix with star", mapping: ModelMapping{Name: "gpt-4o", Alias: "gpt-4*", MatchType: "prefix"}, model: "gpt-4-turbo", want: "gpt-4o", wantOK: true},
+		{name: "prefix is case-insensitive", mapping: ModelMapping{Name: "gpt-4o", Alias: "GPT-4", MatchType: "Prefix"}, model: "gpt-4-turbo", want: "gpt-4o", wantOK: true},
+		{name: "prefix strip", mapping: ModelMapping{Alias: "my-org/", MatchType: "prefix"}, model: "my-org/claude-3", want: "claude-3", wantOK: true},
+		{name: "prefix miss", mapping: ModelMapping{Name: "gpt-4o", Alias: "gpt-4", MatchType: "prefix"}, model: "o1-gpt-4"},
+		{name: "regex full match", mapping: ModelMapping{Name: "gpt-4o", Alias: `gpt-4(-\w+)?`, MatchType: "regex"}, model: "gpt-4-turbo", want: "gpt-4o", wantOK: true},
+		{name: "regex is anchored at start", mapping: ModelMapping{Name: "gpt-4o", Alias: "gpt-4", MatchType: "regex"}, model: "my-gpt-4"},
+		{name: "regex is anchored at end", mapping: ModelMapping{Name: "gpt-4o", Alias: "gpt-4", MatchType: "regex"}, model: "gpt-4-turbo"},
+		{name: "regex template", mapping: ModelMapping{Name: "$1", Alias: `my-org/(.+)`, MatchType: "regex"}, model: "my-org/claude-3", want: "claude-3", wantOK: true},
+		{name: "regex without name keeps model", mapping: ModelMapping{Alias: `claude-.*`, MatchType: "regex"}, model: "claude-3", want: "claude-3", wantOK: true},
+		{name: "invalid regex ignored", mapping: ModelMapping{Name: "x", Alias: `gpt-(`, MatchType: "regex"}, model: "gpt-("},
+	}
+	for _, tc := range cases {
+		got, ok := matchModelMapping(tc.mapping, tc.model)
+		if ok != tc.wantOK || got != tc.want {
+			t.Fatalf("%s: got %q ok=%v want %q ok=%v", tc.name, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
+
+func TestResolveUpstreamModelFirstMappingWins(t *testing.T) {
+	t.Parallel()
+
+	endpoint := &EndpointConfig{Models: []ModelMapping{
+		{Name: "broken", Alias: `gpt-(`, MatchType: ModelMatchRegex},
+		{Name: "gpt-4o", Alias: "gpt-4", MatchType: ModelMatchPrefix},
+		{Name: "never", Alias: "gpt-4-turbo"},
+	}}
+	cases := []struct {
+		model string
+		want  string
+	}{
+		{model: "gpt-4-turbo", want: "gpt-4o"},
+		{model: "claude-3", want: "claude-3"},
+	}
+	for _, tc := range cases {
+		if got := ResolveUpstreamModel(tc.model, endpoint); got != tc.want {
+			t.Fatalf("%s: got %q want %q", tc.model, got, tc.want)
+		}
+	}
+}
+
+func TestModelMappingJSON(t *testing.T) {
+	t.Parallel()
+
+	var mapping ModelMapping
+	if err := json.Unmarshal([]byte(`{"name":"n","alias":"a*","matchType":"prefix"}`), &mapping); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if mapping.MatchType != ModelMatchPrefix {
+		t.Fatalf("matchType not decoded: %+v", mapping)
+	}
+	data, _ := json.Marshal(ModelMapping{Name: "n", Alias: "a"})
+	if string(data) != `{"name":"n","alias":"a"}` {
+		t.Fatalf("marshal=%s", data)
+	}
+}
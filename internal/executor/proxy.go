@@ -16,47 +16,85 @@ import (
 // DefaultHTTPTimeout 默认 HTTP 超时时间
 const DefaultHTTPTimeout = 300 * time.Second
 
-// NewHTTPClient 创建 HTTP 客户端，支持代理配置
+// NewHTTPClient 创建 HTTP 客户端，支持代理与端点 TLS 配置
 // 优先级: endpoint.ProxyURL > 默认直连
+// 相同代理与 TLS 设置的客户端共享同一个 Transport，以复用连接池
 func NewHTTPClient(endpoint *EndpointConfig, timeout time.Duration) *http.Client {
 	if timeout <= 0 {
 		timeout = DefaultHTTPTimeout
 	}
 
 	client := &http.Client{Timeout: timeout}
+	tlsCfg := resolveTLSSettings(endpoint)
+
+	if parsedURL := ResolveProxyURL(endpoint); parsedURL != nil {
+		transport := sharedTransport(parsedURL.String(), tlsCfg, func() *http.Transport {
+			return buildProxyTransport(parsedURL)
+		})
+		if transport != nil {
+			client.Transport = transport
+			return client
+		}
+	}
+
+	client.Transport = sharedTransport("", tlsCfg, newDirectTransport)
+	return client
+}
 
+// ResolveProxyURL 解析端点代理地址，并合并独立配置的代理账号密码
+// URL 中已带 user:pass 时优先使用 URL 中的凭据
+func ResolveProxyURL(endpoint *EndpointConfig) *url.URL {
 	if endpoint == nil {
-		return client
+		return nil
 	}
-
 	proxyURL := strings.TrimSpace(endpoint.ProxyURL)
 	if proxyURL == "" {
-		return client
+		return nil
 	}
 
-	transport := buildProxyTransport(proxyURL)
-	if transport != nil {
-		client.Transport = transport
+	parsedURL, err := url.Parse(proxyURL)
+	if err != nil {
+		logger.Warn("[Executor] parse proxy URL failed: %s", MaskProxyURL(proxyURL))
+		return nil
 	}
 
-	return client
+	username := strings.TrimSpace(endpoint.ProxyUsername)
+	if parsedURL.User == nil && username != "" {
+		if endpoint.ProxyPassword != "" {
+			parsedURL.User = url.UserPassword(username, endpoint.ProxyPassword)
+		} else {
+			parsedURL.User = url.User(username)
+		}
+	}
+	return parsedURL
 }
 
-// buildProxyTransport 根据代理 URL 创建 HTTP Transport
-// 支持 socks5, http, https 代理协议
-func buildProxyTransport(proxyURL string) *http.Transport {
-	if proxyURL == "" {
-		return nil
+// MaskProxyURL 隐藏代理 URL 中的密码，用于日志输出
+func MaskProxyURL(proxyURL string) string {
+	parsedURL, err := url.Parse(strings.TrimSpace(proxyURL))
+	if err != nil {
+		// 无法解析时整体隐藏 userinfo 部分
+		if at := strings.LastIndex(proxyURL, "@"); at >= 0 {
+			if scheme := strings.Index(proxyURL, "://"); scheme >= 0 && scheme < at {
+				return proxyURL[:scheme+3] + "****@" + proxyURL[at+1:]
+			}
+			return "****@" + proxyURL[at+1:]
+		}
+		return proxyURL
 	}
+	return parsedURL.Redacted()
+}
 
-	parsedURL, err := url.Parse(proxyURL)
-	if err != nil {
-		logger.Warn("[Executor] parse proxy URL failed: %v", err)
+// buildProxyTransport 根据代理 URL 创建 HTTP Transport
+// 支持 socks5, http, https 代理协议
+// http/https 代理的凭据由 net/http 自动转换为 Proxy-Authorization 头
+func buildProxyTransport(parsedURL *url.URL) *http.Transport {
+	if parsedURL == nil {
 		return nil
 	}
 
-	switch parsedURL.Scheme {
-	case "socks5":
+	switch strings.ToLower(parsedURL.Scheme) {
+	case "socks5", "socks5h":
 		return buildSOCKS5Transport(parsedURL)
 	case "http", "https":
 		return &http.Transport{Proxy: http.ProxyURL(parsedURL)}
@@ -77,24 +115,32 @@ func buildSOCKS5Transport(parsedURL *url.URL) *http.Transport {
 
 	dialer, err := proxy.SOCKS5("tcp", parsedURL.Host, auth, proxy.Direct)
 	if err != nil {
-		logger.Warn("[Executor] create SOCKS5 dialer failed: %v", err)
+		logger.Warn("[Executor] create SOCKS5 dialer failed (%s): %v", parsedURL.Redacted(), err)
 		return nil
 	}
 
 	return &http.Transport{
 		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if cd, ok := dialer.(proxy.ContextDialer); ok {
+				return cd.DialContext(ctx, network, addr)
+			}
 			return dialer.Dial(network, addr)
 		},
 	}
 }
 
 // ApplyEndpointHeaders 应用端点配置的自定义 headers
+// 先应用供应商默认 headers，再应用端点 headers（同名时端点优先）
 func ApplyEndpointHeaders(req *http.Request, endpoint *EndpointConfig) {
-	if endpoint == nil || len(endpoint.Headers) == 0 {
+	if endpoint == nil {
 		return
 	}
+	applyHeaders(req, endpoint.VendorHeaders)
+	applyHeaders(req, endpoint.Headers)
+}
 
-	for key, value := range endpoint.Headers {
+func applyHeaders(req *http.Request, headers map[string]string) {
+	for key, value := range headers {
 		key = strings.TrimSpace(key)
 		value = strings.TrimSpace(value)
 		if key != "" && value != "" {
@@ -105,6 +151,7 @@ func ApplyEndpointHeaders(req *http.Request, endpoint *EndpointConfig) {
 
 // ResolveUpstreamModel 根据配置解析上游模型名称
 // 如果配置了模型映射，返回映射后的模型名；否则返回原始模型名
+// 请求未指定模型时依次使用端点 Model 与接口类型的全局默认模型
 func ResolveUpstreamModel(requestModel string, endpoint *EndpointConfig) string {
 	if endpoint == nil {
 		return requestModel
@@ -114,25 +161,20 @@ func ResolveUpstreamModel(requestModel string, endpoint *EndpointConfig) string
 		if endpoint.Model != "" {
 			return endpoint.Model
 		}
-		return ""
+		return DefaultModel(endpoint.InterfaceType)
 	}
 
-	// 检查模型映射
+	// 检查模型映射（按顺序，首个匹配生效）
 	for _, mapping := range endpoint.Models {
-		alias := strings.TrimSpace(mapping.Alias)
-		name := strings.TrimSpace(mapping.Name)
-
-		// 如果请求的模型匹配别名，返回实际模型名
-		if alias != "" && strings.EqualFold(alias, requestModel) {
-			if name != "" {
-				return name
-			}
-			return requestModel
+		if upstream, ok := matchModelMapping(mapping, requestModel); ok {
+			return upstream
 		}
+	}
 
-		// 如果请求的模型匹配实际名称，直接返回
-		if name != "" && strings.EqualFold(name, requestModel) {
-			return name
+	// 开启模糊匹配时，按归一化名称（去掉日期、-latest 等后缀）匹配
+	if FuzzyModelMatch() {
+		if upstream, ok := fuzzyMatchModel(endpoint.Models, requestModel); ok {
+			return upstream
 		}
 	}
 
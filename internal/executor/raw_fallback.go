@@ -0,0 +1,38 @@
+package executor
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// maxRawFallbackBytes 流式回退时最多缓冲的原始上游数据，超出后放弃回退按正常转换输出
+const maxRawFallbackBytes = 1 << 20
+
+// rawFallbackDisabled 零值即开启回退，保持 rawFallbackOnTransformError 默认为 true
+var rawFallbackDisabled atomic.Bool
+
+// SetRawFallbackOnTransformError 设置 transformer 对成功的上游响应没有任何输出时（如解析失败），
+// 是否把上游原始响应体连同原 Content-Type 透传给客户端。关闭时保持严格行为：客户端收到转换错误或空流。
+func SetRawFallbackOnTransformError(enabled bool) {
+	rawFallbackDisabled.Store(!enabled)
+}
+
+// RawFallbackOnTransformError 返回是否开启原始响应透传回退
+func RawFallbackOnTransformError() bool {
+	return !rawFallbackDisabled.Load()
+}
+
+// writeRawUpstreamHeader 按上游原始响应头（含 Content-Type）写出响应头
+func writeRawUpstreamHeader(w http.ResponseWriter, resp *http.Response) {
+	for key, values := range resp.Header {
+		switch strings.ToLower(key) {
+		case "content-length", "content-encoding":
+			continue
+		}
+		for _, v := range values {
+			w.Header().Add(key, v)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+}
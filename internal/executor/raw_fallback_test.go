@@ -0,0 +1,72 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"clisimplehub/internal/transformer"
+)
+
+func TestRawFallbackOnTransformError(t *testing.T) {
+	// 修改全局回退开关，不能并行
+	t.Cleanup(func() { SetRawFallbackOnTransformError(true) })
+
+	tr, err := transformer.Get("codex", "openai/chat-completions")
+	if err != nil {
+		t.Fatalf("transformer.Get err=%v", err)
+	}
+	endpoint := &EndpointConfig{Name: "ep", Transformer: "openai/chat-completions"}
+	newResp := func(contentType, body string) *http.Response {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{contentType}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}
+	}
+
+	cases := []struct {
+		name     string
+		enabled  bool
+		stream   bool
+		body     string
+		wantBody string
+		wantType string
+		wantErr  bool
+	}{
+		{name: "non-stream passthrough", enabled: true, body: "plain text", wantBody: "plain text", wantType: "text/plain"},
+		{name: "non-stream strict", enabled: false, body: "plain text", wantErr: true},
+		{name: "stream passthrough", enabled: true, stream: true, body: "data: not json\n\n", wantBody: "data: not json\n", wantType: "text/event-stream"},
+		{name: "stream transformed", enabled: true, stream: true, body: "data: {\"id\":\"c1\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"hi\"}}]}\n\n", wantBody: "response.output_text.delta", wantType: tr.OutputContentType(true)},
+		{name: "stream strict", enabled: false, stream: true, body: "data: not json\n\n", wantBody: "", wantType: tr.OutputContentType(true)},
+	}
+	for _, tc := range cases {
+		SetRawFallbackOnTransformError(tc.enabled)
+		if tc.stream {
+			w := httptest.NewRecorder()
+			handleTransformedStreamingResponse(context.Background(), w, newResp("text/event-stream", tc.body), &ForwardResult{StatusCode: http.StatusOK}, endpoint, tr, "m", nil, nil, nil)
+			if got := w.Header().Get("Content-Type"); got != tc.wantType {
+				t.Fatalf("%s: content-type=%q want %q", tc.name, got, tc.wantType)
+			}
+			if !strings.Contains(w.Body.String(), tc.wantBody) || (tc.wantBody == "" && w.Body.Len() != 0) {
+				t.Fatalf("%s: body=%q want %q", tc.name, w.Body.String(), tc.wantBody)
+			}
+			continue
+		}
+
+		resp := newResp("text/plain", tc.body)
+		result := handleTransformedNonStreamingResponse(context.Background(), resp, &ForwardResult{StatusCode: http.StatusOK, Headers: resp.Header.Clone()}, tr, "m", nil, nil)
+		if (result.Error != nil) != tc.wantErr {
+			t.Fatalf("%s: err=%v wantErr=%v", tc.name, result.Error, tc.wantErr)
+		}
+		if tc.wantErr {
+			continue
+		}
+		if string(result.Body) != tc.wantBody || result.Headers.Get("Content-Type") != tc.wantType {
+			t.Fatalf("%s: body=%q content-type=%q", tc.name, result.Body, result.Headers.Get("Content-Type"))
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// encryptedReasoningInclude 让 Responses 接口返回加密的 reasoning，供多轮对话回传
+const encryptedReasoningInclude = "reasoning.encrypted_content"
+
+// ValidateReasoningSummary 校验端点的 ReasoningSummary，空值表示不写入
+func ValidateReasoningSummary(summary string) error {
+	switch strings.TrimSpace(summary) {
+	case "", "auto", "concise", "detailed":
+		return nil
+	}
+	return fmt.Errorf("invalid reasoningSummary %q (expected auto, concise or detailed)", summary)
+}
+
+// injectReasoningOptions 按端点配置为发往 Responses 接口的请求体注入 reasoning 相关字段，
+// 返回是否有改动：IncludeReasoning 在 include 中追加 reasoning.encrypted_content；
+// ReasoningSummary 在客户端未指定 reasoning.summary 时写入。其他接口的请求不处理。
+func injectReasoningOptions(root map[string]any, endpoint *EndpointConfig, targetPath string) bool {
+	if !isResponsesPath(targetPath) {
+		return false
+	}
+	summary := strings.TrimSpace(endpoint.ReasoningSummary)
+	if !endpoint.IncludeReasoning && summary == "" {
+		return false
+	}
+
+	changed := false
+	if endpoint.IncludeReasoning {
+		include, _ := root["include"].([]any)
+		found := false
+		for _, item := range include {
+			if s, ok := item.(string); ok && s == encryptedReasoningInclude {
+				found = true
+				break
+			}
+		}
+		if !found {
+			root["include"] = append(include, encryptedReasoningInclude)
+			changed = true
+		}
+	}
+	if summary != "" {
+		reasoning, ok := root["reasoning"].(map[string]any)
+		if !ok {
+			reasoning = map[string]any{}
+		}
+		if _, exists := reasoning["summary"]; !exists {
+			reasoning["summary"] = summary
+			root["reasoning"] = reasoning
+			changed = true
+		}
+	}
+	return changed
+}
+
+// isResponsesPath 判断目标路径是否为 OpenAI Responses 接口
+func isResponsesPath(path string) bool {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	return strings.HasSuffix(strings.TrimRight(strings.ToLower(path), "/"), "/responses")
+}
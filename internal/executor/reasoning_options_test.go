@@ -0,0 +1,81 @@
+package executor
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestApplyReasoningOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		endpoint *EndpointConfig
+		path     string
+		body     string
+		want     string
+	}{
+		{
+			name:     "include and summary added",
+			endpoint: &EndpointConfig{IncludeReasoning: true, ReasoningSummary: "auto"},
+			path:     "/v1/responses",
+			body:     `{"model":"gpt-5","reasoning":{"effort":"high"}}`,
+			want:     `{"model":"gpt-5","include":["reasoning.encrypted_content"],"reasoning":{"effort":"high","summary":"auto"}}`,
+		},
+		{
+			name:     "existing include and client summary kept",
+			endpoint: &EndpointConfig{IncludeReasoning: true, ReasoningSummary: "detailed"},
+			path:     "/responses/",
+			body:     `{"include":["message.output_text.logprobs","reasoning.encrypted_content"],"reasoning":{"summary":"concise"}}`,
+			want:     `{"include":["message.output_text.logprobs","reasoning.encrypted_content"],"reasoning":{"summary":"concise"}}`,
+		},
+		{
+			name:     "include appended",
+			endpoint: &EndpointConfig{IncludeReasoning: true},
+			path:     "/v1/responses?beta=true",
+			body:     `{"include":["message.output_text.logprobs"]}`,
+			want:     `{"include":["message.output_text.logprobs","reasoning.encrypted_content"]}`,
+		},
+		{
+			name:     "non responses path untouched",
+			endpoint: &EndpointConfig{IncludeReasoning: true, ReasoningSummary: "auto"},
+			path:     "/v1/chat/completions",
+			body:     `{"model":"gpt-5"}`,
+			want:     `{"model":"gpt-5"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		got, _ := prepareUpstreamBody([]byte(tt.body), tt.endpoint, tt.path)
+		var gotV, wantV any
+		if err := json.Unmarshal(got, &gotV); err != nil {
+			t.Fatalf("%s: invalid output %s: %v", tt.name, got, err)
+		}
+		_ = json.Unmarshal([]byte(tt.want), &wantV)
+		if !reflect.DeepEqual(gotV, wantV) {
+			t.Fatalf("%s: got %s want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidateReasoningSummary(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		summary string
+		wantErr bool
+	}{
+		{summary: ""},
+		{summary: "auto"},
+		{summary: " concise "},
+		{summary: "detailed"},
+		{summary: "verbose", wantErr: true},
+		{summary: "Auto", wantErr: true},
+	}
+	for _, tc := range cases {
+		if err := ValidateReasoningSummary(tc.summary); (err != nil) != tc.wantErr {
+			t.Fatalf("%q: err=%v wantErr=%v", tc.summary, err, tc.wantErr)
+		}
+	}
+}
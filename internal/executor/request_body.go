@@ -0,0 +1,37 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// prepareUpstreamBody 对最终发送的请求体（transformer 之后）只解析一次，依次应用模型映射、
+// body 覆盖、reasoning 选项、参数删除和最大输出 token 上限，有改动时才重新编码。
+// 返回处理后的请求体和最大输出 token 的下调记录（未下调时为空）。
+func prepareUpstreamBody(body []byte, endpoint *EndpointConfig, targetPath string) ([]byte, string) {
+	if endpoint == nil || len(body) == 0 {
+		return body, ""
+	}
+
+	var root map[string]any
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&root); err != nil || root == nil {
+		return body, ""
+	}
+
+	changed := mapModelInBody(root, endpoint)
+	changed = overrideBodyFields(root, endpoint) || changed
+	changed = injectReasoningOptions(root, endpoint, targetPath) || changed
+	changed = dropBodyParams(root, endpoint) || changed
+	clamp := capMaxTokensInBody(root, endpoint)
+	if !changed && clamp == "" {
+		return body, ""
+	}
+
+	out, err := json.Marshal(root)
+	if err != nil {
+		return body, ""
+	}
+	return out, clamp
+}
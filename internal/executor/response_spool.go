@@ -0,0 +1,173 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultResponseSpoolThresholdBytes 非流式响应体超过该大小时落盘到临时文件，再从文件流式写回客户端
+const DefaultResponseSpoolThresholdBytes = 32 * 1024 * 1024
+
+// spoolWindowBytes 落盘响应保留在内存中的首尾片段大小，用于提取 token 用量与日志展示
+const spoolWindowBytes = 64 * 1024
+
+var responseSpoolThresholdBytes int64 = DefaultResponseSpoolThresholdBytes
+
+// SetResponseSpoolThresholdBytes 设置非流式响应落盘阈值，n <= 0 时恢复默认值
+func SetResponseSpoolThresholdBytes(n int) {
+	if n <= 0 {
+		n = DefaultResponseSpoolThresholdBytes
+	}
+	atomic.StoreInt64(&responseSpoolThresholdBytes, int64(n))
+}
+
+// ResponseSpoolThresholdBytes 返回非流式响应落盘阈值
+func ResponseSpoolThresholdBytes() int {
+	return int(atomic.LoadInt64(&responseSpoolThresholdBytes))
+}
+
+// SpooledBody 是落盘到临时文件的非流式响应体。持有者必须调用 Close 删除临时文件，
+// 重复调用 Close 是安全的。
+type SpooledBody struct {
+	file *os.File
+	size int64
+	head []byte
+	tail []byte
+	once sync.Once
+}
+
+// Size 返回响应体总字节数
+func (s *SpooledBody) Size() int64 {
+	return s.size
+}
+
+// Head 返回响应体开头的片段（最多 spoolWindowBytes 字节）
+func (s *SpooledBody) Head() []byte {
+	return s.head
+}
+
+// WriteTo 从头把完整响应体写入 w
+func (s *SpooledBody) WriteTo(w io.Writer) (int64, error) {
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return io.Copy(w, s.file)
+}
+
+// Close 关闭并删除临时文件
+func (s *SpooledBody) Close() error {
+	var err error
+	s.once.Do(func() {
+		err = s.file.Close()
+		if rmErr := os.Remove(s.file.Name()); rmErr != nil && !errors.Is(rmErr, os.ErrNotExist) && err == nil {
+			err = rmErr
+		}
+	})
+	return err
+}
+
+// usageSnippet 从首尾片段中找出 usage 对象，拼成可交给 ExtractTokens 的最小 JSON；
+// usage 通常位于响应末尾，因此优先查找尾部
+func (s *SpooledBody) usageSnippet() []byte {
+	if snippet := findUsageSnippet(s.tail); snippet != nil {
+		return snippet
+	}
+	return findUsageSnippet(s.head)
+}
+
+// readResponseBodyOrSpool 读取非流式响应体：不超过阈值时返回内存中的 body；
+// 超过阈值时把全部内容写入临时文件并返回 SpooledBody。出错时已创建的临时文件会被删除。
+func readResponseBodyOrSpool(reader io.Reader) ([]byte, *SpooledBody, error) {
+	threshold := ResponseSpoolThresholdBytes()
+	buffered, err := io.ReadAll(io.LimitReader(reader, int64(threshold)+1))
+	if err != nil || len(buffered) <= threshold {
+		return buffered, nil, err
+	}
+
+	file, err := os.CreateTemp("", "clisimplehub-response-*")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create spool file: %w", err)
+	}
+	head := buffered
+	if len(head) > spoolWindowBytes {
+		head = head[:spoolWindowBytes]
+	}
+	spooled := &SpooledBody{file: file, head: append([]byte(nil), head...)}
+
+	tail := &tailBuffer{max: spoolWindowBytes}
+	tail.Write(buffered)
+	if _, err := file.Write(buffered); err != nil {
+		spooled.Close()
+		return nil, nil, fmt.Errorf("failed to write spool file: %w", err)
+	}
+	// 释放内存中的前缀，剩余内容直接拷贝到文件
+	buffered = nil
+	n, err := io.Copy(io.MultiWriter(file, tail), reader)
+	if err != nil {
+		spooled.Close()
+		return nil, nil, err
+	}
+	spooled.size = int64(threshold) + 1 + n
+	spooled.tail = tail.buf
+	return nil, spooled, nil
+}
+
+// tailBuffer 只保留写入内容的最后 max 个字节
+type tailBuffer struct {
+	buf []byte
+	max int
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	if len(p) >= t.max {
+		t.buf = append(t.buf[:0], p[len(p)-t.max:]...)
+		return len(p), nil
+	}
+	t.buf = append(t.buf, p...)
+	if over := len(t.buf) - t.max; over > 0 {
+		t.buf = append(t.buf[:0], t.buf[over:]...)
+	}
+	return len(p), nil
+}
+
+// usageKeys 各协议响应中 token 用量对象的字段名
+var usageKeys = [][]byte{[]byte(`"usageMetadata"`), []byte(`"usage"`)}
+
+// findUsageSnippet 在不完整的 JSON 片段中从后向前查找 usage 对象，返回 {"usage":{...}} 形式的 JSON
+func findUsageSnippet(chunk []byte) []byte {
+	for _, key := range usageKeys {
+		end := len(chunk)
+		for {
+			idx := bytes.LastIndex(chunk[:end], key)
+			if idx < 0 {
+				break
+			}
+			end = idx
+			rest := bytes.TrimLeft(chunk[idx+len(key):], " \t\r\n")
+			if len(rest) == 0 || rest[0] != ':' {
+				continue
+			}
+			rest = bytes.TrimLeft(rest[1:], " \t\r\n")
+			if len(rest) == 0 || rest[0] != '{' {
+				continue
+			}
+			var obj json.RawMessage
+			if err := json.NewDecoder(bytes.NewReader(rest)).Decode(&obj); err != nil {
+				continue
+			}
+			snippet := make([]byte, 0, len(key)+len(obj)+3)
+			snippet = append(snippet, '{')
+			snippet = append(snippet, key...)
+			snippet = append(snippet, ':')
+			snippet = append(snippet, obj...)
+			return append(snippet, '}')
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package executor
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestReadResponseBodyOrSpool(t *testing.T) {
+	SetResponseSpoolThresholdBytes(1024)
+	defer SetResponseSpoolThresholdBytes(0)
+
+	filler := strings.Repeat("x", 200*1024)
+	cases := []struct {
+		name        string
+		body        string
+		wantSpooled bool
+		wantOutput  int64
+	}{
+		{name: "small", body: `{"usage":{"input_tokens":1,"output_tokens":2}}`, wantSpooled: false, wantOutput: 2},
+		{name: "claude", body: `{"content":[{"type":"text","text":"` + filler + `"}],"usage":{"input_tokens":5,"output_tokens":7}}`, wantSpooled: true, wantOutput: 7},
+		{name: "gemini", body: `{"candidates":[{"content":{"parts":[{"text":"` + filler + `"}]}}],"usageMetadata":{"promptTokenCount":3,"candidatesTokenCount":9}}`, wantSpooled: true, wantOutput: 9},
+		{name: "usage in prefix", body: `{"usage":{"prompt_tokens":4,"completion_tokens":6},"data":"` + filler + `"}`, wantSpooled: true, wantOutput: 6},
+	}
+	e := &BaseExecutor{}
+	for _, tc := range cases {
+		body, spooled, err := readResponseBodyOrSpool(strings.NewReader(tc.body))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if (spooled != nil) != tc.wantSpooled {
+			t.Fatalf("%s: spooled=%v want %v", tc.name, spooled != nil, tc.wantSpooled)
+		}
+		if spooled == nil {
+			if string(body) != tc.body {
+				t.Fatalf("%s: body mismatch", tc.name)
+			}
+			continue
+		}
+
+		if spooled.Size() != int64(len(tc.body)) {
+			t.Fatalf("%s: size=%d want %d", tc.name, spooled.Size(), len(tc.body))
+		}
+		var out bytes.Buffer
+		if _, err := spooled.WriteTo(&out); err != nil || out.String() != tc.body {
+			t.Fatalf("%s: spooled body mismatch (err=%v)", tc.name, err)
+		}
+		tokens := e.ExtractTokens(spooled.usageSnippet())
+		if tokens == nil || tokens.OutputTokens != tc.wantOutput {
+			t.Fatalf("%s: tokens=%+v want output %d", tc.name, tokens, tc.wantOutput)
+		}
+
+		name := spooled.file.Name()
+		if err := spooled.Close(); err != nil {
+			t.Fatalf("%s: close: %v", tc.name, err)
+		}
+		if _, err := os.Stat(name); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("%s: spool file not removed: %v", tc.name, err)
+		}
+		if err := spooled.Close(); err != nil {
+			t.Fatalf("%s: second close: %v", tc.name, err)
+		}
+	}
+}
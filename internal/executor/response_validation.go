@@ -0,0 +1,157 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrInvalidResponse 上游返回 200 但响应内容不符合端点的 ResponseValidation 配置
+var ErrInvalidResponse = errors.New("upstream response failed validation")
+
+// maxValidationLookahead 流式校验最多预读的行数，超过后放弃校验直接转发
+const maxValidationLookahead = 64
+
+// validateResponseKeys 检查非流式响应体是否为 JSON 对象且包含全部必需的顶层字段
+func validateResponseKeys(body []byte, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(bytes.TrimSpace(body), &obj); err != nil || obj == nil {
+		return fmt.Errorf("%w: response is not a JSON object", ErrInvalidResponse)
+	}
+	var missing []string
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		if _, ok := obj[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: missing keys %s", ErrInvalidResponse, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// applyResponseValidation 校验 200 响应；失败时清空状态码，使调用方按上游异常处理并故障转移。
+// 响应体保留用于请求日志。
+func applyResponseValidation(result *ForwardResult, endpoint *EndpointConfig) *ForwardResult {
+	if result == nil || endpoint == nil || result.Error != nil || result.StatusCode != http.StatusOK {
+		return result
+	}
+	// 落盘的超大响应体无法整体解析，不做字段校验
+	if result.Spooled != nil {
+		return result
+	}
+	if err := validateResponseKeys(result.Body, endpoint.ResponseValidation); err != nil {
+		result.StatusCode = 0
+		result.Headers = nil
+		result.Error = err
+	}
+	return result
+}
+
+// streamScanner 是 streamLineReader 与 sseEventReader 的公共接口
+type streamScanner interface {
+	Scan() bool
+	Bytes() []byte
+	Err() error
+}
+
+// validatingScanner 是流式响应的轻量校验：在向客户端写出任何内容之前预读到第一个 data 事件，
+// 要求其为 JSON 对象；流在出现任何 data 事件之前就结束同样视为异常。
+// 校验失败时 Scan 直接返回 false、Err 返回 ErrInvalidResponse，此时尚未写出响应，可以故障转移。
+// 不检查字段：流式事件的结构与非流式响应不同。
+type validatingScanner struct {
+	inner   streamScanner
+	pending [][]byte // 预读的行，校验通过后依次输出
+	line    []byte
+	checked bool
+	err     error
+}
+
+// newValidatingScanner 端点未配置 ResponseValidation 时原样返回 inner
+func newValidatingScanner(inner streamScanner, endpoint *EndpointConfig) streamScanner {
+	if endpoint == nil || len(endpoint.ResponseValidation) == 0 {
+		return inner
+	}
+	return &validatingScanner{inner: inner}
+}
+
+func (s *validatingScanner) Scan() bool {
+	if !s.checked {
+		s.checked = true
+		if err := s.lookahead(); err != nil {
+			s.err = err
+			s.pending = nil
+			return false
+		}
+	}
+	if len(s.pending) > 0 {
+		s.line = s.pending[0]
+		s.pending = s.pending[1:]
+		return true
+	}
+	if !s.inner.Scan() {
+		return false
+	}
+	s.line = s.inner.Bytes()
+	return true
+}
+
+// lookahead 预读到第一个非空 data 事件并校验
+func (s *validatingScanner) lookahead() error {
+	for len(s.pending) < maxValidationLookahead {
+		if !s.inner.Scan() {
+			if s.inner.Err() != nil {
+				// 读取错误（含首字节超时取消）交给调用方按原逻辑处理
+				return nil
+			}
+			return fmt.Errorf("%w: stream ended without data events", ErrInvalidResponse)
+		}
+		line := append([]byte(nil), s.inner.Bytes()...)
+		s.pending = append(s.pending, line)
+
+		payload, ok := sseFieldValue(line, "data")
+		if !ok {
+			continue
+		}
+		payload = bytes.TrimSpace(payload)
+		if len(payload) == 0 {
+			continue
+		}
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(payload, &obj); err != nil || obj == nil {
+			return fmt.Errorf("%w: first stream event is not a JSON object", ErrInvalidResponse)
+		}
+		return nil
+	}
+	return nil
+}
+
+func (s *validatingScanner) Bytes() []byte {
+	return s.line
+}
+
+func (s *validatingScanner) Err() error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.inner.Err()
+}
+
+// failInvalidStream 流式校验失败时尚未向客户端写出内容，清空结果以便故障转移
+func failInvalidStream(result *ForwardResult, err error) *ForwardResult {
+	result.StatusCode = 0
+	result.Headers = nil
+	result.Streamed = false
+	result.Error = err
+	return result
+}
@@ -0,0 +1,69 @@
+package executor
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateResponseKeys(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		body    string
+		keys    []string
+		wantErr bool
+	}{
+		{name: "no keys configured", body: "garbage", keys: nil},
+		{name: "all keys present", body: `{"id":"msg_1","content":[]}`, keys: []string{"id", "content"}},
+		{name: "missing key", body: `{"id":"msg_1"}`, keys: []string{"id", "content"}, wantErr: true},
+		{name: "not json", body: "<html>oops</html>", keys: []string{"id"}, wantErr: true},
+		{name: "json array", body: `[{"id":"x"}]`, keys: []string{"id"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		err := validateResponseKeys([]byte(tt.body), tt.keys)
+		if tt.wantErr != (err != nil) {
+			t.Fatalf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidResponse) {
+			t.Fatalf("%s: err = %v, want ErrInvalidResponse", tt.name, err)
+		}
+	}
+}
+
+func TestValidatingScanner(t *testing.T) {
+	t.Parallel()
+
+	endpoint := &EndpointConfig{ResponseValidation: []string{"id"}}
+	tests := []struct {
+		name    string
+		stream  string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "valid stream passes through",
+			stream: "event: message_start\ndata: {\"type\":\"message_start\"}\n\ndata: [DONE]\n",
+			want:   []string{"event: message_start", `data: {"type":"message_start"}`, "", "data: [DONE]"},
+		},
+		{name: "garbage first event", stream: "data: not json\n\n", wantErr: true},
+		{name: "no data events", stream: ": keep-alive\n\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		scanner := newValidatingScanner(newStreamLineReader(strings.NewReader(tt.stream), 0), endpoint)
+		var got []string
+		for scanner.Scan() {
+			got = append(got, string(scanner.Bytes()))
+		}
+		if tt.wantErr {
+			if !errors.Is(scanner.Err(), ErrInvalidResponse) || len(got) != 0 {
+				t.Fatalf("%s: got lines %q err %v, want no lines and ErrInvalidResponse", tt.name, got, scanner.Err())
+			}
+			continue
+		}
+		if scanner.Err() != nil || strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Fatalf("%s: got %q err %v, want %q", tt.name, got, scanner.Err(), tt.want)
+		}
+	}
+}
@@ -3,8 +3,10 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"clisimplehub/internal/logger"
@@ -52,7 +54,7 @@ type ExecuteResult struct {
 // 4. 失败时重试或切换端点
 func (r *RetryExecutor) Execute(ctx context.Context, req *ForwardRequest, w http.ResponseWriter, enableRetry bool) *ExecuteResult {
 	// 1. 检测接口类型
-	interfaceType := r.execCtx.DetectInterfaceType(req.Path)
+	interfaceType := r.execCtx.RequestInterfaceType(req)
 
 	// 2. 查找初始端点
 	endpoint := r.execCtx.GetProvider().GetActiveEndpoint(interfaceType)
@@ -66,25 +68,46 @@ func (r *RetryExecutor) Execute(ctx context.Context, req *ForwardRequest, w http
 		}
 	}
 
+	r.execCtx.Tracef(ctx, "route: interface=%s active endpoint=%s retry=%v", interfaceType, endpointDisplayName(endpoint), enableRetry)
+
 	// 不启用重试时，直接执行一次，不更新断路器（避免隐式故障转移）
 	if !enableRetry {
-		result := r.execCtx.ExecuteWithEndpoint(ctx, endpoint, req, w)
-		return &ExecuteResult{
-			Result:        result,
-			Endpoint:      endpoint,
-			InterfaceType: interfaceType,
-			Attempts:      1,
+		if r.execCtx.overDailyBudget(endpoint) {
+			r.execCtx.Tracef(ctx, "skip: endpoint=%s daily token budget exhausted", endpointDisplayName(endpoint))
+			return &ExecuteResult{
+				Result:        dailyBudgetExhaustedResult(interfaceType),
+				Endpoint:      endpoint,
+				InterfaceType: interfaceType,
+			}
 		}
+		return r.ExecuteOnEndpoint(ctx, endpoint, req, w)
 	}
 
 	// 3. 重试循环
 	return r.executeWithRetry(ctx, req, w, interfaceType, endpoint)
 }
 
+// ExecuteOnEndpoint 使用指定端点执行一次请求，不重试也不更新断路器
+func (r *RetryExecutor) ExecuteOnEndpoint(ctx context.Context, endpoint *EndpointConfig, req *ForwardRequest, w http.ResponseWriter) *ExecuteResult {
+	r.execCtx.Tracef(ctx, "attempt 1: endpoint=%s (single attempt, no failover)", endpointDisplayName(endpoint))
+	start := time.Now()
+	result := r.execCtx.ExecuteWithEndpoint(ctx, endpoint, req, w)
+	r.execCtx.traceResult(ctx, endpoint, result, time.Since(start))
+	return &ExecuteResult{
+		Result:        result,
+		Endpoint:      endpoint,
+		InterfaceType: r.execCtx.RequestInterfaceType(req),
+		Attempts:      1,
+	}
+}
+
 func (r *RetryExecutor) executeWithRetry(ctx context.Context, req *ForwardRequest, w http.ResponseWriter, interfaceType string, endpoint *EndpointConfig) *ExecuteResult {
 	var lastErr error
+	var lastResult *ForwardResult
+	var tried []string
 	tracker := retry.NewTracker(r.config)
 	attempts := 0
+	overBudget := false
 
 	for tracker.CanRetry() {
 		if endpoint == nil {
@@ -95,21 +118,37 @@ func (r *RetryExecutor) executeWithRetry(ctx context.Context, req *ForwardReques
 
 		// 跳过已耗尽的端点
 		if tracker.IsEndpointExhausted(currentKey) {
-			nextEndpoint := r.execCtx.FindNextEndpoint(interfaceType, endpoint, tracker.ExhaustedEndpoints())
+			nextEndpoint := r.findNextUntried(interfaceType, endpoint, tracker)
 			if nextEndpoint == nil {
 				break
 			}
 			// 端点耗尽：通知端点切换（故障转移）
+			r.execCtx.Tracef(ctx, "switch: %s -> %s (endpoint exhausted)", endpointDisplayName(endpoint), endpointDisplayName(nextEndpoint))
 			r.execCtx.NotifySwitch(endpoint, nextEndpoint, req.Path, 0, "endpoint exhausted")
 			endpoint = nextEndpoint
 			currentKey = EndpointKey(endpoint)
 		}
 
+		// 当天 token 预算已用尽：静默跳过，不计入尝试次数
+		if r.execCtx.overDailyBudget(endpoint) {
+			r.execCtx.Tracef(ctx, "skip: endpoint=%s daily token budget exhausted", endpointDisplayName(endpoint))
+			overBudget = true
+			tracker.MarkEndpointExhausted(currentKey)
+			endpoint = r.findNextUntried(interfaceType, endpoint, tracker)
+			continue
+		}
+
+		if tracker.GetAttemptCount(currentKey) == 0 {
+			tried = append(tried, endpointDisplayName(endpoint))
+		}
 		tracker.RecordAttempt(currentKey)
 		attempts++
 
 		// 执行请求
+		r.execCtx.Tracef(ctx, "attempt %d: endpoint=%s (attempt %d on this endpoint)", attempts, endpointDisplayName(endpoint), tracker.GetAttemptCount(currentKey))
+		start := time.Now()
 		result := r.execCtx.ExecuteWithEndpoint(ctx, endpoint, req, w)
+		r.execCtx.traceResult(ctx, endpoint, result, time.Since(start))
 
 		// 流式响应已写入，无法重试
 		if result.Streamed {
@@ -122,6 +161,34 @@ func (r *RetryExecutor) executeWithRetry(ctx context.Context, req *ForwardReques
 			}
 		}
 
+		// 请求上下文已结束（客户端断开或到达全局截止时间）：不再重试，也不计入断路器
+		if result.Error != nil && ctx.Err() != nil {
+			return &ExecuteResult{
+				Result:        result,
+				Endpoint:      endpoint,
+				InterfaceType: interfaceType,
+				Attempts:      attempts,
+				LastError:     result.Error,
+			}
+		}
+
+		// 首字节超时或响应校验失败：未向客户端写入任何内容，临时禁用端点并立即切换
+		if errors.Is(result.Error, ErrFirstByteTimeout) || errors.Is(result.Error, ErrInvalidResponse) {
+			lastErr = result.Error
+			lastResult = result
+			logger.Warn("[Executor] unhealthy upstream response: interface=%s endpoint=%s path=%s error=%v", endpoint.InterfaceType, endpoint.Name, req.Path, result.Error)
+			r.execCtx.DisableEndpoint(endpoint.InterfaceType, endpoint)
+			tracker.MarkEndpointExhausted(currentKey)
+			nextEndpoint := r.findNextUntried(interfaceType, endpoint, tracker)
+			if nextEndpoint == nil {
+				break
+			}
+			r.execCtx.Tracef(ctx, "switch: %s -> %s (%v)", endpointDisplayName(endpoint), endpointDisplayName(nextEndpoint), result.Error)
+			r.execCtx.NotifySwitch(endpoint, nextEndpoint, req.Path, 0, result.Error.Error())
+			endpoint = nextEndpoint
+			continue
+		}
+
 		// 成功
 		if result.Error == nil && result.StatusCode == http.StatusOK {
 			r.circuitBreaker.RecordSuccess(currentKey)
@@ -134,11 +201,13 @@ func (r *RetryExecutor) executeWithRetry(ctx context.Context, req *ForwardReques
 		}
 
 		lastErr = result.Error
+		lastResult = result
 		disabledUntil := r.updateCircuitBreaker(endpoint, req.Path, result)
 		if !disabledUntil.IsZero() {
 			// 端点被断路器临时禁用：将其标记为耗尽并静默切换到下一个端点（保持与旧 proxy 行为一致）
 			tracker.MarkEndpointExhausted(currentKey)
-			endpoint = r.execCtx.FindNextEndpoint(interfaceType, endpoint, tracker.ExhaustedEndpoints())
+			r.execCtx.Tracef(ctx, "circuit breaker: endpoint=%s disabled until %s", endpointDisplayName(endpoint), disabledUntil.Format(time.RFC3339))
+			endpoint = r.findNextUntried(interfaceType, endpoint, tracker)
 			continue
 		}
 
@@ -159,7 +228,7 @@ func (r *RetryExecutor) executeWithRetry(ctx context.Context, req *ForwardReques
 			tracker.MarkEndpointExhausted(currentKey)
 
 			// 查找下一个端点
-			nextEndpoint := r.execCtx.FindNextEndpoint(interfaceType, endpoint, tracker.ExhaustedEndpoints())
+			nextEndpoint := r.findNextUntried(interfaceType, endpoint, tracker)
 			if nextEndpoint == nil {
 				break
 			}
@@ -171,16 +240,31 @@ func (r *RetryExecutor) executeWithRetry(ctx context.Context, req *ForwardReques
 			} else if result.StatusCode > 0 {
 				errMsg = fmt.Sprintf("HTTP %d", result.StatusCode)
 			}
+			r.execCtx.Tracef(ctx, "switch: %s -> %s (%s)", endpointDisplayName(endpoint), endpointDisplayName(nextEndpoint), errMsg)
 			r.execCtx.NotifySwitch(endpoint, nextEndpoint, req.Path, result.StatusCode, errMsg)
 			endpoint = nextEndpoint
 		}
 	}
 
+	// 没有实际发出请求且有端点因预算被跳过：返回 429 而不是 503
+	if attempts == 0 && overBudget {
+		return &ExecuteResult{
+			Result:        dailyBudgetExhaustedResult(interfaceType),
+			InterfaceType: interfaceType,
+		}
+	}
+
 	// 所有重试耗尽
+	reason := "no attempt made"
+	if lastErr != nil {
+		reason = lastErr.Error()
+	} else if lastResult != nil && lastResult.StatusCode > 0 {
+		reason = fmt.Sprintf("HTTP %d", lastResult.StatusCode)
+	}
 	return &ExecuteResult{
 		Result: &ForwardResult{
 			StatusCode: http.StatusServiceUnavailable,
-			Error:      fmt.Errorf("all endpoints failed: %v", lastErr),
+			Error:      fmt.Errorf("all endpoints failed (tried: %s): %s", strings.Join(tried, ", "), reason),
 		},
 		Endpoint:      endpoint,
 		InterfaceType: interfaceType,
@@ -189,6 +273,27 @@ func (r *RetryExecutor) executeWithRetry(ctx context.Context, req *ForwardReques
 	}
 }
 
+// findNextUntried 查找本次请求尚未尝试过的下一个端点。
+// 端点选择状态可能在请求过程中变化，因此对返回结果再校验一次，确保不会重复尝试同一端点。
+func (r *RetryExecutor) findNextUntried(interfaceType string, current *EndpointConfig, tracker *retry.Tracker) *EndpointConfig {
+	attempted := tracker.AttemptedEndpoints()
+	next := r.execCtx.FindNextEndpoint(interfaceType, current, attempted)
+	if next == nil || attempted[EndpointKey(next)] {
+		return nil
+	}
+	return next
+}
+
+func endpointDisplayName(ep *EndpointConfig) string {
+	if ep == nil {
+		return ""
+	}
+	if name := strings.TrimSpace(ep.Name); name != "" {
+		return name
+	}
+	return EndpointKey(ep)
+}
+
 func (r *RetryExecutor) updateCircuitBreaker(endpoint *EndpointConfig, path string, result *ForwardResult) time.Time {
 	if endpoint == nil {
 		return time.Time{}
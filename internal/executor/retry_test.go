@@ -0,0 +1,250 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"clisimplehub/internal/retry"
+)
+
+// staleProvider 模拟选择状态在请求过程中变化：FindNextUntried 忽略耗尽集合，总是轮转到下一个端点
+type staleProvider struct {
+	endpoints []*EndpointConfig
+}
+
+func (p *staleProvider) DetectInterfaceType(string) string { return "claude" }
+func (p *staleProvider) GetActiveEndpoint(string) *EndpointConfig {
+	return p.endpoints[0]
+}
+func (p *staleProvider) GetEndpointsByType(string) []*EndpointConfig { return p.endpoints }
+func (p *staleProvider) GetNextEndpoint(string, *EndpointConfig) *EndpointConfig {
+	return nil
+}
+func (p *staleProvider) FindNextUntried(_ string, current *EndpointConfig, _ map[string]bool) *EndpointConfig {
+	for i, ep := range p.endpoints {
+		if ep.ID == current.ID {
+			return p.endpoints[(i+1)%len(p.endpoints)]
+		}
+	}
+	return nil
+}
+func (p *staleProvider) DisableEndpoint(string, *EndpointConfig) time.Time { return time.Time{} }
+func (p *staleProvider) SetActiveEndpoint(string, *EndpointConfig) error   { return nil }
+
+func TestRetryExecutor_NeverRevisitsTriedEndpoint(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	hits := map[string]int{}
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.URL.Path]++
+		mu.Unlock()
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer upstream.Close()
+
+	provider := &staleProvider{endpoints: []*EndpointConfig{
+		{ID: 1, Name: "a", APIURL: upstream.URL + "/a", InterfaceType: "claude"},
+		{ID: 2, Name: "b", APIURL: upstream.URL + "/b", InterfaceType: "claude"},
+	}}
+	exec := NewRetryExecutor(NewExecutionContext(provider), retry.Config{
+		MaxRetriesPerEndpoint:   1,
+		MaxTotalRetries:         10,
+		CircuitBreakerThreshold: 100,
+	})
+
+	req := &ForwardRequest{Method: http.MethodPost, Path: "/v1/messages", Headers: http.Header{}, Body: []byte(`{"model":"m"}`)}
+	res := exec.Execute(context.Background(), req, httptest.NewRecorder(), true)
+
+	if res.Attempts != 2 {
+		t.Fatalf("Attempts=%d want 2", res.Attempts)
+	}
+	for _, path := range []string{"/a/v1/messages", "/b/v1/messages"} {
+		if hits[path] != 1 {
+			t.Fatalf("hits[%s]=%d want 1 (hits=%v)", path, hits[path], hits)
+		}
+	}
+	if res.Result == nil || res.Result.Error == nil || !strings.Contains(res.Result.Error.Error(), "tried: a, b") {
+		t.Fatalf("final error=%v want tried endpoints listed", res.Result.Error)
+	}
+}
+
+func TestRetryExecutor_FirstByteTimeoutFailsOver(t *testing.T) {
+	// 修改全局首字节超时，不能并行
+	SetStreamFirstByteTimeout(100 * time.Millisecond)
+	t.Cleanup(func() { SetStreamFirstByteTimeout(0) })
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("X-Upstream", r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		if strings.HasPrefix(r.URL.Path, "/slow") {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		_, _ = w.Write([]byte("data: ok\n\n"))
+	}))
+	defer upstream.Close()
+
+	provider := &staleProvider{endpoints: []*EndpointConfig{
+		{ID: 1, Name: "slow", APIURL: upstream.URL + "/slow", InterfaceType: "claude"},
+		{ID: 2, Name: "fast", APIURL: upstream.URL + "/fast", InterfaceType: "claude"},
+	}}
+	exec := NewRetryExecutor(NewExecutionContext(provider), retry.Config{
+		MaxRetriesPerEndpoint:   3,
+		MaxTotalRetries:         10,
+		CircuitBreakerThreshold: 100,
+	})
+
+	rec := httptest.NewRecorder()
+	req := &ForwardRequest{Method: http.MethodPost, Path: "/v1/messages", Headers: http.Header{}, Body: []byte(`{"model":"m","stream":true}`), IsStreaming: true}
+	res := exec.Execute(context.Background(), req, rec, true)
+
+	if res.Endpoint == nil || res.Endpoint.Name != "fast" || res.Attempts != 2 {
+		t.Fatalf("endpoint=%v attempts=%d want fast after 2 attempts", res.Endpoint, res.Attempts)
+	}
+	if res.Result.Error != nil || !res.Result.Streamed {
+		t.Fatalf("result error=%v streamed=%v want streamed success", res.Result.Error, res.Result.Streamed)
+	}
+	if got := rec.Header().Get("X-Upstream"); got != "/fast/v1/messages" {
+		t.Fatalf("X-Upstream=%q want only the fast endpoint's headers", got)
+	}
+	if !strings.Contains(rec.Body.String(), "data: ok") {
+		t.Fatalf("body=%q want fast endpoint stream", rec.Body.String())
+	}
+}
+
+// budgetProvider 在 staleProvider 基础上提供今天的 token 用量
+type budgetProvider struct {
+	staleProvider
+	usage map[int64]int64
+}
+
+func (p *budgetProvider) TodayTokenUsage() map[int64]int64 { return p.usage }
+
+func TestRetryExecutor_DailyTokenBudget(t *testing.T) {
+	t.Parallel()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"path":"` + r.URL.Path + `"}`))
+	}))
+	defer upstream.Close()
+
+	endpoints := []*EndpointConfig{
+		{ID: 1, Name: "a", APIURL: upstream.URL + "/a", InterfaceType: "claude", DailyTokenBudget: 1000},
+		{ID: 2, Name: "b", APIURL: upstream.URL + "/b", InterfaceType: "claude", DailyTokenBudget: 1000},
+	}
+	cases := []struct {
+		name       string
+		usage      map[int64]int64
+		retry      bool
+		wantStatus int
+		wantPath   string
+	}{
+		{name: "under budget", usage: map[int64]int64{1: 999}, retry: true, wantStatus: http.StatusOK, wantPath: "/a/v1/messages"},
+		{name: "fails over", usage: map[int64]int64{1: 1000}, retry: true, wantStatus: http.StatusOK, wantPath: "/b/v1/messages"},
+		{name: "all exhausted", usage: map[int64]int64{1: 1000, 2: 5000}, retry: true, wantStatus: http.StatusTooManyRequests},
+		{name: "no retry", usage: map[int64]int64{1: 1000}, wantStatus: http.StatusTooManyRequests},
+		{name: "stats unavailable", retry: true, wantStatus: http.StatusOK, wantPath: "/a/v1/messages"},
+	}
+	for _, tc := range cases {
+		provider := &budgetProvider{staleProvider: staleProvider{endpoints: endpoints}, usage: tc.usage}
+		exec := NewRetryExecutor(NewExecutionContext(provider), retry.Config{
+			MaxRetriesPerEndpoint:   1,
+			MaxTotalRetries:         10,
+			CircuitBreakerThreshold: 100,
+		})
+
+		req := &ForwardRequest{Method: http.MethodPost, Path: "/v1/messages", Headers: http.Header{}, Body: []byte(`{"model":"m"}`)}
+		res := exec.Execute(context.Background(), req, httptest.NewRecorder(), tc.retry)
+		if res.Result == nil || res.Result.StatusCode != tc.wantStatus {
+			t.Fatalf("%s: result=%+v want status %d", tc.name, res.Result, tc.wantStatus)
+		}
+		if tc.wantStatus == http.StatusTooManyRequests {
+			if !errors.Is(res.Result.Error, ErrDailyBudgetExhausted) {
+				t.Fatalf("%s: error=%v want ErrDailyBudgetExhausted", tc.name, res.Result.Error)
+			}
+			continue
+		}
+		if !strings.Contains(string(res.Result.Body), tc.wantPath) {
+			t.Fatalf("%s: body=%s want path %s", tc.name, res.Result.Body, tc.wantPath)
+		}
+	}
+}
+
+// traceObserver 收集调试日志，用于验证按需追踪
+type traceObserver struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (o *traceObserver) OnRequestStart(string, string, *EndpointConfig, string) {}
+func (o *traceObserver) OnRequestComplete(string, string, *EndpointConfig, *ForwardResult, time.Duration) {
+}
+func (o *traceObserver) OnEndpointSwitch(*EndpointConfig, *EndpointConfig, string, int, string) {}
+func (o *traceObserver) OnEndpointDisabled(string, *EndpointConfig, time.Time)                  {}
+func (o *traceObserver) OnDebugLog(_ string, _ int, message string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.messages = append(o.messages, message)
+}
+
+func TestRetryExecutor_TraceOnlyWhenEnabled(t *testing.T) {
+	t.Parallel()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/a") {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer upstream.Close()
+
+	for _, traced := range []bool{false, true} {
+		provider := &staleProvider{endpoints: []*EndpointConfig{
+			{ID: 1, Name: "a", APIURL: upstream.URL + "/a", InterfaceType: "claude"},
+			{ID: 2, Name: "b", APIURL: upstream.URL + "/b", InterfaceType: "claude"},
+		}}
+		observer := &traceObserver{}
+		execCtx := NewExecutionContext(provider)
+		execCtx.SetObserver(observer)
+		exec := NewRetryExecutor(execCtx, retry.Config{
+			MaxRetriesPerEndpoint:   1,
+			MaxTotalRetries:         10,
+			CircuitBreakerThreshold: 100,
+		})
+
+		ctx := context.Background()
+		if traced {
+			ctx = WithTrace(ctx)
+		}
+		req := &ForwardRequest{Method: http.MethodPost, Path: "/v1/messages", Headers: http.Header{}, Body: []byte(`{"model":"m"}`)}
+		exec.Execute(ctx, req, httptest.NewRecorder(), true)
+
+		joined := strings.Join(observer.messages, "\n")
+		if !traced {
+			if strings.Contains(joined, "[Trace]") {
+				t.Fatalf("untraced request emitted trace steps: %s", joined)
+			}
+			continue
+		}
+		for _, want := range []string{"attempt 1: endpoint=a", "switch: a -> b", "upstream=\"m\"", "status=200"} {
+			if !strings.Contains(joined, want) {
+				t.Fatalf("trace missing %q:\n%s", want, joined)
+			}
+		}
+	}
+}
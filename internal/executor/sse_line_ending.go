@@ -0,0 +1,22 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+
+	"clisimplehub/internal/transformer/shared"
+)
+
+// SetSSELineEnding 设置 transformer 与合成流输出的 SSE 行结束符：lf（默认）或 crlf。
+// 透传的上游流保持原样。无效值返回错误且不修改当前设置。
+func SetSSELineEnding(mode string) error {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", shared.SSELineEndingLF:
+		shared.SetSSELineEnding(shared.SSELineEndingLF)
+	case shared.SSELineEndingCRLF:
+		shared.SetSSELineEnding(shared.SSELineEndingCRLF)
+	default:
+		return fmt.Errorf("invalid sseLineEnding %q (want lf or crlf)", mode)
+	}
+	return nil
+}
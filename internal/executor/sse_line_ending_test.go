@@ -0,0 +1,65 @@
+package executor
+
+import (
+	"strings"
+	"testing"
+
+	"clisimplehub/internal/transformer/shared"
+)
+
+// 修改全局 SSE 行结束符，不能与其他测试并行
+func TestSetSSELineEnding(t *testing.T) {
+	t.Cleanup(func() { _ = SetSSELineEnding("") })
+
+	cases := []struct {
+		mode    string
+		wantErr bool
+		wantEOL string
+	}{
+		{mode: "crlf", wantEOL: "\r\n"},
+		{mode: " LF ", wantEOL: "\n"},
+		{mode: "CRLF", wantEOL: "\r\n"},
+		{mode: "cr", wantErr: true, wantEOL: "\r\n"}, // 无效值不修改当前设置
+		{mode: "", wantEOL: "\n"},
+	}
+	for _, tc := range cases {
+		err := SetSSELineEnding(tc.mode)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("%q: err=%v wantErr=%v", tc.mode, err, tc.wantErr)
+		}
+		if got := shared.SSELineEnding(); got != tc.wantEOL {
+			t.Fatalf("%q: line ending=%q want %q", tc.mode, got, tc.wantEOL)
+		}
+	}
+}
+
+// 修改全局 SSE 行结束符，不能与其他测试并行
+func TestSynthesizeStreamCRLF(t *testing.T) {
+	if err := SetSSELineEnding("crlf"); err != nil {
+		t.Fatalf("SetSSELineEnding: %v", err)
+	}
+	t.Cleanup(func() { _ = SetSSELineEnding("") })
+
+	cases := []struct {
+		interfaceType string
+		body          string
+	}{
+		{interfaceType: "claude", body: `{"id":"msg_1","type":"message","role":"assistant","model":"m","content":[{"type":"text","text":"hi"}],"stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":1}}`},
+		{interfaceType: "chat", body: `{"id":"c1","object":"chat.completion","model":"m","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}]}`},
+	}
+	for _, tc := range cases {
+		out, _, err := synthesizeStream(tc.interfaceType, []byte(tc.body), true)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.interfaceType, err)
+		}
+		stream := string(out)
+		// 每一行都以 CRLF 结尾，事件之间恰好一个空行
+		if strings.Contains(strings.ReplaceAll(stream, "\r\n", ""), "\n") || !strings.HasSuffix(stream, "\r\n\r\n") || strings.Contains(stream, "\r\n\r\n\r\n") {
+			t.Fatalf("%s: stream not framed with crlf: %q", tc.interfaceType, stream)
+		}
+	}
+
+	if got, want := shared.SSEEvent("ping", map[string]string{"type": "ping"}), "event: ping\r\ndata: {\"type\":\"ping\"}\r\n\r\n"; got != want {
+		t.Fatalf("SSEEvent=%q want %q", got, want)
+	}
+}
@@ -0,0 +1,125 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// sseEventReader 在 streamLineReader 之上按 SSE 语义重组 data 字段，接口与 bufio.Scanner 一致。
+// 上游偶尔会把一个事件的 data 拆成多行（多个 data: 行，或 JSON 中间被换行截断），
+// 逐行交给 transformer 会解析失败并丢失事件。这里把不完整的 data 暂存，
+// 直到拼成完整 JSON、遇到空行/其他字段或流结束，再作为单行 "data: ..." 输出。
+// 完整的 data 行立即输出，不会增加流式延迟。
+type sseEventReader struct {
+	lines   *streamLineReader
+	pending []byte // 尚不完整的 data 内容
+	queue   [][]byte
+	line    []byte
+
+	onRawLine func() // 每读到一行原始数据时回调（如解除首字节超时）
+}
+
+func newSSEEventReader(lines *streamLineReader) *sseEventReader {
+	return &sseEventReader{lines: lines}
+}
+
+// Scan 读取下一行（data 已重组），返回 false 表示读取结束或出错
+func (s *sseEventReader) Scan() bool {
+	for len(s.queue) == 0 {
+		if !s.lines.Scan() {
+			if s.pending == nil {
+				return false
+			}
+			s.flushPending()
+			break
+		}
+		if s.onRawLine != nil {
+			s.onRawLine()
+		}
+		s.push(s.lines.Bytes())
+	}
+	s.line = s.queue[0]
+	s.queue = s.queue[1:]
+	return true
+}
+
+// Bytes 返回当前行（不含换行符）
+func (s *sseEventReader) Bytes() []byte {
+	return s.line
+}
+
+// Err 返回非 EOF 的读取错误
+func (s *sseEventReader) Err() error {
+	return s.lines.Err()
+}
+
+func (s *sseEventReader) push(raw []byte) {
+	line := append([]byte(nil), raw...)
+
+	if payload, ok := sseFieldValue(line, "data"); ok {
+		if s.pending == nil {
+			s.pending = payload
+		} else {
+			s.pending = append(append(s.pending, '\n'), payload...)
+		}
+		s.flushIfComplete()
+		return
+	}
+
+	if s.pending != nil {
+		// JSON 被裸换行截断：不像 SSE 字段的非空行视为上一行 data 的续行
+		if len(bytes.TrimSpace(line)) > 0 && !isSSEFieldLine(line) {
+			s.pending = append(s.pending, line...)
+			s.flushIfComplete()
+			return
+		}
+		s.flushPending()
+	}
+	s.queue = append(s.queue, line)
+}
+
+func (s *sseEventReader) flushIfComplete() {
+	trimmed := bytes.TrimSpace(s.pending)
+	if bytes.Equal(trimmed, []byte("[DONE]")) || json.Valid(trimmed) {
+		s.flushPending()
+	}
+}
+
+func (s *sseEventReader) flushPending() {
+	if s.pending == nil {
+		return
+	}
+	s.queue = append(s.queue, append([]byte("data: "), s.pending...))
+	s.pending = nil
+}
+
+// sseFieldValue 返回 SSE 字段的值（去掉冒号后的一个空格）
+func sseFieldValue(line []byte, field string) ([]byte, bool) {
+	if !bytes.HasPrefix(line, []byte(field)) {
+		return nil, false
+	}
+	rest := line[len(field):]
+	if len(rest) == 0 {
+		return []byte{}, true
+	}
+	if rest[0] != ':' {
+		return nil, false
+	}
+	rest = rest[1:]
+	if len(rest) > 0 && rest[0] == ' ' {
+		rest = rest[1:]
+	}
+	return rest, true
+}
+
+func isSSEFieldLine(line []byte) bool {
+	if len(line) > 0 && line[0] == ':' {
+		return true // 注释
+	}
+	for _, field := range []string{"event", "id", "retry"} {
+		if _, ok := sseFieldValue(line, field); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"clisimplehub/internal/usage"
+)
+
+// sseUsageAccumulator 按 SSE 事件（event: 与一个或多个 data: 行，空行结束）重组数据后提取 token 使用量。
+// Anthropic 的输入 token 在 message_start 中、输出 token 在 message_delta 中，
+// 各事件的用量按字段取最大值累计，避免后一个事件覆盖前一个。
+// 只用于统计，转发给客户端的字节不受影响。
+type sseUsageAccumulator struct {
+	data  [][]byte // 当前事件的 data 行
+	stats usage.TokenStats
+}
+
+// feed 处理一行原始 SSE 数据
+func (a *sseUsageAccumulator) feed(line []byte) {
+	line = bytes.TrimRight(line, "\r")
+	if len(bytes.TrimSpace(line)) == 0 {
+		a.dispatch()
+		return
+	}
+	if payload, ok := sseFieldValue(line, "data"); ok {
+		// 上游省略事件间空行时，已完整的 data 先单独解析
+		if len(a.data) > 0 && json.Valid(bytes.TrimSpace(bytes.Join(a.data, []byte("\n")))) {
+			a.dispatch()
+		}
+		a.data = append(a.data, append([]byte(nil), payload...))
+		return
+	}
+	if _, ok := sseFieldValue(line, "event"); ok {
+		// event: 开始新事件，未以空行结束的上一个事件在此结束
+		a.dispatch()
+	}
+}
+
+// dispatch 解析当前事件的 data 并累计用量
+func (a *sseUsageAccumulator) dispatch() {
+	if len(a.data) == 0 {
+		return
+	}
+	payload := bytes.TrimSpace(bytes.Join(a.data, []byte("\n")))
+	a.data = a.data[:0]
+	if len(payload) == 0 || bytes.Equal(payload, []byte("[DONE]")) {
+		return
+	}
+	a.stats.Merge(usage.ExtractFromResponse(payload))
+}
+
+// tokens 结束未完成的事件并返回累计用量，没有用量时返回 nil
+func (a *sseUsageAccumulator) tokens() *TokenUsage {
+	a.dispatch()
+	if a.stats.IsEmpty() {
+		return nil
+	}
+	return &TokenUsage{
+		InputTokens:  a.stats.InputTokens,
+		OutputTokens: a.stats.OutputTokens,
+		CachedCreate: a.stats.CachedCreate,
+		CachedRead:   a.stats.CachedRead,
+		Reasoning:    a.stats.Reasoning,
+	}
+}
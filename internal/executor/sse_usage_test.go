@@ -0,0 +1,59 @@
+package executor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSSEUsageAccumulator(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		stream string
+		want   *TokenUsage
+	}{
+		{
+			name: "anthropic message_start and message_delta",
+			stream: "event: message_start\n" +
+				`data: {"type":"message_start","message":{"usage":{"input_tokens":25,"cache_read_input_tokens":10,"output_tokens":1}}}` + "\n\n" +
+				"event: content_block_delta\n" +
+				`data: {"type":"content_block_delta","delta":{"type":"text_delta","text":"hi"}}` + "\n\n" +
+				"event: message_delta\n" +
+				`data: {"type":"message_delta","usage":{"output_tokens":42}}` + "\n\n" +
+				"event: message_stop\n" +
+				`data: {"type":"message_stop"}` + "\n\n",
+			want: &TokenUsage{InputTokens: 25, OutputTokens: 42, CachedRead: 10},
+		},
+		{
+			name: "data split across lines",
+			stream: "event: message_delta\n" +
+				`data: {"type":"message_delta",` + "\n" +
+				`data: "usage":{"output_tokens":7}}` + "\n\n",
+			want: &TokenUsage{OutputTokens: 7},
+		},
+		{
+			name: "missing blank lines and CRLF",
+			stream: `data: {"usage":{"prompt_tokens":3}}` + "\r\n" +
+				`data: {"usage":{"completion_tokens":4}}` + "\r\n" +
+				"data: [DONE]\r\n",
+			want: &TokenUsage{InputTokens: 3, OutputTokens: 4},
+		},
+		{
+			name:   "no usage",
+			stream: "event: ping\ndata: {\"type\":\"ping\"}\n\n",
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		var acc sseUsageAccumulator
+		for _, line := range strings.Split(tt.stream, "\n") {
+			acc.feed([]byte(line))
+		}
+		got := acc.tokens()
+		if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+			t.Fatalf("%s: got %+v want %+v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package executor
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+
+	"clisimplehub/internal/logger"
+	"clisimplehub/internal/transformer/shared"
+)
+
+// DefaultMaxStreamLineBytes 默认单行 SSE 的最大字节数。超过读缓冲区的行会完整拼接读取，
+// 该值只用于防止单行无限占用内存，因此远大于早期 bufio.Scanner 的 1MB 上限
+const DefaultMaxStreamLineBytes = 64 * 1024 * 1024
+
+const streamReaderBufferSize = 64 * 1024
+
+var maxStreamLineBytes int64 = DefaultMaxStreamLineBytes
+
+// ErrStreamLineTooLong 表示上游单行 SSE 超过 maxStreamLineBytes，流以该错误结束，
+// 并向客户端补发一个协议格式的错误事件（见 writeStreamLineTooLongEvent）
+var ErrStreamLineTooLong = errors.New("stream line exceeds maxStreamLineBytes")
+
+// SetMaxStreamLineBytes 设置单行 SSE 的最大字节数，n <= 0 时恢复默认值
+func SetMaxStreamLineBytes(n int) {
+	if n <= 0 {
+		n = DefaultMaxStreamLineBytes
+	}
+	atomic.StoreInt64(&maxStreamLineBytes, int64(n))
+}
+
+// MaxStreamLineBytes 返回单行 SSE 的最大字节数
+func MaxStreamLineBytes() int {
+	return int(atomic.LoadInt64(&maxStreamLineBytes))
+}
+
+// streamLineReader 按行读取流式响应，接口与 bufio.Scanner 一致。
+// 超过读缓冲区的行会拼接读取，不在缓冲区边界切分，保证超大事件（如大段
+// tool-call 参数或 base64 图片）完整转发；超过 maxLine 的行不再继续缓存，
+// 以 ErrStreamLineTooLong 结束读取，避免单行无限占用内存。
+type streamLineReader struct {
+	r       *bufio.Reader
+	maxLine int
+	line    []byte
+	buf     []byte
+	err     error
+}
+
+func newStreamLineReader(r io.Reader, maxLine int) *streamLineReader {
+	if maxLine <= 0 {
+		maxLine = DefaultMaxStreamLineBytes
+	}
+	size := streamReaderBufferSize
+	if maxLine < size {
+		size = maxLine
+	}
+	return &streamLineReader{r: bufio.NewReaderSize(r, size), maxLine: maxLine}
+}
+
+// Scan 读取下一行，返回 false 表示读取结束或出错
+func (s *streamLineReader) Scan() bool {
+	if s.err != nil {
+		return false
+	}
+
+	s.buf = s.buf[:0]
+	for {
+		chunk, err := s.r.ReadSlice('\n')
+		s.buf = append(s.buf, chunk...)
+		if err == bufio.ErrBufferFull {
+			if len(s.buf) > s.maxLine {
+				return s.failTooLong()
+			}
+			continue
+		}
+		if err != nil {
+			s.err = err
+			if len(s.buf) == 0 {
+				return false
+			}
+		}
+		break
+	}
+
+	s.line = dropLineEnding(s.buf)
+	if len(s.line) > s.maxLine {
+		return s.failTooLong()
+	}
+	return true
+}
+
+// failTooLong 以 ErrStreamLineTooLong 结束读取
+func (s *streamLineReader) failTooLong() bool {
+	logger.Warn("[Executor] stream line of more than %d bytes exceeds maxStreamLineBytes, stream aborted with an error event", s.maxLine)
+	s.err = fmt.Errorf("%w (%d bytes)", ErrStreamLineTooLong, s.maxLine)
+	s.line = nil
+	return false
+}
+
+// Bytes 返回当前行（不含换行符），下次 Scan 后失效
+func (s *streamLineReader) Bytes() []byte {
+	return s.line
+}
+
+// Err 返回非 EOF 的读取错误
+func (s *streamLineReader) Err() error {
+	if s.err == io.EOF {
+		return nil
+	}
+	return s.err
+}
+
+func dropLineEnding(line []byte) []byte {
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	return bytes.TrimSuffix(line, []byte("\r"))
+}
+
+// streamErrorEvent 返回客户端协议格式的流式错误事件，用于流已开始后无法再返回错误状态码的情况
+func streamErrorEvent(interfaceType string, message string) []byte {
+	streamErr := &shared.StreamError{Type: "api_error", Message: message}
+	w := &sseWriter{}
+	switch interfaceType {
+	case "codex":
+		w.event("error", map[string]any{"type": "error", "code": "server_error", "message": message, "param": nil})
+	case "chat":
+		w.buf.WriteString(shared.SSEFrame("data: " + string(streamErr.OpenAIErrorResponse(http.StatusBadGateway))))
+	case "gemini":
+		w.data(map[string]any{"error": map[string]any{"code": http.StatusBadGateway, "message": message, "status": "INTERNAL"}})
+	default:
+		w.buf.WriteString(streamErr.ClaudeErrorEvent())
+	}
+	return w.buf.Bytes()
+}
+
+// writeStreamLineTooLongEvent 在流因单行超过 maxStreamLineBytes 中止时向客户端补发错误事件，
+// 让客户端收到明确的错误而不是被截断的响应
+func writeStreamLineTooLongEvent(w http.ResponseWriter, flusher http.Flusher, interfaceType string, err error) {
+	if !errors.Is(err, ErrStreamLineTooLong) {
+		return
+	}
+	_, _ = w.Write(streamErrorEvent(interfaceType, err.Error()))
+	flusher.Flush()
+}
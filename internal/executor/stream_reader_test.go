@@ -0,0 +1,177 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStreamLineReader_LineLongerThanBuffer(t *testing.T) {
+	t.Parallel()
+
+	big := "data: " + strings.Repeat("x", 3*streamReaderBufferSize)
+	input := "event: a\r\n" + big + "\n\ndata: [DONE]"
+
+	r := newStreamLineReader(strings.NewReader(input), DefaultMaxStreamLineBytes)
+	var lines []string
+	for r.Scan() {
+		lines = append(lines, string(r.Bytes()))
+	}
+	if err := r.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"event: a", big, "", "data: [DONE]"}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d: %q", len(lines), len(want), lines)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Fatalf("line %d = %q, want %q", i, lines[i], want[i])
+		}
+	}
+}
+
+func TestStreamLineReader_LineOverOldDefault(t *testing.T) {
+	t.Parallel()
+
+	// 早期 bufio.Scanner 的上限是 1MB，刚超过该值的事件必须完整读出
+	big := "data: " + strings.Repeat("x", 1024*1024)
+	r := newStreamLineReader(strings.NewReader(big+"\n\ndata: [DONE]\n"), DefaultMaxStreamLineBytes)
+	if !r.Scan() || string(r.Bytes()) != big {
+		t.Fatalf("oversized line not read whole: got %d bytes want %d", len(r.Bytes()), len(big))
+	}
+	for r.Scan() {
+	}
+	if err := r.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestStreamLineReader_LineOverLimit(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name  string
+		input string
+	}{
+		{name: "terminated line", input: "data: a\n" + "data: " + strings.Repeat("x", 100) + "\ndata: b\n"},
+		{name: "line at eof", input: "data: a\n" + "data: " + strings.Repeat("x", 100)},
+		{name: "line spanning buffers", input: "data: a\n" + "data: " + strings.Repeat("x", 1000) + "\n"},
+	}
+	for _, tc := range cases {
+		r := newStreamLineReader(strings.NewReader(tc.input), 64)
+		var lines []string
+		for r.Scan() {
+			lines = append(lines, string(r.Bytes()))
+		}
+		if len(lines) != 1 || lines[0] != "data: a" {
+			t.Fatalf("%s: lines=%q want only the line before the oversized one", tc.name, lines)
+		}
+		if err := r.Err(); !errors.Is(err, ErrStreamLineTooLong) {
+			t.Fatalf("%s: err=%v want ErrStreamLineTooLong", tc.name, err)
+		}
+		if r.Scan() {
+			t.Fatalf("%s: Scan after failure returned true", tc.name)
+		}
+	}
+}
+
+func TestSSEEventReader_ReassemblesSplitData(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "complete lines pass through",
+			input: "event: a\ndata: {\"x\":1}\n\ndata: [DONE]\n",
+			want:  []string{"event: a", `data: {"x":1}`, "", "data: [DONE]"},
+		},
+		{
+			name:  "multiple data lines",
+			input: "data: {\"x\":\ndata: 1}\n\n",
+			want:  []string{"data: {\"x\":\n1}", ""},
+		},
+		{
+			name:  "json broken by bare newline",
+			input: "data: {\"text\":\"hel\nlo\"}\n\n",
+			want:  []string{`data: {"text":"hello"}`, ""},
+		},
+		{
+			name:  "incomplete data flushed by blank line",
+			input: "data: {\"x\"\n\ndata: {}\n",
+			want:  []string{`data: {"x"`, "", "data: {}"},
+		},
+		{
+			name:  "incomplete data flushed at eof",
+			input: "data: {\"x\"",
+			want:  []string{`data: {"x"`},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := newSSEEventReader(newStreamLineReader(strings.NewReader(tc.input), 64))
+			var lines []string
+			for r.Scan() {
+				lines = append(lines, string(r.Bytes()))
+			}
+			if err := r.Err(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(lines) != len(tc.want) {
+				t.Fatalf("got %q, want %q", lines, tc.want)
+			}
+			for i := range tc.want {
+				if lines[i] != tc.want[i] {
+					t.Fatalf("line %d = %q, want %q", i, lines[i], tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestStreamLineTooLongSendsErrorEvent(t *testing.T) {
+	// 修改全局 maxStreamLineBytes，不能与其他测试并行
+	SetMaxStreamLineBytes(64)
+	t.Cleanup(func() { SetMaxStreamLineBytes(0) })
+
+	cases := []struct {
+		interfaceType string
+		want          string
+	}{
+		{interfaceType: "claude", want: "event: error\ndata: {\"error\":{\"message\":"},
+		{interfaceType: "codex", want: "event: error\ndata: {\"code\":\"server_error\""},
+		{interfaceType: "chat", want: "data: {\"error\":{\"code\":null,\"message\":"},
+		{interfaceType: "gemini", want: "\"status\":\"INTERNAL\""},
+	}
+	for _, tc := range cases {
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"text/event-stream"}},
+			Body:       io.NopCloser(strings.NewReader("data: a\n\n" + "data: " + strings.Repeat("x", 100) + "\n\n")),
+		}
+		w := httptest.NewRecorder()
+		result := (&BaseExecutor{}).handleStreamingResponse(context.Background(), w, resp, &ForwardResult{StatusCode: http.StatusOK}, &EndpointConfig{Name: "ep", InterfaceType: tc.interfaceType}, nil)
+
+		if !errors.Is(result.Error, ErrStreamLineTooLong) {
+			t.Fatalf("%s: err=%v want ErrStreamLineTooLong", tc.interfaceType, result.Error)
+		}
+		body := w.Body.String()
+		if !strings.HasPrefix(body, "data: a\n") || strings.Contains(body, "xxxx") {
+			t.Fatalf("%s: body=%q want the lines before the oversized one only", tc.interfaceType, body)
+		}
+		if !strings.Contains(body, tc.want) || !strings.Contains(body, "maxStreamLineBytes") {
+			t.Fatalf("%s: body=%q want error event containing %q", tc.interfaceType, body, tc.want)
+		}
+	}
+}
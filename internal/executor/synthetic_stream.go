@@ -0,0 +1,266 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"clisimplehub/internal/transformer/shared"
+)
+
+// synthesizeStream 把完整的非流式 JSON 响应转换为客户端协议的流式响应，
+// 返回流内容和对应的 Content-Type
+func synthesizeStream(interfaceType string, body []byte, geminiSSE bool) ([]byte, string, error) {
+	var resp map[string]any
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, "", fmt.Errorf("invalid response json: %w", err)
+	}
+
+	w := &sseWriter{}
+	switch interfaceType {
+	case "claude":
+		synthesizeClaudeStream(w, resp)
+	case "codex":
+		synthesizeResponsesStream(w, resp)
+	case "chat":
+		synthesizeChatStream(w, resp)
+	case "gemini":
+		// Gemini 流式分片与非流式响应结构一致，整体作为单个分片返回
+		if !geminiSSE {
+			var buf bytes.Buffer
+			buf.WriteByte('[')
+			buf.Write(bytes.TrimSpace(body))
+			buf.WriteByte(']')
+			return buf.Bytes(), "application/json", nil
+		}
+		w.data(resp)
+	default:
+		return nil, "", fmt.Errorf("unsupported interface type: %s", interfaceType)
+	}
+	return w.buf.Bytes(), "text/event-stream", w.err
+}
+
+type sseWriter struct {
+	buf bytes.Buffer
+	err error
+}
+
+func (w *sseWriter) event(name string, payload any) {
+	w.buf.WriteString("event: ")
+	w.buf.WriteString(name)
+	w.buf.WriteString(shared.SSELineEnding())
+	w.data(payload)
+}
+
+func (w *sseWriter) data(payload any) {
+	b, err := json.Marshal(payload)
+	if err != nil && w.err == nil {
+		w.err = err
+	}
+	w.buf.WriteString(shared.SSEFrame("data: " + string(b)))
+}
+
+func synthesizeClaudeStream(w *sseWriter, msg map[string]any) {
+	content, _ := msg["content"].([]any)
+	usage, _ := msg["usage"].(map[string]any)
+
+	startMsg := make(map[string]any, len(msg))
+	for k, v := range msg {
+		startMsg[k] = v
+	}
+	startMsg["content"] = []any{}
+	startMsg["stop_reason"] = nil
+	startMsg["stop_sequence"] = nil
+	if usage != nil {
+		startUsage := make(map[string]any, len(usage))
+		for k, v := range usage {
+			startUsage[k] = v
+		}
+		startUsage["output_tokens"] = 0
+		startMsg["usage"] = startUsage
+	}
+	w.event("message_start", map[string]any{"type": "message_start", "message": startMsg})
+
+	for i, raw := range content {
+		block, _ := raw.(map[string]any)
+		if block == nil {
+			continue
+		}
+		blockType, _ := block["type"].(string)
+
+		var start map[string]any
+		var deltas []map[string]any
+		switch blockType {
+		case "text":
+			start = map[string]any{"type": "text", "text": ""}
+			deltas = append(deltas, map[string]any{"type": "text_delta", "text": block["text"]})
+		case "thinking":
+			start = map[string]any{"type": "thinking", "thinking": ""}
+			deltas = append(deltas, map[string]any{"type": "thinking_delta", "thinking": block["thinking"]})
+			if sig, ok := block["signature"].(string); ok && sig != "" {
+				deltas = append(deltas, map[string]any{"type": "signature_delta", "signature": sig})
+			}
+		case "tool_use", "server_tool_use":
+			start = map[string]any{"type": blockType, "id": block["id"], "name": block["name"], "input": map[string]any{}}
+			input, _ := json.Marshal(block["input"])
+			deltas = append(deltas, map[string]any{"type": "input_json_delta", "partial_json": string(input)})
+		default:
+			start = block
+		}
+
+		w.event("content_block_start", map[string]any{"type": "content_block_start", "index": i, "content_block": start})
+		for _, d := range deltas {
+			w.event("content_block_delta", map[string]any{"type": "content_block_delta", "index": i, "delta": d})
+		}
+		w.event("content_block_stop", map[string]any{"type": "content_block_stop", "index": i})
+	}
+
+	deltaUsage := map[string]any{"output_tokens": 0}
+	if usage != nil && usage["output_tokens"] != nil {
+		deltaUsage["output_tokens"] = usage["output_tokens"]
+	}
+	w.event("message_delta", map[string]any{
+		"type":  "message_delta",
+		"delta": map[string]any{"stop_reason": msg["stop_reason"], "stop_sequence": msg["stop_sequence"]},
+		"usage": deltaUsage,
+	})
+	w.event("message_stop", map[string]any{"type": "message_stop"})
+}
+
+func synthesizeChatStream(w *sseWriter, resp map[string]any) {
+	chunk := func(choices []any) map[string]any {
+		c := map[string]any{
+			"id":      resp["id"],
+			"object":  "chat.completion.chunk",
+			"created": resp["created"],
+			"model":   resp["model"],
+			"choices": choices,
+		}
+		if fp, ok := resp["system_fingerprint"]; ok {
+			c["system_fingerprint"] = fp
+		}
+		return c
+	}
+
+	choices, _ := resp["choices"].([]any)
+	for _, raw := range choices {
+		choice, _ := raw.(map[string]any)
+		if choice == nil {
+			continue
+		}
+		index := choice["index"]
+		message, _ := choice["message"].(map[string]any)
+
+		delta := map[string]any{"role": "assistant"}
+		if message != nil {
+			if role, ok := message["role"]; ok {
+				delta["role"] = role
+			}
+			for _, key := range []string{"content", "reasoning_content", "refusal"} {
+				if v, ok := message[key]; ok && v != nil {
+					delta[key] = v
+				}
+			}
+		}
+		w.data(chunk([]any{map[string]any{"index": index, "delta": delta, "finish_reason": nil}}))
+
+		if message != nil {
+			if toolCalls, ok := message["tool_calls"].([]any); ok && len(toolCalls) > 0 {
+				calls := make([]any, 0, len(toolCalls))
+				for i, tc := range toolCalls {
+					call, _ := tc.(map[string]any)
+					if call == nil {
+						continue
+					}
+					withIndex := map[string]any{"index": i}
+					for k, v := range call {
+						withIndex[k] = v
+					}
+					calls = append(calls, withIndex)
+				}
+				w.data(chunk([]any{map[string]any{"index": index, "delta": map[string]any{"tool_calls": calls}, "finish_reason": nil}}))
+			}
+		}
+
+		w.data(chunk([]any{map[string]any{"index": index, "delta": map[string]any{}, "finish_reason": choice["finish_reason"]}}))
+	}
+
+	if usage, ok := resp["usage"]; ok && usage != nil {
+		c := chunk([]any{})
+		c["usage"] = usage
+		w.data(c)
+	}
+	w.buf.WriteString(shared.SSEFrame("data: [DONE]"))
+}
+
+func synthesizeResponsesStream(w *sseWriter, resp map[string]any) {
+	seq := 0
+	emit := func(eventType string, payload map[string]any) {
+		payload["type"] = eventType
+		payload["sequence_number"] = seq
+		seq++
+		w.event(eventType, payload)
+	}
+
+	created := make(map[string]any, len(resp))
+	for k, v := range resp {
+		created[k] = v
+	}
+	created["status"] = "in_progress"
+	created["output"] = []any{}
+	delete(created, "usage")
+	emit("response.created", map[string]any{"response": created})
+
+	output, _ := resp["output"].([]any)
+	for i, raw := range output {
+		item, _ := raw.(map[string]any)
+		if item == nil {
+			continue
+		}
+		itemID := item["id"]
+		itemType, _ := item["type"].(string)
+
+		added := make(map[string]any, len(item))
+		for k, v := range item {
+			added[k] = v
+		}
+		added["status"] = "in_progress"
+		switch itemType {
+		case "message":
+			added["content"] = []any{}
+		case "function_call":
+			added["arguments"] = ""
+		}
+		emit("response.output_item.added", map[string]any{"output_index": i, "item": added})
+
+		switch itemType {
+		case "message":
+			parts, _ := item["content"].([]any)
+			for j, rawPart := range parts {
+				part, _ := rawPart.(map[string]any)
+				if part == nil {
+					continue
+				}
+				partType, _ := part["type"].(string)
+				emptyPart := map[string]any{"type": partType}
+				if partType == "output_text" {
+					emptyPart["text"] = ""
+					emptyPart["annotations"] = []any{}
+				}
+				emit("response.content_part.added", map[string]any{"item_id": itemID, "output_index": i, "content_index": j, "part": emptyPart})
+				if partType == "output_text" {
+					emit("response.output_text.delta", map[string]any{"item_id": itemID, "output_index": i, "content_index": j, "delta": part["text"]})
+					emit("response.output_text.done", map[string]any{"item_id": itemID, "output_index": i, "content_index": j, "text": part["text"]})
+				}
+				emit("response.content_part.done", map[string]any{"item_id": itemID, "output_index": i, "content_index": j, "part": part})
+			}
+		case "function_call":
+			emit("response.function_call_arguments.delta", map[string]any{"item_id": itemID, "output_index": i, "delta": item["arguments"]})
+			emit("response.function_call_arguments.done", map[string]any{"item_id": itemID, "output_index": i, "arguments": item["arguments"]})
+		}
+
+		emit("response.output_item.done", map[string]any{"output_index": i, "item": item})
+	}
+
+	emit("response.completed", map[string]any{"response": resp})
+}
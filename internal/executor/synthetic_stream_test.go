@@ -0,0 +1,94 @@
+package executor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSynthesizeStream_Claude(t *testing.T) {
+	t.Parallel()
+
+	body := `{"id":"msg_1","type":"message","role":"assistant","model":"claude","content":[{"type":"text","text":"hi"},{"type":"tool_use","id":"tu_1","name":"read","input":{"path":"a"}}],"stop_reason":"tool_use","stop_sequence":null,"usage":{"input_tokens":10,"output_tokens":5}}`
+
+	out, contentType, err := synthesizeStream("claude", []byte(body), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if contentType != "text/event-stream" {
+		t.Fatalf("content type = %q", contentType)
+	}
+
+	stream := string(out)
+	for _, want := range []string{
+		"event: message_start\n",
+		`"text":"hi","type":"text_delta"`,
+		`"partial_json":"{\"path\":\"a\"}"`,
+		`"index":1`,
+		`"stop_reason":"tool_use"`,
+		`"output_tokens":5`,
+		"event: message_stop\n",
+	} {
+		if !strings.Contains(stream, want) {
+			t.Fatalf("stream missing %q:\n%s", want, stream)
+		}
+	}
+	if n := strings.Count(stream, "event: content_block_stop"); n != 2 {
+		t.Fatalf("content_block_stop count = %d, want 2", n)
+	}
+}
+
+func TestDisableStreamInBody(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "stream true", in: `{"model":"m","stream":true,"stream_options":{"include_usage":true}}`, want: `{"model":"m","stream":false}`},
+		{name: "no stream field", in: `{"model":"m"}`, want: `{"model":"m"}`},
+		{name: "invalid json", in: `not json`, want: `not json`},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if got := string(disableStreamInBody([]byte(tc.in))); got != tc.want {
+				t.Fatalf("got %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExecuteForcedNonStream_LargeResponseNotSpooled(t *testing.T) {
+	// 修改全局落盘阈值，不能并行
+	SetResponseSpoolThresholdBytes(1024)
+	t.Cleanup(func() { SetResponseSpoolThresholdBytes(0) })
+
+	text := strings.Repeat("x", 64*1024)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"msg_1","type":"message","role":"assistant","model":"claude","content":[{"type":"text","text":"` + text + `"}],"stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":2}}`))
+	}))
+	defer upstream.Close()
+
+	endpoint := &EndpointConfig{ID: 1, Name: "a", APIURL: upstream.URL, InterfaceType: "claude", ForceNonStreamUpstream: true}
+	execCtx := NewExecutionContext(&staleProvider{endpoints: []*EndpointConfig{endpoint}})
+	req := &ForwardRequest{Method: http.MethodPost, Path: "/v1/messages", Headers: http.Header{}, Body: []byte(`{"model":"m","stream":true}`), IsStreaming: true}
+	rec := httptest.NewRecorder()
+	result := execCtx.executeForcedNonStream(context.Background(), "claude", endpoint, req, rec)
+
+	if result == nil || result.Error != nil || result.Spooled != nil || !result.Streamed {
+		t.Fatalf("result=%+v want streamed result without spool", result)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("content type = %q", ct)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, "event: message_stop") || !strings.Contains(body, text) {
+		t.Fatalf("client did not receive the synthesized stream (%d bytes)", len(body))
+	}
+}
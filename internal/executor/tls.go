@@ -0,0 +1,64 @@
+package executor
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"os"
+	"strings"
+
+	"clisimplehub/internal/logger"
+)
+
+// tlsSettings 端点的上游 TLS 设置，零值表示使用系统根证书正常校验
+type tlsSettings struct {
+	insecureSkipVerify bool   // 跳过证书校验（不安全）
+	caFile             string // PEM 格式的 CA 证书文件路径
+}
+
+// resolveTLSSettings 读取端点的 TLS 设置
+func resolveTLSSettings(endpoint *EndpointConfig) tlsSettings {
+	if endpoint == nil {
+		return tlsSettings{}
+	}
+	return tlsSettings{
+		insecureSkipVerify: endpoint.InsecureSkipVerify,
+		caFile:             strings.TrimSpace(endpoint.CAFile),
+	}
+}
+
+// applyTLSSettings 将端点 TLS 设置写入 Transport.TLSClientConfig。
+// InsecureSkipVerify 会接受任何证书，连接可被中间人窃听，仅用于自签名证书的自建网关；
+// 配置 CA 文件时只信任文件中的证书，文件无法读取或解析时使用空证书池，校验必然失败而不是退回系统根证书。
+func applyTLSSettings(transport *http.Transport, cfg tlsSettings) {
+	if cfg == (tlsSettings{}) {
+		return
+	}
+
+	tlsConfig := &tls.Config{}
+	if transport.TLSClientConfig != nil {
+		tlsConfig = transport.TLSClientConfig.Clone()
+	}
+	if cfg.insecureSkipVerify {
+		logger.Warn("[Executor] TLS certificate verification disabled for upstream transport")
+		tlsConfig.InsecureSkipVerify = true
+	}
+	if cfg.caFile != "" {
+		tlsConfig.RootCAs = loadCAPool(cfg.caFile)
+	}
+	transport.TLSClientConfig = tlsConfig
+}
+
+// loadCAPool 读取 PEM 格式的 CA 证书文件，失败时返回空证书池
+func loadCAPool(path string) *x509.CertPool {
+	pool := x509.NewCertPool()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		logger.Warn("[Executor] read CA file failed (%s): %v", path, err)
+		return pool
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		logger.Warn("[Executor] no valid PEM certificates in CA file: %s", path)
+	}
+	return pool
+}
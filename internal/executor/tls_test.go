@@ -0,0 +1,51 @@
+package executor
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewHTTPClientTLSSettings(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, certPEM, 0o600); err != nil {
+		t.Fatalf("write ca file: %v", err)
+	}
+	badFile := filepath.Join(dir, "bad.pem")
+	if err := os.WriteFile(badFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("write bad file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		endpoint *EndpointConfig
+		wantErr  bool
+	}{
+		{name: "default verifies", endpoint: &EndpointConfig{}, wantErr: true},
+		{name: "insecure skip verify", endpoint: &EndpointConfig{InsecureSkipVerify: true}},
+		{name: "custom ca", endpoint: &EndpointConfig{CAFile: caFile}},
+		{name: "invalid ca file", endpoint: &EndpointConfig{CAFile: badFile}, wantErr: true},
+		{name: "missing ca file", endpoint: &EndpointConfig{CAFile: filepath.Join(dir, "missing.pem")}, wantErr: true},
+	}
+	for _, tt := range tests {
+		resp, err := NewHTTPClient(tt.endpoint, 0).Get(srv.URL)
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type traceKey struct{}
+
+// WithTrace 标记请求需要记录详细的执行决策（按需调试追踪）
+func WithTrace(ctx context.Context) context.Context {
+	return context.WithValue(ctx, traceKey{}, true)
+}
+
+// TraceEnabled 判断请求是否开启了调试追踪
+func TraceEnabled(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	enabled, _ := ctx.Value(traceKey{}).(bool)
+	return enabled
+}
+
+// Tracef 仅在请求开启追踪时通过 DebugLog 输出执行决策，普通请求不产生额外开销
+func (c *ExecutionContext) Tracef(ctx context.Context, format string, args ...any) {
+	if !TraceEnabled(ctx) {
+		return
+	}
+	c.DebugLog(ctx, 1, "[Trace] "+fmt.Sprintf(format, args...))
+}
+
+// traceResult 记录一次端点尝试的结果与耗时
+func (c *ExecutionContext) traceResult(ctx context.Context, endpoint *EndpointConfig, result *ForwardResult, elapsed time.Duration) {
+	if !TraceEnabled(ctx) || result == nil {
+		return
+	}
+	c.Tracef(ctx, "result: endpoint=%s status=%d streamed=%v error=%v target=%s elapsed=%s",
+		endpointDisplayName(endpoint), result.StatusCode, result.Streamed, result.Error, result.TargetURL, elapsed.Round(time.Millisecond))
+}
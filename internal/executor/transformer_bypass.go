@@ -0,0 +1,41 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"clisimplehub/internal/transformer"
+)
+
+// isTransformerBypassPath 判断请求是否为非对话类接口（token 计数、模型列表等）。
+// transformer 只能转换对话请求体，这类请求应原样转发，否则会因转换失败返回 400。
+func isTransformerBypassPath(path string) bool {
+	p := strings.TrimRight(strings.ToLower(strings.TrimSpace(path)), "/")
+	if strings.HasSuffix(p, "/count_tokens") || strings.HasSuffix(p, "/models") {
+		return true
+	}
+	// 单个模型查询（/v1/models/{id}）；gemini 的 /models/{model}:generateContent 属于对话接口
+	if i := strings.LastIndex(p, "/models/"); i >= 0 {
+		return !strings.Contains(p[i:], ":")
+	}
+	return false
+}
+
+// forwardBypassingTransformer 将非对话请求按 transformer 的目标接口类型原样转发到端点
+func (c *ExecutionContext) forwardBypassingTransformer(ctx context.Context, interfaceType string, endpoint *EndpointConfig, req *ForwardRequest, w http.ResponseWriter) (*ForwardResult, bool) {
+	tr, err := transformer.Get(interfaceType, endpoint.Transformer)
+	if err != nil || tr == nil {
+		return nil, false
+	}
+	target := strings.TrimSpace(tr.TargetInterfaceType())
+	if target == "" {
+		return nil, false
+	}
+
+	c.DebugLog(ctx, 1, fmt.Sprintf("[Transformer] 非对话请求跳过转换: endpoint=%s path=%s target=%s", endpoint.Name, req.Path, target))
+	native := *endpoint
+	native.InterfaceType = target
+	return c.GetExecutor(target).Forward(ctx, &native, req, w), true
+}
@@ -0,0 +1,26 @@
+package executor
+
+import "testing"
+
+func TestIsTransformerBypassPath(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		path string
+		want bool
+	}{
+		{path: "/v1/messages", want: false},
+		{path: "/v1/messages/count_tokens", want: true},
+		{path: "/v1/models", want: true},
+		{path: "/v1/models/", want: true},
+		{path: "/v1/models/claude-sonnet-4", want: true},
+		{path: "/v1/chat/completions", want: false},
+		{path: "/v1/responses", want: false},
+		{path: "/v1beta/models/gemini-2.5-pro:streamGenerateContent", want: false},
+	}
+	for _, tc := range cases {
+		if got := isTransformerBypassPath(tc.path); got != tc.want {
+			t.Fatalf("isTransformerBypassPath(%q)=%v want %v", tc.path, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"clisimplehub/internal/transformer"
+)
+
+func TestTransformedNonStreamingResponse_UpstreamError(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name       string
+		from       string
+		spec       string
+		status     int
+		body       string
+		wantStatus int
+		wantType   string
+		wantMsg    string
+	}{
+		{name: "claude from chat 429", from: "claude", spec: "openai/chat-completions", status: 429, body: `{"error":{"message":"slow down","type":"rate_limit_exceeded"}}`, wantStatus: 429, wantType: "rate_limit_error", wantMsg: "slow down"},
+		{name: "claude from gemini 400", from: "claude", spec: "gemini", status: 400, body: `{"error":{"code":400,"message":"bad arg","status":"INVALID_ARGUMENT"}}`, wantStatus: 400, wantType: "invalid_request_error", wantMsg: "bad arg"},
+		{name: "claude from responses 200 error body", from: "claude", spec: "openai/responses", status: 200, body: `{"error":{"message":"overloaded","type":"server_error","code":"503"}}`, wantStatus: 503, wantType: "overloaded_error", wantMsg: "overloaded"},
+		{name: "codex from chat 500 text", from: "codex", spec: "openai/chat-completions", status: 500, body: "internal failure", wantStatus: 500, wantType: "server_error", wantMsg: "internal failure"},
+	}
+	for _, tc := range cases {
+		tr, err := transformer.Get(tc.from, tc.spec)
+		if err != nil {
+			t.Fatalf("%s: transformer.Get err=%v", tc.name, err)
+		}
+		resp := &http.Response{
+			StatusCode: tc.status,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(tc.body)),
+		}
+		result := handleTransformedNonStreamingResponse(context.Background(), resp, &ForwardResult{StatusCode: tc.status, Headers: resp.Header.Clone()}, tr, "m", nil, nil)
+		if result.StatusCode != tc.wantStatus {
+			t.Fatalf("%s: status=%d want %d", tc.name, result.StatusCode, tc.wantStatus)
+		}
+
+		var body struct {
+			Type  string `json:"type"`
+			Error struct {
+				Type    string `json:"type"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(result.Body, &body); err != nil {
+			t.Fatalf("%s: body %q is not JSON: %v", tc.name, result.Body, err)
+		}
+		if tc.from == "claude" && body.Type != "error" {
+			t.Fatalf("%s: type=%q want error", tc.name, body.Type)
+		}
+		if body.Error.Type != tc.wantType || body.Error.Message != tc.wantMsg {
+			t.Fatalf("%s: error=%+v want type=%q message=%q", tc.name, body.Error, tc.wantType, tc.wantMsg)
+		}
+	}
+}
@@ -1,16 +1,18 @@
 package executor
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
 
+	"clisimplehub/internal/logger"
 	"clisimplehub/internal/transformer"
+	"clisimplehub/internal/transformer/shared"
 	"clisimplehub/internal/usage"
 )
 
@@ -52,14 +54,26 @@ func (c *ExecutionContext) executeWithTransformer(ctx context.Context, interface
 		return result
 	}
 	result.TargetURL = targetURL
+	if err := checkProxyLoop(targetURL); err != nil {
+		c.DebugLog(ctx, 3, fmt.Sprintf("[Transformer] 目标指向本代理: endpoint=%s target=%s", endpoint.Name, targetURL))
+		result.Error = err
+		return result
+	}
+
+	ctx, cancel, watchdog := withFirstByteWatchdog(ctx, req.IsStreaming)
+	defer cancel()
 
-	requestBody := applyModelMapping(transformedBody, endpoint)
+	requestBody, maxTokensClamp := prepareUpstreamBody(transformedBody, endpoint, targetPath)
+	result.MaxTokensClamp = maxTokensClamp
 	finalModel := extractModelFromBody(requestBody)
 	if finalModel == "" {
 		finalModel = upstreamModel
 	}
 	modelMapped := requestModel != "" && finalModel != "" && requestModel != finalModel
 	c.DebugLog(ctx, 1, fmt.Sprintf("转发: endpoint=%s interface=%s transformer=%q target=%s model(client=%q upstream=%q final=%q mapped=%v) stream=%v", endpoint.Name, interfaceType, endpoint.Transformer, targetURL, requestModel, upstreamModel, finalModel, modelMapped, req.IsStreaming))
+	if maxTokensClamp != "" {
+		c.DebugLog(ctx, 1, fmt.Sprintf("[MaxTokensCap] endpoint=%s 下调最大输出 token: %s", endpoint.Name, maxTokensClamp))
+	}
 	proxyReq, err := http.NewRequestWithContext(ctx, req.Method, targetURL, bytes.NewReader(requestBody))
 	if err != nil {
 		result.Error = fmt.Errorf("failed to create request: %w", err)
@@ -69,11 +83,18 @@ func (c *ExecutionContext) executeWithTransformer(ctx context.Context, interface
 	copyRequestHeaders(proxyReq, req.Headers)
 	ApplyAuthForInterfaceType(proxyReq, endpoint.APIKey, tr.TargetInterfaceType(), req.IsStreaming)
 	ApplyEndpointHeaders(proxyReq, endpoint)
+	applyAnthropicHeaders(proxyReq, endpoint, tr.TargetInterfaceType())
+	applyClientIPHeaders(proxyReq, endpoint, req)
+	markForwarded(proxyReq)
 	result.TargetHeaders = sanitizeHeaders(proxyReq.Header)
 
 	client := NewHTTPClient(endpoint, 0)
 	resp, err := client.Do(proxyReq)
 	if err != nil {
+		if watchdog.timedOut() {
+			c.DebugLog(ctx, 2, fmt.Sprintf("[Transformer] 首字节超时: endpoint=%s timeout=%s", endpoint.Name, StreamFirstByteTimeout()))
+			return watchdog.fail(result)
+		}
 		result.Error = fmt.Errorf("request failed: %w", err)
 		return result
 	}
@@ -84,11 +105,12 @@ func (c *ExecutionContext) executeWithTransformer(ctx context.Context, interface
 
 	if req.IsStreaming && resp.StatusCode == http.StatusOK && shouldTreatAsStreaming(resp, tr) {
 		c.DebugLog(ctx, 1, fmt.Sprintf("响应: endpoint=%s status=%d content-type=%s (stream)", endpoint.Name, resp.StatusCode, resp.Header.Get("Content-Type")))
-		return handleTransformedStreamingResponse(ctx, w, resp, result, tr, requestModel, originalBody, requestBody)
+		return handleTransformedStreamingResponse(ctx, w, resp, result, endpoint, tr, requestModel, originalBody, requestBody, watchdog)
 	}
+	watchdog.received()
 
 	c.DebugLog(ctx, 1, fmt.Sprintf("响应: endpoint=%s status=%d content-type=%s", endpoint.Name, resp.StatusCode, resp.Header.Get("Content-Type")))
-	out := handleTransformedNonStreamingResponse(ctx, resp, result, tr, requestModel, originalBody, requestBody)
+	out := applyResponseValidation(handleTransformedNonStreamingResponse(ctx, resp, result, tr, requestModel, originalBody, requestBody), endpoint)
 	if out != nil && (out.Error != nil || out.StatusCode >= 400) && len(out.Body) > 0 {
 		level := 2
 		if out.Error != nil || out.StatusCode >= 500 {
@@ -111,21 +133,29 @@ func shouldTreatAsStreaming(resp *http.Response, tr transformer.Transformer) boo
 	return strings.EqualFold(strings.TrimSpace(tr.TargetInterfaceType()), "gemini")
 }
 
-func handleTransformedStreamingResponse(ctx context.Context, w http.ResponseWriter, resp *http.Response, result *ForwardResult, tr transformer.Transformer, modelName string, originalRequestRawJSON, requestRawJSON []byte) *ForwardResult {
-	// Force Claude streaming semantics to the caller.
-	for key, values := range resp.Header {
-		switch strings.ToLower(key) {
-		case "content-length", "content-encoding":
-			continue
-		case "content-type":
-			continue
+func handleTransformedStreamingResponse(ctx context.Context, w http.ResponseWriter, resp *http.Response, result *ForwardResult, endpoint *EndpointConfig, tr transformer.Transformer, modelName string, originalRequestRawJSON, requestRawJSON []byte, watchdog *firstByteWatchdog) *ForwardResult {
+	// 响应头延迟到收到首行数据后再写出，首字节超时时客户端未收到任何内容，可以故障转移
+	wroteHeader := false
+	writeHeader := func() {
+		if wroteHeader {
+			return
 		}
-		for _, v := range values {
-			w.Header().Add(key, v)
+		wroteHeader = true
+		// Force Claude streaming semantics to the caller.
+		for key, values := range resp.Header {
+			switch strings.ToLower(key) {
+			case "content-length", "content-encoding":
+				continue
+			case "content-type":
+				continue
+			}
+			for _, v := range values {
+				w.Header().Add(key, v)
+			}
 		}
+		w.Header().Set("Content-Type", tr.OutputContentType(true))
+		w.WriteHeader(resp.StatusCode)
 	}
-	w.Header().Set("Content-Type", tr.OutputContentType(true))
-	w.WriteHeader(resp.StatusCode)
 
 	flusher, ok := w.(http.Flusher)
 	if !ok {
@@ -138,15 +168,30 @@ func handleTransformedStreamingResponse(ctx context.Context, w http.ResponseWrit
 		defer closer.Close()
 	}
 
-	scanner := bufio.NewScanner(reader)
-	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	// 按 SSE 事件重组被拆分的 data 行，避免 transformer 解析半截 JSON
+	events := newSSEEventReader(newStreamLineReader(reader, MaxStreamLineBytes()))
+	events.onRawLine = watchdog.received
+	var scanner streamScanner = events
+	// Gemini 的 JSON 行流不是 SSE，不做流式校验
+	if strings.Contains(strings.ToLower(resp.Header.Get("Content-Type")), "text/event-stream") {
+		scanner = newValidatingScanner(events, endpoint)
+	}
 
 	var capture strings.Builder
 	const maxCaptureSize = 50 * 1024
 
 	var state any
+	// 原始透传回退：transformer 尚未产出任何内容前缓冲原始行，响应头延迟到首个转换输出
+	rawFallback := RawFallbackOnTransformError()
+	var rawPending bytes.Buffer
+	transformed := false
 
 	for scanner.Scan() {
+		watchdog.received()
+		if !rawFallback || transformed {
+			writeHeader()
+		}
+
 		select {
 		case <-ctx.Done():
 			result.Error = ctx.Err()
@@ -167,6 +212,21 @@ func handleTransformedStreamingResponse(ctx context.Context, w http.ResponseWrit
 		}
 
 		outs, err := tr.TransformResponseStream(ctx, modelName, originalRequestRawJSON, requestRawJSON, line, &state)
+		if rawFallback && !transformed {
+			if err == nil && hasStreamOutput(outs) {
+				transformed = true
+				rawPending = bytes.Buffer{}
+				writeHeader()
+			} else {
+				rawPending.Write(line)
+				rawPending.WriteByte('\n')
+				if rawPending.Len() > maxRawFallbackBytes {
+					rawFallback = false
+					rawPending = bytes.Buffer{}
+					writeHeader()
+				}
+			}
+		}
 		if err != nil {
 			continue
 		}
@@ -182,7 +242,39 @@ func handleTransformedStreamingResponse(ctx context.Context, w http.ResponseWrit
 		}
 	}
 
+	if watchdog.timedOut() {
+		return watchdog.fail(result)
+	}
+	if err := scanner.Err(); errors.Is(err, ErrInvalidResponse) {
+		return failInvalidStream(result, err)
+	}
+	// transformer 对整个流没有任何输出：透传上游原始流，避免客户端收到空响应
+	if rawFallback && !transformed && rawPending.Len() > 0 && scanner.Err() == nil && ctx.Err() == nil {
+		logger.Warn("[Transformer] endpoint=%s transformer=%q produced no output for the stream, passing raw upstream response through", endpoint.Name, endpoint.Transformer)
+		writeRawUpstreamHeader(w, resp)
+		_, _ = w.Write(rawPending.Bytes())
+		flusher.Flush()
+		result.ResponseStream = capture.String()
+		result.Streamed = true
+		return result
+	}
+	writeHeader()
+
+	// 上游未发送 [DONE] 就结束时，补发转换器缓冲的收尾事件（如 response.completed）
+	if finisher, ok := tr.(transformer.StreamFinisher); ok && scanner.Err() == nil && ctx.Err() == nil {
+		for _, out := range finisher.FinishResponseStream(ctx, modelName, &state) {
+			if out == "" {
+				continue
+			}
+			if _, err := w.Write([]byte(out)); err != nil {
+				break
+			}
+		}
+		flusher.Flush()
+	}
+
 	if err := scanner.Err(); err != nil {
+		writeStreamLineTooLongEvent(w, flusher, endpoint.InterfaceType, err)
 		result.Error = err
 	}
 
@@ -225,7 +317,19 @@ func handleTransformedNonStreamingResponse(ctx context.Context, resp *http.Respo
 		return result
 	}
 
+	// 上游错误：保留上游状态码，把错误体转换为客户端协议的错误格式，而不是当作正常响应转换
+	if statusCode, ok := upstreamErrorStatus(resp.StatusCode, body); ok {
+		return transformErrorResult(result, tr, statusCode, body)
+	}
+
 	converted, err := tr.TransformResponseNonStream(ctx, modelName, originalRequestRawJSON, requestRawJSON, body, nil)
+	if (err != nil || len(bytes.TrimSpace(converted)) == 0) && resp.StatusCode < 400 && len(body) > 0 && RawFallbackOnTransformError() {
+		// 转换失败或无输出：透传上游原始响应体与原 Content-Type
+		logger.Warn("[Transformer] response transform failed (status=%d, err=%v), passing raw upstream body through", resp.StatusCode, err)
+		result.Body = body
+		result.Tokens = usageTokens(body)
+		return result
+	}
 	if err != nil {
 		result.Error = err
 		result.Body = body
@@ -238,6 +342,47 @@ func handleTransformedNonStreamingResponse(ctx context.Context, resp *http.Respo
 	return result
 }
 
+// upstreamErrorStatus 判断非流式响应是否为上游错误并返回应回给客户端的状态码：
+// HTTP 4xx/5xx 保留原状态码；HTTP 200 但响应体是错误对象时按错误码/类型推断
+func upstreamErrorStatus(statusCode int, body []byte) (int, bool) {
+	if statusCode >= 400 {
+		return statusCode, true
+	}
+	root, err := shared.DecodeJSONMap(body)
+	if err != nil {
+		return statusCode, false
+	}
+	streamErr, ok := shared.DetectStreamError(root)
+	if !ok {
+		return statusCode, false
+	}
+	return streamErr.HTTPStatus(), true
+}
+
+// transformErrorResult 用 transformer 把上游错误体转换为客户端的错误格式；不支持时原样透传
+func transformErrorResult(result *ForwardResult, tr transformer.Transformer, statusCode int, body []byte) *ForwardResult {
+	result.StatusCode = statusCode
+	result.Body = body
+	if responder, ok := tr.(transformer.ErrorResponder); ok {
+		if converted := responder.TransformErrorResponse(statusCode, body); len(converted) > 0 {
+			result.Body = converted
+			result.Headers.Set("Content-Type", tr.OutputContentType(false))
+		}
+	}
+	result.Headers.Del("Content-Length")
+	return result
+}
+
+// hasStreamOutput 判断 transformer 本次是否产出了非空内容
+func hasStreamOutput(outs []string) bool {
+	for _, out := range outs {
+		if out != "" {
+			return true
+		}
+	}
+	return false
+}
+
 func usageTokens(body []byte) *TokenUsage {
 	stats := usage.ExtractFromResponse(body)
 	if stats == nil || stats.IsEmpty() {
@@ -14,6 +14,12 @@ type ForwardRequest struct {
 	Headers     http.Header
 	Body        []byte
 	IsStreaming bool
+	UserID      string // 请求方用户标识（metadata.user_id 或 user），用于按用户统计
+	ClientIP    string // 客户端连接的来源 IP，端点开启 ForwardClientIP 时转发给上游
+	// InterfaceType 路由规则强制的接口类型，非空时覆盖按路径检测的结果
+	InterfaceType string
+	// NoSpool 需要完整的内存响应体时禁止落盘（强制非流式需据此合成 SSE）
+	NoSpool bool
 }
 
 // ForwardResult 表示转发请求的结果
@@ -21,11 +27,14 @@ type ForwardResult struct {
 	StatusCode     int
 	Headers        http.Header
 	Body           []byte
+	Spooled        *SpooledBody // 超过落盘阈值的非流式响应体（此时 Body 为空），持有者负责 Close
 	TargetURL      string
 	TargetHeaders  map[string]string
 	ResponseStream string
 	Tokens         *TokenUsage
 	Streamed       bool
+	ModelFallback  string // 上游不支持请求模型时替换为默认模型，格式 "原模型 -> 默认模型"
+	MaxTokensClamp string // 请求的最大输出 token 数被端点上限下调，格式 "原值 -> 上限"
 	Error          error
 }
 
@@ -46,23 +55,43 @@ type TokenUsage struct {
 
 // EndpointConfig 端点配置
 type EndpointConfig struct {
-	ID            int64             `json:"id"`
-	Name          string            `json:"name"`
-	APIURL        string            `json:"api_url"`
-	APIKey        string            `json:"api_key"`
-	InterfaceType string            `json:"interface_type"`
-	Transformer   string            `json:"transformer,omitempty"`
-	VendorID      int64             `json:"vendor_id,omitempty"`
-	Model         string            `json:"model,omitempty"`
-	ProxyURL      string            `json:"proxy_url,omitempty"`
-	Models        []ModelMapping    `json:"models,omitempty"`
-	Headers       map[string]string `json:"headers,omitempty"`
+	ID                     int64             `json:"id"`
+	Name                   string            `json:"name"`
+	APIURL                 string            `json:"api_url"`
+	APIKey                 string            `json:"api_key"`
+	InterfaceType          string            `json:"interface_type"`
+	Transformer            string            `json:"transformer,omitempty"`
+	VendorID               int64             `json:"vendor_id,omitempty"`
+	Remark                 string            `json:"remark,omitempty"` // 端点备注，仅用于请求日志展示
+	Model                  string            `json:"model,omitempty"`
+	ProxyURL               string            `json:"proxy_url,omitempty"`
+	ProxyUsername          string            `json:"proxy_username,omitempty"`
+	ProxyPassword          string            `json:"proxy_password,omitempty"`
+	ForceNonStreamUpstream bool              `json:"force_non_stream_upstream,omitempty"`
+	FallbackToDefaultModel bool              `json:"fallback_to_default_model,omitempty"`
+	Models                 []ModelMapping    `json:"models,omitempty"`
+	Headers                map[string]string `json:"headers,omitempty"`
+	DropParams             []string          `json:"drop_params,omitempty"`          // 发送前从请求体删除的参数路径，如 reasoning、stream_options.include_usage
+	BodyOverrides          map[string]any    `json:"body_overrides,omitempty"`       // 合并进请求体的固定字段，如 service_tier
+	BodyForce              bool              `json:"body_force,omitempty"`           // true 时覆盖客户端同名字段
+	IncludeReasoning       bool              `json:"include_reasoning,omitempty"`    // Responses 请求的 include 中加入 reasoning.encrypted_content
+	ReasoningSummary       string            `json:"reasoning_summary,omitempty"`    // Responses 请求未指定时写入 reasoning.summary（auto/concise/detailed）
+	InsecureSkipVerify     bool              `json:"insecure_skip_verify,omitempty"` // 跳过上游 TLS 证书校验（不安全），仅用于自签名证书的自建网关
+	ForwardClientIP        bool              `json:"forward_client_ip,omitempty"`    // 在上游请求中带上客户端真实 IP（X-Forwarded-For / X-Real-IP）
+	CAFile                 string            `json:"ca_file,omitempty"`              // PEM 格式的 CA 证书文件，配置后只信任其中的证书
+	AnthropicVersion       string            `json:"anthropic_version,omitempty"`    // 目标为 claude 时固定上游 anthropic-version 头，覆盖客户端的值
+	AnthropicBeta          string            `json:"anthropic_beta,omitempty"`       // 目标为 claude 时固定上游 anthropic-beta 头（逗号分隔），覆盖客户端的值
+	DailyTokenBudget       int64             `json:"daily_token_budget,omitempty"`   // 每日 token 预算（输入+输出），当天用尽后路由跳过该端点（0 表示不限制）
+	MaxTokensCap           int               `json:"max_tokens_cap,omitempty"`       // 请求的最大输出 token 数上限，超过时下调（0 表示不限制）
+	ResponseValidation     []string          `json:"response_validation,omitempty"`  // 非流式响应必须包含的顶层字段，缺失时视为上游异常并故障转移
+	VendorHeaders          map[string]string `json:"vendor_headers,omitempty"`       // 供应商默认 headers，端点 Headers 优先
 }
 
 // ModelMapping 模型映射配置
 type ModelMapping struct {
-	Name  string `json:"name"`  // 实际模型名（上游模型名）
-	Alias string `json:"alias"` // API 使用的别名（客户端传入的 model）
+	Name      string `json:"name"`                // 实际模型名（上游模型名）
+	Alias     string `json:"alias"`               // API 使用的别名（客户端传入的 model）
+	MatchType string `json:"matchType,omitempty"` // 别名匹配方式: exact(默认) | prefix | regex
 }
 
 // Executor 定义执行器接口
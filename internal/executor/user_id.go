@@ -0,0 +1,40 @@
+package executor
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// maxUserIDLen 限制用户标识长度，避免异常请求写入超长字符串
+const maxUserIDLen = 256
+
+// ExtractUserID 从请求体中提取请求方用户标识，用于按用户统计用量：
+// Claude 使用 metadata.user_id，OpenAI（Chat/Responses）使用 user 字段。
+// Claude Code 的 user_id 形如 "user_<hash>_account_<uuid>_session_<uuid>"，
+// 这里去掉会话后缀，使同一用户的多个会话归到一起。
+func ExtractUserID(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	var req struct {
+		Metadata struct {
+			UserID string `json:"user_id"`
+		} `json:"metadata"`
+		User string `json:"user"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return ""
+	}
+
+	userID := strings.TrimSpace(req.Metadata.UserID)
+	if userID == "" {
+		userID = strings.TrimSpace(req.User)
+	}
+	if i := strings.Index(userID, "_session_"); i > 0 {
+		userID = userID[:i]
+	}
+	if len(userID) > maxUserIDLen {
+		userID = userID[:maxUserIDLen]
+	}
+	return userID
+}
@@ -0,0 +1,24 @@
+package executor
+
+import "testing"
+
+func TestExtractUserID(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		body string
+		want string
+	}{
+		{body: `{"metadata":{"user_id":"alice"}}`, want: "alice"},
+		{body: `{"metadata":{"user_id":"user_abc_account_123_session_456"}}`, want: "user_abc_account_123"},
+		{body: `{"user":" bob "}`, want: "bob"},
+		{body: `{"metadata":{"user_id":"alice"},"user":"bob"}`, want: "alice"},
+		{body: `{"model":"m"}`, want: ""},
+		{body: `not json`, want: ""},
+	}
+	for _, tc := range cases {
+		if got := ExtractUserID([]byte(tc.body)); got != tc.want {
+			t.Fatalf("ExtractUserID(%s)=%q want %q", tc.body, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// warmupTimeout 预热请求的超时时间
+const warmupTimeout = 10 * time.Second
+
+// Warmup 向端点的 API 地址发送一个 HEAD 请求以提前建立连接（DNS、TCP、TLS），
+// 连接经共享 Transport 进入连接池供后续真实请求复用。不携带鉴权、不消耗 token，
+// 任何状态码都视为连接已建立，只有网络错误返回 error。
+func Warmup(ctx context.Context, endpoint *EndpointConfig) error {
+	if endpoint == nil || strings.TrimSpace(endpoint.APIURL) == "" {
+		return fmt.Errorf("endpoint has no api url")
+	}
+	target, err := url.Parse(strings.TrimSpace(endpoint.APIURL))
+	if err != nil || target.Host == "" {
+		return fmt.Errorf("invalid api url: %s", endpoint.APIURL)
+	}
+	targetURL := target.Scheme + "://" + target.Host + "/"
+	if err := checkProxyLoop(targetURL); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, warmupTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, targetURL, nil)
+	if err != nil {
+		return err
+	}
+	markForwarded(req)
+
+	resp, err := NewHTTPClient(endpoint, warmupTimeout).Do(req)
+	if err != nil {
+		return err
+	}
+	// 读完并关闭响应体，连接才会放回连接池
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,33 @@
+package executor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWarmup(t *testing.T) {
+	t.Parallel()
+
+	var heads int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead && r.URL.Path == "/" && r.Header.Get(ForwardedHeader) != "" {
+			atomic.AddInt32(&heads, 1)
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	if err := Warmup(context.Background(), &EndpointConfig{APIURL: srv.URL + "/api/v1"}); err != nil {
+		t.Fatalf("Warmup: %v", err)
+	}
+	if got := atomic.LoadInt32(&heads); got != 1 {
+		t.Fatalf("HEAD requests = %d, want 1", got)
+	}
+
+	if err := Warmup(context.Background(), &EndpointConfig{APIURL: "not a url"}); err == nil {
+		t.Fatalf("expected error for invalid api url")
+	}
+}
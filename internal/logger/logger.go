@@ -8,6 +8,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 )
@@ -42,6 +43,22 @@ func (l LogLevel) String() string {
 	}
 }
 
+// ParseLevel parses a level name (debug, info, warn/warning, error), case-insensitively
+func ParseLevel(name string) (LogLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	default:
+		return LevelInfo, fmt.Errorf("invalid log level %q: expected debug, info, warn or error", name)
+	}
+}
+
 // Logger provides structured logging for the application
 // Requirements: 5.3
 type Logger struct {
@@ -194,6 +211,11 @@ func SetDefaultLevel(level LogLevel) {
 	defaultLogger.SetLevel(level)
 }
 
+// GetDefaultLevel returns the log level of the default logger
+func GetDefaultLevel() LogLevel {
+	return defaultLogger.GetLevel()
+}
+
 // SetDefaultOutput sets the output for the default logger
 func SetDefaultOutput(output io.Writer) {
 	defaultLogger.SetOutput(output)
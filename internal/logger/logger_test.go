@@ -0,0 +1,27 @@
+package logger
+
+import "testing"
+
+func TestParseLevel(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		want    LogLevel
+		wantErr bool
+	}{
+		{name: "debug", want: LevelDebug},
+		{name: " INFO ", want: LevelInfo},
+		{name: "warn", want: LevelWarn},
+		{name: "Warning", want: LevelWarn},
+		{name: "error", want: LevelError},
+		{name: "", want: LevelInfo, wantErr: true},
+		{name: "trace", want: LevelInfo, wantErr: true},
+	}
+	for _, tc := range cases {
+		got, err := ParseLevel(tc.name)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Fatalf("%q: got %s err=%v want %s wantErr=%v", tc.name, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrRequestCancelled is the cancel cause of requests aborted via CancelRequest
+var ErrRequestCancelled = errors.New("request cancelled by user")
+
+// ErrRequestNotActive is returned when cancelling a request that is not in flight
+var ErrRequestNotActive = errors.New("request not active")
+
+const (
+	// statusCancelled is the request log status of requests aborted via CancelRequest
+	statusCancelled = "cancelled"
+	// statusClientClosedRequest is the (nginx-style) status returned for cancelled requests
+	statusClientClosedRequest = 499
+)
+
+// trackRequest registers an in-flight request so it can be cancelled by ID.
+// The returned function must be called when the request finishes.
+func (p *ProxyServer) trackRequest(ctx context.Context, requestID string) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	p.mu.Lock()
+	if p.activeRequests == nil {
+		p.activeRequests = make(map[string]context.CancelCauseFunc)
+	}
+	p.activeRequests[requestID] = cancel
+	p.mu.Unlock()
+
+	return ctx, func() {
+		p.mu.Lock()
+		delete(p.activeRequests, requestID)
+		p.mu.Unlock()
+		cancel(nil)
+	}
+}
+
+// CancelRequest aborts an in-flight request, tearing down its upstream connection
+func (p *ProxyServer) CancelRequest(requestID string) error {
+	p.mu.RLock()
+	cancel := p.activeRequests[requestID]
+	p.mu.RUnlock()
+	if cancel == nil {
+		return ErrRequestNotActive
+	}
+	cancel(ErrRequestCancelled)
+	return nil
+}
+
+// ActiveRequestIDs returns the IDs of requests currently in flight
+func (p *ProxyServer) ActiveRequestIDs() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	ids := make([]string, 0, len(p.activeRequests))
+	for id := range p.activeRequests {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func isRequestCancelled(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrRequestCancelled)
+}
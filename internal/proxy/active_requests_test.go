@@ -0,0 +1,79 @@
+package proxy
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCancelRequest(t *testing.T) {
+	t.Parallel()
+
+	upstreamDone := make(chan struct{}, 1)
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the server only notices a closed connection once the body has been read
+		_, _ = io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+		upstreamDone <- struct{}{}
+	}))
+	defer slow.Close()
+	var fallbackHits atomic.Int32
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fallbackHits.Add(1)
+	}))
+	defer fallback.Close()
+
+	router := NewRouter()
+	router.LoadEndpoints([]*Endpoint{
+		{ID: 1, Name: "slow", APIURL: slow.URL, InterfaceType: "claude", Enabled: true, Active: true},
+		{ID: 2, Name: "fallback", APIURL: fallback.URL, InterfaceType: "claude", Enabled: true},
+	})
+	p := NewProxyServer(0, router)
+	p.SetFallbackEnabled(true)
+
+	done := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		w := httptest.NewRecorder()
+		p.handleProxy(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"m","messages":[]}`)))
+		done <- w
+	}()
+	waitFor(t, "request in flight", func() bool { return len(p.ActiveRequestIDs()) == 1 })
+	id := p.ActiveRequestIDs()[0]
+
+	if err := p.CancelRequest(id); err != nil {
+		t.Fatalf("CancelRequest: %v", err)
+	}
+	var w *httptest.ResponseRecorder
+	select {
+	case w = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("cancelled request did not return")
+	}
+	select {
+	case <-upstreamDone:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("upstream connection not torn down")
+	}
+
+	if w.Code != statusClientClosedRequest {
+		t.Fatalf("status=%d want %d body=%s", w.Code, statusClientClosedRequest, w.Body.String())
+	}
+	if fallbackHits.Load() != 0 {
+		t.Fatalf("cancelled request failed over to another endpoint")
+	}
+	logs := p.stats.GetRecentLogs(0)
+	if len(logs) == 0 || logs[len(logs)-1].ID != id || logs[len(logs)-1].Status != statusCancelled {
+		t.Fatalf("log does not record the cancellation: %+v", logs)
+	}
+	if ids := p.ActiveRequestIDs(); len(ids) != 0 {
+		t.Fatalf("finished request still tracked: %v", ids)
+	}
+	if err := p.CancelRequest(id); !errors.Is(err, ErrRequestNotActive) {
+		t.Fatalf("second cancel err=%v want ErrRequestNotActive", err)
+	}
+}
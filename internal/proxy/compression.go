@@ -0,0 +1,97 @@
+package proxy
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strings"
+)
+
+// minCompressBytes is the smallest response body worth compressing
+const minCompressBytes = 1024
+
+// SetCompressResponses enables gzip compression of non-streaming responses for clients
+// that send Accept-Encoding: gzip
+func (p *ProxyServer) SetCompressResponses(enabled bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.compressResponses = enabled
+}
+
+// IsCompressResponsesEnabled returns whether non-streaming responses may be gzip-compressed
+func (p *ProxyServer) IsCompressResponsesEnabled() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.compressResponses
+}
+
+// writeClientResponse writes a non-streaming response, gzip-compressing the body when
+// enabled and accepted by the client. Tokens are already extracted from the plain body.
+func (p *ProxyServer) writeClientResponse(w http.ResponseWriter, r *http.Request, statusCode int, headers http.Header, body []byte) {
+	if p.IsCompressResponsesEnabled() && shouldCompressResponse(r, headers, body) {
+		if compressed, ok := gzipBytes(body); ok {
+			headers = headers.Clone()
+			if headers == nil {
+				headers = http.Header{}
+			}
+			headers.Set("Content-Encoding", "gzip")
+			headers.Add("Vary", "Accept-Encoding")
+			body = compressed
+		}
+	}
+	writeResponseWithHeaders(w, statusCode, headers, body)
+}
+
+func shouldCompressResponse(r *http.Request, headers http.Header, body []byte) bool {
+	if len(body) < minCompressBytes || !acceptsGzip(r) {
+		return false
+	}
+	if headers.Get("Content-Encoding") != "" {
+		return false
+	}
+	return !isCompressedContentType(headers.Get("Content-Type"))
+}
+
+func acceptsGzip(r *http.Request) bool {
+	if r == nil {
+		return false
+	}
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			continue
+		}
+		// "gzip;q=0" explicitly refuses gzip
+		return strings.ReplaceAll(strings.TrimSpace(params), " ", "") != "q=0"
+	}
+	return false
+}
+
+// isCompressedContentType reports whether compressing the content type gains nothing
+func isCompressedContentType(contentType string) bool {
+	ct := strings.ToLower(strings.TrimSpace(contentType))
+	if ct == "" {
+		return false
+	}
+	if strings.HasPrefix(ct, "image/") || strings.HasPrefix(ct, "audio/") || strings.HasPrefix(ct, "video/") {
+		return true
+	}
+	for _, t := range []string{"application/zip", "application/gzip", "application/x-gzip", "application/octet-stream", "application/pdf"} {
+		if strings.HasPrefix(ct, t) {
+			return true
+		}
+	}
+	return false
+}
+
+func gzipBytes(body []byte) ([]byte, bool) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(body); err != nil {
+		return nil, false
+	}
+	if err := zw.Close(); err != nil {
+		return nil, false
+	}
+	return buf.Bytes(), true
+}
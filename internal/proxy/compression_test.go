@@ -0,0 +1,98 @@
+package proxy
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAcceptsGzip(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		header string
+		want   bool
+	}{
+		{header: "", want: false},
+		{header: "gzip", want: true},
+		{header: "br, GZIP;q=0.5", want: true},
+		{header: "deflate, br", want: false},
+		{header: "gzip;q=0", want: false},
+		{header: "gzip; q=0", want: false},
+		{header: "x-gzip", want: false},
+	}
+	for _, tc := range cases {
+		r := httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+		r.Header.Set("Accept-Encoding", tc.header)
+		if got := acceptsGzip(r); got != tc.want {
+			t.Fatalf("Accept-Encoding %q: got %v want %v", tc.header, got, tc.want)
+		}
+	}
+}
+
+func TestWriteClientResponseCompression(t *testing.T) {
+	t.Parallel()
+
+	large := `{"text":"` + strings.Repeat("a", 2*minCompressBytes) + `"}`
+	cases := []struct {
+		name        string
+		enabled     bool
+		accept      string
+		contentType string
+		encoding    string
+		body        string
+		wantGzip    bool
+	}{
+		{name: "compressed", enabled: true, accept: "gzip", contentType: "application/json", body: large, wantGzip: true},
+		{name: "disabled", enabled: false, accept: "gzip", contentType: "application/json", body: large},
+		{name: "client does not accept gzip", enabled: true, contentType: "application/json", body: large},
+		{name: "small body", enabled: true, accept: "gzip", contentType: "application/json", body: `{"a":1}`},
+		{name: "already encoded", enabled: true, accept: "gzip", contentType: "application/json", encoding: "br", body: large},
+		{name: "binary content", enabled: true, accept: "gzip", contentType: "image/png", body: large},
+	}
+	for _, tc := range cases {
+		p := NewProxyServer(0, NewRouter())
+		p.SetCompressResponses(tc.enabled)
+		r := httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+		if tc.accept != "" {
+			r.Header.Set("Accept-Encoding", tc.accept)
+		}
+		headers := http.Header{"Content-Type": {tc.contentType}, "Content-Length": {"999"}}
+		if tc.encoding != "" {
+			headers.Set("Content-Encoding", tc.encoding)
+		}
+
+		w := httptest.NewRecorder()
+		p.writeClientResponse(w, r, http.StatusOK, headers, []byte(tc.body))
+
+		if headers.Get("Content-Encoding") != tc.encoding {
+			t.Fatalf("%s: caller headers modified: %v", tc.name, headers)
+		}
+		if w.Header().Get("Content-Length") != "" {
+			t.Fatalf("%s: stale Content-Length forwarded", tc.name)
+		}
+		got := w.Body.String()
+		if tc.wantGzip {
+			if w.Header().Get("Content-Encoding") != "gzip" || w.Header().Get("Vary") != "Accept-Encoding" {
+				t.Fatalf("%s: headers=%v", tc.name, w.Header())
+			}
+			zr, err := gzip.NewReader(w.Body)
+			if err != nil {
+				t.Fatalf("%s: gzip reader: %v", tc.name, err)
+			}
+			plain, err := io.ReadAll(zr)
+			if err != nil {
+				t.Fatalf("%s: decompress: %v", tc.name, err)
+			}
+			got = string(plain)
+		} else if w.Header().Get("Content-Encoding") != tc.encoding {
+			t.Fatalf("%s: Content-Encoding=%q want %q", tc.name, w.Header().Get("Content-Encoding"), tc.encoding)
+		}
+		if got != tc.body {
+			t.Fatalf("%s: body mismatch (%d bytes, want %d)", tc.name, len(got), len(tc.body))
+		}
+	}
+}
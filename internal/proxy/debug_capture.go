@@ -0,0 +1,181 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"clisimplehub/internal/executor"
+	"clisimplehub/internal/logger"
+)
+
+const (
+	// maxDebugTraces caps how many captured traces are retained
+	maxDebugTraces = 100
+	// maxDebugTraceSteps caps the steps recorded for a single request
+	maxDebugTraceSteps = 500
+)
+
+// DebugTraceStep is one executor decision recorded for a captured request
+type DebugTraceStep struct {
+	OffsetMs int64  `json:"offsetMs"`
+	Level    int    `json:"level"`
+	Message  string `json:"message"`
+}
+
+// DebugTrace is the step-by-step record of one captured request
+type DebugTrace struct {
+	RequestID     string           `json:"requestId"`
+	Method        string           `json:"method"`
+	Path          string           `json:"path"`
+	InterfaceType string           `json:"interfaceType"`
+	Endpoint      string           `json:"endpoint"`
+	StartTime     time.Time        `json:"startTime"`
+	DurationMs    int64            `json:"durationMs"`
+	Status        string           `json:"status"`
+	Steps         []DebugTraceStep `json:"steps"`
+}
+
+// debugCapture arms tracing for the next N requests and buffers their traces
+type debugCapture struct {
+	mu        sync.Mutex
+	remaining int
+	traces    []*DebugTrace
+	active    map[string]*DebugTrace // in-flight traces by request ID
+}
+
+// EnableDebugCapture records detailed executor decisions for the next count requests,
+// then disables itself. Previously captured traces are discarded; count <= 0 disables capture.
+func (p *ProxyServer) EnableDebugCapture(count int) {
+	c := &p.debugCapture
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remaining = max(count, 0)
+	c.traces = nil
+	c.active = nil
+	if count > 0 {
+		logger.Info("[Proxy] debug capture enabled for the next %d requests", count)
+	}
+}
+
+// GetDebugTraces returns copies of the captured traces, oldest first
+func (p *ProxyServer) GetDebugTraces() []DebugTrace {
+	c := &p.debugCapture
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]DebugTrace, 0, len(c.traces))
+	for _, trace := range c.traces {
+		copied := *trace
+		copied.Steps = append([]DebugTraceStep(nil), trace.Steps...)
+		out = append(out, copied)
+	}
+	return out
+}
+
+// beginDebugTrace claims a capture slot for the request when capture is armed. It returns the
+// context with executor tracing enabled and a func that finalizes the trace with the request status.
+func (p *ProxyServer) beginDebugTrace(ctx context.Context, requestID string, r *http.Request, interfaceType InterfaceType, endpoint *executor.EndpointConfig, pinned bool) (context.Context, func(status string)) {
+	c := &p.debugCapture
+	c.mu.Lock()
+	if c.remaining <= 0 {
+		c.mu.Unlock()
+		return ctx, func(string) {}
+	}
+	c.remaining--
+	trace := &DebugTrace{
+		RequestID:     requestID,
+		Method:        r.Method,
+		Path:          r.URL.Path,
+		InterfaceType: string(interfaceType),
+		Endpoint:      endpoint.Name,
+		StartTime:     time.Now(),
+	}
+	if len(c.traces) >= maxDebugTraces {
+		c.traces = c.traces[1:]
+	}
+	c.traces = append(c.traces, trace)
+	if c.active == nil {
+		c.active = make(map[string]*DebugTrace)
+	}
+	c.active[requestID] = trace
+	remaining := c.remaining
+	c.mu.Unlock()
+
+	transformer := strings.TrimSpace(endpoint.Transformer)
+	if transformer == "" {
+		transformer = "none"
+	}
+	p.recordDebugTraceStep(requestID, 1, fmt.Sprintf("[Trace] selected endpoint=%s interface=%s transformer=%s pinned=%v url=%s",
+		endpoint.Name, interfaceType, transformer, pinned, endpoint.APIURL))
+	if remaining == 0 {
+		logger.Info("[Proxy] debug capture finished, disabling")
+	}
+
+	return executor.WithTrace(ctx), func(status string) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		trace.Status = status
+		trace.DurationMs = time.Since(trace.StartTime).Milliseconds()
+		delete(c.active, requestID)
+	}
+}
+
+// recordDebugTraceStep appends a debug log line to the request's trace if it is being captured
+func (p *ProxyServer) recordDebugTraceStep(requestID string, level int, message string) {
+	c := &p.debugCapture
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	trace := c.active[requestID]
+	if trace == nil || len(trace.Steps) >= maxDebugTraceSteps {
+		return
+	}
+	trace.Steps = append(trace.Steps, DebugTraceStep{
+		OffsetMs: time.Since(trace.StartTime).Milliseconds(),
+		Level:    level,
+		Message:  message,
+	})
+}
+
+// handleDebugTraces lists captured traces or arms capture for the next N requests.
+// It requires the same auth key as proxied requests.
+//
+// GET /debug-traces
+// - {"traces":[...]}
+//
+// POST /debug-traces?count=5
+// - {"count":5}
+func (p *ProxyServer) handleDebugTraces(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	writeError := func(status int, msg string) {
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(map[string]any{"error": msg})
+	}
+
+	if !p.authorizeAdmin(r) {
+		writeError(http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(map[string]any{"traces": p.GetDebugTraces()})
+	case http.MethodPost:
+		count, err := strconv.Atoi(strings.TrimSpace(r.URL.Query().Get("count")))
+		if err != nil || count < 0 {
+			writeError(http.StatusBadRequest, "count must be a non-negative integer")
+			return
+		}
+		p.EnableDebugCapture(count)
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(map[string]any{"count": count})
+	default:
+		writeError(http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
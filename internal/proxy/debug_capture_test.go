@@ -0,0 +1,140 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"clisimplehub/internal/executor"
+)
+
+func beginTestTrace(p *ProxyServer, requestID string) func(string) {
+	r := httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	_, end := p.beginDebugTrace(context.Background(), requestID, r, InterfaceTypeClaude, &executor.EndpointConfig{Name: "a"}, false)
+	return end
+}
+
+func TestDebugCaptureCount(t *testing.T) {
+	t.Parallel()
+
+	p := NewProxyServer(0, NewRouter())
+	beginTestTrace(p, "before")("success")
+	if len(p.GetDebugTraces()) != 0 {
+		t.Fatalf("traced while capture was off")
+	}
+
+	p.EnableDebugCapture(2)
+	for i := 0; i < 3; i++ {
+		id := fmt.Sprintf("req-%d", i)
+		end := beginTestTrace(p, id)
+		p.recordDebugTraceStep(id, 1, "attempt 1")
+		end("success")
+	}
+	traces := p.GetDebugTraces()
+	if len(traces) != 2 || traces[0].RequestID != "req-0" || traces[1].RequestID != "req-1" {
+		t.Fatalf("traces=%+v want req-0 and req-1", traces)
+	}
+	// the selection step plus the recorded one
+	if got := traces[0]; got.Status != "success" || got.Endpoint != "a" || len(got.Steps) != 2 || got.Steps[1].Message != "attempt 1" {
+		t.Fatalf("trace=%+v", got)
+	}
+
+	// Returned traces are copies
+	traces[0].Steps[0].Message = "changed"
+	if p.GetDebugTraces()[0].Steps[0].Message == "changed" {
+		t.Fatalf("GetDebugTraces exposed internal state")
+	}
+
+	// Re-arming discards earlier traces
+	p.EnableDebugCapture(1)
+	if len(p.GetDebugTraces()) != 0 {
+		t.Fatalf("traces kept after re-arming")
+	}
+}
+
+func TestDebugCaptureRingBuffer(t *testing.T) {
+	t.Parallel()
+
+	p := NewProxyServer(0, NewRouter())
+	total := maxDebugTraces + 5
+	p.EnableDebugCapture(total)
+	for i := 0; i < total; i++ {
+		beginTestTrace(p, fmt.Sprintf("req-%d", i))("success")
+	}
+	traces := p.GetDebugTraces()
+	if len(traces) != maxDebugTraces {
+		t.Fatalf("kept %d traces want %d", len(traces), maxDebugTraces)
+	}
+	if traces[0].RequestID != "req-5" || traces[len(traces)-1].RequestID != fmt.Sprintf("req-%d", total-1) {
+		t.Fatalf("oldest=%s newest=%s, want the oldest evicted first", traces[0].RequestID, traces[len(traces)-1].RequestID)
+	}
+}
+
+func TestDebugCaptureStepLimit(t *testing.T) {
+	t.Parallel()
+
+	p := NewProxyServer(0, NewRouter())
+	p.EnableDebugCapture(1)
+	end := beginTestTrace(p, "req")
+	for i := 0; i < maxDebugTraceSteps+10; i++ {
+		p.recordDebugTraceStep("req", 1, "step")
+	}
+	end("success")
+	// steps after the request finished are not recorded
+	p.recordDebugTraceStep("req", 1, "late")
+	if steps := p.GetDebugTraces()[0].Steps; len(steps) != maxDebugTraceSteps || steps[len(steps)-1].Message == "late" {
+		t.Fatalf("steps=%d want %d", len(steps), maxDebugTraceSteps)
+	}
+}
+
+func TestHandleDebugTraces(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name       string
+		method     string
+		query      string
+		auth       string
+		wantStatus int
+		wantCount  int
+	}{
+		{name: "unauthorized", method: http.MethodGet, wantStatus: http.StatusUnauthorized},
+		{name: "list", method: http.MethodGet, auth: "admin", wantStatus: http.StatusOK},
+		{name: "arm", method: http.MethodPost, query: "count=3", auth: "admin", wantStatus: http.StatusOK, wantCount: 3},
+		{name: "negative count", method: http.MethodPost, query: "count=-1", auth: "admin", wantStatus: http.StatusBadRequest},
+		{name: "missing count", method: http.MethodPost, auth: "admin", wantStatus: http.StatusBadRequest},
+		{name: "method", method: http.MethodDelete, auth: "admin", wantStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tc := range cases {
+		p := NewProxyServer(0, NewRouter())
+		p.SetAuthKey("admin")
+		r := httptest.NewRequest(tc.method, "/debug-traces?"+tc.query, nil)
+		if tc.auth != "" {
+			r.Header.Set("Authorization", "Bearer "+tc.auth)
+		}
+		w := httptest.NewRecorder()
+		p.handleDebugTraces(w, r)
+		if w.Code != tc.wantStatus {
+			t.Fatalf("%s: status=%d want %d body=%s", tc.name, w.Code, tc.wantStatus, w.Body.String())
+		}
+		if tc.wantCount > 0 && p.debugCapture.remaining != tc.wantCount {
+			t.Fatalf("%s: capture armed for %d want %d", tc.name, p.debugCapture.remaining, tc.wantCount)
+		}
+	}
+
+	// GET returns captured traces
+	p := NewProxyServer(0, NewRouter())
+	p.EnableDebugCapture(1)
+	beginTestTrace(p, "req")("success")
+	w := httptest.NewRecorder()
+	p.handleDebugTraces(w, httptest.NewRequest(http.MethodGet, "/debug-traces", nil))
+	var body struct {
+		Traces []DebugTrace `json:"traces"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || len(body.Traces) != 1 || body.Traces[0].RequestID != "req" {
+		t.Fatalf("body=%s err=%v", w.Body.String(), err)
+	}
+}
@@ -0,0 +1,175 @@
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Detection rule match types
+const (
+	DetectionMatchPrefix   = "prefix"
+	DetectionMatchSuffix   = "suffix"
+	DetectionMatchContains = "contains"
+	DetectionMatchRegex    = "regex"
+)
+
+// DetectionRule maps request paths to an interface type. Custom rules are
+// consulted in order before the built-in detection logic.
+type DetectionRule struct {
+	PathPattern   string `json:"pathPattern"`
+	MatchType     string `json:"matchType"`
+	InterfaceType string `json:"interfaceType"`
+}
+
+// compiledDetectionRule is a DetectionRule normalized for matching
+type compiledDetectionRule struct {
+	matchType     string
+	pattern       string // lowercased for non-regex match types
+	re            *regexp.Regexp
+	interfaceType InterfaceType
+}
+
+func (c *compiledDetectionRule) matches(path, lowerPath string) bool {
+	switch c.matchType {
+	case DetectionMatchSuffix:
+		return strings.HasSuffix(lowerPath, c.pattern)
+	case DetectionMatchContains:
+		return strings.Contains(lowerPath, c.pattern)
+	case DetectionMatchRegex:
+		return c.re.MatchString(path)
+	default:
+		return strings.HasPrefix(lowerPath, c.pattern)
+	}
+}
+
+// ParseDetectionRules converts the raw appConfig "interfaceDetectionRules" value into rules.
+// Accepts either a JSON array (as decoded from config.json) or a JSON string.
+func ParseDetectionRules(raw interface{}) ([]DetectionRule, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	var data []byte
+	switch v := raw.(type) {
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return nil, nil
+		}
+		data = []byte(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		data = b
+	}
+
+	var rules []DetectionRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("invalid interfaceDetectionRules: %w", err)
+	}
+	return rules, nil
+}
+
+func compileDetectionRules(rules []DetectionRule) ([]compiledDetectionRule, error) {
+	compiled := make([]compiledDetectionRule, 0, len(rules))
+	for i, rule := range rules {
+		pattern := strings.TrimSpace(rule.PathPattern)
+		if pattern == "" {
+			continue
+		}
+
+		interfaceType := InterfaceType(strings.ToLower(strings.TrimSpace(rule.InterfaceType)))
+		switch interfaceType {
+		case InterfaceTypeClaude, InterfaceTypeCodex, InterfaceTypeGemini, InterfaceTypeChat:
+		default:
+			return nil, fmt.Errorf("invalid interfaceDetectionRules[%d] interfaceType %q", i, rule.InterfaceType)
+		}
+
+		c := compiledDetectionRule{
+			matchType:     strings.ToLower(strings.TrimSpace(rule.MatchType)),
+			interfaceType: interfaceType,
+		}
+		switch c.matchType {
+		case "":
+			c.matchType = DetectionMatchPrefix
+			c.pattern = strings.ToLower(pattern)
+		case DetectionMatchPrefix, DetectionMatchSuffix, DetectionMatchContains:
+			c.pattern = strings.ToLower(pattern)
+		case DetectionMatchRegex:
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid interfaceDetectionRules[%d] pathPattern %q: %w", i, rule.PathPattern, err)
+			}
+			c.re = re
+		default:
+			return nil, fmt.Errorf("invalid interfaceDetectionRules[%d] matchType %q", i, rule.MatchType)
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// SetDetectionRules replaces the custom interface-type detection rules.
+// Rules are evaluated in order; the first match wins.
+func (r *DefaultRouter) SetDetectionRules(rules []DetectionRule) error {
+	compiled, err := compileDetectionRules(rules)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.detectionRules = compiled
+	return nil
+}
+
+// detectByRules returns the interface type of the first matching custom rule
+func (r *DefaultRouter) detectByRules(path, lowerPath string) (InterfaceType, bool) {
+	r.mu.RLock()
+	rules := r.detectionRules
+	r.mu.RUnlock()
+
+	for i := range rules {
+		if rules[i].matches(path, lowerPath) {
+			return rules[i].interfaceType, true
+		}
+	}
+	return "", false
+}
+
+// UnknownPathReject makes DetectInterfaceType return "" for paths that neither a custom rule
+// nor the built-in detection recognizes, so the handler can answer 404
+const UnknownPathReject = "reject"
+
+// SetUnknownPathBehavior sets how unrecognized paths are routed: an interface type
+// ("" and "claude" keep the default) or UnknownPathReject
+func (r *DefaultRouter) SetUnknownPathBehavior(behavior string) error {
+	behavior = strings.ToLower(strings.TrimSpace(behavior))
+	switch InterfaceType(behavior) {
+	case "", InterfaceTypeClaude, InterfaceTypeCodex, InterfaceTypeGemini, InterfaceTypeChat, UnknownPathReject:
+	default:
+		return fmt.Errorf("invalid unknownPathBehavior %q (expected %s, an interface type or %s)", behavior, InterfaceTypeClaude, UnknownPathReject)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.unknownPathBehavior = behavior
+	return nil
+}
+
+// unknownPathType returns the interface type for an unrecognized path, "" when rejected
+func (r *DefaultRouter) unknownPathType() InterfaceType {
+	r.mu.RLock()
+	behavior := r.unknownPathBehavior
+	r.mu.RUnlock()
+
+	switch behavior {
+	case "":
+		return InterfaceTypeClaude
+	case UnknownPathReject:
+		return ""
+	default:
+		return InterfaceType(behavior)
+	}
+}
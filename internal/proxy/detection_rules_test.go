@@ -0,0 +1,179 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestParseDetectionRules(t *testing.T) {
+	t.Parallel()
+
+	want := []DetectionRule{{PathPattern: "/api/anthropic", MatchType: "prefix", InterfaceType: "claude"}}
+	cases := []struct {
+		name    string
+		raw     interface{}
+		want    []DetectionRule
+		wantErr bool
+	}{
+		{name: "nil", raw: nil},
+		{name: "blank string", raw: "  "},
+		{name: "json string", raw: `[{"pathPattern":"/api/anthropic","matchType":"prefix","interfaceType":"claude"}]`, want: want},
+		{name: "decoded config value", raw: []interface{}{map[string]interface{}{"pathPattern": "/api/anthropic", "matchType": "prefix", "interfaceType": "claude"}}, want: want},
+		{name: "not a list", raw: map[string]interface{}{"pathPattern": "/x"}, wantErr: true},
+	}
+	for _, tc := range cases {
+		got, err := ParseDetectionRules(tc.raw)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("%s: err=%v wantErr=%v", tc.name, err, tc.wantErr)
+		}
+		if !tc.wantErr && !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("%s: got %+v want %+v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestSetDetectionRulesValidation(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		rule    DetectionRule
+		wantErr string
+	}{
+		{name: "unknown interface type", rule: DetectionRule{PathPattern: "/x", InterfaceType: "openai"}, wantErr: "interfaceType"},
+		{name: "unknown match type", rule: DetectionRule{PathPattern: "/x", MatchType: "glob", InterfaceType: "chat"}, wantErr: "matchType"},
+		{name: "bad regex", rule: DetectionRule{PathPattern: "(", MatchType: "regex", InterfaceType: "chat"}, wantErr: "pathPattern"},
+		{name: "blank pattern skipped", rule: DetectionRule{PathPattern: " ", InterfaceType: "bogus"}},
+	}
+	for _, tc := range cases {
+		router := NewRouter()
+		if err := router.SetDetectionRules([]DetectionRule{{PathPattern: "/keep", InterfaceType: "gemini"}}); err != nil {
+			t.Fatalf("%s: initial rules: %v", tc.name, err)
+		}
+		err := router.SetDetectionRules([]DetectionRule{tc.rule})
+		if tc.wantErr == "" {
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", tc.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Fatalf("%s: err=%v want %q", tc.name, err, tc.wantErr)
+		}
+		// invalid rules leave the previous ones in place
+		if got := router.DetectInterfaceType("/keep"); got != InterfaceTypeGemini {
+			t.Fatalf("%s: previous rules replaced, /keep detected as %s", tc.name, got)
+		}
+	}
+}
+
+func TestDetectInterfaceTypeWithRules(t *testing.T) {
+	t.Parallel()
+
+	router := NewRouter()
+	err := router.SetDetectionRules([]DetectionRule{
+		{PathPattern: "/API/Anthropic", InterfaceType: "claude"},
+		{PathPattern: "/deepseek/v1/chat/completions", MatchType: "suffix", InterfaceType: "Codex"},
+		{PathPattern: "/qwen/", MatchType: "contains", InterfaceType: "chat"},
+		{PathPattern: `^/v\d+/generate$`, MatchType: "regex", InterfaceType: "gemini"},
+		{PathPattern: "/api", InterfaceType: "chat"},
+	})
+	if err != nil {
+		t.Fatalf("SetDetectionRules: %v", err)
+	}
+
+	cases := []struct {
+		path string
+		want InterfaceType
+	}{
+		{path: "/api/anthropic/v1/messages", want: InterfaceTypeClaude},     // prefix, case-insensitive; first match wins over /api
+		{path: "/x/deepseek/v1/chat/completions", want: InterfaceTypeCodex}, // rules win over the built-in chat detection
+		{path: "/v1/qwen/messages", want: InterfaceTypeChat},
+		{path: "/v2/generate", want: InterfaceTypeGemini},
+		{path: "/v2/GENERATE", want: InterfaceTypeClaude}, // regex matches the original case and falls through
+		{path: "/api/other", want: InterfaceTypeChat},
+		{path: "/v1/responses", want: InterfaceTypeCodex}, // no rule: built-in detection
+	}
+	for _, tc := range cases {
+		if got := router.DetectInterfaceType(tc.path); got != tc.want {
+			t.Fatalf("%s: got %s want %s", tc.path, got, tc.want)
+		}
+	}
+
+	// Clearing the rules restores the built-in detection
+	if err := router.SetDetectionRules(nil); err != nil {
+		t.Fatalf("clear rules: %v", err)
+	}
+	if got := router.DetectInterfaceType("/x/deepseek/v1/chat/completions"); got != InterfaceTypeChat {
+		t.Fatalf("after clearing: got %s want chat", got)
+	}
+}
+
+func TestUnknownPathBehavior(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		behavior string
+		wantErr  bool
+		want     InterfaceType
+	}{
+		{behavior: "", want: InterfaceTypeClaude},
+		{behavior: "claude", want: InterfaceTypeClaude},
+		{behavior: " Chat ", want: InterfaceTypeChat},
+		{behavior: "reject", want: ""},
+		{behavior: "drop", wantErr: true},
+	}
+	for _, tc := range cases {
+		router := NewRouter()
+		if err := router.SetDetectionRules([]DetectionRule{{PathPattern: "/custom", InterfaceType: "gemini"}}); err != nil {
+			t.Fatalf("%q: SetDetectionRules: %v", tc.behavior, err)
+		}
+		err := router.SetUnknownPathBehavior(tc.behavior)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("%q: err=%v wantErr=%v", tc.behavior, err, tc.wantErr)
+		}
+		if tc.wantErr {
+			continue
+		}
+		if got := router.DetectInterfaceType("/unknown/path"); got != tc.want {
+			t.Fatalf("%q: unknown path detected as %q want %q", tc.behavior, got, tc.want)
+		}
+		// recognized paths are not affected
+		for path, want := range map[string]InterfaceType{"/custom/x": InterfaceTypeGemini, "/v1/messages": InterfaceTypeClaude, "/v1/models": InterfaceTypeClaude} {
+			if got := router.DetectInterfaceType(path); got != want {
+				t.Fatalf("%q: %s detected as %q want %q", tc.behavior, path, got, want)
+			}
+		}
+	}
+}
+
+func TestHandleProxyRejectsUnknownPath(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer upstream.Close()
+
+	router := NewRouter()
+	router.LoadEndpoints([]*Endpoint{{ID: 1, Name: "a", APIURL: upstream.URL, InterfaceType: "claude", Enabled: true, Active: true}})
+	if err := router.SetUnknownPathBehavior(UnknownPathReject); err != nil {
+		t.Fatalf("SetUnknownPathBehavior: %v", err)
+	}
+	p := NewProxyServer(0, router)
+
+	w := httptest.NewRecorder()
+	p.handleProxy(w, httptest.NewRequest(http.MethodPost, "/favicon.ico", nil))
+	if w.Code != http.StatusNotFound || hits.Load() != 0 {
+		t.Fatalf("status=%d upstream hits=%d", w.Code, hits.Load())
+	}
+	logs := p.stats.GetRecentLogs(0)
+	if len(logs) != 1 || logs[0].Status != "error_404" {
+		t.Fatalf("logs=%+v", logs)
+	}
+}
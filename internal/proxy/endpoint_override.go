@@ -0,0 +1,78 @@
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"clisimplehub/internal/executor"
+)
+
+// EndpointOverrideHeader pins a single request to an endpoint, identified by ID or name
+const EndpointOverrideHeader = "X-CliHub-Endpoint-Id"
+
+// SetEndpointOverrideEnabled allows clients to select an endpoint via EndpointOverrideHeader
+func (p *ProxyServer) SetEndpointOverrideEnabled(enabled bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.endpointOverride = enabled
+}
+
+// IsEndpointOverrideEnabled returns whether the endpoint override header is honored
+func (p *ProxyServer) IsEndpointOverrideEnabled() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.endpointOverride
+}
+
+// resolveEndpointOverride consumes the override header and returns the requested
+// endpoint when overrides are enabled and the endpoint is enabled for the interface type.
+// The header is always stripped so it is never forwarded upstream.
+func (p *ProxyServer) resolveEndpointOverride(requestID string, r *http.Request, interfaceType InterfaceType) *executor.EndpointConfig {
+	value := strings.TrimSpace(r.Header.Get(EndpointOverrideHeader))
+	r.Header.Del(EndpointOverrideHeader)
+
+	p.mu.RLock()
+	enabled := p.endpointOverride
+	hub := p.wsHub
+	p.mu.RUnlock()
+	if value == "" || !enabled {
+		return nil
+	}
+
+	var selected *Endpoint
+	id, idErr := strconv.ParseInt(value, 10, 64)
+	for _, ep := range p.router.GetEndpointsByType(interfaceType) {
+		if !ep.isRoutable(time.Now()) {
+			continue
+		}
+		if (idErr == nil && ep.ID == id) || ep.Name == value {
+			selected = ep
+			break
+		}
+	}
+
+	if hub != nil {
+		payload := &DebugLogPayload{RequestID: requestID, Level: 1}
+		if selected != nil {
+			payload.Message = fmt.Sprintf("[EndpointOverride] %s=%q -> %s", EndpointOverrideHeader, value, endpointNameOrID(selected))
+		} else {
+			payload.Level = 2
+			payload.Message = fmt.Sprintf("[EndpointOverride] %s=%q: no enabled %s endpoint matches, using normal routing", EndpointOverrideHeader, value, interfaceType)
+		}
+		hub.BroadcastDebugLog(payload)
+	}
+	return toExecutorEndpointConfig(selected)
+}
+
+func endpointNameOrID(ep *Endpoint) string {
+	if ep == nil {
+		return ""
+	}
+	if ep.Name != "" {
+		return ep.Name
+	}
+	return strconv.FormatInt(ep.ID, 10)
+}
@@ -0,0 +1,115 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// writeProxyError writes an error generated by the proxy itself (not by upstream)
+// in the error shape the client protocol expects, so SDKs can parse it.
+func writeProxyError(w http.ResponseWriter, interfaceType InterfaceType, statusCode int, message string) {
+	var body interface{}
+	switch interfaceType {
+	case InterfaceTypeCodex, InterfaceTypeChat:
+		body = map[string]interface{}{
+			"error": map[string]interface{}{
+				"message": message,
+				"type":    openAIErrorType(statusCode),
+				"param":   nil,
+				"code":    openAIErrorCode(statusCode),
+			},
+		}
+	case InterfaceTypeGemini:
+		body = map[string]interface{}{
+			"error": map[string]interface{}{
+				"code":    statusCode,
+				"message": message,
+				"status":  geminiErrorStatus(statusCode),
+			},
+		}
+	default:
+		body = map[string]interface{}{
+			"type": "error",
+			"error": map[string]interface{}{
+				"type":    claudeErrorType(statusCode),
+				"message": message,
+			},
+		}
+	}
+
+	data, _ := json.Marshal(body)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(data)
+}
+
+// claudeErrorType maps a status code to an Anthropic error type
+func claudeErrorType(statusCode int) string {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return "invalid_request_error"
+	case http.StatusUnauthorized:
+		return "authentication_error"
+	case http.StatusForbidden:
+		return "permission_error"
+	case http.StatusNotFound:
+		return "not_found_error"
+	case http.StatusRequestEntityTooLarge:
+		return "request_too_large"
+	case http.StatusTooManyRequests:
+		return "rate_limit_error"
+	case http.StatusServiceUnavailable, 529:
+		return "overloaded_error"
+	default:
+		return "api_error"
+	}
+}
+
+// openAIErrorType maps a status code to an OpenAI error type
+func openAIErrorType(statusCode int) string {
+	switch {
+	case statusCode == http.StatusUnauthorized:
+		return "authentication_error"
+	case statusCode == http.StatusTooManyRequests:
+		return "rate_limit_error"
+	case statusCode >= 400 && statusCode < 500:
+		return "invalid_request_error"
+	default:
+		return "server_error"
+	}
+}
+
+// openAIErrorCode maps a status code to an OpenAI error code (nil when there is none)
+func openAIErrorCode(statusCode int) interface{} {
+	switch statusCode {
+	case http.StatusUnauthorized:
+		return "invalid_api_key"
+	case http.StatusTooManyRequests:
+		return "rate_limit_exceeded"
+	default:
+		return nil
+	}
+}
+
+// geminiErrorStatus maps a status code to a Google RPC status name
+func geminiErrorStatus(statusCode int) string {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return "INVALID_ARGUMENT"
+	case http.StatusUnauthorized:
+		return "UNAUTHENTICATED"
+	case http.StatusForbidden:
+		return "PERMISSION_DENIED"
+	case http.StatusNotFound:
+		return "NOT_FOUND"
+	case http.StatusTooManyRequests:
+		return "RESOURCE_EXHAUSTED"
+	case http.StatusServiceUnavailable:
+		return "UNAVAILABLE"
+	case http.StatusGatewayTimeout:
+		return "DEADLINE_EXCEEDED"
+	default:
+		return "INTERNAL"
+	}
+}
@@ -1,6 +1,7 @@
 package proxy
 
 import (
+	"maps"
 	"strings"
 	"time"
 
@@ -69,7 +70,7 @@ func (p *routerEndpointProvider) GetEndpointsByType(interfaceType string) []*exe
 	}
 	result := make([]*executor.EndpointConfig, 0, len(eps))
 	for _, ep := range eps {
-		if ep == nil || !ep.Enabled {
+		if !ep.isRoutable(time.Now()) {
 			continue
 		}
 		result = append(result, toExecutorEndpointConfig(ep))
@@ -116,7 +117,7 @@ func (p *routerEndpointProvider) FindNextUntried(interfaceType string, current *
 
 	for i := currentIdx + 1; i < len(eps); i++ {
 		ep := eps[i]
-		if ep == nil || !ep.Enabled {
+		if !ep.isAutoRoutable(time.Now()) {
 			continue
 		}
 		if exhausted[endpointKeyFromProxy(ep)] {
@@ -127,7 +128,7 @@ func (p *routerEndpointProvider) FindNextUntried(interfaceType string, current *
 
 	for i := 0; i < currentIdx; i++ {
 		ep := eps[i]
-		if ep == nil || !ep.Enabled {
+		if !ep.isAutoRoutable(time.Now()) {
 			continue
 		}
 		if exhausted[endpointKeyFromProxy(ep)] {
@@ -153,6 +154,14 @@ func (p *routerEndpointProvider) SetActiveEndpoint(interfaceType string, endpoin
 	return p.router.SetActiveEndpoint(InterfaceType(normalizeInterfaceType(interfaceType)), proxyEndpointFromConfig(endpoint))
 }
 
+// TodayTokenUsage returns the router's cached per-endpoint token usage for daily budgets
+func (p *routerEndpointProvider) TodayTokenUsage() map[int64]int64 {
+	if source, ok := p.router.(interface{ todayTokenUsage() map[int64]int64 }); ok {
+		return source.todayTokenUsage()
+	}
+	return nil
+}
+
 func normalizeInterfaceType(interfaceType string) string {
 	return strings.ToLower(strings.TrimSpace(interfaceType))
 }
@@ -169,17 +178,36 @@ func toExecutorEndpointConfig(ep *Endpoint) *executor.EndpointConfig {
 		return nil
 	}
 	return &executor.EndpointConfig{
-		ID:            ep.ID,
-		Name:          ep.Name,
-		APIURL:        ep.APIURL,
-		APIKey:        ep.APIKey,
-		InterfaceType: ep.InterfaceType,
-		Transformer:   ep.Transformer,
-		VendorID:      ep.VendorID,
-		Model:         ep.Model,
-		ProxyURL:      ep.ProxyURL,
-		Models:        toExecutorModelMappings(ep.Models),
-		Headers:       cloneStringMap(ep.Headers),
+		ID:                     ep.ID,
+		Name:                   ep.Name,
+		APIURL:                 ep.APIURL,
+		APIKey:                 ep.APIKey,
+		InterfaceType:          ep.InterfaceType,
+		Transformer:            ep.Transformer,
+		VendorID:               ep.VendorID,
+		Remark:                 ep.Remark,
+		Model:                  ep.Model,
+		ProxyURL:               ep.ProxyURL,
+		ProxyUsername:          ep.ProxyUsername,
+		ProxyPassword:          ep.ProxyPassword,
+		ForceNonStreamUpstream: ep.ForceNonStreamUpstream,
+		FallbackToDefaultModel: ep.FallbackToDefaultModel,
+		Models:                 toExecutorModelMappings(ep.Models),
+		Headers:                cloneStringMap(ep.Headers),
+		DropParams:             append([]string(nil), ep.DropParams...),
+		BodyOverrides:          maps.Clone(ep.BodyOverrides),
+		BodyForce:              ep.BodyForce,
+		IncludeReasoning:       ep.IncludeReasoning,
+		ReasoningSummary:       ep.ReasoningSummary,
+		InsecureSkipVerify:     ep.InsecureSkipVerify,
+		ForwardClientIP:        ep.ForwardClientIP,
+		CAFile:                 ep.CAFile,
+		AnthropicVersion:       ep.AnthropicVersion,
+		AnthropicBeta:          ep.AnthropicBeta,
+		DailyTokenBudget:       ep.DailyTokenBudget,
+		MaxTokensCap:           ep.MaxTokensCap,
+		ResponseValidation:     append([]string(nil), ep.ResponseValidation...),
+		VendorHeaders:          cloneStringMap(ep.VendorHeaders),
 	}
 }
 
@@ -189,7 +217,7 @@ func toExecutorModelMappings(models []ModelMapping) []executor.ModelMapping {
 	}
 	out := make([]executor.ModelMapping, 0, len(models))
 	for _, m := range models {
-		out = append(out, executor.ModelMapping{Name: m.Name, Alias: m.Alias})
+		out = append(out, executor.ModelMapping{Name: m.Name, Alias: m.Alias, MatchType: m.MatchType})
 	}
 	return out
 }
@@ -214,3 +242,8 @@ func endpointKeyFromProxy(ep *Endpoint) string {
 		Name: ep.Name,
 	})
 }
+
+// EndpointLastUsed returns the last time each endpoint was used since the proxy started
+func (p *ProxyServer) EndpointLastUsed() map[int64]time.Time {
+	return p.ensureExecutor().ctx.LastUsedSnapshot()
+}
@@ -31,11 +31,15 @@ func (o *proxyExecutionObserver) OnEndpointDisabled(interfaceType string, endpoi
 	if o == nil || o.server == nil {
 		return
 	}
-	o.server.broadcastEndpointTempDisabled(interfaceType, endpoint, until)
+	o.server.onEndpointDisabled(interfaceType, endpoint, until)
 }
 
 func (o *proxyExecutionObserver) OnDebugLog(requestID string, level int, message string) {
-	if o == nil || o.server == nil || o.server.wsHub == nil {
+	if o == nil || o.server == nil {
+		return
+	}
+	o.server.recordDebugTraceStep(strings.TrimSpace(requestID), level, strings.TrimSpace(message))
+	if o.server.wsHub == nil {
 		return
 	}
 	o.server.wsHub.BroadcastDebugLog(&DebugLogPayload{
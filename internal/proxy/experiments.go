@@ -0,0 +1,198 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	"clisimplehub/internal/executor"
+	"clisimplehub/internal/retry"
+)
+
+// ExperimentControl tags stats of requests that stayed on normal routing while
+// experiments were configured for their interface type
+const ExperimentControl = "control"
+
+// Experiment diverts a share of an interface type's requests to a fixed endpoint
+// so its quality, latency and cost can be compared against normal routing
+type Experiment struct {
+	Name          string  `json:"name"`
+	InterfaceType string  `json:"interfaceType"`
+	EndpointID    int64   `json:"endpointId"`
+	Percent       float64 `json:"percent"` // 0-100
+}
+
+// ParseExperiments converts the raw appConfig "experiments" value into experiments.
+// Accepts either a JSON array (as decoded from config.json) or a JSON string.
+func ParseExperiments(raw interface{}) ([]Experiment, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	var data []byte
+	switch v := raw.(type) {
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return nil, nil
+		}
+		data = []byte(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		data = b
+	}
+
+	var experiments []Experiment
+	if err := json.Unmarshal(data, &experiments); err != nil {
+		return nil, fmt.Errorf("invalid experiments: %w", err)
+	}
+	return experiments, nil
+}
+
+// SetExperiments replaces the configured experiments. Experiments of the same interface
+// type take consecutive bucket ranges, so their percentages must not exceed 100 in total.
+func (p *ProxyServer) SetExperiments(experiments []Experiment) error {
+	totals := make(map[string]float64)
+	names := make(map[string]bool)
+	normalized := make([]Experiment, 0, len(experiments))
+	for i, exp := range experiments {
+		exp.Name = strings.TrimSpace(exp.Name)
+		exp.InterfaceType = normalizeInterfaceType(exp.InterfaceType)
+		switch {
+		case exp.Name == "":
+			return fmt.Errorf("invalid experiments[%d]: empty name", i)
+		case strings.EqualFold(exp.Name, ExperimentControl):
+			return fmt.Errorf("invalid experiments[%d]: name %q is reserved", i, exp.Name)
+		case names[exp.Name]:
+			return fmt.Errorf("invalid experiments[%d]: duplicate name %q", i, exp.Name)
+		case exp.InterfaceType == "":
+			return fmt.Errorf("invalid experiments[%d]: empty interfaceType", i)
+		case exp.EndpointID <= 0:
+			return fmt.Errorf("invalid experiments[%d]: endpointId is required", i)
+		case exp.Percent <= 0 || exp.Percent > 100:
+			return fmt.Errorf("invalid experiments[%d]: percent must be in (0, 100]", i)
+		}
+		names[exp.Name] = true
+		totals[exp.InterfaceType] += exp.Percent
+		if totals[exp.InterfaceType] > 100 {
+			return fmt.Errorf("invalid experiments: %s percentages exceed 100", exp.InterfaceType)
+		}
+		normalized = append(normalized, exp)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.experiments = normalized
+	return nil
+}
+
+// assignExperiment buckets a request deterministically by the hash of its body. It returns
+// the stats tag ("" when untagged, ExperimentControl or the experiment name) and, for
+// diverted requests, the experiment endpoint.
+func (p *ProxyServer) assignExperiment(requestID string, interfaceType InterfaceType, body []byte) (string, *executor.EndpointConfig) {
+	p.mu.RLock()
+	experiments := p.experiments
+	hub := p.wsHub
+	p.mu.RUnlock()
+
+	var candidates []Experiment
+	for _, exp := range experiments {
+		if exp.InterfaceType == string(interfaceType) {
+			candidates = append(candidates, exp)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", nil
+	}
+
+	bucket := experimentBucket(body)
+	var upper float64
+	for _, exp := range candidates {
+		upper += exp.Percent
+		if bucket >= upper {
+			continue
+		}
+
+		var selected *Endpoint
+		for _, ep := range p.router.GetEndpointsByType(interfaceType) {
+			if ep.ID == exp.EndpointID && ep.isRoutable(time.Now()) {
+				selected = ep
+				break
+			}
+		}
+		if selected == nil {
+			// an unavailable experiment endpoint must not fail requests; leave them untagged
+			// so they do not skew either group
+			if hub != nil {
+				hub.BroadcastDebugLog(&DebugLogPayload{
+					RequestID: requestID,
+					Level:     2,
+					Message:   fmt.Sprintf("[Experiment] %s: endpoint %d is not available, using normal routing", exp.Name, exp.EndpointID),
+				})
+			}
+			return "", nil
+		}
+		if hub != nil {
+			hub.BroadcastDebugLog(&DebugLogPayload{
+				RequestID: requestID,
+				Level:     1,
+				Message:   fmt.Sprintf("[Experiment] %s -> %s (bucket %.2f)", exp.Name, endpointNameOrID(selected), bucket),
+			})
+		}
+		return exp.Name, toExecutorEndpointConfig(selected)
+	}
+	return ExperimentControl, nil
+}
+
+// experimentArmFailed reports whether a request diverted to an experiment endpoint failed
+// in a way that can still be retried on normal routing: nothing was written to the client,
+// the request is still live and the error is one failover would retry.
+func experimentArmFailed(ctx context.Context, result *executor.ForwardResult) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if result == nil {
+		return true
+	}
+	if result.Streamed {
+		return false
+	}
+	return result.Error != nil || retry.ShouldRetry(result.StatusCode)
+}
+
+// broadcastExperimentFallback reports an experiment endpoint failure that sent the request
+// back to normal routing
+func (p *ProxyServer) broadcastExperimentFallback(requestID, experiment string, armResult *executor.ExecuteResult) {
+	p.mu.RLock()
+	hub := p.wsHub
+	p.mu.RUnlock()
+	if hub == nil {
+		return
+	}
+
+	reason := "request failed"
+	if result := armResult.Result; result != nil {
+		if result.Error != nil {
+			reason = result.Error.Error()
+		} else {
+			reason = fmt.Sprintf("HTTP %d", result.StatusCode)
+		}
+	}
+	hub.BroadcastDebugLog(&DebugLogPayload{
+		RequestID: requestID,
+		Level:     2,
+		Message:   fmt.Sprintf("[Experiment] %s: %s failed (%s), falling back to normal routing", experiment, armResult.Endpoint.Name, reason),
+	})
+}
+
+// experimentBucket maps a request body to a stable value in [0, 100)
+func experimentBucket(body []byte) float64 {
+	h := fnv.New64a()
+	_, _ = h.Write(body)
+	return float64(h.Sum64()%10000) / 100
+}
@@ -0,0 +1,160 @@
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSetExperimentsValidation(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name        string
+		experiments []Experiment
+		wantErr     string
+	}{
+		{name: "reserved name", experiments: []Experiment{{Name: "Control", InterfaceType: "claude", EndpointID: 1, Percent: 10}}, wantErr: "reserved"},
+		{name: "duplicate name", experiments: []Experiment{{Name: "a", InterfaceType: "claude", EndpointID: 1, Percent: 10}, {Name: "a", InterfaceType: "codex", EndpointID: 2, Percent: 10}}, wantErr: "duplicate"},
+		{name: "percent out of range", experiments: []Experiment{{Name: "a", InterfaceType: "claude", EndpointID: 1, Percent: 0}}, wantErr: "percent"},
+		{name: "type total over 100", experiments: []Experiment{{Name: "a", InterfaceType: "claude", EndpointID: 1, Percent: 60}, {Name: "b", InterfaceType: "Claude", EndpointID: 2, Percent: 50}}, wantErr: "exceed 100"},
+		{name: "separate types", experiments: []Experiment{{Name: "a", InterfaceType: "claude", EndpointID: 1, Percent: 60}, {Name: "b", InterfaceType: "codex", EndpointID: 2, Percent: 60}}},
+	}
+	for _, tc := range cases {
+		err := NewProxyServer(0, NewRouter()).SetExperiments(tc.experiments)
+		if tc.wantErr == "" {
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", tc.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Fatalf("%s: err=%v want %q", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestAssignExperiment(t *testing.T) {
+	t.Parallel()
+
+	router := NewRouter()
+	router.LoadEndpoints([]*Endpoint{
+		{ID: 1, Name: "control", InterfaceType: "claude", Enabled: true, Active: true},
+		{ID: 2, Name: "arm-a", InterfaceType: "claude", Enabled: true},
+		{ID: 3, Name: "arm-b", InterfaceType: "claude", Enabled: true},
+		{ID: 4, Name: "arm-off", InterfaceType: "claude", Enabled: false},
+	})
+
+	// expected assignment for two experiments taking buckets [0, 30) and [30, 60)
+	split := func(bucket float64) (string, string) {
+		switch {
+		case bucket < 30:
+			return "a", "arm-a"
+		case bucket < 60:
+			return "b", "arm-b"
+		}
+		return ExperimentControl, ""
+	}
+
+	cases := []struct {
+		name        string
+		experiments []Experiment
+		typ         InterfaceType
+		want        func(bucket float64) (string, string)
+	}{
+		{name: "no experiments", want: func(float64) (string, string) { return "", "" }},
+		{name: "other interface type", experiments: []Experiment{{Name: "a", InterfaceType: "codex", EndpointID: 2, Percent: 100}}, want: func(float64) (string, string) { return "", "" }},
+		{name: "full diversion", experiments: []Experiment{{Name: "a", InterfaceType: "claude", EndpointID: 2, Percent: 100}}, want: func(float64) (string, string) { return "a", "arm-a" }},
+		{name: "consecutive ranges", experiments: []Experiment{{Name: "a", InterfaceType: "claude", EndpointID: 2, Percent: 30}, {Name: "b", InterfaceType: "claude", EndpointID: 3, Percent: 30}}, want: split},
+		{name: "unavailable endpoint left untagged", experiments: []Experiment{{Name: "off", InterfaceType: "claude", EndpointID: 4, Percent: 100}}, want: func(float64) (string, string) { return "", "" }},
+	}
+	for _, tc := range cases {
+		p := NewProxyServer(0, router)
+		if err := p.SetExperiments(tc.experiments); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		for i := 0; i < 50; i++ {
+			body := []byte(fmt.Sprintf(`{"model":"m","n":%d}`, i))
+			wantTag, wantEndpoint := tc.want(experimentBucket(body))
+			for attempt := 0; attempt < 2; attempt++ {
+				tag, endpoint := p.assignExperiment("req", InterfaceTypeClaude, body)
+				gotEndpoint := ""
+				if endpoint != nil {
+					gotEndpoint = endpoint.Name
+				}
+				if tag != wantTag || gotEndpoint != wantEndpoint {
+					t.Fatalf("%s: body %d: got tag=%q endpoint=%q want tag=%q endpoint=%q", tc.name, i, tag, gotEndpoint, wantTag, wantEndpoint)
+				}
+			}
+		}
+	}
+}
+
+func TestExperimentBucketRange(t *testing.T) {
+	t.Parallel()
+
+	for i := 0; i < 1000; i++ {
+		body := []byte(fmt.Sprintf(`{"n":%d}`, i))
+		bucket := experimentBucket(body)
+		if bucket < 0 || bucket >= 100 {
+			t.Fatalf("bucket %v out of [0, 100)", bucket)
+		}
+		if experimentBucket(body) != bucket {
+			t.Fatalf("bucket not stable for %s", body)
+		}
+	}
+}
+
+func TestHandleProxyExperimentFallsBackToControl(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name            string
+		armStatus       int
+		wantStatus      int
+		wantControlHits int32
+	}{
+		{name: "arm succeeds", armStatus: http.StatusOK, wantStatus: http.StatusOK, wantControlHits: 0},
+		{name: "arm 5xx falls back to control", armStatus: http.StatusBadGateway, wantStatus: http.StatusOK, wantControlHits: 1},
+		{name: "arm 4xx returned as is", armStatus: http.StatusBadRequest, wantStatus: http.StatusBadRequest, wantControlHits: 0},
+	}
+	for _, tc := range cases {
+		var armHits, controlHits atomic.Int32
+		arm := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			armHits.Add(1)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(tc.armStatus)
+			_, _ = w.Write([]byte(`{"id":"arm","type":"message","content":[]}`))
+		}))
+		control := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			controlHits.Add(1)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id":"control","type":"message","content":[]}`))
+		}))
+
+		router := NewRouter()
+		router.LoadEndpoints([]*Endpoint{
+			{ID: 1, Name: "control", APIURL: control.URL, InterfaceType: "claude", Enabled: true, Active: true},
+			{ID: 2, Name: "arm", APIURL: arm.URL, InterfaceType: "claude", Enabled: true},
+		})
+		p := NewProxyServer(0, router)
+		if err := p.SetExperiments([]Experiment{{Name: "trial", InterfaceType: "claude", EndpointID: 2, Percent: 100}}); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+
+		w := httptest.NewRecorder()
+		p.handleProxy(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"m","messages":[]}`)))
+		arm.Close()
+		control.Close()
+
+		if w.Code != tc.wantStatus {
+			t.Fatalf("%s: status=%d want %d body=%s", tc.name, w.Code, tc.wantStatus, w.Body.String())
+		}
+		if armHits.Load() != 1 || controlHits.Load() != tc.wantControlHits {
+			t.Fatalf("%s: arm hits=%d control hits=%d want 1/%d", tc.name, armHits.Load(), controlHits.Load(), tc.wantControlHits)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+)
+
+// DefaultPerTypeQueueSize is the number of requests allowed to wait per interface type
+// when perTypeQueueSize is not configured
+const DefaultPerTypeQueueSize = 64
+
+// ErrQueueFull is returned when an interface type's wait queue has no room left
+var ErrQueueFull = errors.New("request queue full")
+
+// FairQueue bounds the number of in-flight requests per interface type. Every type has
+// its own slots and FIFO wait queue, so a burst on one type cannot starve the others.
+type FairQueue struct {
+	mu        sync.Mutex
+	perType   int
+	queueSize int
+	types     map[InterfaceType]*typeQueue
+}
+
+// typeQueue tracks the slots and waiters of one interface type
+type typeQueue struct {
+	active   int
+	waiters  []chan struct{} // closed when the waiter is granted a slot
+	admitted int64
+	queued   int64
+	rejected int64
+}
+
+// QueueStats is a snapshot of one interface type's queue
+type QueueStats struct {
+	InterfaceType string `json:"interfaceType"`
+	Active        int    `json:"active"`
+	Queued        int    `json:"queued"`
+	Limit         int    `json:"limit"`
+	QueueSize     int    `json:"queueSize"`
+	Admitted      int64  `json:"admitted"`    // requests that got a slot since startup
+	TotalQueued   int64  `json:"totalQueued"` // requests that had to wait since startup
+	Rejected      int64  `json:"rejected"`    // requests rejected with 503 since startup
+}
+
+// NewFairQueue creates a queue allowing perType concurrent requests per interface type and
+// queueSize waiting ones (DefaultPerTypeQueueSize when <= 0). Returns nil when perType <= 0.
+func NewFairQueue(perType, queueSize int) *FairQueue {
+	if perType <= 0 {
+		return nil
+	}
+	if queueSize <= 0 {
+		queueSize = DefaultPerTypeQueueSize
+	}
+	return &FairQueue{
+		perType:   perType,
+		queueSize: queueSize,
+		types:     make(map[InterfaceType]*typeQueue),
+	}
+}
+
+// Acquire waits for a slot of the interface type. It returns ErrQueueFull when the type's
+// queue is full, or ctx's error when ctx ends first. On success the caller must call release.
+func (q *FairQueue) Acquire(ctx context.Context, interfaceType InterfaceType) (release func(), err error) {
+	q.mu.Lock()
+	tq := q.types[interfaceType]
+	if tq == nil {
+		tq = &typeQueue{}
+		q.types[interfaceType] = tq
+	}
+	if tq.active < q.perType {
+		tq.active++
+		tq.admitted++
+		q.mu.Unlock()
+		return q.releaseFunc(tq), nil
+	}
+	if len(tq.waiters) >= q.queueSize {
+		tq.rejected++
+		q.mu.Unlock()
+		return nil, ErrQueueFull
+	}
+	ready := make(chan struct{})
+	tq.waiters = append(tq.waiters, ready)
+	tq.queued++
+	q.mu.Unlock()
+
+	select {
+	case <-ready:
+		return q.releaseFunc(tq), nil
+	case <-ctx.Done():
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		for i, w := range tq.waiters {
+			if w == ready {
+				tq.waiters = append(tq.waiters[:i], tq.waiters[i+1:]...)
+				return nil, ctx.Err()
+			}
+		}
+		// The slot was handed over while ctx ended: pass it on
+		q.releaseLocked(tq)
+		return nil, ctx.Err()
+	}
+}
+
+func (q *FairQueue) releaseFunc(tq *typeQueue) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			q.releaseLocked(tq)
+		})
+	}
+}
+
+// releaseLocked hands the slot to the oldest waiter, or frees it when nobody waits or the
+// type is over its limit after a Resize
+func (q *FairQueue) releaseLocked(tq *typeQueue) {
+	if len(tq.waiters) > 0 && tq.active <= q.perType {
+		next := tq.waiters[0]
+		tq.waiters = tq.waiters[1:]
+		tq.admitted++
+		close(next)
+		return
+	}
+	tq.active--
+}
+
+// Resize changes the limits in place, keeping in-flight requests and waiters. A larger perType
+// admits waiters right away; a smaller one takes effect as requests release their slots.
+// queueSize <= 0 means DefaultPerTypeQueueSize; perType <= 0 is ignored.
+func (q *FairQueue) Resize(perType, queueSize int) {
+	if perType <= 0 {
+		return
+	}
+	if queueSize <= 0 {
+		queueSize = DefaultPerTypeQueueSize
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.perType = perType
+	q.queueSize = queueSize
+	for _, tq := range q.types {
+		for len(tq.waiters) > 0 && tq.active < q.perType {
+			next := tq.waiters[0]
+			tq.waiters = tq.waiters[1:]
+			tq.active++
+			tq.admitted++
+			close(next)
+		}
+	}
+}
+
+// Limits returns the per type concurrency and queue size
+func (q *FairQueue) Limits() (perType, queueSize int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.perType, q.queueSize
+}
+
+// Stats returns a snapshot of every interface type's queue, ordered by interface type
+func (q *FairQueue) Stats() []QueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	out := make([]QueueStats, 0, len(q.types))
+	for interfaceType, tq := range q.types {
+		out = append(out, QueueStats{
+			InterfaceType: string(interfaceType),
+			Active:        tq.active,
+			Queued:        len(tq.waiters),
+			Limit:         q.perType,
+			QueueSize:     q.queueSize,
+			Admitted:      tq.admitted,
+			TotalQueued:   tq.queued,
+			Rejected:      tq.rejected,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].InterfaceType < out[j].InterfaceType })
+	return out
+}
+
+// SetFairQueue sets the per interface type concurrency queue (nil disables queuing).
+// Requests already holding a slot release it to the queue they acquired it from.
+func (p *ProxyServer) SetFairQueue(queue *FairQueue) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.fairQueue = queue
+}
+
+// SetFairQueueLimits applies the queue limits: perType <= 0 disables queuing, otherwise the
+// current queue is resized in place so slots and waiters survive a settings reload
+func (p *ProxyServer) SetFairQueueLimits(perType, queueSize int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if perType <= 0 {
+		p.fairQueue = nil
+		return
+	}
+	if p.fairQueue != nil {
+		p.fairQueue.Resize(perType, queueSize)
+		return
+	}
+	p.fairQueue = NewFairQueue(perType, queueSize)
+}
+
+func (p *ProxyServer) getFairQueue() *FairQueue {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.fairQueue
+}
+
+// QueueStats returns the per interface type queue depths, or nil when queuing is disabled
+func (p *ProxyServer) QueueStats() []QueueStats {
+	if queue := p.getFairQueue(); queue != nil {
+		return queue.Stats()
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func queuedCount(q *FairQueue, interfaceType InterfaceType) int {
+	for _, s := range q.Stats() {
+		if s.InterfaceType == string(interfaceType) {
+			return s.Queued
+		}
+	}
+	return 0
+}
+
+func TestFairQueueFIFOHandoff(t *testing.T) {
+	t.Parallel()
+
+	q := NewFairQueue(1, 4)
+	release, err := q.Acquire(context.Background(), InterfaceTypeClaude)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+
+	order := make(chan int, 3)
+	for i := 0; i < 3; i++ {
+		i := i
+		go func() {
+			rel, err := q.Acquire(context.Background(), InterfaceTypeClaude)
+			if err != nil {
+				order <- -1
+				return
+			}
+			order <- i
+			rel()
+		}()
+		// enqueue one at a time so the expected order is deterministic
+		waitFor(t, "waiter queued", func() bool { return queuedCount(q, InterfaceTypeClaude) == i+1 })
+	}
+
+	release()
+	release() // repeated release must not free a second slot
+	for want := 0; want < 3; want++ {
+		select {
+		case got := <-order:
+			if got != want {
+				t.Fatalf("waiter %d admitted, want %d", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("waiter %d never admitted", want)
+		}
+	}
+
+	stats := q.Stats()
+	if len(stats) != 1 || stats[0].Active != 0 || stats[0].Queued != 0 || stats[0].Admitted != 4 || stats[0].TotalQueued != 3 {
+		t.Fatalf("stats=%+v", stats)
+	}
+}
+
+func TestFairQueueCancelWhileWaiting(t *testing.T) {
+	t.Parallel()
+
+	q := NewFairQueue(1, 4)
+	release, _ := q.Acquire(context.Background(), InterfaceTypeClaude)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() {
+		_, err := q.Acquire(ctx, InterfaceTypeClaude)
+		errc <- err
+	}()
+	waitFor(t, "waiter queued", func() bool { return queuedCount(q, InterfaceTypeClaude) == 1 })
+	cancel()
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Fatalf("err=%v want context.Canceled", err)
+	}
+	if queuedCount(q, InterfaceTypeClaude) != 0 {
+		t.Fatalf("cancelled waiter left in queue")
+	}
+
+	// The released slot goes back to the pool, not to the cancelled waiter
+	release()
+	rel, err := q.Acquire(context.Background(), InterfaceTypeClaude)
+	if err != nil {
+		t.Fatalf("slot leaked after cancel: %v", err)
+	}
+	rel()
+}
+
+func TestFairQueueLimits(t *testing.T) {
+	t.Parallel()
+
+	q := NewFairQueue(1, 1)
+	releaseClaude, _ := q.Acquire(context.Background(), InterfaceTypeClaude)
+	defer releaseClaude()
+	go func() {
+		if rel, err := q.Acquire(context.Background(), InterfaceTypeClaude); err == nil {
+			rel()
+		}
+	}()
+	waitFor(t, "waiter queued", func() bool { return queuedCount(q, InterfaceTypeClaude) == 1 })
+
+	if _, err := q.Acquire(context.Background(), InterfaceTypeClaude); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("err=%v want ErrQueueFull", err)
+	}
+	// Other interface types have their own slots
+	rel, err := q.Acquire(context.Background(), InterfaceTypeCodex)
+	if err != nil {
+		t.Fatalf("codex blocked by claude queue: %v", err)
+	}
+	rel()
+
+	if NewFairQueue(0, 1) != nil {
+		t.Fatalf("perType <= 0 should disable the queue")
+	}
+}
+
+func activeCount(q *FairQueue, interfaceType InterfaceType) int {
+	for _, s := range q.Stats() {
+		if s.InterfaceType == string(interfaceType) {
+			return s.Active
+		}
+	}
+	return 0
+}
+
+func TestFairQueueResize(t *testing.T) {
+	t.Parallel()
+
+	q := NewFairQueue(1, 4)
+	releaseFirst, _ := q.Acquire(context.Background(), InterfaceTypeClaude)
+
+	admitted := make(chan func(), 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			if rel, err := q.Acquire(context.Background(), InterfaceTypeClaude); err == nil {
+				admitted <- rel
+			}
+		}()
+	}
+	waitFor(t, "waiters queued", func() bool { return queuedCount(q, InterfaceTypeClaude) == 2 })
+
+	// Growing the limit admits one waiter right away and keeps the in-flight slot
+	q.Resize(2, 4)
+	var releaseSecond func()
+	select {
+	case releaseSecond = <-admitted:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("waiter not admitted after growing the limit")
+	}
+	if activeCount(q, InterfaceTypeClaude) != 2 || queuedCount(q, InterfaceTypeClaude) != 1 {
+		t.Fatalf("after grow stats=%+v", q.Stats())
+	}
+
+	// Shrinking keeps both in-flight requests; the waiter only gets a slot once the
+	// type is back under the new limit
+	q.Resize(1, 4)
+	releaseFirst()
+	if activeCount(q, InterfaceTypeClaude) != 1 || queuedCount(q, InterfaceTypeClaude) != 1 {
+		t.Fatalf("after shrink stats=%+v", q.Stats())
+	}
+	releaseSecond()
+	select {
+	case rel := <-admitted:
+		rel()
+	case <-time.After(5 * time.Second):
+		t.Fatalf("waiter not admitted after the type dropped under the limit")
+	}
+
+	if perType, queueSize := q.Limits(); perType != 1 || queueSize != 4 {
+		t.Fatalf("Limits()=%d,%d want 1,4", perType, queueSize)
+	}
+}
+
+func TestSetFairQueueLimitsKeepsQueue(t *testing.T) {
+	t.Parallel()
+
+	p := NewProxyServer(0, NewRouter())
+	p.SetFairQueueLimits(1, 4)
+	q := p.getFairQueue()
+	release, _ := q.Acquire(context.Background(), InterfaceTypeClaude)
+	defer release()
+
+	p.SetFairQueueLimits(1, 4)
+	if p.getFairQueue() != q || activeCount(q, InterfaceTypeClaude) != 1 {
+		t.Fatalf("unchanged limits replaced the queue or dropped its slot")
+	}
+	p.SetFairQueueLimits(3, 8)
+	if p.getFairQueue() != q {
+		t.Fatalf("changed limits should resize the queue in place")
+	}
+	if perType, queueSize := q.Limits(); perType != 3 || queueSize != 8 {
+		t.Fatalf("Limits()=%d,%d want 3,8", perType, queueSize)
+	}
+	p.SetFairQueueLimits(0, 8)
+	if p.getFairQueue() != nil {
+		t.Fatalf("perType 0 should disable queuing")
+	}
+}
+
+func TestHandleProxySelectsEndpointAfterQueueing(t *testing.T) {
+	t.Parallel()
+
+	var hitsA, hitsB atomic.Int32
+	newUpstream := func(hits *atomic.Int32) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits.Add(1)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id":"msg","type":"message","content":[]}`))
+		}))
+	}
+	upstreamA, upstreamB := newUpstream(&hitsA), newUpstream(&hitsB)
+	defer upstreamA.Close()
+	defer upstreamB.Close()
+
+	router := NewRouter()
+	endpointB := &Endpoint{ID: 2, Name: "b", APIURL: upstreamB.URL, InterfaceType: "claude", Enabled: true}
+	router.LoadEndpoints([]*Endpoint{
+		{ID: 1, Name: "a", APIURL: upstreamA.URL, InterfaceType: "claude", Enabled: true, Active: true},
+		endpointB,
+	})
+	p := NewProxyServer(0, router)
+	queue := NewFairQueue(1, 1)
+	p.SetFairQueue(queue)
+	p.SetResponseCache(NewResponseCache(time.Minute, 0))
+	send := func(w *httptest.ResponseRecorder) {
+		p.handleProxy(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"m","temperature":0,"messages":[]}`)))
+	}
+
+	release, _ := queue.Acquire(context.Background(), InterfaceTypeClaude)
+	done := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		w := httptest.NewRecorder()
+		send(w)
+		done <- w
+	}()
+	waitFor(t, "request queued", func() bool { return queuedCount(queue, InterfaceTypeClaude) == 1 })
+
+	// The active endpoint changes while the request waits for a slot
+	if err := router.SetActiveEndpoint(InterfaceTypeClaude, endpointB); err != nil {
+		t.Fatalf("SetActiveEndpoint: %v", err)
+	}
+	release()
+
+	w := <-done
+	if w.Code != http.StatusOK || hitsA.Load() != 0 || hitsB.Load() != 1 {
+		t.Fatalf("status=%d hits a=%d b=%d", w.Code, hitsA.Load(), hitsB.Load())
+	}
+	logs := p.stats.GetRecentLogs(0)
+	if len(logs) == 0 || logs[len(logs)-1].EndpointName != "b" || !strings.HasPrefix(logs[len(logs)-1].TargetURL, upstreamB.URL) {
+		t.Fatalf("log does not show the reselected endpoint: %+v", logs)
+	}
+
+	// The response was cached under the endpoint that served it
+	w = httptest.NewRecorder()
+	send(w)
+	if w.Header().Get("X-Cache") != "HIT" || hitsB.Load() != 1 {
+		t.Fatalf("X-Cache=%q hits b=%d", w.Header().Get("X-Cache"), hitsB.Load())
+	}
+}
@@ -2,7 +2,9 @@ package proxy
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -22,23 +24,56 @@ func (p *ProxyServer) handleProxy(w http.ResponseWriter, r *http.Request) {
 	requestID := uuid.New().String()
 
 	reqHeaders := sanitizeHeadersForLog(r.Header)
+	if rewritten := p.rewritePath(requestID, r.URL.Path); rewritten != r.URL.Path {
+		r.URL.Path = rewritten
+		r.URL.RawPath = ""
+	}
 	interfaceType := p.router.DetectInterfaceType(r.URL.Path)
 
 	isRetryable := IsRetryablePath(r.URL.Path)
 	shouldRecordStats := ShouldRecordVendorStats(interfaceType, r.URL.Path)
 	fallbackEnabled := p.IsFallbackEnabled()
 
-	if required := p.getAuthKey(); required != "" && !isAuthorized(r, required) {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	authKey, authorized := p.authenticate(r)
+	if !authorized {
+		writeProxyError(w, interfaceType, http.StatusUnauthorized, "Unauthorized")
 		detail := &RequestDetail{Method: r.Method, StatusCode: http.StatusUnauthorized, RequestHeaders: reqHeaders}
 		runTime := time.Since(startTime).Milliseconds()
 		p.recordRequestWithDetail(requestID, interfaceType, nil, r.URL.Path, startTime, "error_401", runTime, detail)
 		return
 	}
+	// 按 key 限定接口类型：带标签的 key 只能访问其允许的接口类型
+	if interfaceType != "" && !authKey.allows(interfaceType) {
+		p.rejectScopedAuthKey(w, r, requestID, interfaceType, authKey, startTime, reqHeaders)
+		return
+	}
+
+	// 未识别的路径：unknownPathBehavior=reject 时直接返回 404，不转发给任何端点
+	if interfaceType == "" {
+		writeProxyError(w, interfaceType, http.StatusNotFound, fmt.Sprintf("Unsupported path: %s", r.URL.Path))
+		detail := &RequestDetail{Method: r.Method, StatusCode: http.StatusNotFound, RequestHeaders: reqHeaders}
+		runTime := time.Since(startTime).Milliseconds()
+		p.recordRequestWithDetail(requestID, interfaceType, nil, r.URL.Path, startTime, "error_404", runTime, detail)
+		return
+	}
+
+	// 环路检测：请求已经经过本代理，说明某个端点（可能跨多跳）指回了这里
+	if executor.IsForwardLoop(r.Header) {
+		writeProxyError(w, interfaceType, http.StatusLoopDetected, "Proxy loop detected: an endpoint forwards back to this proxy")
+		detail := &RequestDetail{Method: r.Method, StatusCode: http.StatusLoopDetected, RequestHeaders: reqHeaders}
+		runTime := time.Since(startTime).Milliseconds()
+		p.recordRequestWithDetail(requestID, interfaceType, nil, r.URL.Path, startTime, "error_508", runTime, detail)
+		return
+	}
+
+	// 模型发现请求：汇总所有可用端点配置的模型别名，而不是转发给单个上游
+	if isModelsListRequest(r) && p.handleModelsList(w, r, interfaceType) {
+		return
+	}
 
 	bodyBytes, err := io.ReadAll(r.Body)
 	if err != nil {
-		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		writeProxyError(w, interfaceType, http.StatusBadRequest, "Failed to read request body")
 		return
 	}
 	_ = r.Body.Close()
@@ -46,13 +81,53 @@ func (p *ProxyServer) handleProxy(w http.ResponseWriter, r *http.Request) {
 	isStreaming := isStreamRequested(bodyBytes)
 
 	exec := p.ensureExecutor()
+	// 路由规则：按路径、请求头和请求体中的模型强制接口类型或指定端点
+	interfaceType, forcedType, rulePinned, ruleErr := p.applyRoutingRules(requestID, r, bodyBytes, interfaceType)
+	if forcedType != "" {
+		if !authKey.allows(forcedType) {
+			p.rejectScopedAuthKey(w, r, requestID, forcedType, authKey, startTime, reqHeaders)
+			return
+		}
+		if ruleErr != nil {
+			// 跨协议强制类型却没有可转换协议的指定端点，原样转发只会得到上游 4xx
+			writeProxyError(w, interfaceType, http.StatusBadRequest, ruleErr.Error())
+			detail := &RequestDetail{Method: r.Method, StatusCode: http.StatusBadRequest, RequestHeaders: reqHeaders, ResponseStream: ruleErr.Error(), AuthKey: authKey.label()}
+			runTime := time.Since(startTime).Milliseconds()
+			p.recordRequestWithDetail(requestID, interfaceType, nil, r.URL.Path, startTime, "error_400", runTime, detail)
+			return
+		}
+		shouldRecordStats = ShouldRecordVendorStats(interfaceType, r.URL.Path)
+	}
+	override := p.resolveEndpointOverride(requestID, r, interfaceType)
 	forwardReq := executor.ForwardRequestFromHTTP(r, bodyBytes, isStreaming)
-	endpoint, resolvedType := exec.ctx.ResolveEndpoint(forwardReq.Path)
-	if resolvedType != "" {
+	if forcedType != "" {
+		forwardReq.InterfaceType = string(interfaceType)
+	}
+	endpoint, resolvedType := exec.ctx.ResolveEndpoint(forwardReq)
+	if resolvedType != "" && InterfaceType(resolvedType) != interfaceType {
 		interfaceType = InterfaceType(resolvedType)
+		if !authKey.allows(interfaceType) {
+			p.rejectScopedAuthKey(w, r, requestID, interfaceType, authKey, startTime, reqHeaders)
+			return
+		}
+	}
+	// 优先级：请求头指定端点 > 路由规则指定端点 > A/B 实验分流
+	var experiment string
+	var experimentEndpoint *executor.EndpointConfig
+	pinned := override
+	if pinned == nil {
+		pinned = rulePinned
+	}
+	if pinned == nil {
+		// A/B 实验：未指定端点时，按请求体哈希把一部分请求分流到实验端点
+		experiment, experimentEndpoint = p.assignExperiment(requestID, interfaceType, bodyBytes)
+		pinned = experimentEndpoint
+	}
+	if pinned != nil {
+		endpoint = pinned
 	}
 	if endpoint == nil {
-		http.Error(w, "No enabled endpoints available", http.StatusServiceUnavailable)
+		writeProxyError(w, interfaceType, http.StatusServiceUnavailable, "No enabled endpoints available")
 		detail := &RequestDetail{
 			Method:         r.Method,
 			StatusCode:     http.StatusServiceUnavailable,
@@ -66,38 +141,139 @@ func (p *ProxyServer) handleProxy(w http.ResponseWriter, r *http.Request) {
 
 	detail := &RequestDetail{
 		Method:         r.Method,
-		TargetURL:      strings.TrimSuffix(endpoint.APIURL, "/") + r.URL.Path,
+		TargetURL:      upstreamTargetURL(endpoint, interfaceType, r, bodyBytes, isStreaming),
 		RequestHeaders: reqHeaders,
 		RequestStream:  string(bodyBytes),
 		UpstreamAuth:   formatUpstreamAuthForLogConfig(endpoint.InterfaceType, endpoint.APIKey),
+		AuthKey:        authKey.label(),
 	}
-	if target, err := executor.BuildTargetURL(endpoint.APIURL, r.URL.Path, r.URL.RawQuery); err == nil && target != "" {
-		detail.TargetURL = target
-	}
-
-	// 如果配置了 transformer，提前计算实际转发目标 URL（用于 started 日志/控制台展示）。
-	if endpoint != nil && strings.TrimSpace(endpoint.Transformer) != "" {
-		if tr, err := transformer.Get(strings.TrimSpace(string(interfaceType)), endpoint.Transformer); err == nil && tr != nil {
-			requestModel := extractModelFromBody(bodyBytes)
-			upstreamModel := executor.ResolveUpstreamModel(requestModel, endpoint)
-			targetPath := tr.TargetPath(isStreaming, upstreamModel)
-			if strings.TrimSpace(targetPath) != "" {
-				if target, err := executor.BuildTargetURL(endpoint.APIURL, targetPath, r.URL.RawQuery); err == nil && target != "" {
-					detail.TargetURL = target
+
+	// 请求合并：相同的进行中流式请求共享一次上游调用，跟随者回放领头请求的响应流
+	coalesceCtx := r.Context()
+	if coalescer := p.getStreamCoalescer(); coalescer != nil && isStreaming && isCoalescableRequest(r, bodyBytes) {
+		key := streamCoalesceKey(endpoint, r, bodyBytes)
+		stream, leader := coalescer.join(key, r)
+		if !leader {
+			p.recordRequestWithDetail(requestID, interfaceType, endpoint, r.URL.Path, startTime, "in_progress", 0, detail)
+			statusCode, completed := stream.serve(r.Context(), w)
+			detail.StatusCode = statusCode
+			detail.ResponseStream = "(coalesced with an identical in-flight request)"
+			status := coalescedStatus(statusCode, completed)
+			runTime := time.Since(startTime).Milliseconds()
+			p.recordRequestWithDetail(requestID, interfaceType, endpoint, r.URL.Path, startTime, status, runTime, detail)
+			if isRetryable && shouldRecordStats {
+				// 合并的请求不消耗上游 token，按零成本记录
+				p.insertVendorStat(r.Context(), interfaceType, endpoint, r.URL.Path, nil, runTime, statusCode, status, nil, forwardReq.UserID, experiment, streamMetrics{})
+			}
+			if statusCode == 0 && completed {
+				writeProxyError(w, interfaceType, http.StatusBadGateway, "Request failed")
+			}
+			return
+		}
+		// 领头请求的上游调用与自身客户端解耦，所有客户端都断开后才取消
+		defer coalescer.finish(key, stream)
+		stream.watch(r.Context())
+		coalesceCtx = stream.ctx
+		w = &coalesceLeaderWriter{ResponseWriter: w, stream: stream, coalescer: coalescer, key: key}
+	}
+
+	// 响应缓存：仅非流式且客户端显式开启（或 temperature==0）的请求；
+	// 在排队之前查找，缓存命中不占用并发槽位
+	var cacheKey string
+	cache := p.getResponseCache()
+	if cache != nil && !isStreaming && isCacheableRequest(r, bodyBytes) {
+		cacheKey = responseCacheKey(endpoint, r, bodyBytes)
+		if cached, ok := cache.Get(cacheKey); ok {
+			detail.TargetURL = cached.targetURL
+			detail.StatusCode = cached.statusCode
+			runTime := time.Since(startTime).Milliseconds()
+			p.recordRequestWithDetail(requestID, interfaceType, endpoint, r.URL.Path, startTime, "success", runTime, detail)
+			if isRetryable && shouldRecordStats {
+				// 缓存命中不消耗上游 token，按零成本记录
+				p.insertVendorStat(r.Context(), interfaceType, endpoint, r.URL.Path, nil, runTime, cached.statusCode, "success", nil, forwardReq.UserID, experiment, streamMetrics{})
+			}
+			w.Header().Set("X-Cache", "HIT")
+			p.writeClientResponse(w, r, cached.statusCode, cached.headers, cached.body)
+			return
+		}
+	}
+
+	// 按接口类型限制并发：槽位已满时排队等待，队列也满时直接返回 503
+	if queue := p.getFairQueue(); queue != nil {
+		release, err := queue.Acquire(r.Context(), interfaceType)
+		if err != nil {
+			statusCode, status, message := http.StatusServiceUnavailable, "error_503", fmt.Sprintf("Too many queued %s requests", interfaceType)
+			if !errors.Is(err, ErrQueueFull) {
+				statusCode, status, message = statusClientClosedRequest, statusCancelled, "Request cancelled"
+			}
+			writeProxyError(w, interfaceType, statusCode, message)
+			detail.StatusCode = statusCode
+			runTime := time.Since(startTime).Milliseconds()
+			p.recordRequestWithDetail(requestID, interfaceType, nil, r.URL.Path, startTime, status, runTime, detail)
+			return
+		}
+		defer release()
+		// 排队期间活动端点可能已切换或被禁用：未指定端点的请求在拿到槽位后重新选择
+		if pinned == nil {
+			if current, _ := exec.ctx.ResolveEndpoint(forwardReq); current != nil && current.ID != endpoint.ID {
+				endpoint = current
+				detail.TargetURL = upstreamTargetURL(endpoint, interfaceType, r, bodyBytes, isStreaming)
+				detail.UpstreamAuth = formatUpstreamAuthForLogConfig(endpoint.InterfaceType, endpoint.APIKey)
+				if cacheKey != "" {
+					cacheKey = responseCacheKey(endpoint, r, bodyBytes)
 				}
 			}
 		}
 	}
+
+	// 流式请求记录首字节写出时间，用于统计 TTFT 与输出速率
+	var timing *streamTimingWriter
+	if isStreaming {
+		timing = &streamTimingWriter{ResponseWriter: w}
+		w = timing
+	}
+
 	p.recordRequestWithDetail(requestID, interfaceType, endpoint, r.URL.Path, startTime, "in_progress", 0, detail)
 
+	// 全局请求截止时间：覆盖包括故障转移在内的全部上游尝试
+	execCtx, cancel := p.withRequestDeadline(coalesceCtx, isStreaming)
+	defer cancel()
+	execCtx, untrack := p.trackRequest(execCtx, requestID)
+	defer untrack()
+	// 按需调试追踪：捕获开启时记录该请求的逐步执行决策
+	execCtx, endTrace := p.beginDebugTrace(execCtx, requestID, r, interfaceType, endpoint, pinned != nil)
+
 	enableRetry := isRetryable && fallbackEnabled
-	execResult := exec.retry.Execute(executor.WithRequestID(r.Context(), requestID), forwardReq, w, enableRetry)
+	var execResult *executor.ExecuteResult
+	if pinned != nil {
+		// 指定端点（或实验分流）的请求不做故障转移，确保只命中该端点
+		execResult = exec.retry.ExecuteOnEndpoint(executor.WithRequestID(execCtx, requestID), pinned, forwardReq, w)
+	} else {
+		execResult = exec.retry.Execute(executor.WithRequestID(execCtx, requestID), forwardReq, w, enableRetry)
+	}
+	if experimentEndpoint != nil && experimentArmFailed(execCtx, execResult.Result) {
+		// 实验端点失败且尚未向客户端写入内容：单独记录该次失败，再回退到正常路由（对照组）重试，
+		// 回退结果不打实验标签，避免计入任一分组
+		if isRetryable && shouldRecordStats {
+			armResult := execResult.Result
+			p.insertVendorStat(r.Context(), interfaceType, execResult.Endpoint, r.URL.Path, targetHeadersFromResult(armResult), time.Since(startTime).Milliseconds(), statusCodeFromResult(armResult), statusFromExecuteResult(armResult), nil, forwardReq.UserID, experiment, streamMetrics{})
+		}
+		p.broadcastExperimentFallback(requestID, experiment, execResult)
+		experiment = ""
+		execResult = exec.retry.Execute(executor.WithRequestID(execCtx, requestID), forwardReq, w, enableRetry)
+	}
 	result := execResult.Result
+	if result != nil && result.Spooled != nil {
+		// 落盘的超大响应体在请求结束时删除临时文件，客户端中途断开同样会执行
+		defer result.Spooled.Close()
+	}
 
 	if result != nil {
 		detail.TargetURL = result.TargetURL
 		detail.StatusCode = result.StatusCode
 		detail.ResponseStream = result.ResponseStream
+		detail.ModelFallback = result.ModelFallback
+		detail.MaxTokensClamp = result.MaxTokensClamp
 		if detail.ResponseStream == "" && shouldCaptureErrorResponse(result) {
 			if len(result.Body) > 0 {
 				detail.ResponseStream = truncateResponseBodyForLog(result.Body, 50*1024)
@@ -107,29 +283,65 @@ func (p *ProxyServer) handleProxy(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	runTime := time.Since(startTime).Milliseconds()
+	endTime := time.Now()
+	runTime := endTime.Sub(startTime).Milliseconds()
 	status := statusFromExecuteResult(result)
+	cancelled := isRequestCancelled(execCtx)
+	if cancelled {
+		status = statusCancelled
+	}
 	p.recordRequestWithDetail(requestID, interfaceType, execResult.Endpoint, r.URL.Path, startTime, status, runTime, detail)
+	endTrace(status)
 
 	if isRetryable {
 		p.recordTokens(execResult.Endpoint, result)
 		if shouldRecordStats {
-			p.insertVendorStat(r.Context(), interfaceType, execResult.Endpoint, r.URL.Path, targetHeadersFromResult(result), runTime, statusCodeFromResult(result), status, tokensFromResult(result))
+			p.insertVendorStat(r.Context(), interfaceType, execResult.Endpoint, r.URL.Path, targetHeadersFromResult(result), runTime, statusCodeFromResult(result), status, tokensFromResult(result), forwardReq.UserID, experiment, streamMetricsFromResult(timing, result, startTime, endTime))
 		}
 	}
 
 	if result == nil {
-		http.Error(w, "Request failed", http.StatusBadGateway)
+		writeProxyError(w, interfaceType, http.StatusBadGateway, "Request failed")
 		return
 	}
 	if result.Streamed {
 		return
 	}
+	if cancelled {
+		writeProxyError(w, interfaceType, statusClientClosedRequest, "Request cancelled")
+		return
+	}
+	if result.Error != nil && errors.Is(execCtx.Err(), context.DeadlineExceeded) {
+		writeProxyError(w, interfaceType, http.StatusGatewayTimeout, "Request deadline exceeded")
+		return
+	}
+	if result.Error != nil && errors.Is(result.Error, executor.ErrDailyBudgetExhausted) {
+		writeProxyError(w, interfaceType, http.StatusTooManyRequests, result.Error.Error())
+		return
+	}
+	if result.Error != nil && result.StatusCode == 0 && errors.Is(result.Error, executor.ErrProxyLoop) {
+		writeProxyError(w, interfaceType, http.StatusLoopDetected, fmt.Sprintf("Request failed: %v", result.Error))
+		return
+	}
 	if result.Error != nil && result.StatusCode == 0 {
-		http.Error(w, fmt.Sprintf("Request failed: %v", result.Error), http.StatusBadGateway)
+		writeProxyError(w, interfaceType, http.StatusBadGateway, fmt.Sprintf("Request failed: %v", result.Error))
+		return
+	}
+	if result.Spooled != nil {
+		// 超大响应体从临时文件流式写回，不缓存也不压缩
+		if cacheKey != "" {
+			w.Header().Set("X-Cache", "MISS")
+		}
+		writeSpooledResponse(w, result.StatusCode, result.Headers, result.Spooled)
 		return
 	}
-	writeResponseWithHeaders(w, result.StatusCode, result.Headers, result.Body)
+	if cacheKey != "" {
+		if result.Error == nil && result.StatusCode == http.StatusOK && execResult.Endpoint != nil && execResult.Endpoint.ID == endpoint.ID {
+			cache.Put(cacheKey, result.StatusCode, result.Headers, result.Body, result.TargetURL)
+		}
+		w.Header().Set("X-Cache", "MISS")
+	}
+	p.writeClientResponse(w, r, result.StatusCode, result.Headers, result.Body)
 }
 
 func isStreamRequested(body []byte) bool {
@@ -207,3 +419,26 @@ func targetHeadersFromResult(result *executor.ForwardResult) map[string]string {
 	}
 	return result.TargetHeaders
 }
+
+// upstreamTargetURL 计算请求在该端点上的实际转发目标 URL（用于 started 日志/控制台展示）；
+// 配置了 transformer 时使用转换后的目标路径
+func upstreamTargetURL(endpoint *executor.EndpointConfig, interfaceType InterfaceType, r *http.Request, bodyBytes []byte, isStreaming bool) string {
+	targetURL := strings.TrimSuffix(endpoint.APIURL, "/") + r.URL.Path
+	if target, err := executor.BuildTargetURL(endpoint.APIURL, r.URL.Path, r.URL.RawQuery); err == nil && target != "" {
+		targetURL = target
+	}
+	if strings.TrimSpace(endpoint.Transformer) == "" {
+		return targetURL
+	}
+	if tr, err := transformer.Get(strings.TrimSpace(string(interfaceType)), endpoint.Transformer); err == nil && tr != nil {
+		requestModel := extractModelFromBody(bodyBytes)
+		upstreamModel := executor.ResolveUpstreamModel(requestModel, endpoint)
+		targetPath := tr.TargetPath(isStreaming, upstreamModel)
+		if strings.TrimSpace(targetPath) != "" {
+			if target, err := executor.BuildTargetURL(endpoint.APIURL, targetPath, r.URL.RawQuery); err == nil && target != "" {
+				targetURL = target
+			}
+		}
+	}
+	return targetURL
+}
@@ -1,8 +1,12 @@
 package proxy
 
 import (
+	"log"
 	"net/http"
+	"strconv"
 	"strings"
+
+	"clisimplehub/internal/executor"
 )
 
 func writeResponseWithHeaders(w http.ResponseWriter, statusCode int, headers http.Header, body []byte) {
@@ -19,3 +23,20 @@ func writeResponseWithHeaders(w http.ResponseWriter, statusCode int, headers htt
 	w.WriteHeader(statusCode)
 	_, _ = w.Write(body)
 }
+
+// writeSpooledResponse streams a response body spooled to disk with its exact Content-Length
+func writeSpooledResponse(w http.ResponseWriter, statusCode int, headers http.Header, body *executor.SpooledBody) {
+	for key, values := range headers {
+		if strings.EqualFold(key, "Content-Length") {
+			continue
+		}
+		for _, v := range values {
+			w.Header().Add(key, v)
+		}
+	}
+	w.Header().Set("Content-Length", strconv.FormatInt(body.Size(), 10))
+	w.WriteHeader(statusCode)
+	if _, err := body.WriteTo(w); err != nil {
+		log.Printf("Warning: Failed to write spooled response (%d bytes): %v", body.Size(), err)
+	}
+}
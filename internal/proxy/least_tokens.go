@@ -0,0 +1,157 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"clisimplehub/internal/statsdb"
+)
+
+// Routing modes selecting the endpoint for new requests
+const (
+	// RoutingModePriority uses the active endpoint and falls back in priority order
+	RoutingModePriority = "priority"
+	// RoutingModeLeastTokens routes to the enabled endpoint with the fewest tokens used today
+	RoutingModeLeastTokens = "least-tokens"
+	// RoutingModeRandom spreads requests across enabled endpoints at random. It is the
+	// randomized selection SetRand seeds; the other modes are deterministic.
+	RoutingModeRandom = "random"
+)
+
+// tokenUsageRefreshInterval is how long the router reuses today's token usage before querying again
+const tokenUsageRefreshInterval = 30 * time.Second
+
+// tokenUsageQueryTimeout bounds the stats query made on the request path
+const tokenUsageQueryTimeout = 2 * time.Second
+
+// TokenUsageSource provides today's per-endpoint usage for least-tokens routing and daily token budgets
+type TokenUsageSource interface {
+	GetTodayStatsByEndpoints(ctx context.Context) (map[string]*statsdb.EndpointDailyStats, error)
+}
+
+// tokenUsageView caches today's token usage per endpoint ID
+type tokenUsageView struct {
+	mu         sync.Mutex
+	source     TokenUsageSource
+	usage      map[int64]int64 // nil when stats are unavailable
+	fetchedAt  time.Time
+	refreshing bool
+}
+
+// SetRoutingMode selects how new requests pick an endpoint ("" means priority)
+func (r *DefaultRouter) SetRoutingMode(mode string) error {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	switch mode {
+	case "":
+		mode = RoutingModePriority
+	case RoutingModePriority, RoutingModeLeastTokens, RoutingModeRandom:
+	default:
+		return fmt.Errorf("invalid routing mode: %s", mode)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routingMode = mode
+	return nil
+}
+
+// RoutingMode returns the current routing mode
+func (r *DefaultRouter) RoutingMode() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.routingMode == "" {
+		return RoutingModePriority
+	}
+	return r.routingMode
+}
+
+// SetTokenUsageSource injects the stats store consulted in least-tokens mode and for daily token budgets
+func (r *DefaultRouter) SetTokenUsageSource(source TokenUsageSource) {
+	r.tokenUsage.mu.Lock()
+	defer r.tokenUsage.mu.Unlock()
+	r.tokenUsage.source = source
+	r.tokenUsage.usage = nil
+	r.tokenUsage.fetchedAt = time.Time{}
+}
+
+// todayTokenUsage returns today's tokens per endpoint ID, refreshing the view when it is stale.
+// Only one caller queries the store at a time; concurrent callers use the previous view.
+// It must not be called with r.mu held.
+func (r *DefaultRouter) todayTokenUsage() map[int64]int64 {
+	v := &r.tokenUsage
+	v.mu.Lock()
+	source := v.source
+	if source == nil || v.refreshing || time.Since(v.fetchedAt) < tokenUsageRefreshInterval {
+		usage := v.usage
+		v.mu.Unlock()
+		return usage
+	}
+	v.refreshing = true
+	v.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), tokenUsageQueryTimeout)
+	stats, err := source.GetTodayStatsByEndpoints(ctx)
+	cancel()
+
+	var usage map[int64]int64
+	if err == nil {
+		usage = make(map[int64]int64, len(stats))
+		for key, s := range stats {
+			id, parseErr := strconv.ParseInt(key, 10, 64)
+			if parseErr != nil || s == nil {
+				continue
+			}
+			usage[id] = s.InputTokens + s.OutputTokens
+		}
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.refreshing = false
+	v.fetchedAt = time.Now()
+	v.usage = usage
+	return usage
+}
+
+// recordTokenUsage adds a finished request's tokens to the cached view, so least-tokens
+// routing and daily budgets see them before the next refresh from the stats store.
+// The map is copied rather than updated in place because callers read it without locking.
+func (r *DefaultRouter) recordTokenUsage(endpointID int64, tokens int64) {
+	if tokens <= 0 {
+		return
+	}
+	v := &r.tokenUsage
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.usage == nil {
+		return
+	}
+	usage := make(map[int64]int64, len(v.usage)+1)
+	for id, n := range v.usage {
+		usage[id] = n
+	}
+	usage[endpointID] += tokens
+	v.usage = usage
+}
+
+// leastTokensEndpointLocked returns the routable endpoint with the fewest tokens used today.
+// Ties keep priority order. Must be called with r.mu held.
+func (r *DefaultRouter) leastTokensEndpointLocked(interfaceType InterfaceType, usage map[int64]int64, now time.Time) *Endpoint {
+	var selected *Endpoint
+	var selectedTokens int64
+	for _, ep := range r.endpoints[interfaceType] {
+		if !ep.isAutoRoutable(now) {
+			continue
+		}
+		tokens := usage[ep.ID]
+		if selected == nil || tokens < selectedTokens {
+			selected = ep
+			selectedTokens = tokens
+		}
+	}
+	return selected
+}
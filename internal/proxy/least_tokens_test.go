@@ -0,0 +1,135 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"clisimplehub/internal/statsdb"
+)
+
+// fakeTokenUsageSource serves fixed per-endpoint stats and counts queries
+type fakeTokenUsageSource struct {
+	stats   map[string]*statsdb.EndpointDailyStats
+	err     error
+	queries atomic.Int32
+}
+
+func (s *fakeTokenUsageSource) GetTodayStatsByEndpoints(ctx context.Context) (map[string]*statsdb.EndpointDailyStats, error) {
+	s.queries.Add(1)
+	return s.stats, s.err
+}
+
+func newLeastTokensRouter(t *testing.T, source TokenUsageSource) *DefaultRouter {
+	t.Helper()
+	router := NewRouter()
+	router.LoadEndpoints([]*Endpoint{
+		{ID: 1, Name: "a", InterfaceType: "claude", Enabled: true, Active: true},
+		{ID: 2, Name: "b", InterfaceType: "claude", Enabled: true},
+		{ID: 3, Name: "c", InterfaceType: "claude", Enabled: true},
+		{ID: 4, Name: "off", InterfaceType: "claude", Enabled: false},
+	})
+	if err := router.SetRoutingMode(RoutingModeLeastTokens); err != nil {
+		t.Fatalf("SetRoutingMode: %v", err)
+	}
+	router.SetTokenUsageSource(source)
+	return router
+}
+
+func TestSetRoutingMode(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		mode    string
+		want    string
+		wantErr bool
+	}{
+		{mode: "", want: RoutingModePriority},
+		{mode: " Least-Tokens ", want: RoutingModeLeastTokens},
+		{mode: "round-robin", wantErr: true},
+	}
+	for _, tc := range cases {
+		router := NewRouter()
+		err := router.SetRoutingMode(tc.mode)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("%q: err=%v wantErr=%v", tc.mode, err, tc.wantErr)
+		}
+		if !tc.wantErr && router.RoutingMode() != tc.want {
+			t.Fatalf("%q: mode=%s want %s", tc.mode, router.RoutingMode(), tc.want)
+		}
+	}
+}
+
+func TestLeastTokensRouting(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		stats  map[string]*statsdb.EndpointDailyStats
+		err    error
+		wantEP string
+	}{
+		{
+			name: "fewest tokens",
+			stats: map[string]*statsdb.EndpointDailyStats{
+				"1": {InputTokens: 500, OutputTokens: 500},
+				"2": {InputTokens: 100, OutputTokens: 50},
+				"3": {InputTokens: 300},
+				"4": {},
+			},
+			wantEP: "b",
+		},
+		{
+			name:   "endpoints without usage count as zero and ties keep priority order",
+			stats:  map[string]*statsdb.EndpointDailyStats{"1": {InputTokens: 10}},
+			wantEP: "b",
+		},
+		{name: "stats unavailable falls back to priority", err: errors.New("db closed"), wantEP: "a"},
+	}
+	for _, tc := range cases {
+		router := newLeastTokensRouter(t, &fakeTokenUsageSource{stats: tc.stats, err: tc.err})
+		ep := router.GetActiveEndpoint(InterfaceTypeClaude)
+		if ep == nil || ep.Name != tc.wantEP {
+			t.Fatalf("%s: got %v want %s", tc.name, ep, tc.wantEP)
+		}
+		if active := router.active[InterfaceTypeClaude]; active == nil || active.Name != "a" {
+			t.Fatalf("%s: routing read changed the active endpoint to %v", tc.name, active)
+		}
+	}
+}
+
+func TestLeastTokensUsageCached(t *testing.T) {
+	t.Parallel()
+
+	source := &fakeTokenUsageSource{stats: map[string]*statsdb.EndpointDailyStats{
+		"1": {InputTokens: 100},
+		"2": {InputTokens: 150},
+		"3": {InputTokens: 400},
+	}}
+	router := newLeastTokensRouter(t, source)
+
+	if ep := router.GetActiveEndpoint(InterfaceTypeClaude); ep.Name != "a" {
+		t.Fatalf("first pick=%s want a", ep.Name)
+	}
+	// Tokens recorded by finished requests shift the choice without querying the store again
+	router.recordTokenUsage(1, 100)
+	if ep := router.GetActiveEndpoint(InterfaceTypeClaude); ep.Name != "b" {
+		t.Fatalf("pick after recording=%s want b", ep.Name)
+	}
+	router.recordTokenUsage(2, 0)
+	router.recordTokenUsage(2, 200)
+	if ep := router.GetActiveEndpoint(InterfaceTypeClaude); ep.Name != "a" {
+		t.Fatalf("pick after recording b=%s want a", ep.Name)
+	}
+	if n := source.queries.Load(); n != 1 {
+		t.Fatalf("store queried %d times want 1", n)
+	}
+
+	// A new source drops the cached view
+	router.SetTokenUsageSource(source)
+	router.GetActiveEndpoint(InterfaceTypeClaude)
+	if n := source.queries.Load(); n != 2 {
+		t.Fatalf("store queried %d times after reset want 2", n)
+	}
+}
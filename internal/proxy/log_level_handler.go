@@ -0,0 +1,62 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"clisimplehub/internal/logger"
+)
+
+// handleLogLevel reads or changes the runtime log level.
+// It requires the same auth key as proxied requests.
+//
+// GET /log-level
+// - {"level":"INFO"}
+//
+// PUT|POST /log-level?level=debug (or JSON body {"level":"debug"})
+// - {"level":"DEBUG"}
+func (p *ProxyServer) handleLogLevel(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	writeError := func(status int, msg string) {
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(map[string]any{"error": msg})
+	}
+
+	if !p.authorizeAdmin(r) {
+		writeError(http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		name := strings.TrimSpace(r.URL.Query().Get("level"))
+		if name == "" {
+			var body struct {
+				Level string `json:"level"`
+			}
+			if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&body); err != nil {
+				writeError(http.StatusBadRequest, "level is required")
+				return
+			}
+			name = body.Level
+		}
+		level, err := logger.ParseLevel(name)
+		if err != nil {
+			writeError(http.StatusBadRequest, err.Error())
+			return
+		}
+		if level != logger.GetDefaultLevel() {
+			logger.SetDefaultLevel(level)
+			logger.Info("[Proxy] log level set to %s", level)
+		}
+	default:
+		writeError(http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]any{"level": logger.GetDefaultLevel().String()})
+}
@@ -0,0 +1,63 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"clisimplehub/internal/logger"
+)
+
+// TestHandleLogLevel changes the process-wide log level, so it does not run in parallel
+func TestHandleLogLevel(t *testing.T) {
+	original := logger.GetDefaultLevel()
+	t.Cleanup(func() { logger.SetDefaultLevel(original) })
+	logger.SetDefaultLevel(logger.LevelInfo)
+
+	cases := []struct {
+		name       string
+		method     string
+		query      string
+		body       string
+		auth       string
+		wantStatus int
+		wantLevel  string
+	}{
+		{name: "unauthorized", method: http.MethodGet, wantStatus: http.StatusUnauthorized},
+		{name: "get", method: http.MethodGet, auth: "admin", wantStatus: http.StatusOK, wantLevel: "INFO"},
+		{name: "post query", method: http.MethodPost, query: "level=debug", auth: "admin", wantStatus: http.StatusOK, wantLevel: "DEBUG"},
+		{name: "put body", method: http.MethodPut, body: `{"level":"warning"}`, auth: "admin", wantStatus: http.StatusOK, wantLevel: "WARN"},
+		{name: "invalid level keeps current", method: http.MethodPost, query: "level=trace", auth: "admin", wantStatus: http.StatusBadRequest},
+		{name: "missing level", method: http.MethodPost, auth: "admin", wantStatus: http.StatusBadRequest},
+		{name: "unauthorized post", method: http.MethodPost, query: "level=error", auth: "wrong", wantStatus: http.StatusUnauthorized},
+		{name: "method", method: http.MethodDelete, auth: "admin", wantStatus: http.StatusMethodNotAllowed},
+		{name: "level after failures", method: http.MethodGet, auth: "admin", wantStatus: http.StatusOK, wantLevel: "WARN"},
+	}
+	p := NewProxyServer(0, NewRouter())
+	p.SetAuthKey("admin")
+	for _, tc := range cases {
+		r := httptest.NewRequest(tc.method, "/log-level?"+tc.query, strings.NewReader(tc.body))
+		if tc.auth != "" {
+			r.Header.Set("Authorization", "Bearer "+tc.auth)
+		}
+		w := httptest.NewRecorder()
+		p.handleLogLevel(w, r)
+		if w.Code != tc.wantStatus {
+			t.Fatalf("%s: status=%d want %d body=%s", tc.name, w.Code, tc.wantStatus, w.Body.String())
+		}
+		if tc.wantLevel == "" {
+			continue
+		}
+		var body struct {
+			Level string `json:"level"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Level != tc.wantLevel {
+			t.Fatalf("%s: body=%s want level %s", tc.name, w.Body.String(), tc.wantLevel)
+		}
+		if got := logger.GetDefaultLevel().String(); got != tc.wantLevel {
+			t.Fatalf("%s: logger level=%s want %s", tc.name, got, tc.wantLevel)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleProxyRejectsForwardLoop(t *testing.T) {
+	t.Parallel()
+
+	// The endpoint points back at the proxy itself through another listener, so the
+	// self-port check does not apply and only the forwarded marker can stop the loop
+	router := NewRouter()
+	p := NewProxyServer(0, router)
+	self := httptest.NewServer(http.HandlerFunc(p.handleProxy))
+	defer self.Close()
+	router.LoadEndpoints([]*Endpoint{{ID: 1, Name: "self", APIURL: self.URL, InterfaceType: "claude", Enabled: true, Active: true}})
+
+	w := httptest.NewRecorder()
+	p.handleProxy(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"m","messages":[]}`)))
+	if w.Code != http.StatusLoopDetected || !strings.Contains(w.Body.String(), "loop") {
+		t.Fatalf("status=%d body=%s", w.Code, w.Body.String())
+	}
+
+	// One log for the client request and one for the rejected hop: the loop stopped there
+	logs := p.stats.GetRecentLogs(0)
+	if len(logs) != 2 {
+		t.Fatalf("logs=%d want 2: %+v", len(logs), logs)
+	}
+	for _, log := range logs {
+		if log.Status != "error_508" {
+			t.Fatalf("log status=%s want error_508", log.Status)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package proxy
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"clisimplehub/internal/executor"
+)
+
+// manualReenableRouter is implemented by routers that can keep failed endpoints
+// disabled until the user re-enables them
+type manualReenableRouter interface {
+	ManualReenable() bool
+}
+
+// isManualReenable reports whether failed endpoints stay disabled until re-enabled by the user
+func (p *ProxyServer) isManualReenable() bool {
+	r, ok := p.router.(manualReenableRouter)
+	return ok && r.ManualReenable()
+}
+
+// onEndpointDisabled persists the disable in manual re-enable mode and notifies clients
+func (p *ProxyServer) onEndpointDisabled(interfaceType string, endpoint *executor.EndpointConfig, disabledAt time.Time) {
+	if endpoint == nil || disabledAt.IsZero() {
+		return
+	}
+	manual := p.isManualReenable()
+	p.notifyEndpointDisabled(interfaceType, endpoint, disabledAt, manual)
+	if !manual {
+		p.broadcastEndpointTempDisabled(interfaceType, endpoint, disabledAt)
+		return
+	}
+
+	persisted := true
+	if err := p.persistEndpointDisabled(endpoint); err != nil {
+		// the router keeps the endpoint disabled in memory until the next reload
+		persisted = false
+		log.Printf("Warning: persist disabled endpoint %s failed: %v", endpointNameOrID(proxyEndpointFromConfig(endpoint)), err)
+	}
+
+	if p.wsHub == nil {
+		return
+	}
+	p.wsHub.BroadcastEndpointReenableRequired(&EndpointReenableRequiredPayload{
+		InterfaceType: strings.TrimSpace(interfaceType),
+		EndpointID:    endpoint.ID,
+		EndpointName:  endpoint.Name,
+		DisabledAt:    unixMillis(disabledAt),
+		Persisted:     persisted,
+	})
+}
+
+// persistEndpointDisabled saves Enabled=false for the endpoint. A disabled endpoint
+// cannot stay active, so the active flag is cleared as well.
+func (p *ProxyServer) persistEndpointDisabled(endpoint *executor.EndpointConfig) error {
+	p.mu.RLock()
+	store := p.store
+	p.mu.RUnlock()
+
+	if store == nil {
+		return fmt.Errorf("storage not initialized")
+	}
+	if endpoint.ID <= 0 {
+		return fmt.Errorf("endpoint has no id")
+	}
+
+	ep, err := store.GetEndpointByID(endpoint.ID)
+	if err != nil {
+		return err
+	}
+	if ep == nil {
+		return ErrEndpointNotFound
+	}
+	if !ep.Enabled && !ep.Active {
+		return nil
+	}
+	ep.Enabled = false
+	ep.Active = false
+	return store.UpdateEndpoint(ep)
+}
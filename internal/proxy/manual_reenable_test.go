@@ -0,0 +1,121 @@
+package proxy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"clisimplehub/internal/config"
+	"clisimplehub/internal/executor"
+	"clisimplehub/internal/storage"
+)
+
+func TestManualReenableKeepsEndpointDisabled(t *testing.T) {
+	t.Parallel()
+
+	endpoints := func() []*Endpoint {
+		return []*Endpoint{
+			{ID: 1, Name: "a", InterfaceType: "claude", Enabled: true, Active: true},
+			{ID: 2, Name: "b", InterfaceType: "claude", Enabled: true},
+		}
+	}
+	for _, manual := range []bool{false, true} {
+		router := NewRouter()
+		router.LoadEndpoints(endpoints())
+		router.SetTempDisableTTL(10 * time.Millisecond)
+		router.SetManualReenable(manual)
+
+		eps := router.GetEndpointsByType(InterfaceTypeClaude)
+		// the returned time is the restore time, or when the endpoint was disabled in manual mode
+		until := router.DisableEndpoint(InterfaceTypeClaude, eps[0])
+		if until.IsZero() || until.After(time.Now()) == manual {
+			t.Fatalf("manual=%v: DisableEndpoint returned %v", manual, until)
+		}
+		time.Sleep(30 * time.Millisecond)
+
+		enabled := router.GetEnabledEndpointsByType(InterfaceTypeClaude)
+		if restored := len(enabled) == 2; restored == manual {
+			t.Fatalf("manual=%v: enabled endpoints after TTL=%d", manual, len(enabled))
+		}
+		for _, s := range router.State()[InterfaceTypeClaude] {
+			if s.Name == "a" && s.ManualDisabled != manual {
+				t.Fatalf("manual=%v: state=%+v", manual, s)
+			}
+		}
+		if !manual {
+			continue
+		}
+
+		// Turning the mode off does not release endpoints already waiting for the user
+		router.SetManualReenable(false)
+		if len(router.GetEnabledEndpointsByType(InterfaceTypeClaude)) != 1 {
+			t.Fatalf("endpoint restored after the mode was turned off")
+		}
+		// Re-enabling in the config reloads the router and clears the disable
+		router.LoadEndpoints(endpoints())
+		if len(router.GetEnabledEndpointsByType(InterfaceTypeClaude)) != 2 {
+			t.Fatalf("endpoint still disabled after being re-enabled")
+		}
+	}
+}
+
+func TestOnEndpointDisabledPersistsInManualMode(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	raw := `{"vendors":[{"id":1,"name":"v","endpoints":[
+		{"id":7,"name":"a","apiUrl":"http://a","apiKey":"k","interfaceType":"claude","enabled":true,"active":true}
+	]}]}`
+	if err := os.WriteFile(path, []byte(raw), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	store, err := storage.NewConfigFileStore(config.NewConfigLoader(path))
+	if err != nil {
+		t.Fatalf("NewConfigFileStore: %v", err)
+	}
+
+	cases := []struct {
+		name          string
+		manual        bool
+		store         storage.Storage
+		wantPersisted bool
+		wantMessage   WSMessageType
+	}{
+		{name: "temporary disable only broadcasts", manual: false, store: store, wantMessage: WSMessageTypeEndpointTempDisabled},
+		{name: "manual mode without storage", manual: true, wantMessage: WSMessageTypeEndpointReenableRequired},
+		{name: "manual mode persists", manual: true, store: store, wantPersisted: true, wantMessage: WSMessageTypeEndpointReenableRequired},
+	}
+	for _, tc := range cases {
+		router := NewRouter()
+		router.SetManualReenable(tc.manual)
+		hub := NewWSHub()
+		p := NewProxyServerWithWSHub(0, router, hub)
+		if tc.store != nil {
+			p.SetStorage(tc.store)
+		}
+
+		p.onEndpointDisabled("claude", &executor.EndpointConfig{ID: 7, Name: "a"}, time.Now())
+
+		var msg *WSMessage
+		select {
+		case msg = <-hub.broadcast:
+		default:
+			t.Fatalf("%s: nothing broadcast", tc.name)
+		}
+		if msg.Type != tc.wantMessage {
+			t.Fatalf("%s: message type=%s want %s", tc.name, msg.Type, tc.wantMessage)
+		}
+		if payload, ok := msg.Payload.(*EndpointReenableRequiredPayload); ok && (payload.Persisted != tc.wantPersisted || payload.EndpointID != 7) {
+			t.Fatalf("%s: payload=%+v", tc.name, payload)
+		}
+
+		ep, err := store.GetEndpointByID(7)
+		if err != nil {
+			t.Fatalf("%s: GetEndpointByID: %v", tc.name, err)
+		}
+		if stored := !ep.Enabled && !ep.Active; stored != tc.wantPersisted {
+			t.Fatalf("%s: stored enabled=%v active=%v", tc.name, ep.Enabled, ep.Active)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// isModelsListRequest reports whether the request is a model discovery call (GET .../v1/models)
+func isModelsListRequest(r *http.Request) bool {
+	if r.Method != http.MethodGet {
+		return false
+	}
+	path := strings.TrimSuffix(strings.ToLower(r.URL.Path), "/")
+	return strings.HasSuffix(path, "/v1/models")
+}
+
+// modelsListInterfaceTypes picks the interface types whose models a discovery call should see.
+// The bare /v1/models path detects as claude by default, so Anthropic clients are recognized
+// by their anthropic-version header and everything else is treated as an OpenAI client.
+func modelsListInterfaceTypes(r *http.Request, detected InterfaceType) []InterfaceType {
+	if detected != InterfaceTypeClaude {
+		return []InterfaceType{detected}
+	}
+	if strings.TrimSpace(r.Header.Get("anthropic-version")) != "" {
+		return []InterfaceType{InterfaceTypeClaude}
+	}
+	return []InterfaceType{InterfaceTypeCodex, InterfaceTypeChat}
+}
+
+// aggregateModelAliases returns the sorted union of exact model aliases across enabled endpoints
+func (p *ProxyServer) aggregateModelAliases(types []InterfaceType) []string {
+	seen := make(map[string]bool)
+	var aliases []string
+	for _, it := range types {
+		for _, ep := range p.router.GetEndpointsByType(it) {
+			if !ep.isRoutable(time.Now()) {
+				continue
+			}
+			for _, m := range ep.Models {
+				// prefix/regex aliases are patterns, not model ids a client can pick
+				if mt := strings.ToLower(strings.TrimSpace(m.MatchType)); mt != "" && mt != "exact" {
+					continue
+				}
+				alias := strings.TrimSpace(m.Alias)
+				if alias == "" {
+					alias = strings.TrimSpace(m.Name)
+				}
+				if alias == "" || seen[alias] {
+					continue
+				}
+				seen[alias] = true
+				aliases = append(aliases, alias)
+			}
+		}
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+// handleModelsList answers model discovery calls with the models the hub can serve.
+// Returns false (request proxied upstream as before) when no endpoint defines model aliases.
+func (p *ProxyServer) handleModelsList(w http.ResponseWriter, r *http.Request, interfaceType InterfaceType) bool {
+	aliases := p.aggregateModelAliases(modelsListInterfaceTypes(r, interfaceType))
+	if len(aliases) == 0 {
+		return false
+	}
+
+	// OpenAI list shape, with the Anthropic fields added so both SDKs can parse it
+	created := time.Now().Unix()
+	createdAt := time.Unix(created, 0).UTC().Format(time.RFC3339)
+	data := make([]map[string]interface{}, 0, len(aliases))
+	for _, alias := range aliases {
+		data = append(data, map[string]interface{}{
+			"id":           alias,
+			"object":       "model",
+			"created":      created,
+			"owned_by":     "clisimplehub",
+			"type":         "model",
+			"display_name": alias,
+			"created_at":   createdAt,
+		})
+	}
+	body := map[string]interface{}{
+		"object":   "list",
+		"data":     data,
+		"has_more": false,
+		"first_id": aliases[0],
+		"last_id":  aliases[len(aliases)-1],
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(body)
+	return true
+}
@@ -0,0 +1,92 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestHandleProxyModelsList(t *testing.T) {
+	t.Parallel()
+
+	var upstreamHits atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamHits.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"upstream-model"}]}`))
+	}))
+	defer upstream.Close()
+
+	router := NewRouter()
+	router.LoadEndpoints([]*Endpoint{
+		{ID: 1, Name: "claude-a", APIURL: upstream.URL, InterfaceType: "claude", Enabled: true, Active: true, Models: []ModelMapping{
+			{Name: "claude-sonnet-4-20250514", Alias: "sonnet"},
+			{Name: "claude-opus-4", Alias: "opus-*", MatchType: "prefix"},
+		}},
+		{ID: 2, Name: "codex-a", APIURL: upstream.URL, InterfaceType: "codex", Enabled: true, Active: true, Models: []ModelMapping{
+			{Name: "gpt-5", Alias: "gpt"},
+			{Name: "gpt-5-mini"},
+		}},
+		{ID: 3, Name: "chat-a", APIURL: upstream.URL, InterfaceType: "chat", Enabled: true, Active: true, Models: []ModelMapping{
+			{Name: "deepseek-chat", Alias: "gpt", MatchType: "exact"},
+			{Name: "qwen", Alias: "q.*", MatchType: "regex"},
+		}},
+		{ID: 4, Name: "chat-off", APIURL: upstream.URL, InterfaceType: "chat", Enabled: false, Models: []ModelMapping{{Name: "disabled-model"}}},
+		{ID: 5, Name: "gemini-a", APIURL: upstream.URL, InterfaceType: "gemini", Enabled: true, Active: true},
+	})
+	p := NewProxyServer(0, router)
+
+	cases := []struct {
+		name         string
+		path         string
+		anthropic    bool
+		wantIDs      []string
+		wantUpstream bool
+	}{
+		{name: "openai client sees codex and chat aliases", path: "/v1/models", wantIDs: []string{"gpt", "gpt-5-mini"}},
+		{name: "anthropic client sees claude aliases", path: "/v1/models/", anthropic: true, wantIDs: []string{"sonnet"}},
+		{name: "no aliases proxies upstream", path: "/gemini/v1/models", wantUpstream: true},
+	}
+	for _, tc := range cases {
+		upstreamHits.Store(0)
+		r := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		if tc.anthropic {
+			r.Header.Set("anthropic-version", "2023-06-01")
+		}
+		w := httptest.NewRecorder()
+		p.handleProxy(w, r)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status=%d body=%s", tc.name, w.Code, w.Body.String())
+		}
+		if tc.wantUpstream {
+			if upstreamHits.Load() != 1 {
+				t.Fatalf("%s: upstream hits=%d want 1", tc.name, upstreamHits.Load())
+			}
+			continue
+		}
+
+		var body struct {
+			Object string `json:"object"`
+			Data   []struct {
+				ID     string `json:"id"`
+				Object string `json:"object"`
+			} `json:"data"`
+			FirstID string `json:"first_id"`
+			LastID  string `json:"last_id"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s: decode: %v body=%s", tc.name, err, w.Body.String())
+		}
+		var ids []string
+		for _, m := range body.Data {
+			ids = append(ids, m.ID)
+		}
+		if upstreamHits.Load() != 0 || body.Object != "list" || !reflect.DeepEqual(ids, tc.wantIDs) ||
+			body.FirstID != tc.wantIDs[0] || body.LastID != tc.wantIDs[len(tc.wantIDs)-1] {
+			t.Fatalf("%s: upstream hits=%d body=%s want ids %v", tc.name, upstreamHits.Load(), w.Body.String(), tc.wantIDs)
+		}
+	}
+}
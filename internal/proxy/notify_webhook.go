@@ -0,0 +1,269 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"clisimplehub/internal/executor"
+)
+
+// Webhook notification event types
+const (
+	NotifyEventFallback     = "fallback"
+	NotifyEventTempDisabled = "temp_disabled"
+	NotifyEventRecovered    = "recovered"
+)
+
+const (
+	// notifyWebhookTimeout bounds a single delivery attempt
+	notifyWebhookTimeout = 5 * time.Second
+	// notifyWebhookAttempts is the number of delivery attempts per event
+	notifyWebhookAttempts = 3
+	// notifyWebhookBackoff is the delay before the first retry; it doubles after each failure
+	notifyWebhookBackoff = time.Second
+	// notifyWebhookMaxPending caps deliveries in flight; further events are dropped
+	notifyWebhookMaxPending = 16
+	// notifyFallbackInterval is the minimum gap between fallback events from the same endpoint;
+	// a failing endpoint would otherwise post one event per request it fails
+	notifyFallbackInterval = 5 * time.Minute
+)
+
+// NotifyEvent is the JSON payload posted to the notification webhook
+type NotifyEvent struct {
+	Event         string `json:"event"`
+	InterfaceType string `json:"interfaceType,omitempty"`
+	EndpointID    int64  `json:"endpointId,omitempty"`
+	Endpoint      string `json:"endpoint"`
+	Vendor        string `json:"vendor,omitempty"`
+	ToEndpoint    string `json:"toEndpoint,omitempty"` // fallback target
+	ToVendor      string `json:"toVendor,omitempty"`
+	Reason        string `json:"reason,omitempty"`
+	Time          string `json:"time"` // RFC 3339
+}
+
+// WebhookNotifier posts endpoint failover events to a webhook (e.g. a Slack incoming webhook
+// relay). Delivery is asynchronous with a per-attempt timeout and bounded retries, so a slow or
+// failing webhook never delays proxying. Fallback events are throttled per endpoint.
+type WebhookNotifier struct {
+	url     string
+	client  *http.Client
+	pending chan struct{}
+	backoff time.Duration
+	now     func() time.Time
+
+	mu           sync.Mutex
+	lastFallback map[string]time.Time // interfaceType/endpoint -> last fallback event posted
+}
+
+// NewWebhookNotifier creates a notifier posting to url
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:          url,
+		client:       &http.Client{Timeout: notifyWebhookTimeout},
+		pending:      make(chan struct{}, notifyWebhookMaxPending),
+		backoff:      notifyWebhookBackoff,
+		now:          time.Now,
+		lastFallback: make(map[string]time.Time),
+	}
+}
+
+// Notify delivers the event in the background and reports whether it was queued. Fallback
+// events for an endpoint already reported within notifyFallbackInterval are skipped, and
+// events are dropped when too many deliveries are already pending.
+func (n *WebhookNotifier) Notify(event NotifyEvent) bool {
+	if n == nil {
+		return false
+	}
+	if event.Time == "" {
+		event.Time = n.now().Format(time.RFC3339)
+	}
+	if !n.admit(event) {
+		return false
+	}
+	select {
+	case n.pending <- struct{}{}:
+	default:
+		log.Printf("Warning: notification webhook busy, dropped %s event for %s", event.Event, event.Endpoint)
+		return false
+	}
+	go func() {
+		defer func() { <-n.pending }()
+		if err := n.deliver(event); err != nil {
+			log.Printf("Warning: notification webhook failed for %s event: %v", event.Event, err)
+		}
+	}()
+	return true
+}
+
+// admit applies the per-endpoint fallback throttle. A recovery resets it so the endpoint's
+// next outage is reported right away.
+func (n *WebhookNotifier) admit(event NotifyEvent) bool {
+	key := event.InterfaceType + "/" + event.Endpoint
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	switch event.Event {
+	case NotifyEventFallback:
+		now := n.now()
+		if last, ok := n.lastFallback[key]; ok && now.Sub(last) < notifyFallbackInterval {
+			return false
+		}
+		n.lastFallback[key] = now
+	case NotifyEventRecovered:
+		delete(n.lastFallback, key)
+	}
+	return true
+}
+
+// deliver posts the event, retrying network errors and 5xx/429 responses
+func (n *WebhookNotifier) deliver(event NotifyEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	backoff := n.backoff
+	for attempt := 1; ; attempt++ {
+		retryable, err := n.post(body)
+		if err == nil {
+			return nil
+		}
+		if !retryable || attempt >= notifyWebhookAttempts {
+			return fmt.Errorf("attempt %d: %w", attempt, err)
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (n *WebhookNotifier) post(body []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), notifyWebhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retryable := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retryable, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+}
+
+// restoreHookRouter is implemented by routers that report endpoints restored after a temporary disable
+type restoreHookRouter interface {
+	SetRestoreHook(hook func(InterfaceType, *Endpoint))
+}
+
+// SetNotifyWebhookURL posts fallback, temp-disable and recovery events to url (empty disables it)
+func (p *ProxyServer) SetNotifyWebhookURL(url string) {
+	url = strings.TrimSpace(url)
+
+	p.mu.Lock()
+	if url == "" {
+		p.notifier = nil
+	} else if p.notifier == nil || p.notifier.url != url {
+		p.notifier = NewWebhookNotifier(url)
+	}
+	router := p.router
+	p.mu.Unlock()
+
+	if hooked, ok := router.(restoreHookRouter); ok {
+		if url != "" {
+			hooked.SetRestoreHook(func(interfaceType InterfaceType, endpoint *Endpoint) {
+				p.notifyEndpointRecovered(interfaceType, endpoint, "temporary disable expired")
+			})
+		} else {
+			hooked.SetRestoreHook(nil)
+		}
+	}
+}
+
+func (p *ProxyServer) getNotifier() *WebhookNotifier {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.notifier
+}
+
+// notifyFallback sends a fallback event when a webhook is configured
+func (p *ProxyServer) notifyFallback(payload *FallbackSwitchPayload, interfaceType string) {
+	notifier := p.getNotifier()
+	if notifier == nil || payload == nil {
+		return
+	}
+	reason := strings.TrimSpace(payload.ErrorMessage)
+	if payload.StatusCode > 0 {
+		reason = strings.TrimSpace(fmt.Sprintf("status %d %s", payload.StatusCode, reason))
+	}
+	notifier.Notify(NotifyEvent{
+		Event:         NotifyEventFallback,
+		InterfaceType: interfaceType,
+		Endpoint:      payload.FromEndpoint,
+		Vendor:        payload.FromVendor,
+		ToEndpoint:    payload.ToEndpoint,
+		ToVendor:      payload.ToVendor,
+		Reason:        reason,
+	})
+}
+
+// notifyEndpointDisabled sends a temp-disable event when a webhook is configured
+func (p *ProxyServer) notifyEndpointDisabled(interfaceType string, endpoint *executor.EndpointConfig, until time.Time, manual bool) {
+	notifier := p.getNotifier()
+	if notifier == nil || endpoint == nil {
+		return
+	}
+	reason := "disabled until " + until.Format(time.RFC3339)
+	if manual {
+		reason = "disabled until manually re-enabled"
+	}
+	notifier.Notify(NotifyEvent{
+		Event:         NotifyEventTempDisabled,
+		InterfaceType: strings.TrimSpace(interfaceType),
+		EndpointID:    endpoint.ID,
+		Endpoint:      endpoint.Name,
+		Vendor:        p.getVendorNameByID(endpoint.VendorID),
+		Reason:        reason,
+	})
+}
+
+// NotifyEndpointReenabled sends a recovery event when the user re-enables an endpoint that was
+// disabled in manual re-enable mode
+func (p *ProxyServer) NotifyEndpointReenabled(interfaceType InterfaceType, endpoint *Endpoint) {
+	if !p.isManualReenable() {
+		return
+	}
+	p.notifyEndpointRecovered(interfaceType, endpoint, "manually re-enabled")
+}
+
+// notifyEndpointRecovered sends a recovery event for an endpoint restored after being disabled
+func (p *ProxyServer) notifyEndpointRecovered(interfaceType InterfaceType, endpoint *Endpoint, reason string) {
+	notifier := p.getNotifier()
+	if notifier == nil || endpoint == nil {
+		return
+	}
+	notifier.Notify(NotifyEvent{
+		Event:         NotifyEventRecovered,
+		InterfaceType: string(interfaceType),
+		EndpointID:    endpoint.ID,
+		Endpoint:      endpoint.Name,
+		Vendor:        p.getVendorNameByID(endpoint.VendorID),
+		Reason:        reason,
+	})
+}
@@ -0,0 +1,147 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWebhookNotifierDeliverRetries(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		statuses []int
+		wantHits int
+		wantErr  bool
+	}{
+		{name: "success", statuses: []int{http.StatusNoContent}, wantHits: 1},
+		{name: "5xx retried then success", statuses: []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK}, wantHits: 3},
+		{name: "429 retried", statuses: []int{http.StatusTooManyRequests, http.StatusOK}, wantHits: 2},
+		{name: "4xx not retried", statuses: []int{http.StatusBadRequest, http.StatusOK}, wantHits: 1, wantErr: true},
+		{name: "gives up after max attempts", statuses: []int{500, 500, 500, 200}, wantHits: notifyWebhookAttempts, wantErr: true},
+	}
+	for _, tc := range cases {
+		var mu sync.Mutex
+		var hits []time.Time
+		var got NotifyEvent
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			_ = json.NewDecoder(r.Body).Decode(&got)
+			w.WriteHeader(tc.statuses[len(hits)])
+			hits = append(hits, time.Now())
+		}))
+
+		n := NewWebhookNotifier(server.URL)
+		n.backoff = 10 * time.Millisecond
+		err := n.deliver(NotifyEvent{Event: NotifyEventFallback, Endpoint: "a"})
+		server.Close()
+
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("%s: err=%v wantErr=%v", tc.name, err, tc.wantErr)
+		}
+		if len(hits) != tc.wantHits {
+			t.Fatalf("%s: hits=%d want %d", tc.name, len(hits), tc.wantHits)
+		}
+		if got.Event != NotifyEventFallback || got.Endpoint != "a" {
+			t.Fatalf("%s: payload=%+v", tc.name, got)
+		}
+		// backoff doubles between attempts
+		if len(hits) == 3 && hits[2].Sub(hits[1]) < hits[1].Sub(hits[0]) {
+			t.Fatalf("%s: backoff did not grow: %v then %v", tc.name, hits[1].Sub(hits[0]), hits[2].Sub(hits[1]))
+		}
+	}
+}
+
+func TestWebhookNotifierDropsWhenBusy(t *testing.T) {
+	t.Parallel()
+
+	n := NewWebhookNotifier("http://127.0.0.1:0")
+	for i := 0; i < cap(n.pending); i++ {
+		n.pending <- struct{}{}
+	}
+	if n.Notify(NotifyEvent{Event: NotifyEventTempDisabled, Endpoint: "a"}) {
+		t.Fatalf("event queued while every delivery slot is busy")
+	}
+	<-n.pending
+	var nilNotifier *WebhookNotifier
+	if nilNotifier.Notify(NotifyEvent{Event: NotifyEventTempDisabled}) {
+		t.Fatalf("nil notifier queued an event")
+	}
+}
+
+func TestWebhookNotifierThrottlesFallback(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	n := NewWebhookNotifier(server.URL)
+	n.now = func() time.Time { return now }
+	fallback := func(endpoint string) NotifyEvent {
+		return NotifyEvent{Event: NotifyEventFallback, InterfaceType: "claude", Endpoint: endpoint}
+	}
+
+	steps := []struct {
+		name    string
+		advance time.Duration
+		event   NotifyEvent
+		want    bool
+	}{
+		{name: "first fallback", event: fallback("a"), want: true},
+		{name: "repeat within interval", advance: time.Minute, event: fallback("a"), want: false},
+		{name: "other endpoint", event: fallback("b"), want: true},
+		{name: "temp disable not throttled", event: NotifyEvent{Event: NotifyEventTempDisabled, InterfaceType: "claude", Endpoint: "a"}, want: true},
+		{name: "after interval", advance: notifyFallbackInterval, event: fallback("a"), want: true},
+		{name: "throttled again", event: fallback("a"), want: false},
+		{name: "recovered", event: NotifyEvent{Event: NotifyEventRecovered, InterfaceType: "claude", Endpoint: "a"}, want: true},
+		{name: "fallback after recovery", event: fallback("a"), want: true},
+	}
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		if got := n.Notify(step.event); got != step.want {
+			t.Fatalf("%s: queued=%v want %v", step.name, got, step.want)
+		}
+	}
+}
+
+func TestNotifyEndpointReenabled(t *testing.T) {
+	t.Parallel()
+
+	events := make(chan NotifyEvent, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event NotifyEvent
+		_ = json.NewDecoder(r.Body).Decode(&event)
+		events <- event
+	}))
+	defer server.Close()
+
+	router := NewRouter()
+	p := NewProxyServer(0, router)
+	p.SetNotifyWebhookURL(server.URL)
+	endpoint := &Endpoint{ID: 7, Name: "a", InterfaceType: "claude", Enabled: true}
+
+	// Outside manual re-enable mode toggling an endpoint on is not a recovery
+	p.NotifyEndpointReenabled(InterfaceTypeClaude, endpoint)
+	router.SetManualReenable(true)
+	p.NotifyEndpointReenabled(InterfaceTypeClaude, endpoint)
+
+	select {
+	case event := <-events:
+		if event.Event != NotifyEventRecovered || event.EndpointID != 7 || event.Reason != "manually re-enabled" {
+			t.Fatalf("event=%+v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("recovered event not posted")
+	}
+	select {
+	case event := <-events:
+		t.Fatalf("unexpected extra event %+v", event)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
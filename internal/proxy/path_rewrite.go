@@ -0,0 +1,106 @@
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// PathRewriteRule represents a regex-based rewrite applied to incoming request paths
+type PathRewriteRule struct {
+	Match   string `json:"match"`
+	Replace string `json:"replace"`
+}
+
+// compiledPathRewrite is a PathRewriteRule with its regex compiled
+type compiledPathRewrite struct {
+	rule PathRewriteRule
+	re   *regexp.Regexp
+}
+
+// ParsePathRewriteRules converts the raw appConfig "pathRewrites" value into rules.
+// Accepts either a JSON array (as decoded from config.json) or a JSON string.
+// Rules whose match is not a valid regex are rejected.
+func ParsePathRewriteRules(raw interface{}) ([]PathRewriteRule, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	var data []byte
+	switch v := raw.(type) {
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return nil, nil
+		}
+		data = []byte(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		data = b
+	}
+
+	var rules []PathRewriteRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("invalid pathRewrites: %w", err)
+	}
+	if _, err := compilePathRewrites(rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func compilePathRewrites(rules []PathRewriteRule) ([]compiledPathRewrite, error) {
+	compiled := make([]compiledPathRewrite, 0, len(rules))
+	for i, rule := range rules {
+		match := strings.TrimSpace(rule.Match)
+		if match == "" {
+			continue
+		}
+		re, err := regexp.Compile(match)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pathRewrites[%d] match %q: %w", i, rule.Match, err)
+		}
+		compiled = append(compiled, compiledPathRewrite{rule: rule, re: re})
+	}
+	return compiled, nil
+}
+
+// SetPathRewrites replaces the path rewrite rules. Rules are evaluated in order and
+// the first one whose match fits the path wins.
+func (p *ProxyServer) SetPathRewrites(rules []PathRewriteRule) error {
+	compiled, err := compilePathRewrites(rules)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pathRewrites = compiled
+	return nil
+}
+
+// rewritePath applies the first matching rewrite rule to an incoming request path
+func (p *ProxyServer) rewritePath(requestID, path string) string {
+	p.mu.RLock()
+	rules := p.pathRewrites
+	hub := p.wsHub
+	p.mu.RUnlock()
+
+	for _, r := range rules {
+		if !r.re.MatchString(path) {
+			continue
+		}
+		rewritten := r.re.ReplaceAllString(path, r.rule.Replace)
+		if rewritten != path && hub != nil {
+			hub.BroadcastDebugLog(&DebugLogPayload{
+				RequestID: requestID,
+				Level:     1,
+				Message:   fmt.Sprintf("[PathRewrite] %s -> %s (match=%q)", path, rewritten, r.rule.Match),
+			})
+		}
+		return rewritten
+	}
+	return path
+}
@@ -0,0 +1,115 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParsePathRewriteRules(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		raw     interface{}
+		want    int
+		wantErr string
+	}{
+		{name: "unset", raw: nil},
+		{name: "blank string", raw: "  "},
+		{name: "json string", raw: `[{"match":"^/api(/.*)$","replace":"$1"}]`, want: 1},
+		{name: "decoded array", raw: []interface{}{
+			map[string]interface{}{"match": "^/a$", "replace": "/b"},
+			map[string]interface{}{"match": "^/c$", "replace": "/d"},
+		}, want: 2},
+		{name: "invalid json", raw: `{"match":`, wantErr: "invalid pathRewrites"},
+		{name: "invalid regex", raw: `[{"match":"^/ok$","replace":"/x"},{"match":"^/api(/.*$","replace":"$1"}]`, wantErr: "pathRewrites[1]"},
+	}
+	for _, tc := range cases {
+		rules, err := ParsePathRewriteRules(tc.raw)
+		if tc.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("%s: err=%v want it to contain %q", tc.name, err, tc.wantErr)
+			}
+			continue
+		}
+		if err != nil || len(rules) != tc.want {
+			t.Fatalf("%s: got %d rules err=%v want %d", tc.name, len(rules), err, tc.want)
+		}
+	}
+}
+
+func TestRewritePath(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name  string
+		rules []PathRewriteRule
+		path  string
+		want  string
+	}{
+		{name: "no rules", path: "/v1/messages", want: "/v1/messages"},
+		{name: "no match", rules: []PathRewriteRule{{Match: "^/api/", Replace: "/"}}, path: "/v1/messages", want: "/v1/messages"},
+		{name: "capture group", rules: []PathRewriteRule{{Match: "^/api/claude(/.*)$", Replace: "$1"}}, path: "/api/claude/v1/messages", want: "/v1/messages"},
+		{name: "named capture group", rules: []PathRewriteRule{{Match: "^/(?P<ver>v[0-9]+)/chat$", Replace: "/${ver}/chat/completions"}}, path: "/v1/chat", want: "/v1/chat/completions"},
+		{name: "non-matching rules are skipped", rules: []PathRewriteRule{
+			{Match: "^/gemini/", Replace: "/v1beta/"},
+			{Match: "^/codex(/.*)$", Replace: "$1"},
+		}, path: "/codex/v1/responses", want: "/v1/responses"},
+		{name: "first match wins", rules: []PathRewriteRule{
+			{Match: "^/api(/.*)$", Replace: "$1"},
+			{Match: "^/api/v1/messages$", Replace: "/v1/responses"},
+		}, path: "/api/v1/messages", want: "/v1/messages"},
+		{name: "later rules do not see the rewritten path", rules: []PathRewriteRule{
+			{Match: "^/api(/.*)$", Replace: "$1"},
+			{Match: "^/v1/messages$", Replace: "/v1/responses"},
+		}, path: "/api/v1/messages", want: "/v1/messages"},
+		{name: "blank match ignored", rules: []PathRewriteRule{
+			{Match: " ", Replace: "/never"},
+			{Match: "^/x$", Replace: "/y"},
+		}, path: "/x", want: "/y"},
+	}
+	for _, tc := range cases {
+		p := NewProxyServer(0, NewRouter())
+		if err := p.SetPathRewrites(tc.rules); err != nil {
+			t.Fatalf("%s: SetPathRewrites: %v", tc.name, err)
+		}
+		if got := p.rewritePath("req", tc.path); got != tc.want {
+			t.Fatalf("%s: got %q want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestHandleProxyDetectsRewrittenPath(t *testing.T) {
+	t.Parallel()
+
+	var gotPath string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"resp","object":"response","output":[]}`))
+	}))
+	defer upstream.Close()
+
+	router := NewRouter()
+	router.LoadEndpoints([]*Endpoint{{ID: 1, Name: "codex", APIURL: upstream.URL, InterfaceType: "codex", Enabled: true, Active: true}})
+	p := NewProxyServer(0, router)
+	if err := p.SetPathRewrites([]PathRewriteRule{{Match: "^/api/openai(/.*)$", Replace: "/v1$1"}}); err != nil {
+		t.Fatalf("SetPathRewrites: %v", err)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/api/openai/responses", strings.NewReader(`{"model":"m","input":"hi"}`))
+	w := httptest.NewRecorder()
+	p.handleProxy(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", w.Code, w.Body.String())
+	}
+	if gotPath != "/v1/responses" {
+		t.Fatalf("upstream path=%q want /v1/responses", gotPath)
+	}
+	logs := p.stats.GetRecentLogs(0)
+	if len(logs) == 0 || logs[len(logs)-1].InterfaceType != string(InterfaceTypeCodex) || logs[len(logs)-1].Path != "/v1/responses" {
+		t.Fatalf("request not detected as codex on the rewritten path: %+v", logs)
+	}
+}
@@ -10,6 +10,7 @@ type RequestLog struct {
 	VendorName    string    `json:"vendorName"`
 	VendorID      int64     `json:"vendorId,omitempty"`
 	EndpointName  string    `json:"endpointName"`
+	Remark        string    `json:"remark,omitempty"` // endpoint remark, e.g. "us-east, high quota"
 	Transformer   string    `json:"transformer,omitempty"`
 	Path          string    `json:"path"`
 	RunTime       int64     `json:"runTime"` // milliseconds
@@ -23,6 +24,9 @@ type RequestLog struct {
 	RequestHeaders map[string]string `json:"requestHeaders,omitempty"`
 	RequestStream  string            `json:"requestStream,omitempty"`
 	ResponseStream string            `json:"responseStream,omitempty"`
+	ModelFallback  string            `json:"modelFallback,omitempty"`
+	MaxTokensClamp string            `json:"maxTokensClamp,omitempty"`
+	AuthKey        string            `json:"authKey,omitempty"` // label of the labeled proxy key used
 }
 
 // TokenStats represents token usage statistics
@@ -0,0 +1,46 @@
+package proxy
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// routingRand is the random source used by randomized routing modes
+type routingRand struct {
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// SetRand replaces the random source used by randomized routing modes, so tests can
+// seed it and assert deterministic endpoint choices. nil restores a time-seeded source.
+func (r *DefaultRouter) SetRand(rnd *rand.Rand) {
+	r.rng.mu.Lock()
+	defer r.rng.mu.Unlock()
+	r.rng.rnd = rnd
+}
+
+// intn returns a random int in [0, n) from the router's random source
+func (g *routingRand) intn(n int) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.rnd == nil {
+		g.rnd = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return g.rnd.Intn(n)
+}
+
+// randomEndpointLocked picks a routable endpoint uniformly at random.
+// Must be called with r.mu held.
+func (r *DefaultRouter) randomEndpointLocked(interfaceType InterfaceType, now time.Time) *Endpoint {
+	var candidates []*Endpoint
+	for _, ep := range r.endpoints[interfaceType] {
+		if ep.isAutoRoutable(now) {
+			candidates = append(candidates, ep)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	return candidates[r.rng.intn(len(candidates))]
+}
@@ -0,0 +1,75 @@
+package proxy
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func newRandomRouter(t *testing.T, seed int64) *DefaultRouter {
+	t.Helper()
+	router := NewRouter()
+	router.LoadEndpoints([]*Endpoint{
+		{ID: 1, Name: "a", InterfaceType: "claude", Enabled: true, Active: true},
+		{ID: 2, Name: "b", InterfaceType: "claude", Enabled: true},
+		{ID: 3, Name: "c", InterfaceType: "claude", Enabled: true},
+		{ID: 4, Name: "off", InterfaceType: "claude", Enabled: false},
+	})
+	if err := router.SetRoutingMode(RoutingModeRandom); err != nil {
+		t.Fatalf("SetRoutingMode: %v", err)
+	}
+	router.SetRand(rand.New(rand.NewSource(seed)))
+	return router
+}
+
+func randomPicks(router *DefaultRouter, n int) []string {
+	picks := make([]string, n)
+	for i := range picks {
+		picks[i] = router.GetActiveEndpoint(InterfaceTypeClaude).Name
+	}
+	return picks
+}
+
+func TestRandomRoutingSeeded(t *testing.T) {
+	t.Parallel()
+
+	first := randomPicks(newRandomRouter(t, 42), 30)
+	second := randomPicks(newRandomRouter(t, 42), 30)
+	seen := make(map[string]int)
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("pick %d differs with the same seed: %s vs %s", i, first[i], second[i])
+		}
+		seen[first[i]]++
+	}
+	if seen["off"] != 0 {
+		t.Fatalf("disabled endpoint picked: %v", seen)
+	}
+	if len(seen) != 3 {
+		t.Fatalf("picks not spread across enabled endpoints: %v", seen)
+	}
+
+	other := randomPicks(newRandomRouter(t, 7), 30)
+	same := true
+	for i := range first {
+		same = same && first[i] == other[i]
+	}
+	if same {
+		t.Fatalf("different seeds produced the same picks")
+	}
+}
+
+func TestRandomRoutingKeepsActive(t *testing.T) {
+	t.Parallel()
+
+	router := newRandomRouter(t, 1)
+	randomPicks(router, 10)
+	if active := router.active[InterfaceTypeClaude]; active == nil || active.Name != "a" {
+		t.Fatalf("random pick changed the active endpoint to %v", active)
+	}
+
+	// nil restores a time-seeded source
+	router.SetRand(nil)
+	if ep := router.GetActiveEndpoint(InterfaceTypeClaude); ep == nil || ep.Name == "off" {
+		t.Fatalf("pick after SetRand(nil)=%v", ep)
+	}
+}
@@ -0,0 +1,159 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"clisimplehub/internal/executor"
+)
+
+// maxReplayResponseBytes caps the buffered body of a streamed replay response
+const maxReplayResponseBytes = 256 * 1024
+
+// ReplayResult is the raw upstream response of a replayed request
+type ReplayResult struct {
+	StatusCode     int
+	TargetURL      string
+	RequestHeaders map[string]string
+	ResponseBody   string
+	Error          error
+}
+
+// ReplayRequest re-sends a captured request log to an endpoint of the same interface type,
+// bypassing routing and fallback. endpointID <= 0 replays to the endpoint that served the
+// original request. Captured credentials are masked, so they are dropped and the endpoint's
+// current stored key is applied instead.
+func (p *ProxyServer) ReplayRequest(ctx context.Context, logID string, endpointID int64) (*ReplayResult, error) {
+	log := p.findRecentLog(logID)
+	if log == nil {
+		return nil, fmt.Errorf("log not found: %s", logID)
+	}
+	if log.RequestStream == "" {
+		return nil, errors.New("request body was not captured for this log")
+	}
+
+	interfaceType := InterfaceType(log.InterfaceType)
+	var target *Endpoint
+	for _, ep := range p.router.GetEndpointsByType(interfaceType) {
+		if (endpointID > 0 && ep.ID == endpointID) || (endpointID <= 0 && ep.Name == log.EndpointName && ep.VendorID == log.VendorID) {
+			target = ep
+			break
+		}
+	}
+	if target == nil {
+		if endpointID > 0 {
+			return nil, fmt.Errorf("endpoint %d is not a %s endpoint", endpointID, interfaceType)
+		}
+		return nil, fmt.Errorf("original endpoint %q no longer exists", log.EndpointName)
+	}
+
+	body := []byte(log.RequestStream)
+	isStreaming := isStreamRequested(body)
+	req := &executor.ForwardRequest{
+		Method:      log.Method,
+		Path:        log.Path,
+		RawQuery:    replayRawQuery(log.TargetURL),
+		Headers:     replayHeaders(log.RequestHeaders),
+		Body:        body,
+		IsStreaming: isStreaming,
+		UserID:      executor.ExtractUserID(body),
+		// keep the original interface type, which may have been forced by a routing rule
+		InterfaceType: string(interfaceType),
+	}
+	if req.Method == "" {
+		req.Method = http.MethodPost
+	}
+
+	ctx, cancel := p.withRequestDeadline(ctx, isStreaming)
+	defer cancel()
+	w := newReplayResponseWriter()
+	execResult := p.ensureExecutor().retry.ExecuteOnEndpoint(ctx, toExecutorEndpointConfig(target), req, w)
+
+	out := &ReplayResult{}
+	result := execResult.Result
+	if result == nil {
+		out.Error = errors.New("request failed")
+		return out, nil
+	}
+	if result.Spooled != nil {
+		defer result.Spooled.Close()
+	}
+	out.StatusCode = result.StatusCode
+	out.TargetURL = result.TargetURL
+	out.RequestHeaders = result.TargetHeaders
+	out.Error = result.Error
+	switch {
+	case result.Streamed:
+		out.ResponseBody = w.body.String()
+	case result.Spooled != nil:
+		out.ResponseBody = truncateResponseBodyForLog(result.Spooled.Head(), 50*1024)
+	case len(result.Body) > 0:
+		out.ResponseBody = string(result.Body)
+	}
+	return out, nil
+}
+
+// findRecentLog returns the in-memory request log with the given ID
+func (p *ProxyServer) findRecentLog(logID string) *RequestLog {
+	for _, log := range p.stats.GetRecentLogs(0) {
+		if log.ID == logID {
+			return log
+		}
+	}
+	return nil
+}
+
+// replayHeaders rebuilds client headers from a log, dropping credentials (masked in logs)
+func replayHeaders(captured map[string]string) http.Header {
+	headers := make(http.Header, len(captured))
+	for key, value := range captured {
+		switch strings.ToLower(key) {
+		case "authorization", "proxy-authorization", "x-api-key", "cookie", "content-length":
+			continue
+		}
+		if executor.IsSensitiveHeader(key) {
+			continue
+		}
+		headers.Set(key, value)
+	}
+	return headers
+}
+
+// replayRawQuery keeps the original query string except the gemini "key" credential
+func replayRawQuery(targetURL string) string {
+	parsed, err := url.Parse(targetURL)
+	if err != nil || parsed.RawQuery == "" {
+		return ""
+	}
+	query := parsed.Query()
+	query.Del("key")
+	return query.Encode()
+}
+
+// replayResponseWriter buffers a streamed replay response up to maxReplayResponseBytes
+type replayResponseWriter struct {
+	header http.Header
+	body   bytes.Buffer
+}
+
+func newReplayResponseWriter() *replayResponseWriter {
+	return &replayResponseWriter{header: make(http.Header)}
+}
+
+func (w *replayResponseWriter) Header() http.Header { return w.header }
+
+func (w *replayResponseWriter) Write(b []byte) (int, error) {
+	if room := maxReplayResponseBytes - w.body.Len(); room > 0 {
+		w.body.Write(b[:min(len(b), room)])
+	}
+	return len(b), nil
+}
+
+func (w *replayResponseWriter) WriteHeader(int) {}
+
+func (w *replayResponseWriter) Flush() {}
@@ -0,0 +1,30 @@
+package proxy
+
+import (
+	"context"
+	"time"
+)
+
+// SetRequestDeadlines sets server-wide deadlines applied to every proxied request,
+// covering all fallback attempts. Zero disables the respective deadline.
+func (p *ProxyServer) SetRequestDeadlines(normal, streaming time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requestDeadline = normal
+	p.streamRequestDeadline = streaming
+}
+
+// withRequestDeadline wraps ctx with the deadline configured for the request kind
+func (p *ProxyServer) withRequestDeadline(ctx context.Context, isStreaming bool) (context.Context, context.CancelFunc) {
+	p.mu.RLock()
+	deadline := p.requestDeadline
+	if isStreaming {
+		deadline = p.streamRequestDeadline
+	}
+	p.mu.RUnlock()
+
+	if deadline <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, deadline)
+}
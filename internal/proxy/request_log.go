@@ -15,6 +15,23 @@ type RequestDetail struct {
 	RequestStream  string
 	ResponseStream string
 	UpstreamAuth   string
+	ModelFallback  string
+	MaxTokensClamp string
+	AuthKey        string // label of the labeled proxy key used, empty for the global key
+}
+
+// SetCaptureBodies controls whether request logs keep the request and response streams
+func (p *ProxyServer) SetCaptureBodies(enabled bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.skipBodyCapture = !enabled
+}
+
+// IsCaptureBodiesEnabled returns whether request logs keep the request and response streams
+func (p *ProxyServer) IsCaptureBodiesEnabled() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return !p.skipBodyCapture
 }
 
 func (p *ProxyServer) recordRequestWithDetail(id string, interfaceType InterfaceType, endpoint *executor.EndpointConfig, path string, startTime time.Time, status string, runTime int64, detail *RequestDetail) {
@@ -29,6 +46,7 @@ func (p *ProxyServer) recordRequestWithDetail(id string, interfaceType Interface
 
 	if endpoint != nil {
 		log.EndpointName = endpoint.Name
+		log.Remark = endpoint.Remark
 		log.VendorID = endpoint.VendorID
 		log.Transformer = endpoint.Transformer
 	}
@@ -38,9 +56,14 @@ func (p *ProxyServer) recordRequestWithDetail(id string, interfaceType Interface
 		log.StatusCode = detail.StatusCode
 		log.TargetURL = detail.TargetURL
 		log.RequestHeaders = detail.RequestHeaders
-		log.RequestStream = detail.RequestStream
-		log.ResponseStream = detail.ResponseStream
+		if p.IsCaptureBodiesEnabled() {
+			log.RequestStream = detail.RequestStream
+			log.ResponseStream = detail.ResponseStream
+		}
 		log.UpstreamAuth = detail.UpstreamAuth
+		log.ModelFallback = detail.ModelFallback
+		log.MaxTokensClamp = detail.MaxTokensClamp
+		log.AuthKey = detail.AuthKey
 	}
 
 	p.stats.RecordRequest(log)
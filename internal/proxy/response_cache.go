@@ -0,0 +1,269 @@
+package proxy
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"clisimplehub/internal/executor"
+)
+
+// ResponseCacheHeader lets clients opt in to response caching for a single request
+const ResponseCacheHeader = "X-Proxy-Cache"
+
+// Default response cache limits
+const (
+	DefaultResponseCacheTTL      = 5 * time.Minute
+	DefaultResponseCacheMaxBytes = 32 * 1024 * 1024
+)
+
+// cachedResponse is a stored upstream response for an identical non-streaming request
+type cachedResponse struct {
+	key        string
+	statusCode int
+	headers    http.Header
+	body       []byte
+	targetURL  string
+	expiresAt  time.Time
+}
+
+func (c *cachedResponse) size() int {
+	n := len(c.body) + len(c.key) + len(c.targetURL)
+	for k, vs := range c.headers {
+		n += len(k)
+		for _, v := range vs {
+			n += len(v)
+		}
+	}
+	return n
+}
+
+// ResponseCache is an LRU cache of upstream responses bounded by total body size
+type ResponseCache struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	maxBytes int
+	curBytes int
+	order    *list.List // front = most recently used
+	entries  map[string]*list.Element
+}
+
+// NewResponseCache creates a response cache with the given TTL and memory bound
+func NewResponseCache(ttl time.Duration, maxBytes int) *ResponseCache {
+	ttl, maxBytes = normalizeResponseCacheLimits(ttl, maxBytes)
+	return &ResponseCache{
+		ttl:      ttl,
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// normalizeResponseCacheLimits replaces non-positive limits with the defaults
+func normalizeResponseCacheLimits(ttl time.Duration, maxBytes int) (time.Duration, int) {
+	if ttl <= 0 {
+		ttl = DefaultResponseCacheTTL
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultResponseCacheMaxBytes
+	}
+	return ttl, maxBytes
+}
+
+// Get returns a cached response if present and not expired
+func (c *ResponseCache) Get(key string) (*cachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*cachedResponse)
+	if time.Now().After(entry.expiresAt) {
+		c.removeLocked(elem)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return entry, true
+}
+
+// Put stores a response, evicting least recently used entries to stay within maxBytes
+func (c *ResponseCache) Put(key string, statusCode int, headers http.Header, body []byte, targetURL string) {
+	entry := &cachedResponse{
+		key:        key,
+		statusCode: statusCode,
+		headers:    headers.Clone(),
+		body:       append([]byte(nil), body...),
+		targetURL:  targetURL,
+		expiresAt:  time.Now().Add(c.ttl),
+	}
+	size := entry.size()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if size > c.maxBytes {
+		return
+	}
+	if elem, ok := c.entries[key]; ok {
+		c.removeLocked(elem)
+	}
+	for c.curBytes+size > c.maxBytes && c.order.Len() > 0 {
+		c.removeLocked(c.order.Back())
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	c.curBytes += size
+}
+
+// ResponseCacheStats is a snapshot of the response cache usage
+type ResponseCacheStats struct {
+	Entries  int `json:"entries"`
+	Bytes    int `json:"bytes"`
+	MaxBytes int `json:"maxBytes"`
+}
+
+// Stats returns the number of cached responses and their total size
+func (c *ResponseCache) Stats() ResponseCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return ResponseCacheStats{Entries: c.order.Len(), Bytes: c.curBytes, MaxBytes: c.maxBytes}
+}
+
+func (c *ResponseCache) removeLocked(elem *list.Element) {
+	entry := elem.Value.(*cachedResponse)
+	c.order.Remove(elem)
+	delete(c.entries, entry.key)
+	c.curBytes -= entry.size()
+}
+
+// SetResponseCache enables response caching; passing nil disables it
+func (p *ProxyServer) SetResponseCache(cache *ResponseCache) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.responseCache = cache
+}
+
+// SetResponseCacheLimits applies the response cache settings. The current cache and its
+// entries are kept when the TTL and memory bound are unchanged; otherwise a new cache
+// replaces it, and a disabled cache is dropped.
+func (p *ProxyServer) SetResponseCacheLimits(enabled bool, ttl time.Duration, maxBytes int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !enabled {
+		p.responseCache = nil
+		return
+	}
+	ttl, maxBytes = normalizeResponseCacheLimits(ttl, maxBytes)
+	if c := p.responseCache; c != nil && c.ttl == ttl && c.maxBytes == maxBytes {
+		return
+	}
+	p.responseCache = NewResponseCache(ttl, maxBytes)
+}
+
+func (p *ProxyServer) getResponseCache() *ResponseCache {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.responseCache
+}
+
+// ResponseCacheStats returns the response cache usage, or nil when caching is disabled
+func (p *ProxyServer) ResponseCacheStats() *ResponseCacheStats {
+	if cache := p.getResponseCache(); cache != nil {
+		stats := cache.Stats()
+		return &stats
+	}
+	return nil
+}
+
+// responseCacheVaryHeaders are forwarded request headers that change the upstream response
+// for an otherwise identical body, so they are part of the cache key
+var responseCacheVaryHeaders = []string{"anthropic-beta", "anthropic-version"}
+
+// responseCacheKey hashes the endpoint, request and the headers in responseCacheVaryHeaders.
+// The executor rewrites the client body with the endpoint's model mapping, body overrides and
+// the global default models, so those settings are hashed too: editing them must not serve
+// responses produced by the old upstream body.
+func responseCacheKey(endpoint *executor.EndpointConfig, r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(strconv.FormatInt(endpoint.ID, 10)))
+	h.Write([]byte{0})
+	h.Write(responseCacheEndpointConfig(endpoint))
+	h.Write([]byte{0})
+	h.Write([]byte(r.URL.Path))
+	h.Write([]byte{0})
+	h.Write([]byte(r.URL.RawQuery))
+	h.Write([]byte{0})
+	for _, name := range responseCacheVaryHeaders {
+		h.Write([]byte(strings.Join(r.Header.Values(name), ",")))
+		h.Write([]byte{0})
+	}
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseCacheEndpointConfig encodes the settings that shape the upstream request for an
+// endpoint. json.Marshal sorts map keys, so the encoding is stable.
+func responseCacheEndpointConfig(endpoint *executor.EndpointConfig) []byte {
+	data, _ := json.Marshal(struct {
+		APIURL           string
+		Transformer      string
+		Model            string
+		DefaultModel     string
+		FuzzyModelMatch  bool
+		Models           []executor.ModelMapping
+		DropParams       []string
+		BodyOverrides    map[string]any
+		BodyForce        bool
+		IncludeReasoning bool
+		ReasoningSummary string
+		MaxTokensCap     int
+		AnthropicVersion string
+		AnthropicBeta    string
+	}{
+		APIURL:           endpoint.APIURL,
+		Transformer:      endpoint.Transformer,
+		Model:            endpoint.Model,
+		DefaultModel:     executor.DefaultModel(endpoint.InterfaceType),
+		FuzzyModelMatch:  executor.FuzzyModelMatch(),
+		Models:           endpoint.Models,
+		DropParams:       endpoint.DropParams,
+		BodyOverrides:    endpoint.BodyOverrides,
+		BodyForce:        endpoint.BodyForce,
+		IncludeReasoning: endpoint.IncludeReasoning,
+		ReasoningSummary: endpoint.ReasoningSummary,
+		MaxTokensCap:     endpoint.MaxTokensCap,
+		AnthropicVersion: endpoint.AnthropicVersion,
+		AnthropicBeta:    endpoint.AnthropicBeta,
+	})
+	return data
+}
+
+// isCacheableRequest reports whether the client opted in to caching, either
+// explicitly via the X-Proxy-Cache header or implicitly with temperature 0.
+func isCacheableRequest(r *http.Request, body []byte) bool {
+	switch strings.ToLower(strings.TrimSpace(r.Header.Get(ResponseCacheHeader))) {
+	case "1", "true", "on", "yes":
+		return true
+	case "0", "false", "off", "no":
+		return false
+	}
+	return isZeroTemperature(body)
+}
+
+// isZeroTemperature reports whether the request body sets temperature to 0
+func isZeroTemperature(body []byte) bool {
+	var req struct {
+		Temperature *float64 `json:"temperature"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return false
+	}
+	return req.Temperature != nil && *req.Temperature == 0
+}
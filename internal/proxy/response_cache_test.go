@@ -0,0 +1,208 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"clisimplehub/internal/executor"
+)
+
+func TestResponseCacheGetPut(t *testing.T) {
+	t.Parallel()
+
+	c := NewResponseCache(time.Minute, 0)
+	if _, ok := c.Get("k"); ok {
+		t.Fatalf("empty cache returned a hit")
+	}
+	c.Put("k", http.StatusOK, http.Header{"Content-Type": {"application/json"}}, []byte(`{"a":1}`), "http://up/v1/messages")
+	got, ok := c.Get("k")
+	if !ok || got.statusCode != http.StatusOK || string(got.body) != `{"a":1}` || got.headers.Get("Content-Type") != "application/json" {
+		t.Fatalf("hit=%v entry=%+v", ok, got)
+	}
+	if _, ok := c.Get("other"); ok {
+		t.Fatalf("different key returned a hit")
+	}
+}
+
+func TestResponseCacheTTL(t *testing.T) {
+	t.Parallel()
+
+	c := NewResponseCache(20*time.Millisecond, 0)
+	c.Put("k", http.StatusOK, nil, []byte("body"), "")
+	if _, ok := c.Get("k"); !ok {
+		t.Fatalf("fresh entry missing")
+	}
+	time.Sleep(40 * time.Millisecond)
+	if _, ok := c.Get("k"); ok {
+		t.Fatalf("expired entry returned")
+	}
+	if c.curBytes != 0 || c.order.Len() != 0 {
+		t.Fatalf("expired entry not removed: bytes=%d entries=%d", c.curBytes, c.order.Len())
+	}
+}
+
+func TestResponseCacheEviction(t *testing.T) {
+	t.Parallel()
+
+	// each entry is a one-byte key plus a 10-byte body
+	c := NewResponseCache(time.Minute, 25)
+	c.Put("a", http.StatusOK, nil, []byte("0123456789"), "")
+	c.Put("b", http.StatusOK, nil, []byte("0123456789"), "")
+	c.Get("a") // a is now the most recently used
+	c.Put("c", http.StatusOK, nil, []byte("0123456789"), "")
+
+	if _, ok := c.Get("b"); ok {
+		t.Fatalf("least recently used entry not evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := c.Get(key); !ok {
+			t.Fatalf("entry %s evicted", key)
+		}
+	}
+	c.Put("big", http.StatusOK, nil, make([]byte, 100), "")
+	if _, ok := c.Get("big"); ok {
+		t.Fatalf("entry larger than the cache was stored")
+	}
+}
+
+func TestSetResponseCacheLimitsKeepsEntries(t *testing.T) {
+	t.Parallel()
+
+	p := NewProxyServer(0, NewRouter())
+	p.SetResponseCacheLimits(true, time.Minute, 0)
+	c := p.getResponseCache()
+	c.Put("k", http.StatusOK, nil, []byte("body"), "")
+
+	// 0 means the default bound, so this is the same setting
+	p.SetResponseCacheLimits(true, time.Minute, DefaultResponseCacheMaxBytes)
+	if p.getResponseCache() != c {
+		t.Fatalf("unchanged settings replaced the cache")
+	}
+	if _, ok := p.getResponseCache().Get("k"); !ok {
+		t.Fatalf("unchanged settings dropped a cached entry")
+	}
+	p.SetResponseCacheLimits(true, 2*time.Minute, 0)
+	if got := p.getResponseCache(); got == c || got == nil {
+		t.Fatalf("changed TTL should replace the cache")
+	}
+	p.SetResponseCacheLimits(false, time.Minute, 0)
+	if p.getResponseCache() != nil {
+		t.Fatalf("disabled cache still set")
+	}
+}
+
+func TestResponseCacheKey(t *testing.T) {
+	t.Parallel()
+
+	base := func() *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/v1/messages?beta=true", nil)
+		r.Header.Set("anthropic-version", "2023-06-01")
+		return r
+	}
+	baseEndpoint := func() *executor.EndpointConfig {
+		return &executor.EndpointConfig{ID: 1, InterfaceType: "claude", Models: []executor.ModelMapping{{Name: "up", Alias: "m"}}}
+	}
+	body := []byte(`{"model":"m","temperature":0}`)
+	want := responseCacheKey(baseEndpoint(), base(), body)
+
+	cases := []struct {
+		name     string
+		endpoint func(e *executor.EndpointConfig)
+		mutate   func(r *http.Request)
+		body     string
+		same     bool
+	}{
+		{name: "identical", same: true},
+		{name: "unrelated header ignored", mutate: func(r *http.Request) { r.Header.Set("User-Agent", "x") }, same: true},
+		{name: "unrelated endpoint field ignored", endpoint: func(e *executor.EndpointConfig) { e.Remark = "note" }, same: true},
+		{name: "endpoint", endpoint: func(e *executor.EndpointConfig) { e.ID = 2 }},
+		{name: "model mapping", endpoint: func(e *executor.EndpointConfig) { e.Models = []executor.ModelMapping{{Name: "other", Alias: "m"}} }},
+		{name: "body overrides", endpoint: func(e *executor.EndpointConfig) { e.BodyOverrides = map[string]any{"service_tier": "flex"} }},
+		{name: "drop params", endpoint: func(e *executor.EndpointConfig) { e.DropParams = []string{"top_k"} }},
+		{name: "max tokens cap", endpoint: func(e *executor.EndpointConfig) { e.MaxTokensCap = 1024 }},
+		{name: "body", body: `{"model":"n","temperature":0}`},
+		{name: "query", mutate: func(r *http.Request) { r.URL.RawQuery = "" }},
+		{name: "anthropic-beta", mutate: func(r *http.Request) { r.Header.Set("anthropic-beta", "prompt-caching-2024-07-31") }},
+		{name: "anthropic-version", mutate: func(r *http.Request) { r.Header.Set("anthropic-version", "2024-01-01") }},
+	}
+	for _, tc := range cases {
+		r := base()
+		if tc.mutate != nil {
+			tc.mutate(r)
+		}
+		e := baseEndpoint()
+		if tc.endpoint != nil {
+			tc.endpoint(e)
+		}
+		b := body
+		if tc.body != "" {
+			b = []byte(tc.body)
+		}
+		if got := responseCacheKey(e, r, b); (got == want) != tc.same {
+			t.Fatalf("%s: same key=%v want %v", tc.name, got == want, tc.same)
+		}
+	}
+}
+
+func TestHandleProxyResponseCache(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"msg","type":"message","content":[]}`))
+	}))
+	defer upstream.Close()
+
+	router := NewRouter()
+	router.LoadEndpoints([]*Endpoint{{ID: 1, Name: "a", APIURL: upstream.URL, InterfaceType: "claude", Enabled: true, Active: true}})
+	p := NewProxyServer(0, router)
+	p.SetResponseCache(NewResponseCache(time.Minute, 0))
+	queue := NewFairQueue(1, 1)
+	p.SetFairQueue(queue)
+
+	send := func(beta string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"m","temperature":0,"messages":[]}`))
+		if beta != "" {
+			r.Header.Set("anthropic-beta", beta)
+		}
+		w := httptest.NewRecorder()
+		p.handleProxy(w, r)
+		return w
+	}
+
+	if w := send(""); w.Code != http.StatusOK || w.Header().Get("X-Cache") != "MISS" {
+		t.Fatalf("first request: status=%d X-Cache=%q", w.Code, w.Header().Get("X-Cache"))
+	}
+
+	// Hold the only slot: a cache hit must be served without queueing
+	release, err := queue.Acquire(context.Background(), InterfaceTypeClaude)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	done := make(chan *httptest.ResponseRecorder, 1)
+	go func() { done <- send("") }()
+	select {
+	case w := <-done:
+		if w.Code != http.StatusOK || w.Header().Get("X-Cache") != "HIT" || !strings.Contains(w.Body.String(), `"msg"`) {
+			t.Fatalf("cached request: status=%d X-Cache=%q body=%s", w.Code, w.Header().Get("X-Cache"), w.Body.String())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("cache hit waited for a queue slot")
+	}
+	release()
+
+	// A different anthropic-beta header is a different upstream request
+	if w := send("prompt-caching-2024-07-31"); w.Code != http.StatusOK || w.Header().Get("X-Cache") != "MISS" {
+		t.Fatalf("beta request: status=%d X-Cache=%q", w.Code, w.Header().Get("X-Cache"))
+	}
+	if hits.Load() != 2 {
+		t.Fatalf("upstream hits=%d want 2", hits.Load())
+	}
+}
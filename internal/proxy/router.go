@@ -12,7 +12,8 @@ import (
 
 // Router handles request routing based on URL path
 type Router interface {
-	// DetectInterfaceType determines the interface type from the request path
+	// DetectInterfaceType determines the interface type from the request path.
+	// It returns "" for unrecognized paths when they are configured to be rejected.
 	DetectInterfaceType(path string) InterfaceType
 
 	// GetActiveEndpoint returns the currently active endpoint for the given interface type
@@ -51,11 +52,26 @@ type DefaultRouter struct {
 	mu             sync.RWMutex
 	tempDisableTTL time.Duration
 	tempDisabled   map[InterfaceType]map[string]*tempDisableEntry
+	// manualReenable keeps disabled endpoints disabled until the user re-enables them
+	manualReenable bool
+	// detectionRules are config-defined path rules consulted before the built-in detection
+	detectionRules []compiledDetectionRule
+	// unknownPathBehavior is the interface type for unrecognized paths, or UnknownPathReject ("" means claude)
+	unknownPathBehavior string
+	// routingMode is RoutingModePriority ("" also), RoutingModeLeastTokens or RoutingModeRandom
+	routingMode string
+	tokenUsage  tokenUsageView
+	rng         routingRand
+	// onActivate is called in its own goroutine when a different endpoint becomes active
+	onActivate func(InterfaceType, *Endpoint)
+	// onRestore is called in its own goroutine when a temporarily disabled endpoint is restored
+	onRestore func(InterfaceType, *Endpoint)
 }
 
 type tempDisableEntry struct {
 	until           time.Time
 	previousEnabled bool
+	manual          bool // never restored automatically; until is the time it was disabled
 }
 
 const defaultTempDisableTTL = 5 * time.Minute
@@ -91,6 +107,50 @@ func (r *DefaultRouter) SetTempDisableTTL(ttl time.Duration) {
 	r.tempDisableTTL = ttl
 }
 
+// SetActivationHook registers a callback run in its own goroutine whenever a different
+// endpoint becomes active: manual switches, failover and recovery of the preferred endpoint.
+// Loading endpoints does not trigger it.
+func (r *DefaultRouter) SetActivationHook(hook func(InterfaceType, *Endpoint)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onActivate = hook
+}
+
+// setActiveLocked makes ep the active endpoint and runs the activation hook when it changed.
+// Must be called with r.mu held.
+func (r *DefaultRouter) setActiveLocked(interfaceType InterfaceType, ep *Endpoint) {
+	previous := r.active[interfaceType]
+	r.active[interfaceType] = ep
+	if ep == nil || r.onActivate == nil || (previous != nil && endpointKey(previous) == endpointKey(ep)) {
+		return
+	}
+	go r.onActivate(interfaceType, ep)
+}
+
+// SetRestoreHook registers a callback run in its own goroutine whenever an endpoint is
+// restored after its temporary disable expired. Expiry is checked lazily during routing,
+// so the callback fires on the first routing decision after the TTL.
+func (r *DefaultRouter) SetRestoreHook(hook func(InterfaceType, *Endpoint)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onRestore = hook
+}
+
+// SetManualReenable makes DisableEndpoint keep endpoints disabled instead of restoring
+// them after the TTL. Endpoints already disabled this way stay disabled when it is turned off.
+func (r *DefaultRouter) SetManualReenable(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.manualReenable = enabled
+}
+
+// ManualReenable reports whether disabled endpoints wait for the user to re-enable them
+func (r *DefaultRouter) ManualReenable() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.manualReenable
+}
+
 func (r *DefaultRouter) restoreExpiredLocked(interfaceType InterfaceType) {
 	disabled := r.tempDisabled[interfaceType]
 	if len(disabled) == 0 {
@@ -101,7 +161,7 @@ func (r *DefaultRouter) restoreExpiredLocked(interfaceType InterfaceType) {
 	eps := r.endpoints[interfaceType]
 
 	for key, entry := range disabled {
-		if entry == nil || now.Before(entry.until) {
+		if entry == nil || entry.manual || now.Before(entry.until) {
 			continue
 		}
 
@@ -113,6 +173,9 @@ func (r *DefaultRouter) restoreExpiredLocked(interfaceType InterfaceType) {
 				continue
 			}
 			ep.Enabled = entry.previousEnabled
+			if ep.Enabled && r.onRestore != nil {
+				go r.onRestore(interfaceType, ep)
+			}
 			break
 		}
 		delete(disabled, key)
@@ -129,33 +192,50 @@ func (r *DefaultRouter) DetectInterfaceType(path string) InterfaceType {
 	// Normalize path to lowercase for comparison
 	lowerPath := strings.ToLower(path)
 
+	if interfaceType, ok := r.detectByRules(path, lowerPath); ok {
+		return interfaceType
+	}
+
+	if interfaceType, ok := detectBuiltinPath(lowerPath); ok {
+		return interfaceType
+	}
+	return r.unknownPathType()
+}
+
+// detectBuiltinPath recognizes the well-known protocol paths; lowerPath must be lowercase
+func detectBuiltinPath(lowerPath string) (InterfaceType, bool) {
 	// Requirement 3.1: /v1/messages -> claude
 	if strings.HasPrefix(lowerPath, "/v1/messages") {
-		return InterfaceTypeClaude
+		return InterfaceTypeClaude, true
 	}
 
 	// 兼容 OpenAI Chat Completions 路径：/v1/chat/completions 或以 /chat/completions 结尾的都走 chat
 	if strings.HasPrefix(lowerPath, "/v1/chat/completions") || strings.HasSuffix(lowerPath, "/chat/completions") {
-		return InterfaceTypeChat
+		return InterfaceTypeChat, true
 	}
 
 	// OpenAI Responses 路径：/v1/responses 或以 /responses 结尾的都走 codex
 	if strings.HasPrefix(lowerPath, "/v1/responses") || strings.HasSuffix(lowerPath, "/responses") {
-		return InterfaceTypeCodex
+		return InterfaceTypeCodex, true
 	}
 
 	// Requirement 3.3: path containing /gemini -> gemini
 	if strings.Contains(lowerPath, "/gemini") {
-		return InterfaceTypeGemini
+		return InterfaceTypeGemini, true
 	}
 
 	// Requirement 3.4: /chat -> chat
 	if strings.HasPrefix(lowerPath, "/chat") {
-		return InterfaceTypeChat
+		return InterfaceTypeChat, true
+	}
+
+	// Model discovery is answered from all endpoints; the claude type lets
+	// handleModelsList tell Anthropic and OpenAI clients apart by their headers
+	if strings.HasSuffix(strings.TrimSuffix(lowerPath, "/"), "/v1/models") {
+		return InterfaceTypeClaude, true
 	}
 
-	// Default to claude if no match (could also return empty string)
-	return InterfaceTypeClaude
+	return "", false
 }
 
 // LoadEndpoints loads endpoints into the router, organizing them by interface type
@@ -240,7 +320,7 @@ func (r *DefaultRouter) LoadEndpoints(endpoints []*Endpoint) {
 		// If still no active endpoint found, use the first enabled endpoint
 		if r.active[interfaceType] == nil {
 			for _, ep := range eps {
-				if ep.Enabled {
+				if ep.Enabled && !ep.Sandbox {
 					r.active[interfaceType] = ep
 					break
 				}
@@ -253,28 +333,52 @@ func (r *DefaultRouter) LoadEndpoints(endpoints []*Endpoint) {
 	}
 }
 
-// GetActiveEndpoint returns the currently active endpoint for the given interface type
+// GetActiveEndpoint returns the currently active endpoint for the given interface type.
+// Schedule windows are re-evaluated on every call. In least-tokens mode the endpoint
+// with the fewest tokens used today is returned instead, when stats are available;
+// in random mode a routable endpoint is picked at random. Neither changes the active endpoint.
 // Requirements: 3.5
 func (r *DefaultRouter) GetActiveEndpoint(interfaceType InterfaceType) *Endpoint {
+	mode := r.RoutingMode()
+	var usage map[int64]int64
+	if mode == RoutingModeLeastTokens {
+		usage = r.todayTokenUsage()
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.restoreExpiredLocked(interfaceType)
+	now := time.Now()
+
+	// Without stats, least-tokens mode falls back to priority routing below. Like random
+	// picks, the choice follows usage per request and leaves the active endpoint alone.
+	if usage != nil {
+		if ep := r.leastTokensEndpointLocked(interfaceType, usage, now); ep != nil {
+			return ep
+		}
+	}
+	// Random picks change per request, so they leave the active endpoint (and its activation hook) alone
+	if mode == RoutingModeRandom {
+		if ep := r.randomEndpointLocked(interfaceType, now); ep != nil {
+			return ep
+		}
+	}
 
 	// Prefer the configured/manual endpoint when available (e.g., after recovery from a temporary disable).
 	if preferredKey := strings.TrimSpace(r.preferred[interfaceType]); preferredKey != "" {
-		if active := r.active[interfaceType]; active != nil && active.Enabled && endpointKey(active) == preferredKey {
+		if active := r.active[interfaceType]; active.isRoutable(now) && endpointKey(active) == preferredKey {
 			return active
 		}
 		for _, ep := range r.endpoints[interfaceType] {
-			if ep != nil && ep.Enabled && endpointKey(ep) == preferredKey {
-				r.active[interfaceType] = ep
+			if ep.isRoutable(now) && endpointKey(ep) == preferredKey {
+				r.setActiveLocked(interfaceType, ep)
 				return ep
 			}
 		}
 	}
 
 	// Ensure active endpoint is enabled; if not, pick the first enabled one.
-	if active := r.active[interfaceType]; active != nil && active.Enabled {
+	if active := r.active[interfaceType]; active.isRoutable(now) {
 		if strings.TrimSpace(r.preferred[interfaceType]) == "" {
 			r.preferred[interfaceType] = endpointKey(active)
 		}
@@ -283,8 +387,8 @@ func (r *DefaultRouter) GetActiveEndpoint(interfaceType InterfaceType) *Endpoint
 
 	eps := r.endpoints[interfaceType]
 	for _, ep := range eps {
-		if ep != nil && ep.Enabled {
-			r.active[interfaceType] = ep
+		if ep.isAutoRoutable(now) {
+			r.setActiveLocked(interfaceType, ep)
 			if strings.TrimSpace(r.preferred[interfaceType]) == "" {
 				r.preferred[interfaceType] = endpointKey(ep)
 			}
@@ -302,6 +406,7 @@ func (r *DefaultRouter) GetNextEndpoint(interfaceType InterfaceType, current *En
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.restoreExpiredLocked(interfaceType)
+	now := time.Now()
 
 	eps := r.endpoints[interfaceType]
 	if len(eps) == 0 {
@@ -311,7 +416,7 @@ func (r *DefaultRouter) GetNextEndpoint(interfaceType InterfaceType, current *En
 	// If current is nil, return the first enabled endpoint
 	if current == nil {
 		for _, ep := range eps {
-			if ep.Enabled {
+			if ep.isAutoRoutable(now) {
 				return ep
 			}
 		}
@@ -337,7 +442,7 @@ func (r *DefaultRouter) GetNextEndpoint(interfaceType InterfaceType, current *En
 	// If current not found, return the first enabled endpoint
 	if currentIdx == -1 {
 		for _, ep := range eps {
-			if ep.Enabled {
+			if ep.isAutoRoutable(now) {
 				return ep
 			}
 		}
@@ -347,7 +452,7 @@ func (r *DefaultRouter) GetNextEndpoint(interfaceType InterfaceType, current *En
 	// Find the next enabled endpoint after current (wrapping around)
 	for i := 1; i <= len(eps); i++ {
 		nextIdx := (currentIdx + i) % len(eps)
-		if !eps[nextIdx].Enabled {
+		if !eps[nextIdx].isAutoRoutable(now) {
 			continue
 		}
 		if current.ID != 0 {
@@ -384,14 +489,14 @@ func (r *DefaultRouter) SetActiveEndpoint(interfaceType InterfaceType, endpoint
 		// 优先用 ID 精确匹配，避免同名端点被错误选中
 		if endpoint.ID != 0 {
 			if ep.ID == endpoint.ID {
-				r.active[interfaceType] = ep
+				r.setActiveLocked(interfaceType, ep)
 				r.preferred[interfaceType] = endpointKey(ep)
 				return nil
 			}
 			continue
 		}
 		if ep.Name == endpoint.Name {
-			r.active[interfaceType] = ep
+			r.setActiveLocked(interfaceType, ep)
 			r.preferred[interfaceType] = endpointKey(ep)
 			return nil
 		}
@@ -401,6 +506,7 @@ func (r *DefaultRouter) SetActiveEndpoint(interfaceType InterfaceType, endpoint
 }
 
 // DisableEndpoint disables an endpoint in memory without persisting to config.json.
+// In manual re-enable mode the endpoint is not restored and the returned time is when it was disabled.
 func (r *DefaultRouter) DisableEndpoint(interfaceType InterfaceType, endpoint *Endpoint) time.Time {
 	if endpoint == nil {
 		return time.Time{}
@@ -446,7 +552,14 @@ func (r *DefaultRouter) DisableEndpoint(interfaceType InterfaceType, endpoint *E
 		entry = &tempDisableEntry{previousEnabled: eps[targetIdx].Enabled}
 		r.tempDisabled[interfaceType][key] = entry
 	}
-	entry.until = time.Now().Add(r.tempDisableTTL)
+	if r.manualReenable || entry.manual {
+		entry.manual = true
+		if entry.until.IsZero() {
+			entry.until = time.Now()
+		}
+	} else {
+		entry.until = time.Now().Add(r.tempDisableTTL)
+	}
 
 	eps[targetIdx].Enabled = false
 
@@ -469,8 +582,8 @@ func (r *DefaultRouter) DisableEndpoint(interfaceType InterfaceType, endpoint *E
 	r.active[interfaceType] = nil
 	for i := 1; i <= len(eps); i++ {
 		nextIdx := (targetIdx + i) % len(eps)
-		if eps[nextIdx].Enabled {
-			r.active[interfaceType] = eps[nextIdx]
+		if eps[nextIdx].isAutoRoutable(time.Now()) {
+			r.setActiveLocked(interfaceType, eps[nextIdx])
 			// Sticky failover: once an endpoint is temporarily disabled, keep using the failover endpoint
 			// until user manually switches again. This avoids automatic "back switch" on recovery.
 			r.preferred[interfaceType] = endpointKey(eps[nextIdx])
@@ -498,17 +611,19 @@ func (r *DefaultRouter) GetEndpointsByType(interfaceType InterfaceType) []*Endpo
 	return result
 }
 
-// GetEnabledEndpointsByType returns only enabled endpoints for the given interface type
+// GetEnabledEndpointsByType returns only enabled endpoints for the given interface type,
+// excluding endpoints outside their schedule windows
 // Requirements: 6.4 - active selector contains only enabled endpoints
 func (r *DefaultRouter) GetEnabledEndpointsByType(interfaceType InterfaceType) []*Endpoint {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.restoreExpiredLocked(interfaceType)
 
+	now := time.Now()
 	eps := r.endpoints[interfaceType]
 	var result []*Endpoint
 	for _, ep := range eps {
-		if ep.Enabled {
+		if ep.isRoutable(now) {
 			result = append(result, ep)
 		}
 	}
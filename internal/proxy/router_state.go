@@ -0,0 +1,56 @@
+package proxy
+
+import "time"
+
+// RouterEndpointState describes one endpoint as the router currently sees it
+type RouterEndpointState struct {
+	ID                int64
+	Name              string
+	Enabled           bool // effective state, false while temporarily disabled
+	Active            bool
+	Preferred         bool      // endpoint the router returns to when it is available
+	InSchedule        bool      // false while outside the endpoint's schedule windows
+	TempDisabledUntil time.Time // zero when not temporarily disabled
+	ManualDisabled    bool      // disabled after failures and waiting to be re-enabled by the user
+}
+
+// State returns, per interface type, the endpoints in routing order with their
+// active and temporary-disable flags. Expired temporary disables are restored first.
+func (r *DefaultRouter) State() map[InterfaceType][]RouterEndpointState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	result := make(map[InterfaceType][]RouterEndpointState, len(r.endpoints))
+	for interfaceType, eps := range r.endpoints {
+		r.restoreExpiredLocked(interfaceType)
+
+		active := r.active[interfaceType]
+		preferred := r.preferred[interfaceType]
+		disabled := r.tempDisabled[interfaceType]
+
+		states := make([]RouterEndpointState, 0, len(eps))
+		for _, ep := range eps {
+			if ep == nil {
+				continue
+			}
+			key := endpointKey(ep)
+			state := RouterEndpointState{
+				ID:         ep.ID,
+				Name:       ep.Name,
+				Enabled:    ep.Enabled,
+				Active:     active != nil && endpointKey(active) == key,
+				Preferred:  preferred != "" && preferred == key,
+				InSchedule: ep.InSchedule(now),
+			}
+			if entry := disabled[key]; entry != nil && entry.manual {
+				state.ManualDisabled = true
+			} else if entry != nil {
+				state.TempDisabledUntil = entry.until
+			}
+			states = append(states, state)
+		}
+		result[interfaceType] = states
+	}
+	return result
+}
@@ -0,0 +1,194 @@
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"clisimplehub/internal/executor"
+	"clisimplehub/internal/transformer"
+)
+
+// RoutingRule routes requests by path, header and body model instead of path alone.
+// All non-empty match fields must match; rules are evaluated in order and the first
+// match wins. A match forces the interface type, pins an endpoint, or both.
+type RoutingRule struct {
+	// PathPrefix matches the request path prefix (case-insensitive)
+	PathPrefix string `json:"pathPrefix"`
+	// Header names a request header that must be present; HeaderValue, when set,
+	// must be a case-insensitive prefix of its value
+	Header      string `json:"header"`
+	HeaderValue string `json:"headerValue"`
+	// ModelPrefix matches the "model" field of the request body (case-insensitive)
+	ModelPrefix string `json:"modelPrefix"`
+
+	// InterfaceType replaces the interface type detected from the path. When the path is a
+	// well-known path of another protocol, Endpoint must name an endpoint of the request's own
+	// type whose transformer converts to InterfaceType, or the request is rejected with 400
+	InterfaceType string `json:"interfaceType"`
+	// Endpoint pins the request to an endpoint (ID or name) of the interface type,
+	// without failover, like the endpoint override header
+	Endpoint string `json:"endpoint"`
+}
+
+// ParseRoutingRules converts the raw appConfig "routingRules" value into rules.
+// Accepts either a JSON array (as decoded from config.json) or a JSON string.
+func ParseRoutingRules(raw interface{}) ([]RoutingRule, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	var data []byte
+	switch v := raw.(type) {
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return nil, nil
+		}
+		data = []byte(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		data = b
+	}
+
+	var rules []RoutingRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("invalid routingRules: %w", err)
+	}
+	return rules, nil
+}
+
+// SetRoutingRules replaces the routing rules consulted before endpoint selection
+func (p *ProxyServer) SetRoutingRules(rules []RoutingRule) error {
+	normalized := make([]RoutingRule, 0, len(rules))
+	for i, rule := range rules {
+		rule.PathPrefix = strings.ToLower(strings.TrimSpace(rule.PathPrefix))
+		rule.Header = http.CanonicalHeaderKey(strings.TrimSpace(rule.Header))
+		rule.HeaderValue = strings.ToLower(strings.TrimSpace(rule.HeaderValue))
+		rule.ModelPrefix = strings.ToLower(strings.TrimSpace(rule.ModelPrefix))
+		rule.InterfaceType = normalizeInterfaceType(rule.InterfaceType)
+		rule.Endpoint = strings.TrimSpace(rule.Endpoint)
+
+		switch InterfaceType(rule.InterfaceType) {
+		case "", InterfaceTypeClaude, InterfaceTypeCodex, InterfaceTypeGemini, InterfaceTypeChat:
+		default:
+			return fmt.Errorf("invalid routingRules[%d] interfaceType %q", i, rule.InterfaceType)
+		}
+		switch {
+		case rule.PathPrefix == "" && rule.Header == "" && rule.ModelPrefix == "":
+			return fmt.Errorf("invalid routingRules[%d]: pathPrefix, header or modelPrefix is required", i)
+		case rule.HeaderValue != "" && rule.Header == "":
+			return fmt.Errorf("invalid routingRules[%d]: headerValue requires header", i)
+		case rule.InterfaceType == "" && rule.Endpoint == "":
+			return fmt.Errorf("invalid routingRules[%d]: interfaceType or endpoint is required", i)
+		}
+		normalized = append(normalized, rule)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.routingRules = normalized
+	return nil
+}
+
+// matches reports whether the rule applies to the request
+func (rule *RoutingRule) matches(lowerPath string, headers http.Header, lowerModel string) bool {
+	if rule.PathPrefix != "" && !strings.HasPrefix(lowerPath, rule.PathPrefix) {
+		return false
+	}
+	if rule.Header != "" {
+		value := strings.TrimSpace(headers.Get(rule.Header))
+		if value == "" || !strings.HasPrefix(strings.ToLower(value), rule.HeaderValue) {
+			return false
+		}
+	}
+	if rule.ModelPrefix != "" && !strings.HasPrefix(lowerModel, rule.ModelPrefix) {
+		return false
+	}
+	return true
+}
+
+// applyRoutingRules evaluates the routing rules for a request. It returns the interface type the
+// request executes as (the detected one unless the matching rule forces another), the interface
+// type forced by the rule (empty when none), and the endpoint pinned by the rule, if any.
+//
+// A rule that forces another protocol onto a request sent to a well-known protocol path (e.g. a
+// Claude body on /v1/messages forced to gemini) cannot be forwarded as is: it must pin an endpoint
+// of the request's own type whose transformer converts to the forced protocol, which then keeps
+// the request's type for execution. Otherwise an error is returned and the request is rejected.
+func (p *ProxyServer) applyRoutingRules(requestID string, r *http.Request, body []byte, interfaceType InterfaceType) (InterfaceType, InterfaceType, *executor.EndpointConfig, error) {
+	p.mu.RLock()
+	rules := p.routingRules
+	hub := p.wsHub
+	p.mu.RUnlock()
+	if len(rules) == 0 {
+		return interfaceType, "", nil, nil
+	}
+
+	lowerPath := strings.ToLower(r.URL.Path)
+	lowerModel := strings.ToLower(extractModelFromBody(body))
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.matches(lowerPath, r.Header, lowerModel) {
+			continue
+		}
+
+		forcedType := InterfaceType(rule.InterfaceType)
+		// 路径已表明请求协议且与强制类型不同：只能交给能转换协议的指定端点
+		requestType, known := detectBuiltinPath(lowerPath)
+		crossProtocol := forcedType != "" && known && requestType != forcedType
+		if forcedType != "" && !crossProtocol {
+			interfaceType = forcedType
+		}
+		message := fmt.Sprintf("[RoutingRule] rule %d matched: interfaceType=%s", i, interfaceType)
+		if crossProtocol {
+			message += fmt.Sprintf(" upstream=%s", forcedType)
+		}
+
+		var pinned *Endpoint
+		if rule.Endpoint != "" {
+			id, idErr := strconv.ParseInt(rule.Endpoint, 10, 64)
+			for _, ep := range p.router.GetEndpointsByType(interfaceType) {
+				if !ep.isRoutable(time.Now()) || !((idErr == nil && ep.ID == id) || ep.Name == rule.Endpoint) {
+					continue
+				}
+				if crossProtocol && !transformerConverts(ep.Transformer, requestType, forcedType) {
+					continue
+				}
+				pinned = ep
+				break
+			}
+			if pinned != nil {
+				message += " endpoint=" + endpointNameOrID(pinned)
+			} else {
+				message += fmt.Sprintf(" endpoint=%q unavailable, using normal routing", rule.Endpoint)
+			}
+		}
+
+		var err error
+		if crossProtocol && pinned == nil {
+			err = fmt.Errorf("routing rule %d forces %s for a %s request but pins no available %s endpoint whose transformer converts it", i, forcedType, requestType, requestType)
+			message = fmt.Sprintf("[RoutingRule] rule %d matched: %v", i, err)
+		}
+		if hub != nil {
+			hub.BroadcastDebugLog(&DebugLogPayload{RequestID: requestID, Level: 1, Message: message})
+		}
+		return interfaceType, forcedType, toExecutorEndpointConfig(pinned), err
+	}
+	return interfaceType, "", nil, nil
+}
+
+// transformerConverts reports whether the endpoint transformer spec converts requests of type
+// from into the upstream protocol to
+func transformerConverts(spec string, from, to InterfaceType) bool {
+	if strings.TrimSpace(spec) == "" {
+		return false
+	}
+	tr, err := transformer.Get(string(from), spec)
+	return err == nil && tr != nil && InterfaceType(tr.TargetInterfaceType()) == to
+}
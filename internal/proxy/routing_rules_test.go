@@ -0,0 +1,147 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newRoutingRulesServer(t *testing.T, rules []RoutingRule) *ProxyServer {
+	t.Helper()
+	router := NewRouter()
+	router.LoadEndpoints([]*Endpoint{
+		{ID: 1, Name: "claude-main", InterfaceType: "claude", Enabled: true, Active: true},
+		{ID: 2, Name: "claude-to-gemini", InterfaceType: "claude", Enabled: true, Transformer: "gemini"},
+		{ID: 3, Name: "gemini-main", InterfaceType: "gemini", Enabled: true, Active: true},
+		{ID: 4, Name: "codex-main", InterfaceType: "codex", Enabled: true, Active: true},
+	})
+	p := NewProxyServer(0, router)
+	if err := p.SetRoutingRules(rules); err != nil {
+		t.Fatalf("SetRoutingRules: %v", err)
+	}
+	return p
+}
+
+func TestSetRoutingRulesValidation(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		rule    RoutingRule
+		wantErr string
+	}{
+		{name: "no match field", rule: RoutingRule{InterfaceType: "claude"}, wantErr: "pathPrefix, header or modelPrefix"},
+		{name: "header value without header", rule: RoutingRule{PathPrefix: "/x", HeaderValue: "v", InterfaceType: "claude"}, wantErr: "headerValue requires header"},
+		{name: "no action", rule: RoutingRule{PathPrefix: "/x"}, wantErr: "interfaceType or endpoint"},
+		{name: "bad type", rule: RoutingRule{PathPrefix: "/x", InterfaceType: "bedrock"}, wantErr: "interfaceType"},
+		{name: "valid", rule: RoutingRule{ModelPrefix: "gemini-", Endpoint: "2"}},
+	}
+	for _, tc := range cases {
+		err := NewProxyServer(0, NewRouter()).SetRoutingRules([]RoutingRule{tc.rule})
+		if tc.wantErr == "" {
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", tc.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Fatalf("%s: err=%v want %q", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestApplyRoutingRules(t *testing.T) {
+	t.Parallel()
+
+	rules := []RoutingRule{
+		{PathPrefix: "/tenant-a/", InterfaceType: "codex"},
+		{Header: "X-Provider", HeaderValue: "gem", ModelPrefix: "gemini-", InterfaceType: "gemini", Endpoint: "claude-to-gemini"},
+		{ModelPrefix: "gemini-", Endpoint: "2"},
+		{ModelPrefix: "gemini-", Endpoint: "claude-main"}, // shadowed by the rule above
+		{Header: "X-Force", InterfaceType: "gemini"},
+		{Header: "X-Pin", Endpoint: "missing"},
+	}
+	cases := []struct {
+		name       string
+		path       string
+		header     string
+		value      string
+		body       string
+		detected   InterfaceType
+		wantType   InterfaceType
+		wantForced InterfaceType
+		wantPinned string
+		wantErr    bool
+	}{
+		{name: "no match", path: "/v1/messages", body: `{"model":"claude-3"}`, detected: InterfaceTypeClaude, wantType: InterfaceTypeClaude},
+		{name: "path prefix on unknown path forces type", path: "/tenant-a/v1/query", body: `{}`, detected: InterfaceTypeClaude, wantType: InterfaceTypeCodex, wantForced: InterfaceTypeCodex},
+		{name: "path prefix is case-insensitive", path: "/Tenant-A/run", body: `{}`, detected: InterfaceTypeClaude, wantType: InterfaceTypeCodex, wantForced: InterfaceTypeCodex},
+		{name: "model prefix pins endpoint", path: "/v1/messages", body: `{"model":"Gemini-2.0-flash"}`, detected: InterfaceTypeClaude, wantType: InterfaceTypeClaude, wantPinned: "claude-to-gemini"},
+		{name: "header and model must both match", path: "/v1/messages", header: "X-Provider", value: "other", body: `{"model":"gemini-pro"}`, detected: InterfaceTypeClaude, wantType: InterfaceTypeClaude, wantPinned: "claude-to-gemini"},
+		{name: "cross protocol with converting endpoint", path: "/v1/messages", header: "X-Provider", value: "Gemini", body: `{"model":"gemini-pro"}`, detected: InterfaceTypeClaude, wantType: InterfaceTypeClaude, wantForced: InterfaceTypeGemini, wantPinned: "claude-to-gemini"},
+		{name: "cross protocol without endpoint rejected", path: "/v1/messages", header: "X-Force", value: "1", body: `{"model":"claude-3"}`, detected: InterfaceTypeClaude, wantType: InterfaceTypeClaude, wantForced: InterfaceTypeGemini, wantErr: true},
+		{name: "same protocol force", path: "/v1beta/models/gemini-pro:generateContent", header: "X-Force", value: "1", body: `{}`, detected: InterfaceTypeGemini, wantType: InterfaceTypeGemini, wantForced: InterfaceTypeGemini},
+		{name: "unavailable endpoint falls back to normal routing", path: "/v1/messages", header: "X-Pin", value: "1", body: `{}`, detected: InterfaceTypeClaude, wantType: InterfaceTypeClaude},
+	}
+	p := newRoutingRulesServer(t, rules)
+	for _, tc := range cases {
+		r := httptest.NewRequest(http.MethodPost, tc.path, nil)
+		if tc.header != "" {
+			r.Header.Set(tc.header, tc.value)
+		}
+		gotType, gotForced, pinned, err := p.applyRoutingRules("req", r, []byte(tc.body), tc.detected)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("%s: err=%v wantErr=%v", tc.name, err, tc.wantErr)
+		}
+		gotPinned := ""
+		if pinned != nil {
+			gotPinned = pinned.Name
+		}
+		if gotType != tc.wantType || gotForced != tc.wantForced || gotPinned != tc.wantPinned {
+			t.Fatalf("%s: got type=%s forced=%s pinned=%q want type=%s forced=%s pinned=%q",
+				tc.name, gotType, gotForced, gotPinned, tc.wantType, tc.wantForced, tc.wantPinned)
+		}
+	}
+}
+
+func TestApplyRoutingRulesCrossProtocolNeedsTransformer(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		endpoint string
+		wantErr  bool
+	}{
+		{name: "endpoint converts to forced type", endpoint: "claude-to-gemini"},
+		{name: "endpoint without transformer", endpoint: "claude-main", wantErr: true},
+		{name: "endpoint of forced type", endpoint: "gemini-main", wantErr: true},
+	}
+	for _, tc := range cases {
+		p := newRoutingRulesServer(t, []RoutingRule{{ModelPrefix: "gemini-", InterfaceType: "gemini", Endpoint: tc.endpoint}})
+		r := httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+		_, _, pinned, err := p.applyRoutingRules("req", r, []byte(`{"model":"gemini-pro"}`), InterfaceTypeClaude)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("%s: err=%v wantErr=%v", tc.name, err, tc.wantErr)
+		}
+		if !tc.wantErr && (pinned == nil || pinned.Name != tc.endpoint) {
+			t.Fatalf("%s: pinned=%v want %s", tc.name, pinned, tc.endpoint)
+		}
+	}
+}
+
+func TestHandleProxyRoutingRuleCrossProtocolRejected(t *testing.T) {
+	t.Parallel()
+
+	p := newRoutingRulesServer(t, []RoutingRule{{ModelPrefix: "gpt-", InterfaceType: "codex"}})
+	r := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"gpt-5"}`))
+	w := httptest.NewRecorder()
+	p.handleProxy(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status=%d want 400 body=%s", w.Code, w.Body.String())
+	}
+	logs := p.stats.GetRecentLogs(0)
+	if len(logs) == 0 || logs[len(logs)-1].Status != "error_400" {
+		t.Fatalf("rejection not logged: %+v", logs)
+	}
+}
@@ -3,6 +3,8 @@ package proxy
 import (
 	"net/http"
 	"strings"
+
+	"clisimplehub/internal/executor"
 )
 
 func sanitizeHeadersForLog(headers http.Header) map[string]string {
@@ -11,10 +13,14 @@ func sanitizeHeadersForLog(headers http.Header) map[string]string {
 	}
 
 	sanitized := make(map[string]string, len(headers))
+	drop := executor.DropSensitiveHeaders()
 	for key, values := range headers {
 		if len(values) == 0 {
 			continue
 		}
+		if drop && executor.IsSensitiveHeader(key) {
+			continue
+		}
 		sanitized[key] = sanitizeHeaderValue(key, values[0])
 	}
 	return sanitized
@@ -34,6 +40,9 @@ func sanitizeHeaderValue(key string, value string) string {
 	if strings.EqualFold(key, "Cookie") {
 		return "[redacted]"
 	}
+	if executor.IsSensitiveHeader(key) {
+		return maskSecret(value)
+	}
 	return value
 }
 
@@ -54,8 +63,10 @@ func MaskAuthorizationValue(value string) string {
 	return maskAuthorizationValue(value)
 }
 
+// formatUpstreamAuthForLogConfig masks the credential sent upstream for apiKey, resolving
+// file: references the same way the executor does
 func formatUpstreamAuthForLogConfig(interfaceType string, apiKey string) string {
-	key := strings.TrimSpace(apiKey)
+	key := strings.TrimSpace(executor.ResolveAPIKey(apiKey))
 	if key == "" {
 		return ""
 	}
@@ -0,0 +1,34 @@
+package proxy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFormatUpstreamAuthForLogConfig(t *testing.T) {
+	t.Parallel()
+
+	keyFile := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(keyFile, []byte("sk-from-file-123456\n"), 0600); err != nil {
+		t.Fatalf("write key file: %v", err)
+	}
+
+	cases := []struct {
+		name          string
+		interfaceType string
+		apiKey        string
+		want          string
+	}{
+		{name: "empty", interfaceType: "claude", apiKey: "", want: ""},
+		{name: "claude", interfaceType: "claude", apiKey: "sk-inline-abcdef", want: "key=" + maskSecret("sk-inline-abcdef")},
+		{name: "codex bearer", interfaceType: "codex", apiKey: "sk-inline-abcdef", want: "Authorization: Bearer " + maskSecret("sk-inline-abcdef")},
+		{name: "file key resolved", interfaceType: "chat", apiKey: "file:" + keyFile, want: "Authorization: Bearer " + maskSecret("sk-from-file-123456")},
+		{name: "unreadable file", interfaceType: "claude", apiKey: "file:" + keyFile + ".missing", want: ""},
+	}
+	for _, tc := range cases {
+		if got := formatUpstreamAuthForLogConfig(tc.interfaceType, tc.apiKey); got != tc.want {
+			t.Fatalf("%s: got %q want %q", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package proxy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"clisimplehub/internal/statsdb"
+)
+
+const minutesPerDay = 24 * 60
+
+// ValidateSchedule checks that every window has valid days and HH:MM times
+func ValidateSchedule(windows []ScheduleWindow) error {
+	for i, w := range windows {
+		for _, d := range w.Days {
+			if d < 0 || d > 6 {
+				return fmt.Errorf("schedule window %d: invalid day %d (expected 0-6, 0=Sunday)", i+1, d)
+			}
+		}
+		start, ok := parseScheduleClock(w.StartHHMM)
+		if !ok || start == minutesPerDay {
+			return fmt.Errorf("schedule window %d: invalid start time %q (expected HH:MM)", i+1, w.StartHHMM)
+		}
+		end, ok := parseScheduleClock(w.EndHHMM)
+		if !ok {
+			return fmt.Errorf("schedule window %d: invalid end time %q (expected HH:MM)", i+1, w.EndHHMM)
+		}
+		if start == end {
+			return fmt.Errorf("schedule window %d: start and end time are equal", i+1)
+		}
+	}
+	return nil
+}
+
+// InSchedule reports whether now falls inside one of the endpoint's schedule windows,
+// evaluated in the stats timezone. Endpoints without a schedule are always in schedule.
+func (e *Endpoint) InSchedule(now time.Time) bool {
+	if e == nil || len(e.Schedule) == 0 {
+		return true
+	}
+	now = now.In(statsdb.StatsLocation())
+	minute := now.Hour()*60 + now.Minute()
+	today := now.Weekday()
+	yesterday := (today + 6) % 7
+
+	matched := false
+	for _, w := range e.Schedule {
+		start, ok1 := parseScheduleClock(w.StartHHMM)
+		end, ok2 := parseScheduleClock(w.EndHHMM)
+		if !ok1 || !ok2 || start == end {
+			continue // invalid windows are ignored
+		}
+		matched = true
+		if start < end {
+			if scheduleHasDay(w.Days, today) && minute >= start && minute < end {
+				return true
+			}
+			continue
+		}
+		// window crosses midnight: the day list refers to the day it starts
+		if scheduleHasDay(w.Days, today) && minute >= start {
+			return true
+		}
+		if scheduleHasDay(w.Days, yesterday) && minute < end {
+			return true
+		}
+	}
+	// a schedule made only of invalid windows does not restrict the endpoint
+	return !matched
+}
+
+// isRoutable reports whether the router may send traffic to the endpoint right now
+func (e *Endpoint) isRoutable(now time.Time) bool {
+	return e != nil && e.Enabled && e.InSchedule(now)
+}
+
+// isAutoRoutable reports whether automatic routing and fallback may pick the endpoint.
+// Sandbox endpoints are only used when selected explicitly.
+func (e *Endpoint) isAutoRoutable(now time.Time) bool {
+	return e.isRoutable(now) && !e.Sandbox
+}
+
+func scheduleHasDay(days []int, day time.Weekday) bool {
+	if len(days) == 0 {
+		return true
+	}
+	for _, d := range days {
+		if time.Weekday(d) == day {
+			return true
+		}
+	}
+	return false
+}
+
+// parseScheduleClock parses "HH:MM" or "HHMM" into minutes since midnight; "24:00" is allowed
+func parseScheduleClock(s string) (int, bool) {
+	s = strings.TrimSpace(s)
+	hh, mm, found := strings.Cut(s, ":")
+	if !found {
+		if len(s) != 4 {
+			return 0, false
+		}
+		hh, mm = s[:2], s[2:]
+	}
+	h, err := strconv.Atoi(hh)
+	if err != nil || len(mm) != 2 {
+		return 0, false
+	}
+	m, err := strconv.Atoi(mm)
+	if err != nil || h < 0 || m < 0 || m > 59 {
+		return 0, false
+	}
+	total := h*60 + m
+	if total > minutesPerDay {
+		return 0, false
+	}
+	return total, true
+}
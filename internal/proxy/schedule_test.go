@@ -0,0 +1,101 @@
+package proxy
+
+import (
+	"testing"
+	"time"
+
+	"clisimplehub/internal/statsdb"
+)
+
+func TestValidateSchedule(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		windows []ScheduleWindow
+		wantErr bool
+	}{
+		{name: "empty"},
+		{name: "valid", windows: []ScheduleWindow{{Days: []int{1, 5}, StartHHMM: "09:00", EndHHMM: "18:00"}}},
+		{name: "compact times and end of day", windows: []ScheduleWindow{{StartHHMM: "2200", EndHHMM: "24:00"}}},
+		{name: "crosses midnight", windows: []ScheduleWindow{{StartHHMM: "22:00", EndHHMM: "06:00"}}},
+		{name: "bad day", windows: []ScheduleWindow{{Days: []int{7}, StartHHMM: "09:00", EndHHMM: "18:00"}}, wantErr: true},
+		{name: "bad start", windows: []ScheduleWindow{{StartHHMM: "9am", EndHHMM: "18:00"}}, wantErr: true},
+		{name: "start at 24:00", windows: []ScheduleWindow{{StartHHMM: "24:00", EndHHMM: "01:00"}}, wantErr: true},
+		{name: "bad minutes", windows: []ScheduleWindow{{StartHHMM: "09:00", EndHHMM: "18:60"}}, wantErr: true},
+		{name: "empty window", windows: []ScheduleWindow{{StartHHMM: "09:00", EndHHMM: "09:00"}}, wantErr: true},
+	}
+	for _, tc := range cases {
+		if err := ValidateSchedule(tc.windows); (err != nil) != tc.wantErr {
+			t.Fatalf("%s: err=%v wantErr=%v", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestEndpointInSchedule(t *testing.T) {
+	t.Parallel()
+
+	// 2024-01-01 is a Monday (day 1)
+	at := func(day int, hhmm string) time.Time {
+		clock, _ := time.Parse("15:04", hhmm)
+		return time.Date(2024, 1, day, clock.Hour(), clock.Minute(), 0, 0, statsdb.StatsLocation())
+	}
+	workHours := []ScheduleWindow{{Days: []int{1, 2, 3, 4, 5}, StartHHMM: "09:00", EndHHMM: "18:00"}}
+	overnight := []ScheduleWindow{{Days: []int{1}, StartHHMM: "22:00", EndHHMM: "06:00"}}
+
+	cases := []struct {
+		name     string
+		schedule []ScheduleWindow
+		now      time.Time
+		want     bool
+	}{
+		{name: "no schedule", now: at(7, "03:00"), want: true},
+		{name: "inside window", schedule: workHours, now: at(1, "09:00"), want: true},
+		{name: "end is exclusive", schedule: workHours, now: at(1, "18:00"), want: false},
+		{name: "before window", schedule: workHours, now: at(1, "08:59"), want: false},
+		{name: "day not listed", schedule: workHours, now: at(7, "12:00"), want: false},
+		{name: "overnight start day", schedule: overnight, now: at(1, "23:30"), want: true},
+		{name: "overnight spills into next day", schedule: overnight, now: at(2, "05:59"), want: true},
+		{name: "overnight ended", schedule: overnight, now: at(2, "06:00"), want: false},
+		{name: "overnight not started on other day", schedule: overnight, now: at(2, "23:00"), want: false},
+		{name: "only invalid windows do not restrict", schedule: []ScheduleWindow{{StartHHMM: "x", EndHHMM: "y"}}, now: at(1, "12:00"), want: true},
+	}
+	for _, tc := range cases {
+		ep := &Endpoint{Enabled: true, Schedule: tc.schedule}
+		if got := ep.InSchedule(tc.now); got != tc.want {
+			t.Fatalf("%s: InSchedule(%s)=%v want %v", tc.name, tc.now.Format("Mon 15:04"), got, tc.want)
+		}
+	}
+}
+
+func TestRouterSkipsEndpointsOutsideSchedule(t *testing.T) {
+	t.Parallel()
+
+	// a window on every day except today never matches now
+	today := int(time.Now().In(statsdb.StatsLocation()).Weekday())
+	var otherDays []int
+	for d := 0; d < 7; d++ {
+		if d != today {
+			otherDays = append(otherDays, d)
+		}
+	}
+	offHours := &Endpoint{ID: 1, Name: "off-hours", InterfaceType: "claude", Enabled: true, Active: true,
+		Schedule: []ScheduleWindow{{Days: otherDays, StartHHMM: "00:00", EndHHMM: "24:00"}}}
+	router := NewRouter()
+	router.LoadEndpoints([]*Endpoint{offHours, {ID: 2, Name: "always", InterfaceType: "claude", Enabled: true}})
+
+	if ep := router.GetActiveEndpoint(InterfaceTypeClaude); ep == nil || ep.Name != "always" {
+		t.Fatalf("active=%v want always", ep)
+	}
+	if eps := router.GetEnabledEndpointsByType(InterfaceTypeClaude); len(eps) != 1 || eps[0].Name != "always" {
+		t.Fatalf("enabled endpoints=%v", eps)
+	}
+	if !offHours.Enabled {
+		t.Fatalf("schedule changed the stored Enabled flag")
+	}
+	for _, s := range router.State()[InterfaceTypeClaude] {
+		if s.Name == "off-hours" && (s.InSchedule || !s.Enabled) {
+			t.Fatalf("state=%+v want enabled but out of schedule", s)
+		}
+	}
+}
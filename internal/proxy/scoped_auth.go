@@ -0,0 +1,152 @@
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ScopedAuthKey is an additional labeled proxy key that may only be used for some interface
+// types. The global auth key keeps access to every interface type.
+type ScopedAuthKey struct {
+	Label          string   `json:"label"`
+	Key            string   `json:"key"`
+	InterfaceTypes []string `json:"interfaceTypes,omitempty"` // empty allows every interface type
+}
+
+// ParseScopedAuthKeys parses the authKeys config value (a JSON array or its string form)
+func ParseScopedAuthKeys(raw interface{}) ([]ScopedAuthKey, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	var data []byte
+	switch v := raw.(type) {
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return nil, nil
+		}
+		data = []byte(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		data = b
+	}
+
+	var keys []ScopedAuthKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("invalid authKeys: %w", err)
+	}
+	return keys, nil
+}
+
+// SetScopedAuthKeys replaces the labeled keys. Once any is configured, requests must present
+// either the global auth key or one of these keys.
+func (p *ProxyServer) SetScopedAuthKeys(keys []ScopedAuthKey) error {
+	normalized := make([]ScopedAuthKey, 0, len(keys))
+	seen := make(map[string]bool, len(keys))
+	for i, key := range keys {
+		key.Label = strings.TrimSpace(key.Label)
+		key.Key = strings.TrimSpace(key.Key)
+		if key.Key == "" {
+			return fmt.Errorf("invalid authKeys[%d]: key is required", i)
+		}
+		if seen[key.Key] {
+			return fmt.Errorf("invalid authKeys[%d]: duplicate key", i)
+		}
+		seen[key.Key] = true
+		if key.Label == "" {
+			key.Label = fmt.Sprintf("key-%d", i+1)
+		}
+
+		types := make([]string, 0, len(key.InterfaceTypes))
+		for _, t := range key.InterfaceTypes {
+			t = normalizeInterfaceType(t)
+			switch InterfaceType(t) {
+			case InterfaceTypeClaude, InterfaceTypeCodex, InterfaceTypeGemini, InterfaceTypeChat:
+			default:
+				return fmt.Errorf("invalid authKeys[%d] interfaceType %q", i, t)
+			}
+			types = append(types, t)
+		}
+		key.InterfaceTypes = types
+		normalized = append(normalized, key)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.scopedAuthKeys = normalized
+	return nil
+}
+
+// authenticate checks the request against the global key and the labeled keys. It returns
+// the matching labeled key (nil for the global key or when auth is off) and whether the
+// request is authorized.
+func (p *ProxyServer) authenticate(r *http.Request) (*ScopedAuthKey, bool) {
+	p.mu.RLock()
+	required := p.authKey
+	keys := p.scopedAuthKeys
+	p.mu.RUnlock()
+
+	if len(keys) > 0 && r != nil {
+		presented := bearerToken(r.Header.Get("Authorization"))
+		if presented == "" {
+			presented = strings.TrimSpace(r.Header.Get("x-api-key"))
+		}
+		for i := range keys {
+			if presented != "" && presented == keys[i].Key {
+				return &keys[i], true
+			}
+		}
+		// labeled keys turn auth on even without a global key
+		if required == "" || required == "-" {
+			return nil, false
+		}
+	}
+	if required == "" {
+		return nil, true
+	}
+	return nil, isAuthorized(r, required)
+}
+
+// authorizeAdmin checks access to the management endpoints (stats, log level, debug traces):
+// only the global key or a labeled key without an interface-type limit is accepted
+func (p *ProxyServer) authorizeAdmin(r *http.Request) bool {
+	key, ok := p.authenticate(r)
+	return ok && (key == nil || len(key.InterfaceTypes) == 0)
+}
+
+// label returns the key label for request logs ("" for the global key)
+func (k *ScopedAuthKey) label() string {
+	if k == nil {
+		return ""
+	}
+	return k.Label
+}
+
+// allows reports whether the key may be used for the interface type
+func (k *ScopedAuthKey) allows(interfaceType InterfaceType) bool {
+	if k == nil || len(k.InterfaceTypes) == 0 {
+		return true
+	}
+	for _, t := range k.InterfaceTypes {
+		if InterfaceType(t) == interfaceType {
+			return true
+		}
+	}
+	return false
+}
+
+// rejectScopedAuthKey answers 403 for a labeled key used outside its interface types and
+// records the denial in the request log
+func (p *ProxyServer) rejectScopedAuthKey(w http.ResponseWriter, r *http.Request, requestID string, interfaceType InterfaceType, key *ScopedAuthKey, startTime time.Time, reqHeaders map[string]string) {
+	message := fmt.Sprintf("Key %q is not allowed to use %s endpoints", key.Label, interfaceType)
+	writeProxyError(w, interfaceType, http.StatusForbidden, message)
+	detail := &RequestDetail{Method: r.Method, StatusCode: http.StatusForbidden, RequestHeaders: reqHeaders, ResponseStream: message, AuthKey: key.label()}
+	runTime := time.Since(startTime).Milliseconds()
+	p.recordRequestWithDetail(requestID, interfaceType, nil, r.URL.Path, startTime, "error_403", runTime, detail)
+}
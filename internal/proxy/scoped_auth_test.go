@@ -0,0 +1,186 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseScopedAuthKeys(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		raw     interface{}
+		want    int
+		wantErr bool
+	}{
+		{name: "nil", raw: nil, want: 0},
+		{name: "blank string", raw: "  ", want: 0},
+		{name: "json string", raw: `[{"label":"a","key":"k1","interfaceTypes":["claude"]}]`, want: 1},
+		{name: "decoded array", raw: []interface{}{map[string]interface{}{"key": "k1"}, map[string]interface{}{"key": "k2"}}, want: 2},
+		{name: "invalid", raw: `{"key":"k1"}`, wantErr: true},
+	}
+	for _, tc := range cases {
+		keys, err := ParseScopedAuthKeys(tc.raw)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("%s: err=%v wantErr=%v", tc.name, err, tc.wantErr)
+		}
+		if len(keys) != tc.want {
+			t.Fatalf("%s: got %d keys want %d", tc.name, len(keys), tc.want)
+		}
+	}
+}
+
+func TestSetScopedAuthKeys(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name      string
+		keys      []ScopedAuthKey
+		wantErr   string
+		wantLabel string
+		wantTypes []string
+	}{
+		{name: "empty key", keys: []ScopedAuthKey{{Label: "a", Key: " "}}, wantErr: "key is required"},
+		{name: "duplicate key", keys: []ScopedAuthKey{{Key: "k1"}, {Key: "k1"}}, wantErr: "duplicate key"},
+		{name: "bad type", keys: []ScopedAuthKey{{Key: "k1", InterfaceTypes: []string{"bedrock"}}}, wantErr: "interfaceType"},
+		{name: "default label", keys: []ScopedAuthKey{{Key: " k1 "}}, wantLabel: "key-1"},
+		{name: "normalized types", keys: []ScopedAuthKey{{Label: " team ", Key: "k1", InterfaceTypes: []string{" Claude "}}}, wantLabel: "team", wantTypes: []string{"claude"}},
+	}
+	for _, tc := range cases {
+		p := NewProxyServer(0, NewRouter())
+		err := p.SetScopedAuthKeys(tc.keys)
+		if tc.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("%s: err=%v want %q", tc.name, err, tc.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		got := p.scopedAuthKeys[0]
+		if got.Label != tc.wantLabel || got.Key != "k1" || strings.Join(got.InterfaceTypes, ",") != strings.Join(tc.wantTypes, ",") {
+			t.Fatalf("%s: got %+v", tc.name, got)
+		}
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	t.Parallel()
+
+	labeled := []ScopedAuthKey{{Label: "claude-only", Key: "k1", InterfaceTypes: []string{"claude"}}, {Label: "any", Key: "k2"}}
+	cases := []struct {
+		name      string
+		globalKey string
+		keys      []ScopedAuthKey
+		presented string
+		wantOK    bool
+		wantLabel string
+		wantAdmin bool
+		useAPIKey bool
+	}{
+		{name: "auth off", wantOK: true, wantAdmin: true},
+		{name: "global key", globalKey: "g", presented: "g", wantOK: true, wantAdmin: true},
+		{name: "global key wrong", globalKey: "g", presented: "x", wantOK: false},
+		{name: "labeled key with global", globalKey: "g", keys: labeled, presented: "k1", wantOK: true, wantLabel: "claude-only"},
+		{name: "global key alongside labeled", globalKey: "g", keys: labeled, presented: "g", wantOK: true, wantAdmin: true},
+		{name: "labeled only mode rejects missing key", keys: labeled, wantOK: false},
+		{name: "labeled only mode accepts x-api-key", keys: labeled, presented: "k2", useAPIKey: true, wantOK: true, wantLabel: "any", wantAdmin: true},
+		{name: "dash disables global only", globalKey: "-", presented: "", wantOK: true, wantAdmin: true},
+		{name: "dash with labeled keys requires a key", globalKey: "-", keys: labeled, presented: "", wantOK: false},
+		{name: "dash with labeled key", globalKey: "-", keys: labeled, presented: "k1", wantOK: true, wantLabel: "claude-only"},
+	}
+	for _, tc := range cases {
+		p := NewProxyServer(0, NewRouter())
+		p.SetAuthKey(tc.globalKey)
+		if err := p.SetScopedAuthKeys(tc.keys); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		r := httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+		if tc.presented != "" {
+			if tc.useAPIKey {
+				r.Header.Set("x-api-key", tc.presented)
+			} else {
+				r.Header.Set("Authorization", "Bearer "+tc.presented)
+			}
+		}
+		key, ok := p.authenticate(r)
+		if ok != tc.wantOK || key.label() != tc.wantLabel {
+			t.Fatalf("%s: ok=%v label=%q want ok=%v label=%q", tc.name, ok, key.label(), tc.wantOK, tc.wantLabel)
+		}
+		if admin := p.authorizeAdmin(r); admin != tc.wantAdmin {
+			t.Fatalf("%s: admin=%v want %v", tc.name, admin, tc.wantAdmin)
+		}
+	}
+}
+
+func TestScopedAuthKeyAllows(t *testing.T) {
+	t.Parallel()
+
+	var global *ScopedAuthKey
+	unscoped := &ScopedAuthKey{Key: "k"}
+	scoped := &ScopedAuthKey{Key: "k", InterfaceTypes: []string{"claude", "chat"}}
+	cases := []struct {
+		name string
+		key  *ScopedAuthKey
+		typ  InterfaceType
+		want bool
+	}{
+		{name: "global key", key: global, typ: InterfaceTypeGemini, want: true},
+		{name: "unscoped key", key: unscoped, typ: InterfaceTypeCodex, want: true},
+		{name: "scoped allowed", key: scoped, typ: InterfaceTypeChat, want: true},
+		{name: "scoped denied", key: scoped, typ: InterfaceTypeCodex, want: false},
+	}
+	for _, tc := range cases {
+		if got := tc.key.allows(tc.typ); got != tc.want {
+			t.Fatalf("%s: allows(%s)=%v want %v", tc.name, tc.typ, got, tc.want)
+		}
+	}
+}
+
+func TestHandleProxyScopedAuthKeyForbidden(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name       string
+		path       string
+		header     string
+		wantStatus int
+	}{
+		{name: "detected type denied", path: "/v1/responses", wantStatus: http.StatusForbidden},
+		{name: "detected type allowed", path: "/v1/messages", wantStatus: http.StatusServiceUnavailable},
+		{name: "routing rule forces denied type", path: "/v1/messages", header: "codex", wantStatus: http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		p := NewProxyServer(0, NewRouter())
+		if err := p.SetScopedAuthKeys([]ScopedAuthKey{{Label: "claude-team", Key: "k1", InterfaceTypes: []string{"claude"}}}); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if err := p.SetRoutingRules([]RoutingRule{{Header: "X-Route", HeaderValue: "codex", InterfaceType: "codex"}}); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+
+		r := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(`{"model":"m"}`))
+		r.Header.Set("Authorization", "Bearer k1")
+		if tc.header != "" {
+			r.Header.Set("X-Route", tc.header)
+		}
+		w := httptest.NewRecorder()
+		p.handleProxy(w, r)
+		if w.Code != tc.wantStatus {
+			t.Fatalf("%s: status=%d want %d body=%s", tc.name, w.Code, tc.wantStatus, w.Body.String())
+		}
+
+		logs := p.stats.GetRecentLogs(0)
+		if len(logs) == 0 {
+			t.Fatalf("%s: request not logged", tc.name)
+		}
+		last := logs[len(logs)-1]
+		if tc.wantStatus == http.StatusForbidden && (last.Status != "error_403" || last.AuthKey != "claude-team") {
+			t.Fatalf("%s: log status=%q authKey=%q", tc.name, last.Status, last.AuthKey)
+		}
+	}
+}
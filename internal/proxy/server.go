@@ -10,24 +10,42 @@ import (
 	"sync"
 	"time"
 
+	"clisimplehub/internal/executor"
 	"clisimplehub/internal/statsdb"
 	"clisimplehub/internal/storage"
 )
 
 // ProxyServer represents the main proxy server implementation
 type ProxyServer struct {
-	port        int
-	router      Router
-	server      *http.Server
-	stats       *StatsManager
-	wsHub       *WSHub
-	mu          sync.RWMutex
-	authKey     string
-	store       storage.Storage
-	vendorStats statsdb.VendorStatsStore
-
-	fallbackEnabled bool
-	exec            *proxyExecutor
+	port    int
+	router  Router
+	server  *http.Server
+	stats   *StatsManager
+	wsHub   *WSHub
+	mu      sync.RWMutex
+	authKey string
+	// scopedAuthKeys are labeled keys limited to some interface types
+	scopedAuthKeys []ScopedAuthKey
+	store          storage.Storage
+	vendorStats    statsdb.VendorStatsStore
+
+	fallbackEnabled       bool
+	endpointOverride      bool
+	compressResponses     bool
+	skipBodyCapture       bool // captureBodies=false: request logs omit request/response streams
+	warmupOnActivate      bool
+	pathRewrites          []compiledPathRewrite
+	experiments           []Experiment
+	routingRules          []RoutingRule
+	responseCache         *ResponseCache
+	streamCoalescer       *StreamCoalescer
+	notifier              *WebhookNotifier
+	fairQueue             *FairQueue
+	requestDeadline       time.Duration
+	streamRequestDeadline time.Duration
+	activeRequests        map[string]context.CancelCauseFunc // in-flight requests by ID
+	debugCapture          debugCapture
+	exec                  *proxyExecutor
 }
 
 // NewProxyServer creates a new ProxyServer instance
@@ -96,12 +114,18 @@ func (p *ProxyServer) Start() error {
 	mux.HandleFunc("/", p.handleProxy)
 	mux.HandleFunc("/health", p.handleHealth)
 	mux.HandleFunc("/stats", p.handleStats)
+	mux.HandleFunc("/stats.json", p.handleStatsJSON)
 	mux.HandleFunc("/transformers", p.handleTransformers)
+	mux.HandleFunc("/log-level", p.handleLogLevel)
+	mux.HandleFunc("/debug-traces", p.handleDebugTraces)
 
 	if p.wsHub != nil {
 		mux.HandleFunc("/ws", p.wsHub.HandleWebSocket)
 	}
 
+	// Endpoints targeting this port on a local address are rejected as proxy loops
+	executor.SetSelfPort(p.port)
+
 	p.server = &http.Server{
 		Addr:         fmt.Sprintf(":%d", p.port),
 		Handler:      mux,
@@ -205,6 +229,9 @@ func (p *ProxyServer) handleStats(w http.ResponseWriter, r *http.Request) {
 		"recent_logs": p.stats.GetRecentLogs(5),
 		"token_stats": p.stats.GetTokenStats(),
 	}
+	if queues := p.QueueStats(); queues != nil {
+		stats["queues"] = queues
+	}
 
 	_ = json.NewEncoder(w).Encode(stats)
 }
@@ -13,6 +13,8 @@ const MaxRecentLogs = 5
 // StatsManager manages request logs and token statistics (in-memory only)
 type StatsManager struct {
 	recentLogs []*RequestLog
+	// dropped holds IDs of in-progress logs deleted by the user, so their completion is not re-added
+	dropped    map[string]bool
 	tokenStats map[string]*TokenStats // keyed by endpoint name
 	mu         sync.RWMutex
 	wsHub      *WSHub          // WebSocket hub for broadcasting
@@ -23,6 +25,7 @@ type StatsManager struct {
 func NewStatsManager() *StatsManager {
 	return &StatsManager{
 		recentLogs: make([]*RequestLog, 0, MaxRecentLogs),
+		dropped:    make(map[string]bool),
 		tokenStats: make(map[string]*TokenStats),
 	}
 }
@@ -32,6 +35,17 @@ func (s *StatsManager) SetWSHub(hub *WSHub) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.wsHub = hub
+	if hub != nil {
+		hub.SetSnapshotProvider(s.Snapshot)
+	}
+}
+
+// Snapshot returns the recent logs and token stats for a newly connected WebSocket client
+func (s *StatsManager) Snapshot() *SnapshotPayload {
+	return &SnapshotPayload{
+		RecentLogs: s.GetRecentLogs(0),
+		TokenStats: s.GetTokenStats(),
+	}
 }
 
 // SetStorage sets the storage for persistence
@@ -52,6 +66,12 @@ func (s *StatsManager) RecordRequest(log *RequestLog) {
 	if log == nil {
 		return
 	}
+	if s.dropped[log.ID] {
+		if log.Status != "in_progress" {
+			delete(s.dropped, log.ID)
+		}
+		return
+	}
 
 	if log.VendorName == "" && log.VendorID != 0 && s.storage != nil {
 		if vendor, err := s.storage.GetVendorByID(log.VendorID); err == nil && vendor != nil {
@@ -93,6 +113,43 @@ broadcast:
 	}
 }
 
+// DeleteLog removes a single request log and its captured detail.
+// Returns false when the log is no longer in the recent logs.
+func (s *StatsManager) DeleteLog(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, log := range s.recentLogs {
+		if log == nil || log.ID != id {
+			continue
+		}
+		s.dropLocked(log)
+		s.recentLogs = append(s.recentLogs[:i:i], s.recentLogs[i+1:]...)
+		return true
+	}
+	return false
+}
+
+// ClearLogs removes all recent request logs and their captured details
+func (s *StatsManager) ClearLogs() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, log := range s.recentLogs {
+		if log != nil {
+			s.dropLocked(log)
+		}
+	}
+	s.recentLogs = make([]*RequestLog, 0, MaxRecentLogs)
+}
+
+// dropLocked remembers deleted in-progress logs so their final update is ignored
+func (s *StatsManager) dropLocked(log *RequestLog) {
+	if log.ID != "" && log.Status == "in_progress" {
+		s.dropped[log.ID] = true
+	}
+}
+
 // RecordTokens records token usage for an endpoint
 // Requirements: 8.1, 8.2, 8.3, 8.5
 func (s *StatsManager) RecordTokens(endpointName string, tokens *TokenUsage) {
@@ -175,3 +232,11 @@ func (s *StatsManager) GetTokenStatsForEndpoint(endpointName string) *TokenStats
 	}
 	return nil
 }
+
+// ResetInMemoryStats clears the accumulated token statistics so they stay
+// consistent with the persisted stats after a clear
+func (s *StatsManager) ResetInMemoryStats() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokenStats = make(map[string]*TokenStats)
+}
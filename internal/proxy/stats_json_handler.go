@@ -0,0 +1,88 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"clisimplehub/internal/statsdb"
+)
+
+// statsQuerier is implemented by vendor stats stores that can answer aggregate queries
+type statsQuerier interface {
+	GetStatsByInterfaceType(ctx context.Context, timeRange statsdb.TimeRange) ([]statsdb.InterfaceTypeStatsSummary, error)
+	GetTodayStatsByEndpoints(ctx context.Context) (map[string]*statsdb.EndpointDailyStats, error)
+}
+
+// parseStatsTimeRange maps the range query param to a statsdb.TimeRange (default: today)
+func parseStatsTimeRange(raw string) (statsdb.TimeRange, bool) {
+	switch tr := statsdb.TimeRange(strings.ToLower(strings.TrimSpace(raw))); tr {
+	case "":
+		return statsdb.TimeRangeToday, true
+	case statsdb.TimeRangeToday, statsdb.TimeRangeYesterday, statsdb.TimeRangeWeek, statsdb.TimeRangeMonth, statsdb.TimeRangeAll:
+		return tr, true
+	default:
+		return "", false
+	}
+}
+
+// handleStatsJSON serves persisted usage aggregates for lightweight dashboards.
+// It requires the same auth key as proxied requests.
+func (p *ProxyServer) handleStatsJSON(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	writeError := func(status int, msg string) {
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(map[string]any{"error": msg})
+	}
+
+	if r.Method != http.MethodGet {
+		writeError(http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if !p.authorizeAdmin(r) {
+		writeError(http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	timeRange, ok := parseStatsTimeRange(r.URL.Query().Get("range"))
+	if !ok {
+		writeError(http.StatusBadRequest, "invalid range: expected today, yesterday, week, month or all")
+		return
+	}
+
+	p.mu.RLock()
+	querier, ok := p.vendorStats.(statsQuerier)
+	p.mu.RUnlock()
+	if !ok || querier == nil {
+		writeError(http.StatusServiceUnavailable, "stats store not available")
+		return
+	}
+
+	byInterface, err := querier.GetStatsByInterfaceType(r.Context(), timeRange)
+	if err != nil {
+		writeError(http.StatusInternalServerError, err.Error())
+		return
+	}
+	todayByEndpoint, err := querier.GetTodayStatsByEndpoints(r.Context())
+	if err != nil {
+		writeError(http.StatusInternalServerError, err.Error())
+		return
+	}
+	if byInterface == nil {
+		byInterface = []statsdb.InterfaceTypeStatsSummary{}
+	}
+	if todayByEndpoint == nil {
+		todayByEndpoint = map[string]*statsdb.EndpointDailyStats{}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"range":           timeRange,
+		"generatedAt":     time.Now().UTC().Format(time.RFC3339),
+		"interfaceTypes":  byInterface,
+		"todayByEndpoint": todayByEndpoint,
+	})
+}
@@ -0,0 +1,298 @@
+package proxy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"clisimplehub/internal/executor"
+)
+
+// StreamCoalesceHeader lets clients opt in to (or out of) stream coalescing for a single request
+const StreamCoalesceHeader = "X-Proxy-Coalesce"
+
+// maxCoalesceBufferBytes caps the buffered stream replayed to late joiners; once exceeded,
+// new identical requests go upstream on their own while existing followers finish the stream
+const maxCoalesceBufferBytes = 8 * 1024 * 1024
+
+// StreamCoalescer shares one upstream call among concurrent identical streaming requests.
+// The first request (leader) runs normally while its response is buffered; identical
+// requests arriving while it is in flight (followers) replay the buffer and then follow
+// the live stream. The upstream call is cancelled only when every client has gone.
+type StreamCoalescer struct {
+	mu       sync.Mutex
+	inflight map[string]*coalescedStream
+}
+
+// NewStreamCoalescer creates an empty stream coalescer
+func NewStreamCoalescer() *StreamCoalescer {
+	return &StreamCoalescer{inflight: make(map[string]*coalescedStream)}
+}
+
+// coalescedStream is the buffered response of one in-flight leader request
+type coalescedStream struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	statusCode int
+	header     http.Header
+	chunks     [][]byte
+	size       int
+	done       bool
+
+	// subscribers counts the clients still reading (leader included); cancel stops the upstream at zero
+	subscribers int
+	ctx         context.Context
+	cancel      context.CancelFunc
+}
+
+// join returns the in-flight stream for key and whether the caller leads it. A leader's
+// upstream context is detached from its own client so a disconnect does not end the stream
+// for followers; the caller must call finish when the response is complete.
+func (c *StreamCoalescer) join(key string, r *http.Request) (*coalescedStream, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if stream := c.inflight[key]; stream != nil {
+		stream.mu.Lock()
+		stream.subscribers++
+		stream.mu.Unlock()
+		return stream, false
+	}
+
+	ctx, cancel := context.WithCancel(context.WithoutCancel(r.Context()))
+	stream := &coalescedStream{subscribers: 1, ctx: ctx, cancel: cancel}
+	stream.cond = sync.NewCond(&stream.mu)
+	c.inflight[key] = stream
+	return stream, true
+}
+
+// finish marks the leader's response complete and stops accepting joiners
+func (c *StreamCoalescer) finish(key string, stream *coalescedStream) {
+	c.mu.Lock()
+	if c.inflight[key] == stream {
+		delete(c.inflight, key)
+	}
+	c.mu.Unlock()
+
+	stream.mu.Lock()
+	stream.done = true
+	stream.cond.Broadcast()
+	stream.mu.Unlock()
+	stream.cancel()
+}
+
+// closeToJoiners stops new requests from joining a stream whose buffer grew too large
+func (c *StreamCoalescer) closeToJoiners(key string, stream *coalescedStream) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.inflight[key] == stream {
+		delete(c.inflight, key)
+	}
+}
+
+// release drops a subscriber whose client went away; the last one cancels the upstream call
+func (s *coalescedStream) release() {
+	s.mu.Lock()
+	s.subscribers--
+	last := s.subscribers == 0 && !s.done
+	s.mu.Unlock()
+	if last {
+		s.cancel()
+	}
+}
+
+// watch releases the subscriber when its client disconnects before the stream is done
+func (s *coalescedStream) watch(clientCtx context.Context) {
+	go func() {
+		select {
+		case <-clientCtx.Done():
+			s.mu.Lock()
+			done := s.done
+			s.mu.Unlock()
+			if !done {
+				s.release()
+			}
+		case <-s.ctx.Done():
+		}
+	}()
+}
+
+// serve replays the leader's response to a follower and follows the live stream until it
+// completes or the follower disconnects. It returns the leader's status code (0 if none).
+func (s *coalescedStream) serve(clientCtx context.Context, w http.ResponseWriter) (int, bool) {
+	s.watch(clientCtx)
+	// Wake the wait loop when the follower disconnects
+	stop := context.AfterFunc(clientCtx, func() {
+		s.mu.Lock()
+		s.cond.Broadcast()
+		s.mu.Unlock()
+	})
+	defer stop()
+
+	flusher, _ := w.(http.Flusher)
+	wroteHeader := false
+	next := 0
+	for {
+		s.mu.Lock()
+		for !s.done && clientCtx.Err() == nil && (s.statusCode == 0 || next == len(s.chunks)) {
+			s.cond.Wait()
+		}
+		statusCode, header, chunks, done := s.statusCode, s.header, s.chunks[next:], s.done
+		s.mu.Unlock()
+
+		if clientCtx.Err() != nil {
+			return statusCode, false
+		}
+		if statusCode == 0 {
+			// The leader ended without writing a response
+			return 0, true
+		}
+		if !wroteHeader {
+			for key, values := range header {
+				for _, v := range values {
+					w.Header().Add(key, v)
+				}
+			}
+			w.Header().Set("X-Coalesced", "HIT")
+			w.WriteHeader(statusCode)
+			wroteHeader = true
+		}
+		for _, chunk := range chunks {
+			if _, err := w.Write(chunk); err != nil {
+				return statusCode, false
+			}
+		}
+		next += len(chunks)
+		if flusher != nil && len(chunks) > 0 {
+			flusher.Flush()
+		}
+		if done {
+			return statusCode, true
+		}
+	}
+}
+
+// coalesceLeaderWriter writes the leader's response to its client and to the shared buffer.
+// Client write errors are swallowed so the upstream keeps streaming for followers after the
+// leader's client disconnects.
+type coalesceLeaderWriter struct {
+	http.ResponseWriter
+	stream    *coalescedStream
+	coalescer *StreamCoalescer
+	key       string
+
+	clientGone  bool
+	wroteHeader bool
+}
+
+func (w *coalesceLeaderWriter) WriteHeader(statusCode int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.ResponseWriter.Header().Set("X-Coalesced", "MISS")
+	header := w.ResponseWriter.Header().Clone()
+	header.Del("X-Coalesced")
+
+	s := w.stream
+	s.mu.Lock()
+	s.statusCode = statusCode
+	s.header = header
+	s.cond.Broadcast()
+	s.mu.Unlock()
+
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *coalesceLeaderWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	s := w.stream
+	s.mu.Lock()
+	s.chunks = append(s.chunks, append([]byte(nil), p...))
+	s.size += len(p)
+	full := s.size > maxCoalesceBufferBytes
+	s.cond.Broadcast()
+	s.mu.Unlock()
+	if full {
+		w.coalescer.closeToJoiners(w.key, s)
+	}
+
+	if !w.clientGone {
+		if _, err := w.ResponseWriter.Write(p); err != nil {
+			w.clientGone = true
+		}
+	}
+	return len(p), nil
+}
+
+func (w *coalesceLeaderWriter) Flush() {
+	if w.clientGone {
+		return
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// SetStreamCoalescing enables or disables coalescing of identical in-flight streaming requests
+func (p *ProxyServer) SetStreamCoalescing(enabled bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !enabled {
+		p.streamCoalescer = nil
+		return
+	}
+	if p.streamCoalescer == nil {
+		p.streamCoalescer = NewStreamCoalescer()
+	}
+}
+
+func (p *ProxyServer) getStreamCoalescer() *StreamCoalescer {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.streamCoalescer
+}
+
+// streamCoalesceKey hashes the endpoint and request like responseCacheKey; Accept-Encoding is
+// included because error responses may be compressed for the leader's client
+func streamCoalesceKey(endpoint *executor.EndpointConfig, r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(responseCacheKey(endpoint, r, body)))
+	h.Write([]byte{0})
+	h.Write([]byte(r.Header.Get("Accept-Encoding")))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// isCoalescableRequest reports whether identical concurrent requests may share one upstream
+// stream: the client opted in via the X-Proxy-Coalesce header, or sent temperature 0.
+func isCoalescableRequest(r *http.Request, body []byte) bool {
+	switch strings.ToLower(strings.TrimSpace(r.Header.Get(StreamCoalesceHeader))) {
+	case "1", "true", "on", "yes":
+		return true
+	case "0", "false", "off", "no":
+		return false
+	}
+	return isZeroTemperature(body)
+}
+
+// coalescedStatus maps a follower's outcome to a request log status
+func coalescedStatus(statusCode int, completed bool) string {
+	switch {
+	case !completed:
+		return statusCancelled
+	case statusCode == http.StatusOK:
+		return "success"
+	case statusCode == 0:
+		return "error"
+	default:
+		return "error_" + strconv.Itoa(statusCode)
+	}
+}
@@ -0,0 +1,227 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStreamCoalescerJoin(t *testing.T) {
+	t.Parallel()
+
+	c := NewStreamCoalescer()
+	r := httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	leader, isLeader := c.join("k", r)
+	if !isLeader {
+		t.Fatalf("first join should lead")
+	}
+	follower, isLeader := c.join("k", r)
+	if isLeader || follower != leader {
+		t.Fatalf("second join should follow the same stream")
+	}
+	if _, isLeader := c.join("other", r); !isLeader {
+		t.Fatalf("a different key should lead its own stream")
+	}
+
+	c.finish("k", leader)
+	if leader.ctx.Err() == nil {
+		t.Fatalf("finish should cancel the upstream context")
+	}
+	if next, isLeader := c.join("k", r); !isLeader || next == leader {
+		t.Fatalf("join after finish should start a new stream")
+	}
+}
+
+func TestCoalescedStreamLateJoinerReplay(t *testing.T) {
+	t.Parallel()
+
+	c := NewStreamCoalescer()
+	r := httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	stream, _ := c.join("k", r)
+	leaderRec := httptest.NewRecorder()
+	leader := &coalesceLeaderWriter{ResponseWriter: leaderRec, stream: stream, coalescer: c, key: "k"}
+	leader.Header().Set("Content-Type", "text/event-stream")
+	leader.WriteHeader(http.StatusOK)
+	_, _ = leader.Write([]byte("data: 1\n\n"))
+	_, _ = leader.Write([]byte("data: 2\n\n"))
+
+	// The follower joins after two chunks were written and must still see them
+	joined, isLeader := c.join("k", r)
+	if isLeader || joined != stream {
+		t.Fatalf("late joiner should follow the in-flight stream")
+	}
+	followerRec := httptest.NewRecorder()
+	result := make(chan int, 1)
+	go func() {
+		statusCode, completed := stream.serve(context.Background(), followerRec)
+		if !completed {
+			statusCode = -1
+		}
+		result <- statusCode
+	}()
+
+	_, _ = leader.Write([]byte("data: 3\n\n"))
+	c.finish("k", stream)
+
+	select {
+	case statusCode := <-result:
+		if statusCode != http.StatusOK {
+			t.Fatalf("follower status=%d want 200", statusCode)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("follower did not finish")
+	}
+	want := "data: 1\n\ndata: 2\n\ndata: 3\n\n"
+	if followerRec.Body.String() != want || leaderRec.Body.String() != want {
+		t.Fatalf("follower=%q leader=%q want %q", followerRec.Body.String(), leaderRec.Body.String(), want)
+	}
+	if followerRec.Header().Get("X-Coalesced") != "HIT" || leaderRec.Header().Get("X-Coalesced") != "MISS" {
+		t.Fatalf("X-Coalesced follower=%q leader=%q", followerRec.Header().Get("X-Coalesced"), leaderRec.Header().Get("X-Coalesced"))
+	}
+	if followerRec.Header().Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("follower did not get the leader's headers: %v", followerRec.Header())
+	}
+}
+
+func TestCoalescedStreamLeaderDisconnect(t *testing.T) {
+	t.Parallel()
+
+	c := NewStreamCoalescer()
+	r := httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	stream, _ := c.join("k", r)
+	leaderCtx, leaderGone := context.WithCancel(context.Background())
+	stream.watch(leaderCtx)
+	c.join("k", r)
+	followerCtx, followerGone := context.WithCancel(context.Background())
+	defer followerGone()
+	stream.watch(followerCtx)
+
+	// The leader's client leaves: the upstream keeps running for the follower
+	leaderGone()
+	time.Sleep(20 * time.Millisecond)
+	if stream.ctx.Err() != nil {
+		t.Fatalf("upstream cancelled while a follower is still reading")
+	}
+
+	// The last client leaves: the upstream call is cancelled
+	followerGone()
+	select {
+	case <-stream.ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("upstream not cancelled after every client left")
+	}
+}
+
+// failingWriter simulates a leader client whose connection broke
+type failingWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (w failingWriter) Write([]byte) (int, error) { return 0, context.Canceled }
+
+func TestCoalesceLeaderWriterHandoff(t *testing.T) {
+	t.Parallel()
+
+	c := NewStreamCoalescer()
+	r := httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	stream, _ := c.join("k", r)
+	leader := &coalesceLeaderWriter{ResponseWriter: failingWriter{httptest.NewRecorder()}, stream: stream, coalescer: c, key: "k"}
+	leader.WriteHeader(http.StatusOK)
+
+	// Writes keep succeeding for the upstream side after the leader's client is gone
+	for _, chunk := range []string{"data: a\n\n", "data: b\n\n"} {
+		if n, err := leader.Write([]byte(chunk)); err != nil || n != len(chunk) {
+			t.Fatalf("leader write n=%d err=%v", n, err)
+		}
+	}
+	if !leader.clientGone {
+		t.Fatalf("leader client failure not recorded")
+	}
+	leader.Flush()
+	c.finish("k", stream)
+
+	rec := httptest.NewRecorder()
+	statusCode, completed := stream.serve(context.Background(), rec)
+	if statusCode != http.StatusOK || !completed || rec.Body.String() != "data: a\n\ndata: b\n\n" {
+		t.Fatalf("follower status=%d completed=%v body=%q", statusCode, completed, rec.Body.String())
+	}
+}
+
+func TestHandleProxyStreamCoalescing(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	release := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("event: message_start\ndata: {\"type\":\"message_start\"}\n\n"))
+		w.(http.Flusher).Flush()
+		<-release
+		_, _ = w.Write([]byte("event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n"))
+	}))
+	defer upstream.Close()
+
+	router := NewRouter()
+	router.LoadEndpoints([]*Endpoint{{ID: 1, Name: "a", APIURL: upstream.URL, InterfaceType: "claude", Enabled: true, Active: true}})
+	p := NewProxyServer(0, router)
+	p.SetStreamCoalescing(true)
+
+	body := `{"model":"m","stream":true,"temperature":0,"messages":[]}`
+	recs := []*httptest.ResponseRecorder{httptest.NewRecorder(), httptest.NewRecorder()}
+	var wg sync.WaitGroup
+	start := func(rec *httptest.ResponseRecorder) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.handleProxy(rec, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(body)))
+		}()
+	}
+
+	start(recs[0])
+	waitFor(t, "leader upstream call", func() bool { return hits.Load() == 1 })
+	start(recs[1])
+	waitFor(t, "follower join", func() bool {
+		c := p.getStreamCoalescer()
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for _, s := range c.inflight {
+			s.mu.Lock()
+			n := s.subscribers
+			s.mu.Unlock()
+			return n == 2
+		}
+		return false
+	})
+	close(release)
+	wg.Wait()
+
+	if hits.Load() != 1 {
+		t.Fatalf("upstream hits=%d want 1", hits.Load())
+	}
+	for i, rec := range recs {
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "message_start") || !strings.Contains(rec.Body.String(), "message_stop") {
+			t.Fatalf("client %d: status=%d body=%q", i, rec.Code, rec.Body.String())
+		}
+	}
+	if recs[0].Header().Get("X-Coalesced") != "MISS" || recs[1].Header().Get("X-Coalesced") != "HIT" {
+		t.Fatalf("X-Coalesced leader=%q follower=%q", recs[0].Header().Get("X-Coalesced"), recs[1].Header().Get("X-Coalesced"))
+	}
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,70 @@
+package proxy
+
+import (
+	"net/http"
+	"time"
+
+	"clisimplehub/internal/executor"
+)
+
+// streamTimingWriter records when the first response byte is written to the client,
+// for time-to-first-token and tokens-per-second metrics of streamed responses
+type streamTimingWriter struct {
+	http.ResponseWriter
+	firstWrite time.Time
+}
+
+func (w *streamTimingWriter) Write(p []byte) (int, error) {
+	if w.firstWrite.IsZero() && len(p) > 0 {
+		w.firstWrite = time.Now()
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *streamTimingWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// streamMetrics holds the latency metrics of one streamed response
+type streamMetrics struct {
+	TTFTMs          int64
+	TokensPerSecond float64
+}
+
+// metrics returns TTFT (request start to first byte) and output tokens per second over the
+// stream duration (first byte to end). Both are zero when nothing was written.
+func (w *streamTimingWriter) metrics(start, end time.Time, outputTokens int64) streamMetrics {
+	if w == nil || w.firstWrite.IsZero() {
+		return streamMetrics{}
+	}
+	m := streamMetrics{TTFTMs: w.firstWrite.Sub(start).Milliseconds()}
+	if m.TTFTMs <= 0 {
+		// keep a measured TTFT distinguishable from "not streamed"
+		m.TTFTMs = 1
+	}
+	if outputTokens > 0 {
+		streamDuration := end.Sub(w.firstWrite)
+		if streamDuration < time.Millisecond {
+			// the whole response arrived in one write; fall back to the total duration
+			streamDuration = end.Sub(start)
+		}
+		if streamDuration > 0 {
+			m.TokensPerSecond = float64(outputTokens) / streamDuration.Seconds()
+		}
+	}
+	return m
+}
+
+// streamMetricsFromResult returns the stream metrics of a streamed result (zero otherwise)
+func streamMetricsFromResult(timing *streamTimingWriter, result *executor.ForwardResult, start, end time.Time) streamMetrics {
+	if timing == nil || result == nil || !result.Streamed {
+		return streamMetrics{}
+	}
+	var outputTokens int64
+	if result.Tokens != nil {
+		outputTokens = result.Tokens.OutputTokens
+	}
+	return timing.metrics(start, end, outputTokens)
+}
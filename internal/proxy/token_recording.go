@@ -13,4 +13,7 @@ func (p *ProxyServer) recordTokens(endpoint *executor.EndpointConfig, result *ex
 		CachedRead:   result.Tokens.CachedRead,
 		Reasoning:    result.Tokens.Reasoning,
 	})
+	if router, ok := p.router.(interface{ recordTokenUsage(int64, int64) }); ok {
+		router.recordTokenUsage(endpoint.ID, result.Tokens.InputTokens+result.Tokens.OutputTokens)
+	}
 }
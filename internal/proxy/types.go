@@ -30,27 +30,55 @@ type Vendor struct {
 
 // Endpoint represents an API endpoint configuration
 type Endpoint struct {
-	ID            int64             `json:"id"`
-	Name          string            `json:"name"`
-	APIURL        string            `json:"api_url"`
-	APIKey        string            `json:"api_key"`
-	Active        bool              `json:"active"`
-	Enabled       bool              `json:"enabled"`
-	InterfaceType string            `json:"interface_type"`
-	Transformer   string            `json:"transformer,omitempty"`
-	VendorID      int64             `json:"vendor_id"`
-	Model         string            `json:"model,omitempty"`
-	Remark        string            `json:"remark,omitempty"`
-	Priority      int               `json:"priority,omitempty"`
-	ProxyURL      string            `json:"proxy_url,omitempty"`
-	Models        []ModelMapping    `json:"models,omitempty"`
-	Headers       map[string]string `json:"headers,omitempty"`
-	CreateTime    time.Time         `json:"create_time"`
-	UpdateTime    time.Time         `json:"update_time"`
+	ID                     int64             `json:"id"`
+	Name                   string            `json:"name"`
+	APIURL                 string            `json:"api_url"`
+	APIKey                 string            `json:"api_key"`
+	Active                 bool              `json:"active"`
+	Enabled                bool              `json:"enabled"`
+	InterfaceType          string            `json:"interface_type"`
+	Transformer            string            `json:"transformer,omitempty"`
+	VendorID               int64             `json:"vendor_id"`
+	Model                  string            `json:"model,omitempty"`
+	Remark                 string            `json:"remark,omitempty"`
+	Priority               int               `json:"priority,omitempty"`
+	ProxyURL               string            `json:"proxy_url,omitempty"`
+	ProxyUsername          string            `json:"proxy_username,omitempty"`
+	ProxyPassword          string            `json:"proxy_password,omitempty"`
+	ForceNonStreamUpstream bool              `json:"force_non_stream_upstream,omitempty"`
+	FallbackToDefaultModel bool              `json:"fallback_to_default_model,omitempty"`
+	Models                 []ModelMapping    `json:"models,omitempty"`
+	Headers                map[string]string `json:"headers,omitempty"`
+	DropParams             []string          `json:"drop_params,omitempty"`
+	BodyOverrides          map[string]any    `json:"body_overrides,omitempty"` // 合并进请求体的固定字段，如 service_tier
+	BodyForce              bool              `json:"body_force,omitempty"`     // true 时覆盖客户端同名字段
+	IncludeReasoning       bool              `json:"include_reasoning,omitempty"`
+	ReasoningSummary       string            `json:"reasoning_summary,omitempty"`
+	InsecureSkipVerify     bool              `json:"insecure_skip_verify,omitempty"`
+	ForwardClientIP        bool              `json:"forward_client_ip,omitempty"`
+	Sandbox                bool              `json:"sandbox,omitempty"` // excluded from automatic routing and fallback; usable when selected explicitly
+	CAFile                 string            `json:"ca_file,omitempty"`
+	AnthropicVersion       string            `json:"anthropic_version,omitempty"`
+	AnthropicBeta          string            `json:"anthropic_beta,omitempty"`
+	DailyTokenBudget       int64             `json:"daily_token_budget,omitempty"`
+	MaxTokensCap           int               `json:"max_tokens_cap,omitempty"`
+	ResponseValidation     []string          `json:"response_validation,omitempty"`
+	Schedule               []ScheduleWindow  `json:"schedule,omitempty"`
+	VendorHeaders          map[string]string `json:"vendor_headers,omitempty"`
+	CreateTime             time.Time         `json:"create_time"`
+	UpdateTime             time.Time         `json:"update_time"`
+}
+
+// ScheduleWindow represents a weekly time window during which an endpoint is routable
+type ScheduleWindow struct {
+	Days      []int  `json:"days,omitempty"` // 0=周日 ... 6=周六，空表示每天
+	StartHHMM string `json:"startHHMM"`      // 开始时间 "HH:MM"（含）
+	EndHHMM   string `json:"endHHMM"`        // 结束时间 "HH:MM"（不含），早于开始时间表示跨午夜
 }
 
 // ModelMapping represents a model name mapping configuration
 type ModelMapping struct {
-	Name  string `json:"name"`  // 实际模型名（上游模型名）
-	Alias string `json:"alias"` // API 使用的别名（客户端传入的 model）
+	Name      string `json:"name"`                // 实际模型名（上游模型名）
+	Alias     string `json:"alias"`               // API 使用的别名（客户端传入的 model）
+	MatchType string `json:"matchType,omitempty"` // 别名匹配方式: exact(默认) | prefix | regex
 }
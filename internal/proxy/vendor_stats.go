@@ -11,7 +11,7 @@ import (
 	"clisimplehub/internal/statsdb"
 )
 
-func (p *ProxyServer) insertVendorStat(ctx context.Context, interfaceType InterfaceType, endpoint *executor.EndpointConfig, path string, targetHeaders map[string]string, durationMs int64, statusCode int, status string, tokens *executor.TokenUsage) {
+func (p *ProxyServer) insertVendorStat(ctx context.Context, interfaceType InterfaceType, endpoint *executor.EndpointConfig, path string, targetHeaders map[string]string, durationMs int64, statusCode int, status string, tokens *executor.TokenUsage, userID, experiment string, metrics streamMetrics) {
 	p.mu.RLock()
 	store := p.store
 	vendorStats := p.vendorStats
@@ -44,12 +44,17 @@ func (p *ProxyServer) insertVendorStat(ctx context.Context, interfaceType Interf
 		EndpointID:    strconv.FormatInt(endpointID, 10),
 		EndpointName:  endpointName,
 		Path:          path,
-		Date:          time.Now().Format("2006-01-02"),
+		Date:          statsdb.Today(),
 		InterfaceType: string(interfaceType),
 		TargetHeaders: statsdb.MustJSON(targetHeaders),
 		DurationMs:    durationMs,
 		StatusCode:    statusCode,
 		Status:        status,
+		UserID:        userID,
+		Experiment:    experiment,
+
+		TTFTMs:          metrics.TTFTMs,
+		TokensPerSecond: metrics.TokensPerSecond,
 	}
 
 	if tokens != nil {
@@ -0,0 +1,64 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+
+	"clisimplehub/internal/executor"
+)
+
+// activationHookRouter is implemented by routers that report endpoint activations
+type activationHookRouter interface {
+	SetActivationHook(hook func(InterfaceType, *Endpoint))
+}
+
+// SetWarmupOnActivate enables a background connection warmup whenever an endpoint becomes
+// active, so the first real request to a cold upstream reuses an established connection
+func (p *ProxyServer) SetWarmupOnActivate(enabled bool) {
+	p.mu.Lock()
+	p.warmupOnActivate = enabled
+	router := p.router
+	p.mu.Unlock()
+
+	if hooked, ok := router.(activationHookRouter); ok {
+		if enabled {
+			hooked.SetActivationHook(p.warmupEndpoint)
+		} else {
+			hooked.SetActivationHook(nil)
+		}
+	}
+}
+
+// IsWarmupOnActivateEnabled returns whether endpoints are warmed up on activation
+func (p *ProxyServer) IsWarmupOnActivateEnabled() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.warmupOnActivate
+}
+
+// warmupEndpoint opens a pooled connection to a newly active endpoint. Failures are only
+// logged; they never change routing state.
+func (p *ProxyServer) warmupEndpoint(interfaceType InterfaceType, ep *Endpoint) {
+	if ep == nil || !p.IsWarmupOnActivateEnabled() {
+		return
+	}
+	err := executor.Warmup(context.Background(), toExecutorEndpointConfig(ep))
+
+	p.mu.RLock()
+	hub := p.wsHub
+	p.mu.RUnlock()
+	if hub == nil {
+		return
+	}
+	if err != nil {
+		hub.BroadcastDebugLog(&DebugLogPayload{
+			Level:   2,
+			Message: fmt.Sprintf("[Warmup] %s-%s failed: %v", interfaceType, endpointNameOrID(ep), err),
+		})
+		return
+	}
+	hub.BroadcastDebugLog(&DebugLogPayload{
+		Level:   1,
+		Message: fmt.Sprintf("[Warmup] %s-%s connection established", interfaceType, endpointNameOrID(ep)),
+	})
+}
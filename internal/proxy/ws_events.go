@@ -1,10 +1,13 @@
 package proxy
 
 import (
+	"context"
+	"log"
 	"strings"
 	"time"
 
 	"clisimplehub/internal/executor"
+	"clisimplehub/internal/statsdb"
 )
 
 func (p *ProxyServer) getVendorNameByID(vendorID int64) string {
@@ -24,8 +27,10 @@ func (p *ProxyServer) getVendorNameByID(vendorID int64) string {
 	return "unknown"
 }
 
+// broadcastFallbackSwitch notifies clients of an endpoint fallback switch and persists it
+// so it can be reviewed after the UI was closed
 func (p *ProxyServer) broadcastFallbackSwitch(fromEndpoint, toEndpoint *executor.EndpointConfig, path string, statusCode int, errorMsg string) {
-	if p == nil || p.wsHub == nil || !p.IsFallbackEnabled() {
+	if p == nil || !p.IsFallbackEnabled() {
 		return
 	}
 
@@ -45,7 +50,41 @@ func (p *ProxyServer) broadcastFallbackSwitch(fromEndpoint, toEndpoint *executor
 		payload.ToEndpoint = toEndpoint.Name
 	}
 
-	p.wsHub.BroadcastFallbackSwitch(payload)
+	go p.recordFallbackEvent(payload)
+	interfaceType := ""
+	if fromEndpoint != nil {
+		interfaceType = fromEndpoint.InterfaceType
+	}
+	p.notifyFallback(payload, interfaceType)
+	if p.wsHub != nil {
+		p.wsHub.BroadcastFallbackSwitch(payload)
+	}
+}
+
+// recordFallbackEvent stores a fallback switch in the stats db when it supports fallback events.
+// It runs in its own goroutine so the db write never delays the retried request.
+func (p *ProxyServer) recordFallbackEvent(payload *FallbackSwitchPayload) {
+	p.mu.RLock()
+	recorder, ok := p.vendorStats.(statsdb.FallbackEventRecorder)
+	p.mu.RUnlock()
+	if !ok || recorder == nil {
+		return
+	}
+
+	insertCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := recorder.InsertFallbackEvent(insertCtx, statsdb.FallbackEvent{
+		FromVendor:   payload.FromVendor,
+		FromEndpoint: payload.FromEndpoint,
+		ToVendor:     payload.ToVendor,
+		ToEndpoint:   payload.ToEndpoint,
+		Path:         payload.Path,
+		StatusCode:   payload.StatusCode,
+		ErrorMessage: payload.ErrorMessage,
+	}); err != nil {
+		log.Printf("Warning: insert fallback event failed: %v", err)
+	}
 }
 
 func (p *ProxyServer) broadcastEndpointTempDisabled(interfaceType string, endpoint *executor.EndpointConfig, disabledUntil time.Time) {
@@ -24,8 +24,14 @@ const (
 	WSMessageTypeFallbackSwitch WSMessageType = "fallback_switch"
 	// WSMessageTypeEndpointTempDisabled indicates an endpoint was temporarily disabled in runtime
 	WSMessageTypeEndpointTempDisabled WSMessageType = "endpoint_temp_disabled"
+	// WSMessageTypeEndpointReenableRequired indicates an endpoint was disabled and must be re-enabled manually
+	WSMessageTypeEndpointReenableRequired WSMessageType = "endpoint_reenable_required"
+	// WSMessageTypeLanguageChanged indicates the UI language setting was changed
+	WSMessageTypeLanguageChanged WSMessageType = "language_changed"
 	// WSMessageTypeDebugLog indicates a debug log message (for UI console)
 	WSMessageTypeDebugLog WSMessageType = "debug_log"
+	// WSMessageTypeSnapshot is sent once to a newly connected client with the current state
+	WSMessageTypeSnapshot WSMessageType = "snapshot"
 )
 
 // WSMessage represents a WebSocket message
@@ -42,6 +48,13 @@ type DebugLogPayload struct {
 	Message   string `json:"message"`
 }
 
+// SnapshotPayload is the initial state sent to a client right after it connects,
+// so a reconnecting dashboard can render without waiting for the next live event
+type SnapshotPayload struct {
+	RecentLogs []*RequestLog `json:"recentLogs"`
+	TokenStats []*TokenStats `json:"tokenStats"`
+}
+
 // WSClient represents a WebSocket client connection
 // Requirements: 7.1, 8.5
 type WSClient struct {
@@ -86,6 +99,7 @@ type WSHub struct {
 	mu         sync.RWMutex
 	running    bool
 	stopCh     chan struct{}
+	snapshot   func() *SnapshotPayload
 }
 
 // NewWSHub creates a new WebSocket hub
@@ -158,6 +172,13 @@ func (h *WSHub) Stop() {
 	}
 }
 
+// SetSnapshotProvider sets the function used to build the snapshot sent to new clients
+func (h *WSHub) SetSnapshotProvider(fn func() *SnapshotPayload) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.snapshot = fn
+}
+
 // Register adds a client to the hub
 func (h *WSHub) Register(client *WSClient) {
 	h.register <- client
@@ -204,6 +225,20 @@ type EndpointTempDisabledPayload struct {
 	DisabledUntil int64  `json:"disabledUntil"` // unix milliseconds
 }
 
+// EndpointReenableRequiredPayload represents the payload for endpoints disabled in manual re-enable mode
+type EndpointReenableRequiredPayload struct {
+	InterfaceType string `json:"interfaceType"`
+	EndpointID    int64  `json:"endpointId"`
+	EndpointName  string `json:"endpointName"`
+	DisabledAt    int64  `json:"disabledAt"` // unix milliseconds
+	Persisted     bool   `json:"persisted"`  // false when saving Enabled=false to config failed
+}
+
+// LanguageChangedPayload represents the payload for language change events
+type LanguageChangedPayload struct {
+	Language string `json:"language"`
+}
+
 // FallbackSwitchPayload represents the payload for fallback switch events
 type FallbackSwitchPayload struct {
 	FromVendor   string `json:"fromVendor"`
@@ -231,6 +266,22 @@ func (h *WSHub) BroadcastEndpointTempDisabled(payload *EndpointTempDisabledPaylo
 	})
 }
 
+// BroadcastEndpointReenableRequired broadcasts that an endpoint needs to be re-enabled manually
+func (h *WSHub) BroadcastEndpointReenableRequired(payload *EndpointReenableRequiredPayload) {
+	h.Broadcast(&WSMessage{
+		Type:    WSMessageTypeEndpointReenableRequired,
+		Payload: payload,
+	})
+}
+
+// BroadcastLanguageChanged broadcasts a language change so every open UI can reload its texts
+func (h *WSHub) BroadcastLanguageChanged(language string) {
+	h.Broadcast(&WSMessage{
+		Type:    WSMessageTypeLanguageChanged,
+		Payload: &LanguageChangedPayload{Language: language},
+	})
+}
+
 // BroadcastDebugLog broadcasts a debug log message for UI console.
 func (h *WSHub) BroadcastDebugLog(payload *DebugLogPayload) {
 	if payload == nil {
@@ -271,6 +322,16 @@ func (h *WSHub) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 		conn: conn,
 	}
 
+	// Queue the snapshot before registering so it is delivered ahead of live events
+	h.mu.RLock()
+	snapshot := h.snapshot
+	h.mu.RUnlock()
+	if snapshot != nil {
+		if payload := snapshot(); payload != nil {
+			client.Send <- &WSMessage{Type: WSMessageTypeSnapshot, Payload: payload}
+		}
+	}
+
 	h.Register(client)
 
 	// Start goroutines for reading and writing
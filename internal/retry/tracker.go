@@ -56,3 +56,22 @@ func (t *Tracker) ExhaustedEndpoints() map[string]bool {
 	}
 	return t.exhaustedEndpoints
 }
+
+// AttemptedEndpoints 返回本次请求已尝试或已耗尽的端点集合，故障转移时应全部跳过
+func (t *Tracker) AttemptedEndpoints() map[string]bool {
+	if t == nil {
+		return nil
+	}
+	out := make(map[string]bool, len(t.triedEndpoints)+len(t.exhaustedEndpoints))
+	for key, n := range t.triedEndpoints {
+		if n > 0 {
+			out[key] = true
+		}
+	}
+	for key, exhausted := range t.exhaustedEndpoints {
+		if exhausted {
+			out[key] = true
+		}
+	}
+	return out
+}
@@ -0,0 +1,181 @@
+// Package runtimeconfig reads the runtime tuning settings from config.json appConfig and
+// applies them to the proxy server, executor and stats store. Both binaries and the desktop
+// app's settings reload go through it, so a setting cannot be read one way in one place and
+// another way elsewhere.
+package runtimeconfig
+
+import (
+	"strconv"
+	"time"
+
+	"clisimplehub/internal/executor"
+	"clisimplehub/internal/logger"
+	"clisimplehub/internal/proxy"
+	"clisimplehub/internal/statsdb"
+)
+
+// Config keys for config.json appConfig
+const (
+	// Opt-in cache for identical non-streaming responses
+	KeyResponseCacheEnabled    = "responseCacheEnabled"
+	KeyResponseCacheTTLSeconds = "responseCacheTTLSeconds"
+	KeyResponseCacheMaxMB      = "responseCacheMaxMB"
+	// Max in-flight requests per interface type (0 = unlimited) and how many may wait for a slot
+	KeyPerTypeConcurrency = "perTypeConcurrency"
+	KeyPerTypeQueueSize   = "perTypeQueueSize"
+	// Max size of a single upstream SSE line (default 64MB); longer lines end the stream with an error event
+	KeyMaxStreamLineBytes = "maxStreamLineBytes"
+	// Size above which a non-streaming response body is spooled to a temp file
+	KeyResponseSpoolThresholdBytes = "responseSpoolThresholdBytes"
+	// Line ending of SSE events emitted by transformers: "lf" (default) or "crlf"
+	KeySSELineEnding = "sseLineEnding"
+	// Upstream connection pool tuning (0 = executor default)
+	KeyHTTPMaxIdleConnsPerHost    = "httpMaxIdleConnsPerHost"
+	KeyHTTPIdleConnTimeoutSeconds = "httpIdleConnTimeoutSeconds"
+	KeyHTTPDisableKeepAlives      = "httpDisableKeepAlives"
+	// Upstream connect timeout (seconds, 0 = executor default), independent of request timeouts
+	KeyConnectTimeoutSeconds = "connectTimeoutSeconds"
+	// Server-wide request deadlines (seconds, 0 = no deadline)
+	KeyRequestDeadlineSeconds       = "requestDeadlineSeconds"
+	KeyStreamRequestDeadlineSeconds = "streamRequestDeadlineSeconds"
+	// Cancel and fail over a streaming request when the upstream sends nothing within this window (seconds, 0 = off)
+	KeyStreamFirstByteTimeoutSeconds = "streamFirstByteTimeoutSeconds"
+	// IANA time zone used to bucket stats by date (default: server local time)
+	KeyStatsTimezone = "statsTimezone"
+)
+
+// Store reads appConfig values as strings ("" when unset); storage.Storage implements it
+type Store interface {
+	GetConfig(key string) (string, error)
+}
+
+// Apply pushes every runtime tuning setting in store into p (skipped when nil) and into the
+// executor and stats globals
+func Apply(store Store, p *proxy.ProxyServer) {
+	if p != nil {
+		p.SetResponseCacheLimits(LoadResponseCacheLimits(store))
+		p.SetFairQueueLimits(LoadFairQueueLimits(store))
+		p.SetRequestDeadlines(LoadRequestDeadlines(store))
+	}
+	executor.SetMaxStreamLineBytes(LoadMaxStreamLineBytes(store))
+	executor.SetResponseSpoolThresholdBytes(LoadResponseSpoolThresholdBytes(store))
+	ApplySSELineEnding(store)
+	executor.SetStreamFirstByteTimeout(LoadStreamFirstByteTimeout(store))
+	executor.SetHTTPPoolConfig(LoadHTTPPoolConfig(store))
+	statsdb.SetStatsLocation(LoadStatsLocation(store))
+}
+
+// positiveInt returns the value of key as a positive integer, or 0 when unset or invalid
+func positiveInt(store Store, key string) int {
+	v, err := store.GetConfig(key)
+	if err != nil || v == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return 0
+	}
+	return n
+}
+
+// LoadResponseCacheLimits reads the response cache settings from config.json appConfig.
+// The cache is disabled unless responseCacheEnabled is true.
+func LoadResponseCacheLimits(store Store) (enabled bool, ttl time.Duration, maxBytes int) {
+	if v, err := store.GetConfig(KeyResponseCacheEnabled); err != nil || v != "true" {
+		return false, 0, 0
+	}
+	ttl = proxy.DefaultResponseCacheTTL
+	if seconds := positiveInt(store, KeyResponseCacheTTLSeconds); seconds > 0 {
+		ttl = time.Duration(seconds) * time.Second
+	}
+	maxBytes = proxy.DefaultResponseCacheMaxBytes
+	if mb := positiveInt(store, KeyResponseCacheMaxMB); mb > 0 {
+		maxBytes = mb * 1024 * 1024
+	}
+	return true, ttl, maxBytes
+}
+
+// LoadFairQueueLimits reads the per interface type concurrency and queue size from
+// config.json appConfig. perType is 0 (no limit) unless perTypeConcurrency is a positive number.
+func LoadFairQueueLimits(store Store) (perType, queueSize int) {
+	perType = positiveInt(store, KeyPerTypeConcurrency)
+	queueSize = proxy.DefaultPerTypeQueueSize
+	if n := positiveInt(store, KeyPerTypeQueueSize); n > 0 {
+		queueSize = n
+	}
+	return perType, queueSize
+}
+
+// ApplySSELineEnding applies sseLineEnding from config.json appConfig; invalid values keep "lf"
+func ApplySSELineEnding(store Store) {
+	v, _ := store.GetConfig(KeySSELineEnding)
+	if err := executor.SetSSELineEnding(v); err != nil {
+		logger.Warn("[RuntimeConfig] %v", err)
+		_ = executor.SetSSELineEnding("")
+	}
+}
+
+// LoadMaxStreamLineBytes reads maxStreamLineBytes from config.json appConfig.
+// Returns 0 (executor default) when unset or invalid.
+func LoadMaxStreamLineBytes(store Store) int {
+	return loadByteSize(store, KeyMaxStreamLineBytes)
+}
+
+// LoadResponseSpoolThresholdBytes reads responseSpoolThresholdBytes from config.json appConfig.
+// Returns 0 (executor default) when unset or invalid.
+func LoadResponseSpoolThresholdBytes(store Store) int {
+	return loadByteSize(store, KeyResponseSpoolThresholdBytes)
+}
+
+func loadByteSize(store Store, key string) int {
+	v, err := store.GetConfig(key)
+	if err != nil || v == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		logger.Warn("[RuntimeConfig] Invalid %s value %q, using default", key, v)
+		return 0
+	}
+	return n
+}
+
+// LoadRequestDeadlines reads the non-streaming and streaming request deadlines
+// from config.json appConfig. Missing or non-positive values disable the deadline.
+func LoadRequestDeadlines(store Store) (time.Duration, time.Duration) {
+	return time.Duration(positiveInt(store, KeyRequestDeadlineSeconds)) * time.Second,
+		time.Duration(positiveInt(store, KeyStreamRequestDeadlineSeconds)) * time.Second
+}
+
+// LoadStreamFirstByteTimeout reads streamFirstByteTimeoutSeconds from config.json appConfig.
+// Missing or non-positive values disable the first-byte timeout.
+func LoadStreamFirstByteTimeout(store Store) time.Duration {
+	return time.Duration(positiveInt(store, KeyStreamFirstByteTimeoutSeconds)) * time.Second
+}
+
+// LoadHTTPPoolConfig reads the upstream connection pool settings from config.json appConfig.
+// Missing or non-positive values keep the executor defaults.
+func LoadHTTPPoolConfig(store Store) executor.HTTPPoolConfig {
+	keepAlives, _ := store.GetConfig(KeyHTTPDisableKeepAlives)
+	return executor.HTTPPoolConfig{
+		MaxIdleConnsPerHost: positiveInt(store, KeyHTTPMaxIdleConnsPerHost),
+		IdleConnTimeout:     time.Duration(positiveInt(store, KeyHTTPIdleConnTimeoutSeconds)) * time.Second,
+		DisableKeepAlives:   keepAlives == "true",
+		ConnectTimeout:      time.Duration(positiveInt(store, KeyConnectTimeoutSeconds)) * time.Second,
+	}
+}
+
+// LoadStatsLocation reads statsTimezone from config.json appConfig.
+// Falls back to local time when unset or not a valid IANA zone name.
+func LoadStatsLocation(store Store) *time.Location {
+	v, err := store.GetConfig(KeyStatsTimezone)
+	if err != nil {
+		return time.Local
+	}
+	loc, err := statsdb.LoadStatsLocation(v)
+	if err != nil {
+		logger.Warn("[RuntimeConfig] %v, using local time", err)
+		return time.Local
+	}
+	return loc
+}
@@ -0,0 +1,62 @@
+package runtimeconfig
+
+import (
+	"testing"
+	"time"
+)
+
+type mapStore map[string]string
+
+func (m mapStore) GetConfig(key string) (string, error) { return m[key], nil }
+
+func TestLoaders(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		store     mapStore
+		wantQueue bool
+		wantCache bool
+		wantLine  int
+		wantDead  time.Duration
+	}{
+		{name: "empty", store: mapStore{}},
+		{
+			name: "set",
+			store: mapStore{
+				KeyPerTypeConcurrency:     "2",
+				KeyResponseCacheEnabled:   "true",
+				KeyMaxStreamLineBytes:     "4096",
+				KeyRequestDeadlineSeconds: "30",
+			},
+			wantQueue: true,
+			wantCache: true,
+			wantLine:  4096,
+			wantDead:  30 * time.Second,
+		},
+		{
+			name: "invalid values fall back",
+			store: mapStore{
+				KeyPerTypeConcurrency:     "-1",
+				KeyResponseCacheEnabled:   "yes",
+				KeyMaxStreamLineBytes:     "big",
+				KeyRequestDeadlineSeconds: "0",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		if perType, _ := LoadFairQueueLimits(tc.store); (perType > 0) != tc.wantQueue {
+			t.Fatalf("%s: fair queue = %v, want %v", tc.name, perType > 0, tc.wantQueue)
+		}
+		if got, _, _ := LoadResponseCacheLimits(tc.store); got != tc.wantCache {
+			t.Fatalf("%s: response cache = %v, want %v", tc.name, got, tc.wantCache)
+		}
+		if got := LoadMaxStreamLineBytes(tc.store); got != tc.wantLine {
+			t.Fatalf("%s: max stream line = %d, want %d", tc.name, got, tc.wantLine)
+		}
+		if got, _ := LoadRequestDeadlines(tc.store); got != tc.wantDead {
+			t.Fatalf("%s: request deadline = %v, want %v", tc.name, got, tc.wantDead)
+		}
+	}
+}
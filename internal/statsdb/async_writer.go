@@ -0,0 +1,266 @@
+package statsdb
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Async writer settings
+const (
+	defaultStatQueueSize = 4096
+	statBatchSize        = 128
+	statFlushInterval    = time.Second
+
+	// statDegradeAfterFailures consecutive failed batches disable stats writes
+	statDegradeAfterFailures = 3
+	// statRetryInterval is how often a degraded writer retries the last failed batch
+	statRetryInterval = 30 * time.Second
+)
+
+// statWriter drains queued stats in the background and inserts them in batches
+type statWriter struct {
+	mu      sync.RWMutex
+	closed  bool
+	queue   chan VendorStat
+	flushCh chan chan struct{}
+	done    chan struct{}
+	dropped atomic.Int64
+
+	// Degraded mode: after repeated insert failures (e.g. disk full) new stats are
+	// discarded instead of queued, and the last failed batch is retried periodically.
+	degraded      atomic.Bool
+	skipped       atomic.Int64 // stats discarded while degraded
+	retryInterval time.Duration
+
+	// owned by the writer goroutine
+	failures  int
+	probe     []VendorStat
+	nextRetry time.Time
+
+	stateMu       sync.Mutex
+	degradedSince time.Time
+	lastErr       string
+}
+
+// WriteStatus describes the health of stats persistence
+type WriteStatus struct {
+	Degraded      bool
+	DegradedSince time.Time // zero when not degraded
+	LastError     string
+	Skipped       int64 // stats discarded while degraded since startup
+}
+
+func (s *SQLiteVendorStatsStore) startWriter(queueSize int) {
+	w := &statWriter{
+		queue:         make(chan VendorStat, queueSize),
+		flushCh:       make(chan chan struct{}),
+		done:          make(chan struct{}),
+		retryInterval: statRetryInterval,
+	}
+	s.writer = w
+	go s.runWriter(w)
+}
+
+// InsertVendorStat enqueues a stat for asynchronous insertion. It never blocks:
+// when the queue is full the stat is dropped and counted.
+func (s *SQLiteVendorStatsStore) InsertVendorStat(ctx context.Context, stat VendorStat) error {
+	if s == nil || s.db == nil {
+		return nil
+	}
+
+	w := s.writer
+	if w == nil {
+		return s.insertVendorStats(ctx, []VendorStat{stat})
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return nil
+	}
+	if w.degraded.Load() {
+		w.skipped.Add(1)
+		return nil
+	}
+	select {
+	case w.queue <- stat:
+	default:
+		if n := w.dropped.Add(1); n&(n-1) == 0 {
+			log.Printf("Warning: vendor stats queue full, dropped %d stats so far", n)
+		}
+	}
+	return nil
+}
+
+// DroppedStats returns how many stats were dropped because the queue was full
+func (s *SQLiteVendorStatsStore) DroppedStats() int64 {
+	if s == nil || s.writer == nil {
+		return 0
+	}
+	return s.writer.dropped.Load()
+}
+
+// WriteStatus reports whether stats writes are currently disabled after repeated failures
+func (s *SQLiteVendorStatsStore) WriteStatus() WriteStatus {
+	if s == nil || s.writer == nil {
+		return WriteStatus{}
+	}
+	w := s.writer
+	w.stateMu.Lock()
+	defer w.stateMu.Unlock()
+	status := WriteStatus{
+		Degraded:  w.degraded.Load(),
+		LastError: w.lastErr,
+		Skipped:   w.skipped.Load(),
+	}
+	if status.Degraded {
+		status.DegradedSince = w.degradedSince
+	}
+	return status
+}
+
+// Flush blocks until all stats queued before the call are written
+func (s *SQLiteVendorStatsStore) Flush() {
+	if s == nil || s.writer == nil {
+		return
+	}
+	w := s.writer
+	w.mu.RLock()
+	if w.closed {
+		w.mu.RUnlock()
+		return
+	}
+	ack := make(chan struct{})
+	w.flushCh <- ack
+	w.mu.RUnlock()
+	<-ack
+}
+
+func (s *SQLiteVendorStatsStore) runWriter(w *statWriter) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(statFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]VendorStat, 0, statBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		s.writeBatch(w, batch)
+		batch = batch[:0]
+	}
+	drain := func() {
+		for {
+			select {
+			case stat, ok := <-w.queue:
+				if !ok {
+					flush()
+					return
+				}
+				batch = append(batch, stat)
+				if len(batch) >= statBatchSize {
+					flush()
+				}
+			default:
+				flush()
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case stat, ok := <-w.queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, stat)
+			if len(batch) >= statBatchSize {
+				flush()
+			}
+		case ack := <-w.flushCh:
+			drain()
+			s.retryDegraded(w)
+			close(ack)
+		case <-ticker.C:
+			flush()
+			s.retryDegraded(w)
+		}
+	}
+}
+
+// writeBatch inserts a batch and switches to degraded mode after repeated failures
+func (s *SQLiteVendorStatsStore) writeBatch(w *statWriter, batch []VendorStat) {
+	if w.degraded.Load() {
+		// queued before writes were disabled
+		w.skipped.Add(int64(len(batch)))
+		return
+	}
+	err := s.insertVendorStats(context.Background(), batch)
+	if err == nil {
+		w.failures = 0
+		return
+	}
+
+	w.failures++
+	w.setLastError(err)
+	if w.failures < statDegradeAfterFailures {
+		log.Printf("Warning: Failed to write %d vendor stats: %v", len(batch), err)
+		return
+	}
+
+	// keep the failed batch as the retry probe so it is not lost if the store recovers
+	w.probe = append(w.probe[:0], batch...)
+	w.nextRetry = time.Now().Add(w.retryInterval)
+	w.stateMu.Lock()
+	w.degradedSince = time.Now()
+	w.stateMu.Unlock()
+	w.degraded.Store(true)
+	log.Printf("Warning: vendor stats writes disabled after %d consecutive failures, retrying every %s: %v", w.failures, w.retryInterval, err)
+}
+
+// retryDegraded retries the last failed batch once the retry interval has passed and
+// re-enables stats writes on success. database/sql reopens broken connections itself.
+func (s *SQLiteVendorStatsStore) retryDegraded(w *statWriter) {
+	if !w.degraded.Load() || time.Now().Before(w.nextRetry) {
+		return
+	}
+	if err := s.insertVendorStats(context.Background(), w.probe); err != nil {
+		w.setLastError(err)
+		w.nextRetry = time.Now().Add(w.retryInterval)
+		return
+	}
+
+	w.failures = 0
+	w.probe = nil
+	w.degraded.Store(false)
+	log.Printf("Vendor stats writes re-enabled, %d stats were skipped while disabled", w.skipped.Load())
+}
+
+func (w *statWriter) setLastError(err error) {
+	w.stateMu.Lock()
+	w.lastErr = err.Error()
+	w.stateMu.Unlock()
+}
+
+// stopWriter stops accepting stats, flushes what is queued and waits for the writer to exit
+func (s *SQLiteVendorStatsStore) stopWriter() {
+	w := s.writer
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return
+	}
+	w.closed = true
+	close(w.queue)
+	w.mu.Unlock()
+	<-w.done
+}
@@ -0,0 +1,81 @@
+package statsdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// dailyRollupTrigger keeps vendor_stats_daily in sync with inserted vendor_stats rows.
+// Its presence also marks the rollup as backfilled.
+const dailyRollupTrigger = "trg_vendor_stats_daily_insert"
+
+const createDailyRollupTriggerSQL = `
+CREATE TRIGGER ` + dailyRollupTrigger + ` AFTER INSERT ON vendor_stats
+BEGIN
+	INSERT INTO vendor_stats_daily(
+		date, interface_type, vendor_id, vendor_name, endpoint_id, endpoint_name,
+		input_tokens, output_tokens, cached_create, cached_read, reasoning, request_count
+	) VALUES (
+		NEW.date, NEW.interface_type, NEW.vendor_id, NEW.vendor_name, NEW.endpoint_id, NEW.endpoint_name,
+		COALESCE(NEW.input_tokens, 0), COALESCE(NEW.output_tokens, 0), COALESCE(NEW.cached_create, 0),
+		COALESCE(NEW.cached_read, 0), COALESCE(NEW.reasoning, 0), 1
+	)
+	ON CONFLICT(date, interface_type, vendor_id, vendor_name, endpoint_id, endpoint_name) DO UPDATE SET
+		input_tokens = input_tokens + excluded.input_tokens,
+		output_tokens = output_tokens + excluded.output_tokens,
+		cached_create = cached_create + excluded.cached_create,
+		cached_read = cached_read + excluded.cached_read,
+		reasoning = reasoning + excluded.reasoning,
+		request_count = request_count + 1;
+END`
+
+const backfillDailyRollupSQL = `
+INSERT INTO vendor_stats_daily(
+	date, interface_type, vendor_id, vendor_name, endpoint_id, endpoint_name,
+	input_tokens, output_tokens, cached_create, cached_read, reasoning, request_count
+)
+SELECT
+	date, interface_type, vendor_id, vendor_name, endpoint_id, endpoint_name,
+	COALESCE(SUM(input_tokens), 0), COALESCE(SUM(output_tokens), 0), COALESCE(SUM(cached_create), 0),
+	COALESCE(SUM(cached_read), 0), COALESCE(SUM(reasoning), 0), COUNT(*)
+FROM vendor_stats
+GROUP BY date, interface_type, vendor_id, vendor_name, endpoint_id, endpoint_name`
+
+// ensureDailyRollup backfills vendor_stats_daily from existing rows and installs the insert
+// trigger, in one transaction so rows are never counted twice. It runs once per database.
+func (s *SQLiteVendorStatsStore) ensureDailyRollup(ctx context.Context) error {
+	var name string
+	err := s.db.QueryRowContext(ctx, "SELECT name FROM sqlite_master WHERE type = 'trigger' AND name = ?", dailyRollupTrigger).Scan(&name)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("check daily rollup trigger: %w", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	for _, stmt := range []string{"DELETE FROM vendor_stats_daily", backfillDailyRollupSQL, createDailyRollupTriggerSQL} {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("build daily rollup: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit daily rollup: %w", err)
+	}
+	return nil
+}
+
+// statsSource returns the table and request count expression for aggregate queries:
+// today reads live rows, longer ranges read the daily rollup
+func statsSource(timeRange TimeRange) (table, requestCount string) {
+	if timeRange == TimeRangeToday {
+		return "vendor_stats", "COUNT(*)"
+	}
+	return "vendor_stats_daily", "COALESCE(SUM(request_count), 0)"
+}
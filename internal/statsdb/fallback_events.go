@@ -0,0 +1,107 @@
+package statsdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// maxFallbackErrorLen truncates stored upstream error messages
+	maxFallbackErrorLen = 1000
+	// maxFallbackEvents caps the number of events returned by a query
+	maxFallbackEvents = 500
+	// fallbackEventRetentionDays is how long fallback events are kept; older ones are
+	// pruned when new events are recorded
+	fallbackEventRetentionDays = 90
+)
+
+// FallbackEvent records a switch from a failing endpoint to the next one
+type FallbackEvent struct {
+	ID           int64     `json:"id"`
+	Date         string    `json:"date"`
+	FromVendor   string    `json:"fromVendor"`
+	FromEndpoint string    `json:"fromEndpoint"`
+	ToVendor     string    `json:"toVendor"`
+	ToEndpoint   string    `json:"toEndpoint"`
+	Path         string    `json:"path"`
+	StatusCode   int       `json:"statusCode"`
+	ErrorMessage string    `json:"errorMessage"`
+	CreateTime   time.Time `json:"createTime"`
+}
+
+// FallbackEventRecorder is implemented by stats stores that persist fallback switches
+type FallbackEventRecorder interface {
+	InsertFallbackEvent(ctx context.Context, event FallbackEvent) error
+}
+
+// InsertFallbackEvent stores a fallback switch and prunes events past the retention window.
+// Date defaults to today in the stats time zone. Events are rare, so they are written
+// directly instead of through the async stats queue.
+func (s *SQLiteVendorStatsStore) InsertFallbackEvent(ctx context.Context, event FallbackEvent) error {
+	if s == nil || s.db == nil {
+		return nil
+	}
+	if event.Date == "" {
+		event.Date = Today()
+	}
+	if len(event.ErrorMessage) > maxFallbackErrorLen {
+		event.ErrorMessage = strings.ToValidUTF8(event.ErrorMessage[:maxFallbackErrorLen], "")
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO fallback_events(
+			date, from_vendor, from_endpoint, to_vendor, to_endpoint, path, status_code, error_message
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, event.Date, event.FromVendor, event.FromEndpoint, event.ToVendor, event.ToEndpoint, event.Path, event.StatusCode, event.ErrorMessage)
+	if err != nil {
+		return fmt.Errorf("insert fallback event: %w", err)
+	}
+
+	cutoff := StatsNow().AddDate(0, 0, -fallbackEventRetentionDays).Format(dateLayout)
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM fallback_events WHERE date < ?", cutoff); err != nil {
+		return fmt.Errorf("prune fallback events: %w", err)
+	}
+	return nil
+}
+
+// GetFallbackEvents returns the most recent fallback switches in the given time range, newest first
+func (s *SQLiteVendorStatsStore) GetFallbackEvents(ctx context.Context, timeRange TimeRange) ([]FallbackEvent, error) {
+	if s == nil || s.db == nil {
+		return nil, errors.New("nil sqlite store")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, date, from_vendor, from_endpoint, to_vendor, to_endpoint, path, status_code, error_message, create_time
+		FROM fallback_events
+		WHERE %s
+		ORDER BY id DESC
+		LIMIT %d
+	`, buildDateCondition(timeRange), maxFallbackEvents)
+
+	rows, err := s.reader().QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query fallback events: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]FallbackEvent, 0)
+	for rows.Next() {
+		var event FallbackEvent
+		var createTime sql.NullString
+		if err := rows.Scan(&event.ID, &event.Date, &event.FromVendor, &event.FromEndpoint, &event.ToVendor, &event.ToEndpoint, &event.Path, &event.StatusCode, &event.ErrorMessage, &createTime); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+		if createTime.Valid {
+			event.CreateTime, _ = parseSQLiteTime(createTime.String)
+		}
+		result = append(result, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query fallback events: %w", err)
+	}
+	return result, nil
+}
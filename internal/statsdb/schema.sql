@@ -17,8 +17,46 @@ CREATE TABLE IF NOT EXISTS vendor_stats (
     cached_create INTEGER DEFAULT 0,
     cached_read INTEGER DEFAULT 0,
     reasoning INTEGER DEFAULT 0,
+    user_id TEXT NOT NULL DEFAULT '',
+    experiment TEXT NOT NULL DEFAULT '',
+    ttft_ms INTEGER NOT NULL DEFAULT 0,
+    tokens_per_second REAL NOT NULL DEFAULT 0,
     create_time DATETIME DEFAULT CURRENT_TIMESTAMP
 );
 CREATE INDEX IF NOT EXISTS idx_vendor_stats_date ON vendor_stats(date);
 CREATE INDEX IF NOT EXISTS idx_vendor_stats_vendor ON vendor_stats(vendor_id, endpoint_id);
+-- Serves the per-endpoint MAX(create_time) lookup from the index instead of the table
+CREATE INDEX IF NOT EXISTS idx_vendor_stats_endpoint_time ON vendor_stats(endpoint_id, create_time);
 
+
+-- Per-endpoint daily aggregates of vendor_stats, kept in sync by a trigger
+CREATE TABLE IF NOT EXISTS vendor_stats_daily (
+    date TEXT NOT NULL,
+    interface_type TEXT NOT NULL,
+    vendor_id TEXT NOT NULL,
+    vendor_name TEXT NOT NULL,
+    endpoint_id TEXT NOT NULL,
+    endpoint_name TEXT NOT NULL,
+    input_tokens INTEGER NOT NULL DEFAULT 0,
+    output_tokens INTEGER NOT NULL DEFAULT 0,
+    cached_create INTEGER NOT NULL DEFAULT 0,
+    cached_read INTEGER NOT NULL DEFAULT 0,
+    reasoning INTEGER NOT NULL DEFAULT 0,
+    request_count INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (date, interface_type, vendor_id, vendor_name, endpoint_id, endpoint_name)
+);
+
+-- Endpoint fallback switches, kept for diagnosing flaky providers after the fact
+CREATE TABLE IF NOT EXISTS fallback_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    date TEXT NOT NULL,
+    from_vendor TEXT NOT NULL,
+    from_endpoint TEXT NOT NULL,
+    to_vendor TEXT NOT NULL,
+    to_endpoint TEXT NOT NULL,
+    path TEXT NOT NULL,
+    status_code INTEGER NOT NULL DEFAULT 0,
+    error_message TEXT NOT NULL DEFAULT '',
+    create_time DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_fallback_events_date ON fallback_events(date);
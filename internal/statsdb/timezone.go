@@ -0,0 +1,50 @@
+package statsdb
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// dateLayout is the format of the date column used for bucketing stats
+const dateLayout = "2006-01-02"
+
+var statsLocation atomic.Pointer[time.Location]
+
+// LoadStatsLocation resolves an IANA time zone name for stats bucketing.
+// An empty name or "Local" selects the server's local time zone.
+func LoadStatsLocation(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.EqualFold(name, "local") {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stats timezone %q: %w", name, err)
+	}
+	return loc, nil
+}
+
+// SetStatsLocation sets the time zone used to compute stats dates (nil restores local time)
+func SetStatsLocation(loc *time.Location) {
+	statsLocation.Store(loc)
+}
+
+// StatsLocation returns the time zone used to compute stats dates
+func StatsLocation() *time.Location {
+	if loc := statsLocation.Load(); loc != nil {
+		return loc
+	}
+	return time.Local
+}
+
+// StatsNow returns the current time in the stats time zone
+func StatsNow() time.Time {
+	return time.Now().In(StatsLocation())
+}
+
+// Today returns today's date bucket in the stats time zone
+func Today() string {
+	return StatsNow().Format(dateLayout)
+}
@@ -7,8 +7,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -30,6 +32,12 @@ type VendorStat struct {
 	DurationMs    int64
 	StatusCode    int
 	Status        string
+	UserID        string // requesting user (metadata.user_id / user), empty when unknown
+	Experiment    string // A/B experiment name, "control" or empty when not part of an experiment
+	// Streaming latency: time to first byte written to the client and output tokens per second
+	// after it. Both are 0 for non-streamed responses.
+	TTFTMs          int64
+	TokensPerSecond float64
 
 	InputTokens  int64
 	OutputTokens int64
@@ -43,8 +51,18 @@ type VendorStatsStore interface {
 	Close() error
 }
 
+// SQLite connection settings. The database runs in WAL mode so readers do not
+// block the writer; writes go through a single connection to stay serialized.
+const (
+	sqliteBusyTimeoutMs = 5000
+	sqliteMaxReadConns  = 4
+)
+
 type SQLiteVendorStatsStore struct {
-	db *sql.DB
+	db     *sql.DB // single connection, serializes writes
+	readDB *sql.DB // small pool for concurrent reads
+
+	writer *statWriter
 }
 
 func OpenSQLiteVendorStatsStore(path string) (*SQLiteVendorStatsStore, error) {
@@ -57,7 +75,7 @@ func OpenSQLiteVendorStatsStore(path string) (*SQLiteVendorStatsStore, error) {
 		}
 	}
 
-	db, err := sql.Open("sqlite", path)
+	db, err := sql.Open("sqlite", sqliteDSN(path, false))
 	if err != nil {
 		return nil, fmt.Errorf("open sqlite: %w", err)
 	}
@@ -70,9 +88,43 @@ func OpenSQLiteVendorStatsStore(path string) (*SQLiteVendorStatsStore, error) {
 		_ = db.Close()
 		return nil, err
 	}
+
+	// The read pool is opened after the schema exists and WAL mode is set.
+	readDB, err := sql.Open("sqlite", sqliteDSN(path, true))
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("open sqlite read pool: %w", err)
+	}
+	readDB.SetMaxOpenConns(sqliteMaxReadConns)
+	readDB.SetMaxIdleConns(sqliteMaxReadConns)
+	readDB.SetConnMaxLifetime(0)
+	store.readDB = readDB
+
+	store.startWriter(defaultStatQueueSize)
 	return store, nil
 }
 
+// sqliteDSN builds a modernc.org/sqlite DSN with per-connection pragmas
+func sqliteDSN(path string, readOnly bool) string {
+	pragmas := []string{
+		"_pragma=journal_mode(WAL)",
+		fmt.Sprintf("_pragma=busy_timeout(%d)", sqliteBusyTimeoutMs),
+		"_pragma=synchronous(NORMAL)",
+	}
+	if readOnly {
+		pragmas = append(pragmas, "_pragma=query_only(1)")
+	}
+	return path + "?" + strings.Join(pragmas, "&")
+}
+
+// reader returns the connection pool used for queries
+func (s *SQLiteVendorStatsStore) reader() *sql.DB {
+	if s.readDB != nil {
+		return s.readDB
+	}
+	return s.db
+}
+
 func (s *SQLiteVendorStatsStore) initSchema(ctx context.Context) error {
 	if s == nil || s.db == nil {
 		return errors.New("nil sqlite store")
@@ -80,6 +132,60 @@ func (s *SQLiteVendorStatsStore) initSchema(ctx context.Context) error {
 	if _, err := s.db.ExecContext(ctx, schemaSQL); err != nil {
 		return fmt.Errorf("apply schema: %w", err)
 	}
+	if err := s.migrateSchema(ctx); err != nil {
+		return err
+	}
+	return s.ensureDailyRollup(ctx)
+}
+
+// columnMigrations are columns added after the initial schema, applied to existing databases
+var columnMigrations = []struct {
+	column     string
+	definition string
+}{
+	{column: "user_id", definition: "TEXT NOT NULL DEFAULT ''"},
+	{column: "experiment", definition: "TEXT NOT NULL DEFAULT ''"},
+	{column: "ttft_ms", definition: "INTEGER NOT NULL DEFAULT 0"},
+	{column: "tokens_per_second", definition: "REAL NOT NULL DEFAULT 0"},
+}
+
+// migrateSchema adds columns missing from databases created by older versions
+func (s *SQLiteVendorStatsStore) migrateSchema(ctx context.Context) error {
+	rows, err := s.db.QueryContext(ctx, "PRAGMA table_info(vendor_stats)")
+	if err != nil {
+		return fmt.Errorf("read vendor_stats columns: %w", err)
+	}
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var (
+			cid        int
+			name, typ  string
+			notNull    int
+			defaultVal sql.NullString
+			pk         int
+		)
+		if err := rows.Scan(&cid, &name, &typ, &notNull, &defaultVal, &pk); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("scan vendor_stats column: %w", err)
+		}
+		existing[name] = true
+	}
+	if err := rows.Close(); err != nil {
+		return fmt.Errorf("read vendor_stats columns: %w", err)
+	}
+
+	for _, m := range columnMigrations {
+		if existing[m.column] {
+			continue
+		}
+		if _, err := s.db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE vendor_stats ADD COLUMN %s %s", m.column, m.definition)); err != nil {
+			return fmt.Errorf("add column %s: %w", m.column, err)
+		}
+	}
+
+	if _, err := s.db.ExecContext(ctx, "CREATE INDEX IF NOT EXISTS idx_vendor_stats_user ON vendor_stats(user_id, date)"); err != nil {
+		return fmt.Errorf("create user index: %w", err)
+	}
 	return nil
 }
 
@@ -87,41 +193,70 @@ func (s *SQLiteVendorStatsStore) Close() error {
 	if s == nil || s.db == nil {
 		return nil
 	}
+	s.stopWriter()
+	if s.readDB != nil {
+		_ = s.readDB.Close()
+	}
 	return s.db.Close()
 }
 
-func (s *SQLiteVendorStatsStore) InsertVendorStat(ctx context.Context, stat VendorStat) error {
-	if s == nil || s.db == nil {
-		return nil
-	}
-
-	normalized := normalizeVendorStat(stat)
-	_, err := s.db.ExecContext(ctx, `
+const insertVendorStatSQL = `
 INSERT INTO vendor_stats(
   vendor_id, vendor_name, endpoint_id, endpoint_name,
   path, date, interface_type, target_headers,
   duration_ms, status_code, status,
-  input_tokens, output_tokens, cached_create, cached_read, reasoning
-) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		normalized.VendorID,
-		normalized.VendorName,
-		normalized.EndpointID,
-		normalized.EndpointName,
-		normalized.Path,
-		normalized.Date,
-		normalized.InterfaceType,
-		normalized.TargetHeaders,
-		normalized.DurationMs,
-		normalized.StatusCode,
-		normalized.Status,
-		normalized.InputTokens,
-		normalized.OutputTokens,
-		normalized.CachedCreate,
-		normalized.CachedRead,
-		normalized.Reasoning,
-	)
+  input_tokens, output_tokens, cached_create, cached_read, reasoning,
+  user_id, experiment, ttft_ms, tokens_per_second
+) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+// insertVendorStats writes a batch of stats in a single transaction
+func (s *SQLiteVendorStatsStore) insertVendorStats(ctx context.Context, stats []VendorStat) error {
+	if len(stats) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("insert vendor_stats: %w", err)
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	stmt, err := tx.PrepareContext(ctx, insertVendorStatSQL)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("prepare insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, stat := range stats {
+		normalized := normalizeVendorStat(stat)
+		if _, err := stmt.ExecContext(ctx,
+			normalized.VendorID,
+			normalized.VendorName,
+			normalized.EndpointID,
+			normalized.EndpointName,
+			normalized.Path,
+			normalized.Date,
+			normalized.InterfaceType,
+			normalized.TargetHeaders,
+			normalized.DurationMs,
+			normalized.StatusCode,
+			normalized.Status,
+			normalized.InputTokens,
+			normalized.OutputTokens,
+			normalized.CachedCreate,
+			normalized.CachedRead,
+			normalized.Reasoning,
+			normalized.UserID,
+			normalized.Experiment,
+			normalized.TTFTMs,
+			normalized.TokensPerSecond,
+		); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("insert vendor_stats: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit vendor_stats: %w", err)
 	}
 	return nil
 }
@@ -137,6 +272,8 @@ func normalizeVendorStat(stat VendorStat) VendorStat {
 	out.InterfaceType = strings.TrimSpace(out.InterfaceType)
 	out.TargetHeaders = strings.TrimSpace(out.TargetHeaders)
 	out.Status = strings.TrimSpace(out.Status)
+	out.UserID = strings.TrimSpace(out.UserID)
+	out.Experiment = strings.TrimSpace(out.Experiment)
 
 	if out.VendorID == "" {
 		out.VendorID = "0"
@@ -154,7 +291,7 @@ func normalizeVendorStat(stat VendorStat) VendorStat {
 		out.Path = "/"
 	}
 	if out.Date == "" {
-		out.Date = time.Now().Format("2006-01-02")
+		out.Date = Today()
 	}
 	if out.InterfaceType == "" {
 		out.InterfaceType = "unknown"
@@ -165,6 +302,12 @@ func normalizeVendorStat(stat VendorStat) VendorStat {
 	if out.Status == "" {
 		out.Status = "unknown"
 	}
+	if out.TTFTMs < 0 {
+		out.TTFTMs = 0
+	}
+	if out.TokensPerSecond < 0 || math.IsNaN(out.TokensPerSecond) || math.IsInf(out.TokensPerSecond, 0) {
+		out.TokensPerSecond = 0
+	}
 
 	if !json.Valid([]byte(out.TargetHeaders)) {
 		out.TargetHeaders = "{}"
@@ -227,6 +370,35 @@ type InterfaceTypeStatsSummary struct {
 	Endpoints     []EndpointStatsSummary `json:"endpoints"`
 }
 
+// UserStatsSummary represents aggregated stats for one requesting user
+type UserStatsSummary struct {
+	UserID       string `json:"userId"` // empty for requests without a user id
+	InputTokens  int64  `json:"inputTokens"`
+	OutputTokens int64  `json:"outputTokens"`
+	CachedCreate int64  `json:"cachedCreate"`
+	CachedRead   int64  `json:"cachedRead"`
+	Reasoning    int64  `json:"reasoning"`
+	Total        int64  `json:"total"`
+	RequestCount int64  `json:"requestCount"`
+	ErrorCount   int64  `json:"errorCount"`
+}
+
+// ExperimentStatsSummary represents aggregated stats for one experiment group
+type ExperimentStatsSummary struct {
+	Experiment    string  `json:"experiment"` // experiment name or "control"
+	EndpointID    string  `json:"endpointId"`
+	EndpointName  string  `json:"endpointName"`
+	InputTokens   int64   `json:"inputTokens"`
+	OutputTokens  int64   `json:"outputTokens"`
+	CachedCreate  int64   `json:"cachedCreate"`
+	CachedRead    int64   `json:"cachedRead"`
+	Reasoning     int64   `json:"reasoning"`
+	Total         int64   `json:"total"`
+	RequestCount  int64   `json:"requestCount"`
+	ErrorCount    int64   `json:"errorCount"`
+	AvgDurationMs float64 `json:"avgDurationMs"`
+}
+
 // TimeRange represents a time range for querying stats
 type TimeRange string
 
@@ -244,9 +416,43 @@ func (s *SQLiteVendorStatsStore) GetStatsByTimeRange(ctx context.Context, timeRa
 		return nil, errors.New("nil sqlite store")
 	}
 
-	dateCondition := buildDateCondition(timeRange)
+	table, _ := statsSource(timeRange)
+	return s.queryVendorSummaries(ctx, table, buildDateCondition(timeRange))
+}
+
+// GetStatsByCustomRange returns aggregated stats grouped by vendor for the inclusive date range
+// [startDate, endDate], both formatted as YYYY-MM-DD in the stats time zone
+func (s *SQLiteVendorStatsStore) GetStatsByCustomRange(ctx context.Context, startDate, endDate string) ([]VendorStatsSummary, error) {
+	if s == nil || s.db == nil {
+		return nil, errors.New("nil sqlite store")
+	}
 
-	// Query aggregated stats grouped by vendor and endpoint
+	start, end, err := parseDateRange(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	// The daily rollup is kept in sync on insert, so it covers today as well
+	return s.queryVendorSummaries(ctx, "vendor_stats_daily", "date BETWEEN ? AND ?", start, end)
+}
+
+// parseDateRange validates a YYYY-MM-DD date range and returns the normalized bounds
+func parseDateRange(startDate, endDate string) (string, string, error) {
+	start, err := time.Parse(dateLayout, strings.TrimSpace(startDate))
+	if err != nil {
+		return "", "", fmt.Errorf("invalid start date %q: want YYYY-MM-DD", startDate)
+	}
+	end, err := time.Parse(dateLayout, strings.TrimSpace(endDate))
+	if err != nil {
+		return "", "", fmt.Errorf("invalid end date %q: want YYYY-MM-DD", endDate)
+	}
+	if end.Before(start) {
+		return "", "", fmt.Errorf("end date %s is before start date %s", endDate, startDate)
+	}
+	return start.Format(dateLayout), end.Format(dateLayout), nil
+}
+
+// queryVendorSummaries aggregates table rows matching where (with args) grouped by vendor and endpoint
+func (s *SQLiteVendorStatsStore) queryVendorSummaries(ctx context.Context, table, where string, args ...any) ([]VendorStatsSummary, error) {
 	query := fmt.Sprintf(`
 		SELECT 
 			vendor_id, vendor_name, endpoint_id, endpoint_name,
@@ -255,13 +461,13 @@ func (s *SQLiteVendorStatsStore) GetStatsByTimeRange(ctx context.Context, timeRa
 			COALESCE(SUM(cached_create), 0) as cached_create,
 			COALESCE(SUM(cached_read), 0) as cached_read,
 			COALESCE(SUM(reasoning), 0) as reasoning
-		FROM vendor_stats
+		FROM %s
 		WHERE %s
 		GROUP BY vendor_id, vendor_name, endpoint_id, endpoint_name
 		ORDER BY vendor_name, endpoint_name
-	`, dateCondition)
+	`, table, where)
 
-	rows, err := s.db.QueryContext(ctx, query)
+	rows, err := s.reader().QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query stats: %w", err)
 	}
@@ -325,25 +531,46 @@ func (s *SQLiteVendorStatsStore) GetStatsByTimeRange(ctx context.Context, timeRa
 	return result, nil
 }
 
-// ClearStats clears all stats or stats for a specific time range
+// ClearStats clears all stats or stats for a specific time range.
+// The daily rollup and fallback events are cleared for the same dates in the same transaction.
 func (s *SQLiteVendorStatsStore) ClearStats(ctx context.Context, timeRange TimeRange) error {
 	if s == nil || s.db == nil {
 		return errors.New("nil sqlite store")
 	}
 
-	var query string
+	var query, rollupQuery, fallbackQuery string
 	if timeRange == TimeRangeAll {
 		query = "DELETE FROM vendor_stats"
+		rollupQuery = "DELETE FROM vendor_stats_daily"
+		fallbackQuery = "DELETE FROM fallback_events"
 	} else {
 		dateCondition := buildDateCondition(timeRange)
 		query = fmt.Sprintf("DELETE FROM vendor_stats WHERE %s", dateCondition)
+		rollupQuery = fmt.Sprintf("DELETE FROM vendor_stats_daily WHERE %s", dateCondition)
+		fallbackQuery = fmt.Sprintf("DELETE FROM fallback_events WHERE %s", dateCondition)
 	}
 
 	fmt.Printf("[ClearStats] Executing query: %s\n", query)
-	result, err := s.db.ExecContext(ctx, query)
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	result, err := tx.ExecContext(ctx, query)
 	if err != nil {
+		_ = tx.Rollback()
 		return fmt.Errorf("clear stats: %w", err)
 	}
+	if _, err := tx.ExecContext(ctx, rollupQuery); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("clear daily rollup: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, fallbackQuery); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("clear fallback events: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit clear stats: %w", err)
+	}
 
 	rowsAffected, _ := result.RowsAffected()
 	fmt.Printf("[ClearStats] Rows affected: %d\n", rowsAffected)
@@ -358,6 +585,8 @@ func (s *SQLiteVendorStatsStore) GetStatsByInterfaceType(ctx context.Context, ti
 
 	dateCondition := buildDateCondition(timeRange)
 
+	table, requestCount := statsSource(timeRange)
+
 	// For "all" time range, include date in grouping
 	includeDate := timeRange == TimeRangeAll
 
@@ -371,12 +600,12 @@ func (s *SQLiteVendorStatsStore) GetStatsByInterfaceType(ctx context.Context, ti
 				COALESCE(SUM(cached_create), 0) as cached_create,
 				COALESCE(SUM(cached_read), 0) as cached_read,
 				COALESCE(SUM(reasoning), 0) as reasoning,
-				COUNT(*) as request_count
-			FROM vendor_stats
+				%s as request_count
+			FROM %s
 			WHERE %s
 			GROUP BY interface_type, vendor_id, vendor_name, endpoint_id, endpoint_name, date
 			ORDER BY interface_type, date DESC, vendor_name, endpoint_name
-		`, dateCondition)
+		`, requestCount, table, dateCondition)
 	} else {
 		query = fmt.Sprintf(`
 			SELECT 
@@ -386,15 +615,15 @@ func (s *SQLiteVendorStatsStore) GetStatsByInterfaceType(ctx context.Context, ti
 				COALESCE(SUM(cached_create), 0) as cached_create,
 				COALESCE(SUM(cached_read), 0) as cached_read,
 				COALESCE(SUM(reasoning), 0) as reasoning,
-				COUNT(*) as request_count
-			FROM vendor_stats
+				%s as request_count
+			FROM %s
 			WHERE %s
 			GROUP BY interface_type, vendor_id, vendor_name, endpoint_id, endpoint_name
 			ORDER BY interface_type, vendor_name, endpoint_name
-		`, dateCondition)
+		`, requestCount, table, dateCondition)
 	}
 
-	rows, err := s.db.QueryContext(ctx, query)
+	rows, err := s.reader().QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("query stats: %w", err)
 	}
@@ -462,20 +691,171 @@ func (s *SQLiteVendorStatsStore) GetStatsByInterfaceType(ctx context.Context, ti
 	return result, nil
 }
 
+// GetStatsByUser returns aggregated stats grouped by requesting user for the given
+// time range, ordered by total tokens descending
+func (s *SQLiteVendorStatsStore) GetStatsByUser(ctx context.Context, timeRange TimeRange) ([]UserStatsSummary, error) {
+	if s == nil || s.db == nil {
+		return nil, errors.New("nil sqlite store")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT 
+			user_id,
+			COALESCE(SUM(input_tokens), 0) as input_tokens,
+			COALESCE(SUM(output_tokens), 0) as output_tokens,
+			COALESCE(SUM(cached_create), 0) as cached_create,
+			COALESCE(SUM(cached_read), 0) as cached_read,
+			COALESCE(SUM(reasoning), 0) as reasoning,
+			COUNT(*) as request_count,
+			SUM(CASE WHEN status_code >= 400 OR status = 'error' THEN 1 ELSE 0 END) as error_count
+		FROM vendor_stats
+		WHERE %s
+		GROUP BY user_id
+	`, buildDateCondition(timeRange))
+
+	rows, err := s.reader().QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query user stats: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]UserStatsSummary, 0)
+	for rows.Next() {
+		var summary UserStatsSummary
+		if err := rows.Scan(&summary.UserID, &summary.InputTokens, &summary.OutputTokens, &summary.CachedCreate, &summary.CachedRead, &summary.Reasoning, &summary.RequestCount, &summary.ErrorCount); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+		summary.Total = summary.InputTokens + summary.OutputTokens + summary.CachedCreate + summary.CachedRead + summary.Reasoning
+		result = append(result, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query user stats: %w", err)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Total != result[j].Total {
+			return result[i].Total > result[j].Total
+		}
+		return result[i].UserID < result[j].UserID
+	})
+	return result, nil
+}
+
+// GetStatsByExperiment returns aggregated stats of requests tagged with an A/B experiment
+// (including the control group), grouped by experiment and endpoint
+func (s *SQLiteVendorStatsStore) GetStatsByExperiment(ctx context.Context, timeRange TimeRange) ([]ExperimentStatsSummary, error) {
+	if s == nil || s.db == nil {
+		return nil, errors.New("nil sqlite store")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT 
+			experiment, endpoint_id, endpoint_name,
+			COALESCE(SUM(input_tokens), 0) as input_tokens,
+			COALESCE(SUM(output_tokens), 0) as output_tokens,
+			COALESCE(SUM(cached_create), 0) as cached_create,
+			COALESCE(SUM(cached_read), 0) as cached_read,
+			COALESCE(SUM(reasoning), 0) as reasoning,
+			COUNT(*) as request_count,
+			SUM(CASE WHEN status_code >= 400 OR status = 'error' THEN 1 ELSE 0 END) as error_count,
+			COALESCE(AVG(duration_ms), 0) as avg_duration_ms
+		FROM vendor_stats
+		WHERE experiment != '' AND %s
+		GROUP BY experiment, endpoint_id, endpoint_name
+		ORDER BY experiment, endpoint_name
+	`, buildDateCondition(timeRange))
+
+	rows, err := s.reader().QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query experiment stats: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]ExperimentStatsSummary, 0)
+	for rows.Next() {
+		var summary ExperimentStatsSummary
+		if err := rows.Scan(&summary.Experiment, &summary.EndpointID, &summary.EndpointName, &summary.InputTokens, &summary.OutputTokens, &summary.CachedCreate, &summary.CachedRead, &summary.Reasoning, &summary.RequestCount, &summary.ErrorCount, &summary.AvgDurationMs); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+		summary.Total = summary.InputTokens + summary.OutputTokens + summary.CachedCreate + summary.CachedRead + summary.Reasoning
+		result = append(result, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query experiment stats: %w", err)
+	}
+	return result, nil
+}
+
+// LatencyStatsSummary represents latency metrics of successful requests for one endpoint
+type LatencyStatsSummary struct {
+	InterfaceType      string  `json:"interfaceType"`
+	VendorName         string  `json:"vendorName"`
+	EndpointID         string  `json:"endpointId"`
+	EndpointName       string  `json:"endpointName"`
+	RequestCount       int64   `json:"requestCount"`
+	AvgDurationMs      float64 `json:"avgDurationMs"`
+	StreamCount        int64   `json:"streamCount"` // requests with a measured time to first token
+	AvgTTFTMs          float64 `json:"avgTtftMs"`
+	MaxTTFTMs          int64   `json:"maxTtftMs"`
+	AvgTokensPerSecond float64 `json:"avgTokensPerSecond"`
+}
+
+// GetLatencyStats returns latency metrics of successful requests for the given time range,
+// grouped by endpoint. TTFT and tokens per second only cover streamed responses.
+func (s *SQLiteVendorStatsStore) GetLatencyStats(ctx context.Context, timeRange TimeRange) ([]LatencyStatsSummary, error) {
+	if s == nil || s.db == nil {
+		return nil, errors.New("nil sqlite store")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT 
+			interface_type, vendor_name, endpoint_id, endpoint_name,
+			COUNT(*) as request_count,
+			COALESCE(AVG(duration_ms), 0) as avg_duration_ms,
+			SUM(CASE WHEN ttft_ms > 0 THEN 1 ELSE 0 END) as stream_count,
+			COALESCE(AVG(CASE WHEN ttft_ms > 0 THEN ttft_ms END), 0) as avg_ttft_ms,
+			COALESCE(MAX(ttft_ms), 0) as max_ttft_ms,
+			COALESCE(AVG(CASE WHEN tokens_per_second > 0 THEN tokens_per_second END), 0) as avg_tps
+		FROM vendor_stats
+		WHERE status = 'success' AND %s
+		GROUP BY interface_type, vendor_name, endpoint_id, endpoint_name
+		ORDER BY interface_type, vendor_name, endpoint_name
+	`, buildDateCondition(timeRange))
+
+	rows, err := s.reader().QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query latency stats: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]LatencyStatsSummary, 0)
+	for rows.Next() {
+		var summary LatencyStatsSummary
+		if err := rows.Scan(&summary.InterfaceType, &summary.VendorName, &summary.EndpointID, &summary.EndpointName, &summary.RequestCount, &summary.AvgDurationMs, &summary.StreamCount, &summary.AvgTTFTMs, &summary.MaxTTFTMs, &summary.AvgTokensPerSecond); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+		result = append(result, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query latency stats: %w", err)
+	}
+	return result, nil
+}
+
 func buildDateCondition(timeRange TimeRange) string {
-	now := time.Now()
+	now := StatsNow()
 	switch timeRange {
 	case TimeRangeToday:
-		return fmt.Sprintf("date = '%s'", now.Format("2006-01-02"))
+		return fmt.Sprintf("date = '%s'", now.Format(dateLayout))
 	case TimeRangeYesterday:
 		yesterday := now.AddDate(0, 0, -1)
-		return fmt.Sprintf("date = '%s'", yesterday.Format("2006-01-02"))
+		return fmt.Sprintf("date = '%s'", yesterday.Format(dateLayout))
 	case TimeRangeWeek:
 		weekStart := now.AddDate(0, 0, -int(now.Weekday()))
-		return fmt.Sprintf("date >= '%s'", weekStart.Format("2006-01-02"))
+		return fmt.Sprintf("date >= '%s'", weekStart.Format(dateLayout))
 	case TimeRangeMonth:
 		monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
-		return fmt.Sprintf("date >= '%s'", monthStart.Format("2006-01-02"))
+		return fmt.Sprintf("date >= '%s'", monthStart.Format(dateLayout))
 	default:
 		return "1=1"
 	}
@@ -496,7 +876,7 @@ func (s *SQLiteVendorStatsStore) GetTodayStatsByEndpoints(ctx context.Context) (
 		return nil, errors.New("nil sqlite store")
 	}
 
-	today := time.Now().Format("2006-01-02")
+	today := Today()
 	query := `
 		SELECT 
 			endpoint_id,
@@ -509,7 +889,7 @@ func (s *SQLiteVendorStatsStore) GetTodayStatsByEndpoints(ctx context.Context) (
 		GROUP BY endpoint_id
 	`
 
-	rows, err := s.db.QueryContext(ctx, query, today)
+	rows, err := s.reader().QueryContext(ctx, query, today)
 	if err != nil {
 		return nil, fmt.Errorf("query today stats: %w", err)
 	}
@@ -535,3 +915,47 @@ func (s *SQLiteVendorStatsStore) GetTodayStatsByEndpoints(ctx context.Context) (
 
 	return result, nil
 }
+
+// GetLastUsedByEndpoints returns the time of the most recent recorded request for each endpoint
+func (s *SQLiteVendorStatsStore) GetLastUsedByEndpoints(ctx context.Context) (map[string]time.Time, error) {
+	if s == nil || s.db == nil {
+		return nil, errors.New("nil sqlite store")
+	}
+
+	rows, err := s.reader().QueryContext(ctx, `
+		SELECT endpoint_id, MAX(create_time)
+		FROM vendor_stats
+		GROUP BY endpoint_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query last used: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]time.Time)
+	for rows.Next() {
+		var endpointID string
+		var lastUsed sql.NullString
+		if err := rows.Scan(&endpointID, &lastUsed); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+		if !lastUsed.Valid {
+			continue
+		}
+		if t, ok := parseSQLiteTime(lastUsed.String); ok {
+			result[endpointID] = t
+		}
+	}
+
+	return result, rows.Err()
+}
+
+// parseSQLiteTime parses a CURRENT_TIMESTAMP value (UTC) as returned by the driver
+func parseSQLiteTime(value string) (time.Time, bool) {
+	for _, layout := range []string{"2006-01-02 15:04:05", time.RFC3339Nano, "2006-01-02 15:04:05.999999999-07:00"} {
+		if t, err := time.ParseInLocation(layout, value, time.UTC); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,497 @@
+package statsdb
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestOpenSQLiteVendorStatsStore_Pragmas(t *testing.T) {
+	t.Parallel()
+
+	store, err := OpenSQLiteVendorStatsStore(filepath.Join(t.TempDir(), "stats.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	if err := store.InsertVendorStat(ctx, VendorStat{EndpointID: "1", Date: "2026-01-01", Status: "success"}); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	store.Flush()
+
+	var journalMode string
+	if err := store.reader().QueryRowContext(ctx, "PRAGMA journal_mode").Scan(&journalMode); err != nil {
+		t.Fatalf("journal_mode: %v", err)
+	}
+	if journalMode != "wal" {
+		t.Fatalf("journal_mode=%q, want wal", journalMode)
+	}
+
+	var busyTimeout int
+	if err := store.db.QueryRowContext(ctx, "PRAGMA busy_timeout").Scan(&busyTimeout); err != nil {
+		t.Fatalf("busy_timeout: %v", err)
+	}
+	if busyTimeout != sqliteBusyTimeoutMs {
+		t.Fatalf("busy_timeout=%d, want %d", busyTimeout, sqliteBusyTimeoutMs)
+	}
+
+	var count int
+	if err := store.reader().QueryRowContext(ctx, "SELECT COUNT(*) FROM vendor_stats").Scan(&count); err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("count=%d, want 1", count)
+	}
+
+	if _, err := store.reader().ExecContext(ctx, "DELETE FROM vendor_stats"); err == nil {
+		t.Fatalf("expected read pool to reject writes")
+	}
+}
+
+func TestSQLiteVendorStatsStore_CloseFlushesQueue(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "stats.db")
+	store, err := OpenSQLiteVendorStatsStore(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	ctx := context.Background()
+	const n = statBatchSize*2 + 7
+	for i := 0; i < n; i++ {
+		if err := store.InsertVendorStat(ctx, VendorStat{EndpointID: "1", Date: "2026-01-01", Status: "success"}); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if err := store.InsertVendorStat(ctx, VendorStat{EndpointID: "1"}); err != nil {
+		t.Fatalf("insert after close: %v", err)
+	}
+
+	reopened, err := OpenSQLiteVendorStatsStore(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer reopened.Close()
+
+	var count int
+	if err := reopened.reader().QueryRowContext(ctx, "SELECT COUNT(*) FROM vendor_stats").Scan(&count); err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if count != n {
+		t.Fatalf("count=%d, want %d", count, n)
+	}
+}
+
+func TestSQLiteVendorStatsStore_MigratesUserIDAndGroupsByUser(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "stats.db")
+	ctx := context.Background()
+
+	// database created before the user_id column existed
+	legacy, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("open legacy: %v", err)
+	}
+	if _, err := legacy.ExecContext(ctx, strings.Replace(schemaSQL, "    user_id TEXT NOT NULL DEFAULT '',\n", "", 1)); err != nil {
+		t.Fatalf("legacy schema: %v", err)
+	}
+	if _, err := legacy.ExecContext(ctx, `INSERT INTO vendor_stats(vendor_id, vendor_name, endpoint_id, endpoint_name, path, date, interface_type, target_headers, status_code, status, input_tokens)
+		VALUES('1', 'v', '1', 'e', '/', '2026-01-01', 'claude', '{}', 200, 'success', 5)`); err != nil {
+		t.Fatalf("legacy insert: %v", err)
+	}
+	_ = legacy.Close()
+
+	store, err := OpenSQLiteVendorStatsStore(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer store.Close()
+
+	for _, stat := range []VendorStat{
+		{EndpointID: "1", Date: "2026-01-01", Status: "success", UserID: "alice", InputTokens: 10, OutputTokens: 5},
+		{EndpointID: "1", Date: "2026-01-01", Status: "error", StatusCode: 500, UserID: "alice", InputTokens: 1},
+		{EndpointID: "1", Date: "2026-01-01", Status: "success", UserID: " bob ", InputTokens: 3},
+	} {
+		if err := store.InsertVendorStat(ctx, stat); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+	store.Flush()
+
+	got, err := store.GetStatsByUser(ctx, TimeRangeAll)
+	if err != nil {
+		t.Fatalf("GetStatsByUser: %v", err)
+	}
+	want := []UserStatsSummary{
+		{UserID: "alice", InputTokens: 11, OutputTokens: 5, Total: 16, RequestCount: 2, ErrorCount: 1},
+		{UserID: "", InputTokens: 5, Total: 5, RequestCount: 1},
+		{UserID: "bob", InputTokens: 3, Total: 3, RequestCount: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d users, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("user %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLoadStatsLocation(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "", want: "Local"},
+		{name: "local", want: "Local"},
+		{name: "UTC", want: "UTC"},
+		{name: "Not/AZone", wantErr: true},
+	}
+	for _, tc := range cases {
+		loc, err := LoadStatsLocation(tc.name)
+		if tc.wantErr {
+			if err == nil {
+				t.Fatalf("LoadStatsLocation(%q) want error", tc.name)
+			}
+			continue
+		}
+		if err != nil || loc.String() != tc.want {
+			t.Fatalf("LoadStatsLocation(%q)=%v,%v want %s", tc.name, loc, err, tc.want)
+		}
+	}
+}
+
+func TestSQLiteVendorStatsStore_DegradesAndRecovers(t *testing.T) {
+	t.Parallel()
+
+	store, err := OpenSQLiteVendorStatsStore(filepath.Join(t.TempDir(), "stats.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer store.Close()
+	store.writer.retryInterval = 0
+
+	ctx := context.Background()
+	if _, err := store.db.ExecContext(ctx, "DROP TABLE vendor_stats"); err != nil {
+		t.Fatalf("drop: %v", err)
+	}
+	for i := 0; i < statDegradeAfterFailures; i++ {
+		if store.WriteStatus().Degraded {
+			t.Fatalf("degraded after %d failures", i)
+		}
+		_ = store.InsertVendorStat(ctx, VendorStat{EndpointID: "1", Status: "success"})
+		store.Flush()
+	}
+	status := store.WriteStatus()
+	if !status.Degraded || status.LastError == "" || status.DegradedSince.IsZero() {
+		t.Fatalf("status=%+v, want degraded", status)
+	}
+
+	_ = store.InsertVendorStat(ctx, VendorStat{EndpointID: "1", Status: "success"})
+	if got := store.WriteStatus().Skipped; got != 1 {
+		t.Fatalf("skipped=%d, want 1", got)
+	}
+
+	if err := store.initSchema(ctx); err != nil {
+		t.Fatalf("recreate schema: %v", err)
+	}
+	store.Flush()
+	if store.WriteStatus().Degraded {
+		t.Fatalf("still degraded after the store recovered")
+	}
+
+	// the last failed batch is written on recovery
+	var count int
+	if err := store.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM vendor_stats").Scan(&count); err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("count=%d, want 1", count)
+	}
+}
+
+func TestSQLiteVendorStatsStore_DailyRollup(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "stats.db")
+	ctx := context.Background()
+
+	// rows written before the rollup existed are backfilled on open
+	legacy, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("open legacy: %v", err)
+	}
+	if _, err := legacy.ExecContext(ctx, schemaSQL); err != nil {
+		t.Fatalf("legacy schema: %v", err)
+	}
+	if _, err := legacy.ExecContext(ctx, `INSERT INTO vendor_stats(vendor_id, vendor_name, endpoint_id, endpoint_name, path, date, interface_type, target_headers, status_code, status, input_tokens)
+		VALUES('1', 'v', '1', 'e', '/', '2026-01-01', 'claude', '{}', 200, 'success', 5)`); err != nil {
+		t.Fatalf("legacy insert: %v", err)
+	}
+	_ = legacy.Close()
+
+	store, err := OpenSQLiteVendorStatsStore(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	for _, stat := range []VendorStat{
+		{VendorID: "1", VendorName: "v", EndpointID: "1", EndpointName: "e", Date: "2026-01-01", InterfaceType: "claude", Status: "success", InputTokens: 10, OutputTokens: 2},
+		{VendorID: "1", VendorName: "v", EndpointID: "1", EndpointName: "e", Date: "2026-01-02", InterfaceType: "claude", Status: "success", CachedRead: 7},
+		{VendorID: "2", VendorName: "w", EndpointID: "2", EndpointName: "f", Date: "2026-01-02", InterfaceType: "codex", Status: "success", Reasoning: 4},
+	} {
+		if err := store.InsertVendorStat(ctx, stat); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+	// reopening must not backfill again
+	if err := store.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	store, err = OpenSQLiteVendorStatsStore(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer store.Close()
+
+	byType, err := store.GetStatsByInterfaceType(ctx, TimeRangeAll)
+	if err != nil {
+		t.Fatalf("GetStatsByInterfaceType: %v", err)
+	}
+	if len(byType) != 2 || byType[0].InterfaceType != "claude" || byType[0].RequestCount != 3 || byType[0].Total != 24 || len(byType[0].Endpoints) != 2 {
+		t.Fatalf("claude stats = %+v", byType)
+	}
+	if byType[1].RequestCount != 1 || byType[1].Reasoning != 4 {
+		t.Fatalf("codex stats = %+v", byType[1])
+	}
+
+	byVendor, err := store.GetStatsByTimeRange(ctx, TimeRangeAll)
+	if err != nil {
+		t.Fatalf("GetStatsByTimeRange: %v", err)
+	}
+	if len(byVendor) != 2 || byVendor[0].InputTokens != 15 || byVendor[0].CachedRead != 7 || byVendor[0].Total != 24 {
+		t.Fatalf("vendor stats = %+v", byVendor)
+	}
+
+	if err := store.ClearStats(ctx, TimeRangeAll); err != nil {
+		t.Fatalf("ClearStats: %v", err)
+	}
+	var count int
+	if err := store.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM vendor_stats_daily").Scan(&count); err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("rollup rows after clear = %d, want 0", count)
+	}
+}
+
+func TestSQLiteVendorStatsStore_FallbackEvents(t *testing.T) {
+	t.Parallel()
+
+	store, err := OpenSQLiteVendorStatsStore(filepath.Join(t.TempDir(), "stats.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	recent := StatsNow().AddDate(0, 0, -10).Format(dateLayout)
+	events := []FallbackEvent{
+		{Date: "2000-01-01", FromEndpoint: "expired", ToEndpoint: "b", StatusCode: 500},
+		{Date: recent, FromEndpoint: "old", ToEndpoint: "b", StatusCode: 500},
+		{FromVendor: "v1", FromEndpoint: "a", ToVendor: "v2", ToEndpoint: "b", Path: "/v1/messages", StatusCode: 529, ErrorMessage: strings.Repeat("é", maxFallbackErrorLen)},
+		{FromEndpoint: "b", ToEndpoint: "c", StatusCode: 502},
+	}
+	for _, e := range events {
+		if err := store.InsertFallbackEvent(ctx, e); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+
+	today, err := store.GetFallbackEvents(ctx, TimeRangeToday)
+	if err != nil {
+		t.Fatalf("query today: %v", err)
+	}
+	if len(today) != 2 || today[0].FromEndpoint != "b" || today[1].FromEndpoint != "a" {
+		t.Fatalf("today events = %+v, want newest first without old event", today)
+	}
+	got := today[1]
+	if got.FromVendor != "v1" || got.ToVendor != "v2" || got.Path != "/v1/messages" || got.StatusCode != 529 || got.CreateTime.IsZero() {
+		t.Fatalf("event = %+v", got)
+	}
+	if len(got.ErrorMessage) > maxFallbackErrorLen || !utf8.ValidString(got.ErrorMessage) {
+		t.Fatalf("error message not truncated to valid utf-8: len=%d", len(got.ErrorMessage))
+	}
+
+	all, err := store.GetFallbackEvents(ctx, TimeRangeAll)
+	if err != nil {
+		t.Fatalf("query all: %v", err)
+	}
+	// the event past the retention window was pruned by later inserts
+	if len(all) != 3 || all[2].FromEndpoint != "old" {
+		t.Fatalf("all events = %+v, want 3 without the expired one", all)
+	}
+
+	if err := store.ClearStats(ctx, TimeRangeToday); err != nil {
+		t.Fatalf("clear today: %v", err)
+	}
+	all, err = store.GetFallbackEvents(ctx, TimeRangeAll)
+	if err != nil {
+		t.Fatalf("query all after clear: %v", err)
+	}
+	if len(all) != 1 || all[0].FromEndpoint != "old" {
+		t.Fatalf("events after clearing today = %+v, want only the older one", all)
+	}
+	if err := store.ClearStats(ctx, TimeRangeAll); err != nil {
+		t.Fatalf("clear all: %v", err)
+	}
+	if all, _ = store.GetFallbackEvents(ctx, TimeRangeAll); len(all) != 0 {
+		t.Fatalf("events after clearing all = %d, want 0", len(all))
+	}
+}
+
+func TestSQLiteVendorStatsStore_GetStatsByCustomRange(t *testing.T) {
+	t.Parallel()
+
+	store, err := OpenSQLiteVendorStatsStore(filepath.Join(t.TempDir(), "stats.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	for _, stat := range []VendorStat{
+		{VendorID: "1", VendorName: "v", EndpointID: "1", EndpointName: "e", Date: "2026-01-01", InterfaceType: "claude", Status: "success", InputTokens: 1},
+		{VendorID: "1", VendorName: "v", EndpointID: "1", EndpointName: "e", Date: "2026-01-05", InterfaceType: "claude", Status: "success", InputTokens: 10},
+		{VendorID: "1", VendorName: "v", EndpointID: "1", EndpointName: "e", Date: "2026-01-10", InterfaceType: "claude", Status: "success", InputTokens: 100},
+	} {
+		if err := store.InsertVendorStat(ctx, stat); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+	store.Flush()
+
+	stats, err := store.GetStatsByCustomRange(ctx, "2026-01-01", "2026-01-05")
+	if err != nil {
+		t.Fatalf("GetStatsByCustomRange: %v", err)
+	}
+	if len(stats) != 1 || stats[0].InputTokens != 11 || len(stats[0].Endpoints) != 1 {
+		t.Fatalf("stats = %+v, want inclusive bounds summing to 11", stats)
+	}
+
+	for _, tc := range []struct{ start, end string }{
+		{"2026-01-05", "2026-01-01"},
+		{"2026/01/01", "2026-01-05"},
+		{"2026-01-01", ""},
+	} {
+		if _, err := store.GetStatsByCustomRange(ctx, tc.start, tc.end); err == nil {
+			t.Fatalf("GetStatsByCustomRange(%q, %q): want error", tc.start, tc.end)
+		}
+	}
+}
+
+func TestSQLiteVendorStatsStore_GetLatencyStats(t *testing.T) {
+	t.Parallel()
+
+	store, err := OpenSQLiteVendorStatsStore(filepath.Join(t.TempDir(), "stats.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	today := Today()
+	for _, stat := range []VendorStat{
+		{EndpointID: "1", EndpointName: "e", Date: today, InterfaceType: "claude", Status: "success", DurationMs: 3000, TTFTMs: 400, TokensPerSecond: 50},
+		{EndpointID: "1", EndpointName: "e", Date: today, InterfaceType: "claude", Status: "success", DurationMs: 1000, TTFTMs: 800, TokensPerSecond: 30},
+		{EndpointID: "1", EndpointName: "e", Date: today, InterfaceType: "claude", Status: "success", DurationMs: 2000},
+		{EndpointID: "1", EndpointName: "e", Date: today, InterfaceType: "claude", Status: "error_500", StatusCode: 500, DurationMs: 9000, TTFTMs: 9000},
+	} {
+		if err := store.InsertVendorStat(ctx, stat); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+	store.Flush()
+
+	got, err := store.GetLatencyStats(ctx, TimeRangeToday)
+	if err != nil {
+		t.Fatalf("GetLatencyStats: %v", err)
+	}
+	want := LatencyStatsSummary{
+		InterfaceType: "claude", VendorName: "unknown", EndpointID: "1", EndpointName: "e",
+		RequestCount: 3, AvgDurationMs: 2000, StreamCount: 2, AvgTTFTMs: 600, MaxTTFTMs: 800, AvgTokensPerSecond: 40,
+	}
+	if len(got) != 1 || got[0] != want {
+		t.Fatalf("latency stats = %+v, want [%+v]", got, want)
+	}
+}
+
+func TestSQLiteVendorStatsStore_GetLastUsedByEndpoints(t *testing.T) {
+	t.Parallel()
+
+	store, err := OpenSQLiteVendorStatsStore(filepath.Join(t.TempDir(), "stats.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	for _, id := range []string{"1", "1", "2"} {
+		if err := store.InsertVendorStat(ctx, VendorStat{EndpointID: id, Date: "2026-01-01", Status: "success"}); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+	store.Flush()
+	// spread the rows out in time: endpoint 1 was last used at 12:00
+	for id, ts := range map[int]string{1: "2026-01-01 09:00:00", 2: "2026-01-01 12:00:00", 3: "2026-01-01 10:30:00"} {
+		if _, err := store.db.ExecContext(ctx, "UPDATE vendor_stats SET create_time = ? WHERE id = ?", ts, id); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+	}
+
+	got, err := store.GetLastUsedByEndpoints(ctx)
+	if err != nil {
+		t.Fatalf("GetLastUsedByEndpoints: %v", err)
+	}
+	want := map[string]string{"1": "2026-01-01T12:00:00Z", "2": "2026-01-01T10:30:00Z"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	for id, ts := range want {
+		if got[id].Format(time.RFC3339) != ts {
+			t.Fatalf("endpoint %s: last used %v want %s", id, got[id], ts)
+		}
+	}
+
+	// the lookup reads the covering index rather than scanning vendor_stats
+	rows, err := store.reader().QueryContext(ctx, "EXPLAIN QUERY PLAN SELECT endpoint_id, MAX(create_time) FROM vendor_stats GROUP BY endpoint_id")
+	if err != nil {
+		t.Fatalf("explain: %v", err)
+	}
+	defer rows.Close()
+	var plan strings.Builder
+	for rows.Next() {
+		var id, parent, notUsed int
+		var detail string
+		if err := rows.Scan(&id, &parent, &notUsed, &detail); err != nil {
+			t.Fatalf("scan plan: %v", err)
+		}
+		plan.WriteString(detail + "\n")
+	}
+	if !strings.Contains(plan.String(), "idx_vendor_stats_endpoint_time") {
+		t.Fatalf("query plan does not use the endpoint time index:\n%s", plan.String())
+	}
+}
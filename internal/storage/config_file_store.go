@@ -3,6 +3,7 @@ package storage
 import (
 	"errors"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
 	"sort"
@@ -30,6 +31,24 @@ func NewConfigFileStore(loader *config.ConfigLoader) (*ConfigFileStore, error) {
 
 func (s *ConfigFileStore) Close() error { return nil }
 
+// SetConfigPath repoints the store to another config file (used for profile switching)
+func (s *ConfigFileStore) SetConfigPath(path string) error {
+	if path == "" {
+		return errors.New("config path is empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev := s.loader.GetPath()
+	s.loader.SetPath(path)
+	if err := s.ensureFileExists(); err != nil {
+		s.loader.SetPath(prev)
+		return err
+	}
+	return nil
+}
+
 func (s *ConfigFileStore) GetVendors() ([]*Vendor, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -47,6 +66,7 @@ func (s *ConfigFileStore) GetVendors() ([]*Vendor, error) {
 			HomeURL: v.HomeURL,
 			APIURL:  v.APIURL,
 			Remark:  v.Remark,
+			Headers: v.Headers,
 		})
 	}
 
@@ -76,6 +96,7 @@ func (s *ConfigFileStore) GetVendorByID(id int64) (*Vendor, error) {
 				HomeURL: v.HomeURL,
 				APIURL:  v.APIURL,
 				Remark:  v.Remark,
+				Headers: v.Headers,
 			}, nil
 		}
 	}
@@ -106,6 +127,7 @@ func (s *ConfigFileStore) SaveVendor(vendor *Vendor) error {
 			HomeURL:   vendor.HomeURL,
 			APIURL:    vendor.APIURL,
 			Remark:    vendor.Remark,
+			Headers:   vendor.Headers,
 			Endpoints: []config.EndpointConfig{},
 		})
 		return s.saveLocked(cfg)
@@ -120,6 +142,7 @@ func (s *ConfigFileStore) SaveVendor(vendor *Vendor) error {
 		cfg.Vendors[i].HomeURL = vendor.HomeURL
 		cfg.Vendors[i].APIURL = vendor.APIURL
 		cfg.Vendors[i].Remark = vendor.Remark
+		cfg.Vendors[i].Headers = vendor.Headers
 		return s.saveLocked(cfg)
 	}
 
@@ -378,6 +401,30 @@ func (s *ConfigFileStore) SetConfigBool(key string, value bool) error {
 	return s.saveLocked(cfg)
 }
 
+// GetAppConfig returns the whole stored config, exactly as it is kept in config.json
+func (s *ConfigFileStore) GetAppConfig() (*config.AppConfig, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.loadAndNormalizeLocked()
+}
+
+// ReplaceAppConfig overwrites the stored config with cfg, assigning any missing or duplicate IDs
+func (s *ConfigFileStore) ReplaceAppConfig(cfg *config.AppConfig) error {
+	if cfg == nil {
+		return errors.New("config is nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.ensureFileExists(); err != nil {
+		return err
+	}
+	ensureIDs(cfg)
+	return s.saveLocked(cfg)
+}
+
 func (s *ConfigFileStore) ensureFileExists() error {
 	path := s.loader.GetPath()
 	if path == "" {
@@ -444,6 +491,9 @@ func (s *ConfigFileStore) saveLocked(cfg *config.AppConfig) error {
 	return nil
 }
 
+// ensureIDs assigns IDs to vendors and endpoints that have none, and reassigns fresh IDs to
+// later entries whose ID duplicates an earlier one (e.g. a hand-edited config.json), so every
+// vendor and endpoint ID is unique. The first occurrence in file order keeps its ID.
 func ensureIDs(cfg *config.AppConfig) bool {
 	if cfg == nil {
 		return false
@@ -464,18 +514,33 @@ func ensureIDs(cfg *config.AppConfig) bool {
 		}
 	}
 
+	seenVendors := make(map[int64]bool)
+	seenEndpoints := make(map[int64]bool)
 	for vi := range cfg.Vendors {
-		if cfg.Vendors[vi].ID == 0 {
-			cfg.Vendors[vi].ID = nextVendor
+		vendor := &cfg.Vendors[vi]
+		if vendor.ID != 0 && seenVendors[vendor.ID] {
+			log.Printf("Warning: duplicate vendor id %d (%q) in config, reassigned to %d", vendor.ID, vendor.Name, nextVendor)
+			vendor.ID = 0
+		}
+		if vendor.ID == 0 {
+			vendor.ID = nextVendor
 			nextVendor++
 			changed = true
 		}
-		for ei := range cfg.Vendors[vi].Endpoints {
-			if cfg.Vendors[vi].Endpoints[ei].ID == 0 {
-				cfg.Vendors[vi].Endpoints[ei].ID = nextEndpoint
+		seenVendors[vendor.ID] = true
+
+		for ei := range vendor.Endpoints {
+			ep := &vendor.Endpoints[ei]
+			if ep.ID != 0 && seenEndpoints[ep.ID] {
+				log.Printf("Warning: duplicate endpoint id %d (%q) in config, reassigned to %d", ep.ID, ep.Name, nextEndpoint)
+				ep.ID = 0
+			}
+			if ep.ID == 0 {
+				ep.ID = nextEndpoint
 				nextEndpoint++
 				changed = true
 			}
+			seenEndpoints[ep.ID] = true
 		}
 	}
 
@@ -516,33 +581,76 @@ func flattenEndpoints(cfg *config.AppConfig) []*Endpoint {
 			var models []ModelMapping
 			for _, m := range ep.Models {
 				models = append(models, ModelMapping{
-					Name:  m.Name,
-					Alias: m.Alias,
+					Name:      m.Name,
+					Alias:     m.Alias,
+					MatchType: m.MatchType,
 				})
 			}
 
 			out = append(out, &Endpoint{
-				ID:            ep.ID,
-				Name:          ep.Name,
-				APIURL:        ep.APIURL,
-				APIKey:        ep.APIKey,
-				Active:        ep.Active,
-				Enabled:       ep.Enabled,
-				InterfaceType: ep.InterfaceType,
-				Transformer:   ep.Transformer,
-				VendorID:      v.ID,
-				Model:         ep.Model,
-				Remark:        ep.Remark,
-				Priority:      ep.Priority,
-				ProxyURL:      ep.ProxyURL,
-				Models:        models,
-				Headers:       ep.Headers,
+				ID:                     ep.ID,
+				Name:                   ep.Name,
+				APIURL:                 ep.APIURL,
+				APIKey:                 ep.APIKey,
+				Active:                 ep.Active,
+				Enabled:                ep.Enabled,
+				InterfaceType:          ep.InterfaceType,
+				Transformer:            ep.Transformer,
+				VendorID:               v.ID,
+				Model:                  ep.Model,
+				Remark:                 ep.Remark,
+				Priority:               ep.Priority,
+				ProxyURL:               ep.ProxyURL,
+				ProxyUsername:          ep.ProxyUsername,
+				ProxyPassword:          ep.ProxyPassword,
+				ForceNonStreamUpstream: ep.ForceNonStreamUpstream,
+				FallbackToDefaultModel: ep.FallbackToDefaultModel,
+				Models:                 models,
+				Headers:                ep.Headers,
+				DropParams:             ep.DropParams,
+				BodyOverrides:          ep.BodyOverrides,
+				BodyForce:              ep.BodyForce,
+				IncludeReasoning:       ep.IncludeReasoning,
+				ReasoningSummary:       ep.ReasoningSummary,
+				InsecureSkipVerify:     ep.InsecureSkipVerify,
+				ForwardClientIP:        ep.ForwardClientIP,
+				Sandbox:                ep.Sandbox,
+				CAFile:                 ep.CAFile,
+				AnthropicVersion:       ep.AnthropicVersion,
+				AnthropicBeta:          ep.AnthropicBeta,
+				DailyTokenBudget:       ep.DailyTokenBudget,
+				MaxTokensCap:           ep.MaxTokensCap,
+				ResponseValidation:     ep.ResponseValidation,
+				Schedule:               toStorageSchedule(ep.Schedule),
+				VendorHeaders:          v.Headers,
 			})
 		}
 	}
 	return out
 }
 
+func toStorageSchedule(windows []config.ScheduleWindow) []ScheduleWindow {
+	if len(windows) == 0 {
+		return nil
+	}
+	out := make([]ScheduleWindow, 0, len(windows))
+	for _, w := range windows {
+		out = append(out, ScheduleWindow{Days: w.Days, StartHHMM: w.StartHHMM, EndHHMM: w.EndHHMM})
+	}
+	return out
+}
+
+func toConfigSchedule(windows []ScheduleWindow) []config.ScheduleWindow {
+	if len(windows) == 0 {
+		return nil
+	}
+	out := make([]config.ScheduleWindow, 0, len(windows))
+	for _, w := range windows {
+		out = append(out, config.ScheduleWindow{Days: w.Days, StartHHMM: w.StartHHMM, EndHHMM: w.EndHHMM})
+	}
+	return out
+}
+
 func addEndpointToVendor(cfg *config.AppConfig, endpoint *Endpoint) error {
 	for i := range cfg.Vendors {
 		if cfg.Vendors[i].ID != endpoint.VendorID {
@@ -553,26 +661,46 @@ func addEndpointToVendor(cfg *config.AppConfig, endpoint *Endpoint) error {
 		var models []config.ModelMapping
 		for _, m := range endpoint.Models {
 			models = append(models, config.ModelMapping{
-				Name:  m.Name,
-				Alias: m.Alias,
+				Name:      m.Name,
+				Alias:     m.Alias,
+				MatchType: m.MatchType,
 			})
 		}
 
 		cfg.Vendors[i].Endpoints = append(cfg.Vendors[i].Endpoints, config.EndpointConfig{
-			ID:            endpoint.ID,
-			Name:          endpoint.Name,
-			APIURL:        endpoint.APIURL,
-			APIKey:        endpoint.APIKey,
-			Active:        endpoint.Active,
-			Enabled:       endpoint.Enabled,
-			InterfaceType: endpoint.InterfaceType,
-			Transformer:   endpoint.Transformer,
-			Model:         endpoint.Model,
-			Remark:        endpoint.Remark,
-			Priority:      endpoint.Priority,
-			ProxyURL:      endpoint.ProxyURL,
-			Models:        models,
-			Headers:       endpoint.Headers,
+			ID:                     endpoint.ID,
+			Name:                   endpoint.Name,
+			APIURL:                 endpoint.APIURL,
+			APIKey:                 endpoint.APIKey,
+			Active:                 endpoint.Active,
+			Enabled:                endpoint.Enabled,
+			InterfaceType:          endpoint.InterfaceType,
+			Transformer:            endpoint.Transformer,
+			Model:                  endpoint.Model,
+			Remark:                 endpoint.Remark,
+			Priority:               endpoint.Priority,
+			ProxyURL:               endpoint.ProxyURL,
+			ProxyUsername:          endpoint.ProxyUsername,
+			ProxyPassword:          endpoint.ProxyPassword,
+			ForceNonStreamUpstream: endpoint.ForceNonStreamUpstream,
+			FallbackToDefaultModel: endpoint.FallbackToDefaultModel,
+			Models:                 models,
+			Headers:                endpoint.Headers,
+			DropParams:             endpoint.DropParams,
+			BodyOverrides:          endpoint.BodyOverrides,
+			BodyForce:              endpoint.BodyForce,
+			IncludeReasoning:       endpoint.IncludeReasoning,
+			ReasoningSummary:       endpoint.ReasoningSummary,
+			InsecureSkipVerify:     endpoint.InsecureSkipVerify,
+			ForwardClientIP:        endpoint.ForwardClientIP,
+			Sandbox:                endpoint.Sandbox,
+			CAFile:                 endpoint.CAFile,
+			AnthropicVersion:       endpoint.AnthropicVersion,
+			AnthropicBeta:          endpoint.AnthropicBeta,
+			DailyTokenBudget:       endpoint.DailyTokenBudget,
+			MaxTokensCap:           endpoint.MaxTokensCap,
+			ResponseValidation:     endpoint.ResponseValidation,
+			Schedule:               toConfigSchedule(endpoint.Schedule),
 		})
 		return nil
 	}
@@ -584,8 +712,9 @@ func updateEndpointByID(cfg *config.AppConfig, endpoint *Endpoint) (bool, error)
 	var models []config.ModelMapping
 	for _, m := range endpoint.Models {
 		models = append(models, config.ModelMapping{
-			Name:  m.Name,
-			Alias: m.Alias,
+			Name:      m.Name,
+			Alias:     m.Alias,
+			MatchType: m.MatchType,
 		})
 	}
 
@@ -611,8 +740,27 @@ func updateEndpointByID(cfg *config.AppConfig, endpoint *Endpoint) (bool, error)
 				moved.Remark = endpoint.Remark
 				moved.Priority = endpoint.Priority
 				moved.ProxyURL = endpoint.ProxyURL
+				moved.ProxyUsername = endpoint.ProxyUsername
+				moved.ProxyPassword = endpoint.ProxyPassword
+				moved.ForceNonStreamUpstream = endpoint.ForceNonStreamUpstream
+				moved.FallbackToDefaultModel = endpoint.FallbackToDefaultModel
 				moved.Models = models
 				moved.Headers = endpoint.Headers
+				moved.DropParams = endpoint.DropParams
+				moved.BodyOverrides = endpoint.BodyOverrides
+				moved.BodyForce = endpoint.BodyForce
+				moved.IncludeReasoning = endpoint.IncludeReasoning
+				moved.ReasoningSummary = endpoint.ReasoningSummary
+				moved.InsecureSkipVerify = endpoint.InsecureSkipVerify
+				moved.ForwardClientIP = endpoint.ForwardClientIP
+				moved.Sandbox = endpoint.Sandbox
+				moved.CAFile = endpoint.CAFile
+				moved.AnthropicVersion = endpoint.AnthropicVersion
+				moved.AnthropicBeta = endpoint.AnthropicBeta
+				moved.DailyTokenBudget = endpoint.DailyTokenBudget
+				moved.MaxTokensCap = endpoint.MaxTokensCap
+				moved.ResponseValidation = endpoint.ResponseValidation
+				moved.Schedule = toConfigSchedule(endpoint.Schedule)
 
 				cfg.Vendors[vi].Endpoints = append(eps[:ei], eps[ei+1:]...)
 				for dest := range cfg.Vendors {
@@ -637,8 +785,27 @@ func updateEndpointByID(cfg *config.AppConfig, endpoint *Endpoint) (bool, error)
 			eps[ei].Remark = endpoint.Remark
 			eps[ei].Priority = endpoint.Priority
 			eps[ei].ProxyURL = endpoint.ProxyURL
+			eps[ei].ProxyUsername = endpoint.ProxyUsername
+			eps[ei].ProxyPassword = endpoint.ProxyPassword
+			eps[ei].ForceNonStreamUpstream = endpoint.ForceNonStreamUpstream
+			eps[ei].FallbackToDefaultModel = endpoint.FallbackToDefaultModel
 			eps[ei].Models = models
 			eps[ei].Headers = endpoint.Headers
+			eps[ei].DropParams = endpoint.DropParams
+			eps[ei].BodyOverrides = endpoint.BodyOverrides
+			eps[ei].BodyForce = endpoint.BodyForce
+			eps[ei].IncludeReasoning = endpoint.IncludeReasoning
+			eps[ei].ReasoningSummary = endpoint.ReasoningSummary
+			eps[ei].InsecureSkipVerify = endpoint.InsecureSkipVerify
+			eps[ei].ForwardClientIP = endpoint.ForwardClientIP
+			eps[ei].Sandbox = endpoint.Sandbox
+			eps[ei].CAFile = endpoint.CAFile
+			eps[ei].AnthropicVersion = endpoint.AnthropicVersion
+			eps[ei].AnthropicBeta = endpoint.AnthropicBeta
+			eps[ei].DailyTokenBudget = endpoint.DailyTokenBudget
+			eps[ei].MaxTokensCap = endpoint.MaxTokensCap
+			eps[ei].ResponseValidation = endpoint.ResponseValidation
+			eps[ei].Schedule = toConfigSchedule(endpoint.Schedule)
 			return true, nil
 		}
 	}
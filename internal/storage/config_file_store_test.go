@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"clisimplehub/internal/config"
+)
+
+func TestEnsureIDs(t *testing.T) {
+	t.Parallel()
+
+	type ids struct {
+		vendor    int64
+		endpoints []int64
+	}
+	cases := []struct {
+		name        string
+		in          []ids
+		want        []ids
+		wantChanged bool
+	}{
+		{
+			name: "unique ids unchanged",
+			in:   []ids{{1, []int64{1, 2}}, {2, []int64{3}}},
+			want: []ids{{1, []int64{1, 2}}, {2, []int64{3}}},
+		},
+		{
+			name:        "missing ids assigned after the max",
+			in:          []ids{{0, []int64{0, 5}}, {3, []int64{0}}},
+			want:        []ids{{4, []int64{6, 5}}, {3, []int64{7}}},
+			wantChanged: true,
+		},
+		{
+			name:        "duplicate endpoint ids across vendors",
+			in:          []ids{{1, []int64{1, 2}}, {2, []int64{2, 1}}},
+			want:        []ids{{1, []int64{1, 2}}, {2, []int64{3, 4}}},
+			wantChanged: true,
+		},
+		{
+			name:        "duplicate endpoint ids within a vendor",
+			in:          []ids{{1, []int64{4, 4, 0}}},
+			want:        []ids{{1, []int64{4, 5, 6}}},
+			wantChanged: true,
+		},
+		{
+			name:        "duplicate vendor ids",
+			in:          []ids{{2, []int64{1}}, {2, []int64{2}}, {0, nil}},
+			want:        []ids{{2, []int64{1}}, {3, []int64{2}}, {4, nil}},
+			wantChanged: true,
+		},
+	}
+	for _, tc := range cases {
+		cfg := &config.AppConfig{}
+		for _, v := range tc.in {
+			vendor := config.VendorConfig{ID: v.vendor}
+			for _, id := range v.endpoints {
+				vendor.Endpoints = append(vendor.Endpoints, config.EndpointConfig{ID: id})
+			}
+			cfg.Vendors = append(cfg.Vendors, vendor)
+		}
+
+		if changed := ensureIDs(cfg); changed != tc.wantChanged {
+			t.Fatalf("%s: changed=%v want %v", tc.name, changed, tc.wantChanged)
+		}
+		for i, v := range tc.want {
+			got := cfg.Vendors[i]
+			if got.ID != v.vendor {
+				t.Fatalf("%s: vendor[%d] id=%d want %d", tc.name, i, got.ID, v.vendor)
+			}
+			for j, id := range v.endpoints {
+				if got.Endpoints[j].ID != id {
+					t.Fatalf("%s: vendor[%d].endpoints[%d] id=%d want %d", tc.name, i, j, got.Endpoints[j].ID, id)
+				}
+			}
+		}
+		// a second pass finds nothing to fix
+		if ensureIDs(cfg) {
+			t.Fatalf("%s: ensureIDs not idempotent", tc.name)
+		}
+	}
+}
+
+func TestConfigFileStoreRepairsDuplicateIDs(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	raw := `{"vendors":[
+		{"id":1,"name":"a","endpoints":[{"id":7,"name":"a1","apiUrl":"http://a","apiKey":"k","interfaceType":"claude"}]},
+		{"id":1,"name":"b","endpoints":[{"id":7,"name":"b1","apiUrl":"http://b","apiKey":"k","interfaceType":"claude"}]}
+	]}`
+	if err := os.WriteFile(path, []byte(raw), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	store, err := NewConfigFileStore(config.NewConfigLoader(path))
+	if err != nil {
+		t.Fatalf("NewConfigFileStore: %v", err)
+	}
+
+	endpoints, err := store.GetEndpoints()
+	if err != nil {
+		t.Fatalf("GetEndpoints: %v", err)
+	}
+	seen := make(map[int64]string)
+	for _, ep := range endpoints {
+		if other, dup := seen[ep.ID]; dup {
+			t.Fatalf("endpoints %s and %s share id %d", other, ep.Name, ep.ID)
+		}
+		seen[ep.ID] = ep.Name
+	}
+
+	// The repaired ids are written back so they stay stable across loads
+	cfg, err := config.NewConfigLoader(path).Load()
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if cfg.Vendors[0].ID != 1 || cfg.Vendors[1].ID != 2 || cfg.Vendors[0].Endpoints[0].ID != 7 || cfg.Vendors[1].Endpoints[0].ID != 8 {
+		t.Fatalf("ids not persisted: %+v", cfg.Vendors)
+	}
+}
@@ -2,44 +2,77 @@
 // All data is stored in config.json file.
 package storage
 
-import "time"
+import (
+	"time"
+
+	"clisimplehub/internal/config"
+)
 
 // Vendor represents an API vendor/provider
 type Vendor struct {
-	ID         int64     `json:"id"`
-	Name       string    `json:"name"`
-	HomeURL    string    `json:"homeUrl"`
-	APIURL     string    `json:"apiUrl"`
-	Remark     string    `json:"remark,omitempty"`
-	CreateTime time.Time `json:"createTime,omitempty"`
-	UpdateTime time.Time `json:"updateTime,omitempty"`
+	ID         int64             `json:"id"`
+	Name       string            `json:"name"`
+	HomeURL    string            `json:"homeUrl"`
+	APIURL     string            `json:"apiUrl"`
+	Remark     string            `json:"remark,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	CreateTime time.Time         `json:"createTime,omitempty"`
+	UpdateTime time.Time         `json:"updateTime,omitempty"`
 }
 
 // Endpoint represents an API endpoint configuration
 type Endpoint struct {
-	ID            int64             `json:"id"`
-	Name          string            `json:"name"`
-	APIURL        string            `json:"apiUrl"`
-	APIKey        string            `json:"apiKey"`
-	Active        bool              `json:"active"`
-	Enabled       bool              `json:"enabled"`
-	InterfaceType string            `json:"interfaceType"`
-	Transformer   string            `json:"transformer,omitempty"`
-	VendorID      int64             `json:"vendorId"`
-	Model         string            `json:"model,omitempty"`
-	Remark        string            `json:"remark,omitempty"`
-	Priority      int               `json:"priority,omitempty"`
-	ProxyURL      string            `json:"proxyUrl,omitempty"`
-	Models        []ModelMapping    `json:"models,omitempty"`
-	Headers       map[string]string `json:"headers,omitempty"`
-	CreateTime    time.Time         `json:"createTime,omitempty"`
-	UpdateTime    time.Time         `json:"updateTime,omitempty"`
+	ID                     int64             `json:"id"`
+	Name                   string            `json:"name"`
+	APIURL                 string            `json:"apiUrl"`
+	APIKey                 string            `json:"apiKey"`
+	Active                 bool              `json:"active"`
+	Enabled                bool              `json:"enabled"`
+	InterfaceType          string            `json:"interfaceType"`
+	Transformer            string            `json:"transformer,omitempty"`
+	VendorID               int64             `json:"vendorId"`
+	Model                  string            `json:"model,omitempty"`
+	Remark                 string            `json:"remark,omitempty"`
+	Priority               int               `json:"priority,omitempty"`
+	ProxyURL               string            `json:"proxyUrl,omitempty"`
+	ProxyUsername          string            `json:"proxyUsername,omitempty"`
+	ProxyPassword          string            `json:"proxyPassword,omitempty"`
+	ForceNonStreamUpstream bool              `json:"forceNonStreamUpstream,omitempty"`
+	FallbackToDefaultModel bool              `json:"fallbackToDefaultModel,omitempty"`
+	Models                 []ModelMapping    `json:"models,omitempty"`
+	Headers                map[string]string `json:"headers,omitempty"`
+	DropParams             []string          `json:"dropParams,omitempty"`
+	BodyOverrides          map[string]any    `json:"bodyOverrides,omitempty"`
+	BodyForce              bool              `json:"bodyForce,omitempty"`
+	IncludeReasoning       bool              `json:"includeReasoning,omitempty"`
+	ReasoningSummary       string            `json:"reasoningSummary,omitempty"`
+	InsecureSkipVerify     bool              `json:"insecureSkipVerify,omitempty"`
+	ForwardClientIP        bool              `json:"forwardClientIP,omitempty"`
+	Sandbox                bool              `json:"sandbox,omitempty"`
+	CAFile                 string            `json:"caFile,omitempty"`
+	AnthropicVersion       string            `json:"anthropicVersion,omitempty"`
+	AnthropicBeta          string            `json:"anthropicBeta,omitempty"`
+	DailyTokenBudget       int64             `json:"dailyTokenBudget,omitempty"`
+	MaxTokensCap           int               `json:"maxTokensCap,omitempty"`
+	ResponseValidation     []string          `json:"responseValidation,omitempty"`
+	Schedule               []ScheduleWindow  `json:"schedule,omitempty"`
+	VendorHeaders          map[string]string `json:"-"` // owning vendor's default headers, not persisted on the endpoint
+	CreateTime             time.Time         `json:"createTime,omitempty"`
+	UpdateTime             time.Time         `json:"updateTime,omitempty"`
+}
+
+// ScheduleWindow represents a weekly time window during which an endpoint is routable
+type ScheduleWindow struct {
+	Days      []int  `json:"days,omitempty"` // 0=周日 ... 6=周六，空表示每天
+	StartHHMM string `json:"startHHMM"`      // 开始时间 "HH:MM"（含）
+	EndHHMM   string `json:"endHHMM"`        // 结束时间 "HH:MM"（不含），早于开始时间表示跨午夜
 }
 
 // ModelMapping represents a model name mapping configuration
 type ModelMapping struct {
-	Name  string `json:"name"`  // 实际模型名（上游模型名）
-	Alias string `json:"alias"` // API 使用的别名（客户端传入的 model）
+	Name      string `json:"name"`                // 实际模型名（上游模型名）
+	Alias     string `json:"alias"`               // API 使用的别名（客户端传入的 model）
+	MatchType string `json:"matchType,omitempty"` // 别名匹配方式: exact(默认) | prefix | regex
 }
 
 // Storage defines the data operations interface
@@ -64,6 +97,10 @@ type Storage interface {
 	SetConfig(key, value string) error
 	SetConfigBool(key string, value bool) error
 
+	// Whole-config operations, used by backups that must carry every stored field
+	GetAppConfig() (*config.AppConfig, error)
+	ReplaceAppConfig(cfg *config.AppConfig) error
+
 	// Close closes the storage
 	Close() error
 }
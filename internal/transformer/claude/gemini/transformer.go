@@ -32,11 +32,20 @@ func (Transformer) OutputContentType(isStreaming bool) string {
 	return "application/json"
 }
 
+// TransformErrorResponse converts an upstream error body into a Claude error response
+func (Transformer) TransformErrorResponse(statusCode int, rawJSON []byte) []byte {
+	return shared.ParseErrorBody(statusCode, rawJSON).ClaudeErrorResponse(statusCode)
+}
+
 func (Transformer) TransformRequest(modelName string, rawJSON []byte, _ bool) ([]byte, error) {
 	root, err := shared.DecodeJSONMap(rawJSON)
 	if err != nil {
 		return nil, err
 	}
+	// Anthropic server tools (web_search, code_execution, ...) cannot run on this target
+	if err := shared.StripClaudeServerTools(root); err != nil {
+		return nil, err
+	}
 
 	out := map[string]any{
 		"contents": []any{},
@@ -150,6 +159,9 @@ func (Transformer) TransformRequest(modelName string, rawJSON []byte, _ bool) ([
 
 	if tools := convertClaudeToolsToGeminiTools(root["tools"]); len(tools) > 0 {
 		out["tools"] = tools
+		if root["tool_choice"] != nil {
+			out["toolConfig"] = map[string]any{"functionCallingConfig": convertClaudeToolChoiceToGemini(root["tool_choice"])}
+		}
 	}
 
 	_ = modelName // model is in URL; keep signature for consistency
@@ -168,7 +180,7 @@ func (Transformer) TransformResponseStream(_ context.Context, _ string, _ []byte
 	s := (*state).(*geminiToClaudeStreamState)
 
 	line := bytes.TrimSpace(rawLine)
-	if len(line) == 0 {
+	if len(line) == 0 || s.failed {
 		return nil, nil
 	}
 
@@ -182,13 +194,18 @@ func (Transformer) TransformResponseStream(_ context.Context, _ string, _ []byte
 			return nil, nil
 		}
 		s.sentMessageStop = true
-		return []string{"event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n\n"}, nil
+		return []string{shared.SSEEvent("message_stop", map[string]any{"type": "message_stop"})}, nil
 	}
 
 	root, err := shared.DecodeJSONMap(payload)
 	if err != nil {
 		return nil, nil
 	}
+	// 上游在流中途返回 error 对象时，输出 Claude error 事件并结束流，避免客户端收到被截断的“成功”响应
+	if streamErr, ok := shared.DetectStreamError(root); ok {
+		s.failed = true
+		return []string{streamErr.ClaudeErrorEvent()}, nil
+	}
 
 	var outputs []string
 
@@ -230,8 +247,8 @@ func (Transformer) TransformResponseStream(_ context.Context, _ string, _ []byte
 						if len(args) == 0 {
 							args = []byte("{}")
 						}
-						outputs = append(outputs, s.ensureToolBlockStarted(name)...)
-						outputs = append(outputs, s.eventToolArgsDelta(string(args)))
+						// Gemini 的 functionCall 总是完整的一次调用，每个都对应一个独立的 tool_use 块
+						outputs = append(outputs, s.eventToolCall(name, string(args))...)
 						s.hasContent = true
 						continue
 					}
@@ -330,6 +347,7 @@ type geminiToClaudeStreamState struct {
 	hasContent     bool
 	usedTool       bool
 	sentMessageStop bool
+	failed          bool
 
 	toolBlockName string
 	toolBlockID   string
@@ -352,18 +370,14 @@ func (s *geminiToClaudeStreamState) eventMessageStart() string {
 			"content": []any{},
 		},
 	}
-	return shared.SSEEvent("message_start", msg) + "\n"
+	return shared.SSEEvent("message_start", msg)
 }
 
 func (s *geminiToClaudeStreamState) ensureTextBlockStarted() []string {
 	if s.responseType == 1 {
 		return nil
 	}
-	var outputs []string
-	if s.responseType == 3 {
-		outputs = append(outputs, shared.SSEEvent("content_block_stop", map[string]any{"type": "content_block_stop", "index": s.responseIndex})+"\n")
-		s.responseIndex++
-	}
+	outputs := s.closeBlock()
 	s.responseType = 1
 	outputs = append(outputs, shared.SSEEvent("content_block_start", map[string]any{
 		"type": "content_block_start",
@@ -372,7 +386,7 @@ func (s *geminiToClaudeStreamState) ensureTextBlockStarted() []string {
 			"type": "text",
 			"text": "",
 		},
-	})+"\n")
+	}))
 	return outputs
 }
 
@@ -384,25 +398,30 @@ func (s *geminiToClaudeStreamState) eventTextDelta(text string) string {
 			"type": "text_delta",
 			"text": text,
 		},
-	}) + "\n"
+	})
 }
 
-func (s *geminiToClaudeStreamState) ensureToolBlockStarted(name string) []string {
-	if s.responseType == 3 && strings.TrimSpace(name) == "" {
+// closeBlock 关闭当前打开的内容块，并推进到下一个块索引
+func (s *geminiToClaudeStreamState) closeBlock() []string {
+	if s.responseType == 0 {
 		return nil
 	}
-	var outputs []string
-	if s.responseType != 0 {
-		outputs = append(outputs, shared.SSEEvent("content_block_stop", map[string]any{"type": "content_block_stop", "index": s.responseIndex})+"\n")
-		s.responseIndex++
-	}
+	outputs := []string{shared.SSEEvent("content_block_stop", map[string]any{"type": "content_block_stop", "index": s.responseIndex})}
+	s.responseIndex++
+	s.responseType = 0
+	return outputs
+}
+
+// eventToolCall 输出一个完整的 tool_use 块：start、一次 input_json_delta 和 stop
+func (s *geminiToClaudeStreamState) eventToolCall(name, argsJSON string) []string {
+	outputs := s.closeBlock()
 
 	s.responseType = 3
 	s.toolBlockName = name
 	s.toolBlockID = "toolu_" + shared.RandomSuffix()
 
 	outputs = append(outputs, shared.SSEEvent("content_block_start", map[string]any{
-		"type": "content_block_start",
+		"type":  "content_block_start",
 		"index": s.responseIndex,
 		"content_block": map[string]any{
 			"type":  "tool_use",
@@ -410,19 +429,16 @@ func (s *geminiToClaudeStreamState) ensureToolBlockStarted(name string) []string
 			"name":  name,
 			"input": map[string]any{},
 		},
-	})+"\n")
-	return outputs
-}
-
-func (s *geminiToClaudeStreamState) eventToolArgsDelta(partialJSON string) string {
-	return shared.SSEEvent("content_block_delta", map[string]any{
+	}))
+	outputs = append(outputs, shared.SSEEvent("content_block_delta", map[string]any{
 		"type":  "content_block_delta",
 		"index": s.responseIndex,
 		"delta": map[string]any{
 			"type":         "input_json_delta",
-			"partial_json": partialJSON,
+			"partial_json": argsJSON,
 		},
-	}) + "\n"
+	}))
+	return append(outputs, s.closeBlock()...)
 }
 
 func (s *geminiToClaudeStreamState) finish(usage map[string]any) []string {
@@ -431,10 +447,7 @@ func (s *geminiToClaudeStreamState) finish(usage map[string]any) []string {
 	}
 	s.sentMessageStop = true
 
-	var outputs []string
-	if s.responseType != 0 {
-		outputs = append(outputs, shared.SSEEvent("content_block_stop", map[string]any{"type": "content_block_stop", "index": s.responseIndex})+"\n")
-	}
+	outputs := s.closeBlock()
 	stopReason := "end_turn"
 	if s.usedTool {
 		stopReason = "tool_use"
@@ -446,8 +459,8 @@ func (s *geminiToClaudeStreamState) finish(usage map[string]any) []string {
 			"stop_sequence": nil,
 		},
 		"usage": usage,
-	})+"\n")
-	outputs = append(outputs, "event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n\n")
+	}))
+	outputs = append(outputs, shared.SSEEvent("message_stop", map[string]any{"type": "message_stop"}))
 	return outputs
 }
 
@@ -516,3 +529,15 @@ func setNested(root map[string]any, path []string, value any) map[string]any {
 	return root
 }
 
+// convertClaudeToolChoiceToGemini maps a Claude tool_choice to a Gemini functionCallingConfig
+func convertClaudeToolChoiceToGemini(v any) map[string]any {
+	switch mode, name := shared.ClaudeToolChoice(v); mode {
+	case "any":
+		return map[string]any{"mode": "ANY"}
+	case "none":
+		return map[string]any{"mode": "NONE"}
+	case "tool":
+		return map[string]any{"mode": "ANY", "allowedFunctionNames": []any{name}}
+	}
+	return map[string]any{"mode": "AUTO"}
+}
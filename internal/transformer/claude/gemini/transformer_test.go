@@ -0,0 +1,121 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTransformResponseStream_MultipleFunctionCalls(t *testing.T) {
+	t.Parallel()
+
+	var state any
+	tr := Transformer{}
+
+	line := []byte(`data: {"responseId":"r1","modelVersion":"gemini-2.5-pro","candidates":[{"content":{"role":"model","parts":[{"text":"calling tools"},{"functionCall":{"name":"read","args":{"path":"a"}}},{"functionCall":{"name":"read","args":{"path":"b"}}}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":3,"candidatesTokenCount":4}}`)
+
+	outs, err := tr.TransformResponseStream(context.Background(), "gemini-2.5-pro", nil, nil, line, &state)
+	if err != nil {
+		t.Fatalf("TransformResponseStream err=%v", err)
+	}
+
+	type event struct {
+		Type         string         `json:"type"`
+		Index        int            `json:"index"`
+		ContentBlock map[string]any `json:"content_block"`
+		Delta        map[string]any `json:"delta"`
+	}
+	var events []event
+	for _, out := range strings.Split(strings.Join(outs, ""), "\n") {
+		if !strings.HasPrefix(out, "data: ") {
+			continue
+		}
+		var ev event
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(out, "data: ")), &ev); err != nil {
+			t.Fatalf("unmarshal %q: %v", out, err)
+		}
+		events = append(events, ev)
+	}
+
+	want := []struct {
+		typ   string
+		index int
+	}{
+		{"message_start", 0},
+		{"content_block_start", 0},
+		{"content_block_delta", 0},
+		{"content_block_stop", 0},
+		{"content_block_start", 1},
+		{"content_block_delta", 1},
+		{"content_block_stop", 1},
+		{"content_block_start", 2},
+		{"content_block_delta", 2},
+		{"content_block_stop", 2},
+		{"message_delta", 0},
+		{"message_stop", 0},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i, w := range want {
+		if events[i].Type != w.typ || events[i].Index != w.index {
+			t.Fatalf("event %d = %s[%d], want %s[%d]", i, events[i].Type, events[i].Index, w.typ, w.index)
+		}
+	}
+
+	if events[4].ContentBlock["type"] != "tool_use" || events[7].ContentBlock["type"] != "tool_use" {
+		t.Fatalf("expected tool_use blocks, got %v and %v", events[4].ContentBlock, events[7].ContentBlock)
+	}
+	if events[4].ContentBlock["id"] == events[7].ContentBlock["id"] {
+		t.Fatalf("tool blocks share id %v", events[4].ContentBlock["id"])
+	}
+	if events[5].Delta["partial_json"] != `{"path":"a"}` || events[8].Delta["partial_json"] != `{"path":"b"}` {
+		t.Fatalf("unexpected args: %v / %v", events[5].Delta, events[8].Delta)
+	}
+	if events[10].Delta["stop_reason"] != "tool_use" {
+		t.Fatalf("stop_reason=%v", events[10].Delta["stop_reason"])
+	}
+}
+
+func TestTransformRequest_ToolChoiceMapping(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		choice string
+		want   any
+	}{
+		{name: "missing", choice: "", want: nil},
+		{name: "auto", choice: `,"tool_choice":{"type":"auto"}`, want: map[string]any{"mode": "AUTO"}},
+		{name: "any", choice: `,"tool_choice":{"type":"any"}`, want: map[string]any{"mode": "ANY"}},
+		{name: "none", choice: `,"tool_choice":{"type":"none"}`, want: map[string]any{"mode": "NONE"}},
+		{name: "tool", choice: `,"tool_choice":{"type":"tool","name":"record"}`, want: map[string]any{"mode": "ANY", "allowedFunctionNames": []any{"record"}}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			raw := `{"model":"claude-x","max_tokens":64,"messages":[{"role":"user","content":"hi"}],` +
+				`"tools":[{"name":"record","input_schema":{"type":"object"}}]` + tc.choice + `}`
+			outBytes, err := Transformer{}.TransformRequest("gemini-x", []byte(raw), false)
+			if err != nil {
+				t.Fatalf("TransformRequest err=%v", err)
+			}
+			var out struct {
+				ToolConfig map[string]any `json:"toolConfig"`
+			}
+			if err := json.Unmarshal(outBytes, &out); err != nil {
+				t.Fatalf("unmarshal err=%v", err)
+			}
+			var got any
+			if out.ToolConfig != nil {
+				got = out.ToolConfig["functionCallingConfig"]
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("functionCallingConfig=%v want %v", got, tc.want)
+			}
+		})
+	}
+}
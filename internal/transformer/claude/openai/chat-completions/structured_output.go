@@ -0,0 +1,85 @@
+package chat_completions
+
+import (
+	"encoding/json"
+	"strings"
+
+	"clisimplehub/internal/transformer/shared"
+)
+
+// convertClaudeToolChoice maps a Claude tool_choice to the OpenAI Chat format
+func convertClaudeToolChoice(v any) any {
+	// OpenAI-style strings ("required", ...) sent by clients pass through unchanged
+	if s, ok := v.(string); ok {
+		return s
+	}
+	switch mode, name := shared.ClaudeToolChoice(v); mode {
+	case "any":
+		return "required"
+	case "none":
+		return "none"
+	case "tool":
+		return map[string]any{"type": "function", "function": map[string]any{"name": name}}
+	}
+	return "auto"
+}
+
+// forcedSingleTool returns the only tool of the request when tool_choice forces it
+// ("any" or "tool" naming it), i.e. the client is asking for structured output.
+func forcedSingleTool(root map[string]any) map[string]any {
+	tools, _ := root["tools"].([]any)
+	if len(tools) != 1 {
+		return nil
+	}
+	tool, _ := tools[0].(map[string]any)
+	name := strings.TrimSpace(shared.StringFromAny(tool["name"]))
+	if name == "" {
+		return nil
+	}
+	choice, _ := root["tool_choice"].(map[string]any)
+	switch strings.TrimSpace(shared.StringFromAny(choice["type"])) {
+	case "any":
+		return tool
+	case "tool":
+		if strings.TrimSpace(shared.StringFromAny(choice["name"])) == name {
+			return tool
+		}
+	}
+	return nil
+}
+
+// toolResponseFormat builds an OpenAI json_schema response_format from a Claude tool
+func toolResponseFormat(tool map[string]any) map[string]any {
+	schema, ok := tool["input_schema"].(map[string]any)
+	if !ok || len(schema) == 0 {
+		schema = map[string]any{"type": "object", "properties": map[string]any{}}
+	}
+	jsonSchema := map[string]any{
+		"name":   shared.StringFromAny(tool["name"]),
+		"schema": schema,
+	}
+	if d := shared.StringFromAny(tool["description"]); strings.TrimSpace(d) != "" {
+		jsonSchema["description"] = d
+	}
+	return map[string]any{"type": "json_schema", "json_schema": jsonSchema}
+}
+
+// structuredOutputToolName returns the tool name a json_schema response should be
+// converted back to, or "" when the upstream request did not use response_format.
+func structuredOutputToolName(requestRawJSON []byte) string {
+	if len(requestRawJSON) == 0 {
+		return ""
+	}
+	var req struct {
+		ResponseFormat struct {
+			Type       string `json:"type"`
+			JSONSchema struct {
+				Name string `json:"name"`
+			} `json:"json_schema"`
+		} `json:"response_format"`
+	}
+	if err := json.Unmarshal(requestRawJSON, &req); err != nil || req.ResponseFormat.Type != "json_schema" {
+		return ""
+	}
+	return strings.TrimSpace(req.ResponseFormat.JSONSchema.Name)
+}
@@ -11,7 +11,20 @@ import (
 	"clisimplehub/internal/transformer/shared"
 )
 
-type Transformer struct{}
+// Transformer converts Claude Messages requests to OpenAI Chat Completions.
+// StructuredOutput sends a forced single tool as a json_schema response_format
+// instead of a tool call, for upstreams with native structured-output support.
+//
+// A Claude message carries a single reply, so when the upstream returns several
+// choices (n>1, e.g. set through body overrides) only choice 0 is converted and
+// the others are dropped.
+//
+// DeveloperRole sends the Claude system prompt with the "developer" role, for
+// upstream models that no longer accept "system".
+type Transformer struct {
+	StructuredOutput bool
+	DeveloperRole    bool
+}
 
 func (Transformer) TargetInterfaceType() string { return "chat" }
 
@@ -24,11 +37,20 @@ func (Transformer) OutputContentType(isStreaming bool) string {
 	return "application/json"
 }
 
-func (Transformer) TransformRequest(modelName string, rawJSON []byte, stream bool) ([]byte, error) {
+// TransformErrorResponse converts an upstream error body into a Claude error response
+func (Transformer) TransformErrorResponse(statusCode int, rawJSON []byte) []byte {
+	return shared.ParseErrorBody(statusCode, rawJSON).ClaudeErrorResponse(statusCode)
+}
+
+func (t Transformer) TransformRequest(modelName string, rawJSON []byte, stream bool) ([]byte, error) {
 	root, err := shared.DecodeJSONMap(rawJSON)
 	if err != nil {
 		return nil, err
 	}
+	// Anthropic server tools (web_search, code_execution, ...) cannot run on this target
+	if err := shared.StripClaudeServerTools(root); err != nil {
+		return nil, err
+	}
 
 	out := make(map[string]any)
 	if v, ok := root["max_tokens"]; ok {
@@ -47,6 +69,13 @@ func (Transformer) TransformRequest(modelName string, rawJSON []byte, stream boo
 			out["stop"] = stops
 		}
 	}
+	// Claude has no logprobs; pass the OpenAI fields through for clients that add them.
+	if v, ok := root["logprobs"]; ok && v != nil {
+		out["logprobs"] = v
+	}
+	if v, ok := root["top_logprobs"]; ok && v != nil {
+		out["top_logprobs"] = v
+	}
 	out["stream"] = stream
 	if modelName != "" {
 		out["model"] = modelName
@@ -59,7 +88,7 @@ func (Transformer) TransformRequest(modelName string, rawJSON []byte, stream boo
 	openAIMessages := make([]any, 0)
 	if system := shared.BuildClaudeSystemText(root["system"]); strings.TrimSpace(system) != "" {
 		openAIMessages = append(openAIMessages, map[string]any{
-			"role":    "system",
+			"role":    shared.ChatInstructionRole(t.DeveloperRole),
 			"content": system,
 		})
 	}
@@ -149,19 +178,17 @@ func (Transformer) TransformRequest(modelName string, rawJSON []byte, stream boo
 
 	out["messages"] = openAIMessages
 
-	if tools := convertClaudeToolsToOpenAITools(root["tools"]); len(tools) > 0 {
+	if tool := forcedSingleTool(root); t.StructuredOutput && tool != nil {
+		out["response_format"] = toolResponseFormat(tool)
+	} else if tools := convertClaudeToolsToOpenAITools(root["tools"]); len(tools) > 0 {
 		out["tools"] = tools
-		if toolChoice, ok := root["tool_choice"]; ok {
-			out["tool_choice"] = toolChoice
-		} else {
-			out["tool_choice"] = "auto"
-		}
+		out["tool_choice"] = convertClaudeToolChoice(root["tool_choice"])
 	}
 
 	return json.Marshal(out)
 }
 
-func (Transformer) TransformResponseStream(_ context.Context, modelName string, _ []byte, _ []byte, rawLine []byte, state *any) ([]string, error) {
+func (Transformer) TransformResponseStream(_ context.Context, modelName string, _ []byte, requestRawJSON []byte, rawLine []byte, state *any) ([]string, error) {
 	if state == nil {
 		return nil, fmt.Errorf("nil transformer state")
 	}
@@ -169,12 +196,13 @@ func (Transformer) TransformResponseStream(_ context.Context, modelName string,
 		*state = &openAIToClaudeStreamState{
 			nextBlockIndex: 0,
 			toolBlocks:     make(map[int]*toolBlock),
+			structuredTool: structuredOutputToolName(requestRawJSON),
 		}
 	}
 	s := (*state).(*openAIToClaudeStreamState)
 
 	line := bytes.TrimSpace(rawLine)
-	if len(line) == 0 {
+	if len(line) == 0 || s.failed {
 		return nil, nil
 	}
 
@@ -191,6 +219,11 @@ func (Transformer) TransformResponseStream(_ context.Context, modelName string,
 	if err != nil {
 		return nil, nil
 	}
+	// An upstream error ends the stream with a Claude error event instead of a truncated message
+	if streamErr, ok := shared.DetectStreamError(root); ok {
+		s.failed = true
+		return []string{streamErr.ClaudeErrorEvent()}, nil
+	}
 
 	var outputs []string
 
@@ -209,11 +242,11 @@ func (Transformer) TransformResponseStream(_ context.Context, modelName string,
 		outputs = append(outputs, s.eventMessageStart())
 	}
 
-	choices, _ := root["choices"].([]any)
-	if len(choices) == 0 {
+	// Chunks of other choices (n>1) are ignored; see Transformer.
+	firstChoice := shared.ChoiceByIndex(root["choices"], 0)
+	if firstChoice == nil {
 		return outputs, nil
 	}
-	firstChoice, _ := choices[0].(map[string]any)
 
 	if finish := shared.StringFromAny(firstChoice["finish_reason"]); strings.TrimSpace(finish) != "" {
 		s.finishReason = finish
@@ -224,7 +257,18 @@ func (Transformer) TransformResponseStream(_ context.Context, modelName string,
 		return outputs, nil
 	}
 
-	if content := shared.StringFromAny(delta["content"]); content != "" {
+	if content := shared.StringFromAny(delta["content"]); content != "" && s.structuredTool != "" {
+		// json_schema output is streamed back to the client as the forced tool's input
+		tb := s.toolBlocks[-1]
+		if tb == nil {
+			tb = &toolBlock{index: -1, started: true, blockIndex: s.nextBlockIndex, id: "toolu_" + shared.RandomSuffix(), name: s.structuredTool}
+			s.toolBlocks[-1] = tb
+			s.nextBlockIndex++
+			outputs = append(outputs, s.eventToolUseStart(tb.blockIndex, tb.id, tb.name))
+		}
+		outputs = append(outputs, s.eventToolArgsDelta(tb.blockIndex, content))
+		s.hasContent = true
+	} else if content != "" {
 		outputs = append(outputs, s.ensureTextBlockStarted()...)
 		outputs = append(outputs, s.eventTextDelta(content))
 		s.hasContent = true
@@ -285,7 +329,7 @@ func (Transformer) TransformResponseStream(_ context.Context, modelName string,
 	return outputs, nil
 }
 
-func (Transformer) TransformResponseNonStream(_ context.Context, modelName string, _ []byte, _ []byte, rawJSON []byte, _ *any) ([]byte, error) {
+func (Transformer) TransformResponseNonStream(_ context.Context, modelName string, _ []byte, requestRawJSON []byte, rawJSON []byte, _ *any) ([]byte, error) {
 	root, err := shared.DecodeJSONMap(rawJSON)
 	if err != nil {
 		return nil, err
@@ -309,9 +353,7 @@ func (Transformer) TransformResponseNonStream(_ context.Context, modelName strin
 		usage = u
 	}
 
-	choices, _ := root["choices"].([]any)
-	if len(choices) > 0 {
-		c0, _ := choices[0].(map[string]any)
+	if c0 := shared.ChoiceByIndex(root["choices"], 0); c0 != nil {
 		finishReason = shared.StringFromAny(c0["finish_reason"])
 		msg, _ := c0["message"].(map[string]any)
 		if msg != nil {
@@ -344,6 +386,22 @@ func (Transformer) TransformResponseNonStream(_ context.Context, modelName strin
 		},
 	}
 
+	if name := structuredOutputToolName(requestRawJSON); name != "" && strings.TrimSpace(contentText) != "" {
+		input := map[string]any{}
+		if err := json.Unmarshal([]byte(contentText), &input); err == nil {
+			toolUses = append([]any{map[string]any{
+				"type":  "tool_use",
+				"id":    "toolu_" + shared.RandomSuffix(),
+				"name":  name,
+				"input": input,
+			}}, toolUses...)
+			contentText = ""
+			if finishReason == "stop" {
+				out["stop_reason"] = "tool_use"
+			}
+		}
+	}
+
 	var contentBlocks []any
 	if strings.TrimSpace(contentText) != "" {
 		contentBlocks = append(contentBlocks, map[string]any{"type": "text", "text": contentText})
@@ -373,6 +431,10 @@ type openAIToClaudeStreamState struct {
 	finishReason string
 	hasContent   bool
 	finished     bool
+	failed       bool
+
+	// structuredTool is the tool name json_schema content is streamed back as
+	structuredTool string
 }
 
 type toolBlock struct {
@@ -487,6 +549,9 @@ func (s *openAIToClaudeStreamState) finish(finalUsage map[string]any) []string {
 		}))
 	}
 
+	if s.structuredTool != "" && s.toolBlocks[-1] != nil && s.finishReason == "stop" {
+		s.finishReason = "tool_calls"
+	}
 	stopReason := mapOpenAIFinishReasonToClaudeStopReason(s.finishReason)
 	usage := map[string]any{"input_tokens": 0, "output_tokens": 0}
 	if finalUsage != nil {
@@ -504,7 +569,7 @@ func (s *openAIToClaudeStreamState) finish(finalUsage map[string]any) []string {
 		},
 		"usage": usage,
 	}))
-	outputs = append(outputs, "event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n")
+	outputs = append(outputs, shared.SSEEvent("message_stop", map[string]any{"type": "message_stop"}))
 
 	return outputs
 }
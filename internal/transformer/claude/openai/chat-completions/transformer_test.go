@@ -0,0 +1,222 @@
+package chat_completions
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const forcedToolRequest = `{
+	"model":"claude-x",
+	"max_tokens":64,
+	"messages":[{"role":"user","content":"extract"}],
+	"tools":[{"name":"record","description":"d","input_schema":{"type":"object","properties":{"a":{"type":"number"}}}}],
+	"tool_choice":{"type":"tool","name":"record"}
+}`
+
+func transformRequest(t *testing.T, tr Transformer, raw string) map[string]any {
+	t.Helper()
+	outBytes, err := tr.TransformRequest("gpt-x", []byte(raw), false)
+	if err != nil {
+		t.Fatalf("TransformRequest err=%v", err)
+	}
+	var out map[string]any
+	if err := json.Unmarshal(outBytes, &out); err != nil {
+		t.Fatalf("unmarshal err=%v", err)
+	}
+	return out
+}
+
+func TestTransformRequest_ForcedToolDefaultsToToolCalling(t *testing.T) {
+	t.Parallel()
+
+	out := transformRequest(t, Transformer{}, forcedToolRequest)
+	if _, ok := out["response_format"]; ok {
+		t.Fatalf("unexpected response_format: %v", out["response_format"])
+	}
+	tools, _ := out["tools"].([]any)
+	if len(tools) != 1 {
+		t.Fatalf("tools=%v", out["tools"])
+	}
+	choice, _ := out["tool_choice"].(map[string]any)
+	fn, _ := choice["function"].(map[string]any)
+	if choice["type"] != "function" || fn["name"] != "record" {
+		t.Fatalf("tool_choice=%v", out["tool_choice"])
+	}
+}
+
+func TestTransformRequest_ToolChoiceMapping(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		choice string
+		want   string
+	}{
+		{choice: `{"type":"auto"}`, want: "auto"},
+		{choice: `{"type":"any"}`, want: "required"},
+		{choice: `{"type":"none"}`, want: "none"},
+	}
+	for _, tc := range cases {
+		raw := strings.Replace(forcedToolRequest, `{"type":"tool","name":"record"}`, tc.choice, 1)
+		out := transformRequest(t, Transformer{}, raw)
+		if out["tool_choice"] != tc.want {
+			t.Fatalf("tool_choice(%s)=%v want %q", tc.choice, out["tool_choice"], tc.want)
+		}
+	}
+}
+
+func TestTransformRequest_ForcedToolAsJSONSchema(t *testing.T) {
+	t.Parallel()
+
+	out := transformRequest(t, Transformer{StructuredOutput: true}, forcedToolRequest)
+	if _, ok := out["tools"]; ok {
+		t.Fatalf("unexpected tools: %v", out["tools"])
+	}
+	if _, ok := out["tool_choice"]; ok {
+		t.Fatalf("unexpected tool_choice: %v", out["tool_choice"])
+	}
+	rf, _ := out["response_format"].(map[string]any)
+	js, _ := rf["json_schema"].(map[string]any)
+	schema, _ := js["schema"].(map[string]any)
+	if rf["type"] != "json_schema" || js["name"] != "record" || schema["type"] != "object" {
+		t.Fatalf("response_format=%v", out["response_format"])
+	}
+
+	// auto tool choice keeps the tool-calling path even with StructuredOutput enabled
+	raw := strings.Replace(forcedToolRequest, `{"type":"tool","name":"record"}`, `{"type":"auto"}`, 1)
+	if out := transformRequest(t, Transformer{StructuredOutput: true}, raw); out["response_format"] != nil || out["tools"] == nil {
+		t.Fatalf("auto choice: response_format=%v tools=%v", out["response_format"], out["tools"])
+	}
+}
+
+func TestTransformResponse_JSONSchemaContentBecomesToolUse(t *testing.T) {
+	t.Parallel()
+
+	tr := Transformer{StructuredOutput: true}
+	upstreamReq, err := tr.TransformRequest("gpt-x", []byte(forcedToolRequest), false)
+	if err != nil {
+		t.Fatalf("TransformRequest err=%v", err)
+	}
+
+	body := []byte(`{"id":"c1","model":"gpt-x","choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"{\"a\":1}"}}],"usage":{"prompt_tokens":3,"completion_tokens":2}}`)
+	converted, err := tr.TransformResponseNonStream(context.Background(), "gpt-x", []byte(forcedToolRequest), upstreamReq, body, nil)
+	if err != nil {
+		t.Fatalf("TransformResponseNonStream err=%v", err)
+	}
+	var msg map[string]any
+	if err := json.Unmarshal(converted, &msg); err != nil {
+		t.Fatalf("unmarshal err=%v", err)
+	}
+	content, _ := msg["content"].([]any)
+	if len(content) != 1 || msg["stop_reason"] != "tool_use" {
+		t.Fatalf("message=%s", converted)
+	}
+	block, _ := content[0].(map[string]any)
+	input, _ := block["input"].(map[string]any)
+	if block["type"] != "tool_use" || block["name"] != "record" || input["a"] != float64(1) {
+		t.Fatalf("block=%v", block)
+	}
+
+	var state any
+	var events []string
+	for _, line := range []string{
+		`data: {"id":"c1","model":"gpt-x","choices":[{"index":0,"delta":{"role":"assistant","content":"{\"a\":"}}]}`,
+		`data: {"id":"c1","model":"gpt-x","choices":[{"index":0,"delta":{"content":"1}"},"finish_reason":"stop"}]}`,
+		`data: [DONE]`,
+	} {
+		outs, err := tr.TransformResponseStream(context.Background(), "gpt-x", []byte(forcedToolRequest), upstreamReq, []byte(line), &state)
+		if err != nil {
+			t.Fatalf("TransformResponseStream err=%v", err)
+		}
+		events = append(events, outs...)
+	}
+	stream := strings.Join(events, "")
+	for _, want := range []string{`"type":"tool_use"`, `"name":"record"`, `"partial_json":"{\"a\":"`, `"partial_json":"1}"`, `"stop_reason":"tool_use"`} {
+		if !strings.Contains(stream, want) {
+			t.Fatalf("stream missing %s:\n%s", want, stream)
+		}
+	}
+	if strings.Contains(stream, `"text_delta"`) {
+		t.Fatalf("stream should not contain text deltas:\n%s", stream)
+	}
+}
+
+func TestTransformResponse_MultipleChoicesKeepsChoiceZero(t *testing.T) {
+	t.Parallel()
+
+	tr := Transformer{}
+
+	nonStream := []byte(`{"id":"c1","choices":[
+		{"index":1,"message":{"content":"second"},"finish_reason":"stop"},
+		{"index":0,"message":{"content":"first"},"finish_reason":"stop"}
+	]}`)
+	outBytes, err := tr.TransformResponseNonStream(context.Background(), "m", nil, nil, nonStream, nil)
+	if err != nil {
+		t.Fatalf("TransformResponseNonStream err=%v", err)
+	}
+	if !strings.Contains(string(outBytes), `"text":"first"`) || strings.Contains(string(outBytes), "second") {
+		t.Fatalf("non-stream content=%s", outBytes)
+	}
+
+	var state any
+	var out []string
+	for _, line := range []string{
+		`data: {"id":"c1","choices":[{"index":0,"delta":{"content":"a"}}]}`,
+		`data: {"id":"c1","choices":[{"index":1,"delta":{"content":"b"}}]}`,
+		`data: {"id":"c1","choices":[{"index":1,"delta":{},"finish_reason":"length"}]}`,
+		`data: {"id":"c1","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+		`data: [DONE]`,
+	} {
+		outs, err := tr.TransformResponseStream(context.Background(), "m", nil, nil, []byte(line), &state)
+		if err != nil {
+			t.Fatalf("TransformResponseStream err=%v", err)
+		}
+		out = append(out, outs...)
+	}
+	joined := strings.Join(out, "")
+	if !strings.Contains(joined, `"text":"a"`) || strings.Contains(joined, `"text":"b"`) {
+		t.Fatalf("stream text deltas=%s", joined)
+	}
+	if !strings.Contains(joined, `"stop_reason":"end_turn"`) {
+		t.Fatalf("stop reason should come from choice 0: %s", joined)
+	}
+}
+
+func TestTransformRequest_DeveloperRole(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name      string
+		developer bool
+		system    string
+		wantRole  string
+	}{
+		{name: "system role by default", system: `"be brief"`, wantRole: "system"},
+		{name: "developer role", developer: true, system: `"be brief"`, wantRole: "developer"},
+		{name: "developer role with system blocks", developer: true, system: `[{"type":"text","text":"be brief"}]`, wantRole: "developer"},
+	}
+	for _, tc := range cases {
+		raw := `{"model":"claude-x","max_tokens":64,"system":` + tc.system + `,"messages":[{"role":"user","content":"hi"}]}`
+		out := transformRequest(t, Transformer{DeveloperRole: tc.developer}, raw)
+		msgs, _ := out["messages"].([]any)
+		if len(msgs) != 2 {
+			t.Fatalf("%s: messages=%v", tc.name, out["messages"])
+		}
+		first, _ := msgs[0].(map[string]any)
+		if first["role"] != tc.wantRole || !strings.Contains(stringify(first["content"]), "be brief") {
+			t.Fatalf("%s: instruction message=%v want role %s", tc.name, first, tc.wantRole)
+		}
+		if second, _ := msgs[1].(map[string]any); second["role"] != "user" {
+			t.Fatalf("%s: user message=%v", tc.name, second)
+		}
+	}
+}
+
+func stringify(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
@@ -23,11 +23,20 @@ func (Transformer) OutputContentType(isStreaming bool) string {
 	return "application/json"
 }
 
+// TransformErrorResponse converts an upstream error body into a Claude error response
+func (Transformer) TransformErrorResponse(statusCode int, rawJSON []byte) []byte {
+	return shared.ParseErrorBody(statusCode, rawJSON).ClaudeErrorResponse(statusCode)
+}
+
 func (Transformer) TransformRequest(modelName string, rawJSON []byte, stream bool) ([]byte, error) {
 	root, err := shared.DecodeJSONMap(rawJSON)
 	if err != nil {
 		return nil, err
 	}
+	// Anthropic server tools (web_search, code_execution, ...) cannot run on this target
+	if err := shared.StripClaudeServerTools(root); err != nil {
+		return nil, err
+	}
 
 	out := make(map[string]any)
 	if strings.TrimSpace(modelName) != "" {
@@ -143,7 +152,7 @@ func (Transformer) TransformRequest(modelName string, rawJSON []byte, stream boo
 
 	if tools := convertClaudeToolsToResponsesTools(root["tools"]); len(tools) > 0 {
 		out["tools"] = tools
-		out["tool_choice"] = "auto"
+		out["tool_choice"] = convertClaudeToolChoiceToResponses(root["tool_choice"])
 	}
 
 	return json.Marshal(out)
@@ -159,7 +168,7 @@ func (Transformer) TransformResponseStream(_ context.Context, modelName string,
 	s := (*state).(*responsesToClaudeStreamState)
 
 	line := bytes.TrimSpace(rawLine)
-	if len(line) == 0 {
+	if len(line) == 0 || s.failed {
 		return nil, nil
 	}
 
@@ -173,13 +182,18 @@ func (Transformer) TransformResponseStream(_ context.Context, modelName string,
 			return nil, nil
 		}
 		s.sentMessageStop = true
-		return []string{"event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n"}, nil
+		return []string{shared.SSEEvent("message_stop", map[string]any{"type": "message_stop"})}, nil
 	}
 
 	root, err := shared.DecodeJSONMap(payload)
 	if err != nil {
 		return nil, nil
 	}
+	// "error" and "response.failed" end the stream with a Claude error event instead of a truncated message
+	if streamErr, ok := shared.DetectStreamError(root); ok {
+		s.failed = true
+		return []string{streamErr.ClaudeErrorEvent()}, nil
+	}
 
 	eventType := shared.StringFromAny(root["type"])
 	switch eventType {
@@ -303,7 +317,7 @@ func (Transformer) TransformResponseStream(_ context.Context, modelName string,
 		s.sentMessageStop = true
 		return []string{
 			shared.SSEEvent("message_delta", msgDelta),
-			"event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n",
+			shared.SSEEvent("message_stop", map[string]any{"type": "message_stop"}),
 		}, nil
 	default:
 		return nil, nil
@@ -326,6 +340,7 @@ func (Transformer) TransformResponseNonStream(_ context.Context, modelName strin
 type responsesToClaudeStreamState struct {
 	hasToolCall     bool
 	sentMessageStop bool
+	failed          bool
 }
 
 func buildClaudeMessageFromResponseObject(modelName string, response map[string]any) ([]byte, error) {
@@ -446,3 +461,16 @@ func convertClaudeToolsToResponsesTools(v any) []any {
 }
 
  
+
+// convertClaudeToolChoiceToResponses maps a Claude tool_choice to the Responses API format
+func convertClaudeToolChoiceToResponses(v any) any {
+	switch mode, name := shared.ClaudeToolChoice(v); mode {
+	case "any":
+		return "required"
+	case "none":
+		return "none"
+	case "tool":
+		return map[string]any{"type": "function", "name": name}
+	}
+	return "auto"
+}
@@ -0,0 +1,42 @@
+package responses
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestTransformRequest_ToolChoiceMapping(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		choice string
+		want   any
+	}{
+		{name: "missing", choice: "", want: "auto"},
+		{name: "auto", choice: `,"tool_choice":{"type":"auto"}`, want: "auto"},
+		{name: "any", choice: `,"tool_choice":{"type":"any"}`, want: "required"},
+		{name: "none", choice: `,"tool_choice":{"type":"none"}`, want: "none"},
+		{name: "tool", choice: `,"tool_choice":{"type":"tool","name":"record"}`, want: map[string]any{"type": "function", "name": "record"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			raw := `{"model":"claude-x","max_tokens":64,"messages":[{"role":"user","content":"hi"}],` +
+				`"tools":[{"name":"record","input_schema":{"type":"object"}}]` + tc.choice + `}`
+			outBytes, err := Transformer{}.TransformRequest("gpt-x", []byte(raw), false)
+			if err != nil {
+				t.Fatalf("TransformRequest err=%v", err)
+			}
+			var out map[string]any
+			if err := json.Unmarshal(outBytes, &out); err != nil {
+				t.Fatalf("unmarshal err=%v", err)
+			}
+			if !reflect.DeepEqual(out["tool_choice"], tc.want) {
+				t.Fatalf("tool_choice=%v want %v", out["tool_choice"], tc.want)
+			}
+		})
+	}
+}
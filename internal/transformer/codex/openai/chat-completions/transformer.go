@@ -5,15 +5,22 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
+	"clisimplehub/internal/logger"
 	"clisimplehub/internal/transformer/shared"
 )
 
 // Transformer implements: OpenAI Responses ("codex") <-> OpenAI Chat Completions ("chat").
 // Use-case: client talks /v1/responses, upstream only supports /v1/chat/completions.
-type Transformer struct{}
+// With n>1 every choice becomes its own message output item; choice 0 keeps output_index 0.
+// Instructions and "system"/"developer" input messages are sent with the "system" role, or
+// with "developer" when DeveloperRole is set.
+type Transformer struct {
+	DeveloperRole bool
+}
 
 func (Transformer) TargetInterfaceType() string { return "chat" }
 
@@ -26,7 +33,12 @@ func (Transformer) OutputContentType(isStreaming bool) string {
 	return "application/json"
 }
 
-func (Transformer) TransformRequest(modelName string, rawJSON []byte, stream bool) ([]byte, error) {
+// TransformErrorResponse converts an upstream error body into an OpenAI error response
+func (Transformer) TransformErrorResponse(statusCode int, rawJSON []byte) []byte {
+	return shared.ParseErrorBody(statusCode, rawJSON).OpenAIErrorResponse(statusCode)
+}
+
+func (t Transformer) TransformRequest(modelName string, rawJSON []byte, stream bool) ([]byte, error) {
 	root, err := shared.DecodeJSONMap(rawJSON)
 	if err != nil {
 		return nil, err
@@ -52,11 +64,22 @@ func (Transformer) TransformRequest(modelName string, rawJSON []byte, stream boo
 	if v := root["tool_choice"]; v != nil {
 		out["tool_choice"] = v
 	}
+	if v := root["n"]; v != nil {
+		out["n"] = v
+	}
+	// Responses asks for logprobs via top_logprobs or include; Chat needs logprobs=true.
+	if v := root["top_logprobs"]; v != nil {
+		out["logprobs"] = true
+		out["top_logprobs"] = v
+	}
+	if includesOutputLogprobs(root["include"]) {
+		out["logprobs"] = true
+	}
 
 	msgs := out["messages"].([]any)
 
 	if instructions := shared.StringFromAny(root["instructions"]); strings.TrimSpace(instructions) != "" {
-		msgs = append(msgs, map[string]any{"role": "system", "content": instructions})
+		msgs = append(msgs, map[string]any{"role": shared.ChatInstructionRole(t.DeveloperRole), "content": instructions})
 	}
 
 	// Convert `input` -> `messages`
@@ -83,6 +106,7 @@ func (Transformer) TransformRequest(modelName string, rawJSON []byte, stream boo
 				if role == "" {
 					continue
 				}
+				role = shared.NormalizeChatInstructionRole(role, t.DeveloperRole)
 
 				contentVal := item["content"]
 				if s, ok := contentVal.(string); ok {
@@ -156,20 +180,45 @@ func (Transformer) TransformRequest(modelName string, rawJSON []byte, stream boo
 
 	out["messages"] = msgs
 
-	if tools := convertResponsesToolsToChatTools(root["tools"]); len(tools) > 0 {
+	tools, webSearch, dropped := convertResponsesToolsToChatTools(root["tools"])
+	if len(tools) > 0 {
 		out["tools"] = tools
 	}
+	if webSearch != nil {
+		out["web_search_options"] = webSearch
+	}
+	if len(dropped) > 0 {
+		logger.Warn("[Transformer] responses->chat: dropped tools unsupported by Chat Completions: %s", strings.Join(dropped, ", "))
+	}
+	if choice, ok := out["tool_choice"].(map[string]any); ok {
+		switch choiceType := strings.TrimSpace(shared.StringFromAny(choice["type"])); choiceType {
+		case "", "function", "custom", "allowed_tools":
+		default:
+			// hosted tools are not chat tools: web_search is enabled by web_search_options, the rest were dropped
+			if webSearch == nil || !isResponsesWebSearchTool(choiceType) {
+				return nil, fmt.Errorf("tool_choice %s unsupported by Chat Completions", choiceType)
+			}
+			delete(out, "tool_choice")
+		}
+	}
+	if len(tools) == 0 {
+		// Chat Completions rejects tool_choice/parallel_tool_calls without tools (e.g. "required" with only file_search)
+		delete(out, "tool_choice")
+		delete(out, "parallel_tool_calls")
+	}
 
 	return json.Marshal(out)
 }
 
-func (Transformer) TransformResponseStream(_ context.Context, modelName string, _ []byte, _ []byte, rawLine []byte, state *any) ([]string, error) {
+func (Transformer) TransformResponseStream(_ context.Context, modelName string, _ []byte, requestRawJSON []byte, rawLine []byte, state *any) ([]string, error) {
 	if state == nil {
 		return nil, fmt.Errorf("nil transformer state")
 	}
 	if *state == nil {
 		*state = &chatToResponsesState{
-			toolCalls: make(map[int]*toolCallState),
+			toolCalls:       make(map[int]*toolCallState),
+			expectedChoices: requestedChoiceCount(requestRawJSON),
+			extraChoices:    make(map[int]*extraChoiceState),
 		}
 	}
 	st := (*state).(*chatToResponsesState)
@@ -197,8 +246,14 @@ func (Transformer) TransformResponseStream(_ context.Context, modelName string,
 	if err != nil {
 		return nil, nil
 	}
+	// An upstream error ends the stream with response.failed instead of a truncated "completed" response
+	if streamErr, ok := shared.DetectStreamError(root); ok {
+		return st.failResponse(modelName, streamErr), nil
+	}
 
-	if usage, ok := root["usage"].(map[string]any); ok {
+	usage, hasUsage := root["usage"].(map[string]any)
+	if hasUsage {
+		st.usageSeen = true
 		st.usagePrompt = int64(shared.IntFromAny(usage["prompt_tokens"]))
 		st.usageCompletion = int64(shared.IntFromAny(usage["completion_tokens"]))
 		st.usageCached = int64(shared.IntFromAny(getNestedInt(usage, "prompt_tokens_details", "cached_tokens")))
@@ -208,19 +263,20 @@ func (Transformer) TransformResponseStream(_ context.Context, modelName string,
 		}
 	}
 
-	choices, _ := root["choices"].([]any)
-	if len(choices) == 0 {
-		return nil, nil
-	}
-	c0, _ := choices[0].(map[string]any)
-	if c0 == nil {
+	// OpenAI sends usage in a trailing chunk after finish_reason: complete the response now
+	if st.finished {
+		if hasUsage {
+			return st.completeResponse(modelName), nil
+		}
 		return nil, nil
 	}
-	delta, _ := c0["delta"].(map[string]any)
-	if delta == nil {
+
+	choices, _ := root["choices"].([]any)
+	if len(choices) == 0 {
 		return nil, nil
 	}
 
+	var out []string
 	if !st.started {
 		st.started = true
 		st.responseID = strings.TrimSpace(shared.StringFromAny(root["id"]))
@@ -238,28 +294,72 @@ func (Transformer) TransformResponseStream(_ context.Context, modelName string,
 		st.msgItemID = "msg_" + st.responseID + "_0"
 		st.nextOutputIndex = 0
 
-		return []string{
+		out = append(out,
 			st.eventResponseCreated(),
-			st.eventMessageItemAdded(),
-			st.eventMessageContentPartAdded(),
-		}, nil
+			st.eventMessageItemAdded(st.msgItemID, 0),
+			st.eventMessageContentPartAdded(st.msgItemID, 0),
+		)
+	}
+
+	// With n>1 choices other than 0 are buffered and sent as extra message items on finish.
+	for _, cRaw := range choices {
+		c, _ := cRaw.(map[string]any)
+		if c == nil {
+			continue
+		}
+		if idx := shared.ChoiceIndex(c); idx > 0 {
+			st.feedExtraChoice(idx, c)
+			continue
+		}
+		out = append(out, st.feedPrimaryChoice(c)...)
+	}
+
+	if st.allChoicesDone() {
+		out = append(out, st.closeOutputItems()...)
+		if st.usageSeen {
+			out = append(out, st.completeResponse(modelName)...)
+		}
+	}
+
+	return out, nil
+}
+
+// FinishResponseStream completes a response whose stream ended without [DONE]
+func (Transformer) FinishResponseStream(_ context.Context, modelName string, state *any) []string {
+	if state == nil || *state == nil {
+		return nil
+	}
+	st, ok := (*state).(*chatToResponsesState)
+	if !ok || !st.started {
+		return nil
+	}
+	return st.finish(modelName)
+}
+
+// feedPrimaryChoice converts a choice 0 delta into Responses events
+func (s *chatToResponsesState) feedPrimaryChoice(c0 map[string]any) []string {
+	delta, _ := c0["delta"].(map[string]any)
+	if delta == nil {
+		delta = map[string]any{}
 	}
 
 	var out []string
 
 	// content delta -> response.output_text.delta
 	if content := shared.StringFromAny(delta["content"]); content != "" {
-		out = append(out, st.eventOutputTextDelta(content))
-		st.textBuf.WriteString(content)
+		logprobs := chatLogprobs(c0)
+		out = append(out, s.eventOutputTextDelta(s.msgItemID, 0, content, logprobs))
+		s.textBuf.WriteString(content)
+		s.logprobs = append(s.logprobs, logprobs...)
 	}
 
 	// tool_calls -> convert to response.function_call_arguments.delta
 	if tcsAny, ok := delta["tool_calls"]; ok {
 		if tcs, ok := tcsAny.([]any); ok {
 			// Ensure message is closed before tool call output items (match common Responses ordering).
-			if !st.msgDone {
-				out = append(out, st.eventCloseMessageItem())
-				st.msgDone = true
+			if !s.msgDone {
+				out = append(out, s.eventCloseMessageItem())
+				s.msgDone = true
 			}
 			for _, tcRaw := range tcs {
 				tc, _ := tcRaw.(map[string]any)
@@ -267,13 +367,13 @@ func (Transformer) TransformResponseStream(_ context.Context, modelName string,
 					continue
 				}
 				tcIndex := shared.IntFromAny(tc["index"])
-				call := st.toolCalls[tcIndex]
+				call := s.toolCalls[tcIndex]
 				if call == nil {
 					call = &toolCallState{
-						outputIndex: st.nextOutputIndex + 1,
+						outputIndex: s.nextOutputIndex + 1,
 					}
-					st.nextOutputIndex = call.outputIndex
-					st.toolCalls[tcIndex] = call
+					s.nextOutputIndex = call.outputIndex
+					s.toolCalls[tcIndex] = call
 				}
 
 				if id := shared.StringFromAny(tc["id"]); strings.TrimSpace(id) != "" {
@@ -292,25 +392,55 @@ func (Transformer) TransformResponseStream(_ context.Context, modelName string,
 
 				if !call.started && strings.TrimSpace(call.name) != "" {
 					call.started = true
-					call.itemID = fmt.Sprintf("fc_%s_%d", st.responseID, call.outputIndex)
-					out = append(out, st.eventFunctionItemAdded(call))
+					call.itemID = fmt.Sprintf("fc_%s_%d", s.responseID, call.outputIndex)
+					out = append(out, s.eventFunctionItemAdded(call))
 				}
 				if call.started && function != nil {
 					if argsDelta := shared.StringFromAny(function["arguments"]); argsDelta != "" {
-						out = append(out, st.eventFunctionArgsDelta(call, argsDelta))
+						out = append(out, s.eventFunctionArgsDelta(call, argsDelta))
 					}
 				}
 			}
 		}
 	}
 
-	finishReason := shared.StringFromAny(c0["finish_reason"])
-	if strings.TrimSpace(finishReason) != "" {
-		st.finishReason = finishReason
-		out = append(out, st.finish(modelName)...)
+	if finishReason := shared.StringFromAny(c0["finish_reason"]); strings.TrimSpace(finishReason) != "" {
+		s.finishReason = finishReason
+		s.choice0Done = true
 	}
 
-	return out, nil
+	return out
+}
+
+// feedExtraChoice buffers the text of a choice other than 0 (n>1). Its tool calls are not
+// converted, for the same reason as in extraChoiceMessageItems.
+func (s *chatToResponsesState) feedExtraChoice(index int, c map[string]any) {
+	extra := s.extraChoices[index]
+	if extra == nil {
+		extra = &extraChoiceState{}
+		s.extraChoices[index] = extra
+	}
+	if delta, ok := c["delta"].(map[string]any); ok {
+		extra.text.WriteString(shared.StringFromAny(delta["content"]))
+	}
+	extra.logprobs = append(extra.logprobs, chatLogprobs(c)...)
+	if strings.TrimSpace(shared.StringFromAny(c["finish_reason"])) != "" {
+		extra.done = true
+	}
+}
+
+// allChoicesDone reports whether choice 0 and every other requested choice have finished
+func (s *chatToResponsesState) allChoicesDone() bool {
+	if !s.choice0Done {
+		return false
+	}
+	done := 0
+	for _, extra := range s.extraChoices {
+		if extra.done {
+			done++
+		}
+	}
+	return done >= s.expectedChoices-1
 }
 
 func (Transformer) TransformResponseNonStream(_ context.Context, modelName string, _ []byte, _ []byte, rawJSON []byte, _ *any) ([]byte, error) {
@@ -335,10 +465,11 @@ func (Transformer) TransformResponseNonStream(_ context.Context, modelName strin
 	var contentText string
 	var toolCalls []map[string]any
 	var finishReason string
+	logprobs := []any{}
 
-	if choices, ok := root["choices"].([]any); ok && len(choices) > 0 {
-		c0, _ := choices[0].(map[string]any)
+	if c0 := shared.ChoiceByIndex(root["choices"], 0); c0 != nil {
 		finishReason = shared.StringFromAny(c0["finish_reason"])
+		logprobs = append(logprobs, chatLogprobs(c0)...)
 		if msg, ok := c0["message"].(map[string]any); ok {
 			contentText = shared.StringFromAny(msg["content"])
 			if tcAny, ok := msg["tool_calls"]; ok {
@@ -372,7 +503,7 @@ func (Transformer) TransformResponseNonStream(_ context.Context, modelName strin
 			map[string]any{
 				"type":        "output_text",
 				"annotations": []any{},
-				"logprobs":    []any{},
+				"logprobs":    logprobs,
 				"text":        contentText,
 			},
 		},
@@ -400,6 +531,8 @@ func (Transformer) TransformResponseNonStream(_ context.Context, modelName strin
 		})
 	}
 
+	output = append(output, extraChoiceMessageItems(root["choices"], id)...)
+
 	resp := map[string]any{
 		"id":                 id,
 		"object":             "response",
@@ -432,18 +565,32 @@ type chatToResponsesState struct {
 	msgItemID string
 	msgDone   bool
 	textBuf   strings.Builder
+	logprobs  []any
 
 	toolCalls map[int]*toolCallState
 
+	// n>1: choice 0 streams live, the other choices are buffered until finish
+	expectedChoices int
+	choice0Done     bool
+	extraChoices    map[int]*extraChoiceState
+
 	finishReason string
-	finished     bool
+	finished     bool // output items closed
+	completed    bool // response.completed sent; waits for the trailing usage chunk or [DONE]
 
+	usageSeen       bool
 	usagePrompt     int64
 	usageCompletion int64
 	usageCached     int64
 	usageReasoning  int64
 }
 
+type extraChoiceState struct {
+	text     strings.Builder
+	logprobs []any
+	done     bool
+}
+
 type toolCallState struct {
 	started     bool
 	itemID      string
@@ -476,13 +623,13 @@ func (s *chatToResponsesState) eventResponseCreated() string {
 	return shared.SSEEvent("response.created", payload)
 }
 
-func (s *chatToResponsesState) eventMessageItemAdded() string {
+func (s *chatToResponsesState) eventMessageItemAdded(itemID string, outputIndex int) string {
 	payload := map[string]any{
 		"type":            "response.output_item.added",
 		"sequence_number": s.nextSeq(),
-		"output_index":    0,
+		"output_index":    outputIndex,
 		"item": map[string]any{
-			"id":      s.msgItemID,
+			"id":      itemID,
 			"type":    "message",
 			"status":  "in_progress",
 			"role":    "assistant",
@@ -492,12 +639,12 @@ func (s *chatToResponsesState) eventMessageItemAdded() string {
 	return shared.SSEEvent("response.output_item.added", payload)
 }
 
-func (s *chatToResponsesState) eventMessageContentPartAdded() string {
+func (s *chatToResponsesState) eventMessageContentPartAdded(itemID string, outputIndex int) string {
 	payload := map[string]any{
 		"type":            "response.content_part.added",
 		"sequence_number": s.nextSeq(),
-		"item_id":         s.msgItemID,
-		"output_index":    0,
+		"item_id":         itemID,
+		"output_index":    outputIndex,
 		"content_index":   0,
 		"part": map[string]any{
 			"type":        "output_text",
@@ -509,49 +656,58 @@ func (s *chatToResponsesState) eventMessageContentPartAdded() string {
 	return shared.SSEEvent("response.content_part.added", payload)
 }
 
-func (s *chatToResponsesState) eventOutputTextDelta(delta string) string {
+func (s *chatToResponsesState) eventOutputTextDelta(itemID string, outputIndex int, delta string, logprobs []any) string {
+	if logprobs == nil {
+		logprobs = []any{}
+	}
 	payload := map[string]any{
 		"type":            "response.output_text.delta",
 		"sequence_number": s.nextSeq(),
-		"item_id":         s.msgItemID,
-		"output_index":    0,
+		"item_id":         itemID,
+		"output_index":    outputIndex,
 		"content_index":   0,
 		"delta":           delta,
-		"logprobs":        []any{},
+		"logprobs":        logprobs,
 	}
 	return shared.SSEEvent("response.output_text.delta", payload)
 }
 
 func (s *chatToResponsesState) eventCloseMessageItem() string {
-	full := s.textBuf.String()
+	return s.eventMessageItemDone(s.msgItemID, 0, s.textBuf.String(), s.logprobs)
+}
+
+func (s *chatToResponsesState) eventMessageItemDone(itemID string, outputIndex int, full string, logprobs []any) string {
+	if logprobs == nil {
+		logprobs = []any{}
+	}
 	doneText := map[string]any{
 		"type":            "response.output_text.done",
 		"sequence_number": s.nextSeq(),
-		"item_id":         s.msgItemID,
-		"output_index":    0,
+		"item_id":         itemID,
+		"output_index":    outputIndex,
 		"content_index":   0,
 		"text":            full,
-		"logprobs":        []any{},
+		"logprobs":        logprobs,
 	}
 	partDone := map[string]any{
 		"type":            "response.content_part.done",
 		"sequence_number": s.nextSeq(),
-		"item_id":         s.msgItemID,
-		"output_index":    0,
+		"item_id":         itemID,
+		"output_index":    outputIndex,
 		"content_index":   0,
 		"part": map[string]any{
 			"type":        "output_text",
 			"annotations": []any{},
-			"logprobs":    []any{},
+			"logprobs":    logprobs,
 			"text":        full,
 		},
 	}
 	itemDone := map[string]any{
 		"type":            "response.output_item.done",
 		"sequence_number": s.nextSeq(),
-		"output_index":    0,
+		"output_index":    outputIndex,
 		"item": map[string]any{
-			"id":     s.msgItemID,
+			"id":     itemID,
 			"type":   "message",
 			"status": "completed",
 			"role":   "assistant",
@@ -559,7 +715,7 @@ func (s *chatToResponsesState) eventCloseMessageItem() string {
 				map[string]any{
 					"type":        "output_text",
 					"annotations": []any{},
-					"logprobs":    []any{},
+					"logprobs":    logprobs,
 					"text":        full,
 				},
 			},
@@ -600,7 +756,13 @@ func (s *chatToResponsesState) eventFunctionArgsDelta(call *toolCallState, delta
 	return shared.SSEEvent("response.function_call_arguments.delta", payload)
 }
 
+// finish closes any open output items and sends response.completed
 func (s *chatToResponsesState) finish(modelName string) []string {
+	return append(s.closeOutputItems(), s.completeResponse(modelName)...)
+}
+
+// closeOutputItems closes the message, tool call and extra choice items
+func (s *chatToResponsesState) closeOutputItems() []string {
 	if s.finished {
 		return nil
 	}
@@ -638,6 +800,17 @@ func (s *chatToResponsesState) finish(modelName string) []string {
 		out = append(out, shared.SSEEvent("response.output_item.done", itemDone))
 	}
 
+	out = append(out, s.extraChoiceItems()...)
+	return out
+}
+
+// completeResponse sends response.completed with the usage seen so far
+func (s *chatToResponsesState) completeResponse(modelName string) []string {
+	if s.completed {
+		return nil
+	}
+	s.completed = true
+
 	model := strings.TrimSpace(modelName)
 	if model == "" {
 		model = s.model
@@ -661,21 +834,155 @@ func (s *chatToResponsesState) finish(modelName string) []string {
 			},
 		},
 	}
-	out = append(out, shared.SSEEvent("response.completed", completed))
+	return []string{shared.SSEEvent("response.completed", completed)}
+}
+
+// failResponse emits response.failed for an upstream stream error; later chunks and [DONE] produce nothing
+func (s *chatToResponsesState) failResponse(modelName string, streamErr *shared.StreamError) []string {
+	if s.completed {
+		return nil
+	}
+	s.completed = true
+	s.finished = true
+
+	if s.responseID == "" {
+		s.responseID = "resp_" + shared.RandomSuffix()
+		s.createdAt = time.Now().Unix()
+	}
+	model := strings.TrimSpace(modelName)
+	if model == "" {
+		model = s.model
+	}
+	code := streamErr.Code
+	if code == "" {
+		code = "server_error"
+	}
+	failed := map[string]any{
+		"type":            "response.failed",
+		"sequence_number": s.nextSeq(),
+		"response": map[string]any{
+			"id":         s.responseID,
+			"object":     "response",
+			"created_at": s.createdAt,
+			"status":     "failed",
+			"error": map[string]any{
+				"code":    code,
+				"message": streamErr.Message,
+			},
+			"model":  model,
+			"output": []any{},
+		},
+	}
+	return []string{shared.SSEEvent("response.failed", failed)}
+}
+
+// extraChoiceItems emits the buffered choices other than 0 as complete message items, in choice order
+func (s *chatToResponsesState) extraChoiceItems() []string {
+	indexes := make([]int, 0, len(s.extraChoices))
+	for idx := range s.extraChoices {
+		indexes = append(indexes, idx)
+	}
+	sort.Ints(indexes)
+
+	var out []string
+	for _, idx := range indexes {
+		extra := s.extraChoices[idx]
+		itemID := fmt.Sprintf("msg_%s_%d", s.responseID, idx)
+		s.nextOutputIndex++
+		outputIndex := s.nextOutputIndex
+
+		out = append(out,
+			s.eventMessageItemAdded(itemID, outputIndex),
+			s.eventMessageContentPartAdded(itemID, outputIndex),
+		)
+		if text := extra.text.String(); text != "" {
+			out = append(out, s.eventOutputTextDelta(itemID, outputIndex, text, extra.logprobs))
+		}
+		out = append(out, s.eventMessageItemDone(itemID, outputIndex, extra.text.String(), extra.logprobs))
+	}
 	return out
 }
 
-func convertResponsesToolsToChatTools(v any) []any {
+// extraChoiceMessageItems converts the choices other than 0 of an n>1 response into extra
+// message items, in choice order. Their tool calls are not converted: they are alternatives
+// to choice 0's calls, and a Responses client would execute every function_call item.
+func extraChoiceMessageItems(choices any, id string) []any {
+	arr, _ := choices.([]any)
+	extras := make([]map[string]any, 0, len(arr))
+	for _, raw := range arr {
+		if c, ok := raw.(map[string]any); ok && shared.ChoiceIndex(c) > 0 {
+			extras = append(extras, c)
+		}
+	}
+	sort.SliceStable(extras, func(i, j int) bool {
+		return shared.ChoiceIndex(extras[i]) < shared.ChoiceIndex(extras[j])
+	})
+
+	items := make([]any, 0, len(extras))
+	for _, c := range extras {
+		msg, _ := c["message"].(map[string]any)
+		logprobs := append([]any{}, chatLogprobs(c)...)
+		items = append(items, map[string]any{
+			"id":     fmt.Sprintf("msg_%s_%d", id, shared.ChoiceIndex(c)),
+			"type":   "message",
+			"status": "completed",
+			"role":   "assistant",
+			"content": []any{
+				map[string]any{
+					"type":        "output_text",
+					"annotations": []any{},
+					"logprobs":    logprobs,
+					"text":        shared.StringFromAny(msg["content"]),
+				},
+			},
+		})
+	}
+	return items
+}
+
+// requestedChoiceCount returns the n of the upstream chat request (at least 1)
+func requestedChoiceCount(requestRawJSON []byte) int {
+	root, err := shared.DecodeJSONMap(requestRawJSON)
+	if err != nil {
+		return 1
+	}
+	if n := shared.IntFromAny(root["n"]); n > 1 {
+		return n
+	}
+	return 1
+}
+
+// convertResponsesToolsToChatTools converts Responses tools to Chat Completions tools.
+// Function and custom tools carry over; a web_search tool becomes web_search_options.
+// Other hosted tools (file_search, code_interpreter, ...) have no Chat equivalent and are
+// returned by type in dropped.
+func convertResponsesToolsToChatTools(v any) (tools []any, webSearch map[string]any, dropped []string) {
 	toolsArr, ok := v.([]any)
 	if !ok {
-		return nil
+		return nil, nil, nil
 	}
-	out := make([]any, 0, len(toolsArr))
+	tools = make([]any, 0, len(toolsArr))
 	for _, t := range toolsArr {
 		tool, _ := t.(map[string]any)
 		if tool == nil {
 			continue
 		}
+		toolType := strings.TrimSpace(shared.StringFromAny(tool["type"]))
+		switch {
+		case toolType == "" || toolType == "function":
+		case toolType == "custom":
+			if custom := convertResponsesCustomTool(tool); custom != nil {
+				tools = append(tools, custom)
+			}
+			continue
+		case isResponsesWebSearchTool(toolType):
+			webSearch = convertResponsesWebSearchTool(tool)
+			continue
+		default:
+			dropped = append(dropped, toolType)
+			continue
+		}
+
 		name := strings.TrimSpace(shared.StringFromAny(tool["name"]))
 		if name == "" {
 			continue
@@ -691,9 +998,76 @@ func convertResponsesToolsToChatTools(v any) []any {
 		} else {
 			fn["parameters"] = map[string]any{"type": "object", "properties": map[string]any{}}
 		}
-		out = append(out, map[string]any{"type": "function", "function": fn})
+		if strict, ok := tool["strict"].(bool); ok {
+			fn["strict"] = strict
+		}
+		tools = append(tools, map[string]any{"type": "function", "function": fn})
 	}
-	return out
+	return tools, webSearch, dropped
+}
+
+// isResponsesWebSearchTool reports whether a Responses tool type is web search
+// (web_search, web_search_preview and their dated variants)
+func isResponsesWebSearchTool(toolType string) bool {
+	return strings.HasPrefix(toolType, "web_search")
+}
+
+// convertResponsesCustomTool converts a Responses custom (free-form input) tool to the Chat shape
+func convertResponsesCustomTool(tool map[string]any) map[string]any {
+	name := strings.TrimSpace(shared.StringFromAny(tool["name"]))
+	if name == "" {
+		return nil
+	}
+	custom := map[string]any{"name": name}
+	if d := strings.TrimSpace(shared.StringFromAny(tool["description"])); d != "" {
+		custom["description"] = d
+	}
+	if format, ok := tool["format"].(map[string]any); ok {
+		custom["format"] = format
+	}
+	return map[string]any{"type": "custom", "custom": custom}
+}
+
+// convertResponsesWebSearchTool maps a Responses web_search tool to Chat web_search_options.
+// Chat nests the approximate location fields under user_location.approximate.
+func convertResponsesWebSearchTool(tool map[string]any) map[string]any {
+	opts := map[string]any{}
+	if size := strings.TrimSpace(shared.StringFromAny(tool["search_context_size"])); size != "" {
+		opts["search_context_size"] = size
+	}
+	if loc, ok := tool["user_location"].(map[string]any); ok {
+		approximate := map[string]any{}
+		for _, key := range []string{"city", "country", "region", "timezone"} {
+			if v := strings.TrimSpace(shared.StringFromAny(loc[key])); v != "" {
+				approximate[key] = v
+			}
+		}
+		if len(approximate) > 0 {
+			opts["user_location"] = map[string]any{"type": "approximate", "approximate": approximate}
+		}
+	}
+	return opts
+}
+
+// includesOutputLogprobs reports whether a Responses include list asks for output text logprobs
+func includesOutputLogprobs(v any) bool {
+	for _, item := range shared.StringListFromAny(v) {
+		if strings.TrimSpace(item) == "message.output_text.logprobs" {
+			return true
+		}
+	}
+	return false
+}
+
+// chatLogprobs returns the per-token logprobs of a chat choice. Chat and Responses share the
+// {token, logprob, bytes, top_logprobs} entry shape, so entries are carried over unchanged.
+func chatLogprobs(choice map[string]any) []any {
+	lp, _ := choice["logprobs"].(map[string]any)
+	if lp == nil {
+		return nil
+	}
+	content, _ := lp["content"].([]any)
+	return content
 }
 
 func doneJoin(parts ...string) string {
@@ -75,3 +75,349 @@ func TestTransformResponseStream_ChatToResponses(t *testing.T) {
 	}
 }
 
+func TestTransformResponseStream_TrailingUsage(t *testing.T) {
+	t.Parallel()
+
+	tr := Transformer{}
+	finishChunk := `data: {"id":"c1","choices":[{"index":0,"delta":{"content":"hi"},"finish_reason":"stop"}],"usage":null}`
+	usageChunk := `data: {"id":"c1","choices":[],"usage":{"prompt_tokens":7,"completion_tokens":5,"completion_tokens_details":{"reasoning_tokens":3}}}`
+
+	tests := []struct {
+		name  string
+		lines []string
+		// finishStream simulates the upstream closing the stream without [DONE]
+		finishStream bool
+		wantUsage    string
+	}{
+		{name: "usage after finish_reason", lines: []string{finishChunk, usageChunk, `data: [DONE]`}, wantUsage: `"reasoning_tokens":3`},
+		{name: "usage in finish chunk", lines: []string{strings.Replace(finishChunk, `"usage":null`, `"usage":{"prompt_tokens":7,"completion_tokens":5}`, 1)}, wantUsage: `"output_tokens":5`},
+		{name: "no usage until DONE", lines: []string{finishChunk, `data: [DONE]`}, wantUsage: `"output_tokens":0`},
+		{name: "stream ends without DONE", lines: []string{finishChunk}, finishStream: true, wantUsage: `"output_tokens":0`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var state any
+			var events []string
+			for _, line := range tt.lines {
+				outs, err := tr.TransformResponseStream(context.Background(), "m", nil, nil, []byte(line), &state)
+				if err != nil {
+					t.Fatalf("TransformResponseStream err=%v", err)
+				}
+				events = append(events, outs...)
+			}
+			if tt.finishStream {
+				events = append(events, tr.FinishResponseStream(context.Background(), "m", &state)...)
+			}
+
+			joined := strings.Join(events, "")
+			if strings.Count(joined, "event: response.completed") != 1 {
+				t.Fatalf("want exactly one response.completed: %s", joined)
+			}
+			completed := joined[strings.Index(joined, "event: response.completed"):]
+			if !strings.Contains(completed, tt.wantUsage) {
+				t.Fatalf("completed usage missing %s: %s", tt.wantUsage, completed)
+			}
+		})
+	}
+}
+
+func TestTransform_Logprobs(t *testing.T) {
+	t.Parallel()
+
+	tr := Transformer{}
+
+	reqCases := []struct {
+		name    string
+		raw     string
+		wantLP  any
+		wantTop any
+	}{
+		{name: "top_logprobs", raw: `{"input":"hi","top_logprobs":3}`, wantLP: true, wantTop: float64(3)},
+		{name: "include", raw: `{"input":"hi","include":["message.output_text.logprobs"]}`, wantLP: true},
+		{name: "absent", raw: `{"input":"hi"}`},
+	}
+	for _, tc := range reqCases {
+		outBytes, err := tr.TransformRequest("m", []byte(tc.raw), false)
+		if err != nil {
+			t.Fatalf("%s: TransformRequest err=%v", tc.name, err)
+		}
+		var out map[string]any
+		if err := json.Unmarshal(outBytes, &out); err != nil {
+			t.Fatalf("%s: unmarshal out: %v", tc.name, err)
+		}
+		if out["logprobs"] != tc.wantLP || out["top_logprobs"] != tc.wantTop {
+			t.Fatalf("%s: logprobs=%v top_logprobs=%v", tc.name, out["logprobs"], out["top_logprobs"])
+		}
+	}
+
+	nonStream := []byte(`{"id":"c1","choices":[{"message":{"content":"hi"},"logprobs":{"content":[{"token":"hi","logprob":-0.1,"bytes":[104,105],"top_logprobs":[]}]},"finish_reason":"stop"}]}`)
+	outBytes, err := tr.TransformResponseNonStream(context.Background(), "m", nil, nil, nonStream, nil)
+	if err != nil {
+		t.Fatalf("TransformResponseNonStream err=%v", err)
+	}
+	if !strings.Contains(string(outBytes), `"logprobs":[{"bytes":[104,105],"logprob":-0.1,"token":"hi","top_logprobs":[]}]`) {
+		t.Fatalf("non-stream logprobs missing: %s", outBytes)
+	}
+
+	var state any
+	var out []string
+	for _, line := range []string{
+		`data: {"id":"c1","choices":[{"delta":{"role":"assistant"}}]}`,
+		`data: {"id":"c1","choices":[{"delta":{"content":"hi"},"logprobs":{"content":[{"token":"hi","logprob":-0.1}]}}]}`,
+		`data: {"id":"c1","choices":[{"delta":{"content":"!"}}]}`,
+		`data: {"id":"c1","choices":[{"delta":{},"finish_reason":"stop"}]}`,
+	} {
+		outs, err := tr.TransformResponseStream(context.Background(), "m", nil, nil, []byte(line), &state)
+		if err != nil {
+			t.Fatalf("TransformResponseStream err=%v", err)
+		}
+		out = append(out, outs...)
+	}
+	joined := strings.Join(out, "")
+	if got := strings.Count(joined, `"logprobs":[{"logprob":-0.1,"token":"hi"}]`); got != 4 {
+		t.Fatalf("stream logprobs count=%d (delta, text done, part done, item done): %s", got, joined)
+	}
+}
+
+func TestTransform_MultipleChoices(t *testing.T) {
+	t.Parallel()
+
+	tr := Transformer{}
+
+	nonStream := []byte(`{"id":"c1","choices":[
+		{"index":1,"message":{"content":"second"},"finish_reason":"stop"},
+		{"index":0,"message":{"content":"first"},"finish_reason":"stop"}
+	]}`)
+	outBytes, err := tr.TransformResponseNonStream(context.Background(), "m", nil, nil, nonStream, nil)
+	if err != nil {
+		t.Fatalf("TransformResponseNonStream err=%v", err)
+	}
+	var resp struct {
+		Output []struct {
+			ID      string `json:"id"`
+			Content []struct {
+				Text string `json:"text"`
+			} `json:"content"`
+		} `json:"output"`
+	}
+	if err := json.Unmarshal(outBytes, &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(resp.Output) != 2 || resp.Output[0].Content[0].Text != "first" || resp.Output[1].Content[0].Text != "second" || resp.Output[1].ID != "msg_c1_1" {
+		t.Fatalf("non-stream output=%s", outBytes)
+	}
+
+	var state any
+	var out []string
+	for _, line := range []string{
+		`data: {"id":"c1","choices":[{"index":0,"delta":{"role":"assistant","content":"a"}}]}`,
+		`data: {"id":"c1","choices":[{"index":1,"delta":{"role":"assistant","content":"b"}}]}`,
+		`data: {"id":"c1","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+		`data: {"id":"c1","choices":[{"index":1,"delta":{"content":"c"},"finish_reason":"stop"}]}`,
+		`data: [DONE]`,
+	} {
+		outs, err := tr.TransformResponseStream(context.Background(), "m", nil, []byte(`{"n":2}`), []byte(line), &state)
+		if err != nil {
+			t.Fatalf("TransformResponseStream err=%v", err)
+		}
+		out = append(out, outs...)
+	}
+	joined := strings.Join(out, "")
+	if strings.Count(joined, "event: response.completed") != 1 {
+		t.Fatalf("want exactly one response.completed: %s", joined)
+	}
+	if !strings.Contains(joined, `"text":"a"`) {
+		t.Fatalf("choice 0 text missing: %s", joined)
+	}
+	if !strings.Contains(joined, `"id":"msg_c1_1"`) || !strings.Contains(joined, `"text":"bc"`) {
+		t.Fatalf("choice 1 item missing: %s", joined)
+	}
+	if strings.Index(joined, `"text":"bc"`) > strings.Index(joined, "event: response.completed") {
+		t.Fatalf("choice 1 emitted after response.completed: %s", joined)
+	}
+}
+
+func TestTransformRequest_InstructionRoles(t *testing.T) {
+	t.Parallel()
+
+	raw := []byte(`{
+		"instructions":"sys",
+		"input":[
+			{"type":"message","role":"developer","content":[{"type":"input_text","text":"dev"}]},
+			{"type":"message","role":"system","content":"sys2"},
+			{"type":"message","role":"user","content":"hi"}
+		]
+	}`)
+
+	cases := []struct {
+		name      string
+		developer bool
+		want      []string
+	}{
+		{name: "system", want: []string{"system", "system", "system", "user"}},
+		{name: "developer", developer: true, want: []string{"developer", "developer", "developer", "user"}},
+	}
+	for _, tc := range cases {
+		outBytes, err := (Transformer{DeveloperRole: tc.developer}).TransformRequest("m", raw, false)
+		if err != nil {
+			t.Fatalf("%s: TransformRequest err=%v", tc.name, err)
+		}
+		var out struct {
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.Unmarshal(outBytes, &out); err != nil {
+			t.Fatalf("%s: unmarshal out: %v", tc.name, err)
+		}
+		if len(out.Messages) != len(tc.want) {
+			t.Fatalf("%s: messages=%+v", tc.name, out.Messages)
+		}
+		for i, role := range tc.want {
+			if out.Messages[i].Role != role {
+				t.Fatalf("%s: messages[%d].role=%q want %q", tc.name, i, out.Messages[i].Role, role)
+			}
+		}
+		if out.Messages[1].Content != "dev" {
+			t.Fatalf("%s: developer content=%q", tc.name, out.Messages[1].Content)
+		}
+	}
+}
+
+func TestTransformRequest_HostedTools(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name       string
+		raw        string
+		wantTools  []string
+		wantSearch bool
+		wantChoice bool
+		wantErr    bool
+	}{
+		{
+			name: "mixed",
+			raw: `{"input":"hi","tools":[
+				{"type":"function","name":"f","parameters":{"type":"object"}},
+				{"type":"custom","name":"c","description":"free"},
+				{"type":"web_search_preview","search_context_size":"low","user_location":{"type":"approximate","city":"Paris"}},
+				{"type":"file_search","vector_store_ids":["vs"]}
+			]}`,
+			wantTools:  []string{"function", "custom"},
+			wantSearch: true,
+		},
+		{
+			name:       "forced web search",
+			raw:        `{"input":"hi","tools":[{"type":"web_search"}],"tool_choice":{"type":"web_search"}}`,
+			wantSearch: true,
+		},
+		{
+			name:       "forced function",
+			raw:        `{"input":"hi","tools":[{"type":"function","name":"f"}],"tool_choice":{"type":"function","name":"f"}}`,
+			wantTools:  []string{"function"},
+			wantChoice: true,
+		},
+		{
+			name: "required with only dropped tools",
+			raw:  `{"input":"hi","tools":[{"type":"file_search","vector_store_ids":["vs"]}],"tool_choice":"required","parallel_tool_calls":true}`,
+		},
+		{
+			name:       "required with function",
+			raw:        `{"input":"hi","tools":[{"type":"function","name":"f"},{"type":"file_search"}],"tool_choice":"required"}`,
+			wantTools:  []string{"function"},
+			wantChoice: true,
+		},
+		{
+			name:       "auto with only web search",
+			raw:        `{"input":"hi","tools":[{"type":"web_search"}],"tool_choice":"auto"}`,
+			wantSearch: true,
+		},
+		{
+			name:    "forced dropped tool",
+			raw:     `{"input":"hi","tools":[{"type":"code_interpreter"}],"tool_choice":{"type":"code_interpreter"}}`,
+			wantErr: true,
+		},
+	}
+	for _, tc := range cases {
+		outBytes, err := (Transformer{}).TransformRequest("m", []byte(tc.raw), false)
+		if tc.wantErr {
+			if err == nil {
+				t.Fatalf("%s: expected error", tc.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: TransformRequest err=%v", tc.name, err)
+		}
+		var out struct {
+			Tools []struct {
+				Type string `json:"type"`
+			} `json:"tools"`
+			WebSearch         map[string]any `json:"web_search_options"`
+			ToolChoice        any            `json:"tool_choice"`
+			ParallelToolCalls any            `json:"parallel_tool_calls"`
+		}
+		if err := json.Unmarshal(outBytes, &out); err != nil {
+			t.Fatalf("%s: unmarshal out: %v", tc.name, err)
+		}
+		if len(out.Tools) != len(tc.wantTools) {
+			t.Fatalf("%s: tools=%+v", tc.name, out.Tools)
+		}
+		for i, typ := range tc.wantTools {
+			if out.Tools[i].Type != typ {
+				t.Fatalf("%s: tools[%d].type=%q want %q", tc.name, i, out.Tools[i].Type, typ)
+			}
+		}
+		if (out.WebSearch != nil) != tc.wantSearch {
+			t.Fatalf("%s: web_search_options=%v", tc.name, out.WebSearch)
+		}
+		if (out.ToolChoice != nil) != tc.wantChoice {
+			t.Fatalf("%s: tool_choice=%v", tc.name, out.ToolChoice)
+		}
+		if len(out.Tools) == 0 && out.ParallelToolCalls != nil {
+			t.Fatalf("%s: parallel_tool_calls kept without tools", tc.name)
+		}
+	}
+
+	outBytes, err := (Transformer{}).TransformRequest("m", []byte(`{"input":"hi","tools":[{"type":"web_search","search_context_size":"low","user_location":{"type":"approximate","city":"Paris"}}]}`), false)
+	if err != nil {
+		t.Fatalf("TransformRequest err=%v", err)
+	}
+	if !strings.Contains(string(outBytes), `"web_search_options":{"search_context_size":"low","user_location":{"approximate":{"city":"Paris"},"type":"approximate"}}`) {
+		t.Fatalf("web_search_options not mapped: %s", outBytes)
+	}
+}
+
+func TestTransformResponseStream_UpstreamError(t *testing.T) {
+	t.Parallel()
+
+	var state any
+	var events []string
+	for _, line := range []string{
+		`data: {"id":"c1","choices":[{"index":0,"delta":{"content":"hel"}}]}`,
+		`data: {"error":{"message":"boom","code":"server_error"}}`,
+		`data: [DONE]`,
+	} {
+		out, err := (Transformer{}).TransformResponseStream(context.Background(), "m", nil, nil, []byte(line), &state)
+		if err != nil {
+			t.Fatalf("TransformResponseStream err=%v", err)
+		}
+		events = append(events, out...)
+	}
+	if extra := (Transformer{}).FinishResponseStream(context.Background(), "m", &state); len(extra) != 0 {
+		t.Fatalf("FinishResponseStream after failure=%v", extra)
+	}
+
+	last := events[len(events)-1]
+	if !strings.HasPrefix(last, "event: response.failed") || !strings.Contains(last, `"message":"boom"`) {
+		t.Fatalf("last event=%q want response.failed", last)
+	}
+	for _, ev := range events {
+		if strings.HasPrefix(ev, "event: response.completed") {
+			t.Fatalf("response.completed emitted after upstream error: %v", events)
+		}
+	}
+}
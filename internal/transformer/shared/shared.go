@@ -5,9 +5,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
+// SSE line endings for events emitted by transformers (config sseLineEnding)
+const (
+	SSELineEndingLF   = "lf"
+	SSELineEndingCRLF = "crlf"
+)
+
+var sseCRLF atomic.Bool
+
 func DecodeJSONMap(raw []byte) (map[string]any, error) {
 	var out map[string]any
 	dec := json.NewDecoder(bytes.NewReader(raw))
@@ -52,26 +61,74 @@ func SSEDataPayload(line []byte) ([]byte, bool) {
 	return bytes.TrimSpace(line[5:]), true
 }
 
+// SetSSELineEnding selects the line ending of emitted SSE events; anything but "crlf" means "lf"
+func SetSSELineEnding(mode string) {
+	sseCRLF.Store(strings.EqualFold(strings.TrimSpace(mode), SSELineEndingCRLF))
+}
+
+// SSELineEnding returns the configured SSE line ending, "\n" or "\r\n"
+func SSELineEnding() string {
+	if sseCRLF.Load() {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// SSEFrame joins SSE field lines into one event terminated by exactly one blank line
+func SSEFrame(fields ...string) string {
+	eol := SSELineEnding()
+	var b strings.Builder
+	for _, field := range fields {
+		b.WriteString(field)
+		b.WriteString(eol)
+	}
+	b.WriteString(eol)
+	return b.String()
+}
+
 func SSEEvent(event string, data any) string {
 	b, _ := json.Marshal(data)
-	return "event: " + event + "\n" + "data: " + string(b) + "\n\n"
+	return SSEFrame("event: "+event, "data: "+string(b))
+}
+
+// ChatInstructionRole returns the Chat Completions role carrying system instructions:
+// newer OpenAI models take "developer" in place of "system".
+func ChatInstructionRole(developer bool) string {
+	if developer {
+		return "developer"
+	}
+	return "system"
 }
 
+// NormalizeChatInstructionRole maps "system" and "developer" to ChatInstructionRole(developer);
+// other roles are returned unchanged.
+func NormalizeChatInstructionRole(role string, developer bool) string {
+	switch role {
+	case "system", "developer":
+		return ChatInstructionRole(developer)
+	default:
+		return role
+	}
+}
+
+// BuildClaudeSystemText flattens a Claude "system" value (string, text block or array
+// of text blocks) into plain text; cache_control and non-text blocks are dropped.
 func BuildClaudeSystemText(v any) string {
 	switch s := v.(type) {
 	case string:
 		return s
+	case map[string]any:
+		return claudeSystemBlockText(s)
 	case []any:
 		var b strings.Builder
 		for _, item := range s {
-			m, _ := item.(map[string]any)
-			if m == nil {
-				continue
-			}
-			if strings.TrimSpace(StringFromAny(m["type"])) != "text" {
-				continue
+			var t string
+			switch block := item.(type) {
+			case string:
+				t = block
+			case map[string]any:
+				t = claudeSystemBlockText(block)
 			}
-			t := StringFromAny(m["text"])
 			if strings.TrimSpace(t) == "" {
 				continue
 			}
@@ -86,6 +143,14 @@ func BuildClaudeSystemText(v any) string {
 	}
 }
 
+// claudeSystemBlockText returns the text of a system block; a missing type is treated as text
+func claudeSystemBlockText(block map[string]any) string {
+	if typ := strings.TrimSpace(StringFromAny(block["type"])); typ != "" && typ != "text" {
+		return ""
+	}
+	return StringFromAny(block["text"])
+}
+
 func RandomSuffix() string {
 	return fmt.Sprintf("%d", time.Now().UnixNano())
 }
@@ -104,3 +169,91 @@ func StringListFromAny(v any) []string {
 	return out
 }
 
+// ClaudeToolChoice normalizes a Claude tool_choice into its mode ("auto", "any", "none"
+// or "tool") and, for "tool", the forced tool name. Missing or unknown values are "auto".
+func ClaudeToolChoice(v any) (mode string, name string) {
+	switch tc := v.(type) {
+	case string:
+		mode = strings.TrimSpace(tc)
+	case map[string]any:
+		mode = strings.TrimSpace(StringFromAny(tc["type"]))
+		name = strings.TrimSpace(StringFromAny(tc["name"]))
+	}
+	switch mode {
+	case "any", "none":
+		return mode, ""
+	case "tool":
+		if name != "" {
+			return mode, name
+		}
+	}
+	return "auto", ""
+}
+
+// claudeServerToolPrefixes are the versioned type prefixes of Anthropic server tools,
+// which Anthropic runs itself and other providers cannot execute
+var claudeServerToolPrefixes = []string{"web_search_", "web_fetch_", "code_execution_"}
+
+// IsClaudeServerTool reports whether a Claude tool definition is an Anthropic server tool
+// such as {"type": "web_search_20250305", "name": "web_search"}.
+func IsClaudeServerTool(tool map[string]any) bool {
+	typ := strings.TrimSpace(StringFromAny(tool["type"]))
+	for _, prefix := range claudeServerToolPrefixes {
+		if strings.HasPrefix(typ, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// StripClaudeServerTools removes Anthropic server tools from a Claude request so it can be
+// sent to a provider that cannot run them. A tool_choice left without tools is dropped;
+// a tool_choice forcing a removed server tool is an error.
+func StripClaudeServerTools(root map[string]any) error {
+	tools, ok := root["tools"].([]any)
+	if !ok {
+		return nil
+	}
+	kept := make([]any, 0, len(tools))
+	removed := make(map[string]bool)
+	for _, t := range tools {
+		tool, _ := t.(map[string]any)
+		if tool != nil && IsClaudeServerTool(tool) {
+			removed[strings.TrimSpace(StringFromAny(tool["name"]))] = true
+			continue
+		}
+		kept = append(kept, t)
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+
+	if mode, name := ClaudeToolChoice(root["tool_choice"]); mode == "tool" && removed[name] {
+		return fmt.Errorf("server tool %s unsupported by this endpoint", name)
+	}
+	if len(kept) == 0 {
+		delete(root, "tools")
+		delete(root, "tool_choice")
+		return nil
+	}
+	root["tools"] = kept
+	return nil
+}
+
+// ChoiceIndex returns the index of an OpenAI Chat Completions choice; a missing index counts as 0.
+func ChoiceIndex(choice map[string]any) int {
+	return IntFromAny(choice["index"])
+}
+
+// ChoiceByIndex returns the choice with the given index from a Chat Completions choices
+// array, or nil. Streaming chunks of n>1 requests carry one choice each, so the first
+// array element is not necessarily choice 0.
+func ChoiceByIndex(choices any, index int) map[string]any {
+	arr, _ := choices.([]any)
+	for _, raw := range arr {
+		if c, ok := raw.(map[string]any); ok && ChoiceIndex(c) == index {
+			return c
+		}
+	}
+	return nil
+}
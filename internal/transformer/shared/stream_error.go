@@ -0,0 +1,207 @@
+package shared
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// StreamError is an error reported by the upstream inside a streaming response
+type StreamError struct {
+	Type    string
+	Code    string
+	Message string
+}
+
+// DetectStreamError recognizes an upstream error carried in a stream payload:
+// an OpenAI/Gemini {"error":{...}} object, a Responses "error" event or a
+// "response.failed" event. ok is false for regular payloads.
+func DetectStreamError(root map[string]any) (*StreamError, bool) {
+	if root == nil {
+		return nil, false
+	}
+
+	switch StringFromAny(root["type"]) {
+	case "error":
+		if obj, ok := root["error"].(map[string]any); ok {
+			return streamErrorFromObject(obj), true
+		}
+		return streamErrorFromObject(root), true
+	case "response.failed":
+		resp, _ := root["response"].(map[string]any)
+		obj, _ := resp["error"].(map[string]any)
+		if obj == nil {
+			obj = map[string]any{}
+		}
+		return streamErrorFromObject(obj), true
+	}
+
+	if obj, ok := root["error"].(map[string]any); ok && len(obj) > 0 {
+		return streamErrorFromObject(obj), true
+	}
+	return nil, false
+}
+
+func streamErrorFromObject(obj map[string]any) *StreamError {
+	e := &StreamError{
+		Type:    strings.TrimSpace(StringFromAny(obj["type"])),
+		Message: strings.TrimSpace(StringFromAny(obj["message"])),
+	}
+	switch code := obj["code"].(type) {
+	case string:
+		e.Code = strings.TrimSpace(code)
+	case nil:
+	default:
+		e.Code = strings.TrimSpace(fmt.Sprint(code))
+	}
+	// Gemini reports the canonical status (e.g. RESOURCE_EXHAUSTED) next to the numeric code
+	if e.Type == "" {
+		e.Type = strings.TrimSpace(StringFromAny(obj["status"]))
+	}
+	if e.Message == "" {
+		e.Message = "upstream stream error"
+	}
+	return e
+}
+
+// ClaudeErrorType maps the upstream error to a Claude error type
+func (e *StreamError) ClaudeErrorType() string {
+	tag := strings.ToLower(e.Type + " " + e.Code)
+	switch {
+	case strings.Contains(tag, "overloaded"), strings.Contains(tag, "unavailable"), strings.Contains(tag, "529"), strings.Contains(tag, "503"):
+		return "overloaded_error"
+	case strings.Contains(tag, "rate_limit"), strings.Contains(tag, "resource_exhausted"), strings.Contains(tag, "429"):
+		return "rate_limit_error"
+	case strings.Contains(tag, "invalid_request"), strings.Contains(tag, "invalid_argument"), strings.Contains(tag, "400"):
+		return "invalid_request_error"
+	default:
+		return "api_error"
+	}
+}
+
+// ClaudeErrorEvent returns the Claude SSE "error" event for the upstream error
+func (e *StreamError) ClaudeErrorEvent() string {
+	return SSEEvent("error", map[string]any{
+		"type": "error",
+		"error": map[string]any{
+			"type":    e.ClaudeErrorType(),
+			"message": e.Message,
+		},
+	})
+}
+
+// maxErrorMessageBytes caps a non-JSON upstream error body used as the error message
+const maxErrorMessageBytes = 1024
+
+// ParseErrorBody extracts the upstream error from a non-streaming error response body.
+// Bodies without a recognizable error object (plain text, HTML, foreign JSON) become the message.
+func ParseErrorBody(statusCode int, raw []byte) *StreamError {
+	if root, err := DecodeJSONMap(raw); err == nil {
+		if e, ok := DetectStreamError(root); ok {
+			return e
+		}
+	}
+	message := strings.TrimSpace(string(raw))
+	if len(message) > maxErrorMessageBytes {
+		message = message[:maxErrorMessageBytes] + "...(truncated)"
+	}
+	if message == "" {
+		message = http.StatusText(statusCode)
+	}
+	if message == "" {
+		message = "upstream error"
+	}
+	return &StreamError{Message: message}
+}
+
+// HTTPStatus returns the status code for an error the upstream reported with HTTP 200:
+// a numeric HTTP error code when present, otherwise one derived from the error type
+func (e *StreamError) HTTPStatus() int {
+	if code, err := strconv.Atoi(e.Code); err == nil && code >= 400 && code <= 599 {
+		return code
+	}
+	tag := strings.ToLower(e.Type + " " + e.Code)
+	switch {
+	case strings.Contains(tag, "invalid_request"), strings.Contains(tag, "invalid_argument"):
+		return http.StatusBadRequest
+	case strings.Contains(tag, "authentication"), strings.Contains(tag, "unauthenticated"), strings.Contains(tag, "invalid_api_key"):
+		return http.StatusUnauthorized
+	case strings.Contains(tag, "permission"):
+		return http.StatusForbidden
+	case strings.Contains(tag, "not_found"):
+		return http.StatusNotFound
+	case strings.Contains(tag, "rate_limit"), strings.Contains(tag, "resource_exhausted"):
+		return http.StatusTooManyRequests
+	case strings.Contains(tag, "overloaded"), strings.Contains(tag, "unavailable"):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// ClaudeErrorResponse returns the Claude error body for an upstream error with the given status
+func (e *StreamError) ClaudeErrorResponse(statusCode int) []byte {
+	errType := e.ClaudeErrorType()
+	switch {
+	case statusCode == http.StatusBadRequest:
+		errType = "invalid_request_error"
+	case statusCode == http.StatusUnauthorized:
+		errType = "authentication_error"
+	case statusCode == http.StatusForbidden:
+		errType = "permission_error"
+	case statusCode == http.StatusNotFound:
+		errType = "not_found_error"
+	case statusCode == http.StatusRequestEntityTooLarge:
+		errType = "request_too_large"
+	case statusCode == http.StatusTooManyRequests:
+		errType = "rate_limit_error"
+	case statusCode == 529 || statusCode == http.StatusServiceUnavailable:
+		errType = "overloaded_error"
+	case statusCode >= 500 && errType != "overloaded_error":
+		errType = "api_error"
+	}
+	data, _ := json.Marshal(map[string]any{
+		"type": "error",
+		"error": map[string]any{
+			"type":    errType,
+			"message": e.Message,
+		},
+	})
+	return data
+}
+
+// OpenAIErrorResponse returns the OpenAI error body for an upstream error with the given status
+func (e *StreamError) OpenAIErrorResponse(statusCode int) []byte {
+	errType := e.Type
+	if errType == "" {
+		switch {
+		case statusCode == http.StatusUnauthorized:
+			errType = "authentication_error"
+		case statusCode == http.StatusForbidden:
+			errType = "permission_error"
+		case statusCode == http.StatusNotFound:
+			errType = "not_found_error"
+		case statusCode == http.StatusTooManyRequests:
+			errType = "rate_limit_error"
+		case statusCode >= 500:
+			errType = "server_error"
+		default:
+			errType = "invalid_request_error"
+		}
+	}
+	var code any
+	if e.Code != "" {
+		code = e.Code
+	}
+	data, _ := json.Marshal(map[string]any{
+		"error": map[string]any{
+			"message": e.Message,
+			"type":    errType,
+			"param":   nil,
+			"code":    code,
+		},
+	})
+	return data
+}
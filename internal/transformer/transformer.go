@@ -22,6 +22,19 @@ type Transformer interface {
 	OutputContentType(isStreaming bool) string
 }
 
+// StreamFinisher is implemented by transformers that buffer the end of a response
+// (e.g. until a trailing usage chunk). It is called once the upstream stream ends and
+// returns whatever is still pending, so streams that end without [DONE] still complete.
+type StreamFinisher interface {
+	FinishResponseStream(ctx context.Context, modelName string, state *any) []string
+}
+
+// ErrorResponder is implemented by transformers that convert an upstream error response into
+// the client's error shape. statusCode is the status the client receives.
+type ErrorResponder interface {
+	TransformErrorResponse(statusCode int, rawJSON []byte) []byte
+}
+
 func Get(fromInterfaceType, transformerSpec string) (Transformer, error) {
 	from := strings.ToLower(strings.TrimSpace(fromInterfaceType))
 	spec := strings.ToLower(strings.TrimSpace(transformerSpec))
@@ -42,7 +55,10 @@ func Get(fromInterfaceType, transformerSpec string) (Transformer, error) {
 func getFromClaude(spec string) (Transformer, error) {
 	switch {
 	case strings.Contains(spec, "chat-completions") || strings.Contains(spec, "chat/completions") || strings.Contains(spec, "chat"):
-		return claude_chat.Transformer{}, nil
+		return claude_chat.Transformer{
+			StructuredOutput: strings.Contains(spec, "json_schema"),
+			DeveloperRole:    strings.Contains(spec, "developer"),
+		}, nil
 	case strings.Contains(spec, "responses") || strings.Contains(spec, "codex"):
 		return claude_responses.Transformer{}, nil
 	case strings.Contains(spec, "gemini"):
@@ -55,7 +71,7 @@ func getFromClaude(spec string) (Transformer, error) {
 func getFromCodex(spec string) (Transformer, error) {
 	switch {
 	case strings.Contains(spec, "chat-completions") || strings.Contains(spec, "chat/completions") || strings.Contains(spec, "chat"):
-		return codex_chat.Transformer{}, nil
+		return codex_chat.Transformer{DeveloperRole: strings.Contains(spec, "developer")}, nil
 	default:
 		return nil, fmt.Errorf("unsupported codex transformer spec=%q (expected openai/chat-completions)", spec)
 	}
@@ -68,12 +84,15 @@ func List(fromInterfaceType string) ([]string, error) {
 	case "claude":
 		return []string{
 			"openai/chat-completions",
+			"openai/chat-completions+json_schema",
+			"openai/chat-completions+developer",
 			"openai/responses",
 			"gemini",
 		}, nil
 	case "codex":
 		return []string{
 			"openai/chat-completions",
+			"openai/chat-completions+developer",
 		}, nil
 	default:
 		return nil, fmt.Errorf("unsupported transformer source interfaceType=%q", fromInterfaceType)
@@ -83,7 +102,7 @@ func List(fromInterfaceType string) ([]string, error) {
 // ListAll returns all supported transformer specs grouped by source interfaceType.
 func ListAll() map[string][]string {
 	return map[string][]string{
-		"claude": {"openai/chat-completions", "openai/responses", "gemini"},
-		"codex":  {"openai/chat-completions"},
+		"claude": {"openai/chat-completions", "openai/chat-completions+json_schema", "openai/chat-completions+developer", "openai/responses", "gemini"},
+		"codex":  {"openai/chat-completions", "openai/chat-completions+developer"},
 	}
 }
@@ -1,6 +1,9 @@
 package transformer_test
 
 import (
+	"context"
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"clisimplehub/internal/transformer"
@@ -65,7 +68,194 @@ func TestList(t *testing.T) {
 	if err != nil {
 		t.Fatalf("List(codex) err=%v", err)
 	}
-	if len(codex) != 1 || codex[0] != "openai/chat-completions" {
+	if len(codex) != 2 || codex[0] != "openai/chat-completions" || codex[1] != "openai/chat-completions+developer" {
 		t.Fatalf("List(codex)=%v", codex)
 	}
 }
+
+func TestClaudeSystemConsistentAcrossTransformers(t *testing.T) {
+	t.Parallel()
+
+	systems := []struct {
+		name   string
+		system string
+		want   string
+	}{
+		{name: "string", system: `"be brief"`, want: "be brief"},
+		{name: "text blocks", system: `[{"type":"text","text":"be brief"},{"type":"text","text":"use english"}]`, want: "be brief\nuse english"},
+		{name: "cache_control", system: `[{"type":"text","text":"be brief","cache_control":{"type":"ephemeral"}}]`, want: "be brief"},
+		{name: "untyped block", system: `[{"text":"be brief"}]`, want: "be brief"},
+		{name: "single block", system: `{"type":"text","text":"be brief"}`, want: "be brief"},
+		{name: "skips empty and non-text", system: `[{"type":"text","text":"  "},{"type":"image","source":{}},{"type":"text","text":"be brief"}]`, want: "be brief"},
+		{name: "empty", system: `""`, want: ""},
+	}
+
+	// extract returns the system text each target protocol carries
+	extractors := map[string]func(out map[string]any) string{
+		"openai/chat-completions": func(out map[string]any) string {
+			msgs, _ := out["messages"].([]any)
+			if len(msgs) == 0 {
+				return ""
+			}
+			first, _ := msgs[0].(map[string]any)
+			if first["role"] != "system" {
+				return ""
+			}
+			s, _ := first["content"].(string)
+			return s
+		},
+		"openai/responses": func(out map[string]any) string {
+			s, _ := out["instructions"].(string)
+			return s
+		},
+		"gemini": func(out map[string]any) string {
+			si, _ := out["system_instruction"].(map[string]any)
+			parts, _ := si["parts"].([]any)
+			if len(parts) == 0 {
+				return ""
+			}
+			part, _ := parts[0].(map[string]any)
+			s, _ := part["text"].(string)
+			return s
+		},
+	}
+
+	for spec, extract := range extractors {
+		tr, err := transformer.Get("claude", spec)
+		if err != nil {
+			t.Fatalf("Get(%q) err=%v", spec, err)
+		}
+		for _, tc := range systems {
+			raw := []byte(`{"model":"m","max_tokens":16,"system":` + tc.system + `,"messages":[{"role":"user","content":"hi"}]}`)
+			outBytes, err := tr.TransformRequest("m", raw, false)
+			if err != nil {
+				t.Fatalf("%s/%s: TransformRequest err=%v", spec, tc.name, err)
+			}
+			var out map[string]any
+			if err := json.Unmarshal(outBytes, &out); err != nil {
+				t.Fatalf("%s/%s: unmarshal err=%v", spec, tc.name, err)
+			}
+			if got := extract(out); got != tc.want {
+				t.Fatalf("%s/%s: system=%q want %q", spec, tc.name, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestClaudeServerToolsStrippedAcrossTransformers(t *testing.T) {
+	t.Parallel()
+
+	const searchTool = `{"type":"web_search_20250305","name":"web_search","max_uses":5}`
+	const customTool = `{"name":"get_weather","input_schema":{"type":"object","properties":{"city":{"type":"string"}}}}`
+	cases := []struct {
+		name       string
+		tools      string
+		toolChoice string
+		wantTools  bool
+		wantErr    string
+	}{
+		{name: "mixed", tools: `[` + searchTool + `,` + customTool + `]`, toolChoice: `{"type":"auto"}`, wantTools: true},
+		{name: "only server tools", tools: `[` + searchTool + `,{"type":"code_execution_20250522","name":"code_execution"}]`, toolChoice: `{"type":"any"}`},
+		{name: "forced server tool", tools: `[` + searchTool + `,` + customTool + `]`, toolChoice: `{"type":"tool","name":"web_search"}`, wantErr: "server tool web_search unsupported by this endpoint"},
+	}
+
+	for _, spec := range []string{"openai/chat-completions", "openai/responses", "gemini"} {
+		tr, err := transformer.Get("claude", spec)
+		if err != nil {
+			t.Fatalf("Get(%q) err=%v", spec, err)
+		}
+		for _, tc := range cases {
+			raw := []byte(`{"model":"m","max_tokens":16,"tools":` + tc.tools + `,"tool_choice":` + tc.toolChoice + `,"messages":[{"role":"user","content":"hi"}]}`)
+			outBytes, err := tr.TransformRequest("m", raw, false)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("%s/%s: err=%v want %q", spec, tc.name, err, tc.wantErr)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%s/%s: TransformRequest err=%v", spec, tc.name, err)
+			}
+			out := string(outBytes)
+			if strings.Contains(out, "web_search") || strings.Contains(out, "code_execution") {
+				t.Fatalf("%s/%s: server tool not stripped: %s", spec, tc.name, out)
+			}
+			if got := strings.Contains(out, "get_weather"); got != tc.wantTools {
+				t.Fatalf("%s/%s: custom tool present=%v want %v: %s", spec, tc.name, got, tc.wantTools, out)
+			}
+		}
+	}
+}
+
+func TestClaudeStreamErrorAcrossTransformers(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		spec      string
+		lines     []string
+		wantType  string
+		wantInMsg string
+	}{
+		{
+			spec: "openai/chat-completions",
+			lines: []string{
+				`data: {"id":"c1","model":"m","choices":[{"index":0,"delta":{"content":"hel"}}]}`,
+				`data: {"error":{"message":"upstream overloaded","type":"overloaded_error"}}`,
+				`data: [DONE]`,
+			},
+			wantType:  "overloaded_error",
+			wantInMsg: "upstream overloaded",
+		},
+		{
+			spec: "openai/responses",
+			lines: []string{
+				`data: {"type":"response.created","response":{"id":"r1","model":"m"}}`,
+				`data: {"type":"response.failed","response":{"id":"r1","status":"failed","error":{"code":"rate_limit_exceeded","message":"slow down"}}}`,
+				`data: [DONE]`,
+			},
+			wantType:  "rate_limit_error",
+			wantInMsg: "slow down",
+		},
+		{
+			spec: "gemini",
+			lines: []string{
+				`data: {"responseId":"g1","candidates":[{"content":{"parts":[{"text":"hel"}]}}]}`,
+				`data: {"error":{"code":500,"message":"internal failure","status":"INTERNAL"}}`,
+				`data: [DONE]`,
+			},
+			wantType:  "api_error",
+			wantInMsg: "internal failure",
+		},
+	}
+
+	for _, tc := range cases {
+		tr, err := transformer.Get("claude", tc.spec)
+		if err != nil {
+			t.Fatalf("Get(%q) err=%v", tc.spec, err)
+		}
+		var state any
+		var events []string
+		for _, line := range tc.lines {
+			out, err := tr.TransformResponseStream(context.Background(), "m", nil, nil, []byte(line), &state)
+			if err != nil {
+				t.Fatalf("%s: TransformResponseStream err=%v", tc.spec, err)
+			}
+			events = append(events, out...)
+		}
+		if len(events) == 0 {
+			t.Fatalf("%s: no events", tc.spec)
+		}
+		last := events[len(events)-1]
+		if !strings.HasPrefix(last, "event: error") {
+			t.Fatalf("%s: last event=%q want error", tc.spec, last)
+		}
+		if !strings.Contains(last, `"type":"`+tc.wantType+`"`) || !strings.Contains(last, tc.wantInMsg) {
+			t.Fatalf("%s: error event=%q", tc.spec, last)
+		}
+		for _, ev := range events {
+			if strings.HasPrefix(ev, "event: message_stop") {
+				t.Fatalf("%s: message_stop emitted after upstream error: %v", tc.spec, events)
+			}
+		}
+	}
+}